@@ -0,0 +1,200 @@
+// +build !js
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// defaultHistoryLimit is the maximum number of events returned by
+	// /history if no limit is given.
+	defaultHistoryLimit = 1000
+	// maxHistoryLimit is the maximum number of events returned by /history.
+	maxHistoryLimit = 10000
+)
+
+// labelValueReplacer escapes label values as required by the Prometheus text
+// exposition format.
+var labelValueReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// serveAPI serves the HTTP API of the bootstrap node on the given address:
+//
+//    GET /peers              connected peers
+//    GET /peers/<peerID>     a single connected peer
+//    GET /rendezvous         rendezvous points with provider records in the DHT
+//                            or registrations with the rendezvous server
+//    GET /bandwidth          total bandwidth and bandwidth per protocol
+//    GET /history            stored connects and disconnects, newest first
+//                            (query parameters: since=<RFC 3339 timestamp>,
+//                            peerID, limit)
+//    GET /metrics            metrics in the Prometheus text format
+//
+func serveAPI(addr string, directory *peerDirectory) error {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/peers", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, directory.peers())
+	})
+	mux.HandleFunc("/peers/", func(w http.ResponseWriter, r *http.Request) {
+		peerID, err := peer.IDB58Decode(strings.TrimPrefix(r.URL.Path, "/peers/"))
+		if err != nil {
+			http.Error(w, "invalid peer ID", http.StatusBadRequest)
+			return
+		}
+		if len(directory.host.Network().ConnsToPeer(peerID)) == 0 {
+			http.Error(w, "peer is not connected", http.StatusNotFound)
+			return
+		}
+		writeJSON(w, directory.peer(peerID))
+	})
+	mux.HandleFunc("/rendezvous", func(w http.ResponseWriter, r *http.Request) {
+		rendezvousPoints, err := directory.rendezvousPoints()
+		if err != nil {
+			log.WithError(err).Error("could not get rendezvous points")
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, rendezvousPoints)
+	})
+	mux.HandleFunc("/bandwidth", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, directory.bandwidth())
+	})
+	mux.HandleFunc("/history", func(w http.ResponseWriter, r *http.Request) {
+		since := time.Time{}
+		if sinceParam := r.URL.Query().Get("since"); sinceParam != "" {
+			var err error
+			since, err = time.Parse(time.RFC3339, sinceParam)
+			if err != nil {
+				http.Error(w, "invalid since parameter: expected RFC 3339 timestamp", http.StatusBadRequest)
+				return
+			}
+		}
+		limit := defaultHistoryLimit
+		if limitParam := r.URL.Query().Get("limit"); limitParam != "" {
+			var err error
+			limit, err = strconv.Atoi(limitParam)
+			if err != nil || limit <= 0 || limit > maxHistoryLimit {
+				http.Error(w, fmt.Sprintf("invalid limit parameter: expected number between 1 and %d", maxHistoryLimit), http.StatusBadRequest)
+				return
+			}
+		}
+		events, err := directory.history.query(since, r.URL.Query().Get("peerID"), limit)
+		if err != nil {
+			log.WithError(err).Error("could not query peer history")
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, events)
+	})
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		writeMetrics(w, directory)
+	})
+	log.WithField("address", addr).Info("serving bootstrap node HTTP API")
+	return http.ListenAndServe(addr, mux)
+}
+
+func writeJSON(w http.ResponseWriter, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		log.WithError(err).Error("could not encode HTTP API response")
+	}
+}
+
+// writeMetrics writes the metrics of the bootstrap node in the Prometheus text
+// exposition format.
+func writeMetrics(w io.Writer, directory *peerDirectory) {
+	writeMetric(w, "mesh_bootstrap_peers", "gauge", "Number of connected peers.", nil, float64(len(directory.host.Network().Peers())))
+	writeMetric(w, "mesh_bootstrap_connections", "gauge", "Number of open connections.", nil, float64(len(directory.host.Network().Conns())))
+	writeMetric(w, "mesh_bootstrap_peer_connects_total", "counter", "Number of connections opened since startup.", nil, float64(atomic.LoadUint64(&directory.connects)))
+	writeMetric(w, "mesh_bootstrap_peer_disconnects_total", "counter", "Number of connections closed since startup.", nil, float64(atomic.LoadUint64(&directory.disconnects)))
+
+	agentVersions := map[string]int{}
+	for _, peerID := range directory.host.Network().Peers() {
+		agentVersions[directory.peerstoreString(peerID, "AgentVersion")]++
+	}
+	sortedAgentVersions := []string{}
+	for agentVersion := range agentVersions {
+		sortedAgentVersions = append(sortedAgentVersions, agentVersion)
+	}
+	sort.Strings(sortedAgentVersions)
+	writeMetricHeader(w, "mesh_bootstrap_peers_by_agent_version", "gauge", "Number of connected peers by agent version.")
+	for _, agentVersion := range sortedAgentVersions {
+		writeSample(w, "mesh_bootstrap_peers_by_agent_version", map[string]string{"agent_version": agentVersion}, float64(agentVersions[agentVersion]))
+	}
+
+	bandwidth := directory.bandwidth()
+	writeMetricHeader(w, "mesh_bootstrap_bandwidth_bytes_total", "counter", "Number of bytes sent and received since startup.")
+	writeSample(w, "mesh_bootstrap_bandwidth_bytes_total", map[string]string{"direction": "in"}, float64(bandwidth.Total.TotalIn))
+	writeSample(w, "mesh_bootstrap_bandwidth_bytes_total", map[string]string{"direction": "out"}, float64(bandwidth.Total.TotalOut))
+	writeMetricHeader(w, "mesh_bootstrap_bandwidth_bytes_per_second", "gauge", "Current number of bytes sent and received per second.")
+	writeSample(w, "mesh_bootstrap_bandwidth_bytes_per_second", map[string]string{"direction": "in"}, bandwidth.Total.RateIn)
+	writeSample(w, "mesh_bootstrap_bandwidth_bytes_per_second", map[string]string{"direction": "out"}, bandwidth.Total.RateOut)
+	protocols := []string{}
+	for protocolID := range bandwidth.ByProtocol {
+		protocols = append(protocols, protocolID)
+	}
+	sort.Strings(protocols)
+	writeMetricHeader(w, "mesh_bootstrap_protocol_bandwidth_bytes_total", "counter", "Number of bytes sent and received since startup by protocol.")
+	for _, protocolID := range protocols {
+		stats := bandwidth.ByProtocol[protocolID]
+		writeSample(w, "mesh_bootstrap_protocol_bandwidth_bytes_total", map[string]string{"protocol": protocolID, "direction": "in"}, float64(stats.TotalIn))
+		writeSample(w, "mesh_bootstrap_protocol_bandwidth_bytes_total", map[string]string{"protocol": protocolID, "direction": "out"}, float64(stats.TotalOut))
+	}
+
+	rendezvousPoints, err := directory.rendezvousPoints()
+	if err != nil {
+		log.WithError(err).Error("could not get rendezvous points")
+		return
+	}
+	writeMetricHeader(w, "mesh_bootstrap_rendezvous_providers", "gauge", "Number of providers per rendezvous point in the DHT.")
+	for _, info := range rendezvousPoints {
+		labels := map[string]string{"key": info.Key}
+		if info.RendezvousPoint != "" {
+			labels["rendezvous_point"] = info.RendezvousPoint
+		}
+		writeSample(w, "mesh_bootstrap_rendezvous_providers", labels, float64(len(info.Providers)))
+	}
+	if directory.rendezvousServer != nil {
+		writeMetricHeader(w, "mesh_bootstrap_rendezvous_registrations", "gauge", "Number of peers registered per rendezvous point with the rendezvous server.")
+		for _, info := range rendezvousPoints {
+			if info.Registrations == 0 {
+				continue
+			}
+			writeSample(w, "mesh_bootstrap_rendezvous_registrations", map[string]string{"key": info.Key, "rendezvous_point": info.RendezvousPoint}, float64(info.Registrations))
+		}
+	}
+}
+
+func writeMetric(w io.Writer, name string, metricType string, help string, labels map[string]string, value float64) {
+	writeMetricHeader(w, name, metricType, help)
+	writeSample(w, name, labels, value)
+}
+
+func writeMetricHeader(w io.Writer, name string, metricType string, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, metricType)
+}
+
+func writeSample(w io.Writer, name string, labels map[string]string, value float64) {
+	if len(labels) == 0 {
+		fmt.Fprintf(w, "%s %s\n", name, strconv.FormatFloat(value, 'g', -1, 64))
+		return
+	}
+	pairs := []string{}
+	for label, labelValue := range labels {
+		pairs = append(pairs, fmt.Sprintf("%s=\"%s\"", label, labelValueReplacer.Replace(labelValue)))
+	}
+	sort.Strings(pairs)
+	fmt.Fprintf(w, "%s{%s} %s\n", name, strings.Join(pairs, ","), strconv.FormatFloat(value, 'g', -1, 64))
+}
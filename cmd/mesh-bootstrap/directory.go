@@ -0,0 +1,322 @@
+// +build !js
+
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/0xProject/0x-mesh/p2p/banner"
+	"github.com/0xProject/0x-mesh/p2p/rendezvous"
+	"github.com/ipfs/go-datastore"
+	"github.com/ipfs/go-datastore/query"
+	"github.com/libp2p/go-libp2p-core/host"
+	"github.com/libp2p/go-libp2p-core/metrics"
+	p2pnet "github.com/libp2p/go-libp2p-core/network"
+	"github.com/libp2p/go-libp2p-core/peer"
+	ma "github.com/multiformats/go-multiaddr"
+	log "github.com/sirupsen/logrus"
+)
+
+// providersKeyPrefix is the prefix of the keys the DHT uses to store provider
+// records. Peers advertise themselves on a rendezvous point by providing a
+// key derived from it.
+const providersKeyPrefix = "/providers/"
+
+// providersKeyEncoding is the encoding the DHT uses for the components of
+// provider record keys.
+var providersKeyEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// peerInfo describes a connected peer.
+type peerInfo struct {
+	PeerID          string        `json:"peerID"`
+	ConnectedAddrs  []string      `json:"connectedAddrs"`
+	AdvertisedAddrs []string      `json:"advertisedAddrs"`
+	AgentVersion    string        `json:"agentVersion"`
+	ProtocolVersion string        `json:"protocolVersion"`
+	Protocols       []string      `json:"protocols"`
+	Bandwidth       metrics.Stats `json:"bandwidth"`
+	IsBanned        bool          `json:"isBanned"`
+	IsProtectedIP   bool          `json:"isProtectedIP"`
+}
+
+// rendezvousPointInfo describes a rendezvous point with provider records in
+// the DHT or registrations with the rendezvous server.
+type rendezvousPointInfo struct {
+	// RendezvousPoint is the rendezvous point, if it is one of the configured
+	// KNOWN_RENDEZVOUS_POINTS or has registrations with the rendezvous server.
+	// The DHT only stores a hash of the rendezvous point, so other rendezvous
+	// points can only be identified by Key.
+	RendezvousPoint string `json:"rendezvousPoint,omitempty"`
+	// Key is the hex encoded DHT key of the rendezvous point.
+	Key       string   `json:"key"`
+	Providers []string `json:"providers"`
+	// Registrations is the number of peers registered under the rendezvous
+	// point with the rendezvous server.
+	Registrations int `json:"registrations"`
+}
+
+// bandwidthInfo describes the bandwidth used by the bootstrap node.
+type bandwidthInfo struct {
+	Total      metrics.Stats            `json:"total"`
+	ByProtocol map[string]metrics.Stats `json:"byProtocol"`
+}
+
+// peerDirectory keeps track of the peers of the bootstrap node. It is notified
+// about connects and disconnects, which it logs and stores in the history.
+type peerDirectory struct {
+	host             host.Host
+	banner           *banner.Banner
+	bandwidthCounter *metrics.BandwidthCounter
+	dhtStore         datastore.Datastore
+	history          *history
+	// knownRendezvousPoints maps the DHT key of each known rendezvous point to
+	// the rendezvous point.
+	knownRendezvousPoints map[string]string
+	// rendezvousServer is nil if the rendezvous server is disabled.
+	rendezvousServer *rendezvous.Server
+	connects         uint64
+	disconnects      uint64
+}
+
+var _ p2pnet.Notifiee = &peerDirectory{}
+
+func newPeerDirectory(h host.Host, banner *banner.Banner, bandwidthCounter *metrics.BandwidthCounter, dhtStore datastore.Datastore, history *history, knownRendezvousPoints []string) *peerDirectory {
+	known := map[string]string{}
+	for _, rendezvousPoint := range knownRendezvousPoints {
+		known[string(rendezvousPointKey(rendezvousPoint))] = rendezvousPoint
+	}
+	return &peerDirectory{
+		host:                  h,
+		banner:                banner,
+		bandwidthCounter:      bandwidthCounter,
+		dhtStore:              dhtStore,
+		history:               history,
+		knownRendezvousPoints: known,
+	}
+}
+
+// rendezvousPointKey returns the SHA2-256 multihash of the rendezvous point,
+// which is what peers provide in the DHT when they advertise themselves on it.
+func rendezvousPointKey(rendezvousPoint string) []byte {
+	digest := sha256.Sum256([]byte(rendezvousPoint))
+	// 0x12 is the multihash code of SHA2-256 and 0x20 is the digest length.
+	return append([]byte{0x12, 0x20}, digest[:]...)
+}
+
+// Listen is called when network starts listening on an addr
+func (d *peerDirectory) Listen(p2pnet.Network, ma.Multiaddr) {}
+
+// ListenClose is called when network stops listening on an addr
+func (d *peerDirectory) ListenClose(p2pnet.Network, ma.Multiaddr) {}
+
+// Connected is called when a connection opened
+func (d *peerDirectory) Connected(network p2pnet.Network, conn p2pnet.Conn) {
+	atomic.AddUint64(&d.connects, 1)
+	log.WithFields(map[string]interface{}{
+		"remotePeerID":       conn.RemotePeer(),
+		"remoteMultiaddress": conn.RemoteMultiaddr(),
+	}).Info("connected to peer")
+	d.history.record(&peerEvent{
+		Type:               peerEventConnected,
+		Timestamp:          time.Now(),
+		PeerID:             conn.RemotePeer().Pretty(),
+		RemoteMultiaddress: conn.RemoteMultiaddr().String(),
+	})
+}
+
+// Disconnected is called when a connection closed
+func (d *peerDirectory) Disconnected(network p2pnet.Network, conn p2pnet.Conn) {
+	atomic.AddUint64(&d.disconnects, 1)
+	log.WithFields(map[string]interface{}{
+		"remotePeerID":       conn.RemotePeer(),
+		"remoteMultiaddress": conn.RemoteMultiaddr(),
+	}).Info("disconnected from peer")
+	bandwidth := d.bandwidthCounter.GetBandwidthForPeer(conn.RemotePeer())
+	d.history.record(&peerEvent{
+		Type:               peerEventDisconnected,
+		Timestamp:          time.Now(),
+		PeerID:             conn.RemotePeer().Pretty(),
+		RemoteMultiaddress: conn.RemoteMultiaddr().String(),
+		AgentVersion:       d.peerstoreString(conn.RemotePeer(), "AgentVersion"),
+		BytesIn:            bandwidth.TotalIn,
+		BytesOut:           bandwidth.TotalOut,
+	})
+}
+
+// OpenedStream is called when a stream opened
+func (d *peerDirectory) OpenedStream(network p2pnet.Network, stream p2pnet.Stream) {}
+
+// ClosedStream is called when a stream closed
+func (d *peerDirectory) ClosedStream(network p2pnet.Network, stream p2pnet.Stream) {}
+
+// peers returns information about all connected peers.
+func (d *peerDirectory) peers() []*peerInfo {
+	peers := []*peerInfo{}
+	for _, peerID := range d.host.Network().Peers() {
+		peers = append(peers, d.peer(peerID))
+	}
+	sort.Slice(peers, func(i, j int) bool {
+		return peers[i].PeerID < peers[j].PeerID
+	})
+	return peers
+}
+
+// peer returns information about the given peer.
+func (d *peerDirectory) peer(peerID peer.ID) *peerInfo {
+	info := &peerInfo{
+		PeerID:          peerID.Pretty(),
+		ConnectedAddrs:  []string{},
+		AdvertisedAddrs: []string{},
+		AgentVersion:    d.peerstoreString(peerID, "AgentVersion"),
+		ProtocolVersion: d.peerstoreString(peerID, "ProtocolVersion"),
+		Protocols:       []string{},
+		Bandwidth:       d.bandwidthCounter.GetBandwidthForPeer(peerID),
+	}
+	for _, conn := range d.host.Network().ConnsToPeer(peerID) {
+		addr := conn.RemoteMultiaddr()
+		info.ConnectedAddrs = append(info.ConnectedAddrs, addr.String())
+		if d.banner.IsAddrBanned(addr) {
+			info.IsBanned = true
+		}
+		if d.banner.IsIPProtected(addr) {
+			info.IsProtectedIP = true
+		}
+	}
+	for _, addr := range d.host.Peerstore().Addrs(peerID) {
+		info.AdvertisedAddrs = append(info.AdvertisedAddrs, addr.String())
+	}
+	if protocols, err := d.host.Peerstore().GetProtocols(peerID); err == nil {
+		sort.Strings(protocols)
+		info.Protocols = protocols
+	}
+	return info
+}
+
+// peerstoreString returns the string value stored in the peerstore for the
+// given peer and key, or an empty string if there is none. The identify
+// protocol stores the agent and protocol versions of peers in the peerstore.
+func (d *peerDirectory) peerstoreString(peerID peer.ID, key string) string {
+	value, err := d.host.Peerstore().Get(peerID, key)
+	if err != nil {
+		return ""
+	}
+	stringValue, _ := value.(string)
+	return stringValue
+}
+
+// rendezvousPoints returns the rendezvous points with provider records in the
+// DHT, ordered by the number of providers.
+func (d *peerDirectory) rendezvousPoints() ([]*rendezvousPointInfo, error) {
+	results, err := d.dhtStore.Query(query.Query{
+		Prefix:   providersKeyPrefix,
+		KeysOnly: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer results.Close()
+	entries, err := results.Rest()
+	if err != nil {
+		return nil, err
+	}
+	byKey := map[string]*rendezvousPointInfo{}
+	for _, entry := range entries {
+		// Provider record keys have the form /providers/<key>/<provider>.
+		parts := strings.Split(strings.TrimPrefix(entry.Key, providersKeyPrefix), "/")
+		if len(parts) != 2 {
+			continue
+		}
+		key, err := providersKeyEncoding.DecodeString(parts[0])
+		if err != nil {
+			continue
+		}
+		providerBytes, err := providersKeyEncoding.DecodeString(parts[1])
+		if err != nil {
+			continue
+		}
+		provider, err := peer.IDFromBytes(providerBytes)
+		if err != nil {
+			continue
+		}
+		info, found := byKey[string(key)]
+		if !found {
+			info = &rendezvousPointInfo{
+				RendezvousPoint: d.lookupRendezvousPoint(key),
+				Key:             hex.EncodeToString(key),
+				Providers:       []string{},
+			}
+			byKey[string(key)] = info
+		}
+		info.Providers = append(info.Providers, provider.Pretty())
+	}
+	if d.rendezvousServer != nil {
+		for namespace, registrations := range d.rendezvousServer.Namespaces() {
+			key := rendezvousPointKey(namespace)
+			info := findRendezvousPointInfo(byKey, key)
+			if info == nil {
+				info = &rendezvousPointInfo{
+					Key:       hex.EncodeToString(key),
+					Providers: []string{},
+				}
+				byKey[string(key)] = info
+			}
+			info.RendezvousPoint = namespace
+			info.Registrations = registrations
+		}
+	}
+	rendezvousPoints := []*rendezvousPointInfo{}
+	for _, info := range byKey {
+		sort.Strings(info.Providers)
+		rendezvousPoints = append(rendezvousPoints, info)
+	}
+	sort.Slice(rendezvousPoints, func(i, j int) bool {
+		if len(rendezvousPoints[i].Providers) != len(rendezvousPoints[j].Providers) {
+			return len(rendezvousPoints[i].Providers) > len(rendezvousPoints[j].Providers)
+		}
+		return rendezvousPoints[i].Key < rendezvousPoints[j].Key
+	})
+	return rendezvousPoints, nil
+}
+
+// findRendezvousPointInfo returns the info whose DHT key contains the given
+// multihash, or nil if there is none.
+func findRendezvousPointInfo(byKey map[string]*rendezvousPointInfo, multihash []byte) *rendezvousPointInfo {
+	for key, info := range byKey {
+		if bytes.HasSuffix([]byte(key), multihash) {
+			return info
+		}
+	}
+	return nil
+}
+
+// lookupRendezvousPoint returns the known rendezvous point for the given DHT
+// key, or an empty string if it is not known. Depending on the DHT version the
+// key is either the multihash of the rendezvous point or a CID containing it.
+func (d *peerDirectory) lookupRendezvousPoint(key []byte) string {
+	for multihash, rendezvousPoint := range d.knownRendezvousPoints {
+		if bytes.HasSuffix(key, []byte(multihash)) {
+			return rendezvousPoint
+		}
+	}
+	return ""
+}
+
+// bandwidth returns the total bandwidth used by the bootstrap node and the
+// bandwidth per protocol.
+func (d *peerDirectory) bandwidth() *bandwidthInfo {
+	info := &bandwidthInfo{
+		Total:      d.bandwidthCounter.GetBandwidthTotals(),
+		ByProtocol: map[string]metrics.Stats{},
+	}
+	for protocolID, stats := range d.bandwidthCounter.GetBandwidthByProtocol() {
+		info.ByProtocol[string(protocolID)] = stats
+	}
+	return info
+}
@@ -0,0 +1,254 @@
+// +build !js
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ipfs/go-datastore"
+	"github.com/ipfs/go-datastore/query"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// historyKeyPrefix is the prefix of all keys used to store peer events.
+	historyKeyPrefix = "/history/"
+	// historyByPeerKeyPrefix is the prefix of the keys used to store a copy of
+	// each peer event per peer, so that the events of a single peer can be
+	// queried without reading the events of all other peers.
+	historyByPeerKeyPrefix = "/historyByPeer/"
+	// historyByPeerIndexedKey marks that the events which were stored before
+	// historyByPeerKeyPrefix existed have been copied.
+	historyByPeerIndexedKey = "/historyByPeerIndexed"
+	// pruneHistoryInterval is how often events older than the history
+	// retention are removed.
+	pruneHistoryInterval = 1 * time.Hour
+)
+
+// peerEventType is the type of a peerEvent.
+type peerEventType string
+
+const (
+	peerEventConnected    peerEventType = "connected"
+	peerEventDisconnected peerEventType = "disconnected"
+)
+
+// peerEvent is a connect or disconnect of a peer which is stored in the
+// history.
+type peerEvent struct {
+	Type               peerEventType `json:"type"`
+	Timestamp          time.Time     `json:"timestamp"`
+	PeerID             string        `json:"peerID"`
+	RemoteMultiaddress string        `json:"remoteMultiaddress"`
+	// AgentVersion is the agent version the peer reported during the identify
+	// protocol. It is only known for disconnects, since identify has not
+	// finished yet when a connection is opened.
+	AgentVersion string `json:"agentVersion,omitempty"`
+	// BytesIn and BytesOut are the total number of bytes received from and sent
+	// to the peer. They are only set for disconnects.
+	BytesIn  int64 `json:"bytesIn,omitempty"`
+	BytesOut int64 `json:"bytesOut,omitempty"`
+}
+
+// history stores peer events in the datastore of the bootstrap node so that
+// they survive restarts.
+type history struct {
+	store     datastore.Batching
+	retention time.Duration
+}
+
+func newHistory(store datastore.Batching, retention time.Duration) *history {
+	return &history{
+		store:     store,
+		retention: retention,
+	}
+}
+
+// historyKey returns the key for the given event. Keys sort in chronological
+// order.
+func historyKey(event *peerEvent) datastore.Key {
+	return datastore.NewKey(fmt.Sprintf("%s%020d-%s", historyKeyPrefix, event.Timestamp.UnixNano(), event.PeerID))
+}
+
+// historyByPeerKey returns the key for the copy of the given event which is
+// stored per peer. Keys sort in chronological order for each peer.
+func historyByPeerKey(event *peerEvent) datastore.Key {
+	return historyByPeerKeyForHistoryKey(historyKey(event))
+}
+
+// historyByPeerKeyForHistoryKey returns the key of the copy of the event
+// stored at the given key.
+func historyByPeerKeyForHistoryKey(key datastore.Key) datastore.Key {
+	// Timestamps are formatted with a fixed width, so the first dash separates
+	// them from the peer ID.
+	timestampAndPeerID := strings.TrimPrefix(key.String(), historyKeyPrefix)
+	parts := strings.SplitN(timestampAndPeerID, "-", 2)
+	if len(parts) != 2 {
+		return datastore.NewKey(historyByPeerKeyPrefix + timestampAndPeerID)
+	}
+	return datastore.NewKey(historyByPeerKeyPrefix + parts[1] + "/" + parts[0])
+}
+
+// record stores the given event.
+func (h *history) record(event *peerEvent) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		log.WithError(err).Error("could not encode peer event")
+		return
+	}
+	batch, err := h.store.Batch()
+	if err != nil {
+		log.WithError(err).Error("could not store peer event")
+		return
+	}
+	if err := batch.Put(historyKey(event), data); err != nil {
+		log.WithError(err).Error("could not store peer event")
+		return
+	}
+	if err := batch.Put(historyByPeerKey(event), data); err != nil {
+		log.WithError(err).Error("could not store peer event")
+		return
+	}
+	if err := batch.Commit(); err != nil {
+		log.WithError(err).Error("could not store peer event")
+	}
+}
+
+// query returns up to limit events which happened after since, ordered from
+// newest to oldest. If peerID is not empty, only events of that peer are
+// returned. Events are read in reverse key order and reading stops as soon as
+// enough events were found or the events are older than since, so the cost of
+// a query does not depend on the size of the history.
+func (h *history) query(since time.Time, peerID string, limit int) ([]*peerEvent, error) {
+	prefix := historyKeyPrefix
+	if peerID != "" {
+		prefix = historyByPeerKeyPrefix + peerID + "/"
+	}
+	// Events at since are not returned, so the cutoff is the key of the first
+	// nanosecond after since.
+	cutoff := ""
+	if since.After(time.Unix(0, 0)) {
+		cutoff = fmt.Sprintf("%s%020d", prefix, since.UnixNano()+1)
+	}
+	results, err := h.store.Query(query.Query{
+		Prefix: prefix,
+		Orders: []query.Order{query.OrderByKeyDescending{}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer results.Close()
+	events := []*peerEvent{}
+	for len(events) < limit {
+		entry, ok := results.NextSync()
+		if !ok {
+			break
+		}
+		if entry.Error != nil {
+			return nil, entry.Error
+		}
+		if entry.Key < cutoff {
+			break
+		}
+		event := &peerEvent{}
+		if err := json.Unmarshal(entry.Value, event); err != nil {
+			log.WithError(err).WithField("key", entry.Key).Warn("could not decode peer event")
+			continue
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// indexByPeer stores a copy per peer of the events which were stored before
+// historyByPeerKeyPrefix existed. It only needs to run once.
+func (h *history) indexByPeer() error {
+	if indexed, err := h.store.Has(datastore.NewKey(historyByPeerIndexedKey)); err != nil {
+		return err
+	} else if indexed {
+		return nil
+	}
+	results, err := h.store.Query(query.Query{
+		Prefix: historyKeyPrefix,
+	})
+	if err != nil {
+		return err
+	}
+	defer results.Close()
+	batch, err := h.store.Batch()
+	if err != nil {
+		return err
+	}
+	for entry := range results.Next() {
+		if entry.Error != nil {
+			return entry.Error
+		}
+		if err := batch.Put(historyByPeerKeyForHistoryKey(datastore.NewKey(entry.Key)), entry.Value); err != nil {
+			return err
+		}
+	}
+	if err := batch.Put(datastore.NewKey(historyByPeerIndexedKey), []byte{}); err != nil {
+		return err
+	}
+	return batch.Commit()
+}
+
+// prune removes all events which are older than the history retention.
+func (h *history) prune() error {
+	results, err := h.store.Query(query.Query{
+		Prefix:   historyKeyPrefix,
+		KeysOnly: true,
+	})
+	if err != nil {
+		return err
+	}
+	defer results.Close()
+	entries, err := results.Rest()
+	if err != nil {
+		return err
+	}
+	cutoff := fmt.Sprintf("%s%020d", historyKeyPrefix, time.Now().Add(-h.retention).UnixNano())
+	batch, err := h.store.Batch()
+	if err != nil {
+		return err
+	}
+	removed := 0
+	for _, entry := range entries {
+		if entry.Key >= cutoff {
+			continue
+		}
+		key := datastore.NewKey(entry.Key)
+		if err := batch.Delete(key); err != nil {
+			return err
+		}
+		if err := batch.Delete(historyByPeerKeyForHistoryKey(key)); err != nil {
+			return err
+		}
+		removed++
+	}
+	if err := batch.Commit(); err != nil {
+		return err
+	}
+	log.WithField("removed", removed).Debug("pruned peer history")
+	return nil
+}
+
+// continuouslyPruneHistory prunes the history until the context is canceled.
+func (h *history) continuouslyPruneHistory(ctx context.Context) {
+	ticker := time.NewTicker(pruneHistoryInterval)
+	defer ticker.Stop()
+	for {
+		if err := h.prune(); err != nil {
+			log.WithError(err).Error("could not prune peer history")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
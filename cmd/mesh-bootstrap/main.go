@@ -15,6 +15,7 @@ import (
 	"github.com/0xProject/0x-mesh/loghooks"
 	"github.com/0xProject/0x-mesh/p2p"
 	"github.com/0xProject/0x-mesh/p2p/banner"
+	"github.com/0xProject/0x-mesh/p2p/rendezvous"
 	sqlds "github.com/0xProject/sql-datastore"
 	"github.com/ipfs/go-datastore"
 	leveldbStore "github.com/ipfs/go-ds-leveldb"
@@ -24,7 +25,6 @@ import (
 	connmgr "github.com/libp2p/go-libp2p-connmgr"
 	"github.com/libp2p/go-libp2p-core/host"
 	"github.com/libp2p/go-libp2p-core/metrics"
-	p2pnet "github.com/libp2p/go-libp2p-core/network"
 	"github.com/libp2p/go-libp2p-core/peer"
 	"github.com/libp2p/go-libp2p-core/routing"
 	dht "github.com/libp2p/go-libp2p-kad-dht"
@@ -122,6 +122,23 @@ type Config struct {
 	// allowed to send before failing the bandwidth check. Defaults to 1 MiB, which
 	// is roughly 100x expected usage based on real world measurements.
 	MaxBytesPerSecond float64 `envvar:"MAX_BYTES_PER_SECOND" default:"1048576"`
+	// HTTPAPIAddr is the address on which the HTTP API, which lists connected
+	// peers, rendezvous points, bandwidth usage and the peer history and serves
+	// Prometheus metrics, is served (e.g. "0.0.0.0:60600"). The API is disabled
+	// if empty.
+	HTTPAPIAddr string `envvar:"HTTP_API_ADDR" default:""`
+	// KnownRendezvousPoints is a comma-separated list of rendezvous points
+	// which are shown by name in the HTTP API. The DHT only stores hashes of
+	// rendezvous points, so all other rendezvous points are only shown by their
+	// DHT key.
+	KnownRendezvousPoints string `envvar:"KNOWN_RENDEZVOUS_POINTS" default:""`
+	// HistoryRetention is how long peer connects and disconnects are kept in
+	// the peer history. Defaults to 7 days.
+	HistoryRetention time.Duration `envvar:"HISTORY_RETENTION" default:"168h"`
+	// EnableRendezvousServer determines whether the bootstrap node acts as a
+	// rendezvous server, with which Mesh nodes configured with
+	// RENDEZVOUS_SERVERS register their rendezvous points and discover peers.
+	EnableRendezvousServer bool `envvar:"ENABLE_RENDEZVOUS_SERVER" default:"false"`
 }
 
 func init() {
@@ -164,16 +181,22 @@ func main() {
 	var newDHT func(h host.Host) (routing.PeerRouting, error)
 
 	var peerStore peerstore.Peerstore
+	var dhtStore datastore.Batching
+	var historyStore datastore.Batching
 
 	// TODO(oskar) - Figure out why returning an anonymous function from
 	// getNewDHT() is making kadDHT.runBootstrap panicing.
 	// When solved this big switch case can be removed from main()
 	switch config.DataStoreType {
 	case leveldbDataStore:
+		dhtStore, err = leveldbStore.NewDatastore(getDHTDir(config), nil)
+		if err != nil {
+			log.Fatal(err)
+		}
+
 		newDHT = func(h host.Host) (routing.PeerRouting, error) {
 			var err error
-			dhtDir := getDHTDir(config)
-			kadDHT, err = p2p.NewDHT(ctx, dhtDir, h)
+			kadDHT, err = NewDHTWithDatastore(ctx, dhtStore, h)
 			if err != nil {
 				log.WithField("error", err).Fatal("could not create DHT")
 			}
@@ -191,6 +214,11 @@ func main() {
 			log.Fatal(err)
 		}
 
+		historyStore, err = leveldbStore.NewDatastore(getHistoryDir(config), nil)
+		if err != nil {
+			log.Fatal(err)
+		}
+
 	case sqlDataStore:
 		db, err := getSQLDatabase(config)
 		if err != nil {
@@ -202,11 +230,10 @@ func main() {
 			log.WithField("error", err).Fatal("failed to repare SQL tables for datastores")
 		}
 
+		dhtStore = sqlds.NewDatastore(db, sqlds.NewQueriesForTable(dhtTableName))
 		newDHT = func(h host.Host) (routing.PeerRouting, error) {
 			var err error
-			dstore := sqlds.NewDatastore(db, sqlds.NewQueriesForTable(dhtTableName))
-
-			kadDHT, err = NewDHTWithDatastore(ctx, dstore, h)
+			kadDHT, err = NewDHTWithDatastore(ctx, dhtStore, h)
 			if err != nil {
 				log.WithField("error", err).Fatal("could not create DHT")
 			}
@@ -220,6 +247,8 @@ func main() {
 			log.WithField("error", err).Fatal("could not create peerStore")
 		}
 
+		historyStore = sqlds.NewDatastore(db, sqlds.NewQueriesForTable(historyTableName))
+
 	default:
 		log.Fatalf("invalid datastore configured: %s. Expected either %s or %s", config.DataStoreType, leveldbDataStore, sqlDataStore)
 
@@ -263,8 +292,34 @@ func main() {
 		log.WithField("error", err).Fatal("could not create host")
 	}
 
-	// Set up the notifee.
-	basicHost.Network().Notify(&notifee{})
+	// Configure banner.
+	banner := banner.New(ctx, banner.Config{
+		Host:                   basicHost,
+		Filters:                filters,
+		BandwidthCounter:       bandwidthCounter,
+		MaxBytesPerSecond:      config.MaxBytesPerSecond,
+		LogBandwidthUsageStats: true,
+	})
+
+	// Set up the peer directory, which keeps track of connects and disconnects.
+	history := newHistory(historyStore, config.HistoryRetention)
+	if err := history.indexByPeer(); err != nil {
+		log.WithError(err).Error("could not index peer history by peer")
+	}
+	go history.continuouslyPruneHistory(ctx)
+	knownRendezvousPoints := []string{}
+	if config.KnownRendezvousPoints != "" {
+		knownRendezvousPoints = strings.Split(config.KnownRendezvousPoints, ",")
+	}
+	directory := newPeerDirectory(basicHost, banner, bandwidthCounter, dhtStore, history, knownRendezvousPoints)
+	basicHost.Network().Notify(directory)
+
+	// Enable the rendezvous server.
+	if config.EnableRendezvousServer {
+		rendezvousServer := rendezvous.NewServer(ctx, 0)
+		basicHost.SetStreamHandler(rendezvous.ProtocolID, rendezvousServer.HandleStream)
+		directory.rendezvousServer = rendezvousServer
+	}
 
 	// Enable AutoNAT service.
 	if _, err := autonat.NewAutoNATService(ctx, basicHost); err != nil {
@@ -276,15 +331,6 @@ func main() {
 		log.WithField("error", err).Fatal("could not bootstrap DHT")
 	}
 
-	// Configure banner.
-	banner := banner.New(ctx, banner.Config{
-		Host:                   basicHost,
-		Filters:                filters,
-		BandwidthCounter:       bandwidthCounter,
-		MaxBytesPerSecond:      config.MaxBytesPerSecond,
-		LogBandwidthUsageStats: true,
-	})
-
 	if config.UseBootstrapList {
 		bootstrapList := p2p.DefaultBootstrapList
 		if config.BootstrapList != "" {
@@ -316,43 +362,18 @@ func main() {
 		"config": config,
 	}).Info("started bootstrap node")
 
+	if config.HTTPAPIAddr != "" {
+		go func() {
+			if err := serveAPI(config.HTTPAPIAddr, directory); err != nil {
+				log.WithField("error", err).Error("HTTP API stopped")
+			}
+		}()
+	}
+
 	// Sleep until stopped
 	select {}
 }
 
-// notifee receives notifications for network-related events.
-type notifee struct{}
-
-var _ p2pnet.Notifiee = &notifee{}
-
-// Listen is called when network starts listening on an addr
-func (n *notifee) Listen(p2pnet.Network, ma.Multiaddr) {}
-
-// ListenClose is called when network stops listening on an addr
-func (n *notifee) ListenClose(p2pnet.Network, ma.Multiaddr) {}
-
-// Connected is called when a connection opened
-func (n *notifee) Connected(network p2pnet.Network, conn p2pnet.Conn) {
-	log.WithFields(map[string]interface{}{
-		"remotePeerID":       conn.RemotePeer(),
-		"remoteMultiaddress": conn.RemoteMultiaddr(),
-	}).Info("connected to peer")
-}
-
-// Disconnected is called when a connection closed
-func (n *notifee) Disconnected(network p2pnet.Network, conn p2pnet.Conn) {
-	log.WithFields(map[string]interface{}{
-		"remotePeerID":       conn.RemotePeer(),
-		"remoteMultiaddress": conn.RemoteMultiaddr(),
-	}).Info("disconnected from peer")
-}
-
-// OpenedStream is called when a stream opened
-func (n *notifee) OpenedStream(network p2pnet.Network, stream p2pnet.Stream) {}
-
-// ClosedStream is called when a stream closed
-func (n *notifee) ClosedStream(network p2pnet.Network, stream p2pnet.Stream) {}
-
 func newAddrsFactory(advertiseAddrs []ma.Multiaddr) func([]ma.Multiaddr) []ma.Multiaddr {
 	return func([]ma.Multiaddr) []ma.Multiaddr {
 		return advertiseAddrs
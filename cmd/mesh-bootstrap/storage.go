@@ -19,6 +19,7 @@ import (
 const (
 	dhtTableName       = "dhtkv"
 	peerStoreTableName = "peerStore"
+	historyTableName   = "peerHistory"
 )
 
 func getPrivateKeyPath(config Config) string {
@@ -33,6 +34,10 @@ func getPeerstoreDir(config Config) string {
 	return filepath.Join(config.LevelDBDataDir, "p2p", "peerstore")
 }
 
+func getHistoryDir(config Config) string {
+	return filepath.Join(config.LevelDBDataDir, "p2p", "history")
+}
+
 func getSQLDatabase(config Config) (*sql.DB, error) {
 	// Currently we only support the postgres driver.
 	if config.SQLDBEngine != "postgres" {
@@ -53,6 +58,7 @@ func prepareSQLDatabase(db *sql.DB) error {
 	createTableString := "CREATE TABLE IF NOT EXISTS %s (key TEXT NOT NULL UNIQUE, data BYTEA NOT NULL)"
 	createDHTTable := fmt.Sprintf(createTableString, dhtTableName)
 	createPeerStoreTable := fmt.Sprintf(createTableString, peerStoreTableName)
+	createHistoryTable := fmt.Sprintf(createTableString, historyTableName)
 
 	_, err := db.Exec(createDHTTable)
 	if err != nil {
@@ -64,6 +70,11 @@ func prepareSQLDatabase(db *sql.DB) error {
 		return err
 	}
 
+	_, err = db.Exec(createHistoryTable)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -0,0 +1,457 @@
+// +build !js
+
+package main
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/0xProject/0x-mesh/common/types"
+	"github.com/0xProject/0x-mesh/ethereum"
+	"github.com/0xProject/0x-mesh/orderfilter"
+	"github.com/0xProject/0x-mesh/rpc"
+	"github.com/0xProject/0x-mesh/zeroex"
+	"github.com/0xProject/0x-mesh/zeroex/ordervalidator"
+	"github.com/ethereum/go-ethereum/common"
+	ethrpc "github.com/ethereum/go-ethereum/rpc"
+	lru "github.com/hashicorp/golang-lru"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// knownOrdersCacheSize is the number of order hashes per node that are
+	// remembered in order to avoid forwarding orders the node already has.
+	knownOrdersCacheSize = 100000
+	// routeQueueSize is the number of orders which can be queued for a route
+	// before the subscription to the source node is blocked.
+	routeQueueSize = 10000
+	// minBackoff and maxBackoff are the minimum and maximum time to wait before
+	// reconnecting to a node or retrying to send orders.
+	minBackoff = time.Second
+	maxBackoff = time.Minute
+)
+
+var errNotConnected = errors.New("not connected")
+
+type bridge struct {
+	startedAt time.Time
+	nodes     []*node
+	routes    []*route
+}
+
+// node is a Mesh node which is part of the bridge. The connection to the node
+// is opened lazily and reopened after it failed.
+type node struct {
+	name       string
+	rpcAddress string
+	// known contains the hashes of orders which the node is known to have,
+	// either because we received them from the node or because we forwarded
+	// them to it.
+	known   *lru.Cache
+	mut     sync.Mutex
+	client  *rpc.Client
+	chainID int
+	stats   nodeStats
+}
+
+// route forwards orders from one node to another.
+type route struct {
+	from      *node
+	to        *node
+	pinned    bool
+	rawFilter string
+	filter    *orderfilter.Filter
+	queue     chan *zeroex.OrderEvent
+	stats     routeStats
+}
+
+func newBridge(config *bridgeConfig) (*bridge, error) {
+	b := &bridge{
+		startedAt: time.Now(),
+	}
+	nodesByName := map[string]*node{}
+	for _, nodeConfig := range config.Nodes {
+		known, err := lru.New(knownOrdersCacheSize)
+		if err != nil {
+			return nil, err
+		}
+		node := &node{
+			name:       nodeConfig.Name,
+			rpcAddress: nodeConfig.RPCAddress,
+			known:      known,
+			stats: nodeStats{
+				Name:       nodeConfig.Name,
+				RPCAddress: nodeConfig.RPCAddress,
+			},
+		}
+		b.nodes = append(b.nodes, node)
+		nodesByName[node.name] = node
+	}
+	for _, routeConfig := range config.Routes {
+		pinned := true
+		if routeConfig.Pinned != nil {
+			pinned = *routeConfig.Pinned
+		}
+		route := &route{
+			from:      nodesByName[routeConfig.From],
+			to:        nodesByName[routeConfig.To],
+			pinned:    pinned,
+			rawFilter: string(routeConfig.CustomOrderFilter),
+			queue:     make(chan *zeroex.OrderEvent, routeQueueSize),
+			stats: routeStats{
+				From:              routeConfig.From,
+				To:                routeConfig.To,
+				Pinned:            pinned,
+				CustomOrderFilter: string(routeConfig.CustomOrderFilter),
+			},
+		}
+		b.routes = append(b.routes, route)
+	}
+	return b, nil
+}
+
+// run forwards orders until the context is canceled.
+func (b *bridge) run(ctx context.Context) {
+	wg := &sync.WaitGroup{}
+	for _, r := range b.routes {
+		wg.Add(1)
+		go func(r *route) {
+			defer wg.Done()
+			r.run(ctx)
+		}(r)
+	}
+	for _, source := range b.nodes {
+		outgoing := []*route{}
+		for _, route := range b.routes {
+			if route.from == source {
+				outgoing = append(outgoing, route)
+			}
+		}
+		if len(outgoing) == 0 {
+			continue
+		}
+		wg.Add(1)
+		go func(source *node, outgoing []*route) {
+			defer wg.Done()
+			runSource(ctx, source, outgoing)
+		}(source, outgoing)
+	}
+	wg.Wait()
+}
+
+// getClient returns the client for the node. It connects to the node if
+// there is no open connection.
+func (n *node) getClient() (*rpc.Client, int, error) {
+	n.mut.Lock()
+	defer n.mut.Unlock()
+	if n.client != nil {
+		return n.client, n.chainID, nil
+	}
+	client, err := rpc.NewClient(n.rpcAddress)
+	if err != nil {
+		n.recordError(err)
+		return nil, 0, err
+	}
+	stats, err := client.GetStats()
+	if err != nil {
+		client.Close()
+		n.recordError(err)
+		return nil, 0, err
+	}
+	n.client = client
+	n.chainID = stats.EthereumChainID
+	statsMut.Lock()
+	n.stats.Connected = true
+	n.stats.Connects++
+	statsMut.Unlock()
+	log.WithFields(log.Fields{
+		"node":  n.name,
+		"stats": stats,
+	}).Info("connected to node")
+	return client, n.chainID, nil
+}
+
+// disconnect closes the given client if it is the current client of the
+// node. The next call to getClient opens a new connection.
+func (n *node) disconnect(client *rpc.Client, err error) {
+	n.mut.Lock()
+	defer n.mut.Unlock()
+	n.recordError(err)
+	if n.client != client {
+		return
+	}
+	n.client.Close()
+	n.client = nil
+	statsMut.Lock()
+	n.stats.Connected = false
+	statsMut.Unlock()
+	log.WithError(err).WithField("node", n.name).Warn("disconnected from node")
+}
+
+func (n *node) recordError(err error) {
+	statsMut.Lock()
+	defer statsMut.Unlock()
+	n.stats.LastError = err.Error()
+	n.stats.LastErrorAt = time.Now()
+}
+
+// runSource subscribes to new orders on the source node and queues them for
+// all outgoing routes. It resubscribes with backoff if the subscription fails.
+func runSource(ctx context.Context, source *node, outgoing []*route) {
+	backoff := newBackoff()
+	for {
+		err := subscribe(ctx, source, outgoing, backoff)
+		if ctx.Err() != nil {
+			return
+		}
+		log.WithError(err).WithField("node", source.name).Warn("order subscription failed")
+		if !backoff.wait(ctx) {
+			return
+		}
+	}
+}
+
+// subscribe queues new orders from the source node until the subscription
+// fails or the context is canceled.
+func subscribe(ctx context.Context, source *node, outgoing []*route, backoff *backoff) error {
+	client, _, err := source.getClient()
+	if err != nil {
+		return err
+	}
+	orderEventsChan := make(chan []*zeroex.OrderEvent, routeQueueSize)
+	subscription, err := client.SubscribeToOrders(ctx, orderEventsChan)
+	if err != nil {
+		source.disconnect(client, err)
+		return err
+	}
+	defer subscription.Unsubscribe()
+	backoff.reset()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-subscription.Err():
+			if err == nil {
+				err = errNotConnected
+			}
+			source.disconnect(client, err)
+			return err
+		case orderEvents := <-orderEventsChan:
+			for _, orderEvent := range orderEvents {
+				if orderEvent.EndState != zeroex.ESOrderAdded {
+					continue
+				}
+				source.known.Add(orderEvent.OrderHash, struct{}{})
+				for _, route := range outgoing {
+					select {
+					case <-ctx.Done():
+						return ctx.Err()
+					case route.queue <- orderEvent:
+					}
+				}
+			}
+		}
+	}
+}
+
+// run forwards batches of queued orders to the destination node until the
+// context is canceled. The filter of the route is set up before any orders are
+// consumed, so that orders are not dropped while the source node is
+// unreachable.
+func (r *route) run(ctx context.Context) {
+	if !r.setupFilter(ctx) {
+		return
+	}
+	for {
+		batch, ok := r.receiveBatch(ctx)
+		if !ok {
+			return
+		}
+		signedOrders, orderHashes := r.prepare(batch)
+		if len(signedOrders) == 0 {
+			continue
+		}
+		if !r.send(ctx, signedOrders, orderHashes) {
+			return
+		}
+	}
+}
+
+// receiveBatch waits for up to maxReceiveBatch queued orders. It returns
+// false if the context was canceled.
+func (r *route) receiveBatch(ctx context.Context) ([]*zeroex.OrderEvent, bool) {
+	batch := []*zeroex.OrderEvent{}
+	timeoutChan := time.After(receiveTimeout)
+	for len(batch) < maxReceiveBatch {
+		select {
+		case <-ctx.Done():
+			return nil, false
+		case <-timeoutChan:
+			return batch, true
+		case orderEvent := <-r.queue:
+			batch = append(batch, orderEvent)
+		}
+	}
+	return batch, true
+}
+
+// prepare removes orders which don't match the filter of the route or which
+// the destination node already has. Since the filter is set up before the
+// route starts, a match error means the order itself can't be matched, so the
+// order is dropped as filtered.
+func (r *route) prepare(batch []*zeroex.OrderEvent) ([]*zeroex.SignedOrder, []common.Hash) {
+	signedOrders := []*zeroex.SignedOrder{}
+	orderHashes := []common.Hash{}
+	var filtered, duplicate uint64
+	for _, orderEvent := range batch {
+		if r.to.known.Contains(orderEvent.OrderHash) {
+			duplicate++
+			continue
+		}
+		matches, err := r.matchOrder(orderEvent.SignedOrder)
+		if err != nil {
+			log.WithError(err).WithField("orderHash", orderEvent.OrderHash.Hex()).Error("could not match order against route filter")
+		}
+		if !matches {
+			filtered++
+			continue
+		}
+		signedOrders = append(signedOrders, orderEvent.SignedOrder)
+		orderHashes = append(orderHashes, orderEvent.OrderHash)
+	}
+	statsMut.Lock()
+	r.stats.OrdersReceived += uint64(len(batch))
+	r.stats.OrdersFiltered += filtered
+	r.stats.OrdersDuplicate += duplicate
+	statsMut.Unlock()
+	return signedOrders, orderHashes
+}
+
+// setupFilter creates the filter of the route, which depends on the chain ID
+// of the source node. It retries with backoff until it succeeds and returns
+// false if the context was canceled.
+func (r *route) setupFilter(ctx context.Context) bool {
+	if r.rawFilter == "" {
+		return true
+	}
+	backoff := newBackoff()
+	for {
+		filter, err := r.newFilter()
+		if err == nil {
+			r.filter = filter
+			return true
+		}
+		log.WithError(err).WithFields(log.Fields{
+			"from": r.from.name,
+			"to":   r.to.name,
+		}).Warn("could not set up route filter; retrying")
+		if !backoff.wait(ctx) {
+			return false
+		}
+	}
+}
+
+func (r *route) newFilter() (*orderfilter.Filter, error) {
+	_, chainID, err := r.from.getClient()
+	if err != nil {
+		return nil, err
+	}
+	contractAddresses, err := ethereum.NewContractAddressesForChainID(chainID)
+	if err != nil {
+		return nil, err
+	}
+	return orderfilter.New(chainID, r.rawFilter, contractAddresses)
+}
+
+// matchOrder returns true if the order matches the filter of the route.
+func (r *route) matchOrder(signedOrder *zeroex.SignedOrder) (bool, error) {
+	if r.filter == nil {
+		return true, nil
+	}
+	return r.filter.MatchOrder(signedOrder)
+}
+
+// send adds the orders to the destination node. If the node cannot be reached,
+// it retries with backoff until it succeeds. If the node returns an error, the
+// batch is dropped, since sending it again would most likely fail again. It
+// returns false if the context was canceled.
+func (r *route) send(ctx context.Context, signedOrders []*zeroex.SignedOrder, orderHashes []common.Hash) bool {
+	logger := log.WithFields(log.Fields{
+		"from": r.from.name,
+		"to":   r.to.name,
+	})
+	backoff := newBackoff()
+	for {
+		client, _, err := r.to.getClient()
+		if err == nil {
+			var validationResults *ordervalidator.ValidationResults
+			validationResults, err = client.AddOrders(signedOrders, types.AddOrdersOpts{Pinned: r.pinned})
+			if err == nil {
+				// Rejected orders are also marked as known, since sending them
+				// again would not change the outcome.
+				for _, orderHash := range orderHashes {
+					r.to.known.Add(orderHash, struct{}{})
+				}
+				statsMut.Lock()
+				r.stats.OrdersForwarded += uint64(len(signedOrders))
+				r.stats.OrdersAccepted += uint64(len(validationResults.Accepted))
+				r.stats.OrdersRejected += uint64(len(validationResults.Rejected))
+				r.stats.LastForwardedAt = time.Now()
+				statsMut.Unlock()
+				logger.WithFields(log.Fields{
+					"numSent":     len(signedOrders),
+					"numAccepted": len(validationResults.Accepted),
+					"numRejected": len(validationResults.Rejected),
+				}).Info("Finished bridging orders")
+				return true
+			}
+			if _, ok := err.(ethrpc.Error); ok {
+				// The node received the batch but returned an error.
+				r.to.recordError(err)
+				statsMut.Lock()
+				r.stats.BatchesDropped++
+				statsMut.Unlock()
+				logger.WithError(err).WithField("numOrders", len(signedOrders)).Error("destination node could not add orders; dropping them")
+				return true
+			}
+			r.to.disconnect(client, err)
+		}
+		statsMut.Lock()
+		r.stats.SendErrors++
+		statsMut.Unlock()
+		logger.WithError(err).Warn("could not forward orders; retrying")
+		if !backoff.wait(ctx) {
+			return false
+		}
+	}
+}
+
+// backoff implements exponential backoff between minBackoff and maxBackoff.
+type backoff struct {
+	next time.Duration
+}
+
+func newBackoff() *backoff {
+	return &backoff{next: minBackoff}
+}
+
+// wait waits for the current backoff duration and doubles it. It returns
+// false if the context was canceled.
+func (b *backoff) wait(ctx context.Context) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case <-time.After(b.next):
+	}
+	b.next *= 2
+	if b.next > maxBackoff {
+		b.next = maxBackoff
+	}
+	return true
+}
+
+func (b *backoff) reset() {
+	b.next = minBackoff
+}
@@ -0,0 +1,144 @@
+// +build !js
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/0xProject/0x-mesh/ethereum"
+	"github.com/0xProject/0x-mesh/orderfilter"
+)
+
+// bridgeConfig describes the nodes to bridge and the routes that orders take
+// between them. It is read from the file at CONFIG_PATH.
+//
+// Example:
+//
+//    {
+//        "nodes": [
+//            {"name": "private", "rpcAddress": "ws://localhost:60557"},
+//            {"name": "public", "rpcAddress": "ws://mesh.example.com:60557"}
+//        ],
+//        "routes": [
+//            {"from": "private", "to": "public", "pinned": false},
+//            {
+//                "from": "public",
+//                "to": "private",
+//                "customOrderFilter": {"properties": {"makerAssetData": {"pattern": ".*c02aaa39b223fe8d0a0e5c4f27ead9083c756cc2.*"}}}
+//            }
+//        ]
+//    }
+//
+type bridgeConfig struct {
+	Nodes []nodeConfig `json:"nodes"`
+	// Routes are the directions in which orders are forwarded. If empty,
+	// orders are forwarded between all nodes in both directions.
+	Routes []routeConfig `json:"routes"`
+}
+
+type nodeConfig struct {
+	// Name is used to refer to the node in routes, logs and stats.
+	Name string `json:"name"`
+	// RPCAddress is the WebSocket address of the JSON RPC endpoint of the node.
+	RPCAddress string `json:"rpcAddress"`
+}
+
+type routeConfig struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	// CustomOrderFilter is an optional custom order schema in the same format
+	// as the CUSTOM_ORDER_FILTER of Mesh nodes (see
+	// docs/custom_order_filters.md). Only orders which match it are
+	// forwarded.
+	CustomOrderFilter json.RawMessage `json:"customOrderFilter,omitempty"`
+	// Pinned determines whether the forwarded orders are pinned on the
+	// receiving node. Defaults to true.
+	Pinned *bool `json:"pinned,omitempty"`
+}
+
+// loadConfig reads the bridge config from the file at the given path.
+func loadConfig(path string) (*bridgeConfig, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	config := &bridgeConfig{}
+	if err := json.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("could not parse bridge config: %s", err.Error())
+	}
+	return config, nil
+}
+
+// newTwoNodeConfig returns the config for bridging two nodes in both
+// directions. It is used when FIRST_WS_RPC_ADDRESS and SECOND_WS_RPC_ADDRESS
+// are set instead of CONFIG_PATH.
+func newTwoNodeConfig(firstRPCAddress, secondRPCAddress string) *bridgeConfig {
+	return &bridgeConfig{
+		Nodes: []nodeConfig{
+			{Name: firstWSRPCAddressLabel, RPCAddress: firstRPCAddress},
+			{Name: secondWSRPCAddressLabel, RPCAddress: secondRPCAddress},
+		},
+	}
+}
+
+// validate checks the config and fills in the default routes.
+func (c *bridgeConfig) validate() error {
+	if len(c.Nodes) < 2 {
+		return errors.New("at least two nodes are required")
+	}
+	names := map[string]struct{}{}
+	for _, node := range c.Nodes {
+		if node.Name == "" {
+			return errors.New("every node must have a name")
+		}
+		if node.RPCAddress == "" {
+			return fmt.Errorf("node %q has no rpcAddress", node.Name)
+		}
+		if _, found := names[node.Name]; found {
+			return fmt.Errorf("duplicate node name: %q", node.Name)
+		}
+		names[node.Name] = struct{}{}
+	}
+	if len(c.Routes) == 0 {
+		for _, from := range c.Nodes {
+			for _, to := range c.Nodes {
+				if from.Name != to.Name {
+					c.Routes = append(c.Routes, routeConfig{From: from.Name, To: to.Name})
+				}
+			}
+		}
+	}
+	routes := map[string]struct{}{}
+	for _, route := range c.Routes {
+		if _, found := names[route.From]; !found {
+			return fmt.Errorf("route from unknown node: %q", route.From)
+		}
+		if _, found := names[route.To]; !found {
+			return fmt.Errorf("route to unknown node: %q", route.To)
+		}
+		if route.From == route.To {
+			return fmt.Errorf("route from node %q to itself", route.From)
+		}
+		key := route.From + "->" + route.To
+		if _, found := routes[key]; found {
+			return fmt.Errorf("duplicate route from %q to %q", route.From, route.To)
+		}
+		routes[key] = struct{}{}
+		if len(route.CustomOrderFilter) != 0 {
+			// The filter is created for the chain of the nodes once we are
+			// connected. Compile it for mainnet here so that invalid filters
+			// are detected on startup.
+			contractAddresses, err := ethereum.NewContractAddressesForChainID(1)
+			if err != nil {
+				return err
+			}
+			if _, err := orderfilter.New(1, string(route.CustomOrderFilter), contractAddresses); err != nil {
+				return fmt.Errorf("invalid customOrderFilter for route from %q to %q: %s", route.From, route.To, err.Error())
+			}
+		}
+	}
+	return nil
+}
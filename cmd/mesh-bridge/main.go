@@ -1,17 +1,20 @@
 // +build !js
 
-// mesh-bridge is a short program that bridges two Mesh nodes. This is useful in cases where
+// mesh-bridge is a short program that bridges Mesh nodes. This is useful in cases where
 // we introduce a network-level breaking change but still want the liquidity from one network
-//to flow to another
+// to flow to another, or to connect a private Mesh network to the public one.
+//
+// The nodes and the routes that orders take between them are read from the
+// JSON file at CONFIG_PATH (see bridgeConfig). Alternatively,
+// FIRST_WS_RPC_ADDRESS and SECOND_WS_RPC_ADDRESS can be set to bridge two nodes
+// in both directions. Stats are served as JSON at /stats on
+// STATS_HTTP_ADDRESS.
 package main
 
 import (
 	"context"
 	"time"
 
-	"github.com/0xProject/0x-mesh/rpc"
-	"github.com/0xProject/0x-mesh/zeroex"
-	ethrpc "github.com/ethereum/go-ethereum/rpc"
 	"github.com/plaid/go-envvar/envvar"
 	log "github.com/sirupsen/logrus"
 )
@@ -21,13 +24,18 @@ const (
 	secondWSRPCAddressLabel = "SecondWSRPCAddress"
 	maxReceiveBatch         = 100
 	receiveTimeout          = 2 * time.Second
-	tenThousand             = 10000
 )
 
 type clientEnvVars struct {
-	FirstWSRPCAddress  string `envvar:"FIRST_WS_RPC_ADDRESS"`
-	SecondWSRPCAddress string `envvar:"SECOND_WS_RPC_ADDRESS"`
-	Verbosity          int    `envvar:"VERBOSITY"`
+	// ConfigPath is the path to the JSON bridge config. If empty,
+	// FirstWSRPCAddress and SecondWSRPCAddress are bridged in both directions.
+	ConfigPath         string `envvar:"CONFIG_PATH" default:""`
+	FirstWSRPCAddress  string `envvar:"FIRST_WS_RPC_ADDRESS" default:""`
+	SecondWSRPCAddress string `envvar:"SECOND_WS_RPC_ADDRESS" default:""`
+	// StatsHTTPAddress is the address on which the bridge stats are served.
+	// Set it to an empty string to disable the stats endpoint.
+	StatsHTTPAddress string `envvar:"STATS_HTTP_ADDRESS" default:"localhost:60600"`
+	Verbosity        int    `envvar:"VERBOSITY"`
 }
 
 func main() {
@@ -38,87 +46,30 @@ func main() {
 
 	log.SetLevel(log.Level(env.Verbosity))
 
-	firstClient, err := rpc.NewClient(env.FirstWSRPCAddress)
-	if err != nil {
-		log.WithError(err).Fatal("could not create client")
-	}
-	stats, err := firstClient.GetStats()
-	if err != nil {
-		log.Fatal(err)
-	}
-	log.WithField("stats", stats).Info("Spun up first client")
-
-	secondClient, err := rpc.NewClient(env.SecondWSRPCAddress)
-	if err != nil {
-		log.WithError(err).Fatal("could not create client")
+	var config *bridgeConfig
+	if env.ConfigPath != "" {
+		var err error
+		config, err = loadConfig(env.ConfigPath)
+		if err != nil {
+			log.WithError(err).Fatal("could not load bridge config")
+		}
+	} else {
+		config = newTwoNodeConfig(env.FirstWSRPCAddress, env.SecondWSRPCAddress)
 	}
-	stats, err = secondClient.GetStats()
-	if err != nil {
-		log.Fatal(err)
+	if err := config.validate(); err != nil {
+		log.WithError(err).Fatal("invalid bridge config")
 	}
-	log.WithField("stats", stats).Info("Spun up second client")
-
-	go func() {
-		pipeOrders(secondClient, firstClient, secondWSRPCAddressLabel, firstWSRPCAddressLabel)
-	}()
-	pipeOrders(firstClient, secondClient, firstWSRPCAddressLabel, secondWSRPCAddressLabel)
-}
 
-func pipeOrders(inClient, outClient *rpc.Client, inLabel, outLabel string) {
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-	orderEventsChan := make(chan []*zeroex.OrderEvent, tenThousand)
-	clientSubscription, err := inClient.SubscribeToOrders(ctx, orderEventsChan)
+	bridge, err := newBridge(config)
 	if err != nil {
-		log.WithError(err).Fatal("Couldn't set up OrderStream subscription")
+		log.WithError(err).Fatal("could not create bridge")
 	}
-	defer clientSubscription.Unsubscribe()
-	for {
-		incomingSignedOrders, err := receiveBatch(orderEventsChan, clientSubscription, inLabel, outLabel)
-		if err != nil {
-			log.Fatal(err)
-		}
-		if len(incomingSignedOrders) == 0 {
-			continue
-		}
-		validationResults, err := outClient.AddOrders(incomingSignedOrders)
-		if err != nil {
-			log.Fatal(err)
-		}
-		log.WithFields(log.Fields{
-			"from":        inLabel,
-			"to":          outLabel,
-			"numSent":     len(incomingSignedOrders),
-			"numAccepted": len(validationResults.Accepted),
-			"numRejected": len(validationResults.Rejected),
-		}).Info("Finished bridging orders")
-	}
-}
-
-func receiveBatch(inChan chan []*zeroex.OrderEvent, subscription *ethrpc.ClientSubscription, inLabel, outLabel string) ([]*zeroex.SignedOrder, error) {
-	signedOrders := []*zeroex.SignedOrder{}
-	timeoutChan := time.After(receiveTimeout)
-	for {
-		if len(signedOrders) >= maxReceiveBatch {
-			return signedOrders, nil
-		}
-		select {
-		case <-timeoutChan:
-			return signedOrders, nil
-		case orderEvents := <-inChan:
-			for _, orderEvent := range orderEvents {
-				if orderEvent.EndState != zeroex.ESOrderAdded {
-					continue
-				}
-				log.WithFields(log.Fields{
-					"from":      inLabel,
-					"to":        outLabel,
-					"orderHash": orderEvent.OrderHash.Hex(),
-				}).Info("Found new order over bridge")
-				signedOrders = append(signedOrders, orderEvent.SignedOrder)
+	if env.StatsHTTPAddress != "" {
+		go func() {
+			if err := bridge.serveStats(env.StatsHTTPAddress); err != nil {
+				log.WithError(err).Error("stats HTTP server stopped")
 			}
-		case err := <-subscription.Err():
-			log.Fatal(err)
-		}
+		}()
 	}
+	bridge.run(context.Background())
 }
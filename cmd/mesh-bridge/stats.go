@@ -0,0 +1,97 @@
+// +build !js
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// nodeStats are the stats for a single node. They are served on the stats
+// HTTP endpoint.
+type nodeStats struct {
+	Name        string    `json:"name"`
+	RPCAddress  string    `json:"rpcAddress"`
+	Connected   bool      `json:"connected"`
+	Connects    uint64    `json:"connects"`
+	LastError   string    `json:"lastError,omitempty"`
+	LastErrorAt time.Time `json:"lastErrorAt"`
+}
+
+// routeStats are the stats for a single route. They are served on the stats
+// HTTP endpoint.
+type routeStats struct {
+	From              string `json:"from"`
+	To                string `json:"to"`
+	Pinned            bool   `json:"pinned"`
+	CustomOrderFilter string `json:"customOrderFilter,omitempty"`
+	// OrdersReceived is the number of new orders received from the source
+	// node.
+	OrdersReceived uint64 `json:"ordersReceived"`
+	// OrdersFiltered is the number of orders which did not match the filter of
+	// the route.
+	OrdersFiltered uint64 `json:"ordersFiltered"`
+	// OrdersDuplicate is the number of orders which were not forwarded because
+	// the destination node already has them.
+	OrdersDuplicate uint64 `json:"ordersDuplicate"`
+	// OrdersForwarded is the number of orders sent to the destination node.
+	OrdersForwarded uint64 `json:"ordersForwarded"`
+	// OrdersAccepted and OrdersRejected are the number of forwarded orders the
+	// destination node accepted and rejected.
+	OrdersAccepted uint64 `json:"ordersAccepted"`
+	OrdersRejected uint64 `json:"ordersRejected"`
+	// SendErrors is the number of times the destination node could not be
+	// reached while forwarding orders.
+	SendErrors uint64 `json:"sendErrors"`
+	// BatchesDropped is the number of batches of orders which were dropped
+	// because the destination node returned an error for them.
+	BatchesDropped  uint64    `json:"batchesDropped"`
+	LastForwardedAt time.Time `json:"lastForwardedAt"`
+}
+
+// bridgeStats is the response of the stats HTTP endpoint.
+type bridgeStats struct {
+	StartedAt time.Time     `json:"startedAt"`
+	Nodes     []*nodeStats  `json:"nodes"`
+	Routes    []*routeStats `json:"routes"`
+}
+
+// statsMut protects the stats of all nodes and routes.
+var statsMut sync.Mutex
+
+// stats returns a snapshot of the stats of all nodes and routes.
+func (b *bridge) stats() *bridgeStats {
+	statsMut.Lock()
+	defer statsMut.Unlock()
+	result := &bridgeStats{
+		StartedAt: b.startedAt,
+		Nodes:     []*nodeStats{},
+		Routes:    []*routeStats{},
+	}
+	for _, node := range b.nodes {
+		nodeStats := node.stats
+		result.Nodes = append(result.Nodes, &nodeStats)
+	}
+	for _, route := range b.routes {
+		routeStats := route.stats
+		result.Routes = append(result.Routes, &routeStats)
+	}
+	return result
+}
+
+// serveStats serves the bridge stats as JSON on /stats at the given address.
+func (b *bridge) serveStats(addr string) error {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/stats", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(b.stats()); err != nil {
+			log.WithError(err).Error("could not encode stats")
+		}
+	})
+	log.WithField("address", addr).Info("serving bridge stats")
+	return http.ListenAndServe(addr, mux)
+}
@@ -1,19 +1,55 @@
 // +build !js
 
-// mesh-keygen is a short program that can be used to generate private keys.
+// mesh-keygen is a short program that can be used to generate, encrypt and
+// rotate private keys.
 package main
 
 import (
+	"fmt"
 	"log"
 	"os"
+	"time"
 
 	"github.com/0xProject/0x-mesh/keys"
+	p2pcrypto "github.com/libp2p/go-libp2p-core/crypto"
+	"github.com/libp2p/go-libp2p-core/peer"
 	"github.com/plaid/go-envvar/envvar"
 )
 
+const (
+	// modeGenerate generates a new private key. It refuses to overwrite an
+	// existing key.
+	modeGenerate = "generate"
+	// modeEncrypt encrypts an existing private key with NEW_PASSPHRASE. If the
+	// key is already encrypted, it is decrypted with PASSPHRASE first, so this
+	// mode can also be used to change the passphrase.
+	modeEncrypt = "encrypt"
+	// modeRotate backs up the existing private key and replaces it with a new
+	// one. The new key is encrypted with NEW_PASSPHRASE if it is set.
+	modeRotate = "rotate"
+)
+
 type envVars struct {
 	// PrivateKeyPath is the path where the private key will be written.
 	PrivateKeyPath string `envvar:"PRIVATE_KEY_PATH" default:"0x_mesh/keys/privkey"`
+	// Mode is one of "generate", "encrypt" or "rotate".
+	Mode string `envvar:"MODE" default:"generate"`
+	// Passphrase is the passphrase of the existing private key, if it is
+	// encrypted.
+	Passphrase string `envvar:"PASSPHRASE" default:""`
+	// PassphraseFile is a file containing the passphrase of the existing private
+	// key. It is used if Passphrase is empty.
+	PassphraseFile string `envvar:"PASSPHRASE_FILE" default:""`
+	// NewPassphrase is the passphrase used to encrypt the written private key.
+	// In "generate" and "rotate" mode, the key is written unencrypted if no
+	// new passphrase is given.
+	NewPassphrase string `envvar:"NEW_PASSPHRASE" default:""`
+	// NewPassphraseFile is a file containing the new passphrase. It is used if
+	// NewPassphrase is empty.
+	NewPassphraseFile string `envvar:"NEW_PASSPHRASE_FILE" default:""`
+	// LightScrypt uses scrypt parameters which are faster to decrypt but less
+	// secure. This is useful for keys which are used in the browser.
+	LightScrypt bool `envvar:"LIGHT_SCRYPT" default:"false"`
 }
 
 func main() {
@@ -21,10 +57,74 @@ func main() {
 	if err := envvar.Parse(&env); err != nil {
 		log.Fatal(err)
 	}
-	if _, err := os.Stat(env.PrivateKeyPath); !os.IsNotExist(err) {
-		log.Fatalf("Key file: %s already exists. If you really want to overwrite it, delete the file and try again.", env.PrivateKeyPath)
+	passphrase, err := keys.ReadPassphrase(env.Passphrase, env.PassphraseFile)
+	if err != nil {
+		log.Fatal(err)
 	}
-	if _, err := keys.GenerateAndSavePrivateKey(env.PrivateKeyPath); err != nil {
+	newPassphrase, err := keys.ReadPassphrase(env.NewPassphrase, env.NewPassphraseFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	scryptN, scryptP := keys.StandardScryptN, keys.StandardScryptP
+	if env.LightScrypt {
+		scryptN, scryptP = keys.LightScryptN, keys.LightScryptP
+	}
+
+	switch env.Mode {
+	case modeGenerate:
+		if _, err := os.Stat(env.PrivateKeyPath); !os.IsNotExist(err) {
+			log.Fatalf("Key file: %s already exists. If you really want to overwrite it, delete the file and try again.", env.PrivateKeyPath)
+		}
+		privKey, err := generateKey(env.PrivateKeyPath, newPassphrase, scryptN, scryptP)
+		if err != nil {
+			log.Fatal(err)
+		}
+		logPeerID("Generated key for peer ID", privKey)
+	case modeEncrypt:
+		if newPassphrase == "" {
+			log.Fatal("NEW_PASSPHRASE or NEW_PASSPHRASE_FILE is required to encrypt a key")
+		}
+		privKey, err := keys.GetEncryptedPrivateKeyFromPath(env.PrivateKeyPath, passphrase)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := keys.SaveEncryptedPrivateKey(env.PrivateKeyPath, privKey, newPassphrase, scryptN, scryptP); err != nil {
+			log.Fatal(err)
+		}
+		logPeerID("Encrypted key for peer ID", privKey)
+	case modeRotate:
+		oldKey, err := keys.GetEncryptedPrivateKeyFromPath(env.PrivateKeyPath, passphrase)
+		if err != nil {
+			log.Fatal(err)
+		}
+		// Keep a copy of the old key, so that the rotation can be undone.
+		backupPath := fmt.Sprintf("%s.%d.bak", env.PrivateKeyPath, time.Now().Unix())
+		if err := os.Rename(env.PrivateKeyPath, backupPath); err != nil {
+			log.Fatal(err)
+		}
+		newKey, err := generateKey(env.PrivateKeyPath, newPassphrase, scryptN, scryptP)
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("Moved old key to %s", backupPath)
+		logPeerID("Old peer ID", oldKey)
+		logPeerID("New peer ID", newKey)
+	default:
+		log.Fatalf("Unknown mode: %q. Must be one of %q, %q or %q.", env.Mode, modeGenerate, modeEncrypt, modeRotate)
+	}
+}
+
+func generateKey(path string, passphrase string, scryptN, scryptP int) (p2pcrypto.PrivKey, error) {
+	if passphrase == "" {
+		return keys.GenerateAndSavePrivateKey(path)
+	}
+	return keys.GenerateAndSaveEncryptedPrivateKey(path, passphrase, scryptN, scryptP)
+}
+
+func logPeerID(message string, privKey p2pcrypto.PrivKey) {
+	peerID, err := peer.IDFromPrivateKey(privKey)
+	if err != nil {
 		log.Fatal(err)
 	}
+	log.Printf("%s: %s", message, peerID.Pretty())
 }
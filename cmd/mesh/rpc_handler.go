@@ -15,11 +15,15 @@ import (
 	"github.com/0xProject/0x-mesh/common/types"
 	"github.com/0xProject/0x-mesh/constants"
 	"github.com/0xProject/0x-mesh/core"
+	"github.com/0xProject/0x-mesh/p2p/banner"
 	"github.com/0xProject/0x-mesh/rpc"
 	"github.com/0xProject/0x-mesh/zeroex"
 	"github.com/0xProject/0x-mesh/zeroex/ordervalidator"
+	"github.com/ethereum/go-ethereum/common"
 	ethrpc "github.com/ethereum/go-ethereum/rpc"
+	"github.com/libp2p/go-libp2p-core/peer"
 	peerstore "github.com/libp2p/go-libp2p-peerstore"
+	ma "github.com/multiformats/go-multiaddr"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -128,6 +132,71 @@ func (handler *rpcHandler) AddOrders(signedOrdersRaw []*json.RawMessage, opts ty
 	return validationResults, nil
 }
 
+// CreateOrder is called when an RPC client calls CreateOrder.
+func (handler *rpcHandler) CreateOrder(params types.CreateOrderParams, opts types.AddOrdersOpts) (results *ordervalidator.ValidationResults, err error) {
+	log.WithFields(log.Fields{
+		"params": params,
+		"pinned": opts.Pinned,
+	}).Info("received CreateOrder request via RPC")
+	// Catch panics, log stack trace and return RPC error message
+	defer func() {
+		if r := recover(); r != nil {
+			internalErr, ok := r.(error)
+			if !ok {
+				// If r is not of type error, convert it.
+				internalErr = fmt.Errorf("Recovered from non-error: (%T) %v", r, r)
+			}
+			log.WithFields(log.Fields{
+				"error":      internalErr,
+				"method":     "CreateOrder",
+				"stackTrace": string(debug.Stack()),
+			}).Error("RPC method handler crashed")
+			err = errors.New("method handler crashed in CreateOrder RPC call (check logs for stack trace)")
+		}
+	}()
+	validationResults, err := handler.app.CreateOrder(handler.ctx, params, opts.Pinned)
+	if err != nil {
+		if _, ok := err.(core.InvalidCreateOrderParamsError); ok || err == core.ErrNoOrderSigner {
+			return nil, err
+		}
+		// We don't want to leak internal error details to the RPC client.
+		log.WithField("error", err.Error()).Error("internal error in CreateOrder RPC call")
+		return nil, constants.ErrInternal
+	}
+	return validationResults, nil
+}
+
+// AddOrdersV4 is called when an RPC client calls AddOrdersV4.
+func (handler *rpcHandler) AddOrdersV4(signedOrdersRaw []*json.RawMessage, opts types.AddOrdersOpts) (results *ordervalidator.ValidationResults, err error) {
+	log.WithFields(log.Fields{
+		"count":  len(signedOrdersRaw),
+		"pinned": opts.Pinned,
+	}).Info("received AddOrdersV4 request via RPC")
+	// Catch panics, log stack trace and return RPC error message
+	defer func() {
+		if r := recover(); r != nil {
+			internalErr, ok := r.(error)
+			if !ok {
+				// If r is not of type error, convert it.
+				internalErr = fmt.Errorf("Recovered from non-error: (%T) %v", r, r)
+			}
+			log.WithFields(log.Fields{
+				"error":      internalErr,
+				"method":     "AddOrdersV4",
+				"stackTrace": string(debug.Stack()),
+			}).Error("RPC method handler crashed")
+			err = errors.New("method handler crashed in AddOrdersV4 RPC call (check logs for stack trace)")
+		}
+	}()
+	validationResults, err := handler.app.AddOrdersV4(handler.ctx, signedOrdersRaw, opts.Pinned)
+	if err != nil {
+		// We don't want to leak internal error details to the RPC client.
+		log.WithField("error", err.Error()).Error("internal error in AddOrdersV4 RPC call")
+		return nil, constants.ErrInternal
+	}
+	return validationResults, nil
+}
+
 // AddPeer is called when an RPC client calls AddPeer,
 func (handler *rpcHandler) AddPeer(peerInfo peerstore.PeerInfo) (err error) {
 	log.Debug("received AddPeer request via RPC")
@@ -181,6 +250,391 @@ func (handler *rpcHandler) GetStats() (result *types.Stats, err error) {
 	return getStatsResponse, nil
 }
 
+// GetPeers is called when an RPC client calls GetPeers,
+func (handler *rpcHandler) GetPeers() (result []*types.PeerInfo, err error) {
+	log.Debug("received GetPeers request via RPC")
+	// Catch panics, log stack trace and return RPC error message
+	defer func() {
+		if r := recover(); r != nil {
+			internalErr, ok := r.(error)
+			if !ok {
+				// If r is not of type error, convert it.
+				internalErr = fmt.Errorf("Recovered from non-error: (%T) %v", r, r)
+			}
+			log.WithFields(log.Fields{
+				"error":      internalErr,
+				"method":     "GetPeers",
+				"stackTrace": string(debug.Stack()),
+			}).Error("RPC method handler crashed")
+			err = errors.New("method handler crashed in GetPeers RPC call (check logs for stack trace)")
+		}
+	}()
+	peers, err := handler.app.GetPeers()
+	if err != nil {
+		log.WithField("error", err.Error()).Error("internal error in GetPeers RPC call")
+		return nil, constants.ErrInternal
+	}
+	return peers, nil
+}
+
+// DisconnectPeer is called when an RPC client calls DisconnectPeer,
+func (handler *rpcHandler) DisconnectPeer(peerID peer.ID) (err error) {
+	log.WithField("peerID", peerID.String()).Debug("received DisconnectPeer request via RPC")
+	// Catch panics, log stack trace and return RPC error message
+	defer func() {
+		if r := recover(); r != nil {
+			internalErr, ok := r.(error)
+			if !ok {
+				// If r is not of type error, convert it.
+				internalErr = fmt.Errorf("Recovered from non-error: (%T) %v", r, r)
+			}
+			log.WithFields(log.Fields{
+				"error":      internalErr,
+				"method":     "DisconnectPeer",
+				"stackTrace": string(debug.Stack()),
+			}).Error("RPC method handler crashed")
+			err = errors.New("method handler crashed in DisconnectPeer RPC call (check logs for stack trace)")
+		}
+	}()
+	if err := handler.app.DisconnectPeer(peerID); err != nil {
+		log.WithField("error", err.Error()).Error("internal error in DisconnectPeer RPC call")
+		return constants.ErrInternal
+	}
+	return nil
+}
+
+// BanIP is called when an RPC client calls BanIP,
+func (handler *rpcHandler) BanIP(maddr ma.Multiaddr) (err error) {
+	log.WithField("maddr", maddr.String()).Debug("received BanIP request via RPC")
+	// Catch panics, log stack trace and return RPC error message
+	defer func() {
+		if r := recover(); r != nil {
+			internalErr, ok := r.(error)
+			if !ok {
+				// If r is not of type error, convert it.
+				internalErr = fmt.Errorf("Recovered from non-error: (%T) %v", r, r)
+			}
+			log.WithFields(log.Fields{
+				"error":      internalErr,
+				"method":     "BanIP",
+				"stackTrace": string(debug.Stack()),
+			}).Error("RPC method handler crashed")
+			err = errors.New("method handler crashed in BanIP RPC call (check logs for stack trace)")
+		}
+	}()
+	if err := handler.app.BanIP(maddr); err != nil {
+		if err == banner.ErrProtectedIP {
+			return err
+		}
+		log.WithField("error", err.Error()).Error("internal error in BanIP RPC call")
+		return constants.ErrInternal
+	}
+	return nil
+}
+
+// UnbanIP is called when an RPC client calls UnbanIP,
+func (handler *rpcHandler) UnbanIP(maddr ma.Multiaddr) (err error) {
+	log.WithField("maddr", maddr.String()).Debug("received UnbanIP request via RPC")
+	// Catch panics, log stack trace and return RPC error message
+	defer func() {
+		if r := recover(); r != nil {
+			internalErr, ok := r.(error)
+			if !ok {
+				// If r is not of type error, convert it.
+				internalErr = fmt.Errorf("Recovered from non-error: (%T) %v", r, r)
+			}
+			log.WithFields(log.Fields{
+				"error":      internalErr,
+				"method":     "UnbanIP",
+				"stackTrace": string(debug.Stack()),
+			}).Error("RPC method handler crashed")
+			err = errors.New("method handler crashed in UnbanIP RPC call (check logs for stack trace)")
+		}
+	}()
+	if err := handler.app.UnbanIP(maddr); err != nil {
+		log.WithField("error", err.Error()).Error("internal error in UnbanIP RPC call")
+		return constants.ErrInternal
+	}
+	return nil
+}
+
+// ProtectPeer is called when an RPC client calls ProtectPeer,
+func (handler *rpcHandler) ProtectPeer(peerID peer.ID) (err error) {
+	log.WithField("peerID", peerID.String()).Debug("received ProtectPeer request via RPC")
+	// Catch panics, log stack trace and return RPC error message
+	defer func() {
+		if r := recover(); r != nil {
+			internalErr, ok := r.(error)
+			if !ok {
+				// If r is not of type error, convert it.
+				internalErr = fmt.Errorf("Recovered from non-error: (%T) %v", r, r)
+			}
+			log.WithFields(log.Fields{
+				"error":      internalErr,
+				"method":     "ProtectPeer",
+				"stackTrace": string(debug.Stack()),
+			}).Error("RPC method handler crashed")
+			err = errors.New("method handler crashed in ProtectPeer RPC call (check logs for stack trace)")
+		}
+	}()
+	if err := handler.app.ProtectPeer(peerID); err != nil {
+		log.WithField("error", err.Error()).Error("internal error in ProtectPeer RPC call")
+		return constants.ErrInternal
+	}
+	return nil
+}
+
+// UnprotectPeer is called when an RPC client calls UnprotectPeer,
+func (handler *rpcHandler) UnprotectPeer(peerID peer.ID) (err error) {
+	log.WithField("peerID", peerID.String()).Debug("received UnprotectPeer request via RPC")
+	// Catch panics, log stack trace and return RPC error message
+	defer func() {
+		if r := recover(); r != nil {
+			internalErr, ok := r.(error)
+			if !ok {
+				// If r is not of type error, convert it.
+				internalErr = fmt.Errorf("Recovered from non-error: (%T) %v", r, r)
+			}
+			log.WithFields(log.Fields{
+				"error":      internalErr,
+				"method":     "UnprotectPeer",
+				"stackTrace": string(debug.Stack()),
+			}).Error("RPC method handler crashed")
+			err = errors.New("method handler crashed in UnprotectPeer RPC call (check logs for stack trace)")
+		}
+	}()
+	if err := handler.app.UnprotectPeer(peerID); err != nil {
+		log.WithField("error", err.Error()).Error("internal error in UnprotectPeer RPC call")
+		return constants.ErrInternal
+	}
+	return nil
+}
+
+// ProtectIP is called when an RPC client calls ProtectIP,
+func (handler *rpcHandler) ProtectIP(maddr ma.Multiaddr) (err error) {
+	log.WithField("maddr", maddr.String()).Debug("received ProtectIP request via RPC")
+	// Catch panics, log stack trace and return RPC error message
+	defer func() {
+		if r := recover(); r != nil {
+			internalErr, ok := r.(error)
+			if !ok {
+				// If r is not of type error, convert it.
+				internalErr = fmt.Errorf("Recovered from non-error: (%T) %v", r, r)
+			}
+			log.WithFields(log.Fields{
+				"error":      internalErr,
+				"method":     "ProtectIP",
+				"stackTrace": string(debug.Stack()),
+			}).Error("RPC method handler crashed")
+			err = errors.New("method handler crashed in ProtectIP RPC call (check logs for stack trace)")
+		}
+	}()
+	if err := handler.app.ProtectIP(maddr); err != nil {
+		log.WithField("error", err.Error()).Error("internal error in ProtectIP RPC call")
+		return constants.ErrInternal
+	}
+	return nil
+}
+
+// UnprotectIP is called when an RPC client calls UnprotectIP,
+func (handler *rpcHandler) UnprotectIP(maddr ma.Multiaddr) (err error) {
+	log.WithField("maddr", maddr.String()).Debug("received UnprotectIP request via RPC")
+	// Catch panics, log stack trace and return RPC error message
+	defer func() {
+		if r := recover(); r != nil {
+			internalErr, ok := r.(error)
+			if !ok {
+				// If r is not of type error, convert it.
+				internalErr = fmt.Errorf("Recovered from non-error: (%T) %v", r, r)
+			}
+			log.WithFields(log.Fields{
+				"error":      internalErr,
+				"method":     "UnprotectIP",
+				"stackTrace": string(debug.Stack()),
+			}).Error("RPC method handler crashed")
+			err = errors.New("method handler crashed in UnprotectIP RPC call (check logs for stack trace)")
+		}
+	}()
+	if err := handler.app.UnprotectIP(maddr); err != nil {
+		log.WithField("error", err.Error()).Error("internal error in UnprotectIP RPC call")
+		return constants.ErrInternal
+	}
+	return nil
+}
+
+// GetPeerReputations is called when an RPC client calls GetPeerReputations,
+func (handler *rpcHandler) GetPeerReputations(subject string) (result []*types.PeerReputation, err error) {
+	log.WithField("subject", subject).Debug("received GetPeerReputations request via RPC")
+	// Catch panics, log stack trace and return RPC error message
+	defer func() {
+		if r := recover(); r != nil {
+			internalErr, ok := r.(error)
+			if !ok {
+				// If r is not of type error, convert it.
+				internalErr = fmt.Errorf("Recovered from non-error: (%T) %v", r, r)
+			}
+			log.WithFields(log.Fields{
+				"error":      internalErr,
+				"method":     "GetPeerReputations",
+				"stackTrace": string(debug.Stack()),
+			}).Error("RPC method handler crashed")
+			err = errors.New("method handler crashed in GetPeerReputations RPC call (check logs for stack trace)")
+		}
+	}()
+	reputations, err := handler.app.GetPeerReputations(subject)
+	if err != nil {
+		log.WithField("error", err.Error()).Error("internal error in GetPeerReputations RPC call")
+		return nil, constants.ErrInternal
+	}
+	return reputations, nil
+}
+
+// GetOrderFilters is called when an RPC client calls GetOrderFilters.
+func (handler *rpcHandler) GetOrderFilters() (result []*types.OrderFilterInfo, err error) {
+	log.Debug("received GetOrderFilters request via RPC")
+	// Catch panics, log stack trace and return RPC error message
+	defer func() {
+		if r := recover(); r != nil {
+			internalErr, ok := r.(error)
+			if !ok {
+				// If r is not of type error, convert it.
+				internalErr = fmt.Errorf("Recovered from non-error: (%T) %v", r, r)
+			}
+			log.WithFields(log.Fields{
+				"error":      internalErr,
+				"method":     "GetOrderFilters",
+				"stackTrace": string(debug.Stack()),
+			}).Error("RPC method handler crashed")
+			err = errors.New("method handler crashed in GetOrderFilters RPC call (check logs for stack trace)")
+		}
+	}()
+	filters, err := handler.app.GetOrderFilters()
+	if err != nil {
+		log.WithField("error", err.Error()).Error("internal error in GetOrderFilters RPC call")
+		return nil, constants.ErrInternal
+	}
+	return filters, nil
+}
+
+// AddOrderFilter is called when an RPC client calls AddOrderFilter.
+func (handler *rpcHandler) AddOrderFilter(customOrderFilter string) (result *types.OrderFilterInfo, err error) {
+	log.WithField("customOrderFilter", customOrderFilter).Debug("received AddOrderFilter request via RPC")
+	// Catch panics, log stack trace and return RPC error message
+	defer func() {
+		if r := recover(); r != nil {
+			internalErr, ok := r.(error)
+			if !ok {
+				// If r is not of type error, convert it.
+				internalErr = fmt.Errorf("Recovered from non-error: (%T) %v", r, r)
+			}
+			log.WithFields(log.Fields{
+				"error":      internalErr,
+				"method":     "AddOrderFilter",
+				"stackTrace": string(debug.Stack()),
+			}).Error("RPC method handler crashed")
+			err = errors.New("method handler crashed in AddOrderFilter RPC call (check logs for stack trace)")
+		}
+	}()
+	filter, err := handler.app.AddOrderFilter(customOrderFilter)
+	if err != nil {
+		if _, ok := err.(core.InvalidOrderFilterError); ok || err == core.ErrOrderFilterExists {
+			return nil, err
+		}
+		log.WithField("error", err.Error()).Error("internal error in AddOrderFilter RPC call")
+		return nil, constants.ErrInternal
+	}
+	return filter, nil
+}
+
+// RemoveOrderFilter is called when an RPC client calls RemoveOrderFilter.
+func (handler *rpcHandler) RemoveOrderFilter(topic string) (err error) {
+	log.WithField("topic", topic).Debug("received RemoveOrderFilter request via RPC")
+	// Catch panics, log stack trace and return RPC error message
+	defer func() {
+		if r := recover(); r != nil {
+			internalErr, ok := r.(error)
+			if !ok {
+				// If r is not of type error, convert it.
+				internalErr = fmt.Errorf("Recovered from non-error: (%T) %v", r, r)
+			}
+			log.WithFields(log.Fields{
+				"error":      internalErr,
+				"method":     "RemoveOrderFilter",
+				"stackTrace": string(debug.Stack()),
+			}).Error("RPC method handler crashed")
+			err = errors.New("method handler crashed in RemoveOrderFilter RPC call (check logs for stack trace)")
+		}
+	}()
+	if err := handler.app.RemoveOrderFilter(topic); err != nil {
+		if err == core.ErrOrderFilterNotFound || err == core.ErrPrimaryOrderFilter {
+			return err
+		}
+		log.WithField("error", err.Error()).Error("internal error in RemoveOrderFilter RPC call")
+		return constants.ErrInternal
+	}
+	return nil
+}
+
+// GetTopMakers is called when an RPC client calls GetTopMakers.
+func (handler *rpcHandler) GetTopMakers(limit int) (result []*types.MakerOrderCount, err error) {
+	log.WithField("limit", limit).Debug("received GetTopMakers request via RPC")
+	// Catch panics, log stack trace and return RPC error message
+	defer func() {
+		if r := recover(); r != nil {
+			internalErr, ok := r.(error)
+			if !ok {
+				// If r is not of type error, convert it.
+				internalErr = fmt.Errorf("Recovered from non-error: (%T) %v", r, r)
+			}
+			log.WithFields(log.Fields{
+				"error":      internalErr,
+				"method":     "GetTopMakers",
+				"stackTrace": string(debug.Stack()),
+			}).Error("RPC method handler crashed")
+			err = errors.New("method handler crashed in GetTopMakers RPC call (check logs for stack trace)")
+		}
+	}()
+	makers, err := handler.app.GetTopMakers(limit)
+	if err != nil {
+		log.WithField("error", err.Error()).Error("internal error in GetTopMakers RPC call")
+		return nil, constants.ErrInternal
+	}
+	return makers, nil
+}
+
+// GetOrderbook is called when an RPC client calls GetOrderbook.
+func (handler *rpcHandler) GetOrderbook(makerAssetData, takerAssetData []byte) (result *types.Orderbook, err error) {
+	log.WithFields(log.Fields{
+		"makerAssetData": common.ToHex(makerAssetData),
+		"takerAssetData": common.ToHex(takerAssetData),
+	}).Debug("received GetOrderbook request via RPC")
+	// Catch panics, log stack trace and return RPC error message
+	defer func() {
+		if r := recover(); r != nil {
+			internalErr, ok := r.(error)
+			if !ok {
+				// If r is not of type error, convert it.
+				internalErr = fmt.Errorf("Recovered from non-error: (%T) %v", r, r)
+			}
+			log.WithFields(log.Fields{
+				"error":      internalErr,
+				"method":     "GetOrderbook",
+				"stackTrace": string(debug.Stack()),
+			}).Error("RPC method handler crashed")
+			err = errors.New("method handler crashed in GetOrderbook RPC call (check logs for stack trace)")
+		}
+	}()
+	orderbook, err := handler.app.GetOrderbook(makerAssetData, takerAssetData)
+	if err != nil {
+		if err == core.ErrInvalidAssetPair {
+			return nil, err
+		}
+		log.WithField("error", err.Error()).Error("internal error in GetOrderbook RPC call")
+		return nil, constants.ErrInternal
+	}
+	return orderbook, nil
+}
+
 // SubscribeToOrders is called when an RPC client sends a `mesh_subscribe` request with the `orders` topic parameter
 func (handler *rpcHandler) SubscribeToOrders(ctx context.Context) (result *ethrpc.Subscription, err error) {
 	log.Debug("received order event subscription request via RPC")
@@ -270,3 +724,97 @@ func SetupOrderStream(ctx context.Context, app *core.App) (*ethrpc.Subscription,
 
 	return rpcSub, nil
 }
+
+// SubscribeToOrderbook is called when an RPC client sends a `mesh_subscribe` request with the `orderbook` topic parameter
+func (handler *rpcHandler) SubscribeToOrderbook(ctx context.Context, makerAssetData, takerAssetData []byte) (result *ethrpc.Subscription, err error) {
+	log.WithFields(log.Fields{
+		"makerAssetData": common.ToHex(makerAssetData),
+		"takerAssetData": common.ToHex(takerAssetData),
+	}).Debug("received orderbook subscription request via RPC")
+	// Catch panics, log stack trace and return RPC error message
+	defer func() {
+		if r := recover(); r != nil {
+			internalErr, ok := r.(error)
+			if !ok {
+				// If r is not of type error, convert it.
+				internalErr = fmt.Errorf("Recovered from non-error: (%T) %v", r, r)
+			}
+			log.WithFields(log.Fields{
+				"error":      internalErr,
+				"method":     "SubscribeToOrderbook",
+				"stackTrace": string(debug.Stack()),
+			}).Error("RPC method handler crashed")
+			err = errors.New("method handler crashed in SubscribeToOrderbook RPC call (check logs for stack trace)")
+		}
+	}()
+	subscription, err := SetupOrderbookStream(ctx, handler.app, makerAssetData, takerAssetData)
+	if err != nil {
+		if err == core.ErrInvalidAssetPair {
+			return nil, err
+		}
+		log.WithField("error", err.Error()).Error("internal error in `mesh_subscribe` to `orderbook` RPC call")
+		return nil, constants.ErrInternal
+	}
+	return subscription, nil
+}
+
+// SetupOrderbookStream sets up the orderbook update stream for a subscription
+func SetupOrderbookStream(ctx context.Context, app *core.App, makerAssetData, takerAssetData []byte) (*ethrpc.Subscription, error) {
+	notifier, supported := ethrpc.NotifierFromContext(ctx)
+	if !supported {
+		return &ethrpc.Subscription{}, ethrpc.ErrNotificationsUnsupported
+	}
+
+	updatesChan := make(chan *types.OrderbookUpdate, orderEventsBufferSize)
+	orderbookSub, err := app.SubscribeToOrderbook(makerAssetData, takerAssetData, updatesChan)
+	if err != nil {
+		return nil, err
+	}
+
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		defer orderbookSub.Unsubscribe()
+
+		for {
+			select {
+			case update := <-updatesChan:
+				err := notifier.Notify(rpcSub.ID, update)
+				if err != nil {
+					// See the comment in SetupOrderStream for why some of these
+					// errors are only logged with `Trace` severity.
+					logEntry := log.WithFields(map[string]interface{}{
+						"error":            err.Error(),
+						"subscriptionType": "orderbook",
+					})
+					message := "error while calling notifier.Notify"
+					if _, ok := err.(*net.OpError); ok {
+						logEntry.Trace(message)
+						return
+					}
+					if strings.Contains(err.Error(), "write: broken pipe") {
+						logEntry.Trace(message)
+					} else {
+						logEntry.Error(message)
+					}
+				}
+			case err := <-orderbookSub.Err():
+				if err != nil {
+					log.WithField("err", err).Error("orderbook subscription returned an error")
+				}
+				return
+			case err := <-rpcSub.Err():
+				if err != nil {
+					log.WithField("err", err).Error("rpcSub returned an error")
+				} else {
+					log.Debug("rpcSub was closed without error")
+				}
+				return
+			case <-notifier.Closed():
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
@@ -24,6 +24,7 @@ type Stats struct {
 	EthereumChainID                   int         `json:"ethereumChainID"`
 	LatestBlock                       LatestBlock `json:"latestBlock"`
 	NumPeers                          int         `json:"numPeers"`
+	Reachability                      string      `json:"reachability"`
 	NumOrders                         int         `json:"numOrders"`
 	NumOrdersIncludingRemoved         int         `json:"numOrdersIncludingRemoved"`
 	NumPinnedOrders                   int         `json:"numPinnedOrders"`
@@ -47,6 +48,182 @@ type GetOrdersResponse struct {
 	OrdersInfos       []*OrderInfo `json:"ordersInfos"`
 }
 
+// PeerInfo holds information about a connected peer. It is the return value
+// for core.GetPeers. Also used in the RPC interface.
+type PeerInfo struct {
+	PeerID string `json:"peerID"`
+	// Multiaddrs are the remote addresses of all open connections to the peer.
+	Multiaddrs []string `json:"multiaddrs"`
+	// Protocols are the protocols the peer has advertised support for.
+	Protocols []string `json:"protocols"`
+	// LatencyMs is the observed latency to the peer in milliseconds.
+	LatencyMs float64 `json:"latencyMs"`
+	// Score is the current reputation score of the peer.
+	Score     float64        `json:"score"`
+	Bandwidth BandwidthStats `json:"bandwidth"`
+	// Protected is true if either the peer or one of the IP addresses it is
+	// connected from is protected.
+	Protected bool `json:"protected"`
+}
+
+// BandwidthStats holds the bandwidth used by a peer.
+type BandwidthStats struct {
+	TotalIn  int64   `json:"totalIn"`
+	TotalOut int64   `json:"totalOut"`
+	RateIn   float64 `json:"rateIn"`
+	RateOut  float64 `json:"rateOut"`
+}
+
+// PeerReputation is the reputation of a peer or IP address. It is the return
+// value for core.GetPeerReputations. Also used in the RPC interface.
+type PeerReputation struct {
+	// Kind is either "peer" or "ip".
+	Kind string `json:"kind"`
+	// Subject is the peer ID or IP address.
+	Subject string `json:"subject"`
+	// Score is the current total score, i.e. the sum of all tags.
+	Score float64 `json:"score"`
+	// Tags holds the current (decayed) value for each score tag.
+	Tags      map[string]float64 `json:"tags"`
+	Banned    bool               `json:"banned"`
+	Protected bool               `json:"protected"`
+	// History holds the most recent changes in reputation.
+	History []*PeerReputationEvent `json:"history"`
+}
+
+// PeerReputationEvent is a single change in the reputation of a peer or IP
+// address.
+type PeerReputationEvent struct {
+	Time time.Time `json:"time"`
+	Tag  string    `json:"tag"`
+	// Value is the new value of the tag.
+	Value float64 `json:"value"`
+	// Score is the total score after the change.
+	Score float64 `json:"score"`
+}
+
+// OrderFilterInfo holds information about a custom order filter used by the
+// Mesh node. It is the return value for core.GetOrderFilters. Also used in the
+// RPC interface.
+type OrderFilterInfo struct {
+	// Topic is the GossipSub topic for the filter. It also identifies the
+	// filter.
+	Topic             string `json:"topic"`
+	CustomOrderFilter string `json:"customOrderFilter"`
+	// Primary is true for the filter from the CustomOrderFilter config option.
+	// The primary filter is used for ordersync and cannot be removed.
+	Primary bool      `json:"primary"`
+	AddedAt time.Time `json:"addedAt"`
+	// OrdersReceived is the number of orders received on the topic of the
+	// filter.
+	OrdersReceived uint64 `json:"ordersReceived"`
+	// OrdersStored is the number of new orders which matched the filter and
+	// were stored.
+	OrdersStored uint64 `json:"ordersStored"`
+	// OrdersShared is the number of orders shared on the topic of the filter.
+	OrdersShared uint64 `json:"ordersShared"`
+}
+
+// MakerOrderCount holds the number of unpinned orders of a maker which are
+// stored by the Mesh node. It is the return value for core.GetTopMakers. Also
+// used in the RPC interface.
+type MakerOrderCount struct {
+	MakerAddress common.Address `json:"makerAddress"`
+	NumOrders    int            `json:"numOrders"`
+	// MaxOrders is the quota for the number of unpinned orders of the maker,
+	// or 0 if there is no limit.
+	MaxOrders int `json:"maxOrders"`
+}
+
+// Orderbook is the aggregated orderbook for an asset pair. It is the return
+// value for core.GetOrderbook. Also used in the RPC interface.
+//
+// Asks are the orders which sell the pair's maker asset for its taker asset,
+// sorted by ascending price. Bids are the orders which buy the pair's maker
+// asset with its taker asset, sorted by descending price. In both cases, the
+// price is the amount of the taker asset per unit of the maker asset.
+type Orderbook struct {
+	MakerAssetData string        `json:"makerAssetData"`
+	TakerAssetData string        `json:"takerAssetData"`
+	Bids           []*PriceLevel `json:"bids"`
+	Asks           []*PriceLevel `json:"asks"`
+}
+
+// OrderbookUpdate contains the price levels of an Orderbook which changed. The
+// amounts of each level are the new totals rather than differences, and a level
+// with NumOrders equal to 0 has been removed. Depth is not set. Also used in
+// the RPC interface.
+type OrderbookUpdate struct {
+	MakerAssetData string        `json:"makerAssetData"`
+	TakerAssetData string        `json:"takerAssetData"`
+	Bids           []*PriceLevel `json:"bids"`
+	Asks           []*PriceLevel `json:"asks"`
+}
+
+// PriceLevel is the aggregate of all orders on one side of an Orderbook with
+// the same price. Amounts are remaining fillable amounts, expressed in the
+// maker and taker asset of the orderbook's asset pair regardless of the side.
+type PriceLevel struct {
+	// Price is the amount of the taker asset per unit of the maker asset,
+	// including taker fees which are paid in either asset of the pair.
+	Price            string
+	MakerAssetAmount *big.Int
+	TakerAssetAmount *big.Int
+	// Depth is the cumulative MakerAssetAmount of this level and all levels
+	// with a better price.
+	Depth     *big.Int
+	NumOrders int
+}
+
+type priceLevelJSON struct {
+	Price            string `json:"price"`
+	MakerAssetAmount string `json:"makerAssetAmount"`
+	TakerAssetAmount string `json:"takerAssetAmount"`
+	Depth            string `json:"depth,omitempty"`
+	NumOrders        int    `json:"numOrders"`
+}
+
+// MarshalJSON is a custom Marshaler for PriceLevel
+func (l PriceLevel) MarshalJSON() ([]byte, error) {
+	levelJSON := priceLevelJSON{
+		Price:            l.Price,
+		MakerAssetAmount: l.MakerAssetAmount.String(),
+		TakerAssetAmount: l.TakerAssetAmount.String(),
+		NumOrders:        l.NumOrders,
+	}
+	if l.Depth != nil {
+		levelJSON.Depth = l.Depth.String()
+	}
+	return json.Marshal(levelJSON)
+}
+
+// UnmarshalJSON implements a custom JSON unmarshaller for the PriceLevel type
+func (l *PriceLevel) UnmarshalJSON(data []byte) error {
+	var levelJSON priceLevelJSON
+	if err := json.Unmarshal(data, &levelJSON); err != nil {
+		return err
+	}
+
+	l.Price = levelJSON.Price
+	l.NumOrders = levelJSON.NumOrders
+	var ok bool
+	l.MakerAssetAmount, ok = math.ParseBig256(levelJSON.MakerAssetAmount)
+	if !ok {
+		return errors.New("Invalid uint256 number encountered for MakerAssetAmount")
+	}
+	l.TakerAssetAmount, ok = math.ParseBig256(levelJSON.TakerAssetAmount)
+	if !ok {
+		return errors.New("Invalid uint256 number encountered for TakerAssetAmount")
+	}
+	if levelJSON.Depth != "" {
+		l.Depth, ok = math.ParseBig256(levelJSON.Depth)
+		if !ok {
+			return errors.New("Invalid uint256 number encountered for Depth")
+		}
+	}
+	return nil
+}
+
 // AddOrdersOpts is a set of options for core.AddOrders. Also used in the
 // browser and RPC interface.
 type AddOrdersOpts struct {
@@ -57,6 +234,26 @@ type AddOrdersOpts struct {
 	Pinned bool `json:"pinned"`
 }
 
+// CreateOrderParams is the set of parameters for core.CreateOrder. Amounts are
+// decimal strings and asset data is hex encoded. Only the maker and taker
+// asset data and amounts are required; all other fields are filled with
+// default values if they are empty. Also used in the RPC interface.
+type CreateOrderParams struct {
+	MakerAssetData        string `json:"makerAssetData"`
+	MakerAssetAmount      string `json:"makerAssetAmount"`
+	TakerAssetData        string `json:"takerAssetData"`
+	TakerAssetAmount      string `json:"takerAssetAmount"`
+	MakerFeeAssetData     string `json:"makerFeeAssetData,omitempty"`
+	MakerFee              string `json:"makerFee,omitempty"`
+	TakerFeeAssetData     string `json:"takerFeeAssetData,omitempty"`
+	TakerFee              string `json:"takerFee,omitempty"`
+	TakerAddress          string `json:"takerAddress,omitempty"`
+	SenderAddress         string `json:"senderAddress,omitempty"`
+	FeeRecipientAddress   string `json:"feeRecipientAddress,omitempty"`
+	ExpirationTimeSeconds string `json:"expirationTimeSeconds,omitempty"`
+	Salt                  string `json:"salt,omitempty"`
+}
+
 // OrderInfo represents an fillable order and how much it could be filled for.
 type OrderInfo struct {
 	OrderHash                common.Hash         `json:"orderHash"`
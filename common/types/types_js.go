@@ -39,6 +39,7 @@ func (s Stats) JSValue() js.Value {
 		"ethereumChainID":                   s.EthereumChainID,
 		"latestBlock":                       s.LatestBlock.JSValue(),
 		"numPeers":                          s.NumPeers,
+		"reachability":                      s.Reachability,
 		"numOrders":                         s.NumOrders,
 		"numOrdersIncludingRemoved":         s.NumOrdersIncludingRemoved,
 		"numPinnedOrders":                   s.NumPinnedOrders,
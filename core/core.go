@@ -2,12 +2,14 @@
 package core
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -22,6 +24,7 @@ import (
 	"github.com/0xProject/0x-mesh/ethereum/blockwatch"
 	"github.com/0xProject/0x-mesh/ethereum/ethrpcclient"
 	"github.com/0xProject/0x-mesh/ethereum/ratelimit"
+	"github.com/0xProject/0x-mesh/ethereum/signer"
 	"github.com/0xProject/0x-mesh/ethereum/simplestack"
 	"github.com/0xProject/0x-mesh/expirationwatch"
 	"github.com/0xProject/0x-mesh/keys"
@@ -29,9 +32,11 @@ import (
 	"github.com/0xProject/0x-mesh/meshdb"
 	"github.com/0xProject/0x-mesh/orderfilter"
 	"github.com/0xProject/0x-mesh/p2p"
+	"github.com/0xProject/0x-mesh/p2p/privnet"
 	"github.com/0xProject/0x-mesh/zeroex"
 	"github.com/0xProject/0x-mesh/zeroex/ordervalidator"
 	"github.com/0xProject/0x-mesh/zeroex/orderwatch"
+	"github.com/0xProject/0x-mesh/zeroex/orderwatch/mempool"
 	"github.com/albrow/stringset"
 	"github.com/benbjohnson/clock"
 	"github.com/ethereum/go-ethereum/common"
@@ -40,7 +45,9 @@ import (
 	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/google/uuid"
 	p2pcrypto "github.com/libp2p/go-libp2p-core/crypto"
+	network "github.com/libp2p/go-libp2p-core/network"
 	peer "github.com/libp2p/go-libp2p-core/peer"
+	protocol "github.com/libp2p/go-libp2p-core/protocol"
 	peerstore "github.com/libp2p/go-libp2p-peerstore"
 	ma "github.com/multiformats/go-multiaddr"
 	log "github.com/sirupsen/logrus"
@@ -68,6 +75,14 @@ const (
 	// run of the ordersync protocol (as a requester). We always request orders
 	// immediately on startup. This delay only applies to subsequent runs.
 	ordersyncApproxDelay = 1 * time.Hour
+	// compactEncodingProtocolID is advertised to peers to signal that this node
+	// can decode GossipSub messages in the compact binary encoding.
+	compactEncodingProtocolID = protocol.ID("/injective-0x-mesh/compact-encoding/version/1")
+	// compactTopicSuffix is appended to a topic to get the topic on which orders
+	// are shared in the compact encoding. Nodes which cannot decode the compact
+	// encoding never subscribe to these topics, so GossipSub never forwards
+	// compact messages to them.
+	compactTopicSuffix = "/encoding/compact/version/1"
 )
 
 // privateConfig contains some configuration options that can only be changed from
@@ -113,6 +128,13 @@ type Config struct {
 	// "/ip4/3.214.190.67/tcp/60558/ipfs/16Uiu2HAmGx8Z6gdq5T5AQE54GMtqDhDFhizywTy1o28NJbAMMumF").
 	// If empty, the default bootstrap list will be used.
 	BootstrapList string `envvar:"BOOTSTRAP_LIST" default:""`
+	// RendezvousServers is an optional comma-separated list of multiaddresses
+	// (including the peer ID) of rendezvous servers, such as bootstrap nodes
+	// started with ENABLE_RENDEZVOUS_SERVER. If set, Mesh registers its
+	// rendezvous points with these servers and asks them for peers before
+	// falling back to the DHT, which helps to find peers for custom order
+	// filters which only few nodes use.
+	RendezvousServers string `envvar:"RENDEZVOUS_SERVERS" default:""`
 	// BlockPollingInterval is the polling interval to wait before checking for a new Ethereum block
 	// that might contain transactions that impact the fillability of orders stored by Mesh. Different
 	// chains have different block producing intervals: POW chains are typically slower (e.g., Mainnet)
@@ -148,8 +170,11 @@ type Config struct {
 	// is typically only needed for testing on custom chains/networks. The given
 	// addresses are added to the default list of addresses for known chains/networks and
 	// overriding any contract addresses for known chains/networks is not allowed. The
-	// addresses for exchange, devUtils, erc20Proxy, erc721Proxy and erc1155Proxy are required
-	// for each chain/network. For example:
+	// addresses for exchange, erc20Proxy, erc721Proxy and erc1155Proxy are required
+	// for each chain/network. The devUtils address is also required unless
+	// OrderValidatorBackend is set to "direct". The multicall address is
+	// optional and allows the "direct" backend to check Validator, Wallet and
+	// EIP1271Wallet signatures in a single call. For example:
 	//
 	//    {
 	//        "exchange":"0x48bacb9266a570d521063ef5dd96e61686dbe788",
@@ -162,9 +187,69 @@ type Config struct {
 	CustomContractAddresses string `envvar:"CUSTOM_CONTRACT_ADDRESSES" default:""`
 	// MaxOrdersInStorage is the maximum number of orders that Mesh will keep in
 	// storage. As the number of orders in storage grows, Mesh will begin
-	// enforcing a limit on maximum expiration time for incoming orders and remove
-	// any orders with an expiration time too far in the future.
+	// removing orders according to OrderEvictionPolicy. With the default policy,
+	// Mesh enforces a limit on maximum expiration time for incoming orders and
+	// removes any orders with an expiration time too far in the future.
 	MaxOrdersInStorage int `envvar:"MAX_ORDERS_IN_STORAGE" default:"100000"`
+	// OrderEvictionPolicy determines which orders are removed when the number of
+	// orders in storage exceeds MaxOrdersInStorage. Pinned orders are never
+	// removed. It can be one of:
+	//
+	//    "expiration": remove the orders with the highest expiration times and
+	//                  enforce a limit on the maximum expiration time of
+	//                  incoming orders.
+	//    "value":      remove the orders with the lowest fillable value first,
+	//                  priced with OrderEvictionTokenPrices.
+	//    "spread":     remove the orders whose price is furthest from the best
+	//                  price for the same asset pair first.
+	//    "reputation": remove the orders of the makers with the lowest
+	//                  OrderEvictionMakerReputations first.
+	//    "age":        remove the orders which were added the longest time ago
+	//                  first.
+	//
+	// All policies except "expiration" load and score all unpinned orders every
+	// time orders are removed, which takes longer the higher MaxOrdersInStorage
+	// is. While storage is full, they reject incoming unpinned orders which
+	// score lower than the orders that were removed last.
+	OrderEvictionPolicy string `envvar:"ORDER_EVICTION_POLICY" default:"expiration"`
+	// OrderEvictionTokenPrices is a JSON-encoded map from ERC20 token address to
+	// the price of one base unit of the token in base units of a reference token
+	// (e.g. WETH). It is required if OrderEvictionPolicy is "value". Orders whose
+	// maker and taker assets both have no price are removed first. For example:
+	//
+	//    {
+	//        "0xc02aaa39b223fe8d0a0e5c4f27ead9083c756cc2": 1,
+	//        "0x6b175474e89094c44da98b954eedeac495271d0f": 0.0005
+	//    }
+	//
+	OrderEvictionTokenPrices string `envvar:"ORDER_EVICTION_TOKEN_PRICES" default:""`
+	// OrderEvictionMakerReputations is a JSON-encoded map from maker address to
+	// a reputation score. Higher scores are better and makers without a score
+	// have a score of 0. It is required if OrderEvictionPolicy is "reputation".
+	OrderEvictionMakerReputations string `envvar:"ORDER_EVICTION_MAKER_REPUTATIONS" default:""`
+	// MaxOrdersPerMaker is the maximum number of unpinned orders with the same
+	// maker address that Mesh will keep in storage. Orders which would exceed
+	// the quota are rejected. A value of 0 means there is no limit.
+	MaxOrdersPerMaker int `envvar:"MAX_ORDERS_PER_MAKER" default:"0"`
+	// MaxOrdersPerPeer is the maximum number of unpinned orders received from
+	// the same peer that Mesh will keep in storage. Orders added via the
+	// JSON-RPC API do not count towards any peer quota. A value of 0 means there
+	// is no limit.
+	MaxOrdersPerPeer int `envvar:"MAX_ORDERS_PER_PEER" default:"0"`
+	// OrderQuotaOverrides is a JSON-encoded object which overrides
+	// MaxOrdersPerMaker and MaxOrdersPerPeer for specific maker addresses and
+	// peer IDs. For example:
+	//
+	//    {
+	//        "makers": {
+	//            "0x6ecbe1db9ef729cbe972c83fb886247691fb6beb": 50000
+	//        },
+	//        "peers": {
+	//            "16Uiu2HAmGd949LwaV4KNvK2WDSiMVy7xEmW983VH75CMmefmMpP7": 0
+	//        }
+	//    }
+	//
+	OrderQuotaOverrides string `envvar:"ORDER_QUOTA_OVERRIDES" default:""`
 	// CustomOrderFilter is a stringified JSON Schema which will be used for
 	// validating incoming orders. If provided, Mesh will only receive orders from
 	// other peers in the network with the same filter.
@@ -185,6 +270,106 @@ type Config struct {
 	// all the required fields) are automatically included. For more information
 	// on JSON Schemas, see https://json-schema.org/
 	CustomOrderFilter string `envvar:"CUSTOM_ORDER_FILTER" default:"{}"`
+	// CustomOrderFilters is a JSON array of additional custom order filters in
+	// the same format as CustomOrderFilter. Mesh subscribes to the topics of all
+	// of the filters, stores orders which match at least one of them and shares
+	// each order on the topics of all the filters it matches. CustomOrderFilter
+	// remains the primary filter which is used for ordersync and peer
+	// discovery. Filters can also be added and removed at runtime via the
+	// JSON-RPC API.
+	CustomOrderFilters string `envvar:"CUSTOM_ORDER_FILTERS" default:""`
+	// EnableMempoolWatcher determines whether or not Mesh should watch the
+	// pending transactions of the Ethereum RPC endpoint for fills and cancels of
+	// stored orders. If enabled, Mesh emits PENDING_FILL and PENDING_CANCEL order
+	// events as soon as a matching transaction is seen. The fillability of orders
+	// is not changed until the transaction is mined. The Ethereum RPC endpoint
+	// must support eth_newPendingTransactionFilter. Note that every new pending
+	// transaction results in an additional Ethereum RPC request, so this should
+	// typically only be enabled when connected to your own Ethereum node.
+	EnableMempoolWatcher bool `envvar:"ENABLE_MEMPOOL_WATCHER" default:"false"`
+	// MempoolPollingInterval is the polling interval to wait before checking for
+	// new pending transactions. It has no effect unless EnableMempoolWatcher is
+	// true.
+	MempoolPollingInterval time.Duration `envvar:"MEMPOOL_POLLING_INTERVAL" default:"1s"`
+	// OrderValidatorBackend determines how Mesh fetches the on-chain state
+	// (balances, allowances, fill amounts, etc.) needed to validate orders. It
+	// can be either "devutils" or "direct". The "devutils" backend fetches the
+	// state for many orders at once with a single call to the DevUtils
+	// contract. The "direct" backend makes individual calls to the Exchange and
+	// token contracts instead. It results in many more Ethereum RPC requests but
+	// works on chains where DevUtils is not deployed.
+	OrderValidatorBackend string `envvar:"ORDER_VALIDATOR_BACKEND" default:"devutils"`
+	// CustomAssetProxies is a JSON-encoded array of asset proxies which are not
+	// built into the 0x protocol but whose asset data uses the same encoding as
+	// ERC20, ERC721 or ERC1155 asset data. Orders using these asset proxies are
+	// validated as if they used the corresponding standard asset proxy, except
+	// that allowances are checked for the custom asset proxy address. Custom
+	// asset proxies require OrderValidatorBackend to be "direct". For example:
+	//
+	//    [
+	//        {
+	//            "id": "a7cb5fb7",
+	//            "tokenStandard": "ERC1155Assets",
+	//            "address": "0x8a2c5c4a0d0b4c1d4b7a3e6f3b2d3e2a1c0b9f8e"
+	//        }
+	//    ]
+	//
+	CustomAssetProxies string `envvar:"CUSTOM_ASSET_PROXIES" default:""`
+	// PrivateNetworkKeyPath is the path to a libp2p pre-shared key file (also
+	// known as "swarm.key"). If set, Mesh joins a private network and only
+	// connects to peers which use the same key. Private networks use separate
+	// rendezvous points and do not use the default bootstrap list.
+	PrivateNetworkKeyPath string `envvar:"PRIVATE_NETWORK_KEY_PATH" default:""`
+	// PrivateNetworkAllowlistPath is the path to a file which contains the peer
+	// IDs of all peers that are allowed to connect to Mesh, one per line. If
+	// set, connections, GossipSub messages and ordersync requests from any
+	// other peer are rejected. The file is reloaded automatically when it
+	// changes. It can be used with or without PrivateNetworkKeyPath.
+	PrivateNetworkAllowlistPath string `envvar:"PRIVATE_NETWORK_ALLOWLIST_PATH" default:""`
+	// PrivateNetworkName is the name of the private network. It is used to
+	// compute the rendezvous points for peer discovery. Defaults to the
+	// fingerprint of the pre-shared key. It has no effect unless
+	// PrivateNetworkKeyPath or PrivateNetworkAllowlistPath is set.
+	PrivateNetworkName string `envvar:"PRIVATE_NETWORK_NAME" default:""`
+	// EnableNATPortMap determines whether Mesh tries to open a port in the NAT
+	// via UPnP or NAT-PMP, so that other peers can dial it directly.
+	EnableNATPortMap bool `envvar:"ENABLE_NAT_PORT_MAP" default:"false"`
+	// EnableRelayHop determines whether Mesh acts as a circuit relay for peers
+	// which can't be dialed directly. Relays should only be run on nodes with a
+	// public IP address and enough bandwidth.
+	EnableRelayHop bool `envvar:"ENABLE_RELAY_HOP" default:"false"`
+	// EnableAutoNATService determines whether Mesh helps other peers determine
+	// whether they are reachable by dialing them back.
+	EnableAutoNATService bool `envvar:"ENABLE_AUTONAT_SERVICE" default:"false"`
+	// EnableHolePunching determines whether Mesh tries to replace relayed
+	// connections with direct connections by punching holes in the NAT.
+	EnableHolePunching bool `envvar:"ENABLE_HOLE_PUNCHING" default:"false"`
+	// PrivateKeyPassphrase is the passphrase used to decrypt the private key
+	// which identifies the node. If set and no private key exists yet, the
+	// generated key is encrypted with it. Unencrypted keys can still be loaded,
+	// and can be encrypted with the mesh-keygen tool.
+	PrivateKeyPassphrase string `envvar:"PRIVATE_KEY_PASSPHRASE" default:"" json:"-"`
+	// PrivateKeyPassphraseFile is the path to a file which contains the
+	// passphrase for the private key. It is used if PrivateKeyPassphrase is
+	// empty.
+	PrivateKeyPassphraseFile string `envvar:"PRIVATE_KEY_PASSPHRASE_FILE" default:""`
+	// OrderSignerKeystorePath is the path to an Ethereum keystore file which
+	// holds the private key used to sign orders created via the
+	// mesh_createOrder RPC method. The address of the key is used as the maker
+	// address of created orders.
+	OrderSignerKeystorePath string `envvar:"ORDER_SIGNER_KEYSTORE_PATH" default:""`
+	// OrderSignerKeystorePassphrase is the passphrase for the keystore file at
+	// OrderSignerKeystorePath.
+	OrderSignerKeystorePassphrase string `envvar:"ORDER_SIGNER_KEYSTORE_PASSPHRASE" default:"" json:"-"`
+	// OrderSignerRPCURL is the URL of a remote signer which supports the
+	// eth_signTypedData_v4 JSON RPC method (e.g. Clef or a node with an
+	// unlocked account). It is used to sign orders created via the
+	// mesh_createOrder RPC method instead of a keystore file.
+	// OrderSignerAddress must also be set.
+	OrderSignerRPCURL string `envvar:"ORDER_SIGNER_RPC_URL" default:"" json:"-"`
+	// OrderSignerAddress is the address of the account used by the remote
+	// signer at OrderSignerRPCURL.
+	OrderSignerAddress string `envvar:"ORDER_SIGNER_ADDRESS" default:""`
 	// EthereumRPCClient is the client to use for all Ethereum RPC reuqests. It is only
 	// settable in browsers and cannot be set via environment variable. If
 	// provided, EthereumRPCURL will be ignored.
@@ -195,6 +380,9 @@ type snapshotInfo struct {
 	Snapshot            *db.Snapshot
 	CreatedAt           time.Time
 	ExpirationTimestamp time.Time
+	// BloomFilter is the bloom filter which was sent by the requester for whom
+	// the snapshot was created. It is only set by the BloomFilterSubProtocol.
+	BloomFilter *ordersync.BloomFilter
 }
 
 type App struct {
@@ -205,9 +393,12 @@ type App struct {
 	node                      *p2p.Node
 	chainID                   int
 	blockWatcher              *blockwatch.Watcher
+	mempoolWatcher            *mempool.Watcher
 	orderWatcher              *orderwatch.Watcher
 	orderValidator            *ordervalidator.OrderValidator
 	orderFilter               *orderfilter.Filter
+	orderFilters              *orderFilterSet
+	defaultTopic              string
 	snapshotExpirationWatcher *expirationwatch.Watcher
 	muIdToSnapshotInfo        sync.Mutex
 	idToSnapshotInfo          map[string]snapshotInfo
@@ -216,6 +407,9 @@ type App struct {
 	db                        *meshdb.MeshDB
 	ordersyncService          *ordersync.Service
 	contractAddresses         *ethereum.ContractAddresses
+	privateNetworkKey         *privnet.PSK
+	orderSigner               signer.Signer
+	orderSignerAddress        common.Address
 
 	// started is closed to signal that the App has been started. Some methods
 	// will block until after the App is started.
@@ -249,9 +443,26 @@ func newWithPrivateConfig(config Config, pConfig privateConfig) (*App, error) {
 		return nil, err
 	}
 
+	// Parse custom asset proxies. They are passed to every component which
+	// decodes asset data.
+	var customAssetProxies []zeroex.AssetProxy
+	if config.CustomAssetProxies != "" {
+		if config.OrderValidatorBackend != ordervalidator.DirectBackend {
+			return nil, fmt.Errorf("config.CustomAssetProxies requires the %q order validator backend", ordervalidator.DirectBackend)
+		}
+		customAssetProxies, err = parseCustomAssetProxies(config.CustomAssetProxies)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Load private key and add peer ID hook.
 	privKeyPath := filepath.Join(config.DataDir, "keys", "privkey")
-	privKey, err := initPrivateKey(privKeyPath)
+	privKeyPassphrase, err := keys.ReadPassphrase(config.PrivateKeyPassphrase, config.PrivateKeyPassphraseFile)
+	if err != nil {
+		return nil, err
+	}
+	privKey, err := initPrivateKey(privKeyPath, privKeyPassphrase)
 	if err != nil {
 		return nil, err
 	}
@@ -282,7 +493,7 @@ func newWithPrivateConfig(config Config, pConfig privateConfig) (*App, error) {
 
 	// Initialize db
 	databasePath := filepath.Join(config.DataDir, "db")
-	meshDB, err := meshdb.New(databasePath, contractAddresses)
+	meshDB, err := meshdb.NewWithAssetProxies(databasePath, contractAddresses, customAssetProxies)
 	if err != nil {
 		return nil, err
 	}
@@ -373,25 +584,65 @@ func newWithPrivateConfig(config Config, pConfig privateConfig) (*App, error) {
 	blockWatcher := blockwatch.New(blockWatcherConfig)
 
 	// Initialize the order validator
-	orderValidator, err := ordervalidator.New(
+	if config.OrderValidatorBackend == ordervalidator.DevUtilsBackend && contractAddresses.DevUtils == constants.NullAddress {
+		return nil, fmt.Errorf("cannot use the %q order validator backend: no DevUtils address for chain ID %d", ordervalidator.DevUtilsBackend, config.EthereumChainID)
+	}
+	stateFetcher, err := ordervalidator.NewStateFetcher(config.OrderValidatorBackend, ethClient, contractAddresses, customAssetProxies)
+	if err != nil {
+		return nil, err
+	}
+	orderValidator, err := ordervalidator.NewWithStateFetcher(
 		ethClient,
 		config.EthereumChainID,
 		config.EthereumRPCMaxContentLength,
 		contractAddresses,
+		stateFetcher,
+		customAssetProxies,
 	)
 	if err != nil {
 		return nil, err
 	}
 
+	// Initialize the mempool watcher if needed (but don't start it yet).
+	var mempoolWatcher *mempool.Watcher
+	if config.EnableMempoolWatcher {
+		mempoolWatcher, err = mempool.New(mempool.Config{
+			Client:          ethClient,
+			ChainID:         config.EthereumChainID,
+			ExchangeAddress: contractAddresses.Exchange,
+			PollingInterval: config.MempoolPollingInterval,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Initialize order watcher (but don't start it yet).
+	balanceFetcher, err := ordervalidator.NewBalanceFetcher(ethClient, contractAddresses, customAssetProxies)
+	if err != nil {
+		return nil, err
+	}
+	evictionPolicy, err := parseOrderEvictionPolicy(config)
+	if err != nil {
+		return nil, err
+	}
+	orderQuotas, err := parseOrderQuotas(config)
+	if err != nil {
+		return nil, err
+	}
 	orderWatcher, err := orderwatch.New(orderwatch.Config{
 		MeshDB:            meshDB,
 		BlockWatcher:      blockWatcher,
 		OrderValidator:    orderValidator,
+		BalanceFetcher:    balanceFetcher,
 		ChainID:           config.EthereumChainID,
 		ContractAddresses: contractAddresses,
 		MaxOrders:         config.MaxOrdersInStorage,
 		MaxExpirationTime: metadata.MaxExpirationTime,
+		MempoolWatcher:    mempoolWatcher,
+		EvictionPolicy:    evictionPolicy,
+		OrderQuotas:       orderQuotas,
+		AssetProxies:      customAssetProxies,
 	})
 	if err != nil {
 		return nil, err
@@ -402,6 +653,36 @@ func newWithPrivateConfig(config Config, pConfig privateConfig) (*App, error) {
 	if err != nil {
 		return nil, fmt.Errorf("invalid custom order filter: %s", err.Error())
 	}
+	orderFilters := newOrderFilterSet(orderFilter)
+	additionalOrderSchemas, err := parseCustomOrderFilters(config.CustomOrderFilters)
+	if err != nil {
+		return nil, err
+	}
+	for _, orderSchema := range additionalOrderSchemas {
+		additionalFilter, err := orderfilter.New(config.EthereumChainID, orderSchema, contractAddresses)
+		if err != nil {
+			return nil, fmt.Errorf("invalid custom order filter: %s", err.Error())
+		}
+		if _, err := orderFilters.add(additionalFilter); err != nil {
+			return nil, fmt.Errorf("invalid custom order filter: %s", err.Error())
+		}
+	}
+	defaultTopic, err := orderfilter.GetDefaultTopic(config.EthereumChainID, contractAddresses)
+	if err != nil {
+		return nil, err
+	}
+
+	// Load the pre-shared key if Mesh should join a private network.
+	privateNetworkKey, err := loadPrivateNetworkKey(config.PrivateNetworkKeyPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// Set up the signer for orders created via CreateOrder, if configured.
+	orderSigner, orderSignerAddress, err := initOrderSigner(config)
+	if err != nil {
+		return nil, err
+	}
 
 	// Initialize remaining fields.
 	snapshotExpirationWatcher := expirationwatch.New()
@@ -414,15 +695,21 @@ func newWithPrivateConfig(config Config, pConfig privateConfig) (*App, error) {
 		peerID:                    peerID,
 		chainID:                   config.EthereumChainID,
 		blockWatcher:              blockWatcher,
+		mempoolWatcher:            mempoolWatcher,
 		orderWatcher:              orderWatcher,
 		orderValidator:            orderValidator,
 		orderFilter:               orderFilter,
+		orderFilters:              orderFilters,
+		defaultTopic:              defaultTopic,
 		snapshotExpirationWatcher: snapshotExpirationWatcher,
 		idToSnapshotInfo:          map[string]snapshotInfo{},
 		ethRPCRateLimiter:         ethRPCRateLimiter,
 		ethRPCClient:              ethClient,
 		db:                        meshDB,
 		contractAddresses:         &contractAddresses,
+		privateNetworkKey:         privateNetworkKey,
+		orderSigner:               orderSigner,
+		orderSignerAddress:        orderSignerAddress,
 	}
 
 	log.WithFields(map[string]interface{}{
@@ -466,6 +753,15 @@ func getPublishTopics(chainID int, contractAddresses ethereum.ContractAddresses,
 
 func (app *App) getRendezvousPoints() ([]string, error) {
 	defaultRendezvousPoint := fmt.Sprintf("/injective-0x-mesh/network/%d/version/2", app.config.EthereumChainID)
+	if app.isPrivateNetwork() {
+		// Private networks use rendezvous points which are disjoint from the
+		// ones used by the public network.
+		privateNetworkID, err := app.getPrivateNetworkID()
+		if err != nil {
+			return nil, err
+		}
+		defaultRendezvousPoint = fmt.Sprintf("/injective-0x-mesh/network/%d/private/%s/version/2", app.config.EthereumChainID, privateNetworkID)
+	}
 	defaultTopic, err := orderfilter.GetDefaultTopic(app.chainID, *app.contractAddresses)
 	if err != nil {
 		return nil, err
@@ -479,17 +775,27 @@ func (app *App) getRendezvousPoints() ([]string, error) {
 		// If we are using a custom order filter, use *both* the default
 		// rendezvous point and a separate one specific to the filter. The
 		// filter-specific rendezvous point takes priority.
-		return []string{app.orderFilter.Rendezvous(), defaultRendezvousPoint}, nil
+		filterRendezvousPoint := app.orderFilter.Rendezvous()
+		if app.isPrivateNetwork() {
+			filterRendezvousPoint = defaultRendezvousPoint + filterRendezvousPoint
+		}
+		return []string{filterRendezvousPoint, defaultRendezvousPoint}, nil
 	}
 }
 
-func initPrivateKey(path string) (p2pcrypto.PrivKey, error) {
-	privKey, err := keys.GetPrivateKeyFromPath(path)
+func initPrivateKey(path string, passphrase string) (p2pcrypto.PrivKey, error) {
+	privKey, err := keys.GetEncryptedPrivateKeyFromPath(path, passphrase)
 	if err == nil {
+		if encrypted, _ := keys.IsEncryptedFile(path); passphrase != "" && !encrypted {
+			log.Warn("A private key passphrase was given but the private key is not encrypted. Use mesh-keygen to encrypt it.")
+		}
 		return privKey, nil
 	} else if os.IsNotExist(err) {
 		// If the private key doesn't exist, generate one.
 		log.Info("No private key found. Generating a new one.")
+		if passphrase != "" {
+			return keys.GenerateAndSaveEncryptedPrivateKey(path, passphrase, keys.StandardScryptN, keys.StandardScryptP)
+		}
 		return keys.GenerateAndSavePrivateKey(path)
 	}
 
@@ -636,6 +942,20 @@ func (app *App) Start(ctx context.Context) error {
 		blockWatcherErrChan <- app.blockWatcher.Watch(innerCtx)
 	}()
 
+	// Start the mempool watcher if needed.
+	mempoolWatcherErrChan := make(chan error, 1)
+	if app.mempoolWatcher != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() {
+				log.Debug("closing mempool watcher")
+			}()
+			log.WithField("pollingInterval", app.config.MempoolPollingInterval).Info("starting mempool watcher")
+			mempoolWatcherErrChan <- app.mempoolWatcher.Watch(innerCtx)
+		}()
+	}
+
 	// If Mesh is not caught up with the latest block found via Ethereum RPC, ensure orderWatcher
 	// has processed at least one recent block before starting the P2P node and completing app start,
 	// so that Mesh does not validate any orders at outdated block heights
@@ -662,13 +982,30 @@ func (app *App) Start(ctx context.Context) error {
 	// panic with a nil pointer exception. All the other fields of core.App that
 	// we need to use will have already been initialized and are ready to use.
 	bootstrapList := p2p.DefaultBootstrapList
+	useBootstrapList := app.config.UseBootstrapList
 	if app.config.BootstrapList != "" {
 		bootstrapList = strings.Split(app.config.BootstrapList, ",")
+	} else if app.isPrivateNetwork() && useBootstrapList {
+		// The default bootstrap nodes are part of the public network.
+		log.Info("not using the default bootstrap list because Mesh is configured to join a private network")
+		useBootstrapList = false
+	}
+	var peerAllowlist []peer.ID
+	var peerAllowlistModified time.Time
+	if app.config.PrivateNetworkAllowlistPath != "" {
+		peerAllowlist, peerAllowlistModified, err = loadPeerAllowlist(app.config.PrivateNetworkAllowlistPath)
+		if err != nil {
+			return err
+		}
 	}
 	rendezvousPoints, err := app.getRendezvousPoints()
 	if err != nil {
 		return err
 	}
+	rendezvousServers := []string{}
+	if app.config.RendezvousServers != "" {
+		rendezvousServers = strings.Split(app.config.RendezvousServers, ",")
+	}
 	nodeConfig := p2p.Config{
 		SubscribeTopic:         app.orderFilter.Topic(),
 		PublishTopics:          publishTopics,
@@ -678,18 +1015,49 @@ func (app *App) Start(ctx context.Context) error {
 		PrivateKey:             app.privKey,
 		MessageHandler:         app,
 		RendezvousPoints:       rendezvousPoints,
-		UseBootstrapList:       app.config.UseBootstrapList,
+		UseBootstrapList:       useBootstrapList,
 		BootstrapList:          bootstrapList,
+		RendezvousServers:      rendezvousServers,
 		DataDir:                filepath.Join(app.config.DataDir, "p2p"),
+		PubSubMessageWeight:    encoding.NumOrdersInMessage,
 		CustomMessageValidator: app.orderFilter.ValidatePubSubMessage,
+		ReputationStore:        app.db,
+		PrivateNetworkKey:      app.privateNetworkKey,
+		PeerAllowlist:          peerAllowlist,
+		EnableNATPortMap:       app.config.EnableNATPortMap,
+		EnableRelayHop:         app.config.EnableRelayHop,
+		EnableAutoNATService:   app.config.EnableAutoNATService,
+		EnableHolePunching:     app.config.EnableHolePunching,
 	}
 	app.node, err = p2p.New(innerCtx, nodeConfig)
 	if err != nil {
 		return err
 	}
+	for _, topic := range publishTopics {
+		if err := app.node.AddTopic(compactTopic(topic), app.orderFilter.ValidatePubSubMessage); err != nil {
+			return err
+		}
+	}
+	for _, tracked := range app.orderFilters.additional() {
+		if err := app.node.AddTopic(tracked.topic, tracked.filter.ValidatePubSubMessage); err != nil {
+			return err
+		}
+		if err := app.node.AddTopic(compactTopic(tracked.topic), tracked.filter.ValidatePubSubMessage); err != nil {
+			return err
+		}
+	}
+	// Advertise that we can decode GossipSub messages in the compact encoding.
+	// The protocol is only used for negotiation, so streams are never accepted.
+	app.node.SetStreamHandler(compactEncodingProtocolID, func(stream network.Stream) {
+		_ = stream.Reset()
+	})
 
-	// Register and start ordersync service.
+	// Register and start ordersync service. Subprotocols are listed in order of
+	// preference. FilteredPaginationSubProtocol is kept for peers which do not
+	// support set reconciliation yet.
 	ordersyncSubprotocols := []ordersync.Subprotocol{
+		NewOrdersSinceSubprotocol(app, app.privateConfig.paginationSubprotocolPerPage),
+		NewBloomFilterSubprotocol(app, app.privateConfig.paginationSubprotocolPerPage),
 		NewFilteredPaginationSubprotocol(app, app.privateConfig.paginationSubprotocolPerPage),
 	}
 	app.ordersyncService = ordersync.New(innerCtx, app.node, ordersyncSubprotocols)
@@ -703,7 +1071,7 @@ func (app *App) Start(ctx context.Context) error {
 		log.WithFields(map[string]interface{}{
 			"approxDelay":  ordersyncApproxDelay,
 			"perPage":      app.privateConfig.paginationSubprotocolPerPage,
-			"subprotocols": []string{"FilteredPaginationSubProtocol"},
+			"subprotocols": []string{"OrdersSinceSubProtocol", "BloomFilterSubProtocol", "FilteredPaginationSubProtocol"},
 		}).Info("starting ordersync service")
 
 		if err := app.ordersyncService.PeriodicallyGetOrders(innerCtx, ordersyncMinPeers, ordersyncApproxDelay); err != nil {
@@ -747,6 +1115,18 @@ func (app *App) Start(ctx context.Context) error {
 		app.periodicallyLogStats(innerCtx)
 	}()
 
+	// Start loop for reloading the peer allowlist.
+	if app.config.PrivateNetworkAllowlistPath != "" {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() {
+				log.Debug("closing peer allowlist watcher")
+			}()
+			app.watchPeerAllowlist(innerCtx, peerAllowlistModified)
+		}()
+	}
+
 	// Signal that the app has been started.
 	log.Info("core.App was started")
 	close(app.started)
@@ -781,6 +1161,12 @@ func (app *App) Start(ctx context.Context) error {
 				cancel()
 				return err
 			}
+		case err := <-mempoolWatcherErrChan:
+			if err != nil {
+				log.WithError(err).Error("mempool watcher exited with error")
+				cancel()
+				return err
+			}
 		case err := <-ethRPCRateLimiterErrChan:
 			if err != nil {
 				log.WithError(err).Error("ETH JSON-RPC ratelimiter exited with error")
@@ -863,52 +1249,15 @@ func (app *App) GetOrders(page, perPage int, snapshotID string) (*types.GetOrder
 		return nil, ErrPerPageZero{}
 	}
 
-	ordersInfos := []*types.OrderInfo{}
-	var snapshot *db.Snapshot
-	var createdAt time.Time
-	if snapshotID == "" {
-		// Create a new snapshot
-		snapshotID = uuid.New().String()
-		var err error
-		snapshot, err = app.db.Orders.GetSnapshot()
-		if err != nil {
-			return nil, err
-		}
-		createdAt = time.Now().UTC()
-		expirationTimestamp := time.Now().Add(1 * time.Minute)
-		app.snapshotExpirationWatcher.Add(expirationTimestamp, snapshotID)
-		app.muIdToSnapshotInfo.Lock()
-		app.idToSnapshotInfo[snapshotID] = snapshotInfo{
-			Snapshot:            snapshot,
-			CreatedAt:           createdAt,
-			ExpirationTimestamp: expirationTimestamp,
-		}
-		app.muIdToSnapshotInfo.Unlock()
-	} else {
-		// Try and find an existing snapshot
-		app.muIdToSnapshotInfo.Lock()
-		info, ok := app.idToSnapshotInfo[snapshotID]
-		if !ok {
-			app.muIdToSnapshotInfo.Unlock()
-			return nil, ErrSnapshotNotFound{id: snapshotID}
-		}
-		snapshot = info.Snapshot
-		createdAt = info.CreatedAt
-		// Reset the snapshot's expiry
-		app.snapshotExpirationWatcher.Remove(info.ExpirationTimestamp, snapshotID)
-		expirationTimestamp := time.Now().Add(1 * time.Minute)
-		app.snapshotExpirationWatcher.Add(expirationTimestamp, snapshotID)
-		app.idToSnapshotInfo[snapshotID] = snapshotInfo{
-			Snapshot:            snapshot,
-			CreatedAt:           createdAt,
-			ExpirationTimestamp: expirationTimestamp,
-		}
-		app.muIdToSnapshotInfo.Unlock()
+	snapshotID, snapshot, createdAt, err := app.getOrCreateSnapshot(snapshotID)
+	if err != nil {
+		return nil, err
 	}
 
+	ordersInfos := []*types.OrderInfo{}
 	notRemovedFilter := app.db.Orders.IsRemovedIndex.ValueFilter([]byte{0})
 	var selectedOrders []*meshdb.Order
-	err := snapshot.NewQuery(notRemovedFilter).Offset(page * perPage).Max(perPage).Run(&selectedOrders)
+	err = snapshot.NewQuery(notRemovedFilter).Offset(page * perPage).Max(perPage).Run(&selectedOrders)
 	if err != nil {
 		return nil, err
 	}
@@ -929,6 +1278,115 @@ func (app *App) GetOrders(page, perPage int, snapshotID string) (*types.GetOrder
 	return getOrdersResponse, nil
 }
 
+// getOrdersUpdatedSince returns a page of orders which were added or updated
+// at or after the given time. Orders which have been flagged for removal are
+// counted towards the page but not returned, so the number of returned orders
+// may be less than perPage even if there are more pages left. The returned
+// boolean is true if there are no pages left. Like GetOrders, it uses a
+// snapshot of the database so that orders are served consistently across
+// pages.
+func (app *App) getOrdersUpdatedSince(since time.Time, page, perPage int, snapshotID string) (*types.GetOrdersResponse, bool, error) {
+	<-app.started
+
+	if perPage <= 0 {
+		return nil, false, ErrPerPageZero{}
+	}
+
+	snapshotID, snapshot, createdAt, err := app.getOrCreateSnapshot(snapshotID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	start := []byte(since.UTC().Format(time.RFC3339Nano))
+	limit := []byte(time.Date(9999, 12, 31, 23, 59, 59, 0, time.UTC).Format(time.RFC3339Nano))
+	lastUpdatedFilter := app.db.Orders.LastUpdatedIndex.RangeFilter(start, limit)
+	var selectedOrders []*meshdb.Order
+	err = snapshot.NewQuery(lastUpdatedFilter).Offset(page * perPage).Max(perPage).Run(&selectedOrders)
+	if err != nil {
+		return nil, false, err
+	}
+	ordersInfos := []*types.OrderInfo{}
+	for _, order := range selectedOrders {
+		if order.IsRemoved {
+			continue
+		}
+		ordersInfos = append(ordersInfos, &types.OrderInfo{
+			OrderHash:                order.Hash,
+			SignedOrder:              order.SignedOrder,
+			FillableTakerAssetAmount: order.FillableTakerAssetAmount,
+		})
+	}
+
+	getOrdersResponse := &types.GetOrdersResponse{
+		SnapshotID:        snapshotID,
+		SnapshotTimestamp: createdAt,
+		OrdersInfos:       ordersInfos,
+	}
+	return getOrdersResponse, len(selectedOrders) == 0, nil
+}
+
+// getOrCreateSnapshot returns the snapshot with the given ID and resets its
+// expiry. If snapshotID is empty, a new snapshot is created instead. It also
+// returns the ID of the snapshot and the time at which it was created.
+func (app *App) getOrCreateSnapshot(snapshotID string) (string, *db.Snapshot, time.Time, error) {
+	app.muIdToSnapshotInfo.Lock()
+	defer app.muIdToSnapshotInfo.Unlock()
+
+	if snapshotID == "" {
+		// Create a new snapshot
+		snapshotID = uuid.New().String()
+		// createdAt is recorded before the snapshot is taken so that every order
+		// updated after createdAt is guaranteed to be served by a later snapshot.
+		createdAt := time.Now().UTC()
+		snapshot, err := app.db.Orders.GetSnapshot()
+		if err != nil {
+			return "", nil, time.Time{}, err
+		}
+		expirationTimestamp := time.Now().Add(1 * time.Minute)
+		app.snapshotExpirationWatcher.Add(expirationTimestamp, snapshotID)
+		app.idToSnapshotInfo[snapshotID] = snapshotInfo{
+			Snapshot:            snapshot,
+			CreatedAt:           createdAt,
+			ExpirationTimestamp: expirationTimestamp,
+		}
+		return snapshotID, snapshot, createdAt, nil
+	}
+
+	// Try and find an existing snapshot
+	info, ok := app.idToSnapshotInfo[snapshotID]
+	if !ok {
+		return "", nil, time.Time{}, ErrSnapshotNotFound{id: snapshotID}
+	}
+	// Reset the snapshot's expiry
+	app.snapshotExpirationWatcher.Remove(info.ExpirationTimestamp, snapshotID)
+	expirationTimestamp := time.Now().Add(1 * time.Minute)
+	app.snapshotExpirationWatcher.Add(expirationTimestamp, snapshotID)
+	info.ExpirationTimestamp = expirationTimestamp
+	app.idToSnapshotInfo[snapshotID] = info
+	return snapshotID, info.Snapshot, info.CreatedAt, nil
+}
+
+// setSnapshotBloomFilter stores the given bloom filter alongside the snapshot
+// with the given ID. It is discarded when the snapshot expires.
+func (app *App) setSnapshotBloomFilter(snapshotID string, bloomFilter *ordersync.BloomFilter) {
+	app.muIdToSnapshotInfo.Lock()
+	defer app.muIdToSnapshotInfo.Unlock()
+	info, ok := app.idToSnapshotInfo[snapshotID]
+	if !ok {
+		return
+	}
+	info.BloomFilter = bloomFilter
+	app.idToSnapshotInfo[snapshotID] = info
+}
+
+// getSnapshotBloomFilter returns the bloom filter stored alongside the
+// snapshot with the given ID or nil if there is none.
+func (app *App) getSnapshotBloomFilter(snapshotID string) *ordersync.BloomFilter {
+	app.muIdToSnapshotInfo.Lock()
+	defer app.muIdToSnapshotInfo.Unlock()
+	return app.idToSnapshotInfo[snapshotID].BloomFilter
+}
+
 // AddOrders can be used to add orders to Mesh. It validates the given orders
 // and if they are valid, will store and eventually broadcast the orders to
 // peers. If pinned is true, the orders will be marked as pinned, which means
@@ -962,7 +1420,16 @@ func (app *App) AddOrders(ctx context.Context, signedOrdersRaw []*json.RawMessag
 			})
 			continue
 		}
-		if !result.Valid() {
+		isValid := result.Valid()
+		if !isValid {
+			// The order is still accepted if it matches one of the additional
+			// order filters.
+			isValid, err = app.orderFilters.additionalMatchOrderJSON(signedOrderBytes)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if !isValid {
 			log.WithField("signedOrderRaw", string(signedOrderBytes)).Info("Order failed schema validation")
 			status := ordervalidator.RejectedOrderStatus{
 				Code:    ordervalidator.ROInvalidSchemaCode,
@@ -1011,6 +1478,7 @@ func (app *App) AddOrders(ctx context.Context, signedOrdersRaw []*json.RawMessag
 		allValidationResults.Rejected = append(allValidationResults.Rejected, orderInfo)
 	}
 
+	newOrders := []*zeroex.SignedOrder{}
 	for _, acceptedOrderInfo := range allValidationResults.Accepted {
 		// If the order isn't new, we don't add to OrderWatcher, log it's receipt
 		// or share the order with peers.
@@ -1021,25 +1489,207 @@ func (app *App) AddOrders(ctx context.Context, signedOrdersRaw []*json.RawMessag
 		log.WithFields(log.Fields{
 			"orderHash": acceptedOrderInfo.OrderHash.String(),
 		}).Debug("added new valid order via RPC or browser callback")
+		app.orderFilters.recordStored(acceptedOrderInfo.SignedOrder)
+		newOrders = append(newOrders, acceptedOrderInfo.SignedOrder)
+	}
 
-		// Share the order with our peers.
-		if err := app.shareOrder(acceptedOrderInfo.SignedOrder); err != nil {
-			return nil, err
-		}
+	// Share the new orders with our peers.
+	if err := app.shareOrders(ctx, newOrders); err != nil {
+		return nil, err
 	}
 
 	return allValidationResults, nil
 }
 
-// shareOrder immediately shares the given order on the GossipSub network.
-func (app *App) shareOrder(order *zeroex.SignedOrder) error {
+// AddOrdersV4 can be used to add Exchange V4 orders to Mesh. It validates the
+// given orders and if they are valid, will store them. Unlike V3 orders, V4
+// orders are not shared with peers. If pinned is true, the orders will be
+// marked as pinned.
+func (app *App) AddOrdersV4(ctx context.Context, signedOrdersRaw []*json.RawMessage, pinned bool) (*ordervalidator.ValidationResults, error) {
 	<-app.started
 
-	encoded, err := encoding.OrderToRawMessage(app.orderFilter.Topic(), order)
+	allValidationResults := &ordervalidator.ValidationResults{
+		Accepted: []*ordervalidator.AcceptedOrderInfo{},
+		Rejected: []*ordervalidator.RejectedOrderInfo{},
+	}
+	orderHashesSeen := map[common.Hash]struct{}{}
+	signedOrders := []*zeroex.SignedOrderV4{}
+	for _, signedOrderRaw := range signedOrdersRaw {
+		signedOrderBytes := []byte(*signedOrderRaw)
+		signedOrder := &zeroex.SignedOrderV4{}
+		if err := signedOrder.UnmarshalJSON(signedOrderBytes); err != nil {
+			log.WithField("signedOrderRaw", string(signedOrderBytes)).Info("Failed to unmarshal SignedOrderV4")
+			allValidationResults.Rejected = append(allValidationResults.Rejected, &ordervalidator.RejectedOrderInfo{
+				Kind: ordervalidator.MeshValidation,
+				Status: ordervalidator.RejectedOrderStatus{
+					Code:    ordervalidator.ROInvalidSchemaCode,
+					Message: fmt.Sprintf("order could not be decoded: %s", err.Error()),
+				},
+			})
+			continue
+		}
+
+		orderHash, err := signedOrder.ComputeOrderHash()
+		if err != nil {
+			allValidationResults.Rejected = append(allValidationResults.Rejected, &ordervalidator.RejectedOrderInfo{
+				SignedOrderV4: signedOrder,
+				Kind:          ordervalidator.MeshValidation,
+				Status:        ordervalidator.ROInvalidOrderTypeV4,
+			})
+			continue
+		}
+		if _, alreadySeen := orderHashesSeen[orderHash]; alreadySeen {
+			continue
+		}
+
+		signedOrders = append(signedOrders, signedOrder)
+		orderHashesSeen[orderHash] = struct{}{}
+	}
+
+	validationResults, err := app.orderWatcher.ValidateAndStoreValidOrdersV4(ctx, signedOrders, pinned, app.chainID)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return app.node.Send(encoded)
+	allValidationResults.Accepted = append(allValidationResults.Accepted, validationResults.Accepted...)
+	allValidationResults.Rejected = append(allValidationResults.Rejected, validationResults.Rejected...)
+
+	for _, acceptedOrderInfo := range allValidationResults.Accepted {
+		if acceptedOrderInfo.IsNew {
+			log.WithFields(log.Fields{
+				"orderHash": acceptedOrderInfo.OrderHash.String(),
+			}).Debug("added new valid V4 order via RPC")
+		}
+	}
+
+	return allValidationResults, nil
+}
+
+// shareOrders immediately shares the given orders on the GossipSub network.
+// Each order is shared on the topics of all the order filters it matches (and
+// on the default topic if it matches the primary filter). Orders are sent in
+// as few batched messages as possible on the compact versions of these topics.
+// If any of our peers on the topics don't support the compact encoding, each
+// order is also sent as a separate JSON message on the topics themselves.
+// Messages are paced to the per-peer rate limit of our peers, so sharing a large
+// number of orders blocks until all of them have been sent or ctx is canceled.
+func (app *App) shareOrders(ctx context.Context, orders []*zeroex.SignedOrder) error {
+	return app.publishOrders(ctx, orders, true)
+}
+
+// shareOrdersWithLegacyPeers shares the given orders as JSON messages with
+// our peers which don't support the compact encoding. It is used to forward
+// orders which we received in the compact encoding, since GossipSub only
+// forwards messages to peers which are subscribed to the same topic.
+func (app *App) shareOrdersWithLegacyPeers(ctx context.Context, orders []*zeroex.SignedOrder) error {
+	return app.publishOrders(ctx, orders, false)
+}
+
+func (app *App) publishOrders(ctx context.Context, orders []*zeroex.SignedOrder, shareCompact bool) error {
+	<-app.started
+
+	if len(orders) == 0 {
+		return nil
+	}
+
+	// Group the orders by the topics they will be shared on so that orders for
+	// the same set of topics can be batched.
+	groupKeys := []string{}
+	groups := map[string][]*zeroex.SignedOrder{}
+	groupFilters := map[string][]*trackedOrderFilter{}
+	for _, order := range orders {
+		matching, err := app.orderFilters.matching(order)
+		if err != nil {
+			return err
+		}
+		if len(matching) == 0 {
+			continue
+		}
+		key := strings.Join(sortedTopics(matching), ",")
+		if _, found := groups[key]; !found {
+			groupKeys = append(groupKeys, key)
+			groupFilters[key] = matching
+		}
+		groups[key] = append(groups[key], order)
+	}
+
+	for _, key := range groupKeys {
+		groupOrders := groups[key]
+		filters := groupFilters[key]
+		topics := []string{}
+		for _, tracked := range filters {
+			if tracked.primary && tracked.topic != app.defaultTopic {
+				// All orders that match the custom order filter must necessarily
+				// match the default filter. This also allows us to implement
+				// cross-topic forwarding in the future.
+				// See https://github.com/0xProject/0x-mesh/pull/563
+				topics = append(topics, app.defaultTopic)
+			}
+			topics = append(topics, tracked.topic)
+		}
+		shared := false
+		if shareCompact {
+			compactTopics := make([]string, len(topics))
+			for i, topic := range topics {
+				compactTopics[i] = compactTopic(topic)
+			}
+			messages, err := encoding.OrdersToCompactMessages(groupOrders)
+			if err != nil {
+				return err
+			}
+			for _, message := range messages {
+				if err := app.node.PacedSendToTopics(ctx, message, compactTopics); err != nil {
+					return err
+				}
+			}
+			shared = true
+		}
+		if app.hasLegacyTopicPeers(topics) {
+			for _, order := range groupOrders {
+				message, err := encoding.OrderToRawMessage(filters[0].topic, order)
+				if err != nil {
+					return err
+				}
+				if err := app.node.PacedSendToTopics(ctx, message, topics); err != nil {
+					return err
+				}
+			}
+			shared = true
+		}
+		if shared {
+			for _, tracked := range filters {
+				tracked.recordShared(len(groupOrders))
+			}
+		}
+	}
+	return nil
+}
+
+// hasLegacyTopicPeers returns true if at least one of the peers subscribed to
+// the given topics doesn't support the compact encoding.
+func (app *App) hasLegacyTopicPeers(topics []string) bool {
+	for _, peerID := range app.node.TopicPeers(topics) {
+		if !app.node.PeerSupportsProtocol(peerID, compactEncodingProtocolID) {
+			return true
+		}
+	}
+	return false
+}
+
+// compactTopic returns the topic on which orders for the given topic are
+// shared in the compact encoding.
+func compactTopic(topic string) string {
+	return topic + compactTopicSuffix
+}
+
+// isCompactTopic returns true if the given topic was returned by compactTopic.
+func isCompactTopic(topic string) bool {
+	return strings.HasSuffix(topic, compactTopicSuffix)
+}
+
+// legacyTopic returns the topic for which the given topic was returned by
+// compactTopic, or the given topic itself if it isn't a compact topic.
+func legacyTopic(topic string) string {
+	return strings.TrimSuffix(topic, compactTopicSuffix)
 }
 
 // AddPeer can be used to manually connect to a new peer.
@@ -1093,6 +1743,7 @@ func (app *App) GetStats() (*types.Stats, error) {
 		LatestBlock:                       latestBlock,
 		NumOrders:                         numOrders,
 		NumPeers:                          app.node.GetNumPeers(),
+		Reachability:                      string(app.node.Reachability()),
 		NumOrdersIncludingRemoved:         numOrdersIncludingRemoved,
 		NumPinnedOrders:                   numPinnedOrders,
 		MaxExpirationTime:                 app.orderWatcher.MaxExpirationTime().String(),
@@ -1103,6 +1754,157 @@ func (app *App) GetStats() (*types.Stats, error) {
 	return response, nil
 }
 
+// GetTopMakers returns the makers with the most unpinned orders in storage,
+// sorted by number of orders in descending order. At most limit makers are
+// returned. If limit is <= 0, all makers are returned.
+func (app *App) GetTopMakers(limit int) ([]*types.MakerOrderCount, error) {
+	<-app.started
+
+	makerAddressToCount, err := app.db.CountUnpinnedOrdersPerMakerAddress()
+	if err != nil {
+		return nil, err
+	}
+	quotas := app.orderWatcher.OrderQuotas()
+	makers := make([]*types.MakerOrderCount, 0, len(makerAddressToCount))
+	for makerAddress, count := range makerAddressToCount {
+		makers = append(makers, &types.MakerOrderCount{
+			MakerAddress: makerAddress,
+			NumOrders:    count,
+			MaxOrders:    quotas.MaxOrdersForMaker(makerAddress),
+		})
+	}
+	sort.Slice(makers, func(i, j int) bool {
+		if makers[i].NumOrders != makers[j].NumOrders {
+			return makers[i].NumOrders > makers[j].NumOrders
+		}
+		return bytes.Compare(makers[i].MakerAddress.Bytes(), makers[j].MakerAddress.Bytes()) == -1
+	})
+	if limit > 0 && len(makers) > limit {
+		makers = makers[:limit]
+	}
+	return makers, nil
+}
+
+// GetPeers returns information about all connected peers.
+func (app *App) GetPeers() ([]*types.PeerInfo, error) {
+	<-app.started
+
+	peerStats := app.node.GetPeerStats()
+	peers := make([]*types.PeerInfo, len(peerStats))
+	for i, stats := range peerStats {
+		multiaddrs := make([]string, len(stats.Multiaddrs))
+		for j, maddr := range stats.Multiaddrs {
+			multiaddrs[j] = maddr.String()
+		}
+		peers[i] = &types.PeerInfo{
+			PeerID:     stats.ID.Pretty(),
+			Multiaddrs: multiaddrs,
+			Protocols:  stats.Protocols,
+			LatencyMs:  float64(stats.Latency) / float64(time.Millisecond),
+			Score:      stats.Score,
+			Bandwidth: types.BandwidthStats{
+				TotalIn:  stats.Bandwidth.TotalIn,
+				TotalOut: stats.Bandwidth.TotalOut,
+				RateIn:   stats.Bandwidth.RateIn,
+				RateOut:  stats.Bandwidth.RateOut,
+			},
+			Protected: stats.Protected,
+		}
+	}
+	return peers, nil
+}
+
+// DisconnectPeer closes all connections to the given peer.
+func (app *App) DisconnectPeer(peerID peer.ID) error {
+	<-app.started
+
+	return app.node.DisconnectPeer(peerID)
+}
+
+// BanIP bans the IP address of the given multiaddress and disconnects from all
+// peers connected from it.
+func (app *App) BanIP(maddr ma.Multiaddr) error {
+	<-app.started
+
+	return app.node.BanIP(maddr)
+}
+
+// UnbanIP lifts the ban for the IP address of the given multiaddress.
+func (app *App) UnbanIP(maddr ma.Multiaddr) error {
+	<-app.started
+
+	return app.node.UnbanIP(maddr)
+}
+
+// ProtectPeer persistently protects the given peer from being banned or
+// disconnected.
+func (app *App) ProtectPeer(peerID peer.ID) error {
+	<-app.started
+
+	return app.node.ProtectPeer(peerID)
+}
+
+// UnprotectPeer removes the protection for the given peer.
+func (app *App) UnprotectPeer(peerID peer.ID) error {
+	<-app.started
+
+	return app.node.UnprotectPeer(peerID)
+}
+
+// ProtectIP persistently protects the IP address of the given multiaddress from
+// being banned.
+func (app *App) ProtectIP(maddr ma.Multiaddr) error {
+	<-app.started
+
+	return app.node.ProtectIP(maddr)
+}
+
+// UnprotectIP removes the protection for the IP address of the given
+// multiaddress.
+func (app *App) UnprotectIP(maddr ma.Multiaddr) error {
+	<-app.started
+
+	return app.node.UnprotectIP(maddr)
+}
+
+// GetPeerReputations returns the reputation of all known peers and IP
+// addresses, sorted from the lowest to the highest score. If subject is not
+// empty, only the reputation of the peer or IP address with the given ID is
+// returned.
+func (app *App) GetPeerReputations(subject string) ([]*types.PeerReputation, error) {
+	<-app.started
+
+	records := append(app.node.PeerReputations(), app.node.IPReputations()...)
+	reputations := []*types.PeerReputation{}
+	for _, record := range records {
+		if subject != "" && record.Subject != subject {
+			continue
+		}
+		history := make([]*types.PeerReputationEvent, len(record.History))
+		for i, event := range record.History {
+			history[i] = &types.PeerReputationEvent{
+				Time:  event.Time,
+				Tag:   event.Tag,
+				Value: event.Value,
+				Score: event.Score,
+			}
+		}
+		reputations = append(reputations, &types.PeerReputation{
+			Kind:      string(record.Kind),
+			Subject:   record.Subject,
+			Score:     record.Score(),
+			Tags:      record.Tags,
+			Banned:    record.Banned,
+			Protected: record.Protected,
+			History:   history,
+		})
+	}
+	sort.SliceStable(reputations, func(i, j int) bool {
+		return reputations[i].Score < reputations[j].Score
+	})
+	return reputations, nil
+}
+
 func (app *App) periodicallyLogStats(ctx context.Context) {
 	<-app.started
 
@@ -1130,6 +1932,7 @@ func (app *App) periodicallyLogStats(ctx context.Context) {
 			"numOrdersIncludingRemoved":         stats.NumOrdersIncludingRemoved,
 			"numPinnedOrders":                   stats.NumPinnedOrders,
 			"numPeers":                          stats.NumPeers,
+			"reachability":                      stats.Reachability,
 			"maxExpirationTime":                 stats.MaxExpirationTime,
 			"startOfCurrentUTCDay":              stats.StartOfCurrentUTCDay,
 			"ethRPCRequestsSentInCurrentUTCDay": stats.EthRPCRequestsSentInCurrentUTCDay,
@@ -1178,3 +1981,90 @@ func parseAndValidateCustomContractAddresses(chainID int, encodedContractAddress
 	}
 	return customAddresses, nil
 }
+
+func parseCustomAssetProxies(encodedAssetProxies string) ([]zeroex.AssetProxy, error) {
+	assetProxies := []zeroex.AssetProxy{}
+	if err := json.Unmarshal([]byte(encodedAssetProxies), &assetProxies); err != nil {
+		return nil, fmt.Errorf("config.CustomAssetProxies is invalid: %s", err.Error())
+	}
+	if err := zeroex.ValidateAssetProxies(assetProxies); err != nil {
+		return nil, fmt.Errorf("config.CustomAssetProxies is invalid: %s", err.Error())
+	}
+	return assetProxies, nil
+}
+
+// orderQuotaOverrides is the format of the OrderQuotaOverrides config option.
+type orderQuotaOverrides struct {
+	Makers map[common.Address]int `json:"makers"`
+	Peers  map[string]int         `json:"peers"`
+}
+
+func parseOrderQuotas(config Config) (orderwatch.OrderQuotas, error) {
+	if config.MaxOrdersPerMaker < 0 {
+		return orderwatch.OrderQuotas{}, errors.New("config.MaxOrdersPerMaker cannot be negative")
+	}
+	if config.MaxOrdersPerPeer < 0 {
+		return orderwatch.OrderQuotas{}, errors.New("config.MaxOrdersPerPeer cannot be negative")
+	}
+	quotas := orderwatch.OrderQuotas{
+		MaxOrdersPerMaker: config.MaxOrdersPerMaker,
+		MaxOrdersPerPeer:  config.MaxOrdersPerPeer,
+	}
+	if config.OrderQuotaOverrides == "" {
+		return quotas, nil
+	}
+	overrides := orderQuotaOverrides{}
+	if err := json.Unmarshal([]byte(config.OrderQuotaOverrides), &overrides); err != nil {
+		return orderwatch.OrderQuotas{}, fmt.Errorf("config.OrderQuotaOverrides is invalid: %s", err.Error())
+	}
+	for makerAddress, maxOrders := range overrides.Makers {
+		if maxOrders < 0 {
+			return orderwatch.OrderQuotas{}, fmt.Errorf("config.OrderQuotaOverrides is invalid: quota for maker %s cannot be negative", makerAddress.Hex())
+		}
+	}
+	for peerID, maxOrders := range overrides.Peers {
+		if _, err := peer.IDB58Decode(peerID); err != nil {
+			return orderwatch.OrderQuotas{}, fmt.Errorf("config.OrderQuotaOverrides is invalid: %q is not a valid peer ID", peerID)
+		}
+		if maxOrders < 0 {
+			return orderwatch.OrderQuotas{}, fmt.Errorf("config.OrderQuotaOverrides is invalid: quota for peer %s cannot be negative", peerID)
+		}
+	}
+	quotas.MakerOverrides = overrides.Makers
+	quotas.PeerOverrides = overrides.Peers
+	return quotas, nil
+}
+
+// parseOrderEvictionPolicy returns the eviction policy for the order watcher.
+// It returns nil for the default "expiration" policy, which is built into the
+// order watcher.
+func parseOrderEvictionPolicy(config Config) (orderwatch.EvictionPolicy, error) {
+	switch config.OrderEvictionPolicy {
+	case "", "expiration":
+		return nil, nil
+	case "value":
+		if config.OrderEvictionTokenPrices == "" {
+			return nil, errors.New("config.OrderEvictionTokenPrices is required when config.OrderEvictionPolicy is \"value\"")
+		}
+		tokenPrices := orderwatch.TokenPrices{}
+		if err := json.Unmarshal([]byte(config.OrderEvictionTokenPrices), &tokenPrices); err != nil {
+			return nil, fmt.Errorf("config.OrderEvictionTokenPrices is invalid: %s", err.Error())
+		}
+		return orderwatch.NewOrderValueEvictionPolicy(tokenPrices), nil
+	case "spread":
+		return orderwatch.NewSpreadEvictionPolicy(), nil
+	case "reputation":
+		if config.OrderEvictionMakerReputations == "" {
+			return nil, errors.New("config.OrderEvictionMakerReputations is required when config.OrderEvictionPolicy is \"reputation\"")
+		}
+		makerReputations := orderwatch.MakerReputations{}
+		if err := json.Unmarshal([]byte(config.OrderEvictionMakerReputations), &makerReputations); err != nil {
+			return nil, fmt.Errorf("config.OrderEvictionMakerReputations is invalid: %s", err.Error())
+		}
+		return orderwatch.NewMakerReputationEvictionPolicy(makerReputations), nil
+	case "age":
+		return orderwatch.NewAgeEvictionPolicy(), nil
+	default:
+		return nil, fmt.Errorf("config.OrderEvictionPolicy is invalid: unknown policy %q", config.OrderEvictionPolicy)
+	}
+}
@@ -201,6 +201,13 @@ func TestOrderSync(t *testing.T) {
 				paginationSubprotocolPerPage: 10,
 			},
 		},
+		{
+			name: "BloomFilterSubprotocol with some orders already stored",
+			pConfig: privateConfig{
+				paginationSubprotocolPerPage: 10,
+			},
+			numOrdersAlreadyStored: 15,
+		},
 		{
 			name:              "makerAssetAmount orderfilter - match all orders",
 			customOrderFilter: `{"properties":{"makerAssetAmount":{"pattern":"^1$","type":"string"}}}`,
@@ -236,6 +243,10 @@ type ordersyncTestCase struct {
 	customOrderFilter    string
 	orderOptionsForIndex func(int) []orderopts.Option
 	pConfig              privateConfig
+	// numOrdersAlreadyStored is the number of orders which the new node
+	// already has before ordersync begins. These orders should not be
+	// received again.
+	numOrdersAlreadyStored int
 }
 
 const defaultOrderFilter = "{}"
@@ -289,6 +300,13 @@ func runOrdersyncTestCase(t *testing.T, testCase ordersyncTestCase) func(t *test
 		}()
 		<-newNode.started
 
+		if testCase.numOrdersAlreadyStored > 0 {
+			time.Sleep(blockProcessingWaitTime)
+			results, err := newNode.orderWatcher.ValidateAndStoreValidOrders(ctx, originalOrders[:testCase.numOrdersAlreadyStored], true, constants.TestChainID)
+			require.NoError(t, err)
+			require.Empty(t, results.Rejected, "tried to add orders but some were invalid: \n%s\n", spew.Sdump(results))
+		}
+
 		orderEventsChan := make(chan []*zeroex.OrderEvent)
 		orderEventsSub := newNode.SubscribeToOrderEvents(orderEventsChan)
 		defer orderEventsSub.Unsubscribe()
@@ -325,7 +343,7 @@ func runOrdersyncTestCase(t *testing.T, testCase ordersyncTestCase) func(t *test
 						receivedAddedEvents = append(receivedAddedEvents, orderEvent)
 					}
 				}
-				if len(receivedAddedEvents) >= len(filteredOrders) {
+				if len(receivedAddedEvents) >= len(filteredOrders)-testCase.numOrdersAlreadyStored {
 					break OrderEventLoop
 				}
 			}
@@ -9,6 +9,7 @@ import (
 	"github.com/0xProject/0x-mesh/zeroex"
 	"github.com/0xProject/0x-mesh/zeroex/ordervalidator"
 	"github.com/ethereum/go-ethereum/common"
+	peer "github.com/libp2p/go-libp2p-core/peer"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -18,6 +19,7 @@ var _ p2p.MessageHandler = &App{}
 func (app *App) HandleMessages(ctx context.Context, messages []*p2p.Message) error {
 	// First we validate the messages and decode them into orders.
 	orders := []*zeroex.SignedOrder{}
+	senders := []peer.ID{}
 	orderHashToMessage := map[common.Hash]*p2p.Message{}
 
 	for _, msg := range messages {
@@ -32,7 +34,9 @@ func (app *App) HandleMessages(ctx context.Context, messages []*p2p.Message) err
 			continue
 		}
 
-		order, err := encoding.RawMessageToOrder(msg.Data)
+		// A single message may contain a batch of orders. From here on, every
+		// order is accounted for separately.
+		msgOrders, err := encoding.RawMessageToOrders(msg.Data)
 		if err != nil {
 			log.WithFields(map[string]interface{}{
 				"error": err,
@@ -41,27 +45,34 @@ func (app *App) HandleMessages(ctx context.Context, messages []*p2p.Message) err
 			app.handlePeerScoreEvent(msg.From, psInvalidMessage)
 			continue
 		}
-		orderHash, err := order.ComputeOrderHash()
-		if err != nil {
-			return err
+		if tracked := app.orderFilters.get(legacyTopic(msg.Topic)); tracked != nil {
+			tracked.recordReceived(len(msgOrders))
 		}
-		// Validate doesn't guarantee there are no duplicates so we keep track of
-		// which orders we've already seen.
-		if _, alreadySeen := orderHashToMessage[orderHash]; alreadySeen {
-			continue
+		for _, order := range msgOrders {
+			orderHash, err := order.ComputeOrderHash()
+			if err != nil {
+				return err
+			}
+			// Validate doesn't guarantee there are no duplicates so we keep track of
+			// which orders we've already seen.
+			if _, alreadySeen := orderHashToMessage[orderHash]; alreadySeen {
+				continue
+			}
+			orders = append(orders, order)
+			senders = append(senders, msg.From)
+			orderHashToMessage[orderHash] = msg
+			app.handlePeerScoreEvent(msg.From, psValidMessage)
 		}
-		orders = append(orders, order)
-		orderHashToMessage[orderHash] = msg
-		app.handlePeerScoreEvent(msg.From, psValidMessage)
 	}
 
 	// Next, we validate the orders.
-	validationResults, err := app.orderWatcher.ValidateAndStoreValidOrders(ctx, orders, false, app.chainID)
+	validationResults, err := app.orderWatcher.ValidateAndStoreValidOrdersFromPeers(ctx, orders, senders, app.chainID)
 	if err != nil {
 		return err
 	}
 
 	// Store any valid orders and update the peer scores.
+	compactOrders := []*zeroex.SignedOrder{}
 	for _, acceptedOrderInfo := range validationResults.Accepted {
 		// If the order isn't new, we don't log it's receipt or adjust peer scores
 		if !acceptedOrderInfo.IsNew {
@@ -83,6 +94,19 @@ func (app *App) HandleMessages(ctx context.Context, messages []*p2p.Message) err
 			"protocol":  "GossipSub",
 		}).Trace("all fields for new valid order received from peer")
 		app.handlePeerScoreEvent(msg.From, psOrderStored)
+		app.orderFilters.recordStored(acceptedOrderInfo.SignedOrder)
+		if isCompactTopic(msg.Topic) {
+			compactOrders = append(compactOrders, acceptedOrderInfo.SignedOrder)
+		}
+	}
+	if len(compactOrders) > 0 {
+		// Peers which don't support the compact encoding can't receive these
+		// orders through GossipSub, so we forward them as JSON messages.
+		go func() {
+			if err := app.shareOrdersWithLegacyPeers(ctx, compactOrders); err != nil {
+				log.WithError(err).Error("could not share orders with legacy peers")
+			}
+		}()
 	}
 
 	// We don't store invalid orders, but in some cases still need to update peer
@@ -94,9 +118,16 @@ func (app *App) HandleMessages(ctx context.Context, messages []*p2p.Message) err
 			"from":              msg.From.String(),
 		}).Trace("not storing rejected order received from peer")
 		switch rejectedOrderInfo.Status {
-		case ordervalidator.ROInternalError, ordervalidator.ROEthRPCRequestFailed, ordervalidator.ROCoordinatorRequestFailed, ordervalidator.RODatabaseFullOfOrders:
+		case ordervalidator.ROInternalError, ordervalidator.ROEthRPCRequestFailed, ordervalidator.ROCoordinatorRequestFailed, ordervalidator.RODatabaseFullOfOrders,
+			ordervalidator.ROMakerOrderQuotaExceeded, ordervalidator.ROPeerOrderQuotaExceeded, ordervalidator.ROOrderWouldBeEvicted:
 			// Don't incur a negative score for these status types (it might not be
-			// their fault).
+			// their fault). Quotas and eviction policies are local to our node, so
+			// peers have no way of knowing that an order would be rejected by them.
+		case ordervalidator.ROExpired, ordervalidator.ROFullyFilled, ordervalidator.ROCancelled, ordervalidator.ROUnfunded,
+			ordervalidator.ROCoordinatorSoftCancelled, ordervalidator.ROOrderAlreadyStoredAndUnfillable, ordervalidator.ROMaxExpirationExceeded:
+			// These orders may have been valid when the peer sent them, so they only
+			// incur a bounded penalty.
+			app.handlePeerScoreEvent(msg.From, psStaleOrder)
 		default:
 			// For other status types, we need to update the peer's score
 			app.handlePeerScoreEvent(msg.From, psInvalidMessage)
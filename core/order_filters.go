@@ -0,0 +1,290 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/0xProject/0x-mesh/common/types"
+	"github.com/0xProject/0x-mesh/orderfilter"
+	"github.com/0xProject/0x-mesh/zeroex"
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	// ErrOrderFilterExists is returned by AddOrderFilter if an equivalent
+	// filter is already in use.
+	ErrOrderFilterExists = errors.New("order filter already exists")
+	// ErrOrderFilterNotFound is returned by RemoveOrderFilter if there is no
+	// filter for the given topic.
+	ErrOrderFilterNotFound = errors.New("no order filter found for topic")
+	// ErrPrimaryOrderFilter is returned by RemoveOrderFilter when attempting to
+	// remove the primary order filter.
+	ErrPrimaryOrderFilter = errors.New("cannot remove the primary order filter")
+)
+
+// InvalidOrderFilterError is returned by AddOrderFilter if the given filter
+// is not a valid JSON Schema.
+type InvalidOrderFilterError struct {
+	err error
+}
+
+func (e InvalidOrderFilterError) Error() string {
+	return fmt.Sprintf("invalid order filter: %s", e.err.Error())
+}
+
+// trackedOrderFilter is an order filter in an orderFilterSet along with some
+// stats about how the filter is being used.
+type trackedOrderFilter struct {
+	filter  *orderfilter.Filter
+	topic   string
+	primary bool
+	addedAt time.Time
+	// The following fields must be accessed atomically.
+	ordersReceived uint64
+	ordersStored   uint64
+	ordersShared   uint64
+}
+
+func (f *trackedOrderFilter) info() *types.OrderFilterInfo {
+	return &types.OrderFilterInfo{
+		Topic:             f.topic,
+		CustomOrderFilter: f.filter.CustomOrderSchema(),
+		Primary:           f.primary,
+		AddedAt:           f.addedAt,
+		OrdersReceived:    atomic.LoadUint64(&f.ordersReceived),
+		OrdersStored:      atomic.LoadUint64(&f.ordersStored),
+		OrdersShared:      atomic.LoadUint64(&f.ordersShared),
+	}
+}
+
+// recordReceived increments the number of orders received on the topic of
+// the filter.
+func (f *trackedOrderFilter) recordReceived(numOrders int) {
+	atomic.AddUint64(&f.ordersReceived, uint64(numOrders))
+}
+
+// recordShared increments the number of orders shared on the topic of the
+// filter.
+func (f *trackedOrderFilter) recordShared(numOrders int) {
+	atomic.AddUint64(&f.ordersShared, uint64(numOrders))
+}
+
+// orderFilterSet is the set of order filters used by Mesh. It always contains
+// the primary filter from Config.CustomOrderFilter. Orders are stored if they
+// match at least one of the filters and are shared on the topics of all the
+// filters they match.
+type orderFilterSet struct {
+	mut sync.RWMutex
+	// filters is ordered by the time each filter was added. The primary filter
+	// is always first.
+	filters []*trackedOrderFilter
+}
+
+func newOrderFilterSet(primary *orderfilter.Filter) *orderFilterSet {
+	return &orderFilterSet{
+		filters: []*trackedOrderFilter{
+			{
+				filter:  primary,
+				topic:   primary.Topic(),
+				primary: true,
+				addedAt: time.Now(),
+			},
+		},
+	}
+}
+
+// add adds a new filter to the set. It returns ErrOrderFilterExists if there
+// is already a filter with the same topic.
+func (s *orderFilterSet) add(filter *orderfilter.Filter) (*trackedOrderFilter, error) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	topic := filter.Topic()
+	for _, existing := range s.filters {
+		if existing.topic == topic {
+			return nil, ErrOrderFilterExists
+		}
+	}
+	tracked := &trackedOrderFilter{
+		filter:  filter,
+		topic:   topic,
+		addedAt: time.Now(),
+	}
+	s.filters = append(s.filters, tracked)
+	return tracked, nil
+}
+
+// remove removes the filter with the given topic from the set.
+func (s *orderFilterSet) remove(topic string) error {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	for i, existing := range s.filters {
+		if existing.topic != topic {
+			continue
+		}
+		if existing.primary {
+			return ErrPrimaryOrderFilter
+		}
+		s.filters = append(s.filters[:i], s.filters[i+1:]...)
+		return nil
+	}
+	return ErrOrderFilterNotFound
+}
+
+// get returns the filter with the given topic or nil if there is none.
+func (s *orderFilterSet) get(topic string) *trackedOrderFilter {
+	s.mut.RLock()
+	defer s.mut.RUnlock()
+	for _, existing := range s.filters {
+		if existing.topic == topic {
+			return existing
+		}
+	}
+	return nil
+}
+
+// all returns all filters in the set, starting with the primary filter.
+func (s *orderFilterSet) all() []*trackedOrderFilter {
+	s.mut.RLock()
+	defer s.mut.RUnlock()
+	return append([]*trackedOrderFilter{}, s.filters...)
+}
+
+// additional returns all filters in the set except for the primary filter.
+func (s *orderFilterSet) additional() []*trackedOrderFilter {
+	return s.all()[1:]
+}
+
+// matching returns all filters which the given order matches.
+func (s *orderFilterSet) matching(order *zeroex.SignedOrder) ([]*trackedOrderFilter, error) {
+	matching := []*trackedOrderFilter{}
+	for _, tracked := range s.all() {
+		matches, err := tracked.filter.MatchOrder(order)
+		if err != nil {
+			return nil, err
+		}
+		if matches {
+			matching = append(matching, tracked)
+		}
+	}
+	return matching, nil
+}
+
+// additionalMatchOrderJSON returns true if the given order matches at least
+// one of the filters except for the primary filter.
+func (s *orderFilterSet) additionalMatchOrderJSON(orderJSON []byte) (bool, error) {
+	for _, tracked := range s.additional() {
+		result, err := tracked.filter.ValidateOrderJSON(orderJSON)
+		if err != nil {
+			return false, err
+		}
+		if result.Valid() {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// recordStored increments the number of stored orders for all filters which
+// the given order matches.
+func (s *orderFilterSet) recordStored(order *zeroex.SignedOrder) {
+	matching, err := s.matching(order)
+	if err != nil {
+		log.WithError(err).Error("could not match order against order filters")
+		return
+	}
+	for _, tracked := range matching {
+		atomic.AddUint64(&tracked.ordersStored, 1)
+	}
+}
+
+// parseCustomOrderFilters parses the value of Config.CustomOrderFilters into a
+// list of JSON Schemas.
+func parseCustomOrderFilters(customOrderFilters string) ([]string, error) {
+	if strings.TrimSpace(customOrderFilters) == "" {
+		return nil, nil
+	}
+	var schemas []json.RawMessage
+	if err := json.Unmarshal([]byte(customOrderFilters), &schemas); err != nil {
+		return nil, fmt.Errorf("CustomOrderFilters must be a JSON array of JSON Schemas: %s", err.Error())
+	}
+	result := make([]string, len(schemas))
+	for i, schema := range schemas {
+		result[i] = string(schema)
+	}
+	return result, nil
+}
+
+// GetOrderFilters returns information about all order filters used by Mesh,
+// starting with the primary filter.
+func (app *App) GetOrderFilters() ([]*types.OrderFilterInfo, error) {
+	<-app.started
+
+	infos := []*types.OrderFilterInfo{}
+	for _, tracked := range app.orderFilters.all() {
+		infos = append(infos, tracked.info())
+	}
+	return infos, nil
+}
+
+// AddOrderFilter adds a new custom order filter at runtime. Mesh subscribes to
+// the topic of the filter, stores orders which match it and shares those
+// orders on its topic. Filters added at runtime are not persisted across
+// restarts.
+func (app *App) AddOrderFilter(customOrderFilter string) (*types.OrderFilterInfo, error) {
+	<-app.started
+
+	filter, err := orderfilter.New(app.config.EthereumChainID, customOrderFilter, *app.contractAddresses)
+	if err != nil {
+		return nil, InvalidOrderFilterError{err: err}
+	}
+	tracked, err := app.orderFilters.add(filter)
+	if err != nil {
+		return nil, err
+	}
+	if err := app.node.AddTopic(tracked.topic, filter.ValidatePubSubMessage); err != nil {
+		_ = app.orderFilters.remove(tracked.topic)
+		return nil, err
+	}
+	if err := app.node.AddTopic(compactTopic(tracked.topic), filter.ValidatePubSubMessage); err != nil {
+		_ = app.orderFilters.remove(tracked.topic)
+		_ = app.node.RemoveTopic(tracked.topic)
+		return nil, err
+	}
+	log.WithField("topic", tracked.topic).Info("added custom order filter")
+	return tracked.info(), nil
+}
+
+// RemoveOrderFilter removes the custom order filter with the given topic. Mesh
+// unsubscribes from the topic and stops sharing orders on it. Orders which
+// were already stored are not removed. The primary filter cannot be removed.
+func (app *App) RemoveOrderFilter(topic string) error {
+	<-app.started
+
+	if err := app.orderFilters.remove(topic); err != nil {
+		return err
+	}
+	if err := app.node.RemoveTopic(topic); err != nil {
+		return err
+	}
+	if err := app.node.RemoveTopic(compactTopic(topic)); err != nil {
+		return err
+	}
+	log.WithField("topic", topic).Info("removed custom order filter")
+	return nil
+}
+
+// sortedTopics returns the topics of the given filters in sorted order.
+func sortedTopics(filters []*trackedOrderFilter) []string {
+	topics := make([]string, len(filters))
+	for i, tracked := range filters {
+		topics[i] = tracked.topic
+	}
+	sort.Strings(topics)
+	return topics
+}
@@ -0,0 +1,90 @@
+// +build !js
+
+package core
+
+import (
+	"testing"
+
+	"github.com/0xProject/0x-mesh/constants"
+	"github.com/0xProject/0x-mesh/orderfilter"
+	"github.com/0xProject/0x-mesh/zeroex"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	standardOrderJSON       = []byte(`{"makerAddress":"0xa3ece5d5b6319fa785efc10d3112769a46c6e149","takerAddress":"0x0000000000000000000000000000000000000000","makerAssetAmount":"100000000000000000000","takerAssetAmount":"100000000000000000000000","expirationTimeSeconds":"1559856615025","makerFee":"0","takerFee":"0","feeRecipientAddress":"0x0000000000000000000000000000000000000000","senderAddress":"0x0000000000000000000000000000000000000000","salt":"46108882540880341679561755865076495033942060608820537332859096815711589201849","makerAssetData":"0xf47261b0000000000000000000000000e41d2489571d322189246dafa5ebde1f4699f498","takerAssetData":"0xf47261b0000000000000000000000000c02aaa39b223fe8d0a0e5c4f27ead9083c756cc2","makerFeeAssetData":"0x","takerFeeAssetData":"0x","exchangeAddress":"0x48bacb9266a570d521063ef5dd96e61686dbe788","chainId":1337,"signature":"0x1c52f75daa4bd2ad9e6e8a7c35adbd089d709e48ae86463f2abfafa3578747fafc264a04d02fa26227e90476d57bca94e24af32f1cc8da444bba21092ca56cd85603"}`)
+	specificSenderOrderJSON = []byte(`{"makerAddress":"0xa3ece5d5b6319fa785efc10d3112769a46c6e149","takerAddress":"0x0000000000000000000000000000000000000000","makerAssetAmount":"100000000000000000000","takerAssetAmount":"100000000000000000000000","expirationTimeSeconds":"1559856615025","makerFee":"0","takerFee":"0","feeRecipientAddress":"0x0000000000000000000000000000000000000000","senderAddress":"0x00000000000000000000000000000000ba5eba11","salt":"46108882540880341679561755865076495033942060608820537332859096815711589201849","makerAssetData":"0xf47261b0000000000000000000000000e41d2489571d322189246dafa5ebde1f4699f498","takerAssetData":"0xf47261b0000000000000000000000000c02aaa39b223fe8d0a0e5c4f27ead9083c756cc2","makerFeeAssetData":"0x","takerFeeAssetData":"0x","exchangeAddress":"0x48bacb9266a570d521063ef5dd96e61686dbe788","chainId":1337,"signature":"0x1c52f75daa4bd2ad9e6e8a7c35adbd089d709e48ae86463f2abfafa3578747fafc264a04d02fa26227e90476d57bca94e24af32f1cc8da444bba21092ca56cd85603"}`)
+	specificSenderSchema    = `{"properties":{"senderAddress":{"pattern":"0x00000000000000000000000000000000ba5eba11"}}}`
+	specificTakerSchema     = `{"properties":{"takerAddress":{"pattern":"0x00000000000000000000000000000000ba5eba11"}}}`
+)
+
+func TestOrderFilterSet(t *testing.T) {
+	primary, err := orderfilter.New(constants.TestChainID, orderfilter.DefaultCustomOrderSchema, contractAddresses)
+	require.NoError(t, err)
+	filters := newOrderFilterSet(primary)
+	senderFilter, err := orderfilter.New(constants.TestChainID, specificSenderSchema, contractAddresses)
+	require.NoError(t, err)
+	takerFilter, err := orderfilter.New(constants.TestChainID, specificTakerSchema, contractAddresses)
+	require.NoError(t, err)
+
+	trackedSender, err := filters.add(senderFilter)
+	require.NoError(t, err)
+	_, err = filters.add(takerFilter)
+	require.NoError(t, err)
+	_, err = filters.add(senderFilter)
+	assert.Equal(t, ErrOrderFilterExists, err)
+	require.Len(t, filters.all(), 3)
+	assert.Len(t, filters.additional(), 2)
+	assert.Equal(t, trackedSender, filters.get(senderFilter.Topic()))
+
+	standardOrder := &zeroex.SignedOrder{}
+	require.NoError(t, standardOrder.UnmarshalJSON(standardOrderJSON))
+	specificSenderOrder := &zeroex.SignedOrder{}
+	require.NoError(t, specificSenderOrder.UnmarshalJSON(specificSenderOrderJSON))
+
+	matching, err := filters.matching(standardOrder)
+	require.NoError(t, err)
+	assert.Equal(t, []string{primary.Topic()}, sortedTopics(matching))
+	matching, err = filters.matching(specificSenderOrder)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{primary.Topic(), senderFilter.Topic()}, sortedTopics(matching))
+
+	matches, err := filters.additionalMatchOrderJSON(specificSenderOrderJSON)
+	require.NoError(t, err)
+	assert.True(t, matches)
+	matches, err = filters.additionalMatchOrderJSON(standardOrderJSON)
+	require.NoError(t, err)
+	assert.False(t, matches)
+
+	filters.recordStored(specificSenderOrder)
+	trackedSender.recordReceived(2)
+	trackedSender.recordShared(3)
+	info := trackedSender.info()
+	assert.Equal(t, senderFilter.Topic(), info.Topic)
+	assert.Equal(t, specificSenderSchema, info.CustomOrderFilter)
+	assert.False(t, info.Primary)
+	assert.Equal(t, uint64(2), info.OrdersReceived)
+	assert.Equal(t, uint64(1), info.OrdersStored)
+	assert.Equal(t, uint64(3), info.OrdersShared)
+	assert.Equal(t, uint64(1), filters.all()[0].info().OrdersStored)
+
+	assert.Equal(t, ErrPrimaryOrderFilter, filters.remove(primary.Topic()))
+	require.NoError(t, filters.remove(senderFilter.Topic()))
+	assert.Equal(t, ErrOrderFilterNotFound, filters.remove(senderFilter.Topic()))
+	assert.Nil(t, filters.get(senderFilter.Topic()))
+	assert.Len(t, filters.all(), 2)
+}
+
+func TestParseCustomOrderFilters(t *testing.T) {
+	schemas, err := parseCustomOrderFilters("")
+	require.NoError(t, err)
+	assert.Empty(t, schemas)
+
+	schemas, err = parseCustomOrderFilters(`[` + specificSenderSchema + `, ` + specificTakerSchema + `]`)
+	require.NoError(t, err)
+	assert.Equal(t, []string{specificSenderSchema, specificTakerSchema}, schemas)
+
+	_, err = parseCustomOrderFilters(specificSenderSchema)
+	assert.Error(t, err)
+}
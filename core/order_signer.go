@@ -0,0 +1,212 @@
+package core
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"time"
+
+	"github.com/0xProject/0x-mesh/common/types"
+	"github.com/0xProject/0x-mesh/ethereum/signer"
+	"github.com/0xProject/0x-mesh/zeroex"
+	"github.com/0xProject/0x-mesh/zeroex/ordervalidator"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/rpc"
+	log "github.com/sirupsen/logrus"
+)
+
+// defaultCreatedOrderExpiration is how long orders created with CreateOrder
+// are valid for if no expiration time is given.
+const defaultCreatedOrderExpiration = 1 * time.Hour
+
+// ErrNoOrderSigner is returned by CreateOrder if no order signer was
+// configured.
+var ErrNoOrderSigner = errors.New("no order signer configured")
+
+// maxSalt is the exclusive upper bound for randomly generated salts.
+var maxSalt = new(big.Int).Lsh(big.NewInt(1), 256)
+
+// InvalidCreateOrderParamsError is returned by CreateOrder if one of the given
+// parameters is invalid.
+type InvalidCreateOrderParamsError struct {
+	field string
+	err   error
+}
+
+func (e InvalidCreateOrderParamsError) Error() string {
+	return fmt.Sprintf("invalid %s: %s", e.field, e.err.Error())
+}
+
+// initOrderSigner creates the signer used by CreateOrder from the config and
+// returns it along with the maker address of created orders. It returns a nil
+// signer if no order signer was configured.
+func initOrderSigner(config Config) (signer.Signer, common.Address, error) {
+	if config.OrderSignerKeystorePath != "" && config.OrderSignerRPCURL != "" {
+		return nil, common.Address{}, errors.New("config.OrderSignerKeystorePath and config.OrderSignerRPCURL cannot both be set")
+	}
+	switch {
+	case config.OrderSignerKeystorePath != "":
+		keyJSON, err := ioutil.ReadFile(config.OrderSignerKeystorePath)
+		if err != nil {
+			return nil, common.Address{}, fmt.Errorf("could not read order signer keystore: %s", err.Error())
+		}
+		localSigner, err := signer.NewKeystoreSigner(keyJSON, config.OrderSignerKeystorePassphrase)
+		if err != nil {
+			return nil, common.Address{}, fmt.Errorf("could not decrypt order signer keystore: %s", err.Error())
+		}
+		return localSigner, localSigner.GetSignerAddress(), nil
+	case config.OrderSignerRPCURL != "":
+		if !common.IsHexAddress(config.OrderSignerAddress) {
+			return nil, common.Address{}, errors.New("config.OrderSignerAddress must be a valid address when config.OrderSignerRPCURL is set")
+		}
+		rpcClient, err := rpc.Dial(config.OrderSignerRPCURL)
+		if err != nil {
+			return nil, common.Address{}, err
+		}
+		return signer.NewEthRPCSigner(rpcClient), common.HexToAddress(config.OrderSignerAddress), nil
+	default:
+		return nil, common.Address{}, nil
+	}
+}
+
+// CreateOrder creates an order from the given parameters, signs it with the
+// configured order signer and adds it to Mesh. Missing parameters are filled
+// with default values. The validation results of the signed order are
+// returned in the same format as AddOrders.
+func (app *App) CreateOrder(ctx context.Context, params types.CreateOrderParams, pinned bool) (*ordervalidator.ValidationResults, error) {
+	if app.orderSigner == nil {
+		return nil, ErrNoOrderSigner
+	}
+	order, err := newOrderFromParams(params, big.NewInt(int64(app.chainID)), app.contractAddresses.Exchange, app.orderSignerAddress, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	signedOrder, err := zeroex.SignOrder(app.orderSigner, order, zeroex.EIP712Signature)
+	if err != nil {
+		return nil, err
+	}
+	orderHash, err := signedOrder.ComputeOrderHash()
+	if err != nil {
+		return nil, err
+	}
+	log.WithFields(map[string]interface{}{
+		"orderHash":    orderHash.Hex(),
+		"makerAddress": signedOrder.MakerAddress.Hex(),
+	}).Debug("created and signed order")
+
+	signedOrderBytes, err := json.Marshal(signedOrder)
+	if err != nil {
+		return nil, err
+	}
+	signedOrderRaw := json.RawMessage(signedOrderBytes)
+	return app.AddOrders(ctx, []*json.RawMessage{&signedOrderRaw}, pinned)
+}
+
+// newOrderFromParams converts the given parameters to an unsigned order and
+// fills in the default values for missing parameters.
+func newOrderFromParams(params types.CreateOrderParams, chainID *big.Int, exchangeAddress common.Address, makerAddress common.Address, now time.Time) (*zeroex.Order, error) {
+	order := &zeroex.Order{
+		ChainID:         chainID,
+		ExchangeAddress: exchangeAddress,
+		MakerAddress:    makerAddress,
+	}
+	var err error
+	if order.MakerAssetData, err = parseAssetData("makerAssetData", params.MakerAssetData, true); err != nil {
+		return nil, err
+	}
+	if order.TakerAssetData, err = parseAssetData("takerAssetData", params.TakerAssetData, true); err != nil {
+		return nil, err
+	}
+	if order.MakerFeeAssetData, err = parseAssetData("makerFeeAssetData", params.MakerFeeAssetData, false); err != nil {
+		return nil, err
+	}
+	if order.TakerFeeAssetData, err = parseAssetData("takerFeeAssetData", params.TakerFeeAssetData, false); err != nil {
+		return nil, err
+	}
+	if order.MakerAssetAmount, err = parseAmount("makerAssetAmount", params.MakerAssetAmount, nil); err != nil {
+		return nil, err
+	}
+	if order.TakerAssetAmount, err = parseAmount("takerAssetAmount", params.TakerAssetAmount, nil); err != nil {
+		return nil, err
+	}
+	if order.MakerFee, err = parseAmount("makerFee", params.MakerFee, big.NewInt(0)); err != nil {
+		return nil, err
+	}
+	if order.TakerFee, err = parseAmount("takerFee", params.TakerFee, big.NewInt(0)); err != nil {
+		return nil, err
+	}
+	defaultExpirationTime := big.NewInt(now.Add(defaultCreatedOrderExpiration).Unix())
+	if order.ExpirationTimeSeconds, err = parseAmount("expirationTimeSeconds", params.ExpirationTimeSeconds, defaultExpirationTime); err != nil {
+		return nil, err
+	}
+	if params.Salt == "" {
+		if order.Salt, err = rand.Int(rand.Reader, maxSalt); err != nil {
+			return nil, err
+		}
+	} else if order.Salt, err = parseAmount("salt", params.Salt, nil); err != nil {
+		return nil, err
+	}
+	if order.TakerAddress, err = parseAddress("takerAddress", params.TakerAddress); err != nil {
+		return nil, err
+	}
+	if order.SenderAddress, err = parseAddress("senderAddress", params.SenderAddress); err != nil {
+		return nil, err
+	}
+	if order.FeeRecipientAddress, err = parseAddress("feeRecipientAddress", params.FeeRecipientAddress); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// parseAssetData decodes hex encoded asset data. If the asset data is empty
+// and not required, it returns empty asset data.
+func parseAssetData(field string, assetData string, required bool) ([]byte, error) {
+	if assetData == "" {
+		if required {
+			return nil, InvalidCreateOrderParamsError{field: field, err: errors.New("required")}
+		}
+		return []byte{}, nil
+	}
+	decoded, err := hexutil.Decode(assetData)
+	if err != nil {
+		return nil, InvalidCreateOrderParamsError{field: field, err: err}
+	}
+	if len(decoded) == 0 && required {
+		return nil, InvalidCreateOrderParamsError{field: field, err: errors.New("required")}
+	}
+	return decoded, nil
+}
+
+// parseAmount parses a decimal or hex encoded uint256. If the amount is empty,
+// it returns defaultValue, or an error if there is no default value.
+func parseAmount(field string, amount string, defaultValue *big.Int) (*big.Int, error) {
+	if amount == "" {
+		if defaultValue == nil {
+			return nil, InvalidCreateOrderParamsError{field: field, err: errors.New("required")}
+		}
+		return defaultValue, nil
+	}
+	parsed, ok := math.ParseBig256(amount)
+	if !ok || parsed.Sign() < 0 {
+		return nil, InvalidCreateOrderParamsError{field: field, err: errors.New("not a valid uint256")}
+	}
+	return parsed, nil
+}
+
+// parseAddress parses a hex encoded address. If the address is empty, it
+// returns the null address.
+func parseAddress(field string, address string) (common.Address, error) {
+	if address == "" {
+		return common.Address{}, nil
+	}
+	if !common.IsHexAddress(address) {
+		return common.Address{}, InvalidCreateOrderParamsError{field: field, err: errors.New("not a valid address")}
+	}
+	return common.HexToAddress(address), nil
+}
@@ -0,0 +1,95 @@
+// +build !js
+
+package core
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/0xProject/0x-mesh/common/types"
+	"github.com/0xProject/0x-mesh/constants"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testMakerAssetData = "0xf47261b0000000000000000000000000e41d2489571d322189246dafa5ebde1f4699f498"
+	testTakerAssetData = "0xf47261b0000000000000000000000000c02aaa39b223fe8d0a0e5c4f27ead9083c756cc2"
+)
+
+func TestNewOrderFromParamsDefaults(t *testing.T) {
+	now := time.Now()
+	params := types.CreateOrderParams{
+		MakerAssetData:   testMakerAssetData,
+		MakerAssetAmount: "100",
+		TakerAssetData:   testTakerAssetData,
+		TakerAssetAmount: "200",
+	}
+	order, err := newOrderFromParams(params, big.NewInt(constants.TestChainID), contractAddresses.Exchange, constants.GanacheAccount0, now)
+	require.NoError(t, err)
+
+	assert.Equal(t, big.NewInt(constants.TestChainID), order.ChainID)
+	assert.Equal(t, contractAddresses.Exchange, order.ExchangeAddress)
+	assert.Equal(t, constants.GanacheAccount0, order.MakerAddress)
+	assert.Equal(t, common.FromHex(testMakerAssetData), order.MakerAssetData)
+	assert.Equal(t, common.FromHex(testTakerAssetData), order.TakerAssetData)
+	assert.Equal(t, big.NewInt(100), order.MakerAssetAmount)
+	assert.Equal(t, big.NewInt(200), order.TakerAssetAmount)
+	assert.Equal(t, big.NewInt(0), order.MakerFee)
+	assert.Equal(t, big.NewInt(0), order.TakerFee)
+	assert.Equal(t, []byte{}, order.MakerFeeAssetData)
+	assert.Equal(t, []byte{}, order.TakerFeeAssetData)
+	assert.Equal(t, constants.NullAddress, order.TakerAddress)
+	assert.Equal(t, constants.NullAddress, order.SenderAddress)
+	assert.Equal(t, constants.NullAddress, order.FeeRecipientAddress)
+	assert.Equal(t, big.NewInt(now.Add(defaultCreatedOrderExpiration).Unix()), order.ExpirationTimeSeconds)
+	require.NotNil(t, order.Salt)
+
+	// Orders with the same parameters get different salts.
+	otherOrder, err := newOrderFromParams(params, big.NewInt(constants.TestChainID), contractAddresses.Exchange, constants.GanacheAccount0, now)
+	require.NoError(t, err)
+	assert.NotEqual(t, order.Salt, otherOrder.Salt)
+}
+
+func TestNewOrderFromParamsInvalid(t *testing.T) {
+	validParams := types.CreateOrderParams{
+		MakerAssetData:   testMakerAssetData,
+		MakerAssetAmount: "100",
+		TakerAssetData:   testTakerAssetData,
+		TakerAssetAmount: "200",
+	}
+	testCases := []struct {
+		name          string
+		modify        func(params *types.CreateOrderParams)
+		expectedError string
+	}{
+		{
+			name:          "missing maker asset data",
+			modify:        func(params *types.CreateOrderParams) { params.MakerAssetData = "" },
+			expectedError: "invalid makerAssetData: required",
+		},
+		{
+			name:          "missing taker asset amount",
+			modify:        func(params *types.CreateOrderParams) { params.TakerAssetAmount = "" },
+			expectedError: "invalid takerAssetAmount: required",
+		},
+		{
+			name:          "negative maker fee",
+			modify:        func(params *types.CreateOrderParams) { params.MakerFee = "-1" },
+			expectedError: "invalid makerFee: not a valid uint256",
+		},
+		{
+			name:          "invalid taker address",
+			modify:        func(params *types.CreateOrderParams) { params.TakerAddress = "0x1234" },
+			expectedError: "invalid takerAddress: not a valid address",
+		},
+	}
+	for _, testCase := range testCases {
+		params := validParams
+		testCase.modify(&params)
+		_, err := newOrderFromParams(params, big.NewInt(constants.TestChainID), contractAddresses.Exchange, constants.GanacheAccount0, time.Now())
+		assert.EqualError(t, err, testCase.expectedError, testCase.name)
+	}
+}
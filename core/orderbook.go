@@ -0,0 +1,375 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/0xProject/0x-mesh/common/types"
+	"github.com/0xProject/0x-mesh/zeroex"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// orderbookPriceDecimals is the number of decimals to which prices are rounded.
+// Orders whose prices are equal after rounding belong to the same price level.
+const orderbookPriceDecimals = 18
+
+// orderbookEventsBufferSize is the buffer size of the channel which receives
+// order events for an orderbook subscription.
+const orderbookEventsBufferSize = 8000
+
+// ErrInvalidAssetPair is returned by GetOrderbook and SubscribeToOrderbook if
+// either asset data is empty or both are the same.
+var ErrInvalidAssetPair = errors.New("makerAssetData and takerAssetData must be non-empty and different")
+
+// GetOrderbook returns the aggregated orderbook for the given asset pair,
+// computed from all orders which are currently stored.
+func (app *App) GetOrderbook(makerAssetData, takerAssetData []byte) (*types.Orderbook, error) {
+	<-app.started
+
+	book, err := app.loadOrderbook(makerAssetData, takerAssetData)
+	if err != nil {
+		return nil, err
+	}
+	return book.snapshot(), nil
+}
+
+// SubscribeToOrderbook subscribes to changes of the aggregated orderbook for
+// the given asset pair. The first update sent to sink contains all price
+// levels of the orderbook. Every following update contains the price levels
+// which were changed by a batch of order events.
+func (app *App) SubscribeToOrderbook(makerAssetData, takerAssetData []byte, sink chan<- *types.OrderbookUpdate) (event.Subscription, error) {
+	<-app.started
+
+	if err := validateAssetPair(makerAssetData, takerAssetData); err != nil {
+		return nil, err
+	}
+	// Subscribe to order events before loading the orderbook so that no event
+	// is missed. Events for orders which are already reflected by the loaded
+	// orderbook are harmless, because orderbook.update is idempotent.
+	orderEventsChan := make(chan []*zeroex.OrderEvent, orderbookEventsBufferSize)
+	orderEventsSubscription := app.SubscribeToOrderEvents(orderEventsChan)
+	book, err := app.loadOrderbook(makerAssetData, takerAssetData)
+	if err != nil {
+		orderEventsSubscription.Unsubscribe()
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer orderEventsSubscription.Unsubscribe()
+		update := book.fullUpdate()
+		for {
+			if update != nil {
+				select {
+				case sink <- update:
+				case <-quit:
+					return nil
+				}
+			}
+			select {
+			case orderEvents := <-orderEventsChan:
+				update = book.handleOrderEvents(orderEvents)
+			case err := <-orderEventsSubscription.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+func (app *App) loadOrderbook(makerAssetData, takerAssetData []byte) (*orderbook, error) {
+	if err := validateAssetPair(makerAssetData, takerAssetData); err != nil {
+		return nil, err
+	}
+	// Asks have the requested maker and taker asset data and bids have them
+	// the other way around.
+	asks, err := app.db.FindOrdersByAssetPair(makerAssetData, takerAssetData)
+	if err != nil {
+		return nil, err
+	}
+	bids, err := app.db.FindOrdersByAssetPair(takerAssetData, makerAssetData)
+	if err != nil {
+		return nil, err
+	}
+	book := newOrderbook(makerAssetData, takerAssetData)
+	for _, order := range append(asks, bids...) {
+		book.update(order.Hash, order.SignedOrder, order.FillableTakerAssetAmount)
+	}
+	return book, nil
+}
+
+func validateAssetPair(makerAssetData, takerAssetData []byte) error {
+	if len(makerAssetData) == 0 || len(takerAssetData) == 0 || bytes.Equal(makerAssetData, takerAssetData) {
+		return ErrInvalidAssetPair
+	}
+	return nil
+}
+
+type orderbookSide int
+
+const (
+	bidSide orderbookSide = iota
+	askSide
+)
+
+// orderbookEntry is the contribution of a single order to a price level.
+type orderbookEntry struct {
+	side             orderbookSide
+	price            string
+	makerAssetAmount *big.Int
+	takerAssetAmount *big.Int
+}
+
+type priceLevelTotals struct {
+	makerAssetAmount *big.Int
+	takerAssetAmount *big.Int
+	numOrders        int
+}
+
+// orderbook aggregates the orders for an asset pair into price levels. It
+// keeps track of the contribution of every order so that it can be updated
+// incrementally. It is not safe for concurrent use.
+type orderbook struct {
+	makerAssetData []byte
+	takerAssetData []byte
+	entries        map[common.Hash]*orderbookEntry
+	levels         map[orderbookSide]map[string]*priceLevelTotals
+}
+
+func newOrderbook(makerAssetData, takerAssetData []byte) *orderbook {
+	return &orderbook{
+		makerAssetData: makerAssetData,
+		takerAssetData: takerAssetData,
+		entries:        map[common.Hash]*orderbookEntry{},
+		levels: map[orderbookSide]map[string]*priceLevelTotals{
+			bidSide: {},
+			askSide: {},
+		},
+	}
+}
+
+// update sets the contribution of the given order to the orderbook, replacing
+// any previous contribution. Orders which are not for the asset pair of the
+// orderbook or which are not fillable are removed. It returns the price levels
+// which were touched, keyed by side.
+func (b *orderbook) update(orderHash common.Hash, signedOrder *zeroex.SignedOrder, fillableTakerAssetAmount *big.Int) map[orderbookSide][]string {
+	touched := map[orderbookSide][]string{}
+	if oldEntry, found := b.entries[orderHash]; found {
+		b.subtract(oldEntry)
+		delete(b.entries, orderHash)
+		touched[oldEntry.side] = append(touched[oldEntry.side], oldEntry.price)
+	}
+	if signedOrder == nil || fillableTakerAssetAmount == nil {
+		return touched
+	}
+	newEntry := b.newEntry(signedOrder, fillableTakerAssetAmount)
+	if newEntry == nil {
+		return touched
+	}
+	b.add(newEntry)
+	b.entries[orderHash] = newEntry
+	touched[newEntry.side] = append(touched[newEntry.side], newEntry.price)
+	return touched
+}
+
+// newEntry returns the contribution of the given order to the orderbook, or
+// nil if the order does not contribute to it. The price of an order includes
+// its remaining taker fee if the fee is paid in either asset of the pair.
+func (b *orderbook) newEntry(signedOrder *zeroex.SignedOrder, fillableTakerAssetAmount *big.Int) *orderbookEntry {
+	var side orderbookSide
+	switch {
+	case bytes.Equal(signedOrder.MakerAssetData, b.makerAssetData) && bytes.Equal(signedOrder.TakerAssetData, b.takerAssetData):
+		side = askSide
+	case bytes.Equal(signedOrder.MakerAssetData, b.takerAssetData) && bytes.Equal(signedOrder.TakerAssetData, b.makerAssetData):
+		side = bidSide
+	default:
+		return nil
+	}
+	if fillableTakerAssetAmount.Sign() <= 0 || signedOrder.TakerAssetAmount.Sign() <= 0 {
+		return nil
+	}
+	fillableMakerAssetAmount := new(big.Int).Mul(fillableTakerAssetAmount, signedOrder.MakerAssetAmount)
+	fillableMakerAssetAmount.Div(fillableMakerAssetAmount, signedOrder.TakerAssetAmount)
+
+	effectiveMakerAssetAmount := new(big.Int).Set(fillableMakerAssetAmount)
+	effectiveTakerAssetAmount := new(big.Int).Set(fillableTakerAssetAmount)
+	if signedOrder.TakerFee != nil && signedOrder.TakerFee.Sign() > 0 {
+		remainingTakerFee := new(big.Int).Mul(signedOrder.TakerFee, fillableTakerAssetAmount)
+		remainingTakerFee.Div(remainingTakerFee, signedOrder.TakerAssetAmount)
+		switch {
+		case bytes.Equal(signedOrder.TakerFeeAssetData, signedOrder.TakerAssetData):
+			effectiveTakerAssetAmount.Add(effectiveTakerAssetAmount, remainingTakerFee)
+		case bytes.Equal(signedOrder.TakerFeeAssetData, signedOrder.MakerAssetData):
+			effectiveMakerAssetAmount.Sub(effectiveMakerAssetAmount, remainingTakerFee)
+		}
+	}
+	if effectiveMakerAssetAmount.Sign() <= 0 {
+		return nil
+	}
+
+	entry := &orderbookEntry{side: side}
+	if side == askSide {
+		entry.price = formatPrice(new(big.Rat).SetFrac(effectiveTakerAssetAmount, effectiveMakerAssetAmount))
+		entry.makerAssetAmount = fillableMakerAssetAmount
+		entry.takerAssetAmount = fillableTakerAssetAmount
+	} else {
+		entry.price = formatPrice(new(big.Rat).SetFrac(effectiveMakerAssetAmount, effectiveTakerAssetAmount))
+		entry.makerAssetAmount = fillableTakerAssetAmount
+		entry.takerAssetAmount = fillableMakerAssetAmount
+	}
+	return entry
+}
+
+func (b *orderbook) add(entry *orderbookEntry) {
+	levels := b.levels[entry.side]
+	level, found := levels[entry.price]
+	if !found {
+		level = &priceLevelTotals{
+			makerAssetAmount: big.NewInt(0),
+			takerAssetAmount: big.NewInt(0),
+		}
+		levels[entry.price] = level
+	}
+	level.makerAssetAmount.Add(level.makerAssetAmount, entry.makerAssetAmount)
+	level.takerAssetAmount.Add(level.takerAssetAmount, entry.takerAssetAmount)
+	level.numOrders++
+}
+
+func (b *orderbook) subtract(entry *orderbookEntry) {
+	levels := b.levels[entry.side]
+	level := levels[entry.price]
+	level.numOrders--
+	if level.numOrders == 0 {
+		delete(levels, entry.price)
+		return
+	}
+	level.makerAssetAmount.Sub(level.makerAssetAmount, entry.makerAssetAmount)
+	level.takerAssetAmount.Sub(level.takerAssetAmount, entry.takerAssetAmount)
+}
+
+// handleOrderEvents applies the given order events to the orderbook and
+// returns the price levels which changed, or nil if none did. Events for
+// pending fills and cancellations are ignored since the order is still
+// fillable until the transaction is mined.
+func (b *orderbook) handleOrderEvents(orderEvents []*zeroex.OrderEvent) *types.OrderbookUpdate {
+	touched := map[orderbookSide]map[string]struct{}{
+		bidSide: {},
+		askSide: {},
+	}
+	for _, orderEvent := range orderEvents {
+		var touchedLevels map[orderbookSide][]string
+		switch orderEvent.EndState {
+		case zeroex.ESOrderPendingFill, zeroex.ESOrderPendingCancel:
+			continue
+		case zeroex.ESOrderFullyFilled, zeroex.ESOrderCancelled, zeroex.ESOrderExpired, zeroex.ESOrderBecameUnfunded, zeroex.ESStoppedWatching, zeroex.ESInvalid:
+			touchedLevels = b.update(orderEvent.OrderHash, nil, nil)
+		default:
+			touchedLevels = b.update(orderEvent.OrderHash, orderEvent.SignedOrder, orderEvent.FillableTakerAssetAmount)
+		}
+		for side, prices := range touchedLevels {
+			for _, price := range prices {
+				touched[side][price] = struct{}{}
+			}
+		}
+	}
+	if len(touched[bidSide]) == 0 && len(touched[askSide]) == 0 {
+		return nil
+	}
+	return &types.OrderbookUpdate{
+		MakerAssetData: hexutil.Encode(b.makerAssetData),
+		TakerAssetData: hexutil.Encode(b.takerAssetData),
+		Bids:           b.changedPriceLevels(bidSide, touched[bidSide]),
+		Asks:           b.changedPriceLevels(askSide, touched[askSide]),
+	}
+}
+
+func (b *orderbook) changedPriceLevels(side orderbookSide, prices map[string]struct{}) []*types.PriceLevel {
+	levels := make([]*types.PriceLevel, 0, len(prices))
+	for price := range prices {
+		if totals, found := b.levels[side][price]; found {
+			levels = append(levels, totals.priceLevel(price))
+			continue
+		}
+		levels = append(levels, &types.PriceLevel{
+			Price:            price,
+			MakerAssetAmount: big.NewInt(0),
+			TakerAssetAmount: big.NewInt(0),
+		})
+	}
+	sortPriceLevels(side, levels)
+	return levels
+}
+
+// snapshot returns all price levels of the orderbook, including their depth.
+func (b *orderbook) snapshot() *types.Orderbook {
+	return &types.Orderbook{
+		MakerAssetData: hexutil.Encode(b.makerAssetData),
+		TakerAssetData: hexutil.Encode(b.takerAssetData),
+		Bids:           b.sortedPriceLevels(bidSide, true),
+		Asks:           b.sortedPriceLevels(askSide, true),
+	}
+}
+
+// fullUpdate returns an update which contains all price levels of the
+// orderbook. Like any other update, it does not include the depth.
+func (b *orderbook) fullUpdate() *types.OrderbookUpdate {
+	return &types.OrderbookUpdate{
+		MakerAssetData: hexutil.Encode(b.makerAssetData),
+		TakerAssetData: hexutil.Encode(b.takerAssetData),
+		Bids:           b.sortedPriceLevels(bidSide, false),
+		Asks:           b.sortedPriceLevels(askSide, false),
+	}
+}
+
+func (b *orderbook) sortedPriceLevels(side orderbookSide, includeDepth bool) []*types.PriceLevel {
+	levels := make([]*types.PriceLevel, 0, len(b.levels[side]))
+	for price, totals := range b.levels[side] {
+		levels = append(levels, totals.priceLevel(price))
+	}
+	sortPriceLevels(side, levels)
+	if includeDepth {
+		depth := big.NewInt(0)
+		for _, level := range levels {
+			depth.Add(depth, level.MakerAssetAmount)
+			level.Depth = new(big.Int).Set(depth)
+		}
+	}
+	return levels
+}
+
+func (l *priceLevelTotals) priceLevel(price string) *types.PriceLevel {
+	return &types.PriceLevel{
+		Price:            price,
+		MakerAssetAmount: new(big.Int).Set(l.makerAssetAmount),
+		TakerAssetAmount: new(big.Int).Set(l.takerAssetAmount),
+		NumOrders:        l.numOrders,
+	}
+}
+
+// sortPriceLevels sorts asks by ascending price and bids by descending price,
+// so that the best price always comes first.
+func sortPriceLevels(side orderbookSide, levels []*types.PriceLevel) {
+	prices := make(map[string]*big.Rat, len(levels))
+	for _, level := range levels {
+		prices[level.Price], _ = new(big.Rat).SetString(level.Price)
+	}
+	sort.Slice(levels, func(i, j int) bool {
+		cmp := prices[levels[i].Price].Cmp(prices[levels[j].Price])
+		if side == bidSide {
+			return cmp == 1
+		}
+		return cmp == -1
+	})
+}
+
+// formatPrice formats the given price as a decimal string rounded to
+// orderbookPriceDecimals decimals, without trailing zeros.
+func formatPrice(price *big.Rat) string {
+	formatted := price.FloatString(orderbookPriceDecimals)
+	formatted = strings.TrimRight(formatted, "0")
+	return strings.TrimSuffix(formatted, ".")
+}
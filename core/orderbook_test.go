@@ -0,0 +1,145 @@
+// +build !js
+
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/0xProject/0x-mesh/common/types"
+	"github.com/0xProject/0x-mesh/constants"
+	"github.com/0xProject/0x-mesh/zeroex"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	orderbookTestMakerAssetData = common.Hex2Bytes("f47261b0000000000000000000000000871dd7c2b4b25e1aa18728e9d5f2af4c4e431f5c")
+	orderbookTestTakerAssetData = common.Hex2Bytes("f47261b00000000000000000000000000b1ba0af832d7c05fd64161e0db78e85978e8082")
+	orderbookTestOtherAssetData = common.Hex2Bytes("f47261b000000000000000000000000034d402f14d58e001d8efbe6585051bf9706aa064")
+)
+
+func newOrderbookTestOrder(makerAssetData, takerAssetData []byte, makerAssetAmount, takerAssetAmount int64) *zeroex.SignedOrder {
+	return &zeroex.SignedOrder{
+		Order: zeroex.Order{
+			ChainID:               big.NewInt(constants.TestChainID),
+			MakerAssetData:        makerAssetData,
+			TakerAssetData:        takerAssetData,
+			MakerFeeAssetData:     constants.NullBytes,
+			TakerFeeAssetData:     constants.NullBytes,
+			MakerAssetAmount:      big.NewInt(makerAssetAmount),
+			TakerAssetAmount:      big.NewInt(takerAssetAmount),
+			MakerFee:              big.NewInt(0),
+			TakerFee:              big.NewInt(0),
+			Salt:                  big.NewInt(0),
+			ExpirationTimeSeconds: big.NewInt(0),
+		},
+	}
+}
+
+func newTestPriceLevel(price string, makerAssetAmount, takerAssetAmount int64, depth int64, numOrders int) *types.PriceLevel {
+	level := &types.PriceLevel{
+		Price:            price,
+		MakerAssetAmount: big.NewInt(makerAssetAmount),
+		TakerAssetAmount: big.NewInt(takerAssetAmount),
+		NumOrders:        numOrders,
+	}
+	if depth != 0 {
+		level.Depth = big.NewInt(depth)
+	}
+	return level
+}
+
+func TestOrderbook(t *testing.T) {
+	book := newOrderbook(orderbookTestMakerAssetData, orderbookTestTakerAssetData)
+
+	// Two asks with the same price which belong to the same price level.
+	askA := newOrderbookTestOrder(orderbookTestMakerAssetData, orderbookTestTakerAssetData, 100, 200)
+	book.update(common.HexToHash("0xa"), askA, big.NewInt(200))
+	askB := newOrderbookTestOrder(orderbookTestMakerAssetData, orderbookTestTakerAssetData, 50, 100)
+	book.update(common.HexToHash("0xb"), askB, big.NewInt(50))
+	// The taker fee of this ask is paid in the taker asset, so it raises the
+	// price.
+	askC := newOrderbookTestOrder(orderbookTestMakerAssetData, orderbookTestTakerAssetData, 100, 100)
+	askC.TakerFee = big.NewInt(10)
+	askC.TakerFeeAssetData = orderbookTestTakerAssetData
+	book.update(common.HexToHash("0xc"), askC, big.NewInt(100))
+	bidD := newOrderbookTestOrder(orderbookTestTakerAssetData, orderbookTestMakerAssetData, 300, 100)
+	book.update(common.HexToHash("0xd"), bidD, big.NewInt(100))
+	// The taker fee of this bid is paid in the asset the taker receives, so it
+	// lowers the price. Only the remaining fee is taken into account.
+	bidE := newOrderbookTestOrder(orderbookTestTakerAssetData, orderbookTestMakerAssetData, 100, 100)
+	bidE.TakerFee = big.NewInt(50)
+	bidE.TakerFeeAssetData = orderbookTestTakerAssetData
+	book.update(common.HexToHash("0xe"), bidE, big.NewInt(50))
+	// Orders for other asset pairs are ignored.
+	otherPairOrder := newOrderbookTestOrder(orderbookTestMakerAssetData, orderbookTestOtherAssetData, 100, 100)
+	book.update(common.HexToHash("0xf"), otherPairOrder, big.NewInt(100))
+
+	expectedOrderbook := &types.Orderbook{
+		MakerAssetData: "0x" + common.Bytes2Hex(orderbookTestMakerAssetData),
+		TakerAssetData: "0x" + common.Bytes2Hex(orderbookTestTakerAssetData),
+		Bids: []*types.PriceLevel{
+			newTestPriceLevel("3", 100, 300, 100, 1),
+			newTestPriceLevel("0.5", 50, 50, 150, 1),
+		},
+		Asks: []*types.PriceLevel{
+			newTestPriceLevel("1.1", 100, 100, 100, 1),
+			newTestPriceLevel("2", 125, 250, 225, 2),
+		},
+	}
+	assert.Equal(t, expectedOrderbook, book.snapshot())
+
+	// Pending fills do not change the orderbook.
+	update := book.handleOrderEvents([]*zeroex.OrderEvent{
+		{
+			OrderHash:                common.HexToHash("0xd"),
+			SignedOrder:              bidD,
+			EndState:                 zeroex.ESOrderPendingFill,
+			FillableTakerAssetAmount: big.NewInt(0),
+		},
+	})
+	assert.Nil(t, update)
+
+	update = book.handleOrderEvents([]*zeroex.OrderEvent{
+		{
+			OrderHash:                common.HexToHash("0xa"),
+			SignedOrder:              askA,
+			EndState:                 zeroex.ESOrderFullyFilled,
+			FillableTakerAssetAmount: big.NewInt(0),
+		},
+		{
+			OrderHash:                common.HexToHash("0xc"),
+			SignedOrder:              askC,
+			EndState:                 zeroex.ESOrderCancelled,
+			FillableTakerAssetAmount: big.NewInt(0),
+		},
+		{
+			OrderHash:                common.HexToHash("0xd"),
+			SignedOrder:              bidD,
+			EndState:                 zeroex.ESOrderFilled,
+			FillableTakerAssetAmount: big.NewInt(40),
+		},
+	})
+	require.NotNil(t, update)
+	expectedUpdate := &types.OrderbookUpdate{
+		MakerAssetData: expectedOrderbook.MakerAssetData,
+		TakerAssetData: expectedOrderbook.TakerAssetData,
+		Bids: []*types.PriceLevel{
+			newTestPriceLevel("3", 40, 120, 0, 1),
+		},
+		Asks: []*types.PriceLevel{
+			// Levels without any orders have been removed.
+			newTestPriceLevel("1.1", 0, 0, 0, 0),
+			newTestPriceLevel("2", 25, 50, 0, 1),
+		},
+	}
+	assert.Equal(t, expectedUpdate, update)
+}
+
+func TestValidateAssetPair(t *testing.T) {
+	assert.NoError(t, validateAssetPair(orderbookTestMakerAssetData, orderbookTestTakerAssetData))
+	assert.Equal(t, ErrInvalidAssetPair, validateAssetPair(orderbookTestMakerAssetData, orderbookTestMakerAssetData))
+	assert.Equal(t, ErrInvalidAssetPair, validateAssetPair(orderbookTestMakerAssetData, nil))
+}
@@ -0,0 +1,146 @@
+package ordersync
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	// MaxBloomFilterBytes is the maximum size of the bit array of a BloomFilter
+	// that will be accepted from a peer. 4 MiB is enough to hold ~3.5 million
+	// order hashes with a false positive rate of 1%.
+	MaxBloomFilterBytes = 4 * 1024 * 1024
+	// maxBloomFilterHashes is the maximum number of hash functions a BloomFilter
+	// received from a peer may use.
+	maxBloomFilterHashes = 32
+)
+
+// BloomFilter is a probabilistic set of order hashes. It is used by set
+// reconciliation subprotocols to summarize the orders a peer already has so
+// that the other peer only needs to send the orders that are missing.
+// Contains never returns a false negative, but may return a false positive.
+//
+// Each BloomFilter has a random seed which is mixed into every hash. This
+// ensures that the orders which are false positives differ between filters,
+// so that an order which was not sent during one run of ordersync will very
+// likely be sent in the next.
+type BloomFilter struct {
+	bits      []byte
+	numHashes uint32
+	seed      uint64
+}
+
+// bloomFilterJSON is the JSON representation of a BloomFilter.
+type bloomFilterJSON struct {
+	Bits      []byte `json:"bits"`
+	NumHashes uint32 `json:"numHashes"`
+	Seed      uint64 `json:"seed"`
+}
+
+// NewBloomFilter creates an empty BloomFilter which is sized to hold
+// expectedItems order hashes with the given false positive rate. The size of
+// the filter is capped at MaxBloomFilterBytes, in which case the actual false
+// positive rate will be higher.
+func NewBloomFilter(expectedItems int, falsePositiveRate float64, seed uint64) (*BloomFilter, error) {
+	if falsePositiveRate <= 0 || falsePositiveRate >= 1 {
+		return nil, fmt.Errorf("invalid false positive rate: %f", falsePositiveRate)
+	}
+	if expectedItems < 1 {
+		expectedItems = 1
+	}
+	// m = -n * ln(p) / ln(2)^2 and k = m / n * ln(2)
+	numBits := math.Ceil(-float64(expectedItems) * math.Log(falsePositiveRate) / (math.Ln2 * math.Ln2))
+	numBytes := int(math.Ceil(numBits / 8))
+	if numBytes > MaxBloomFilterBytes {
+		// Peers would reject a larger filter, so we accept a higher false
+		// positive rate instead.
+		numBytes = MaxBloomFilterBytes
+	}
+	numHashes := uint32(math.Round(float64(numBytes*8) / float64(expectedItems) * math.Ln2))
+	if numHashes < 1 {
+		numHashes = 1
+	} else if numHashes > maxBloomFilterHashes {
+		numHashes = maxBloomFilterHashes
+	}
+	return &BloomFilter{
+		bits:      make([]byte, numBytes),
+		numHashes: numHashes,
+		seed:      seed,
+	}, nil
+}
+
+// Add adds the given order hash to the filter.
+func (f *BloomFilter) Add(orderHash common.Hash) {
+	h1, h2 := f.baseHashes(orderHash)
+	numBits := uint64(len(f.bits)) * 8
+	for i := uint64(0); i < uint64(f.numHashes); i++ {
+		bit := (h1 + i*h2) % numBits
+		f.bits[bit/8] |= 1 << (bit % 8)
+	}
+}
+
+// Contains returns true if the given order hash might have been added to the
+// filter and false if it definitely has not.
+func (f *BloomFilter) Contains(orderHash common.Hash) bool {
+	h1, h2 := f.baseHashes(orderHash)
+	numBits := uint64(len(f.bits)) * 8
+	for i := uint64(0); i < uint64(f.numHashes); i++ {
+		bit := (h1 + i*h2) % numBits
+		if f.bits[bit/8]&(1<<(bit%8)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// baseHashes returns the two hashes from which all of the filter's hash
+// functions are derived (see Kirsch and Mitzenmacher, "Less Hashing, Same
+// Performance").
+func (f *BloomFilter) baseHashes(orderHash common.Hash) (uint64, uint64) {
+	var preimage [8 + common.HashLength]byte
+	binary.BigEndian.PutUint64(preimage[:8], f.seed)
+	copy(preimage[8:], orderHash.Bytes())
+	digest := sha256.Sum256(preimage[:])
+	h1 := binary.BigEndian.Uint64(digest[0:8])
+	// h2 must be odd so that it is never a multiple of the number of bits,
+	// which is always a multiple of 8.
+	h2 := binary.BigEndian.Uint64(digest[8:16]) | 1
+	return h1, h2
+}
+
+// MarshalJSON implements json.Marshaler.
+func (f *BloomFilter) MarshalJSON() ([]byte, error) {
+	return json.Marshal(bloomFilterJSON{
+		Bits:      f.bits,
+		NumHashes: f.numHashes,
+		Seed:      f.seed,
+	})
+}
+
+// UnmarshalJSON implements json.Unmarshaler. Since bloom filters are received
+// from peers, it returns an error if the filter is empty or too large.
+func (f *BloomFilter) UnmarshalJSON(data []byte) error {
+	var filterJSON bloomFilterJSON
+	if err := json.Unmarshal(data, &filterJSON); err != nil {
+		return err
+	}
+	if len(filterJSON.Bits) == 0 {
+		return errors.New("bloom filter cannot be empty")
+	}
+	if len(filterJSON.Bits) > MaxBloomFilterBytes {
+		return fmt.Errorf("bloom filter exceeds the maximum size (%d bytes)", MaxBloomFilterBytes)
+	}
+	if filterJSON.NumHashes < 1 || filterJSON.NumHashes > maxBloomFilterHashes {
+		return fmt.Errorf("bloom filter has invalid number of hashes: %d", filterJSON.NumHashes)
+	}
+	f.bits = filterJSON.Bits
+	f.numHashes = filterJSON.NumHashes
+	f.seed = filterJSON.Seed
+	return nil
+}
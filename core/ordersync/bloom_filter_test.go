@@ -0,0 +1,113 @@
+package ordersync
+
+import (
+	"encoding/json"
+	"math/rand"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func randomOrderHash(r *rand.Rand) common.Hash {
+	var hash common.Hash
+	_, _ = r.Read(hash[:])
+	return hash
+}
+
+func TestBloomFilter(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	numItems := 10000
+	falsePositiveRate := 0.01
+	filter, err := NewBloomFilter(numItems, falsePositiveRate, 1337)
+	require.NoError(t, err)
+
+	added := make([]common.Hash, numItems)
+	for i := range added {
+		added[i] = randomOrderHash(r)
+		filter.Add(added[i])
+	}
+	// There should never be any false negatives.
+	for _, hash := range added {
+		assert.True(t, filter.Contains(hash), "false negative for %s", hash.Hex())
+	}
+
+	// The false positive rate should be roughly the expected rate.
+	numChecks := 10000
+	falsePositives := 0
+	for i := 0; i < numChecks; i++ {
+		if filter.Contains(randomOrderHash(r)) {
+			falsePositives++
+		}
+	}
+	assert.InDelta(t, falsePositiveRate, float64(falsePositives)/float64(numChecks), falsePositiveRate)
+}
+
+func TestBloomFilterSeed(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	// A tiny filter with a high false positive rate, so that collisions happen
+	// frequently.
+	filterA, err := NewBloomFilter(10, 0.5, 1)
+	require.NoError(t, err)
+	filterB, err := NewBloomFilter(10, 0.5, 2)
+	require.NoError(t, err)
+	for i := 0; i < 10; i++ {
+		hash := randomOrderHash(r)
+		filterA.Add(hash)
+		filterB.Add(hash)
+	}
+	// Filters with different seeds should not produce the same false positives.
+	differences := 0
+	for i := 0; i < 1000; i++ {
+		hash := randomOrderHash(r)
+		if filterA.Contains(hash) != filterB.Contains(hash) {
+			differences++
+		}
+	}
+	assert.NotZero(t, differences)
+}
+
+func TestBloomFilterMarshalUnmarshalJSON(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	filter, err := NewBloomFilter(100, 0.01, 1337)
+	require.NoError(t, err)
+	added := make([]common.Hash, 100)
+	for i := range added {
+		added[i] = randomOrderHash(r)
+		filter.Add(added[i])
+	}
+
+	encoded, err := json.Marshal(filter)
+	require.NoError(t, err)
+	var decoded BloomFilter
+	require.NoError(t, json.Unmarshal(encoded, &decoded))
+	assert.Equal(t, filter, &decoded)
+	for _, hash := range added {
+		assert.True(t, decoded.Contains(hash))
+	}
+}
+
+func TestBloomFilterUnmarshalJSONInvalid(t *testing.T) {
+	testCases := []struct {
+		name    string
+		encoded string
+	}{
+		{
+			name:    "empty bits",
+			encoded: `{"bits":"","numHashes":3,"seed":0}`,
+		},
+		{
+			name:    "zero hashes",
+			encoded: `{"bits":"AAAA","numHashes":0,"seed":0}`,
+		},
+		{
+			name:    "too many hashes",
+			encoded: `{"bits":"AAAA","numHashes":33,"seed":0}`,
+		},
+	}
+	for _, tc := range testCases {
+		var decoded BloomFilter
+		assert.Error(t, json.Unmarshal([]byte(tc.encoded), &decoded), tc.name)
+	}
+}
@@ -0,0 +1,174 @@
+package ordersync
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/0xProject/0x-mesh/encoding"
+	protocol "github.com/libp2p/go-libp2p-core/protocol"
+)
+
+// maxCompactMessageSize is the maximum size of a single ordersync request or
+// response in the compact encoding (after decompression). It is large enough
+// for a full page of orders or a bloom filter of the maximum size.
+const maxCompactMessageSize = 32 * 1024 * 1024
+
+// codec is used to read and write ordersync requests and responses on a
+// stream. The codec is chosen based on the protocol ID that was negotiated
+// when the stream was opened.
+type codec interface {
+	writeRequest(w io.Writer, rawReq *rawRequest) error
+	readRequest(r io.Reader) (*rawRequest, error)
+	writeResponse(w io.Writer, rawRes *rawResponse) error
+	readResponse(r io.Reader) (*rawResponse, error)
+}
+
+// codecForProtocol returns the codec for the given ordersync protocol ID.
+func codecForProtocol(pid protocol.ID) codec {
+	if pid == CompactID {
+		return compactCodec{}
+	}
+	return jsonCodec{}
+}
+
+// jsonCodec is the codec used for ID. Requests and responses are written as
+// JSON, including all orders.
+type jsonCodec struct{}
+
+func (jsonCodec) writeRequest(w io.Writer, rawReq *rawRequest) error {
+	return json.NewEncoder(w).Encode(rawReq)
+}
+
+func (jsonCodec) readRequest(r io.Reader) (*rawRequest, error) {
+	var rawReq rawRequest
+	if err := json.NewDecoder(r).Decode(&rawReq); err != nil {
+		return nil, err
+	}
+	return &rawReq, nil
+}
+
+func (jsonCodec) writeResponse(w io.Writer, rawRes *rawResponse) error {
+	return json.NewEncoder(w).Encode(rawRes)
+}
+
+func (jsonCodec) readResponse(r io.Reader) (*rawResponse, error) {
+	var rawRes rawResponse
+	if err := json.NewDecoder(r).Decode(&rawRes); err != nil {
+		return nil, err
+	}
+	return &rawRes, nil
+}
+
+// compactCodec is the codec used for CompactID. Every message is written as a
+// length-prefixed frame. Requests contain JSON, since they do not contain any
+// orders. Responses contain a length-prefixed JSON header followed by the
+// orders in the compact encoding.
+type compactCodec struct{}
+
+func (compactCodec) writeRequest(w io.Writer, rawReq *rawRequest) error {
+	encoded, err := json.Marshal(rawReq)
+	if err != nil {
+		return err
+	}
+	return writeFrame(w, encoded)
+}
+
+func (compactCodec) readRequest(r io.Reader) (*rawRequest, error) {
+	frame, err := readFrame(r)
+	if err != nil {
+		return nil, err
+	}
+	var rawReq rawRequest
+	if err := json.Unmarshal(frame, &rawReq); err != nil {
+		return nil, err
+	}
+	return &rawReq, nil
+}
+
+func (compactCodec) writeResponse(w io.Writer, rawRes *rawResponse) error {
+	// The orders are encoded separately, so we leave them out of the header.
+	header := *rawRes
+	header.Orders = nil
+	encodedHeader, err := json.Marshal(header)
+	if err != nil {
+		return err
+	}
+	encodedOrders, err := encoding.OrdersToCompactMessage(rawRes.Orders)
+	if err != nil {
+		return err
+	}
+	var lengthPrefix [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(lengthPrefix[:], uint64(len(encodedHeader)))
+	frame := make([]byte, 0, n+len(encodedHeader)+len(encodedOrders))
+	frame = append(frame, lengthPrefix[:n]...)
+	frame = append(frame, encodedHeader...)
+	frame = append(frame, encodedOrders...)
+	return writeFrame(w, frame)
+}
+
+func (compactCodec) readResponse(r io.Reader) (*rawResponse, error) {
+	frame, err := readFrame(r)
+	if err != nil {
+		return nil, err
+	}
+	headerLength, n := binary.Uvarint(frame)
+	if n <= 0 || headerLength > uint64(len(frame)-n) {
+		return nil, errors.New("invalid ordersync response header")
+	}
+	var rawRes rawResponse
+	if err := json.Unmarshal(frame[n:n+int(headerLength)], &rawRes); err != nil {
+		return nil, err
+	}
+	rawRes.Orders, err = encoding.CompactMessageToOrders(frame[n+int(headerLength):], maxCompactMessageSize)
+	if err != nil {
+		return nil, err
+	}
+	return &rawRes, nil
+}
+
+// writeFrame writes the given data to w, prefixed by its length.
+func writeFrame(w io.Writer, data []byte) error {
+	if len(data) > maxCompactMessageSize {
+		return fmt.Errorf("ordersync message exceeds maximum size of %d bytes", maxCompactMessageSize)
+	}
+	var lengthPrefix [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(lengthPrefix[:], uint64(len(data)))
+	if _, err := w.Write(append(lengthPrefix[:n], data...)); err != nil {
+		return err
+	}
+	return nil
+}
+
+// readFrame reads a single frame that was written by writeFrame. It never reads
+// past the end of the frame.
+func readFrame(r io.Reader) ([]byte, error) {
+	length, err := binary.ReadUvarint(byteReader{r})
+	if err != nil {
+		return nil, err
+	}
+	if length > maxCompactMessageSize {
+		return nil, fmt.Errorf("ordersync message exceeds maximum size of %d bytes", maxCompactMessageSize)
+	}
+	data := make([]byte, length)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// byteReader implements io.ByteReader without buffering, so that no bytes
+// beyond the length prefix are consumed from the underlying reader.
+type byteReader struct {
+	io.Reader
+}
+
+func (r byteReader) ReadByte() (byte, error) {
+	var b [1]byte
+	if _, err := io.ReadFull(r.Reader, b[:]); err != nil {
+		return 0, err
+	}
+	return b[0], nil
+}
@@ -0,0 +1,115 @@
+package ordersync
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"testing"
+
+	"github.com/0xProject/0x-mesh/scenario"
+	"github.com/0xProject/0x-mesh/zeroex"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCodecForProtocol(t *testing.T) {
+	assert.Equal(t, compactCodec{}, codecForProtocol(CompactID))
+	assert.Equal(t, jsonCodec{}, codecForProtocol(ID))
+}
+
+func TestCompactCodecRequest(t *testing.T) {
+	rawReq := &rawRequest{
+		Type:         TypeRequest,
+		Subprotocols: []string{"/first", "/second"},
+		Metadata:     json.RawMessage(`{"page":1}`),
+	}
+	buf := &bytes.Buffer{}
+	require.NoError(t, compactCodec{}.writeRequest(buf, rawReq))
+	// Trailing data must not be consumed when reading the request.
+	buf.WriteString("trailing")
+	actual, err := compactCodec{}.readRequest(buf)
+	require.NoError(t, err)
+	assert.Equal(t, rawReq, actual)
+	assert.Equal(t, "trailing", buf.String())
+}
+
+func TestCompactCodecResponse(t *testing.T) {
+	orders := []*zeroex.SignedOrder{
+		scenario.NewSignedTestOrder(t),
+		scenario.NewSignedTestOrder(t),
+	}
+	rawRes := &rawResponse{
+		Type:        TypeResponse,
+		Subprotocol: "/first",
+		Orders:      orders,
+		Complete:    true,
+		Metadata:    json.RawMessage(`{"page":2}`),
+	}
+	buf := &bytes.Buffer{}
+	require.NoError(t, compactCodec{}.writeResponse(buf, rawRes))
+	actual, err := compactCodec{}.readResponse(buf)
+	require.NoError(t, err)
+	require.Len(t, actual.Orders, len(orders))
+	for i, order := range orders {
+		expectedHash, err := order.ComputeOrderHash()
+		require.NoError(t, err)
+		actualHash, err := actual.Orders[i].ComputeOrderHash()
+		require.NoError(t, err)
+		assert.Equal(t, expectedHash, actualHash)
+	}
+	assert.Equal(t, rawRes.Type, actual.Type)
+	assert.Equal(t, rawRes.Subprotocol, actual.Subprotocol)
+	assert.Equal(t, rawRes.Complete, actual.Complete)
+	assert.JSONEq(t, string(rawRes.Metadata), string(actual.Metadata))
+	assert.Equal(t, 0, buf.Len())
+}
+
+func TestCompactCodecResponseInvalidHeader(t *testing.T) {
+	// The header length exceeds the length of the frame.
+	var lengthPrefix [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(lengthPrefix[:], 100)
+	buf := &bytes.Buffer{}
+	require.NoError(t, writeFrame(buf, append(lengthPrefix[:n], []byte("{}")...)))
+	_, err := compactCodec{}.readResponse(buf)
+	assert.Error(t, err)
+}
+
+func TestReadWriteFrame(t *testing.T) {
+	buf := &bytes.Buffer{}
+	require.NoError(t, writeFrame(buf, []byte("first")))
+	require.NoError(t, writeFrame(buf, []byte{}))
+	require.NoError(t, writeFrame(buf, []byte("second")))
+
+	frame, err := readFrame(buf)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("first"), frame)
+	frame, err = readFrame(buf)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{}, frame)
+	frame, err = readFrame(buf)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("second"), frame)
+	_, err = readFrame(buf)
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestReadFrameTruncated(t *testing.T) {
+	buf := &bytes.Buffer{}
+	require.NoError(t, writeFrame(buf, []byte("truncated")))
+	truncated := bytes.NewReader(buf.Bytes()[:buf.Len()-1])
+	_, err := readFrame(truncated)
+	assert.Equal(t, io.ErrUnexpectedEOF, err)
+}
+
+func TestFrameSizeLimit(t *testing.T) {
+	err := writeFrame(&bytes.Buffer{}, make([]byte, maxCompactMessageSize+1))
+	assert.Error(t, err)
+
+	// A length prefix which exceeds the limit is rejected before any data is
+	// allocated or read.
+	var lengthPrefix [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(lengthPrefix[:], maxCompactMessageSize+1)
+	_, err = readFrame(bytes.NewReader(lengthPrefix[:n]))
+	assert.Error(t, err)
+}
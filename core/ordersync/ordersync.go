@@ -69,6 +69,10 @@ func (e NoMatchingSubprotocolsError) Error() string {
 const (
 	// ID is the ID for the ordersync protocol.
 	ID = protocol.ID("/injective-0x-mesh/order-sync/version/0")
+	// CompactID is the ID for the version of the ordersync protocol which sends
+	// orders in the compact binary encoding. It is preferred over ID if both
+	// peers support it.
+	CompactID = protocol.ID("/injective-0x-mesh/order-sync/version/1")
 )
 
 // Request represents a high-level ordersync request. It abstracts away some
@@ -146,6 +150,19 @@ type Subprotocol interface {
 	GenerateFirstRequestMetadata() (json.RawMessage, error)
 }
 
+// PeerAwareSubprotocol is an optional interface which may be implemented by a
+// Subprotocol whose first request depends on the peer it is sent to (e.g.
+// because the requester keeps track of previous runs of ordersync with each
+// peer). If a Subprotocol implements PeerAwareSubprotocol,
+// GenerateFirstRequestMetadataForPeer is used instead of
+// GenerateFirstRequestMetadata.
+type PeerAwareSubprotocol interface {
+	Subprotocol
+	// GenerateFirstRequestMetadataForPeer generates the metadata for the first
+	// request that should be made with this subprotocol to the given provider.
+	GenerateFirstRequestMetadataForPeer(providerID peer.ID) (json.RawMessage, error)
+}
+
 // New creates and returns a new ordersync service, which is used for both
 // requesting orders from other peers and providing orders to peers who request
 // them. New expects an array of subprotocols which the service will support, in the
@@ -167,6 +184,7 @@ func New(ctx context.Context, node *p2p.Node, subprotocols []Subprotocol) *Servi
 		preferredSubprotocols: sids,
 		requestRateLimiter:    rate.NewLimiter(maxRequestsPerSecond, requestsBurst),
 	}
+	s.node.SetStreamHandler(CompactID, s.HandleStream)
 	s.node.SetStreamHandler(ID, s.HandleStream)
 	return s
 }
@@ -205,6 +223,7 @@ func (s *Service) HandleStream(stream network.Stream) {
 		_ = stream.Close()
 	}()
 	requesterID := stream.Conn().RemotePeer()
+	streamCodec := codecForProtocol(stream.Protocol())
 
 	for {
 		if err := s.requestRateLimiter.Wait(s.ctx); err != nil {
@@ -213,7 +232,7 @@ func (s *Service) HandleStream(stream network.Stream) {
 			}).Warn("ordersync rate limiter returned error")
 			return
 		}
-		rawReq, err := waitForRequest(s.ctx, stream)
+		rawReq, err := waitForRequest(s.ctx, stream, streamCodec)
 		if err != nil {
 			log.WithError(err).Warn("waitForRequest returned error")
 			return
@@ -225,7 +244,7 @@ func (s *Service) HandleStream(stream network.Stream) {
 		if rawRes == nil {
 			return
 		}
-		if err := json.NewEncoder(stream).Encode(rawRes); err != nil {
+		if err := streamCodec.writeResponse(stream, rawRes); err != nil {
 			log.WithFields(log.Fields{
 				"error":     err.Error(),
 				"requester": requesterID.Pretty(),
@@ -436,11 +455,17 @@ type FirstRequestsForSubprotocols struct {
 
 // createFirstRequestForAllSubprotocols creates an initial ordersync request that
 // contains metadata for all of the ordersync subprotocols.
-func (s *Service) createFirstRequestForAllSubprotocols() (*rawRequest, error) {
+func (s *Service) createFirstRequestForAllSubprotocols(providerID peer.ID) (*rawRequest, error) {
 	metadata := []json.RawMessage{}
 	for _, sid := range s.preferredSubprotocols {
 		subp, _ := s.subprotocolSet[sid]
-		m, err := subp.GenerateFirstRequestMetadata()
+		var m json.RawMessage
+		var err error
+		if peerAwareSubp, ok := subp.(PeerAwareSubprotocol); ok {
+			m, err = peerAwareSubp.GenerateFirstRequestMetadataForPeer(providerID)
+		} else {
+			m, err = subp.GenerateFirstRequestMetadata()
+		}
 		if err != nil {
 			return nil, err
 		}
@@ -460,7 +485,7 @@ func (s *Service) createFirstRequestForAllSubprotocols() (*rawRequest, error) {
 }
 
 func (s *Service) getOrdersFromPeer(ctx context.Context, providerID peer.ID) error {
-	stream, err := s.node.NewStream(ctx, providerID, ID)
+	stream, err := s.node.NewStream(ctx, providerID, CompactID, ID)
 	if err != nil {
 		s.handlePeerScoreEvent(providerID, psUnexpectedDisconnect)
 		return err
@@ -468,6 +493,7 @@ func (s *Service) getOrdersFromPeer(ctx context.Context, providerID peer.ID) err
 	defer func() {
 		_ = stream.Close()
 	}()
+	streamCodec := codecForProtocol(stream.Protocol())
 
 	var nextReq *Request
 	var selectedSubprotocol Subprotocol
@@ -481,7 +507,7 @@ func (s *Service) getOrdersFromPeer(ctx context.Context, providerID peer.ID) err
 		var rawReq *rawRequest
 		if nextReq == nil {
 			// First request
-			rawReq, err = s.createFirstRequestForAllSubprotocols()
+			rawReq, err = s.createFirstRequestForAllSubprotocols(providerID)
 			if err != nil {
 				return err
 			}
@@ -497,12 +523,12 @@ func (s *Service) getOrdersFromPeer(ctx context.Context, providerID peer.ID) err
 			}
 		}
 
-		if err := json.NewEncoder(stream).Encode(rawReq); err != nil {
+		if err := streamCodec.writeRequest(stream, rawReq); err != nil {
 			s.handlePeerScoreEvent(providerID, psUnexpectedDisconnect)
 			return err
 		}
 
-		rawRes, err := waitForResponse(ctx, stream)
+		rawRes, err := waitForResponse(ctx, stream, streamCodec)
 		if err != nil {
 			return err
 		}
@@ -538,14 +564,14 @@ func shufflePeers(peers []peer.ID) {
 	rand.Shuffle(len(peers), func(i, j int) { peers[i], peers[j] = peers[j], peers[i] })
 }
 
-func waitForRequest(parentCtx context.Context, stream network.Stream) (*rawRequest, error) {
+func waitForRequest(parentCtx context.Context, stream network.Stream, streamCodec codec) (*rawRequest, error) {
 	ctx, cancel := context.WithTimeout(parentCtx, requestResponseTimeout)
 	defer cancel()
 	reqChan := make(chan *rawRequest, 1)
 	errChan := make(chan error, 1)
 	go func() {
-		var rawReq rawRequest
-		if err := json.NewDecoder(stream).Decode(&rawReq); err != nil {
+		rawReq, err := streamCodec.readRequest(stream)
+		if err != nil {
 			log.WithFields(log.Fields{
 				"error":     err.Error(),
 				"requester": stream.Conn().RemotePeer().Pretty(),
@@ -553,7 +579,7 @@ func waitForRequest(parentCtx context.Context, stream network.Stream) (*rawReque
 			errChan <- err
 			return
 		}
-		reqChan <- &rawReq
+		reqChan <- rawReq
 	}()
 
 	select {
@@ -570,14 +596,14 @@ func waitForRequest(parentCtx context.Context, stream network.Stream) (*rawReque
 	}
 }
 
-func waitForResponse(parentCtx context.Context, stream network.Stream) (*rawResponse, error) {
+func waitForResponse(parentCtx context.Context, stream network.Stream, streamCodec codec) (*rawResponse, error) {
 	ctx, cancel := context.WithTimeout(parentCtx, requestResponseTimeout)
 	defer cancel()
 	resChan := make(chan *rawResponse, 1)
 	errChan := make(chan error, 1)
 	go func() {
-		var rawRes rawResponse
-		if err := json.NewDecoder(stream).Decode(&rawRes); err != nil {
+		rawRes, err := streamCodec.readResponse(stream)
+		if err != nil {
 			log.WithFields(log.Fields{
 				"error":    err.Error(),
 				"provider": stream.Conn().RemotePeer().Pretty(),
@@ -585,7 +611,7 @@ func waitForResponse(parentCtx context.Context, stream network.Stream) (*rawResp
 			errChan <- err
 			return
 		}
-		resChan <- &rawRes
+		resChan <- rawRes
 	}()
 
 	select {
@@ -71,7 +71,7 @@ func TestHandleRawRequest(t *testing.T) {
 
 	// Test handling a request from a node that is using the new first request
 	// encoding scheme.
-	rawReq, err = s.createFirstRequestForAllSubprotocols()
+	rawReq, err = s.createFirstRequestForAllSubprotocols(n.ID())
 	res = s.handleRawRequest(rawReq, n.ID())
 	require.NotNil(t, res)
 	assert.True(t, res.Complete)
@@ -80,6 +80,42 @@ func TestHandleRawRequest(t *testing.T) {
 	assert.Equal(t, res.Metadata, rawReq.Metadata)
 }
 
+func TestCreateFirstRequestWithPeerAwareSubprotocol(t *testing.T) {
+	n, err := p2p.New(
+		context.Background(),
+		p2p.Config{
+			MessageHandler:   &noopMessageHandler{},
+			RendezvousPoints: []string{"/test-rendezvous-point"},
+			DataDir:          "/tmp",
+		},
+	)
+	require.NoError(t, err)
+	subp0 := &peerAwareSubprotocol{
+		oneOrderSubprotocol: oneOrderSubprotocol{
+			myPeerID: n.ID(),
+		},
+	}
+	subp1 := &oneOrderSubprotocol{
+		myPeerID: n.ID(),
+	}
+	s := New(context.Background(), n, []Subprotocol{subp0, subp1})
+
+	providerID := peer.ID("provider")
+	rawReq, err := s.createFirstRequestForAllSubprotocols(providerID)
+	require.NoError(t, err)
+	var firstRequests FirstRequestsForSubprotocols
+	require.NoError(t, json.Unmarshal(rawReq.Metadata, &firstRequests))
+	require.Len(t, firstRequests.MetadataForSubprotocol, 2)
+
+	// The first request for the peer aware subprotocol should include the
+	// provider ID.
+	var metadata oneOrderSubprotocolRequestMetadata
+	require.NoError(t, json.Unmarshal(firstRequests.MetadataForSubprotocol[0], &metadata))
+	assert.Equal(t, providerID.Pretty(), metadata.SomeValue)
+	require.NoError(t, json.Unmarshal(firstRequests.MetadataForSubprotocol[1], &metadata))
+	assert.Equal(t, float64(0), metadata.SomeValue)
+}
+
 var _ p2p.MessageHandler = &noopMessageHandler{}
 
 // noopMessageHandler is a dummy message handler that allows a p2p node to be
@@ -228,3 +264,22 @@ func (s *hostedSubprotocol) GenerateFirstRequestMetadata() (json.RawMessage, err
 		AnotherValue: 1,
 	})
 }
+
+var _ PeerAwareSubprotocol = &peerAwareSubprotocol{}
+
+// peerAwareSubprotocol is an ordersync subprotocol that is used for testing
+// ordersync. It behaves like oneOrderSubprotocol, but includes the provider ID
+// in the metadata of the first request.
+type peerAwareSubprotocol struct {
+	oneOrderSubprotocol
+}
+
+func (s *peerAwareSubprotocol) Name() string {
+	return "/peer-aware-order-sync-subprotocol/v0"
+}
+
+func (s *peerAwareSubprotocol) GenerateFirstRequestMetadataForPeer(providerID peer.ID) (json.RawMessage, error) {
+	return json.Marshal(oneOrderSubprotocolRequestMetadata{
+		SomeValue: providerID.Pretty(),
+	})
+}
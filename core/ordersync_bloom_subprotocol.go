@@ -0,0 +1,249 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/0xProject/0x-mesh/core/ordersync"
+	"github.com/0xProject/0x-mesh/orderfilter"
+	"github.com/0xProject/0x-mesh/zeroex"
+)
+
+// bloomFilterFalsePositiveRate is the false positive rate of the bloom filters
+// sent by the BloomFilterSubProtocol. Any order which is a false positive will
+// not be received during a single run of ordersync. Since every bloom filter
+// uses a different random seed, such an order is very likely to be received
+// during the next run.
+const bloomFilterFalsePositiveRate = 0.01
+
+// bloomFilterMaxAge is the amount of time for which a bloom filter is re-used
+// for the first requests to different peers. A run of ordersync with several
+// peers usually takes much less time, so the bloom filter is generated about
+// once per run, and runs are far enough apart that each of them uses a new one.
+const bloomFilterMaxAge = 5 * time.Minute
+
+// Ensure that BloomFilterSubProtocol implements the Subprotocol interface.
+var _ ordersync.Subprotocol = (*BloomFilterSubProtocol)(nil)
+
+// BloomFilterSubProtocol is an ordersync subprotocol based on set
+// reconciliation. The requester sends a bloom filter summarizing the hashes of
+// all orders it already has, and the provider only responds with orders which
+// are not in the bloom filter. Like FilteredPaginationSubProtocol, the
+// provider paginates through its orders, but it skips pages which contain no
+// missing orders. When both peers already hold most of the same orders, this
+// requires far fewer round trips and far less bandwidth.
+//
+// The bloom filter is only sent with the first request. The provider stores it
+// alongside the snapshot it creates for the requester and uses it for all
+// subsequent requests which reference that snapshot.
+type BloomFilterSubProtocol struct {
+	app         *App
+	orderFilter *orderfilter.Filter
+	perPage     int
+	// bloomFilterMu protects bloomFilter and bloomFilterCreatedAt. bloomFilter
+	// is sent with the first request to every peer until it is older than
+	// bloomFilterMaxAge.
+	bloomFilterMu        sync.Mutex
+	bloomFilter          *ordersync.BloomFilter
+	bloomFilterCreatedAt time.Time
+}
+
+// NewBloomFilterSubprotocol creates and returns a new BloomFilterSubProtocol
+// which will respond with at least perPage missing orders for each individual
+// request/response (unless there are fewer than perPage missing orders left).
+func NewBloomFilterSubprotocol(app *App, perPage int) *BloomFilterSubProtocol {
+	return &BloomFilterSubProtocol{
+		app:         app,
+		orderFilter: app.orderFilter,
+		perPage:     perPage,
+	}
+}
+
+// BloomFilterRequestMetadata is the request metadata for the
+// BloomFilterSubProtocol. In addition to the pagination state, the first
+// request contains a bloom filter of the order hashes the requester already
+// has.
+type BloomFilterRequestMetadata struct {
+	Page        int                    `json:"page"`
+	SnapshotID  string                 `json:"snapshotID"`
+	OrderFilter *orderfilter.Filter    `json:"orderfilter"`
+	BloomFilter *ordersync.BloomFilter `json:"bloomFilter"`
+}
+
+// BloomFilterResponseMetadata is the response metadata for the
+// BloomFilterSubProtocol. It keeps track of the last page that was served and
+// the SnapshotID.
+type BloomFilterResponseMetadata struct {
+	Page       int    `json:"page"`
+	SnapshotID string `json:"snapshotID"`
+}
+
+// Name returns the name of the BloomFilterSubProtocol
+func (p *BloomFilterSubProtocol) Name() string {
+	return "/bloom-filter-with-filter/version/0"
+}
+
+// HandleOrderSyncRequest returns the orders which are not in the requester's
+// bloom filter, starting at the page corresponding to the given request. This
+// is the implementation for the "provider" side of the subprotocol.
+func (p *BloomFilterSubProtocol) HandleOrderSyncRequest(ctx context.Context, req *ordersync.Request) (*ordersync.Response, error) {
+	var metadata *BloomFilterRequestMetadata
+	if req.Metadata == nil {
+		// Default metadata for the first request.
+		metadata = &BloomFilterRequestMetadata{
+			Page:       0,
+			SnapshotID: "",
+		}
+	} else {
+		var ok bool
+		metadata, ok = req.Metadata.(*BloomFilterRequestMetadata)
+		if !ok {
+			return nil, fmt.Errorf("BloomFilterSubProtocol received request with wrong metadata type (got %T)", req.Metadata)
+		}
+	}
+	bloomFilter := metadata.BloomFilter
+	if bloomFilter == nil && metadata.SnapshotID != "" {
+		bloomFilter = p.app.getSnapshotBloomFilter(metadata.SnapshotID)
+	}
+
+	// Keep iterating through pages until we have found at least perPage orders
+	// that the requester is missing or there are no orders left.
+	missingOrders := []*zeroex.SignedOrder{}
+	snapshotID := metadata.SnapshotID
+	currentPage := metadata.Page
+	complete := false
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+		ordersResp, err := p.app.GetOrders(currentPage, p.perPage, snapshotID)
+		if err != nil {
+			return nil, err
+		}
+		snapshotID = ordersResp.SnapshotID
+		if len(ordersResp.OrdersInfos) == 0 {
+			// No more orders left.
+			complete = true
+			break
+		}
+		for _, orderInfo := range ordersResp.OrdersInfos {
+			if bloomFilter != nil && bloomFilter.Contains(orderInfo.OrderHash) {
+				continue
+			}
+			if metadata.OrderFilter != nil {
+				if matches, err := metadata.OrderFilter.MatchOrder(orderInfo.SignedOrder); err != nil {
+					return nil, err
+				} else if !matches {
+					continue
+				}
+			}
+			missingOrders = append(missingOrders, orderInfo.SignedOrder)
+		}
+		if len(missingOrders) >= p.perPage {
+			break
+		}
+		currentPage += 1
+	}
+	if metadata.BloomFilter != nil {
+		p.app.setSnapshotBloomFilter(snapshotID, metadata.BloomFilter)
+	}
+
+	return &ordersync.Response{
+		Orders:   missingOrders,
+		Complete: complete,
+		Metadata: &BloomFilterResponseMetadata{
+			Page:       currentPage,
+			SnapshotID: snapshotID,
+		},
+	}, nil
+}
+
+// HandleOrderSyncResponse handles the missing orders by validating them,
+// storing them in the database, and firing the appropriate events. It also
+// returns the next request to be sent. This is the implementation for the
+// "requester" side of the subprotocol.
+func (p *BloomFilterSubProtocol) HandleOrderSyncResponse(ctx context.Context, res *ordersync.Response) (*ordersync.Request, error) {
+	if res.Metadata == nil {
+		return nil, errors.New("BloomFilterSubProtocol received response with nil metadata")
+	}
+	metadata, ok := res.Metadata.(*BloomFilterResponseMetadata)
+	if !ok {
+		return nil, fmt.Errorf("BloomFilterSubProtocol received response with wrong metadata type (got %T)", res.Metadata)
+	}
+	if _, err := handleOrdersFromOrdersync(ctx, p.app, p.orderFilter, res); err != nil {
+		return nil, err
+	}
+
+	// The provider keeps the bloom filter from the first request alongside the
+	// snapshot, so it doesn't need to be sent again.
+	return &ordersync.Request{
+		Metadata: &BloomFilterRequestMetadata{
+			OrderFilter: p.orderFilter,
+			Page:        metadata.Page + 1,
+			SnapshotID:  metadata.SnapshotID,
+		},
+	}, nil
+}
+
+func (p *BloomFilterSubProtocol) ParseRequestMetadata(metadata json.RawMessage) (interface{}, error) {
+	var parsed BloomFilterRequestMetadata
+	if err := json.Unmarshal(metadata, &parsed); err != nil {
+		return nil, err
+	}
+	return &parsed, nil
+}
+
+func (p *BloomFilterSubProtocol) ParseResponseMetadata(metadata json.RawMessage) (interface{}, error) {
+	var parsed BloomFilterResponseMetadata
+	if err := json.Unmarshal(metadata, &parsed); err != nil {
+		return nil, err
+	}
+	return &parsed, nil
+}
+
+// GenerateFirstRequestMetadata generates the metadata for the first request,
+// which contains a bloom filter of the hashes of all orders in the database.
+// Orders which have been flagged for removal are included as well, since they
+// would be rejected anyway. The bloom filter is re-used until it is older than
+// bloomFilterMaxAge.
+func (p *BloomFilterSubProtocol) GenerateFirstRequestMetadata() (json.RawMessage, error) {
+	bloomFilter, err := p.getOrGenerateBloomFilter()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(BloomFilterRequestMetadata{
+		OrderFilter: p.orderFilter,
+		Page:        0,
+		SnapshotID:  "",
+		BloomFilter: bloomFilter,
+	})
+}
+
+func (p *BloomFilterSubProtocol) getOrGenerateBloomFilter() (*ordersync.BloomFilter, error) {
+	p.bloomFilterMu.Lock()
+	defer p.bloomFilterMu.Unlock()
+	if p.bloomFilter != nil && time.Since(p.bloomFilterCreatedAt) < bloomFilterMaxAge {
+		return p.bloomFilter, nil
+	}
+	orderHashes, err := p.app.db.FindOrderHashes()
+	if err != nil {
+		return nil, err
+	}
+	bloomFilter, err := ordersync.NewBloomFilter(len(orderHashes), bloomFilterFalsePositiveRate, rand.Uint64())
+	if err != nil {
+		return nil, err
+	}
+	for _, orderHash := range orderHashes {
+		bloomFilter.Add(orderHash)
+	}
+	p.bloomFilter = bloomFilter
+	p.bloomFilterCreatedAt = time.Now()
+	return bloomFilter, nil
+}
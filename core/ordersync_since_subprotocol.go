@@ -0,0 +1,283 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/0xProject/0x-mesh/core/ordersync"
+	"github.com/0xProject/0x-mesh/db"
+	"github.com/0xProject/0x-mesh/meshdb"
+	"github.com/0xProject/0x-mesh/orderfilter"
+	"github.com/0xProject/0x-mesh/zeroex"
+	peer "github.com/libp2p/go-libp2p-core/peer"
+	log "github.com/sirupsen/logrus"
+)
+
+// ordersyncCheckpointBuffer is subtracted from the checkpoint for a peer when
+// requesting the orders that were updated since the last sync. The LastUpdated
+// time of an order is set before the order is committed to the provider's
+// database, so without a buffer an order which was being stored while the
+// provider took its snapshot could be missed.
+const ordersyncCheckpointBuffer = 5 * time.Minute
+
+// Ensure that OrdersSinceSubProtocol implements the PeerAwareSubprotocol
+// interface.
+var _ ordersync.PeerAwareSubprotocol = (*OrdersSinceSubProtocol)(nil)
+
+// OrdersSinceSubProtocol is an ordersync subprotocol for incremental syncing.
+// The requester keeps a checkpoint for each peer it has successfully synced
+// with and sends the time of its last sync with the provider. The provider
+// only responds with orders which were added or updated since then. If there
+// is no checkpoint for the provider, all orders are requested. Like
+// FilteredPaginationSubProtocol, the provider paginates through the orders.
+//
+// If any order received during a sync was rejected for a reason that is local
+// to our node (see isLocalRejection), the checkpoint is not advanced so that
+// the order is requested again during the next sync.
+type OrdersSinceSubProtocol struct {
+	app         *App
+	orderFilter *orderfilter.Filter
+	perPage     int
+	// localRejectionsMu protects localRejections, which contains the IDs of
+	// the providers for which we rejected at least one order for a local
+	// reason during the current sync.
+	localRejectionsMu sync.Mutex
+	localRejections   map[peer.ID]struct{}
+}
+
+// NewOrdersSinceSubprotocol creates and returns a new OrdersSinceSubProtocol
+// which will respond with at most perPage orders for each individual
+// request/response.
+func NewOrdersSinceSubprotocol(app *App, perPage int) *OrdersSinceSubProtocol {
+	return &OrdersSinceSubProtocol{
+		app:             app,
+		orderFilter:     app.orderFilter,
+		perPage:         perPage,
+		localRejections: map[peer.ID]struct{}{},
+	}
+}
+
+// OrdersSinceRequestMetadata is the request metadata for the
+// OrdersSinceSubProtocol. Since is the time after which the requester wants
+// to receive orders. Page and SnapshotID keep track of the pagination state.
+type OrdersSinceRequestMetadata struct {
+	Since       time.Time           `json:"since"`
+	Page        int                 `json:"page"`
+	SnapshotID  string              `json:"snapshotID"`
+	OrderFilter *orderfilter.Filter `json:"orderfilter"`
+}
+
+// OrdersSinceResponseMetadata is the response metadata for the
+// OrdersSinceSubProtocol. In addition to the pagination state, it contains the
+// time at which the provider's snapshot was created, which the requester
+// stores as its checkpoint once ordersync is complete.
+type OrdersSinceResponseMetadata struct {
+	Since             time.Time `json:"since"`
+	Page              int       `json:"page"`
+	SnapshotID        string    `json:"snapshotID"`
+	SnapshotTimestamp time.Time `json:"snapshotTimestamp"`
+}
+
+// Name returns the name of the OrdersSinceSubProtocol
+func (p *OrdersSinceSubProtocol) Name() string {
+	return "/orders-since-with-filter/version/0"
+}
+
+// HandleOrderSyncRequest returns the orders which were added or updated since
+// the time given in the request, starting at the page corresponding to the
+// request. This is the implementation for the "provider" side of the
+// subprotocol.
+func (p *OrdersSinceSubProtocol) HandleOrderSyncRequest(ctx context.Context, req *ordersync.Request) (*ordersync.Response, error) {
+	var metadata *OrdersSinceRequestMetadata
+	if req.Metadata == nil {
+		// Default metadata for the first request.
+		metadata = &OrdersSinceRequestMetadata{
+			Page:       0,
+			SnapshotID: "",
+		}
+	} else {
+		var ok bool
+		metadata, ok = req.Metadata.(*OrdersSinceRequestMetadata)
+		if !ok {
+			return nil, fmt.Errorf("OrdersSinceSubProtocol received request with wrong metadata type (got %T)", req.Metadata)
+		}
+	}
+
+	// It's possible that none of the orders in the current page match the
+	// filter or that all of them have been removed. We don't want to respond
+	// with zero orders unless there are none left, so keep iterating until we
+	// find at least some orders.
+	filteredOrders := []*zeroex.SignedOrder{}
+	snapshotID := metadata.SnapshotID
+	var snapshotTimestamp time.Time
+	currentPage := metadata.Page
+	complete := false
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+		ordersResp, noPagesLeft, err := p.app.getOrdersUpdatedSince(metadata.Since, currentPage, p.perPage, snapshotID)
+		if err != nil {
+			return nil, err
+		}
+		snapshotID = ordersResp.SnapshotID
+		snapshotTimestamp = ordersResp.SnapshotTimestamp
+		if noPagesLeft {
+			complete = true
+			break
+		}
+		for _, orderInfo := range ordersResp.OrdersInfos {
+			if metadata.OrderFilter != nil {
+				if matches, err := metadata.OrderFilter.MatchOrder(orderInfo.SignedOrder); err != nil {
+					return nil, err
+				} else if !matches {
+					continue
+				}
+			}
+			filteredOrders = append(filteredOrders, orderInfo.SignedOrder)
+		}
+		if len(filteredOrders) > 0 {
+			break
+		}
+		currentPage += 1
+	}
+
+	return &ordersync.Response{
+		Orders:   filteredOrders,
+		Complete: complete,
+		Metadata: &OrdersSinceResponseMetadata{
+			Since:             metadata.Since,
+			Page:              currentPage,
+			SnapshotID:        snapshotID,
+			SnapshotTimestamp: snapshotTimestamp,
+		},
+	}, nil
+}
+
+// HandleOrderSyncResponse handles the orders for one page by validating them,
+// storing them in the database, and firing the appropriate events. Once all
+// orders have been received, it stores a new checkpoint for the provider
+// unless some orders were rejected for local reasons. It also returns the next
+// request to be sent. This is the implementation for the "requester" side of
+// the subprotocol.
+func (p *OrdersSinceSubProtocol) HandleOrderSyncResponse(ctx context.Context, res *ordersync.Response) (*ordersync.Request, error) {
+	if res.Metadata == nil {
+		return nil, errors.New("OrdersSinceSubProtocol received response with nil metadata")
+	}
+	metadata, ok := res.Metadata.(*OrdersSinceResponseMetadata)
+	if !ok {
+		return nil, fmt.Errorf("OrdersSinceSubProtocol received response with wrong metadata type (got %T)", res.Metadata)
+	}
+	validationResults, err := handleOrdersFromOrdersync(ctx, p.app, p.orderFilter, res)
+	if err != nil {
+		return nil, err
+	}
+	for _, rejectedOrderInfo := range validationResults.Rejected {
+		if isLocalRejection(rejectedOrderInfo.Status) {
+			p.recordLocalRejection(res.ProviderID)
+			break
+		}
+	}
+
+	if res.Complete && p.popLocalRejection(res.ProviderID) {
+		log.WithFields(log.Fields{
+			"provider":   res.ProviderID.Pretty(),
+			"snapshotID": metadata.SnapshotID,
+		}).Debug("not advancing ordersync checkpoint because some orders were rejected for local reasons")
+	} else if res.Complete && !metadata.SnapshotTimestamp.IsZero() {
+		checkpoint := &meshdb.OrdersyncCheckpoint{
+			PeerID:           res.ProviderID.Pretty(),
+			OrderFilterTopic: p.orderFilter.Topic(),
+			LastSynced:       metadata.SnapshotTimestamp.UTC(),
+		}
+		if err := p.app.db.SaveOrdersyncCheckpoint(checkpoint); err != nil {
+			// Failing to save the checkpoint only means that more orders will be
+			// requested from this peer next time.
+			log.WithError(err).WithField("provider", res.ProviderID.Pretty()).Error("could not save ordersync checkpoint")
+		}
+	}
+
+	return &ordersync.Request{
+		Metadata: &OrdersSinceRequestMetadata{
+			Since:       metadata.Since,
+			OrderFilter: p.orderFilter,
+			Page:        metadata.Page + 1,
+			SnapshotID:  metadata.SnapshotID,
+		},
+	}, nil
+}
+
+func (p *OrdersSinceSubProtocol) recordLocalRejection(providerID peer.ID) {
+	p.localRejectionsMu.Lock()
+	defer p.localRejectionsMu.Unlock()
+	p.localRejections[providerID] = struct{}{}
+}
+
+// popLocalRejection returns true if an order received from the given provider
+// during the current sync was rejected for a local reason and forgets about
+// the rejection.
+func (p *OrdersSinceSubProtocol) popLocalRejection(providerID peer.ID) bool {
+	p.localRejectionsMu.Lock()
+	defer p.localRejectionsMu.Unlock()
+	_, found := p.localRejections[providerID]
+	delete(p.localRejections, providerID)
+	return found
+}
+
+func (p *OrdersSinceSubProtocol) ParseRequestMetadata(metadata json.RawMessage) (interface{}, error) {
+	var parsed OrdersSinceRequestMetadata
+	if err := json.Unmarshal(metadata, &parsed); err != nil {
+		return nil, err
+	}
+	return &parsed, nil
+}
+
+func (p *OrdersSinceSubProtocol) ParseResponseMetadata(metadata json.RawMessage) (interface{}, error) {
+	var parsed OrdersSinceResponseMetadata
+	if err := json.Unmarshal(metadata, &parsed); err != nil {
+		return nil, err
+	}
+	return &parsed, nil
+}
+
+// GenerateFirstRequestMetadata requests all orders, since the provider is not
+// known.
+func (p *OrdersSinceSubProtocol) GenerateFirstRequestMetadata() (json.RawMessage, error) {
+	return json.Marshal(OrdersSinceRequestMetadata{
+		OrderFilter: p.orderFilter,
+		Page:        0,
+		SnapshotID:  "",
+	})
+}
+
+// GenerateFirstRequestMetadataForPeer requests the orders which were added or
+// updated since the last successful sync with the given provider. If there is
+// no checkpoint for the provider, or the checkpoint was created with a
+// different order filter, all orders are requested.
+func (p *OrdersSinceSubProtocol) GenerateFirstRequestMetadataForPeer(providerID peer.ID) (json.RawMessage, error) {
+	// A new sync with the provider is starting, so forget about any rejections
+	// from a previous sync which did not complete.
+	p.popLocalRejection(providerID)
+
+	var since time.Time
+	checkpoint, err := p.app.db.GetOrdersyncCheckpoint(providerID.Pretty())
+	if err != nil {
+		if _, ok := err.(db.NotFoundError); !ok {
+			return nil, err
+		}
+	} else if checkpoint.OrderFilterTopic == p.orderFilter.Topic() {
+		since = checkpoint.LastSynced.Add(-ordersyncCheckpointBuffer)
+	}
+	return json.Marshal(OrdersSinceRequestMetadata{
+		Since:       since,
+		OrderFilter: p.orderFilter,
+		Page:        0,
+		SnapshotID:  "",
+	})
+}
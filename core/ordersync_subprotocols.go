@@ -9,6 +9,8 @@ import (
 	"github.com/0xProject/0x-mesh/core/ordersync"
 	"github.com/0xProject/0x-mesh/orderfilter"
 	"github.com/0xProject/0x-mesh/zeroex"
+	"github.com/0xProject/0x-mesh/zeroex/ordervalidator"
+	"github.com/libp2p/go-libp2p-core/peer"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -141,35 +143,9 @@ func (p *FilteredPaginationSubProtocol) HandleOrderSyncResponse(ctx context.Cont
 	if !ok {
 		return nil, fmt.Errorf("FilteredPaginationSubProtocol received response with wrong metadata type (got %T)", res.Metadata)
 	}
-	filteredOrders := []*zeroex.SignedOrder{}
-	for _, order := range res.Orders {
-		if matches, err := p.orderFilter.MatchOrder(order); err != nil {
-			return nil, err
-		} else if matches {
-			filteredOrders = append(filteredOrders, order)
-		} else if !matches {
-			p.app.handlePeerScoreEvent(res.ProviderID, psReceivedOrderDoesNotMatchFilter)
-		}
-	}
-	validationResults, err := p.app.orderWatcher.ValidateAndStoreValidOrders(ctx, filteredOrders, false, p.app.chainID)
-	if err != nil {
+	if _, err := handleOrdersFromOrdersync(ctx, p.app, p.orderFilter, res); err != nil {
 		return nil, err
 	}
-	for _, acceptedOrderInfo := range validationResults.Accepted {
-		if acceptedOrderInfo.IsNew {
-			log.WithFields(map[string]interface{}{
-				"orderHash": acceptedOrderInfo.OrderHash.Hex(),
-				"from":      res.ProviderID.Pretty(),
-				"protocol":  "ordersync",
-			}).Info("received new valid order from peer")
-			log.WithFields(map[string]interface{}{
-				"order":     acceptedOrderInfo.SignedOrder,
-				"orderHash": acceptedOrderInfo.OrderHash.Hex(),
-				"from":      res.ProviderID.Pretty(),
-				"protocol":  "ordersync",
-			}).Trace("all fields for new valid order received from peer")
-		}
-	}
 
 	return &ordersync.Request{
 		Metadata: &FilteredPaginationRequestMetadata{
@@ -203,3 +179,58 @@ func (p *FilteredPaginationSubProtocol) GenerateFirstRequestMetadata() (json.Raw
 		SnapshotID:  "",
 	})
 }
+
+// handleOrdersFromOrdersync validates the orders in the given ordersync
+// response and stores the valid ones. Orders which do not match orderFilter are
+// dropped and count against the provider's peer score. It returns the
+// validation results for the orders which matched the filter.
+func handleOrdersFromOrdersync(ctx context.Context, app *App, orderFilter *orderfilter.Filter, res *ordersync.Response) (*ordervalidator.ValidationResults, error) {
+	filteredOrders := []*zeroex.SignedOrder{}
+	for _, order := range res.Orders {
+		if matches, err := orderFilter.MatchOrder(order); err != nil {
+			return nil, err
+		} else if matches {
+			filteredOrders = append(filteredOrders, order)
+		} else if !matches {
+			app.handlePeerScoreEvent(res.ProviderID, psReceivedOrderDoesNotMatchFilter)
+		}
+	}
+	senders := make([]peer.ID, len(filteredOrders))
+	for i := range senders {
+		senders[i] = res.ProviderID
+	}
+	validationResults, err := app.orderWatcher.ValidateAndStoreValidOrdersFromPeers(ctx, filteredOrders, senders, app.chainID)
+	if err != nil {
+		return nil, err
+	}
+	for _, acceptedOrderInfo := range validationResults.Accepted {
+		if acceptedOrderInfo.IsNew {
+			log.WithFields(map[string]interface{}{
+				"orderHash": acceptedOrderInfo.OrderHash.Hex(),
+				"from":      res.ProviderID.Pretty(),
+				"protocol":  "ordersync",
+			}).Info("received new valid order from peer")
+			log.WithFields(map[string]interface{}{
+				"order":     acceptedOrderInfo.SignedOrder,
+				"orderHash": acceptedOrderInfo.OrderHash.Hex(),
+				"from":      res.ProviderID.Pretty(),
+				"protocol":  "ordersync",
+			}).Trace("all fields for new valid order received from peer")
+			app.orderFilters.recordStored(acceptedOrderInfo.SignedOrder)
+		}
+	}
+	return validationResults, nil
+}
+
+// isLocalRejection returns true if an order was rejected for a reason that is
+// specific to our node and might not apply if the order were received again
+// later (e.g. a quota was exceeded or an Ethereum RPC request failed).
+func isLocalRejection(status ordervalidator.RejectedOrderStatus) bool {
+	switch status {
+	case ordervalidator.ROInternalError, ordervalidator.ROEthRPCRequestFailed, ordervalidator.ROCoordinatorRequestFailed, ordervalidator.RODatabaseFullOfOrders,
+		ordervalidator.ROMakerOrderQuotaExceeded, ordervalidator.ROPeerOrderQuotaExceeded, ordervalidator.ROMaxExpirationExceeded, ordervalidator.ROOrderWouldBeEvicted:
+		return true
+	default:
+		return false
+	}
+}
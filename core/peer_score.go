@@ -12,6 +12,7 @@ const (
 	psValidMessage
 	psOrderStored
 	psReceivedOrderDoesNotMatchFilter
+	psStaleOrder
 )
 
 func (app *App) handlePeerScoreEvent(id peer.ID, event peerScoreEvent) {
@@ -29,6 +30,11 @@ func (app *App) handlePeerScoreEvent(id peer.ID, event peerScoreEvent) {
 		app.node.SetPeerScore(id, "order-stored", 10)
 	case psReceivedOrderDoesNotMatchFilter:
 		app.node.SetPeerScore(id, "received-order-does-not-match-filter", -10)
+	case psStaleOrder:
+		// Honest peers regularly send orders which were filled, cancelled or
+		// expired by the time we receive them, so this penalty is bounded and
+		// can never cause a ban on its own.
+		app.node.SetPeerScore(id, "stale-order", -5)
 	default:
 		log.WithField("event", event).Error("unknown peerScoreEvent")
 	}
@@ -0,0 +1,131 @@
+package core
+
+import (
+	"bufio"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/0xProject/0x-mesh/p2p/privnet"
+	"github.com/libp2p/go-libp2p-core/peer"
+	log "github.com/sirupsen/logrus"
+)
+
+// peerAllowlistReloadInterval is how often to check whether the peer allowlist
+// file has changed.
+const peerAllowlistReloadInterval = 10 * time.Second
+
+// isPrivateNetwork returns true if Mesh is configured to join a private
+// network instead of the public network.
+func (app *App) isPrivateNetwork() bool {
+	return app.privateNetworkKey != nil || app.config.PrivateNetworkAllowlistPath != ""
+}
+
+// getPrivateNetworkID returns the ID of the private network which is used to
+// compute the rendezvous points. It is the configured name of the network or
+// the fingerprint of the pre-shared key if no name was configured.
+func (app *App) getPrivateNetworkID() (string, error) {
+	if app.config.PrivateNetworkName != "" {
+		return app.config.PrivateNetworkName, nil
+	}
+	if app.privateNetworkKey != nil {
+		fingerprint, err := privnet.Fingerprint(app.privateNetworkKey)
+		if err != nil {
+			return "", err
+		}
+		return hex.EncodeToString(fingerprint), nil
+	}
+	return "default", nil
+}
+
+// loadPrivateNetworkKey loads the pre-shared key for the private network from
+// the given path. It returns nil if path is empty.
+func loadPrivateNetworkKey(path string) (*privnet.PSK, error) {
+	if path == "" {
+		return nil, nil
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	psk, err := privnet.DecodeKey(file)
+	if err != nil {
+		return nil, fmt.Errorf("invalid private network key: %s", err.Error())
+	}
+	return psk, nil
+}
+
+// loadPeerAllowlist loads the peer allowlist from the given path. The file
+// must contain one peer ID per line. Empty lines and lines starting with "#"
+// are ignored. It also returns the time at which the file was last modified.
+func loadPeerAllowlist(path string) ([]peer.ID, time.Time, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	peerIDs := []peer.ID{}
+	scanner := bufio.NewScanner(file)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		peerID, err := peer.IDB58Decode(line)
+		if err != nil {
+			return nil, time.Time{}, fmt.Errorf("invalid peer ID in allowlist on line %d: %s", lineNumber, err.Error())
+		}
+		peerIDs = append(peerIDs, peerID)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, time.Time{}, err
+	}
+	return peerIDs, info.ModTime(), nil
+}
+
+// watchPeerAllowlist reloads the peer allowlist whenever the file changes
+// until the context is canceled. lastModified is the time at which the
+// currently used allowlist was last modified.
+func (app *App) watchPeerAllowlist(ctx context.Context, lastModified time.Time) {
+	path := app.config.PrivateNetworkAllowlistPath
+	ticker := time.NewTicker(peerAllowlistReloadInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			info, err := os.Stat(path)
+			if err != nil {
+				log.WithError(err).WithField("path", path).Error("could not check peer allowlist")
+				continue
+			}
+			if info.ModTime().Equal(lastModified) {
+				continue
+			}
+			peerIDs, modified, err := loadPeerAllowlist(path)
+			if err != nil {
+				// Keep using the previous allowlist.
+				log.WithError(err).WithField("path", path).Error("could not reload peer allowlist")
+				lastModified = info.ModTime()
+				continue
+			}
+			lastModified = modified
+			app.node.SetPeerAllowlist(peerIDs)
+			log.WithFields(log.Fields{
+				"path":     path,
+				"numPeers": len(peerIDs),
+			}).Info("reloaded peer allowlist")
+		}
+	}
+}
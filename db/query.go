@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"reflect"
+	"strings"
 
 	"github.com/syndtr/goleveldb/leveldb"
 
@@ -144,6 +145,67 @@ func (q *Query) Count() (int, error) {
 	return len(pkSet), nil
 }
 
+// CountByValue returns the number of models that match the query for each
+// index value. Unlike Count, it does not respect q.Max or q.Offset. It only
+// reads the index, so it is much cheaper than running the query and counting
+// the models by hand.
+func (q *Query) CountByValue() (map[string]int, error) {
+	iter := q.reader.NewIterator(q.filter.slice, nil)
+	defer iter.Release()
+	counts := map[string]int{}
+	for iter.Next() && iter.Error() == nil {
+		// Index keys have the format "<index prefix>:<value>:<primary key>". Both
+		// the value and the primary key are escaped and cannot contain ':'.
+		valAndPK := strings.TrimPrefix(string(iter.Key()), string(q.filter.index.prefix()))
+		split := strings.Split(valAndPK, ":")
+		val, err := unescape([]byte(split[1]))
+		if err != nil {
+			return nil, err
+		}
+		counts[string(val)]++
+	}
+	if iter.Error() != nil {
+		return nil, iter.Error()
+	}
+	return counts, nil
+}
+
+// IDs returns the IDs of the models that match the query. Like Count, it only
+// reads the index and respects q.Max and q.Offset, so it is much cheaper than
+// running the query when the models themselves are not needed. Unlike Run, it
+// ignores q.Reverse.
+func (q *Query) IDs() ([][]byte, error) {
+	iter := q.reader.NewIterator(q.filter.slice, nil)
+	defer iter.Release()
+	pkSet := stringset.New()
+	ids := [][]byte{}
+	for i := 0; iter.Next() && iter.Error() == nil; i++ {
+		if i < q.offset {
+			continue
+		}
+		// Index keys have the format "<index prefix>:<value>:<primary key>". Both
+		// the value and the primary key are escaped and cannot contain ':'.
+		valAndPK := strings.TrimPrefix(string(iter.Key()), string(q.filter.index.prefix()))
+		escapedID := strings.Split(valAndPK, ":")[2]
+		if pkSet.Contains(escapedID) {
+			continue
+		}
+		pkSet.Add(escapedID)
+		id, err := unescape([]byte(escapedID))
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+		if q.max != 0 && len(ids) >= q.max {
+			break
+		}
+	}
+	if iter.Error() != nil {
+		return nil, iter.Error()
+	}
+	return ids, nil
+}
+
 func (q *Query) getModelsWithIteratorForward(iter iterator.Iterator, models interface{}) error {
 	// MultiIndexes can result in the same model being included more than once. To
 	// prevent this, we keep track of the primaryKeys we have already seen using
@@ -262,6 +262,88 @@ func TestFindWithRangeWithMultiIndex(t *testing.T) {
 
 // testQueryWithFilter runs a comprehensive set of queries based on the given
 // filter and checks that the results are always what we expect.
+func TestQueryCountByValue(t *testing.T) {
+	db := newTestDB(t)
+	defer db.Close()
+	col, err := db.NewCollection("people", &testModel{})
+	require.NoError(t, err)
+
+	nicknameIndex := col.AddMultiIndex("nicknames", func(m Model) [][]byte {
+		person := m.(*testModel)
+		values := make([][]byte, len(person.Nicknames))
+		for i, nickname := range person.Nicknames {
+			values[i] = []byte(nickname)
+		}
+		return values
+	})
+
+	// Nicknames with ':' and '\' make sure that index values are unescaped.
+	nicknames := [][]string{
+		{"Al"},
+		{"Al", "Bob:Smith"},
+		{"Bob:Smith"},
+		{"Al", "C\\"},
+		{},
+	}
+	for i, personNicknames := range nicknames {
+		model := &testModel{
+			Name:      "Person_" + strconv.Itoa(i),
+			Age:       i,
+			Nicknames: personNicknames,
+		}
+		require.NoError(t, col.Insert(model))
+	}
+
+	counts, err := col.NewQuery(nicknameIndex.All()).CountByValue()
+	require.NoError(t, err)
+	expected := map[string]int{
+		"Al":        3,
+		"Bob:Smith": 2,
+		"C\\":       1,
+	}
+	assert.Equal(t, expected, counts)
+
+	counts, err = col.NewQuery(nicknameIndex.PrefixFilter([]byte("Bob"))).CountByValue()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"Bob:Smith": 2}, counts)
+}
+
+func TestQueryIDs(t *testing.T) {
+	db := newTestDB(t)
+	defer db.Close()
+	col, err := db.NewCollection("people", &testModel{})
+	require.NoError(t, err)
+
+	nicknameIndex := col.AddMultiIndex("nicknames", func(m Model) [][]byte {
+		person := m.(*testModel)
+		values := make([][]byte, len(person.Nicknames))
+		for i, nickname := range person.Nicknames {
+			values[i] = []byte(nickname)
+		}
+		return values
+	})
+
+	// Names with ':' and '\' make sure that IDs are unescaped.
+	people := []*testModel{
+		{Name: "Alice:Smith", Nicknames: []string{"Al", "Ally"}},
+		{Name: "Bob", Nicknames: []string{"Bob"}},
+		{Name: "C\\", Nicknames: []string{"Al"}},
+		{Name: "Dave"},
+	}
+	for _, person := range people {
+		require.NoError(t, col.Insert(person))
+	}
+
+	// Alice is only included once even though she has two nicknames.
+	ids, err := col.NewQuery(nicknameIndex.All()).IDs()
+	require.NoError(t, err)
+	assert.ElementsMatch(t, [][]byte{[]byte("Alice:Smith"), []byte("Bob"), []byte("C\\")}, ids)
+
+	ids, err = col.NewQuery(nicknameIndex.ValueFilter([]byte("Al"))).Max(1).IDs()
+	require.NoError(t, err)
+	assert.Len(t, ids, 1)
+}
+
 func testQueryWithFilter(t *testing.T, col *Collection, filter *Filter, expected []*testModel) {
 	reverseExpected := reverseSlice(expected)
 	// safeMax is min(2, len(expected)) to account for the fact that expected may
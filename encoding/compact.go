@@ -0,0 +1,372 @@
+package encoding
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/0xProject/0x-mesh/constants"
+	"github.com/0xProject/0x-mesh/zeroex"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/golang/snappy"
+)
+
+// compactMessagePrefix is the first byte of every message in the compact
+// binary encoding. JSON messages always begin with '{' or whitespace, which
+// allows both encodings to be decoded by the same functions.
+const compactMessagePrefix byte = 0x00
+
+// MessageType identifies the contents and version of a message in the compact
+// binary encoding. It is the second byte of every compact message. New
+// versions must use a new MessageType so that older nodes can reject messages
+// which they do not understand.
+type MessageType byte
+
+const (
+	// MessageTypeOrderV1 is a single order in version 1 of the compact encoding.
+	MessageTypeOrderV1 MessageType = 0x01
+	// MessageTypeOrderV1Snappy is a single order in version 1 of the compact
+	// encoding, compressed with snappy.
+	MessageTypeOrderV1Snappy MessageType = 0x02
+	// MessageTypeOrdersV1 is a list of orders in version 1 of the compact
+	// encoding. The number of orders follows the message type and is never
+	// compressed, so that it can be read without decoding the orders.
+	MessageTypeOrdersV1 MessageType = 0x03
+	// MessageTypeOrdersV1Snappy is a list of orders in version 1 of the compact
+	// encoding, compressed with snappy.
+	MessageTypeOrdersV1Snappy MessageType = 0x04
+)
+
+const (
+	// MaxOrdersPerMessage is the maximum number of orders in a single batched
+	// GossipSub message. It must be lower than the burst of the per-peer rate
+	// limit, since every order counts toward the limit.
+	MaxOrdersPerMessage = 50
+	// maxBatchPayloadSize is the maximum size of the uncompressed orders in a
+	// batched GossipSub message. It ensures that messages never exceed the
+	// maximum message size, even if they can't be compressed.
+	maxBatchPayloadSize = constants.MaxOrderSizeInBytes - 2 - binary.MaxVarintLen64
+)
+
+var (
+	errUnexpectedEOF = errors.New("unexpected end of compact message")
+	errNegativeInt   = errors.New("cannot encode negative integer")
+)
+
+// IsCompactMessage returns true if the given message uses the compact binary
+// encoding (as opposed to JSON).
+func IsCompactMessage(data []byte) bool {
+	return len(data) > 0 && data[0] == compactMessagePrefix
+}
+
+// OrderToCompactMessage encodes an order into an order message using the
+// compact binary encoding. The message is compressed with snappy if that makes
+// it smaller.
+func OrderToCompactMessage(order *zeroex.SignedOrder) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	if err := writeSignedOrder(buf, order); err != nil {
+		return nil, err
+	}
+	return newCompactMessage(MessageTypeOrderV1, MessageTypeOrderV1Snappy, buf.Bytes()), nil
+}
+
+// OrdersToCompactMessage encodes a list of orders using the compact binary
+// encoding. The message is compressed with snappy if that makes it smaller.
+func OrdersToCompactMessage(orders []*zeroex.SignedOrder) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	for _, order := range orders {
+		if err := writeSignedOrder(buf, order); err != nil {
+			return nil, err
+		}
+	}
+	return newOrdersCompactMessage(len(orders), buf.Bytes()), nil
+}
+
+// OrdersToCompactMessages encodes the given orders into as few batched
+// GossipSub messages as possible. Each message contains at most
+// MaxOrdersPerMessage orders and does not exceed the maximum message size.
+func OrdersToCompactMessages(orders []*zeroex.SignedOrder) ([][]byte, error) {
+	messages := [][]byte{}
+	batch := &bytes.Buffer{}
+	batchSize := 0
+	encodedOrder := &bytes.Buffer{}
+	for _, order := range orders {
+		encodedOrder.Reset()
+		if err := writeSignedOrder(encodedOrder, order); err != nil {
+			return nil, err
+		}
+		if batchSize > 0 && (batchSize == MaxOrdersPerMessage || batch.Len()+encodedOrder.Len() > maxBatchPayloadSize) {
+			messages = append(messages, newOrdersCompactMessage(batchSize, batch.Bytes()))
+			batch = &bytes.Buffer{}
+			batchSize = 0
+		}
+		batch.Write(encodedOrder.Bytes())
+		batchSize++
+	}
+	if batchSize > 0 {
+		messages = append(messages, newOrdersCompactMessage(batchSize, batch.Bytes()))
+	}
+	return messages, nil
+}
+
+// CompactMessageToOrders decodes a list of orders that was encoded with
+// OrdersToCompactMessage. maxSize is the maximum size of the message after
+// decompression.
+func CompactMessageToOrders(data []byte, maxSize int) ([]*zeroex.SignedOrder, error) {
+	numOrders, n, err := numOrdersInCompactMessage(data)
+	if err != nil {
+		return nil, err
+	}
+	// The number of orders is not part of the compressed payload, so we remove
+	// it before opening the message.
+	withoutCount := append([]byte{data[0], data[1]}, data[2+n:]...)
+	payload, err := openCompactMessage(withoutCount, MessageTypeOrdersV1, MessageTypeOrdersV1Snappy, maxSize)
+	if err != nil {
+		return nil, err
+	}
+	r := bytes.NewReader(payload)
+	// Every order takes up more than one byte, so this prevents us from
+	// allocating a huge slice for a malicious message.
+	if numOrders > uint64(r.Len()) {
+		return nil, errUnexpectedEOF
+	}
+	orders := make([]*zeroex.SignedOrder, 0, numOrders)
+	for i := uint64(0); i < numOrders; i++ {
+		order, err := readSignedOrder(r)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, order)
+	}
+	if r.Len() != 0 {
+		return nil, errors.New("unexpected trailing bytes in compact message")
+	}
+	return orders, nil
+}
+
+func compactMessageToOrder(data []byte, maxSize int) (*zeroex.SignedOrder, error) {
+	payload, err := openCompactMessage(data, MessageTypeOrderV1, MessageTypeOrderV1Snappy, maxSize)
+	if err != nil {
+		return nil, err
+	}
+	r := bytes.NewReader(payload)
+	order, err := readSignedOrder(r)
+	if err != nil {
+		return nil, err
+	}
+	if r.Len() != 0 {
+		return nil, errors.New("unexpected trailing bytes in compact message")
+	}
+	return order, nil
+}
+
+// numOrdersInCompactMessage returns the number of orders in the given
+// MessageTypeOrdersV1 or MessageTypeOrdersV1Snappy message as well as the
+// number of bytes used to encode it.
+func numOrdersInCompactMessage(data []byte) (uint64, int, error) {
+	if !IsCompactMessage(data) || len(data) < 2 {
+		return 0, 0, errors.New("not a compact message")
+	}
+	if actualType := MessageType(data[1]); actualType != MessageTypeOrdersV1 && actualType != MessageTypeOrdersV1Snappy {
+		return 0, 0, fmt.Errorf("unexpected message type: %d", actualType)
+	}
+	numOrders, n := binary.Uvarint(data[2:])
+	if n <= 0 {
+		return 0, 0, errUnexpectedEOF
+	}
+	return numOrders, n, nil
+}
+
+// newOrdersCompactMessage adds the message header, including the number of
+// orders, to the given payload, compressing it with snappy if that makes it
+// smaller.
+func newOrdersCompactMessage(numOrders int, payload []byte) []byte {
+	message := newCompactMessage(MessageTypeOrdersV1, MessageTypeOrdersV1Snappy, payload)
+	var count [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(count[:], uint64(numOrders))
+	withCount := make([]byte, 0, len(message)+n)
+	withCount = append(withCount, message[:2]...)
+	withCount = append(withCount, count[:n]...)
+	return append(withCount, message[2:]...)
+}
+
+// newCompactMessage adds the message header to the given payload, compressing
+// it with snappy if that makes it smaller.
+func newCompactMessage(messageType, snappyMessageType MessageType, payload []byte) []byte {
+	compressed := snappy.Encode(nil, payload)
+	if len(compressed) < len(payload) {
+		return append([]byte{compactMessagePrefix, byte(snappyMessageType)}, compressed...)
+	}
+	return append([]byte{compactMessagePrefix, byte(messageType)}, payload...)
+}
+
+// openCompactMessage checks the message header and returns the (decompressed)
+// payload of the given message.
+func openCompactMessage(data []byte, messageType, snappyMessageType MessageType, maxSize int) ([]byte, error) {
+	if !IsCompactMessage(data) || len(data) < 2 {
+		return nil, errors.New("not a compact message")
+	}
+	switch actualType := MessageType(data[1]); actualType {
+	case messageType:
+		return data[2:], nil
+	case snappyMessageType:
+		decodedLen, err := snappy.DecodedLen(data[2:])
+		if err != nil {
+			return nil, err
+		}
+		if decodedLen > maxSize {
+			return nil, fmt.Errorf("decompressed message exceeds maximum size of %d bytes", maxSize)
+		}
+		return snappy.Decode(nil, data[2:])
+	default:
+		return nil, fmt.Errorf("unexpected message type: %d", actualType)
+	}
+}
+
+func writeSignedOrder(buf *bytes.Buffer, order *zeroex.SignedOrder) error {
+	if err := writeBigInt(buf, order.ChainID); err != nil {
+		return err
+	}
+	buf.Write(order.ExchangeAddress.Bytes())
+	buf.Write(order.MakerAddress.Bytes())
+	writeBytes(buf, order.MakerAssetData)
+	writeBytes(buf, order.MakerFeeAssetData)
+	for _, value := range []*big.Int{order.MakerAssetAmount, order.MakerFee} {
+		if err := writeBigInt(buf, value); err != nil {
+			return err
+		}
+	}
+	buf.Write(order.TakerAddress.Bytes())
+	writeBytes(buf, order.TakerAssetData)
+	writeBytes(buf, order.TakerFeeAssetData)
+	for _, value := range []*big.Int{order.TakerAssetAmount, order.TakerFee} {
+		if err := writeBigInt(buf, value); err != nil {
+			return err
+		}
+	}
+	buf.Write(order.SenderAddress.Bytes())
+	buf.Write(order.FeeRecipientAddress.Bytes())
+	for _, value := range []*big.Int{order.ExpirationTimeSeconds, order.Salt} {
+		if err := writeBigInt(buf, value); err != nil {
+			return err
+		}
+	}
+	writeBytes(buf, order.Signature)
+	return nil
+}
+
+func readSignedOrder(r *bytes.Reader) (*zeroex.SignedOrder, error) {
+	order := &zeroex.SignedOrder{}
+	var err error
+	if order.ChainID, err = readBigInt(r); err != nil {
+		return nil, err
+	}
+	if order.ExchangeAddress, err = readAddress(r); err != nil {
+		return nil, err
+	}
+	if order.MakerAddress, err = readAddress(r); err != nil {
+		return nil, err
+	}
+	if order.MakerAssetData, err = readBytes(r); err != nil {
+		return nil, err
+	}
+	if order.MakerFeeAssetData, err = readBytes(r); err != nil {
+		return nil, err
+	}
+	if order.MakerAssetAmount, err = readBigInt(r); err != nil {
+		return nil, err
+	}
+	if order.MakerFee, err = readBigInt(r); err != nil {
+		return nil, err
+	}
+	if order.TakerAddress, err = readAddress(r); err != nil {
+		return nil, err
+	}
+	if order.TakerAssetData, err = readBytes(r); err != nil {
+		return nil, err
+	}
+	if order.TakerFeeAssetData, err = readBytes(r); err != nil {
+		return nil, err
+	}
+	if order.TakerAssetAmount, err = readBigInt(r); err != nil {
+		return nil, err
+	}
+	if order.TakerFee, err = readBigInt(r); err != nil {
+		return nil, err
+	}
+	if order.SenderAddress, err = readAddress(r); err != nil {
+		return nil, err
+	}
+	if order.FeeRecipientAddress, err = readAddress(r); err != nil {
+		return nil, err
+	}
+	if order.ExpirationTimeSeconds, err = readBigInt(r); err != nil {
+		return nil, err
+	}
+	if order.Salt, err = readBigInt(r); err != nil {
+		return nil, err
+	}
+	if order.Signature, err = readBytes(r); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+func writeUvarint(buf *bytes.Buffer, x uint64) {
+	var scratch [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(scratch[:], x)
+	buf.Write(scratch[:n])
+}
+
+func writeBytes(buf *bytes.Buffer, b []byte) {
+	writeUvarint(buf, uint64(len(b)))
+	buf.Write(b)
+}
+
+// writeBigInt writes the given non-negative integer as length-prefixed big
+// endian bytes. A nil integer is encoded the same way as zero.
+func writeBigInt(buf *bytes.Buffer, x *big.Int) error {
+	if x == nil {
+		writeBytes(buf, nil)
+		return nil
+	}
+	if x.Sign() < 0 {
+		return errNegativeInt
+	}
+	writeBytes(buf, x.Bytes())
+	return nil
+}
+
+func readBytes(r *bytes.Reader) ([]byte, error) {
+	length, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, errUnexpectedEOF
+	}
+	if length > uint64(r.Len()) {
+		return nil, errUnexpectedEOF
+	}
+	b := make([]byte, length)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return nil, errUnexpectedEOF
+	}
+	return b, nil
+}
+
+func readBigInt(r *bytes.Reader) (*big.Int, error) {
+	b, err := readBytes(r)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+func readAddress(r *bytes.Reader) (common.Address, error) {
+	var address common.Address
+	if _, err := io.ReadFull(r, address[:]); err != nil {
+		return common.Address{}, errUnexpectedEOF
+	}
+	return address, nil
+}
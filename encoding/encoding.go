@@ -2,8 +2,10 @@ package encoding
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 
+	"github.com/0xProject/0x-mesh/constants"
 	"github.com/0xProject/0x-mesh/zeroex"
 )
 
@@ -22,8 +24,12 @@ func OrderToRawMessage(topic string, order *zeroex.SignedOrder) ([]byte, error)
 	})
 }
 
-// RawMessageToOrder decodes an order message sent over the wire into an order
+// RawMessageToOrder decodes an order message sent over the wire into an order.
+// It supports both the JSON encoding and the compact binary encoding.
 func RawMessageToOrder(data []byte) (*zeroex.SignedOrder, error) {
+	if IsCompactMessage(data) {
+		return compactMessageToOrder(data, constants.MaxMessageSizeInBytes)
+	}
 	var orderMessage orderMessage
 	if err := json.Unmarshal(data, &orderMessage); err != nil {
 		return nil, err
@@ -33,3 +39,46 @@ func RawMessageToOrder(data []byte) (*zeroex.SignedOrder, error) {
 	}
 	return orderMessage.Order, nil
 }
+
+// RawMessageToOrders decodes a message sent over the wire into a list of
+// orders. In addition to the messages supported by RawMessageToOrder, it
+// supports batched messages in the compact binary encoding, which may contain
+// up to MaxOrdersPerMessage orders.
+func RawMessageToOrders(data []byte) ([]*zeroex.SignedOrder, error) {
+	if IsCompactMessage(data) && len(data) >= 2 && (MessageType(data[1]) == MessageTypeOrdersV1 || MessageType(data[1]) == MessageTypeOrdersV1Snappy) {
+		numOrders, _, err := numOrdersInCompactMessage(data)
+		if err != nil {
+			return nil, err
+		}
+		if numOrders == 0 {
+			return nil, errors.New("message does not contain any orders")
+		} else if numOrders > MaxOrdersPerMessage {
+			return nil, fmt.Errorf("message contains more than %d orders", MaxOrdersPerMessage)
+		}
+		return CompactMessageToOrders(data, constants.MaxMessageSizeInBytes)
+	}
+	order, err := RawMessageToOrder(data)
+	if err != nil {
+		return nil, err
+	}
+	return []*zeroex.SignedOrder{order}, nil
+}
+
+// NumOrdersInMessage returns the number of orders in a message sent over the
+// wire without decoding the orders. It returns 1 for any message that is not a
+// batched message, including invalid messages.
+func NumOrdersInMessage(data []byte) int {
+	if !IsCompactMessage(data) {
+		return 1
+	}
+	numOrders, _, err := numOrdersInCompactMessage(data)
+	if err != nil || numOrders == 0 {
+		return 1
+	}
+	if numOrders > MaxOrdersPerMessage {
+		// The message is invalid, but it should still count as much as the
+		// largest valid message.
+		return MaxOrdersPerMessage
+	}
+	return int(numOrders)
+}
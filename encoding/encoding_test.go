@@ -0,0 +1,170 @@
+package encoding
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/0xProject/0x-mesh/constants"
+	"github.com/0xProject/0x-mesh/zeroex"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestOrder(salt int64) *zeroex.SignedOrder {
+	return &zeroex.SignedOrder{
+		Order: zeroex.Order{
+			ChainID:               big.NewInt(constants.TestChainID),
+			ExchangeAddress:       common.HexToAddress("0x48bacb9266a570d521063ef5dd96e61686dbe788"),
+			MakerAddress:          constants.GanacheAccount0,
+			MakerAssetData:        common.FromHex("0xf47261b0000000000000000000000000871dd7c2b4b25e1aa18728e9d5f2af4c4e431f5c"),
+			MakerFeeAssetData:     []byte{},
+			MakerAssetAmount:      big.NewInt(1000),
+			MakerFee:              big.NewInt(0),
+			TakerAddress:          constants.NullAddress,
+			TakerAssetData:        common.FromHex("0xf47261b00000000000000000000000000b1ba0af832d7c05fd64161e0db78e85978e8082"),
+			TakerFeeAssetData:     []byte{},
+			TakerAssetAmount:      big.NewInt(2000),
+			TakerFee:              big.NewInt(0),
+			SenderAddress:         constants.NullAddress,
+			FeeRecipientAddress:   common.HexToAddress("0xa258b39954cef5cb142fd567a46cddb31a670124"),
+			ExpirationTimeSeconds: big.NewInt(1548619325),
+			Salt:                  big.NewInt(salt),
+		},
+		Signature: bytes.Repeat([]byte{0x1b}, 66),
+	}
+}
+
+func TestCompactMessageRoundTrip(t *testing.T) {
+	order := newTestOrder(1548619145450)
+	encoded, err := OrderToCompactMessage(order)
+	require.NoError(t, err)
+	assert.True(t, IsCompactMessage(encoded))
+
+	jsonEncoded, err := OrderToRawMessage("/test-topic", order)
+	require.NoError(t, err)
+	assert.False(t, IsCompactMessage(jsonEncoded))
+	assert.Less(t, len(encoded), len(jsonEncoded))
+
+	decoded, err := RawMessageToOrder(encoded)
+	require.NoError(t, err)
+	assert.Equal(t, order, decoded)
+
+	// JSON messages can still be decoded.
+	decoded, err = RawMessageToOrder(jsonEncoded)
+	require.NoError(t, err)
+	expectedHash, err := order.ComputeOrderHash()
+	require.NoError(t, err)
+	actualHash, err := decoded.ComputeOrderHash()
+	require.NoError(t, err)
+	assert.Equal(t, expectedHash, actualHash)
+}
+
+func TestCompactMessageOrdersRoundTrip(t *testing.T) {
+	// Enough similar orders that the message is compressed.
+	orders := []*zeroex.SignedOrder{}
+	for i := int64(0); i < 20; i++ {
+		orders = append(orders, newTestOrder(i))
+	}
+	encoded, err := OrdersToCompactMessage(orders)
+	require.NoError(t, err)
+	assert.Equal(t, MessageTypeOrdersV1Snappy, MessageType(encoded[1]))
+
+	decoded, err := CompactMessageToOrders(encoded, constants.MaxMessageSizeInBytes*len(orders))
+	require.NoError(t, err)
+	assert.Equal(t, orders, decoded)
+
+	// The decompressed message exceeds maxSize.
+	_, err = CompactMessageToOrders(encoded, 100)
+	assert.Error(t, err)
+
+	// No orders.
+	encoded, err = OrdersToCompactMessage([]*zeroex.SignedOrder{})
+	require.NoError(t, err)
+	decoded, err = CompactMessageToOrders(encoded, constants.MaxMessageSizeInBytes)
+	require.NoError(t, err)
+	assert.Len(t, decoded, 0)
+}
+
+func TestOrdersToCompactMessages(t *testing.T) {
+	orders := []*zeroex.SignedOrder{}
+	for i := int64(0); i < 2*MaxOrdersPerMessage+20; i++ {
+		orders = append(orders, newTestOrder(i))
+	}
+	messages, err := OrdersToCompactMessages(orders)
+	require.NoError(t, err)
+	require.Len(t, messages, 3)
+
+	decodedOrders := []*zeroex.SignedOrder{}
+	for _, message := range messages {
+		assert.LessOrEqual(t, len(message), constants.MaxOrderSizeInBytes)
+		decoded, err := RawMessageToOrders(message)
+		require.NoError(t, err)
+		assert.Equal(t, len(decoded), NumOrdersInMessage(message))
+		decodedOrders = append(decodedOrders, decoded...)
+	}
+	assert.Equal(t, orders, decodedOrders)
+	assert.Equal(t, MaxOrdersPerMessage, NumOrdersInMessage(messages[0]))
+	assert.Equal(t, 20, NumOrdersInMessage(messages[2]))
+
+	// Single order messages count as one order.
+	jsonEncoded, err := OrderToRawMessage("/test-topic", orders[0])
+	require.NoError(t, err)
+	assert.Equal(t, 1, NumOrdersInMessage(jsonEncoded))
+	decoded, err := RawMessageToOrders(jsonEncoded)
+	require.NoError(t, err)
+	assert.Len(t, decoded, 1)
+
+	// Batched messages with too many orders are rejected.
+	tooManyOrders, err := OrdersToCompactMessage(orders[:MaxOrdersPerMessage+1])
+	require.NoError(t, err)
+	_, err = RawMessageToOrders(tooManyOrders)
+	assert.Error(t, err)
+	assert.Equal(t, MaxOrdersPerMessage, NumOrdersInMessage(tooManyOrders))
+
+	// Batched messages without any orders are rejected.
+	noOrders, err := OrdersToCompactMessage([]*zeroex.SignedOrder{})
+	require.NoError(t, err)
+	_, err = RawMessageToOrders(noOrders)
+	assert.Error(t, err)
+}
+
+func TestCompactMessageInvalid(t *testing.T) {
+	// Use an uncompressed message so that it is easy to modify.
+	buf := &bytes.Buffer{}
+	require.NoError(t, writeSignedOrder(buf, newTestOrder(1)))
+	encoded := append([]byte{compactMessagePrefix, byte(MessageTypeOrderV1)}, buf.Bytes()...)
+	_, err := RawMessageToOrder(encoded)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name    string
+		encoded []byte
+	}{
+		{
+			name:    "truncated",
+			encoded: encoded[:len(encoded)-1],
+		},
+		{
+			name:    "trailing bytes",
+			encoded: append(append([]byte{}, encoded...), 0x00),
+		},
+		{
+			name:    "unknown message type",
+			encoded: append([]byte{compactMessagePrefix, 0xff}, encoded[2:]...),
+		},
+		{
+			name:    "list of orders",
+			encoded: append([]byte{compactMessagePrefix, byte(MessageTypeOrdersV1)}, encoded[2:]...),
+		},
+		{
+			name:    "header only",
+			encoded: []byte{compactMessagePrefix},
+		},
+	}
+	for _, tc := range testCases {
+		_, err := RawMessageToOrder(tc.encoded)
+		assert.Error(t, err, tc.name)
+	}
+}
@@ -21,6 +21,13 @@ type ContractAddresses struct {
 	ChaiBridge          common.Address `json:"chaiBridge"`
 	ChaiToken           common.Address `json:"chaiToken"`
 	MaximumGasPrice     common.Address `json:"maximumGasPrice"`
+	// ExchangeProxy is the address of the Exchange Proxy (V4) contract. It is
+	// optional and support for V4 limit and RFQ orders is disabled if it is not
+	// set.
+	ExchangeProxy common.Address `json:"exchangeProxy"`
+	// Multicall is the address of a Multicall2 contract. It is optional and is
+	// used to batch contract signature checks into a single eth_call if set.
+	Multicall common.Address `json:"multicall"`
 }
 
 // GanacheAddresses The addresses that the 0x contracts were deployed to on the Ganache snapshot (chainID = 1337).
@@ -43,6 +50,8 @@ func NewContractAddressesForChainID(chainID int) (ContractAddresses, error) {
 			ChaiBridge:          common.HexToAddress("0x0000000000000000000000000000000000000000"),
 			ChaiToken:           common.HexToAddress("0x0000000000000000000000000000000000000000"),
 			MaximumGasPrice:     common.HexToAddress("0x0000000000000000000000000000000000000000"),
+			ExchangeProxy:       common.HexToAddress("0x0000000000000000000000000000000000000000"),
+			Multicall:           common.HexToAddress("0x5ba1e12693dc8f9c48aad8770482f4739beed696"),
 		}, nil
 	case 15001:
 		return ContractAddresses{
@@ -58,6 +67,10 @@ func NewContractAddressesForChainID(chainID int) (ContractAddresses, error) {
 			ChaiBridge:          common.HexToAddress("0x0000000000000000000000000000000000000000"),
 			ChaiToken:           common.HexToAddress("0x0000000000000000000000000000000000000000"),
 			MaximumGasPrice:     common.HexToAddress("0x2c668051f237caa8aba4277143ac5f663bdbfeca"),
+			ExchangeProxy:       common.HexToAddress("0x0000000000000000000000000000000000000000"),
+			// Multicall2 is not deployed on this chain. It can be set with custom
+			// contract addresses once it is.
+			Multicall: common.HexToAddress("0x0000000000000000000000000000000000000000"),
 		}, nil
 	case 1337:
 		return ganacheAddresses(), nil
@@ -73,9 +86,8 @@ func ValidateContractAddressesForChainID(chainID int, addresses ContractAddresse
 	if addresses.Exchange == constants.NullAddress {
 		return fmt.Errorf("cannot add contract addresses for chain ID %d: Exchange address is required", chainID)
 	}
-	if addresses.DevUtils == constants.NullAddress {
-		return fmt.Errorf("cannot add contract addresses for chain ID %d: DevUtils address is required", chainID)
-	}
+	// DevUtils is not required here since it is not needed by every order
+	// validator backend. Callers which depend on DevUtils should check for it.
 	if addresses.ERC20Proxy == constants.NullAddress {
 		return fmt.Errorf("cannot add contract addresses for chain ID %d: ERC20Proxy address is required", chainID)
 	}
@@ -108,5 +120,9 @@ func ganacheAddresses() ContractAddresses {
 		ChaiBridge:          common.HexToAddress("0x0000000000000000000000000000000000000000"),
 		ChaiToken:           common.HexToAddress("0x0000000000000000000000000000000000000000"),
 		MaximumGasPrice:     common.HexToAddress("0x2c530e4ecc573f11bd72cf5fdf580d134d25f15f"),
+		ExchangeProxy:       common.HexToAddress("0x0000000000000000000000000000000000000000"),
+		// Multicall2 is not part of the Ganache snapshot, so signatures are
+		// checked with one call to the Exchange per order in tests.
+		Multicall: common.HexToAddress("0x0000000000000000000000000000000000000000"),
 	}
 }
@@ -0,0 +1,18 @@
+package signer
+
+import (
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+)
+
+// NewKeystoreSigner decrypts an Ethereum keystore file (as created by geth or
+// most wallets) with the given passphrase and returns a LocalSigner for the
+// private key it contains.
+func NewKeystoreSigner(keyJSON []byte, passphrase string) (*LocalSigner, error) {
+	key, err := keystore.DecryptKey(keyJSON, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return &LocalSigner{
+		privateKey: key.PrivateKey,
+	}, nil
+}
@@ -0,0 +1,49 @@
+// +build !js
+
+package signer
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/0xProject/0x-mesh/constants"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeystoreSigner(t *testing.T) {
+	dir, err := ioutil.TempDir("", "keystore")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	signerAddress := constants.GanacheAccount0
+	privateKey, err := crypto.ToECDSA(constants.GanacheAccountToPrivateKey[signerAddress])
+	require.NoError(t, err)
+	ks := keystore.NewKeyStore(dir, keystore.LightScryptN, keystore.LightScryptP)
+	account, err := ks.ImportECDSA(privateKey, "passphrase")
+	require.NoError(t, err)
+	keyJSON, err := ioutil.ReadFile(account.URL.Path)
+	require.NoError(t, err)
+
+	_, err = NewKeystoreSigner(keyJSON, "wrong passphrase")
+	assert.Error(t, err)
+
+	keystoreSigner, err := NewKeystoreSigner(keyJSON, "passphrase")
+	require.NoError(t, err)
+	assert.Equal(t, signerAddress, keystoreSigner.GetSignerAddress())
+
+	// Test parameters lifted from @0x/order-utils' `signature_utils_test.ts`
+	message := common.Hex2Bytes("6927e990021d23b1eb7b8789f6a6feaf98fe104bb0cf8259421b79f9a34222b0")
+	expectedSignature := &ECSignature{
+		V: byte(27),
+		R: common.HexToHash("61a3ed31b43c8780e905a260a35faefcc527be7516aa11c0256729b5b351bc33"),
+		S: common.HexToHash("40349190569279751135161d22529dc25add4f6069af05be04cacbda2ace2254"),
+	}
+	actualSignature, err := keystoreSigner.EthSign(message, signerAddress)
+	require.NoError(t, err)
+	assert.Equal(t, expectedSignature, actualSignature)
+}
@@ -9,12 +9,14 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/rpc"
+	gethsigner "github.com/ethereum/go-ethereum/signer/core"
 	"golang.org/x/crypto/sha3"
 )
 
 // Signer defines the methods needed to act as a elliptic curve signer
 type Signer interface {
 	EthSign(message []byte, signerAddress common.Address) (*ECSignature, error)
+	SignTypedData(typedData *gethsigner.TypedData, signerAddress common.Address) (*ECSignature, error)
 }
 
 // ECSignature contains the parameters of an elliptic curve signature
@@ -44,7 +46,26 @@ func (e *EthRPCSigner) EthSign(message []byte, signerAddress common.Address) (*E
 		return nil, err
 	}
 	// `eth_sign` returns the signature in the [R || S || V] format where V is 0 or 1.
-	signatureBytes := common.Hex2Bytes(signatureHex[2:])
+	return parseRPCSignature(signatureHex)
+}
+
+// SignTypedData signs EIP-712 typed data via the `eth_signTypedData_v4`
+// Ethereum JSON-RPC call
+func (e *EthRPCSigner) SignTypedData(typedData *gethsigner.TypedData, signerAddress common.Address) (*ECSignature, error) {
+	var signatureHex string
+	if err := e.rpcClient.Call(&signatureHex, "eth_signTypedData_v4", signerAddress.Hex(), typedDataJSON(typedData)); err != nil {
+		return nil, err
+	}
+	return parseRPCSignature(signatureHex)
+}
+
+// parseRPCSignature parses a signature returned by an Ethereum JSON-RPC call
+// in the [R || S || V] format where V is 0, 1, 27 or 28.
+func parseRPCSignature(signatureHex string) (*ECSignature, error) {
+	signatureBytes := common.FromHex(signatureHex)
+	if len(signatureBytes) != 65 {
+		return nil, fmt.Errorf("invalid signature length: %d", len(signatureBytes))
+	}
 	vParam := signatureBytes[64]
 	if vParam == byte(0) {
 		vParam = byte(27)
@@ -90,6 +111,16 @@ func (l *LocalSigner) EthSign(message []byte, signerAddress common.Address) (*EC
 	return ecSignature, nil
 }
 
+// SignTypedData signs the EIP-712 hash of the typed data locally with its
+// supplied private key
+func (l *LocalSigner) SignTypedData(typedData *gethsigner.TypedData, signerAddress common.Address) (*ECSignature, error) {
+	hash, err := TypedDataHash(typedData)
+	if err != nil {
+		return nil, err
+	}
+	return l.sign(hash.Bytes(), signerAddress)
+}
+
 // Sign signs the message with the corresponding private key to the supplied signerAddress and returns
 // the raw signature byte array
 func (l *LocalSigner) simpleSign(message []byte, signerAddress common.Address) ([]byte, error) {
@@ -156,6 +187,22 @@ func (t *TestSigner) EthSign(message []byte, signerAddress common.Address) (*ECS
 	return localSigner.EthSign(message, signerAddress)
 }
 
+// SignTypedData generates an `eth_signTypedData_v4` equivalent signature using
+// an public/private key pair hard-coded in the constants package.
+func (t *TestSigner) SignTypedData(typedData *gethsigner.TypedData, signerAddress common.Address) (*ECSignature, error) {
+	pkBytes, ok := constants.GanacheAccountToPrivateKey[signerAddress]
+	if !ok {
+		return nil, errors.New("Unrecognized Ganache account supplied to ECSignForTests")
+	}
+	privateKey, err := crypto.ToECDSA(pkBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	localSigner := NewLocalSigner(privateKey)
+	return localSigner.SignTypedData(typedData, signerAddress)
+}
+
 // SignTx signs an Ethereum transaction with a public/private key pair hard-coded in the constants package.
 // It returns the transaction signature.
 func (t *TestSigner) SignTx(message []byte, signerAddress common.Address) ([]byte, error) {
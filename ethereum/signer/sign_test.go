@@ -3,11 +3,13 @@
 package signer
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/0xProject/0x-mesh/constants"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/rpc"
+	gethsigner "github.com/ethereum/go-ethereum/signer/core"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -47,3 +49,19 @@ func TestTestSigner(t *testing.T) {
 
 	assert.Equal(t, expectedSignature, actualSignature)
 }
+
+func TestTypedDataJSONEncodesBytesAsHex(t *testing.T) {
+	typedData := &gethsigner.TypedData{
+		PrimaryType: "Order",
+		Message: gethsigner.TypedDataMessage{
+			"makerAssetData": []byte{0xf4, 0x72, 0x61, 0xb0},
+			"salt":           "200",
+		},
+	}
+	encoded, err := json.Marshal(typedDataJSON(typedData))
+	require.NoError(t, err)
+	assert.Contains(t, string(encoded), `"makerAssetData":"0xf47261b0"`)
+	assert.Contains(t, string(encoded), `"salt":"200"`)
+	// The original typed data is not modified.
+	assert.Equal(t, []byte{0xf4, 0x72, 0x61, 0xb0}, typedData.Message["makerAssetData"])
+}
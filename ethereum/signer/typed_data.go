@@ -0,0 +1,50 @@
+package signer
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	gethsigner "github.com/ethereum/go-ethereum/signer/core"
+)
+
+// TypedDataHash computes the EIP-712 hash of the typed data, which is the
+// message that is signed by `eth_signTypedData_v4`. The hash is calculated as
+//   keccak256("\x19\x01" || domainSeparator || hashStruct(message)).
+func TypedDataHash(typedData *gethsigner.TypedData) (common.Hash, error) {
+	domainSeparator, err := typedData.HashStruct("EIP712Domain", typedData.Domain.Map())
+	if err != nil {
+		return common.Hash{}, err
+	}
+	typedDataHash, err := typedData.HashStruct(typedData.PrimaryType, typedData.Message)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return crypto.Keccak256Hash([]byte("\x19\x01"), domainSeparator, typedDataHash), nil
+}
+
+// typedDataJSON returns a copy of the typed data in which all byte slices in
+// the message are hex encoded. Otherwise they would be base64 encoded when
+// sent over JSON-RPC, which `eth_signTypedData_v4` does not accept.
+func typedDataJSON(typedData *gethsigner.TypedData) *gethsigner.TypedData {
+	return &gethsigner.TypedData{
+		Types:       typedData.Types,
+		PrimaryType: typedData.PrimaryType,
+		Domain:      typedData.Domain,
+		Message:     hexEncodeBytes(typedData.Message),
+	}
+}
+
+func hexEncodeBytes(message map[string]interface{}) map[string]interface{} {
+	encoded := make(map[string]interface{}, len(message))
+	for key, value := range message {
+		switch value := value.(type) {
+		case []byte:
+			encoded[key] = hexutil.Bytes(value)
+		case map[string]interface{}:
+			encoded[key] = hexEncodeBytes(value)
+		default:
+			encoded[key] = value
+		}
+	}
+	return encoded
+}
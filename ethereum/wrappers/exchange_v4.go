@@ -0,0 +1,155 @@
+package wrappers
+
+import (
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// NOTE: Unlike the other bindings in this package, the Exchange Proxy (V4)
+// binding is maintained by hand and only contains the read-only functions
+// needed for order validation.
+
+// ExchangeV4ABI is the input ABI used to bind the order state functions of the
+// Exchange Proxy (V4) contract.
+const ExchangeV4ABI = "[{\"inputs\":[{\"internalType\":\"struct LibNativeOrder.LimitOrder[]\",\"name\":\"orders\",\"type\":\"tuple[]\",\"components\":[{\"internalType\":\"contract IERC20TokenV06\",\"name\":\"makerToken\",\"type\":\"address\"},{\"internalType\":\"contract IERC20TokenV06\",\"name\":\"takerToken\",\"type\":\"address\"},{\"internalType\":\"uint128\",\"name\":\"makerAmount\",\"type\":\"uint128\"},{\"internalType\":\"uint128\",\"name\":\"takerAmount\",\"type\":\"uint128\"},{\"internalType\":\"uint128\",\"name\":\"takerTokenFeeAmount\",\"type\":\"uint128\"},{\"internalType\":\"address\",\"name\":\"maker\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"taker\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"sender\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"feeRecipient\",\"type\":\"address\"},{\"internalType\":\"bytes32\",\"name\":\"pool\",\"type\":\"bytes32\"},{\"internalType\":\"uint64\",\"name\":\"expiry\",\"type\":\"uint64\"},{\"internalType\":\"uint256\",\"name\":\"salt\",\"type\":\"uint256\"}]},{\"internalType\":\"struct LibSignature.Signature[]\",\"name\":\"signatures\",\"type\":\"tuple[]\",\"components\":[{\"internalType\":\"enum LibSignature.SignatureType\",\"name\":\"signatureType\",\"type\":\"uint8\"},{\"internalType\":\"uint8\",\"name\":\"v\",\"type\":\"uint8\"},{\"internalType\":\"bytes32\",\"name\":\"r\",\"type\":\"bytes32\"},{\"internalType\":\"bytes32\",\"name\":\"s\",\"type\":\"bytes32\"}]}],\"name\":\"batchGetLimitOrderRelevantStates\",\"outputs\":[{\"internalType\":\"struct LibNativeOrder.OrderInfo[]\",\"name\":\"orderInfos\",\"type\":\"tuple[]\",\"components\":[{\"internalType\":\"bytes32\",\"name\":\"orderHash\",\"type\":\"bytes32\"},{\"internalType\":\"enum LibNativeOrder.OrderStatus\",\"name\":\"status\",\"type\":\"uint8\"},{\"internalType\":\"uint128\",\"name\":\"takerTokenFilledAmount\",\"type\":\"uint128\"}]},{\"internalType\":\"uint128[]\",\"name\":\"actualFillableTakerTokenAmounts\",\"type\":\"uint128[]\"},{\"internalType\":\"bool[]\",\"name\":\"isSignatureValids\",\"type\":\"bool[]\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"struct LibNativeOrder.RfqOrder[]\",\"name\":\"orders\",\"type\":\"tuple[]\",\"components\":[{\"internalType\":\"contract IERC20TokenV06\",\"name\":\"makerToken\",\"type\":\"address\"},{\"internalType\":\"contract IERC20TokenV06\",\"name\":\"takerToken\",\"type\":\"address\"},{\"internalType\":\"uint128\",\"name\":\"makerAmount\",\"type\":\"uint128\"},{\"internalType\":\"uint128\",\"name\":\"takerAmount\",\"type\":\"uint128\"},{\"internalType\":\"address\",\"name\":\"maker\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"taker\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"txOrigin\",\"type\":\"address\"},{\"internalType\":\"bytes32\",\"name\":\"pool\",\"type\":\"bytes32\"},{\"internalType\":\"uint64\",\"name\":\"expiry\",\"type\":\"uint64\"},{\"internalType\":\"uint256\",\"name\":\"salt\",\"type\":\"uint256\"}]},{\"internalType\":\"struct LibSignature.Signature[]\",\"name\":\"signatures\",\"type\":\"tuple[]\",\"components\":[{\"internalType\":\"enum LibSignature.SignatureType\",\"name\":\"signatureType\",\"type\":\"uint8\"},{\"internalType\":\"uint8\",\"name\":\"v\",\"type\":\"uint8\"},{\"internalType\":\"bytes32\",\"name\":\"r\",\"type\":\"bytes32\"},{\"internalType\":\"bytes32\",\"name\":\"s\",\"type\":\"bytes32\"}]}],\"name\":\"batchGetRfqOrderRelevantStates\",\"outputs\":[{\"internalType\":\"struct LibNativeOrder.OrderInfo[]\",\"name\":\"orderInfos\",\"type\":\"tuple[]\",\"components\":[{\"internalType\":\"bytes32\",\"name\":\"orderHash\",\"type\":\"bytes32\"},{\"internalType\":\"enum LibNativeOrder.OrderStatus\",\"name\":\"status\",\"type\":\"uint8\"},{\"internalType\":\"uint128\",\"name\":\"takerTokenFilledAmount\",\"type\":\"uint128\"}]},{\"internalType\":\"uint128[]\",\"name\":\"actualFillableTakerTokenAmounts\",\"type\":\"uint128[]\"},{\"internalType\":\"bool[]\",\"name\":\"isSignatureValids\",\"type\":\"bool[]\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"struct LibNativeOrder.LimitOrder\",\"name\":\"order\",\"type\":\"tuple\",\"components\":[{\"internalType\":\"contract IERC20TokenV06\",\"name\":\"makerToken\",\"type\":\"address\"},{\"internalType\":\"contract IERC20TokenV06\",\"name\":\"takerToken\",\"type\":\"address\"},{\"internalType\":\"uint128\",\"name\":\"makerAmount\",\"type\":\"uint128\"},{\"internalType\":\"uint128\",\"name\":\"takerAmount\",\"type\":\"uint128\"},{\"internalType\":\"uint128\",\"name\":\"takerTokenFeeAmount\",\"type\":\"uint128\"},{\"internalType\":\"address\",\"name\":\"maker\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"taker\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"sender\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"feeRecipient\",\"type\":\"address\"},{\"internalType\":\"bytes32\",\"name\":\"pool\",\"type\":\"bytes32\"},{\"internalType\":\"uint64\",\"name\":\"expiry\",\"type\":\"uint64\"},{\"internalType\":\"uint256\",\"name\":\"salt\",\"type\":\"uint256\"}]},{\"internalType\":\"struct LibSignature.Signature\",\"name\":\"signature\",\"type\":\"tuple\",\"components\":[{\"internalType\":\"enum LibSignature.SignatureType\",\"name\":\"signatureType\",\"type\":\"uint8\"},{\"internalType\":\"uint8\",\"name\":\"v\",\"type\":\"uint8\"},{\"internalType\":\"bytes32\",\"name\":\"r\",\"type\":\"bytes32\"},{\"internalType\":\"bytes32\",\"name\":\"s\",\"type\":\"bytes32\"}]}],\"name\":\"getLimitOrderRelevantState\",\"outputs\":[{\"internalType\":\"struct LibNativeOrder.OrderInfo\",\"name\":\"orderInfo\",\"type\":\"tuple\",\"components\":[{\"internalType\":\"bytes32\",\"name\":\"orderHash\",\"type\":\"bytes32\"},{\"internalType\":\"enum LibNativeOrder.OrderStatus\",\"name\":\"status\",\"type\":\"uint8\"},{\"internalType\":\"uint128\",\"name\":\"takerTokenFilledAmount\",\"type\":\"uint128\"}]},{\"internalType\":\"uint128\",\"name\":\"actualFillableTakerTokenAmount\",\"type\":\"uint128\"},{\"internalType\":\"bool\",\"name\":\"isSignatureValid\",\"type\":\"bool\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"struct LibNativeOrder.RfqOrder\",\"name\":\"order\",\"type\":\"tuple\",\"components\":[{\"internalType\":\"contract IERC20TokenV06\",\"name\":\"makerToken\",\"type\":\"address\"},{\"internalType\":\"contract IERC20TokenV06\",\"name\":\"takerToken\",\"type\":\"address\"},{\"internalType\":\"uint128\",\"name\":\"makerAmount\",\"type\":\"uint128\"},{\"internalType\":\"uint128\",\"name\":\"takerAmount\",\"type\":\"uint128\"},{\"internalType\":\"address\",\"name\":\"maker\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"taker\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"txOrigin\",\"type\":\"address\"},{\"internalType\":\"bytes32\",\"name\":\"pool\",\"type\":\"bytes32\"},{\"internalType\":\"uint64\",\"name\":\"expiry\",\"type\":\"uint64\"},{\"internalType\":\"uint256\",\"name\":\"salt\",\"type\":\"uint256\"}]},{\"internalType\":\"struct LibSignature.Signature\",\"name\":\"signature\",\"type\":\"tuple\",\"components\":[{\"internalType\":\"enum LibSignature.SignatureType\",\"name\":\"signatureType\",\"type\":\"uint8\"},{\"internalType\":\"uint8\",\"name\":\"v\",\"type\":\"uint8\"},{\"internalType\":\"bytes32\",\"name\":\"r\",\"type\":\"bytes32\"},{\"internalType\":\"bytes32\",\"name\":\"s\",\"type\":\"bytes32\"}]}],\"name\":\"getRfqOrderRelevantState\",\"outputs\":[{\"internalType\":\"struct LibNativeOrder.OrderInfo\",\"name\":\"orderInfo\",\"type\":\"tuple\",\"components\":[{\"internalType\":\"bytes32\",\"name\":\"orderHash\",\"type\":\"bytes32\"},{\"internalType\":\"enum LibNativeOrder.OrderStatus\",\"name\":\"status\",\"type\":\"uint8\"},{\"internalType\":\"uint128\",\"name\":\"takerTokenFilledAmount\",\"type\":\"uint128\"}]},{\"internalType\":\"uint128\",\"name\":\"actualFillableTakerTokenAmount\",\"type\":\"uint128\"},{\"internalType\":\"bool\",\"name\":\"isSignatureValid\",\"type\":\"bool\"}],\"stateMutability\":\"view\",\"type\":\"function\"}]"
+
+// LimitOrderV4 is an Exchange V4 limit order representation expected by the
+// Exchange Proxy.
+type LimitOrderV4 struct {
+	MakerToken          common.Address
+	TakerToken          common.Address
+	MakerAmount         *big.Int
+	TakerAmount         *big.Int
+	TakerTokenFeeAmount *big.Int
+	Maker               common.Address
+	Taker               common.Address
+	Sender              common.Address
+	FeeRecipient        common.Address
+	Pool                [32]byte
+	Expiry              uint64
+	Salt                *big.Int
+}
+
+// RfqOrderV4 is an Exchange V4 RFQ order representation expected by the
+// Exchange Proxy.
+type RfqOrderV4 struct {
+	MakerToken  common.Address
+	TakerToken  common.Address
+	MakerAmount *big.Int
+	TakerAmount *big.Int
+	Maker       common.Address
+	Taker       common.Address
+	TxOrigin    common.Address
+	Pool        [32]byte
+	Expiry      uint64
+	Salt        *big.Int
+}
+
+// SignatureV4 is an Exchange V4 signature representation expected by the
+// Exchange Proxy.
+type SignatureV4 struct {
+	SignatureType uint8
+	V             uint8
+	R             [32]byte
+	S             [32]byte
+}
+
+// OrderInfoV4 contains the hash, status and filled amount of an Exchange V4
+// order.
+type OrderInfoV4 struct {
+	OrderHash              [32]byte
+	Status                 uint8
+	TakerTokenFilledAmount *big.Int
+}
+
+// ExchangeV4Caller is a read-only Go binding around the Exchange Proxy (V4)
+// contract.
+type ExchangeV4Caller struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// NewExchangeV4Caller creates a new read-only instance of the Exchange Proxy, bound to a specific deployed contract.
+func NewExchangeV4Caller(address common.Address, caller bind.ContractCaller) (*ExchangeV4Caller, error) {
+	parsed, err := abi.JSON(strings.NewReader(ExchangeV4ABI))
+	if err != nil {
+		return nil, err
+	}
+	contract := bind.NewBoundContract(address, parsed, caller, nil, nil)
+	return &ExchangeV4Caller{contract: contract}, nil
+}
+
+// GetLimitOrderRelevantState is a free data retrieval call binding the contract method getLimitOrderRelevantState.
+//
+// Solidity: function getLimitOrderRelevantState(LimitOrder order, Signature signature) view returns(OrderInfo orderInfo, uint128 actualFillableTakerTokenAmount, bool isSignatureValid)
+func (_ExchangeV4 *ExchangeV4Caller) GetLimitOrderRelevantState(opts *bind.CallOpts, order LimitOrderV4, signature SignatureV4) (struct {
+	OrderInfo                      OrderInfoV4
+	ActualFillableTakerTokenAmount *big.Int
+	IsSignatureValid               bool
+}, error) {
+	ret := new(struct {
+		OrderInfo                      OrderInfoV4
+		ActualFillableTakerTokenAmount *big.Int
+		IsSignatureValid               bool
+	})
+	out := ret
+	err := _ExchangeV4.contract.Call(opts, out, "getLimitOrderRelevantState", order, signature)
+	return *ret, err
+}
+
+// GetRfqOrderRelevantState is a free data retrieval call binding the contract method getRfqOrderRelevantState.
+//
+// Solidity: function getRfqOrderRelevantState(RfqOrder order, Signature signature) view returns(OrderInfo orderInfo, uint128 actualFillableTakerTokenAmount, bool isSignatureValid)
+func (_ExchangeV4 *ExchangeV4Caller) GetRfqOrderRelevantState(opts *bind.CallOpts, order RfqOrderV4, signature SignatureV4) (struct {
+	OrderInfo                      OrderInfoV4
+	ActualFillableTakerTokenAmount *big.Int
+	IsSignatureValid               bool
+}, error) {
+	ret := new(struct {
+		OrderInfo                      OrderInfoV4
+		ActualFillableTakerTokenAmount *big.Int
+		IsSignatureValid               bool
+	})
+	out := ret
+	err := _ExchangeV4.contract.Call(opts, out, "getRfqOrderRelevantState", order, signature)
+	return *ret, err
+}
+
+// BatchGetLimitOrderRelevantStates is a free data retrieval call binding the contract method batchGetLimitOrderRelevantStates.
+//
+// Solidity: function batchGetLimitOrderRelevantStates(LimitOrder[] orders, Signature[] signatures) view returns(OrderInfo[] orderInfos, uint128[] actualFillableTakerTokenAmounts, bool[] isSignatureValids)
+func (_ExchangeV4 *ExchangeV4Caller) BatchGetLimitOrderRelevantStates(opts *bind.CallOpts, orders []LimitOrderV4, signatures []SignatureV4) (struct {
+	OrderInfos                      []OrderInfoV4
+	ActualFillableTakerTokenAmounts []*big.Int
+	IsSignatureValids               []bool
+}, error) {
+	ret := new(struct {
+		OrderInfos                      []OrderInfoV4
+		ActualFillableTakerTokenAmounts []*big.Int
+		IsSignatureValids               []bool
+	})
+	out := ret
+	err := _ExchangeV4.contract.Call(opts, out, "batchGetLimitOrderRelevantStates", orders, signatures)
+	return *ret, err
+}
+
+// BatchGetRfqOrderRelevantStates is a free data retrieval call binding the contract method batchGetRfqOrderRelevantStates.
+//
+// Solidity: function batchGetRfqOrderRelevantStates(RfqOrder[] orders, Signature[] signatures) view returns(OrderInfo[] orderInfos, uint128[] actualFillableTakerTokenAmounts, bool[] isSignatureValids)
+func (_ExchangeV4 *ExchangeV4Caller) BatchGetRfqOrderRelevantStates(opts *bind.CallOpts, orders []RfqOrderV4, signatures []SignatureV4) (struct {
+	OrderInfos                      []OrderInfoV4
+	ActualFillableTakerTokenAmounts []*big.Int
+	IsSignatureValids               []bool
+}, error) {
+	ret := new(struct {
+		OrderInfos                      []OrderInfoV4
+		ActualFillableTakerTokenAmounts []*big.Int
+		IsSignatureValids               []bool
+	})
+	out := ret
+	err := _ExchangeV4.contract.Call(opts, out, "batchGetRfqOrderRelevantStates", orders, signatures)
+	return *ret, err
+}
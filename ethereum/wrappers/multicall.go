@@ -0,0 +1,60 @@
+package wrappers
+
+import (
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// NOTE: Like the Exchange Proxy (V4) binding, the Multicall binding is
+// maintained by hand and only contains the functions needed to batch read-only
+// calls.
+
+// MulticallABI is the input ABI used to bind the tryAggregate function of the
+// Multicall2 contract.
+const MulticallABI = "[{\"inputs\":[{\"internalType\":\"bool\",\"name\":\"requireSuccess\",\"type\":\"bool\"},{\"components\":[{\"internalType\":\"address\",\"name\":\"target\",\"type\":\"address\"},{\"internalType\":\"bytes\",\"name\":\"callData\",\"type\":\"bytes\"}],\"internalType\":\"struct Multicall2.Call[]\",\"name\":\"calls\",\"type\":\"tuple[]\"}],\"name\":\"tryAggregate\",\"outputs\":[{\"components\":[{\"internalType\":\"bool\",\"name\":\"success\",\"type\":\"bool\"},{\"internalType\":\"bytes\",\"name\":\"returnData\",\"type\":\"bytes\"}],\"internalType\":\"struct Multicall2.Result[]\",\"name\":\"returnData\",\"type\":\"tuple[]\"}],\"stateMutability\":\"nonpayable\",\"type\":\"function\"}]"
+
+// MulticallCall is a single call which is executed by the Multicall contract.
+type MulticallCall struct {
+	Target   common.Address
+	CallData []byte
+}
+
+// MulticallResult is the result of a single call executed by the Multicall
+// contract. If the call reverted, Success is false and ReturnData contains the
+// revert reason.
+type MulticallResult struct {
+	Success    bool
+	ReturnData []byte
+}
+
+// MulticallCaller is a read-only Go binding around the Multicall2 contract.
+type MulticallCaller struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// NewMulticallCaller creates a new read-only instance of Multicall, bound to a specific deployed contract.
+func NewMulticallCaller(address common.Address, caller bind.ContractCaller) (*MulticallCaller, error) {
+	parsed, err := abi.JSON(strings.NewReader(MulticallABI))
+	if err != nil {
+		return nil, err
+	}
+	contract := bind.NewBoundContract(address, parsed, caller, nil, nil)
+	return &MulticallCaller{contract: contract}, nil
+}
+
+// TryAggregate is a binding for the contract method tryAggregate. Although the
+// method is not marked as view, it does not modify any state and is only ever
+// executed with eth_call.
+//
+// Solidity: function tryAggregate(bool requireSuccess, Call[] calls) returns(Result[] returnData)
+func (_Multicall *MulticallCaller) TryAggregate(opts *bind.CallOpts, requireSuccess bool, calls []MulticallCall) ([]MulticallResult, error) {
+	var (
+		ret0 = new([]MulticallResult)
+	)
+	out := ret0
+	err := _Multicall.contract.Call(opts, out, "tryAggregate", requireSuccess, calls)
+	return *ret0, err
+}
@@ -64,7 +64,7 @@ func main() {
 	}
 
 	signer := signer.NewEthRPCSigner(ethClient)
-	signedTestOrder, err := zeroex.SignOrder(signer, testOrder)
+	signedTestOrder, err := zeroex.SignOrder(signer, testOrder, zeroex.EthSignSignature)
 	if err != nil {
 		log.WithError(err).Fatal("could not sign 0x order")
 	}
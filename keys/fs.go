@@ -5,6 +5,7 @@ package keys
 import (
 	"io/ioutil"
 	"os"
+	"path/filepath"
 )
 
 func readFile(path string) ([]byte, error) {
@@ -15,6 +16,30 @@ func mkdirAll(dir string) error {
 	return os.MkdirAll(dir, os.ModePerm)
 }
 
+// writeFile writes the data to a temporary file in the same directory, which
+// only the current user can access, and then renames it to the given path. An
+// existing file is replaced atomically, so a failed write never leaves a
+// truncated key behind.
 func writeFile(path string, data []byte) error {
-	return ioutil.WriteFile(path, data, os.ModePerm)
+	file, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	tmpPath := file.Name()
+	defer func() {
+		// The temporary file no longer exists if it was renamed.
+		_ = os.Remove(tmpPath)
+	}()
+	if _, err := file.Write(data); err != nil {
+		_ = file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		_ = file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
 }
@@ -0,0 +1,55 @@
+// +build !js
+
+package keys
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSaveEncryptedPrivateKeyReplacesKey(t *testing.T) {
+	dir := "/tmp/keys/" + uuid.New().String()
+	path := filepath.Join(dir, "privkey")
+	_, err := GenerateAndSavePrivateKey(path)
+	require.NoError(t, err)
+	newKey, err := GenerateAndSavePrivateKey(filepath.Join(dir, "newkey"))
+	require.NoError(t, err)
+
+	require.NoError(t, SaveEncryptedPrivateKey(path, newKey, "passphrase", LightScryptN, LightScryptP))
+	gotKey, err := GetEncryptedPrivateKeyFromPath(path, "passphrase")
+	require.NoError(t, err)
+	assert.Equal(t, newKey, gotKey)
+
+	// The key is only accessible by the current user and no temporary files
+	// are left behind.
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	files, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, files, 2)
+}
+
+func TestReadPassphrase(t *testing.T) {
+	passphraseFile := "/tmp/keys/" + uuid.New().String()
+	require.NoError(t, os.MkdirAll(filepath.Dir(passphraseFile), os.ModePerm))
+	require.NoError(t, ioutil.WriteFile(passphraseFile, []byte("from file\n"), 0600))
+
+	passphrase, err := ReadPassphrase("given", passphraseFile)
+	require.NoError(t, err)
+	assert.Equal(t, "given", passphrase)
+	passphrase, err = ReadPassphrase("", passphraseFile)
+	require.NoError(t, err)
+	assert.Equal(t, "from file", passphrase)
+	passphrase, err = ReadPassphrase("", "")
+	require.NoError(t, err)
+	assert.Equal(t, "", passphrase)
+	_, err = ReadPassphrase("", passphraseFile+"-missing")
+	assert.Error(t, err)
+}
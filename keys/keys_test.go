@@ -24,3 +24,31 @@ func TestGetKeyNotExists(t *testing.T) {
 	assert.Error(t, err)
 	assert.True(t, os.IsNotExist(err), "error should be a NotExist error, but got: (%T) %s", err, err)
 }
+
+func TestEncryptAndDecryptKey(t *testing.T) {
+	path := "/tmp/keys/" + uuid.New().String()
+	generatedKey, err := GenerateAndSaveEncryptedPrivateKey(path, "passphrase", LightScryptN, LightScryptP)
+	require.NoError(t, err)
+	gotKey, err := GetEncryptedPrivateKeyFromPath(path, "passphrase")
+	require.NoError(t, err)
+	assert.Equal(t, generatedKey, gotKey)
+}
+
+func TestDecryptKeyWrongPassphrase(t *testing.T) {
+	path := "/tmp/keys/" + uuid.New().String()
+	_, err := GenerateAndSaveEncryptedPrivateKey(path, "passphrase", LightScryptN, LightScryptP)
+	require.NoError(t, err)
+	_, err = GetEncryptedPrivateKeyFromPath(path, "wrong passphrase")
+	assert.Equal(t, ErrDecrypt, err)
+	_, err = GetEncryptedPrivateKeyFromPath(path, "")
+	assert.Equal(t, ErrPassphraseRequired, err)
+}
+
+func TestGetUnencryptedKeyWithPassphrase(t *testing.T) {
+	path := "/tmp/keys/" + uuid.New().String()
+	generatedKey, err := GenerateAndSavePrivateKey(path)
+	require.NoError(t, err)
+	gotKey, err := GetEncryptedPrivateKeyFromPath(path, "passphrase")
+	require.NoError(t, err)
+	assert.Equal(t, generatedKey, gotKey)
+}
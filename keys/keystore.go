@@ -0,0 +1,265 @@
+package keys
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	p2pcrypto "github.com/libp2p/go-libp2p-core/crypto"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	// StandardScryptN and StandardScryptP are the scrypt parameters used by
+	// Ethereum keystores. Decrypting a key takes about one second and 256 MB of
+	// memory.
+	StandardScryptN = 1 << 18
+	StandardScryptP = 1
+	// LightScryptN and LightScryptP are scrypt parameters which use less memory
+	// and CPU time (about 4 MB and 100 ms) at the cost of being easier to brute
+	// force. They are suitable for browsers and testing.
+	LightScryptN = 1 << 12
+	LightScryptP = 6
+
+	keystoreVersion = 3
+	keystoreType    = "libp2p-private-key"
+	scryptR         = 8
+	scryptDKLen     = 32
+	cipherName      = "aes-128-ctr"
+	kdfName         = "scrypt"
+)
+
+var (
+	// ErrDecrypt is returned if an encrypted key could not be decrypted with
+	// the given passphrase.
+	ErrDecrypt = errors.New("could not decrypt key with given passphrase")
+	// ErrPassphraseRequired is returned when loading an encrypted key without a
+	// passphrase.
+	ErrPassphraseRequired = errors.New("private key is encrypted but no passphrase was given")
+)
+
+// encryptedKeyJSON is the format of encrypted key files. It follows the
+// Ethereum keystore v3 format, but holds a marshaled libp2p private key
+// instead of an Ethereum account.
+type encryptedKeyJSON struct {
+	Type    string     `json:"type"`
+	PeerID  string     `json:"peerID"`
+	Crypto  cryptoJSON `json:"crypto"`
+	Version int        `json:"version"`
+}
+
+type cryptoJSON struct {
+	Cipher       string           `json:"cipher"`
+	CipherText   string           `json:"ciphertext"`
+	CipherParams cipherParamsJSON `json:"cipherparams"`
+	KDF          string           `json:"kdf"`
+	KDFParams    kdfParamsJSON    `json:"kdfparams"`
+	MAC          string           `json:"mac"`
+}
+
+type cipherParamsJSON struct {
+	IV string `json:"iv"`
+}
+
+type kdfParamsJSON struct {
+	DKLen int    `json:"dklen"`
+	N     int    `json:"n"`
+	P     int    `json:"p"`
+	R     int    `json:"r"`
+	Salt  string `json:"salt"`
+}
+
+// IsEncrypted returns true if the given key file contents hold an encrypted
+// key.
+func IsEncrypted(data []byte) bool {
+	return bytes.HasPrefix(bytes.TrimSpace(data), []byte("{"))
+}
+
+// IsEncryptedFile returns true if the key file at the given path holds an
+// encrypted key.
+func IsEncryptedFile(path string) (bool, error) {
+	data, err := readFile(path)
+	if err != nil {
+		return false, err
+	}
+	return IsEncrypted(data), nil
+}
+
+// EncryptPrivateKey encrypts the private key with the given passphrase and
+// returns the contents of an encrypted key file.
+func EncryptPrivateKey(privKey p2pcrypto.PrivKey, passphrase string, scryptN, scryptP int) ([]byte, error) {
+	keyBytes, err := p2pcrypto.MarshalPrivateKey(privKey)
+	if err != nil {
+		return nil, err
+	}
+	peerID, err := peer.IDFromPrivateKey(privKey)
+	if err != nil {
+		return nil, err
+	}
+	salt := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, err
+	}
+	derivedKey, err := scrypt.Key([]byte(passphrase), salt, scryptN, scryptR, scryptP, scryptDKLen)
+	if err != nil {
+		return nil, err
+	}
+	iv := make([]byte, aes.BlockSize)
+	if _, err := io.ReadFull(rand.Reader, iv); err != nil {
+		return nil, err
+	}
+	cipherText, err := aesCTRXOR(derivedKey[:16], keyBytes, iv)
+	if err != nil {
+		return nil, err
+	}
+	mac := crypto.Keccak256(derivedKey[16:32], cipherText)
+	return json.MarshalIndent(encryptedKeyJSON{
+		Type:   keystoreType,
+		PeerID: peerID.Pretty(),
+		Crypto: cryptoJSON{
+			Cipher:     cipherName,
+			CipherText: hex.EncodeToString(cipherText),
+			CipherParams: cipherParamsJSON{
+				IV: hex.EncodeToString(iv),
+			},
+			KDF: kdfName,
+			KDFParams: kdfParamsJSON{
+				DKLen: scryptDKLen,
+				N:     scryptN,
+				P:     scryptP,
+				R:     scryptR,
+				Salt:  hex.EncodeToString(salt),
+			},
+			MAC: hex.EncodeToString(mac),
+		},
+		Version: keystoreVersion,
+	}, "", "  ")
+}
+
+// DecryptPrivateKey decrypts the contents of an encrypted key file with the
+// given passphrase.
+func DecryptPrivateKey(data []byte, passphrase string) (p2pcrypto.PrivKey, error) {
+	var encryptedKey encryptedKeyJSON
+	if err := json.Unmarshal(data, &encryptedKey); err != nil {
+		return nil, fmt.Errorf("invalid encrypted key: %s", err.Error())
+	}
+	if encryptedKey.Version != keystoreVersion {
+		return nil, fmt.Errorf("unsupported encrypted key version: %d", encryptedKey.Version)
+	}
+	params := encryptedKey.Crypto
+	if params.Cipher != cipherName {
+		return nil, fmt.Errorf("unsupported cipher: %s", params.Cipher)
+	}
+	if params.KDF != kdfName {
+		return nil, fmt.Errorf("unsupported key derivation function: %s", params.KDF)
+	}
+	salt, err := hex.DecodeString(params.KDFParams.Salt)
+	if err != nil {
+		return nil, err
+	}
+	iv, err := hex.DecodeString(params.CipherParams.IV)
+	if err != nil {
+		return nil, err
+	}
+	cipherText, err := hex.DecodeString(params.CipherText)
+	if err != nil {
+		return nil, err
+	}
+	mac, err := hex.DecodeString(params.MAC)
+	if err != nil {
+		return nil, err
+	}
+	if params.KDFParams.DKLen != scryptDKLen {
+		return nil, fmt.Errorf("unsupported derived key length: %d", params.KDFParams.DKLen)
+	}
+	derivedKey, err := scrypt.Key([]byte(passphrase), salt, params.KDFParams.N, params.KDFParams.R, params.KDFParams.P, params.KDFParams.DKLen)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(crypto.Keccak256(derivedKey[16:32], cipherText), mac) {
+		return nil, ErrDecrypt
+	}
+	keyBytes, err := aesCTRXOR(derivedKey[:16], cipherText, iv)
+	if err != nil {
+		return nil, err
+	}
+	return p2pcrypto.UnmarshalPrivateKey(keyBytes)
+}
+
+// GetEncryptedPrivateKeyFromPath reads the key file at the given path. If the
+// key is encrypted, it is decrypted with the given passphrase. Unencrypted keys
+// are returned as is, so that existing key files keep working.
+func GetEncryptedPrivateKeyFromPath(path string, passphrase string) (p2pcrypto.PrivKey, error) {
+	data, err := readFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if !IsEncrypted(data) {
+		return GetPrivateKeyFromPath(path)
+	}
+	if passphrase == "" {
+		return nil, ErrPassphraseRequired
+	}
+	return DecryptPrivateKey(data, passphrase)
+}
+
+// ReadPassphrase returns the given passphrase or, if it is empty, the contents
+// of the given passphrase file without the trailing newline.
+func ReadPassphrase(passphrase string, passphraseFile string) (string, error) {
+	if passphrase != "" || passphraseFile == "" {
+		return passphrase, nil
+	}
+	data, err := ioutil.ReadFile(passphraseFile)
+	if err != nil {
+		return "", fmt.Errorf("could not read passphrase file: %s", err.Error())
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// SaveEncryptedPrivateKey encrypts the private key with the given passphrase
+// and writes it to the given path, replacing any existing file.
+func SaveEncryptedPrivateKey(path string, privKey p2pcrypto.PrivKey, passphrase string, scryptN, scryptP int) error {
+	if err := mkdirAll(filepath.Dir(path)); err != nil {
+		return err
+	}
+	data, err := EncryptPrivateKey(privKey, passphrase, scryptN, scryptP)
+	if err != nil {
+		return err
+	}
+	return writeFile(path, data)
+}
+
+// GenerateAndSaveEncryptedPrivateKey generates a new private key, encrypts it
+// with the given passphrase and writes it to the given path.
+func GenerateAndSaveEncryptedPrivateKey(path string, passphrase string, scryptN, scryptP int) (p2pcrypto.PrivKey, error) {
+	privKey, _, err := p2pcrypto.GenerateSecp256k1Key(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	if err := SaveEncryptedPrivateKey(path, privKey, passphrase, scryptN, scryptP); err != nil {
+		return nil, err
+	}
+	return privKey, nil
+}
+
+func aesCTRXOR(key, input, iv []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	stream := cipher.NewCTR(block, iv)
+	output := make([]byte, len(input))
+	stream.XORKeyStream(output, input)
+	return output, nil
+}
@@ -31,6 +31,10 @@ type Order struct {
 	SignedOrder *zeroex.SignedOrder
 	// When was this order last validated
 	LastUpdated time.Time
+	// When was this order added to the database. For orders which were added
+	// before this field existed, it is set to LastUpdated by the backfillAddedAt
+	// migration.
+	AddedAt time.Time
 	// How much of this order can still be filled
 	FillableTakerAssetAmount *big.Int
 	// Was this order flagged for removal? Due to the possibility of block-reorgs, instead
@@ -41,6 +45,9 @@ type Order struct {
 	// IsPinned indicates whether or not the order is pinned. Pinned orders are
 	// not removed from the database unless they become unfillable.
 	IsPinned bool
+	// ReceivedFrom is the ID of the peer which sent us this order. It is empty
+	// for orders which were added locally (e.g. via the JSON-RPC API).
+	ReceivedFrom string
 }
 
 // ID returns the Order's ID
@@ -67,6 +74,10 @@ type MeshDB struct {
 	metadata                 *MetadataCollection
 	MiniHeaders              *MiniHeadersCollection
 	Orders                   *OrdersCollection
+	OrdersV4                 *OrdersV4Collection
+	OrdersyncCheckpoints     *OrdersyncCheckpointsCollection
+	PeerReputations          *PeerReputationsCollection
+	migrations               *db.Collection
 	MiniHeaderRetentionLimit int
 }
 
@@ -85,6 +96,9 @@ type OrdersCollection struct {
 	LastUpdatedIndex                             *db.Index
 	IsRemovedIndex                               *db.Index
 	ExpirationTimeIndex                          *db.Index
+	UnpinnedMakerAddressIndex                    *db.Index
+	UnpinnedReceivedFromIndex                    *db.Index
+	AssetPairIndex                               *db.Index
 }
 
 // MetadataCollection represents a DB collection used to store instance metadata
@@ -94,6 +108,17 @@ type MetadataCollection struct {
 
 // New instantiates a new MeshDB instance
 func New(path string, contractAddresses ethereum.ContractAddresses) (*MeshDB, error) {
+	return NewWithAssetProxies(path, contractAddresses, nil)
+}
+
+// NewWithAssetProxies instantiates a new MeshDB instance which also indexes
+// orders by the tokens in asset data belonging to the given custom asset
+// proxies.
+func NewWithAssetProxies(path string, contractAddresses ethereum.ContractAddresses, assetProxies []zeroex.AssetProxy) (*MeshDB, error) {
+	assetDataDecoder, err := zeroex.NewAssetDataDecoderWithAssetProxies(assetProxies)
+	if err != nil {
+		return nil, err
+	}
 	database, err := db.Open(path)
 	if err != nil {
 		return nil, err
@@ -104,7 +129,22 @@ func New(path string, contractAddresses ethereum.ContractAddresses) (*MeshDB, er
 		return nil, err
 	}
 
-	orders, err := setupOrders(database, contractAddresses)
+	orders, err := setupOrders(database, contractAddresses, assetDataDecoder)
+	if err != nil {
+		return nil, err
+	}
+
+	ordersV4, err := setupOrdersV4(database)
+	if err != nil {
+		return nil, err
+	}
+
+	ordersyncCheckpoints, err := setupOrdersyncCheckpoints(database)
+	if err != nil {
+		return nil, err
+	}
+
+	peerReputations, err := setupPeerReputations(database)
 	if err != nil {
 		return nil, err
 	}
@@ -114,16 +154,29 @@ func New(path string, contractAddresses ethereum.ContractAddresses) (*MeshDB, er
 		return nil, err
 	}
 
-	return &MeshDB{
+	migrations, err := setupMigrations(database)
+	if err != nil {
+		return nil, err
+	}
+
+	meshDB := &MeshDB{
 		database:                 database,
 		metadata:                 metadata,
 		MiniHeaders:              miniHeaders,
 		Orders:                   orders,
+		OrdersV4:                 ordersV4,
+		OrdersyncCheckpoints:     ordersyncCheckpoints,
+		PeerReputations:          peerReputations,
+		migrations:               migrations,
 		MiniHeaderRetentionLimit: defaultMiniHeaderRetentionLimit,
-	}, nil
+	}
+	if err := meshDB.applyMigrations(); err != nil {
+		return nil, err
+	}
+	return meshDB, nil
 }
 
-func setupOrders(database *db.DB, contractAddresses ethereum.ContractAddresses) (*OrdersCollection, error) {
+func setupOrders(database *db.DB, contractAddresses ethereum.ContractAddresses, assetDataDecoder *zeroex.AssetDataDecoder) (*OrdersCollection, error) {
 	col, err := database.NewCollection("order", &Order{})
 	if err != nil {
 		return nil, err
@@ -146,7 +199,7 @@ func setupOrders(database *db.DB, contractAddresses ethereum.ContractAddresses)
 	// here is compute time for storage space.
 	makerAddressTokenAddressTokenIDIndex := col.AddMultiIndex("makerAddressTokenAddressTokenId", func(m db.Model) [][]byte {
 		order := m.(*Order)
-		singleAssetDatas, err := parseContractAddressesAndTokenIdsFromAssetData(order.SignedOrder.MakerAssetData, contractAddresses)
+		singleAssetDatas, err := parseContractAddressesAndTokenIdsFromAssetData(assetDataDecoder, order.SignedOrder.MakerAssetData, contractAddresses)
 		if err != nil {
 			log.WithFields(log.Fields{
 				"error": err.Error(),
@@ -172,7 +225,7 @@ func setupOrders(database *db.DB, contractAddresses ethereum.ContractAddresses)
 				[]byte(order.SignedOrder.MakerAddress.Hex() + "|" + common.ToHex(constants.NullBytes) + "|"),
 			}
 		}
-		singleAssetDatas, err := parseContractAddressesAndTokenIdsFromAssetData(order.SignedOrder.MakerFeeAssetData, contractAddresses)
+		singleAssetDatas, err := parseContractAddressesAndTokenIdsFromAssetData(assetDataDecoder, order.SignedOrder.MakerFeeAssetData, contractAddresses)
 		if err != nil {
 			log.WithFields(log.Fields{
 				"error": err.Error(),
@@ -211,6 +264,38 @@ func setupOrders(database *db.DB, contractAddresses ethereum.ContractAddresses)
 		return []byte(fmt.Sprintf("%s|%s", pinnedString, expTimeString))
 	})
 
+	// The following indexes are used to enforce per-maker and per-peer quotas.
+	// Only unpinned orders which are not flagged for removal count towards the
+	// quotas, so other orders are not indexed at all. Orders which were stored
+	// before these indexes existed are added by the
+	// backfillUnpinnedOrderIndexes migration.
+	unpinnedMakerAddressIndex := col.AddMultiIndex("unpinnedMakerAddress", func(m db.Model) [][]byte {
+		order := m.(*Order)
+		if order.IsPinned || order.IsRemoved {
+			return nil
+		}
+		return [][]byte{[]byte(order.SignedOrder.MakerAddress.Hex())}
+	})
+	unpinnedReceivedFromIndex := col.AddMultiIndex("unpinnedReceivedFrom", func(m db.Model) [][]byte {
+		order := m.(*Order)
+		if order.IsPinned || order.IsRemoved || order.ReceivedFrom == "" {
+			return nil
+		}
+		return [][]byte{[]byte(order.ReceivedFrom)}
+	})
+
+	// assetPairIndex is used to load the orderbook for an asset pair. Orders
+	// which are flagged for removal are not part of any orderbook, so they are
+	// not indexed. Orders which were stored before this index existed are
+	// added by the backfillAssetPairIndex migration.
+	assetPairIndex := col.AddMultiIndex("assetPair", func(m db.Model) [][]byte {
+		order := m.(*Order)
+		if order.IsRemoved {
+			return nil
+		}
+		return [][]byte{assetPairIndexValue(order.SignedOrder.MakerAssetData, order.SignedOrder.TakerAssetData)}
+	})
+
 	return &OrdersCollection{
 		Collection:                                   col,
 		MakerAddressTokenAddressTokenIDIndex:         makerAddressTokenAddressTokenIDIndex,
@@ -219,9 +304,16 @@ func setupOrders(database *db.DB, contractAddresses ethereum.ContractAddresses)
 		LastUpdatedIndex:                             lastUpdatedIndex,
 		IsRemovedIndex:                               isRemovedIndex,
 		ExpirationTimeIndex:                          expirationTimeIndex,
+		UnpinnedMakerAddressIndex:                    unpinnedMakerAddressIndex,
+		UnpinnedReceivedFromIndex:                    unpinnedReceivedFromIndex,
+		AssetPairIndex:                               assetPairIndex,
 	}, nil
 }
 
+func assetPairIndexValue(makerAssetData, takerAssetData []byte) []byte {
+	return []byte(common.ToHex(makerAssetData) + "|" + common.ToHex(takerAssetData))
+}
+
 func setupMiniHeaders(database *db.DB) (*MiniHeadersCollection, error) {
 	col, err := database.NewCollection("miniHeader", &miniheader.MiniHeader{})
 	if err != nil {
@@ -403,6 +495,17 @@ func (m *MeshDB) FindOrdersByMakerAddress(makerAddress common.Address) ([]*Order
 	return orders, nil
 }
 
+// FindOrdersByAssetPair finds all orders which are not flagged for removal
+// with the given maker asset data and taker asset data
+func (m *MeshDB) FindOrdersByAssetPair(makerAssetData, takerAssetData []byte) ([]*Order, error) {
+	filter := m.Orders.AssetPairIndex.ValueFilter(assetPairIndexValue(makerAssetData, takerAssetData))
+	orders := []*Order{}
+	if err := m.Orders.NewQuery(filter).Run(&orders); err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
 // FindOrdersByMakerAddressTokenAddressAndTokenID finds all orders belonging to a particular maker
 // address where makerAssetData encodes for a particular token contract and optionally a token ID
 func (m *MeshDB) FindOrdersByMakerAddressTokenAddressAndTokenID(makerAddress, tokenAddress common.Address, tokenID *big.Int) ([]*Order, error) {
@@ -525,9 +628,8 @@ type singleAssetData struct {
 	TokenID *big.Int
 }
 
-func parseContractAddressesAndTokenIdsFromAssetData(assetData []byte, contractAddresses ethereum.ContractAddresses) ([]singleAssetData, error) {
+func parseContractAddressesAndTokenIdsFromAssetData(assetDataDecoder *zeroex.AssetDataDecoder, assetData []byte, contractAddresses ethereum.ContractAddresses) ([]singleAssetData, error) {
 	singleAssetDatas := []singleAssetData{}
-	assetDataDecoder := zeroex.NewAssetDataDecoder()
 
 	assetDataName, err := assetDataDecoder.GetName(assetData)
 	if err != nil {
@@ -584,7 +686,7 @@ func parseContractAddressesAndTokenIdsFromAssetData(assetData []byte, contractAd
 			return nil, err
 		}
 		for _, assetData := range decodedAssetData.NestedAssetData {
-			as, err := parseContractAddressesAndTokenIdsFromAssetData(assetData, contractAddresses)
+			as, err := parseContractAddressesAndTokenIdsFromAssetData(assetDataDecoder, assetData, contractAddresses)
 			if err != nil {
 				return nil, err
 			}
@@ -673,9 +775,118 @@ func (m *MeshDB) TrimOrdersByExpirationTime(targetMaxOrders int) (newMaxExpirati
 	return newMaxExpirationTime, removedOrders, nil
 }
 
+// OrderSelector selects which of the given unpinned orders should be removed
+// to make space for new orders. It must return exactly numOrdersToRemove
+// orders.
+type OrderSelector func(unpinnedOrders []*Order, numOrdersToRemove int) ([]*Order, error)
+
+// TrimOrders removes existing orders until the number of remaining orders is
+// <= targetMaxOrders. Unlike TrimOrdersByExpirationTime, the orders which are
+// removed are chosen by selectOrders out of all unpinned orders. It returns the
+// orders that were removed.
+//
+// Note that every call loads all unpinned orders into memory and passes them to
+// selectOrders, which is considerably more expensive than
+// TrimOrdersByExpirationTime when there are many orders. Callers should remove
+// more orders than strictly necessary so that trimming is infrequent.
+func (m *MeshDB) TrimOrders(targetMaxOrders int, selectOrders OrderSelector) (removedOrders []*Order, err error) {
+	txn := m.Orders.OpenTransaction()
+	defer func() {
+		_ = txn.Discard()
+	}()
+
+	numOrders, err := m.Orders.Count()
+	if err != nil {
+		return nil, err
+	}
+	if numOrders <= targetMaxOrders {
+		return nil, nil
+	}
+
+	// We use a prefix filter of "0|" so that we only consider non-pinned orders.
+	filter := m.Orders.ExpirationTimeIndex.PrefixFilter([]byte("0|"))
+	unpinnedOrders := []*Order{}
+	if err := m.Orders.NewQuery(filter).Run(&unpinnedOrders); err != nil {
+		return nil, err
+	}
+	numOrdersToRemove := numOrders - targetMaxOrders
+	numOrdersToSelect := numOrdersToRemove
+	if len(unpinnedOrders) < numOrdersToSelect {
+		numOrdersToSelect = len(unpinnedOrders)
+	}
+	removedOrders, err = selectOrders(unpinnedOrders, numOrdersToSelect)
+	if err != nil {
+		return nil, err
+	}
+	if len(removedOrders) != numOrdersToSelect {
+		return nil, fmt.Errorf("expected %d orders to be selected for removal but got %d", numOrdersToSelect, len(removedOrders))
+	}
+
+	// Remove the selected orders and commit the transaction.
+	for _, order := range removedOrders {
+		if err := txn.Delete(order.Hash.Bytes()); err != nil {
+			return nil, err
+		}
+	}
+	if err := txn.Commit(); err != nil {
+		return nil, err
+	}
+
+	// If there were not enough unpinned orders it means the database is full of
+	// pinned orders. We still remove as many orders as we can and then return
+	// an error.
+	if len(unpinnedOrders) < numOrdersToRemove {
+		return nil, ErrDBFilledWithPinnedOrders
+	}
+	return removedOrders, nil
+}
+
+// FindOrderHashes returns the hashes of all orders, including orders which have
+// been flagged for removal. It only reads the expiration time index, so it is
+// much cheaper than loading all orders.
+func (m *MeshDB) FindOrderHashes() ([]common.Hash, error) {
+	ids, err := m.Orders.NewQuery(m.Orders.ExpirationTimeIndex.All()).IDs()
+	if err != nil {
+		return nil, err
+	}
+	hashes := make([]common.Hash, len(ids))
+	for i, id := range ids {
+		hashes[i] = common.BytesToHash(id)
+	}
+	return hashes, nil
+}
+
 // CountPinnedOrders returns the number of pinned orders.
 func (m *MeshDB) CountPinnedOrders() (int, error) {
 	// We use a prefix filter of "1|" so that we only count pinned orders.
 	filter := m.Orders.ExpirationTimeIndex.PrefixFilter([]byte("1|"))
 	return m.Orders.NewQuery(filter).Count()
 }
+
+// CountUnpinnedOrdersByMakerAddress returns the number of unpinned orders with
+// the given maker address which are not flagged for removal.
+func (m *MeshDB) CountUnpinnedOrdersByMakerAddress(makerAddress common.Address) (int, error) {
+	filter := m.Orders.UnpinnedMakerAddressIndex.ValueFilter([]byte(makerAddress.Hex()))
+	return m.Orders.NewQuery(filter).Count()
+}
+
+// CountUnpinnedOrdersReceivedFrom returns the number of unpinned orders which
+// were received from the given peer and are not flagged for removal.
+func (m *MeshDB) CountUnpinnedOrdersReceivedFrom(peerID string) (int, error) {
+	filter := m.Orders.UnpinnedReceivedFromIndex.ValueFilter([]byte(peerID))
+	return m.Orders.NewQuery(filter).Count()
+}
+
+// CountUnpinnedOrdersPerMakerAddress returns the number of unpinned orders
+// which are not flagged for removal for each maker address.
+func (m *MeshDB) CountUnpinnedOrdersPerMakerAddress() (map[common.Address]int, error) {
+	counts, err := m.Orders.NewQuery(m.Orders.UnpinnedMakerAddressIndex.All()).CountByValue()
+	if err != nil {
+		return nil, err
+	}
+	makerAddressToCount := make(map[common.Address]int, len(counts))
+	for makerAddress, count := range counts {
+		makerAddressToCount[common.HexToAddress(makerAddress)] = count
+	}
+	return makerAddressToCount, nil
+}
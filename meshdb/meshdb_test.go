@@ -99,9 +99,11 @@ func TestOrderCRUDOperations(t *testing.T) {
 }
 
 func TestParseContractAddressesAndTokenIdsFromAssetData(t *testing.T) {
+	assetDataDecoder := zeroex.NewAssetDataDecoder()
+
 	// ERC20 AssetData
 	erc20AssetData := common.Hex2Bytes("f47261b000000000000000000000000038ae374ecf4db50b0ff37125b591a04997106a32")
-	singleAssetDatas, err := parseContractAddressesAndTokenIdsFromAssetData(erc20AssetData, contractAddresses)
+	singleAssetDatas, err := parseContractAddressesAndTokenIdsFromAssetData(assetDataDecoder, erc20AssetData, contractAddresses)
 	require.NoError(t, err)
 	assert.Len(t, singleAssetDatas, 1)
 	expectedAddress := common.HexToAddress("0x38ae374ecf4db50b0ff37125b591a04997106a32")
@@ -111,7 +113,7 @@ func TestParseContractAddressesAndTokenIdsFromAssetData(t *testing.T) {
 
 	// ERC721 AssetData
 	erc721AssetData := common.Hex2Bytes("025717920000000000000000000000001dc4c1cefef38a777b15aa20260a54e584b16c480000000000000000000000000000000000000000000000000000000000000001")
-	singleAssetDatas, err = parseContractAddressesAndTokenIdsFromAssetData(erc721AssetData, contractAddresses)
+	singleAssetDatas, err = parseContractAddressesAndTokenIdsFromAssetData(assetDataDecoder, erc721AssetData, contractAddresses)
 	require.NoError(t, err)
 	assert.Equal(t, 1, len(singleAssetDatas))
 	expectedAddress = common.HexToAddress("0x1dC4c1cEFEF38a777b15aA20260a54E584b16C48")
@@ -121,7 +123,7 @@ func TestParseContractAddressesAndTokenIdsFromAssetData(t *testing.T) {
 
 	// Multi AssetData
 	multiAssetData := common.Hex2Bytes("94cfcdd7000000000000000000000000000000000000000000000000000000000000004000000000000000000000000000000000000000000000000000000000000000a00000000000000000000000000000000000000000000000000000000000000002000000000000000000000000000000000000000000000000000000000000004600000000000000000000000000000000000000000000000000000000000000010000000000000000000000000000000000000000000000000000000000000002000000000000000000000000000000000000000000000000000000000000004000000000000000000000000000000000000000000000000000000000000000a00000000000000000000000000000000000000000000000000000000000000024f47261b00000000000000000000000001dc4c1cefef38a777b15aa20260a54e584b16c48000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000044025717920000000000000000000000001dc4c1cefef38a777b15aa20260a54e584b16c480000000000000000000000000000000000000000000000000000000000000001000000000000000000000000000000000000000000000000000000000x94cfcdd7000000000000000000000000000000000000000000000000000000000000004000000000000000000000000000000000000000000000000000000000000000a00000000000000000000000000000000000000000000000000000000000000002000000000000000000000000000000000000000000000000000000000000004600000000000000000000000000000000000000000000000000000000000000010000000000000000000000000000000000000000000000000000000000000002000000000000000000000000000000000000000000000000000000000000004000000000000000000000000000000000000000000000000000000000000000a00000000000000000000000000000000000000000000000000000000000000024f47261b00000000000000000000000001dc4c1cefef38a777b15aa20260a54e584b16c48000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000044025717920000000000000000000000001dc4c1cefef38a777b15aa20260a54e584b16c48000000000000000000000000000000000000000000000000000000000000000100000000000000000000000000000000000000000000000000000000")
-	singleAssetDatas, err = parseContractAddressesAndTokenIdsFromAssetData(multiAssetData, contractAddresses)
+	singleAssetDatas, err = parseContractAddressesAndTokenIdsFromAssetData(assetDataDecoder, multiAssetData, contractAddresses)
 	require.NoError(t, err)
 	assert.Equal(t, 2, len(singleAssetDatas))
 	expectedSingleAssetDatas := []singleAssetData{
@@ -304,6 +306,219 @@ func TestTrimOrdersByExpirationTime(t *testing.T) {
 	assert.EqualError(t, err, ErrDBFilledWithPinnedOrders.Error(), "expected ErrFilledWithPinnedOrders when targetMaxOrders is less than the number of pinned orders")
 }
 
+func TestTrimOrders(t *testing.T) {
+	meshDB, err := New("/tmp/meshdb_testing/"+uuid.New().String(), contractAddresses)
+	require.NoError(t, err)
+	defer meshDB.Close()
+
+	rawUnpinnedOrders := make([]*zeroex.Order, 4)
+	for i := range rawUnpinnedOrders {
+		rawUnpinnedOrders[i] = &zeroex.Order{
+			MakerAddress:          constants.GanacheAccount0,
+			TakerAddress:          constants.NullAddress,
+			SenderAddress:         constants.NullAddress,
+			FeeRecipientAddress:   common.HexToAddress("0xa258b39954cef5cb142fd567a46cddb31a670124"),
+			TakerAssetData:        common.Hex2Bytes("f47261b000000000000000000000000034d402f14d58e001d8efbe6585051bf9706aa064"),
+			MakerAssetData:        common.Hex2Bytes("f47261b0000000000000000000000000871dd7c2b4b25e1aa18728e9d5f2af4c4e431f5c"),
+			ChainID:               big.NewInt(constants.TestChainID),
+			TakerFeeAssetData:     constants.NullBytes,
+			MakerFeeAssetData:     constants.NullBytes,
+			Salt:                  big.NewInt(int64(i)),
+			MakerFee:              big.NewInt(0),
+			TakerFee:              big.NewInt(0),
+			MakerAssetAmount:      big.NewInt(1000),
+			TakerAssetAmount:      big.NewInt(1000),
+			ExpirationTimeSeconds: big.NewInt(100),
+			ExchangeAddress:       contractAddresses.Exchange,
+		}
+	}
+	rawPinnedOrder := *rawUnpinnedOrders[0]
+	rawPinnedOrder.Salt = big.NewInt(100)
+	insertedUnpinnedOrders := insertRawOrders(t, meshDB, rawUnpinnedOrders, false)
+	insertedPinnedOrders := insertRawOrders(t, meshDB, []*zeroex.Order{&rawPinnedOrder}, true)
+
+	// Select the orders with the lowest salts. Only unpinned orders should be
+	// passed to the selector.
+	selectLowestSalts := func(unpinnedOrders []*Order, numOrdersToRemove int) ([]*Order, error) {
+		assert.Len(t, unpinnedOrders, len(rawUnpinnedOrders))
+		selectedOrders := []*Order{}
+		for _, order := range unpinnedOrders {
+			if order.SignedOrder.Salt.Int64() < int64(numOrdersToRemove) {
+				selectedOrders = append(selectedOrders, order)
+			}
+		}
+		return selectedOrders, nil
+	}
+	removedOrders, err := meshDB.TrimOrders(3, selectLowestSalts)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, orderHashes(insertedUnpinnedOrders[:2]), orderHashes(removedOrders))
+
+	var remainingOrders []*Order
+	require.NoError(t, meshDB.Orders.FindAll(&remainingOrders))
+	expectedRemainingOrders := append(insertedUnpinnedOrders[2:], insertedPinnedOrders...)
+	assert.ElementsMatch(t, orderHashes(expectedRemainingOrders), orderHashes(remainingOrders))
+
+	// Nothing should be removed if there are no more orders than the target.
+	removedOrders, err = meshDB.TrimOrders(3, selectLowestSalts)
+	require.NoError(t, err)
+	assert.Empty(t, removedOrders)
+
+	// The selector must select the requested number of orders.
+	selectNothing := func(unpinnedOrders []*Order, numOrdersToRemove int) ([]*Order, error) {
+		return nil, nil
+	}
+	_, err = meshDB.TrimOrders(1, selectNothing)
+	assert.Error(t, err)
+}
+
+func TestCountUnpinnedOrders(t *testing.T) {
+	meshDB, err := New("/tmp/meshdb_testing/"+uuid.New().String(), contractAddresses)
+	require.NoError(t, err)
+	defer meshDB.Close()
+
+	makerAddresses := []common.Address{constants.GanacheAccount0, constants.GanacheAccount0, constants.GanacheAccount0, constants.GanacheAccount1}
+	rawOrders := make([]*zeroex.Order, len(makerAddresses))
+	for i, makerAddress := range makerAddresses {
+		rawOrders[i] = &zeroex.Order{
+			MakerAddress:          makerAddress,
+			TakerAddress:          constants.NullAddress,
+			SenderAddress:         constants.NullAddress,
+			FeeRecipientAddress:   common.HexToAddress("0xa258b39954cef5cb142fd567a46cddb31a670124"),
+			TakerAssetData:        common.Hex2Bytes("f47261b000000000000000000000000034d402f14d58e001d8efbe6585051bf9706aa064"),
+			MakerAssetData:        common.Hex2Bytes("f47261b0000000000000000000000000871dd7c2b4b25e1aa18728e9d5f2af4c4e431f5c"),
+			ChainID:               big.NewInt(constants.TestChainID),
+			TakerFeeAssetData:     constants.NullBytes,
+			MakerFeeAssetData:     constants.NullBytes,
+			Salt:                  big.NewInt(int64(i)),
+			MakerFee:              big.NewInt(0),
+			TakerFee:              big.NewInt(0),
+			MakerAssetAmount:      big.NewInt(1000),
+			TakerAssetAmount:      big.NewInt(1000),
+			ExpirationTimeSeconds: big.NewInt(100),
+			ExchangeAddress:       contractAddresses.Exchange,
+		}
+	}
+	rawPinnedOrder := *rawOrders[0]
+	rawPinnedOrder.Salt = big.NewInt(100)
+	orders := insertRawOrders(t, meshDB, rawOrders, false)
+	insertRawOrders(t, meshDB, []*zeroex.Order{&rawPinnedOrder}, true)
+
+	peerID := "16Uiu2HAmGd949LwaV4KNvK2WDSiMVy7xEmW983VH75CMmefmMpP7"
+	for _, order := range orders[1:] {
+		order.ReceivedFrom = peerID
+		require.NoError(t, meshDB.Orders.Update(order))
+	}
+	// Orders which are flagged for removal do not count.
+	orders[2].IsRemoved = true
+	require.NoError(t, meshDB.Orders.Update(orders[2]))
+
+	count, err := meshDB.CountUnpinnedOrdersByMakerAddress(constants.GanacheAccount0)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+	count, err = meshDB.CountUnpinnedOrdersByMakerAddress(constants.GanacheAccount2)
+	require.NoError(t, err)
+	assert.Equal(t, 0, count)
+
+	count, err = meshDB.CountUnpinnedOrdersReceivedFrom(peerID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	counts, err := meshDB.CountUnpinnedOrdersPerMakerAddress()
+	require.NoError(t, err)
+	expectedCounts := map[common.Address]int{
+		constants.GanacheAccount0: 2,
+		constants.GanacheAccount1: 1,
+	}
+	assert.Equal(t, expectedCounts, counts)
+}
+
+func TestFindOrdersByAssetPair(t *testing.T) {
+	meshDB, err := New("/tmp/meshdb_testing/"+uuid.New().String(), contractAddresses)
+	require.NoError(t, err)
+	defer meshDB.Close()
+
+	zrxAssetData := common.Hex2Bytes("f47261b0000000000000000000000000871dd7c2b4b25e1aa18728e9d5f2af4c4e431f5c")
+	wethAssetData := common.Hex2Bytes("f47261b000000000000000000000000034d402f14d58e001d8efbe6585051bf9706aa064")
+	otherAssetData := common.Hex2Bytes("f47261b00000000000000000000000000b1ba0af832d7c05fd64161e0db78e85978e8082")
+	assetPairs := [][2][]byte{
+		{zrxAssetData, wethAssetData},
+		{zrxAssetData, wethAssetData},
+		{wethAssetData, zrxAssetData},
+		{zrxAssetData, otherAssetData},
+	}
+	rawOrders := make([]*zeroex.Order, len(assetPairs))
+	for i, assetPair := range assetPairs {
+		rawOrders[i] = &zeroex.Order{
+			MakerAddress:          constants.GanacheAccount0,
+			TakerAddress:          constants.NullAddress,
+			SenderAddress:         constants.NullAddress,
+			FeeRecipientAddress:   common.HexToAddress("0xa258b39954cef5cb142fd567a46cddb31a670124"),
+			MakerAssetData:        assetPair[0],
+			TakerAssetData:        assetPair[1],
+			ChainID:               big.NewInt(constants.TestChainID),
+			TakerFeeAssetData:     constants.NullBytes,
+			MakerFeeAssetData:     constants.NullBytes,
+			Salt:                  big.NewInt(int64(i)),
+			MakerFee:              big.NewInt(0),
+			TakerFee:              big.NewInt(0),
+			MakerAssetAmount:      big.NewInt(1000),
+			TakerAssetAmount:      big.NewInt(1000),
+			ExpirationTimeSeconds: big.NewInt(100),
+			ExchangeAddress:       contractAddresses.Exchange,
+		}
+	}
+	orders := insertRawOrders(t, meshDB, rawOrders, false)
+	// Orders which are flagged for removal are not returned.
+	orders[1].IsRemoved = true
+	require.NoError(t, meshDB.Orders.Update(orders[1]))
+
+	foundOrders, err := meshDB.FindOrdersByAssetPair(zrxAssetData, wethAssetData)
+	require.NoError(t, err)
+	assert.Equal(t, orderHashes(orders[:1]), orderHashes(foundOrders))
+	foundOrders, err = meshDB.FindOrdersByAssetPair(wethAssetData, zrxAssetData)
+	require.NoError(t, err)
+	assert.Equal(t, orderHashes(orders[2:3]), orderHashes(foundOrders))
+	foundOrders, err = meshDB.FindOrdersByAssetPair(wethAssetData, otherAssetData)
+	require.NoError(t, err)
+	assert.Empty(t, foundOrders)
+}
+
+func TestFindOrderHashes(t *testing.T) {
+	meshDB, err := New("/tmp/meshdb_testing/"+uuid.New().String(), contractAddresses)
+	require.NoError(t, err)
+	defer meshDB.Close()
+
+	rawOrders := make([]*zeroex.Order, 3)
+	for i := range rawOrders {
+		rawOrders[i] = &zeroex.Order{
+			MakerAddress:          constants.GanacheAccount0,
+			TakerAddress:          constants.NullAddress,
+			SenderAddress:         constants.NullAddress,
+			FeeRecipientAddress:   common.HexToAddress("0xa258b39954cef5cb142fd567a46cddb31a670124"),
+			MakerAssetData:        common.Hex2Bytes("f47261b0000000000000000000000000871dd7c2b4b25e1aa18728e9d5f2af4c4e431f5c"),
+			TakerAssetData:        common.Hex2Bytes("f47261b000000000000000000000000034d402f14d58e001d8efbe6585051bf9706aa064"),
+			ChainID:               big.NewInt(constants.TestChainID),
+			TakerFeeAssetData:     constants.NullBytes,
+			MakerFeeAssetData:     constants.NullBytes,
+			Salt:                  big.NewInt(int64(i)),
+			MakerFee:              big.NewInt(0),
+			TakerFee:              big.NewInt(0),
+			MakerAssetAmount:      big.NewInt(1000),
+			TakerAssetAmount:      big.NewInt(1000),
+			ExpirationTimeSeconds: big.NewInt(int64(100 + i)),
+			ExchangeAddress:       contractAddresses.Exchange,
+		}
+	}
+	orders := insertRawOrders(t, meshDB, rawOrders, false)
+	// Orders which are flagged for removal are included.
+	orders[1].IsRemoved = true
+	require.NoError(t, meshDB.Orders.Update(orders[1]))
+
+	foundHashes, err := meshDB.FindOrderHashes()
+	require.NoError(t, err)
+	assert.ElementsMatch(t, orderHashes(orders), foundHashes)
+}
+
 func TestFindOrdersByMakerAddressMakerFeeAssetAddressTokenID(t *testing.T) {
 	meshDB, err := New("/tmp/meshdb_testing/"+uuid.New().String(), contractAddresses)
 	require.NoError(t, err)
@@ -472,6 +687,14 @@ func insertRawOrders(t *testing.T, meshDB *MeshDB, rawOrders []*zeroex.Order, is
 	return results
 }
 
+func orderHashes(orders []*Order) []common.Hash {
+	hashes := make([]common.Hash, len(orders))
+	for i, order := range orders {
+		hashes[i] = order.Hash
+	}
+	return hashes
+}
+
 func TestPruneMiniHeadersAboveRetentionLimit(t *testing.T) {
 	t.Parallel()
 
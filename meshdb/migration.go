@@ -0,0 +1,116 @@
+package meshdb
+
+import (
+	"time"
+
+	"github.com/0xProject/0x-mesh/db"
+	log "github.com/sirupsen/logrus"
+)
+
+// Migration is the database representation of a migration which has been
+// applied to the database.
+type Migration struct {
+	Name      string
+	AppliedAt time.Time
+}
+
+// ID returns the Migration's ID
+func (m Migration) ID() []byte {
+	return []byte(m.Name)
+}
+
+// migrations are applied in order when the database is opened, unless they
+// have already been applied. New migrations must be appended to the end and
+// the names of existing migrations must never change. Migrations must be safe
+// to apply more than once, since the database could be closed after a
+// migration was applied but before it was recorded.
+var migrations = []struct {
+	name  string
+	apply func(m *MeshDB) error
+}{
+	{name: "backfillUnpinnedOrderIndexes", apply: (*MeshDB).backfillUnpinnedOrderIndexes},
+	{name: "backfillAssetPairIndex", apply: (*MeshDB).backfillAssetPairIndex},
+	{name: "backfillAddedAt", apply: (*MeshDB).backfillAddedAt},
+}
+
+func setupMigrations(database *db.DB) (*db.Collection, error) {
+	return database.NewCollection("migration", &Migration{})
+}
+
+// applyMigrations applies all migrations which have not been applied yet.
+func (m *MeshDB) applyMigrations() error {
+	for _, migration := range migrations {
+		var applied Migration
+		err := m.migrations.FindByID([]byte(migration.name), &applied)
+		if err == nil {
+			continue
+		} else if _, ok := err.(db.NotFoundError); !ok {
+			return err
+		}
+		log.WithField("migration", migration.name).Info("applying database migration")
+		if err := migration.apply(m); err != nil {
+			return err
+		}
+		if err := m.migrations.Insert(&Migration{Name: migration.name, AppliedAt: time.Now()}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// backfillUnpinnedOrderIndexes adds the unpinned orders which were stored
+// before UnpinnedMakerAddressIndex and UnpinnedReceivedFromIndex existed to
+// these indexes. Otherwise they would not count towards the per-maker and
+// per-peer quotas until they are updated.
+func (m *MeshDB) backfillUnpinnedOrderIndexes() error {
+	return m.updateOrders(func(order *Order) bool {
+		return !order.IsPinned && !order.IsRemoved
+	})
+}
+
+// backfillAssetPairIndex adds the orders which were stored before
+// AssetPairIndex existed to the index. Otherwise they would be missing from
+// the orderbook until they are updated.
+func (m *MeshDB) backfillAssetPairIndex() error {
+	return m.updateOrders(func(order *Order) bool {
+		return !order.IsRemoved
+	})
+}
+
+// backfillAddedAt sets AddedAt of the orders which were stored before it was
+// recorded to the time they were last validated, which is the best available
+// approximation. Otherwise the age eviction policy would evict all of them
+// before any newer order, regardless of how long ago they were actually added.
+func (m *MeshDB) backfillAddedAt() error {
+	return m.updateOrders(func(order *Order) bool {
+		if !order.AddedAt.IsZero() {
+			return false
+		}
+		order.AddedAt = order.LastUpdated
+		return true
+	})
+}
+
+// updateOrders updates all orders for which shouldUpdate returns true in a
+// single transaction. shouldUpdate may modify the order before it is updated.
+func (m *MeshDB) updateOrders(shouldUpdate func(order *Order) bool) error {
+	var orders []*Order
+	if err := m.Orders.FindAll(&orders); err != nil {
+		return err
+	}
+	txn := m.Orders.OpenTransaction()
+	defer func() {
+		_ = txn.Discard()
+	}()
+	for _, order := range orders {
+		if !shouldUpdate(order) {
+			continue
+		}
+		// Updating an order saves all of its index entries, including the ones
+		// which are missing.
+		if err := txn.Update(order); err != nil {
+			return err
+		}
+	}
+	return txn.Commit()
+}
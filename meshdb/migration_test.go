@@ -0,0 +1,138 @@
+package meshdb
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/0xProject/0x-mesh/constants"
+	"github.com/0xProject/0x-mesh/db"
+	"github.com/0xProject/0x-mesh/zeroex"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackfillOrderIndexes(t *testing.T) {
+	path := "/tmp/meshdb_testing/" + uuid.New().String()
+	peerID := "16Uiu2HAmGd949LwaV4KNvK2WDSiMVy7xEmW983VH75CMmefmMpP7"
+
+	// Store orders the way they were stored before the unpinned order and asset
+	// pair indexes existed.
+	database, err := db.Open(path)
+	require.NoError(t, err)
+	oldOrders, err := database.NewCollection("order", &Order{})
+	require.NoError(t, err)
+	for i, isPinned := range []bool{false, false, true} {
+		signedOrder, err := zeroex.SignTestOrder(&zeroex.Order{
+			MakerAddress:          constants.GanacheAccount0,
+			TakerAddress:          constants.NullAddress,
+			SenderAddress:         constants.NullAddress,
+			FeeRecipientAddress:   common.HexToAddress("0xa258b39954cef5cb142fd567a46cddb31a670124"),
+			TakerAssetData:        common.Hex2Bytes("f47261b000000000000000000000000034d402f14d58e001d8efbe6585051bf9706aa064"),
+			MakerAssetData:        common.Hex2Bytes("f47261b0000000000000000000000000871dd7c2b4b25e1aa18728e9d5f2af4c4e431f5c"),
+			ChainID:               big.NewInt(constants.TestChainID),
+			TakerFeeAssetData:     constants.NullBytes,
+			MakerFeeAssetData:     constants.NullBytes,
+			Salt:                  big.NewInt(int64(i)),
+			MakerFee:              big.NewInt(0),
+			TakerFee:              big.NewInt(0),
+			MakerAssetAmount:      big.NewInt(1000),
+			TakerAssetAmount:      big.NewInt(1000),
+			ExpirationTimeSeconds: big.NewInt(100),
+			ExchangeAddress:       contractAddresses.Exchange,
+		})
+		require.NoError(t, err)
+		orderHash, err := signedOrder.ComputeOrderHash()
+		require.NoError(t, err)
+		require.NoError(t, oldOrders.Insert(&Order{
+			Hash:                     orderHash,
+			SignedOrder:              signedOrder,
+			FillableTakerAssetAmount: big.NewInt(1),
+			IsPinned:                 isPinned,
+			ReceivedFrom:             peerID,
+		}))
+	}
+	database.Close()
+
+	meshDB, err := New(path, contractAddresses)
+	require.NoError(t, err)
+	count, err := meshDB.CountUnpinnedOrdersByMakerAddress(constants.GanacheAccount0)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+	count, err = meshDB.CountUnpinnedOrdersReceivedFrom(peerID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+	orders, err := meshDB.FindOrdersByAssetPair(
+		common.Hex2Bytes("f47261b0000000000000000000000000871dd7c2b4b25e1aa18728e9d5f2af4c4e431f5c"),
+		common.Hex2Bytes("f47261b000000000000000000000000034d402f14d58e001d8efbe6585051bf9706aa064"),
+	)
+	require.NoError(t, err)
+	assert.Len(t, orders, 3)
+
+	// The migration is recorded and not applied again.
+	var migration Migration
+	require.NoError(t, meshDB.migrations.FindByID([]byte("backfillUnpinnedOrderIndexes"), &migration))
+	meshDB.Close()
+	meshDB, err = New(path, contractAddresses)
+	require.NoError(t, err)
+	defer meshDB.Close()
+	var reopenedMigration Migration
+	require.NoError(t, meshDB.migrations.FindByID([]byte("backfillUnpinnedOrderIndexes"), &reopenedMigration))
+	assert.True(t, migration.AppliedAt.Equal(reopenedMigration.AppliedAt))
+}
+
+func TestBackfillAddedAt(t *testing.T) {
+	path := "/tmp/meshdb_testing/" + uuid.New().String()
+	lastUpdated := time.Now().Add(-time.Hour).UTC()
+	addedAt := time.Now().UTC()
+
+	// Store one order without AddedAt, as it was stored before AddedAt was
+	// recorded, and one order with AddedAt.
+	database, err := db.Open(path)
+	require.NoError(t, err)
+	oldOrders, err := database.NewCollection("order", &Order{})
+	require.NoError(t, err)
+	hashes := make([]common.Hash, 2)
+	for i, orderAddedAt := range []time.Time{{}, addedAt} {
+		signedOrder, err := zeroex.SignTestOrder(&zeroex.Order{
+			MakerAddress:          constants.GanacheAccount0,
+			TakerAddress:          constants.NullAddress,
+			SenderAddress:         constants.NullAddress,
+			FeeRecipientAddress:   common.HexToAddress("0xa258b39954cef5cb142fd567a46cddb31a670124"),
+			TakerAssetData:        common.Hex2Bytes("f47261b000000000000000000000000034d402f14d58e001d8efbe6585051bf9706aa064"),
+			MakerAssetData:        common.Hex2Bytes("f47261b0000000000000000000000000871dd7c2b4b25e1aa18728e9d5f2af4c4e431f5c"),
+			ChainID:               big.NewInt(constants.TestChainID),
+			TakerFeeAssetData:     constants.NullBytes,
+			MakerFeeAssetData:     constants.NullBytes,
+			Salt:                  big.NewInt(int64(i)),
+			MakerFee:              big.NewInt(0),
+			TakerFee:              big.NewInt(0),
+			MakerAssetAmount:      big.NewInt(1000),
+			TakerAssetAmount:      big.NewInt(1000),
+			ExpirationTimeSeconds: big.NewInt(100),
+			ExchangeAddress:       contractAddresses.Exchange,
+		})
+		require.NoError(t, err)
+		hashes[i], err = signedOrder.ComputeOrderHash()
+		require.NoError(t, err)
+		require.NoError(t, oldOrders.Insert(&Order{
+			Hash:                     hashes[i],
+			SignedOrder:              signedOrder,
+			FillableTakerAssetAmount: big.NewInt(1),
+			LastUpdated:              lastUpdated,
+			AddedAt:                  orderAddedAt,
+		}))
+	}
+	database.Close()
+
+	meshDB, err := New(path, contractAddresses)
+	require.NoError(t, err)
+	defer meshDB.Close()
+	var order Order
+	require.NoError(t, meshDB.Orders.FindByID(hashes[0].Bytes(), &order))
+	assert.True(t, lastUpdated.Equal(order.AddedAt), "AddedAt was not set to LastUpdated")
+	require.NoError(t, meshDB.Orders.FindByID(hashes[1].Bytes(), &order))
+	assert.True(t, addedAt.Equal(order.AddedAt), "AddedAt was overwritten")
+}
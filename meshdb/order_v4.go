@@ -0,0 +1,204 @@
+package meshdb
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/0xProject/0x-mesh/constants"
+	"github.com/0xProject/0x-mesh/db"
+	"github.com/0xProject/0x-mesh/zeroex"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// OrderV4 is the database representation of an Exchange V4 order along with
+// some relevant metadata. V4 orders are stored in a separate collection from
+// V3 orders, but their metadata has the same meaning.
+type OrderV4 struct {
+	Hash        common.Hash
+	SignedOrder *zeroex.SignedOrderV4
+	// When was this order last validated
+	LastUpdated time.Time
+	// How much of this order can still be filled
+	FillableTakerAssetAmount *big.Int
+	// Was this order flagged for removal? See Order.IsRemoved.
+	IsRemoved bool
+	// IsPinned indicates whether or not the order is pinned. Pinned orders are
+	// not removed from the database unless they become unfillable.
+	IsPinned bool
+}
+
+// ID returns the OrderV4's ID
+func (o OrderV4) ID() []byte {
+	return o.Hash.Bytes()
+}
+
+// OrdersV4Collection represents a DB collection of Exchange V4 orders
+type OrdersV4Collection struct {
+	*db.Collection
+	MakerAddressAndPairIndex *db.Index
+	LastUpdatedIndex         *db.Index
+	IsRemovedIndex           *db.Index
+	ExpirationTimeIndex      *db.Index
+}
+
+func setupOrdersV4(database *db.DB) (*OrdersV4Collection, error) {
+	col, err := database.NewCollection("orderV4", &OrderV4{})
+	if err != nil {
+		return nil, err
+	}
+	lastUpdatedIndex := col.AddIndex("lastUpdated", func(m db.Model) []byte {
+		index := []byte(m.(*OrderV4).LastUpdated.UTC().Format(time.RFC3339Nano))
+		return index
+	})
+	// Unlike V3 orders, V4 orders always consist of exactly one maker token and
+	// one taker token, so we can index them directly. The index is used both to
+	// find orders affected by token events (by prefix) and to find orders
+	// affected by pair cancellations.
+	makerAddressAndPairIndex := col.AddIndex("makerAddressAndPair", func(m db.Model) []byte {
+		signedOrder := m.(*OrderV4).SignedOrder
+		return []byte(signedOrder.Maker.Hex() + "|" + signedOrder.MakerToken.Hex() + "|" + signedOrder.TakerToken.Hex())
+	})
+	isRemovedIndex := col.AddIndex("isRemoved", func(m db.Model) []byte {
+		order := m.(*OrderV4)
+		// false = 0; true = 1
+		if order.IsRemoved {
+			return []byte{1}
+		}
+		return []byte{0}
+	})
+	expirationTimeIndex := col.AddIndex("expirationTime", func(m db.Model) []byte {
+		order := m.(*OrderV4)
+		expTimeString := uint256ToConstantLengthBytes(order.SignedOrder.Expiry)
+		// We separate pinned and non-pinned orders via a prefix that is either 0 or
+		// 1.
+		pinnedString := "0"
+		if order.IsPinned {
+			pinnedString = "1"
+		}
+		return []byte(fmt.Sprintf("%s|%s", pinnedString, expTimeString))
+	})
+
+	return &OrdersV4Collection{
+		Collection:               col,
+		MakerAddressAndPairIndex: makerAddressAndPairIndex,
+		LastUpdatedIndex:         lastUpdatedIndex,
+		IsRemovedIndex:           isRemovedIndex,
+		ExpirationTimeIndex:      expirationTimeIndex,
+	}, nil
+}
+
+// FindOrdersV4ByMakerAddressAndMakerToken finds all V4 orders belonging to a
+// particular maker address with the given maker token.
+func (m *MeshDB) FindOrdersV4ByMakerAddressAndMakerToken(makerAddress, makerToken common.Address) ([]*OrderV4, error) {
+	prefix := []byte(makerAddress.Hex() + "|" + makerToken.Hex() + "|")
+	filter := m.OrdersV4.MakerAddressAndPairIndex.PrefixFilter(prefix)
+	orders := []*OrderV4{}
+	if err := m.OrdersV4.NewQuery(filter).Run(&orders); err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+// FindOrdersV4ByMakerAddressAndPair finds all V4 orders belonging to a
+// particular maker address with the given maker and taker tokens.
+func (m *MeshDB) FindOrdersV4ByMakerAddressAndPair(makerAddress, makerToken, takerToken common.Address) ([]*OrderV4, error) {
+	value := []byte(makerAddress.Hex() + "|" + makerToken.Hex() + "|" + takerToken.Hex())
+	filter := m.OrdersV4.MakerAddressAndPairIndex.ValueFilter(value)
+	orders := []*OrderV4{}
+	if err := m.OrdersV4.NewQuery(filter).Run(&orders); err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+// FindOrdersV4LastUpdatedBefore finds all V4 orders where the LastUpdated time
+// is less than X
+func (m *MeshDB) FindOrdersV4LastUpdatedBefore(lastUpdated time.Time) ([]*OrderV4, error) {
+	start := []byte(time.Unix(0, 0).Format(time.RFC3339Nano))
+	limit := []byte(lastUpdated.UTC().Format(time.RFC3339Nano))
+	filter := m.OrdersV4.LastUpdatedIndex.RangeFilter(start, limit)
+	orders := []*OrderV4{}
+	if err := m.OrdersV4.NewQuery(filter).Run(&orders); err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+// FindRemovedOrdersV4 finds all V4 orders that have been flagged for removal
+func (m *MeshDB) FindRemovedOrdersV4() ([]*OrderV4, error) {
+	var removedOrders []*OrderV4
+	isRemovedFilter := m.OrdersV4.IsRemovedIndex.ValueFilter([]byte{1})
+	if err := m.OrdersV4.NewQuery(isRemovedFilter).Run(&removedOrders); err != nil {
+		return nil, err
+	}
+	return removedOrders, nil
+}
+
+// FindOrdersV4ExpiredAt finds all V4 orders, pinned or not, that are expired at
+// the given timestamp. The Exchange Proxy considers an order expired once its
+// expiry is less than or equal to the block timestamp.
+func (m *MeshDB) FindOrdersV4ExpiredAt(timestamp *big.Int) ([]*OrderV4, error) {
+	// DB range queries exclude the limit value, so we add 1 to the supplied
+	// timestamp in order to make the query inclusive.
+	timestampPlusOne := new(big.Int).Add(timestamp, big.NewInt(1))
+	expiredOrders := []*OrderV4{}
+	for _, pinnedString := range []string{"0", "1"} {
+		start := []byte(fmt.Sprintf("%s|%s", pinnedString, uint256ToConstantLengthBytes(big.NewInt(0))))
+		limit := []byte(fmt.Sprintf("%s|%s", pinnedString, uint256ToConstantLengthBytes(timestampPlusOne)))
+		filter := m.OrdersV4.ExpirationTimeIndex.RangeFilter(start, limit)
+		orders := []*OrderV4{}
+		if err := m.OrdersV4.NewQuery(filter).Run(&orders); err != nil {
+			return nil, err
+		}
+		expiredOrders = append(expiredOrders, orders...)
+	}
+	return expiredOrders, nil
+}
+
+// TrimOrdersV4ByExpirationTime removes existing V4 orders with the highest
+// expiry until the number of remaining V4 orders is <= targetMaxOrders. It
+// behaves like TrimOrdersByExpirationTime, except that the orders which were
+// removed are also returned along with ErrDBFilledWithPinnedOrders so that the
+// caller can stop watching them.
+func (m *MeshDB) TrimOrdersV4ByExpirationTime(targetMaxOrders int) (newMaxExpirationTime *big.Int, removedOrders []*OrderV4, err error) {
+	txn := m.OrdersV4.OpenTransaction()
+	defer func() {
+		_ = txn.Discard()
+	}()
+
+	numOrders, err := m.OrdersV4.Count()
+	if err != nil {
+		return nil, nil, err
+	}
+	if numOrders <= targetMaxOrders {
+		return constants.UnlimitedExpirationTime, nil, nil
+	}
+
+	// Only non-pinned orders are removed, see TrimOrdersByExpirationTime.
+	filter := m.OrdersV4.ExpirationTimeIndex.PrefixFilter([]byte("0|"))
+	numOrdersToRemove := numOrders - targetMaxOrders
+	if err := m.OrdersV4.NewQuery(filter).Reverse().Max(numOrdersToRemove).Run(&removedOrders); err != nil {
+		return nil, nil, err
+	}
+	for _, order := range removedOrders {
+		if err := txn.Delete(order.Hash.Bytes()); err != nil {
+			return nil, nil, err
+		}
+	}
+	if err := txn.Commit(); err != nil {
+		return nil, nil, err
+	}
+	if len(removedOrders) < numOrdersToRemove {
+		return nil, removedOrders, ErrDBFilledWithPinnedOrders
+	}
+
+	newMaxExpirationTime = new(big.Int).Sub(removedOrders[len(removedOrders)-1].SignedOrder.Expiry, big.NewInt(1))
+	return newMaxExpirationTime, removedOrders, nil
+}
+
+// CountPinnedOrdersV4 returns the number of pinned V4 orders.
+func (m *MeshDB) CountPinnedOrdersV4() (int, error) {
+	filter := m.OrdersV4.ExpirationTimeIndex.PrefixFilter([]byte("1|"))
+	return m.OrdersV4.NewQuery(filter).Count()
+}
@@ -0,0 +1,174 @@
+package meshdb
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/0xProject/0x-mesh/constants"
+	"github.com/0xProject/0x-mesh/db"
+	"github.com/0xProject/0x-mesh/zeroex"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderV4CRUDOperations(t *testing.T) {
+	meshDB, err := New("/tmp/meshdb_testing/"+uuid.New().String(), contractAddresses)
+	require.NoError(t, err)
+	defer meshDB.Close()
+
+	makerAddress := constants.GanacheAccount0
+	o := &zeroex.OrderV4{
+		Type:                zeroex.LimitOrderTypeV4,
+		ChainID:             big.NewInt(constants.TestChainID),
+		VerifyingContract:   common.HexToAddress("0xdef1c0ded9bec7f1a1670819833240f027b25eff"),
+		MakerToken:          contractAddresses.ZRXToken,
+		TakerToken:          contractAddresses.WETH9,
+		MakerAmount:         big.NewInt(3551808554499581700),
+		TakerAmount:         big.NewInt(1),
+		TakerTokenFeeAmount: big.NewInt(1),
+		Maker:               makerAddress,
+		Taker:               constants.NullAddress,
+		Sender:              constants.NullAddress,
+		FeeRecipient:        common.HexToAddress("0xa258b39954cef5cb142fd567a46cddb31a670124"),
+		TxOrigin:            constants.NullAddress,
+		Expiry:              big.NewInt(1548619325),
+		Salt:                big.NewInt(1548619145450),
+	}
+	signedOrder, err := zeroex.SignTestOrderV4(o)
+	require.NoError(t, err)
+
+	orderHash, err := o.ComputeOrderHash()
+	require.NoError(t, err)
+
+	currentTime := time.Now().UTC()
+	fiveMinutesFromNow := currentTime.Add(5 * time.Minute)
+
+	// Insert
+	order := &OrderV4{
+		Hash:                     orderHash,
+		SignedOrder:              signedOrder,
+		FillableTakerAssetAmount: big.NewInt(1),
+		LastUpdated:              currentTime,
+		IsRemoved:                false,
+	}
+	require.NoError(t, meshDB.OrdersV4.Insert(order))
+	// We need to call ResetHash so that unexported hash field is equal in later
+	// assertions.
+	signedOrder.ResetHash()
+
+	// Find
+	foundOrder := &OrderV4{}
+	require.NoError(t, meshDB.OrdersV4.FindByID(order.ID(), foundOrder))
+	assert.Equal(t, order, foundOrder)
+
+	// V4 orders are not visible in the V3 orders collection
+	v3Orders, err := meshDB.FindOrdersByMakerAddress(makerAddress)
+	require.NoError(t, err)
+	assert.Len(t, v3Orders, 0)
+
+	// Check Indexes
+	orders, err := meshDB.FindOrdersV4ByMakerAddressAndMakerToken(makerAddress, contractAddresses.ZRXToken)
+	require.NoError(t, err)
+	assert.Equal(t, []*OrderV4{order}, orders)
+
+	orders, err = meshDB.FindOrdersV4ByMakerAddressAndMakerToken(makerAddress, contractAddresses.WETH9)
+	require.NoError(t, err)
+	assert.Len(t, orders, 0)
+
+	orders, err = meshDB.FindOrdersV4ByMakerAddressAndPair(makerAddress, contractAddresses.ZRXToken, contractAddresses.WETH9)
+	require.NoError(t, err)
+	assert.Equal(t, []*OrderV4{order}, orders)
+
+	orders, err = meshDB.FindOrdersV4ByMakerAddressAndPair(makerAddress, contractAddresses.WETH9, contractAddresses.ZRXToken)
+	require.NoError(t, err)
+	assert.Len(t, orders, 0)
+
+	orders, err = meshDB.FindOrdersV4LastUpdatedBefore(fiveMinutesFromNow)
+	require.NoError(t, err)
+	assert.Equal(t, []*OrderV4{order}, orders)
+
+	orders, err = meshDB.FindOrdersV4ExpiredAt(big.NewInt(1548619324))
+	require.NoError(t, err)
+	assert.Len(t, orders, 0)
+
+	orders, err = meshDB.FindOrdersV4ExpiredAt(big.NewInt(1548619325))
+	require.NoError(t, err)
+	assert.Equal(t, []*OrderV4{order}, orders)
+
+	// Update
+	modifiedOrder := foundOrder
+	modifiedOrder.FillableTakerAssetAmount = big.NewInt(0)
+	modifiedOrder.IsRemoved = true
+	require.NoError(t, meshDB.OrdersV4.Update(modifiedOrder))
+	foundModifiedOrder := &OrderV4{}
+	require.NoError(t, meshDB.OrdersV4.FindByID(modifiedOrder.ID(), foundModifiedOrder))
+	assert.Equal(t, modifiedOrder, foundModifiedOrder)
+
+	removedOrders, err := meshDB.FindRemovedOrdersV4()
+	require.NoError(t, err)
+	assert.Equal(t, []*OrderV4{modifiedOrder}, removedOrders)
+
+	// Delete
+	require.NoError(t, meshDB.OrdersV4.Delete(foundModifiedOrder.ID()))
+	nonExistentOrder := &OrderV4{}
+	err = meshDB.OrdersV4.FindByID(foundModifiedOrder.ID(), nonExistentOrder)
+	assert.IsType(t, db.NotFoundError{}, err)
+}
+
+func TestTrimOrdersV4ByExpirationTime(t *testing.T) {
+	meshDB, err := New("/tmp/meshdb_testing/"+uuid.New().String(), contractAddresses)
+	require.NoError(t, err)
+	defer meshDB.Close()
+
+	// Orders 0 to 3 are unpinned and order 4 is pinned. The pinned order has the
+	// highest expiry but must never be removed.
+	orders := make([]*OrderV4, 5)
+	for i := range orders {
+		signedOrder, err := zeroex.SignTestOrderV4(&zeroex.OrderV4{
+			Type:                zeroex.LimitOrderTypeV4,
+			ChainID:             big.NewInt(constants.TestChainID),
+			VerifyingContract:   common.HexToAddress("0xdef1c0ded9bec7f1a1670819833240f027b25eff"),
+			MakerToken:          contractAddresses.ZRXToken,
+			TakerToken:          contractAddresses.WETH9,
+			MakerAmount:         big.NewInt(100),
+			TakerAmount:         big.NewInt(1),
+			TakerTokenFeeAmount: big.NewInt(0),
+			Maker:               constants.GanacheAccount0,
+			Expiry:              big.NewInt(int64(100 * (i + 1))),
+			Salt:                big.NewInt(int64(i)),
+		})
+		require.NoError(t, err)
+		orderHash, err := signedOrder.ComputeOrderHash()
+		require.NoError(t, err)
+		signedOrder.ResetHash()
+		orders[i] = &OrderV4{
+			Hash:                     orderHash,
+			SignedOrder:              signedOrder,
+			FillableTakerAssetAmount: big.NewInt(1),
+			LastUpdated:              time.Now().UTC(),
+			IsPinned:                 i == 4,
+		}
+		require.NoError(t, meshDB.OrdersV4.Insert(orders[i]))
+	}
+
+	newMaxExpirationTime, removedOrders, err := meshDB.TrimOrdersV4ByExpirationTime(3)
+	require.NoError(t, err)
+	assert.Equal(t, []*OrderV4{orders[3], orders[2]}, removedOrders)
+	assert.Equal(t, big.NewInt(299), newMaxExpirationTime)
+	count, err := meshDB.OrdersV4.Count()
+	require.NoError(t, err)
+	assert.Equal(t, 3, count)
+
+	numPinnedOrders, err := meshDB.CountPinnedOrdersV4()
+	require.NoError(t, err)
+	assert.Equal(t, 1, numPinnedOrders)
+
+	// Only the pinned order would remain, so the DB is filled with pinned orders.
+	// The unpinned orders are still removed and returned.
+	_, removedOrders, err = meshDB.TrimOrdersV4ByExpirationTime(0)
+	assert.Equal(t, ErrDBFilledWithPinnedOrders, err)
+	assert.Equal(t, []*OrderV4{orders[1], orders[0]}, removedOrders)
+}
@@ -0,0 +1,67 @@
+package meshdb
+
+import (
+	"time"
+
+	"github.com/0xProject/0x-mesh/db"
+)
+
+// OrdersyncCheckpoint is the database representation of the last successful
+// run of ordersync with a particular peer.
+type OrdersyncCheckpoint struct {
+	// PeerID is the ID of the peer which provided the orders.
+	PeerID string
+	// OrderFilterTopic identifies the order filter that was used during
+	// ordersync. A checkpoint is only valid for the same order filter.
+	OrderFilterTopic string
+	// LastSynced is the time, according to the provider's clock, up to which
+	// all orders have been received.
+	LastSynced time.Time
+}
+
+// ID returns the OrdersyncCheckpoint's ID
+func (c OrdersyncCheckpoint) ID() []byte {
+	return []byte(c.PeerID)
+}
+
+// OrdersyncCheckpointsCollection represents a DB collection of ordersync
+// checkpoints.
+type OrdersyncCheckpointsCollection struct {
+	*db.Collection
+}
+
+func setupOrdersyncCheckpoints(database *db.DB) (*OrdersyncCheckpointsCollection, error) {
+	col, err := database.NewCollection("ordersyncCheckpoint", &OrdersyncCheckpoint{})
+	if err != nil {
+		return nil, err
+	}
+	return &OrdersyncCheckpointsCollection{col}, nil
+}
+
+// GetOrdersyncCheckpoint returns the ordersync checkpoint for the given peer
+// (or a db.NotFoundError if there is no checkpoint for the peer).
+func (m *MeshDB) GetOrdersyncCheckpoint(peerID string) (*OrdersyncCheckpoint, error) {
+	var checkpoint OrdersyncCheckpoint
+	if err := m.OrdersyncCheckpoints.FindByID([]byte(peerID), &checkpoint); err != nil {
+		return nil, err
+	}
+	return &checkpoint, nil
+}
+
+// SaveOrdersyncCheckpoint inserts the given checkpoint into the database,
+// overwriting any existing checkpoint for the same peer.
+func (m *MeshDB) SaveOrdersyncCheckpoint(checkpoint *OrdersyncCheckpoint) error {
+	txn := m.OrdersyncCheckpoints.OpenTransaction()
+	defer func() {
+		_ = txn.Discard()
+	}()
+	if err := txn.Insert(checkpoint); err != nil {
+		if _, ok := err.(db.AlreadyExistsError); !ok {
+			return err
+		}
+		if err := txn.Update(checkpoint); err != nil {
+			return err
+		}
+	}
+	return txn.Commit()
+}
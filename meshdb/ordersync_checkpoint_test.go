@@ -0,0 +1,39 @@
+package meshdb
+
+import (
+	"testing"
+	"time"
+
+	"github.com/0xProject/0x-mesh/db"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrdersyncCheckpoints(t *testing.T) {
+	meshDB, err := New("/tmp/meshdb_testing/"+uuid.New().String(), contractAddresses)
+	require.NoError(t, err)
+	defer meshDB.Close()
+
+	peerID := "16Uiu2HAmGd949LwaV4KNvK2WDSiMVy7xEmW983VH75CMmefmMpP7"
+	_, err = meshDB.GetOrdersyncCheckpoint(peerID)
+	assert.IsType(t, db.NotFoundError{}, err)
+
+	// Insert
+	checkpoint := &OrdersyncCheckpoint{
+		PeerID:           peerID,
+		OrderFilterTopic: "/0x-orders/version/3/chain/1337/schema/e30=",
+		LastSynced:       time.Now().UTC(),
+	}
+	require.NoError(t, meshDB.SaveOrdersyncCheckpoint(checkpoint))
+	foundCheckpoint, err := meshDB.GetOrdersyncCheckpoint(peerID)
+	require.NoError(t, err)
+	assert.Equal(t, checkpoint, foundCheckpoint)
+
+	// Overwrite
+	checkpoint.LastSynced = checkpoint.LastSynced.Add(time.Hour)
+	require.NoError(t, meshDB.SaveOrdersyncCheckpoint(checkpoint))
+	foundCheckpoint, err = meshDB.GetOrdersyncCheckpoint(peerID)
+	require.NoError(t, err)
+	assert.Equal(t, checkpoint, foundCheckpoint)
+}
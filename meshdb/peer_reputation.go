@@ -0,0 +1,68 @@
+package meshdb
+
+import (
+	"github.com/0xProject/0x-mesh/db"
+	"github.com/0xProject/0x-mesh/p2p/reputation"
+)
+
+// PeerReputationsCollection represents a DB collection of peer and IP address
+// reputations.
+type PeerReputationsCollection struct {
+	*db.Collection
+}
+
+func setupPeerReputations(database *db.DB) (*PeerReputationsCollection, error) {
+	col, err := database.NewCollection("peerReputation", &reputation.Record{})
+	if err != nil {
+		return nil, err
+	}
+	return &PeerReputationsCollection{col}, nil
+}
+
+// GetPeerReputation returns the reputation record with the given kind and
+// subject (or a db.NotFoundError if there is no such record).
+func (m *MeshDB) GetPeerReputation(kind reputation.Kind, subject string) (*reputation.Record, error) {
+	record := &reputation.Record{Kind: kind, Subject: subject}
+	if err := m.PeerReputations.FindByID(record.ID(), record); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// SavePeerReputation inserts the given reputation record into the database,
+// overwriting any existing record for the same peer or IP address.
+func (m *MeshDB) SavePeerReputation(record *reputation.Record) error {
+	txn := m.PeerReputations.OpenTransaction()
+	defer func() {
+		_ = txn.Discard()
+	}()
+	if err := txn.Insert(record); err != nil {
+		if _, ok := err.(db.AlreadyExistsError); !ok {
+			return err
+		}
+		if err := txn.Update(record); err != nil {
+			return err
+		}
+	}
+	return txn.Commit()
+}
+
+// DeletePeerReputation deletes the given reputation record from the database.
+// It is a no-op if the record does not exist.
+func (m *MeshDB) DeletePeerReputation(record *reputation.Record) error {
+	if err := m.PeerReputations.Delete(record.ID()); err != nil {
+		if _, ok := err.(db.NotFoundError); !ok {
+			return err
+		}
+	}
+	return nil
+}
+
+// FindPeerReputations returns all stored reputation records.
+func (m *MeshDB) FindPeerReputations() ([]*reputation.Record, error) {
+	records := []*reputation.Record{}
+	if err := m.PeerReputations.FindAll(&records); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
@@ -0,0 +1,71 @@
+package meshdb
+
+import (
+	"testing"
+	"time"
+
+	"github.com/0xProject/0x-mesh/db"
+	"github.com/0xProject/0x-mesh/p2p/reputation"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPeerReputations(t *testing.T) {
+	meshDB, err := New("/tmp/meshdb_testing/"+uuid.New().String(), contractAddresses)
+	require.NoError(t, err)
+	defer meshDB.Close()
+
+	peerID := "16Uiu2HAmGd949LwaV4KNvK2WDSiMVy7xEmW983VH75CMmefmMpP7"
+	_, err = meshDB.GetPeerReputation(reputation.KindPeer, peerID)
+	assert.IsType(t, db.NotFoundError{}, err)
+
+	// Insert
+	now := time.Now().UTC()
+	record := &reputation.Record{
+		Kind:        reputation.KindPeer,
+		Subject:     peerID,
+		Tags:        map[string]float64{"invalid-message": -5},
+		LastUpdated: now,
+		History: []reputation.Event{
+			{Time: now, Tag: "invalid-message", Value: -5, Score: -5},
+		},
+	}
+	require.NoError(t, meshDB.SavePeerReputation(record))
+	foundRecord, err := meshDB.GetPeerReputation(reputation.KindPeer, peerID)
+	require.NoError(t, err)
+	assert.Equal(t, record, foundRecord)
+
+	// Records for IP addresses are stored separately.
+	ipRecord := &reputation.Record{
+		Kind:        reputation.KindIP,
+		Subject:     "1.2.3.4",
+		Tags:        map[string]float64{},
+		LastUpdated: now,
+		Banned:      true,
+		History:     []reputation.Event{},
+	}
+	require.NoError(t, meshDB.SavePeerReputation(ipRecord))
+
+	// Overwrite
+	record.Tags["invalid-message"] = -10
+	record.Banned = true
+	require.NoError(t, meshDB.SavePeerReputation(record))
+	foundRecord, err = meshDB.GetPeerReputation(reputation.KindPeer, peerID)
+	require.NoError(t, err)
+	assert.Equal(t, record, foundRecord)
+
+	records, err := meshDB.FindPeerReputations()
+	require.NoError(t, err)
+	assert.Len(t, records, 2)
+
+	// Delete
+	require.NoError(t, meshDB.DeletePeerReputation(record))
+	_, err = meshDB.GetPeerReputation(reputation.KindPeer, peerID)
+	assert.IsType(t, db.NotFoundError{}, err)
+	// Deleting a record which doesn't exist is a no-op.
+	require.NoError(t, meshDB.DeletePeerReputation(record))
+	records, err = meshDB.FindPeerReputations()
+	require.NoError(t, err)
+	assert.Len(t, records, 1)
+}
@@ -0,0 +1,113 @@
+// Package expr implements a small, typed expression language for filtering
+// 0x orders. It can express conditions which are hard or impossible to express
+// with JSON Schema, for example:
+//
+//    makerAssetAmount >= 1e18 &&
+//    takerAssetAmount / makerAssetAmount <= 0.0025 &&
+//    expirationTimeSeconds <= now() + 24h &&
+//    assetType(makerAssetData) == "ERC20"
+//
+// Expressions are type checked when they are compiled. There are three types:
+// numbers, strings and booleans. Numbers are arbitrary-precision rationals, so
+// comparisons and arithmetic on token amounts are exact. Duration literals
+// (e.g. 30s, 15m, 24h, 7d, 2w) are numbers of seconds and can be combined with
+// now() and expirationTimeSeconds. Addresses and asset data are lowercase,
+// 0x-prefixed hex strings.
+//
+// Every compiled expression has a canonical form (see Expression.String) which
+// does not depend on whitespace, redundant parentheses or the formatting of
+// literals. Two expressions with the same canonical form always match the same
+// orders, which makes the canonical form suitable for hashing and for deriving
+// topics and rendezvous points.
+package expr
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/0xProject/0x-mesh/zeroex"
+)
+
+// Type is the type of a value in an expression.
+type Type int
+
+const (
+	TypeBool Type = iota
+	TypeNumber
+	TypeString
+)
+
+func (t Type) String() string {
+	switch t {
+	case TypeBool:
+		return "bool"
+	case TypeNumber:
+		return "number"
+	case TypeString:
+		return "string"
+	default:
+		return fmt.Sprintf("Type(%d)", int(t))
+	}
+}
+
+// CompileError is returned by Compile if the expression is not valid.
+type CompileError struct {
+	// Pos is the byte offset in the source at which the error occurred.
+	Pos int
+	Msg string
+}
+
+func (e CompileError) Error() string {
+	return fmt.Sprintf("invalid filter expression at position %d: %s", e.Pos, e.Msg)
+}
+
+// Expression is a compiled filter expression.
+type Expression struct {
+	root node
+}
+
+// Compile parses and type checks the given source. The expression must
+// evaluate to a boolean.
+func Compile(source string) (*Expression, error) {
+	root, err := parse(source)
+	if err != nil {
+		return nil, err
+	}
+	if root.typ() != TypeBool {
+		return nil, CompileError{Pos: 0, Msg: fmt.Sprintf("expression must be a bool but is a %s", root.typ())}
+	}
+	return &Expression{root: root}, nil
+}
+
+// String returns the canonical form of the expression. Compiling the canonical
+// form results in an equivalent expression with the same canonical form.
+func (e *Expression) String() string {
+	return e.root.String()
+}
+
+// Evaluate returns true if the order matches the expression. now is the time
+// used for now(). It returns an error if the expression could not be
+// evaluated for the order, e.g. because of a division by zero or asset data
+// which could not be decoded.
+func (e *Expression) Evaluate(order *zeroex.SignedOrder, now time.Time) (bool, error) {
+	result, err := e.root.eval(&env{order: order, now: now})
+	if err != nil {
+		return false, err
+	}
+	return result.b, nil
+}
+
+// value is the result of evaluating a node. Only the field that corresponds to
+// the type of the node is set.
+type value struct {
+	b bool
+	n *big.Rat
+	s string
+}
+
+// env contains everything needed to evaluate an expression.
+type env struct {
+	order *zeroex.SignedOrder
+	now   time.Time
+}
@@ -0,0 +1,207 @@
+package expr
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/0xProject/0x-mesh/zeroex"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testNow            = time.Unix(1580000000, 0)
+	erc20AssetData     = hexutil.MustDecode("0xf47261b0000000000000000000000000e41d2489571d322189246dafa5ebde1f4699f498")
+	wethAssetData      = hexutil.MustDecode("0xf47261b0000000000000000000000000c02aaa39b223fe8d0a0e5c4f27ead9083c756cc2")
+	erc721AssetData    = hexutil.MustDecode("0x025717920000000000000000000000001dc4c1cefef38a777b15aa20260a54e584b16c480000000000000000000000000000000000000000000000000000000000000001")
+	oneEther, _        = new(big.Int).SetString("1000000000000000000", 10)
+	twoHundredEther, _ = new(big.Int).SetString("200000000000000000000", 10)
+)
+
+func newTestOrder() *zeroex.SignedOrder {
+	return &zeroex.SignedOrder{
+		Order: zeroex.Order{
+			ChainID:               big.NewInt(1337),
+			MakerAddress:          common.HexToAddress("0x6ecbe1db9ef729cbe972c83fb886247691fb6beb"),
+			MakerAssetData:        erc20AssetData,
+			MakerFeeAssetData:     []byte{},
+			MakerAssetAmount:      twoHundredEther,
+			MakerFee:              big.NewInt(0),
+			TakerAssetData:        wethAssetData,
+			TakerFeeAssetData:     []byte{},
+			TakerAssetAmount:      oneEther,
+			TakerFee:              big.NewInt(0),
+			ExpirationTimeSeconds: big.NewInt(testNow.Unix() + 60*60),
+			Salt:                  big.NewInt(1),
+		},
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		source      string
+		expectedPos int
+	}{
+		{source: "", expectedPos: 0},
+		{source: "makerAssetAmount", expectedPos: 0},
+		{source: "makerAssetAmount >= ", expectedPos: 20},
+		{source: "unknownField == 1", expectedPos: 0},
+		{source: "unknownFunction() == 1", expectedPos: 0},
+		{source: "makerAddress > 1", expectedPos: 13},
+		{source: "makerFee == \"0\"", expectedPos: 9},
+		{source: "!makerFee", expectedPos: 0},
+		{source: "0 < makerFee < 10", expectedPos: 13},
+		{source: "assetType(1) == \"ERC20\"", expectedPos: 0},
+		{source: "assetType() == \"ERC20\"", expectedPos: 0},
+		{source: "expirationTimeSeconds < now() + 1y", expectedPos: 33},
+		{source: "makerFee == 1 # 2", expectedPos: 14},
+		{source: "makerAddress == \"0x1", expectedPos: 16},
+		{source: "(makerFee == 1", expectedPos: 14},
+		{source: "makerFee == 1e101", expectedPos: 12},
+		{source: "makerFee == 1e-101", expectedPos: 12},
+		{source: "makerFee == 1e100000000", expectedPos: 12},
+		{source: "makerFee == 1e99999999999999999999", expectedPos: 12},
+		{source: "makerFee == " + strings.Repeat("1", maxNumberLength+1), expectedPos: 12},
+	}
+	for _, tc := range testCases {
+		_, err := Compile(tc.source)
+		require.Error(t, err, tc.source)
+		compileErr, ok := err.(CompileError)
+		require.True(t, ok, "expected CompileError for %q but got %T: %s", tc.source, err, err)
+		assert.Equal(t, tc.expectedPos, compileErr.Pos, "wrong position for %q: %s", tc.source, err)
+	}
+}
+
+func TestCanonicalForm(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		sources   []string
+		canonical string
+	}{
+		{
+			sources: []string{
+				"makerAssetAmount >= 1e18",
+				"  makerAssetAmount>=1000000000000000000 ",
+				"(makerAssetAmount >= 1.0e18)",
+			},
+			canonical: "(makerAssetAmount >= 1000000000000000000)",
+		},
+		{
+			sources: []string{
+				"expirationTimeSeconds <= now() + 24h",
+				"expirationTimeSeconds <= now() + 1d",
+				"expirationTimeSeconds<=(now()+86400)",
+			},
+			canonical: "(expirationTimeSeconds <= (now() + 86400))",
+		},
+		{
+			sources: []string{
+				"takerAssetAmount / makerAssetAmount <= 0.0025 && !(makerFee > 0)",
+				"((takerAssetAmount / makerAssetAmount) <= 25e-4) && (!(makerFee > 0))",
+			},
+			canonical: "(((takerAssetAmount / makerAssetAmount) <= 0.0025) && (!(makerFee > 0)))",
+		},
+		{
+			sources: []string{
+				`makerAddress == "0x6ECBE1DB9EF729CBE972C83FB886247691FB6BEB" || makerFee == -1`,
+				`(makerAddress == "0x6ecbe1db9ef729cbe972c83fb886247691fb6beb") || (makerFee == (-1))`,
+			},
+			canonical: `((makerAddress == "0x6ecbe1db9ef729cbe972c83fb886247691fb6beb") || (makerFee == (-1)))`,
+		},
+	}
+	for _, tc := range testCases {
+		for _, source := range tc.sources {
+			compiled, err := Compile(source)
+			require.NoError(t, err, source)
+			assert.Equal(t, tc.canonical, compiled.String(), source)
+
+			// Compiling the canonical form must not change it.
+			recompiled, err := Compile(compiled.String())
+			require.NoError(t, err, source)
+			assert.Equal(t, tc.canonical, recompiled.String(), source)
+		}
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		source   string
+		expected bool
+	}{
+		{source: "makerAssetAmount >= 1e18", expected: true},
+		{source: "makerAssetAmount >= 201e18", expected: false},
+		{source: "makerAssetAmount == 200000000000000000000", expected: true},
+		{source: "takerAssetAmount / makerAssetAmount == 0.005", expected: true},
+		{source: "takerAssetAmount / makerAssetAmount >= 0.004 && takerAssetAmount / makerAssetAmount <= 0.006", expected: true},
+		{source: "takerAssetAmount / makerAssetAmount < 0.005", expected: false},
+		{source: "expirationTimeSeconds <= now() + 24h", expected: true},
+		{source: "expirationTimeSeconds <= now() + 30m", expected: false},
+		{source: "expirationTimeSeconds - now() == 1h", expected: true},
+		{source: `makerAddress == "0x6ECBE1DB9EF729CBE972C83FB886247691FB6BEB"`, expected: true},
+		{source: `takerAddress == "0x0000000000000000000000000000000000000000"`, expected: true},
+		{source: `assetType(makerAssetData) == "ERC20"`, expected: true},
+		{source: `assetType(makerFeeAssetData) == ""`, expected: true},
+		{source: `assetAddress(takerAssetData) == "0xc02aaa39b223fe8d0a0e5c4f27ead9083c756cc2"`, expected: true},
+		{source: `assetAddress(makerAssetData) == "0xc02aaa39b223fe8d0a0e5c4f27ead9083c756cc2"`, expected: false},
+		{source: `makerAssetData == "0xf47261b0000000000000000000000000e41d2489571d322189246dafa5ebde1f4699f498"`, expected: true},
+		{source: "makerFee > 0 || !(takerFee > 0)", expected: true},
+		// The right-hand side is not evaluated because of short-circuiting.
+		{source: "false && makerAssetAmount / makerFee > 1", expected: false},
+		{source: "makerAssetAmount < 1e100 && makerAssetAmount > 1e-100", expected: true},
+	}
+	order := newTestOrder()
+	for _, tc := range testCases {
+		compiled, err := Compile(tc.source)
+		require.NoError(t, err, tc.source)
+		actual, err := compiled.Evaluate(order, testNow)
+		require.NoError(t, err, tc.source)
+		assert.Equal(t, tc.expected, actual, tc.source)
+	}
+}
+
+func TestEvaluateERC721(t *testing.T) {
+	t.Parallel()
+
+	order := newTestOrder()
+	order.MakerAssetData = erc721AssetData
+	compiled, err := Compile(`assetType(makerAssetData) == "ERC721" && assetAddress(makerAssetData) == "0x1dc4c1cefef38a777b15aa20260a54e584b16c48" && tokenId(makerAssetData) == 1`)
+	require.NoError(t, err)
+	actual, err := compiled.Evaluate(order, testNow)
+	require.NoError(t, err)
+	assert.True(t, actual)
+}
+
+func TestEvaluateErrors(t *testing.T) {
+	t.Parallel()
+
+	testCases := []string{
+		"makerAssetAmount / makerFee > 1",
+		"tokenId(makerAssetData) == 1",
+		`assetAddress(makerFeeAssetData) == "0x"`,
+		// The size of the results of arithmetic operations is bounded.
+		"makerAssetAmount * 1e100 * 1e100 * 1e100 * 1e100 > 0",
+		"makerAssetAmount / 3e100 / 3e100 / 3e100 / 3e100 > 0",
+	}
+	order := newTestOrder()
+	for _, source := range testCases {
+		compiled, err := Compile(source)
+		require.NoError(t, err, source)
+		_, err = compiled.Evaluate(order, testNow)
+		assert.Error(t, err, source)
+	}
+
+	compiled, err := Compile("makerFee == 0")
+	require.NoError(t, err)
+	order.MakerFee = nil
+	_, err = compiled.Evaluate(order, testNow)
+	assert.Error(t, err)
+}
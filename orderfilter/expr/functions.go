@@ -0,0 +1,231 @@
+package expr
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+
+	"github.com/0xProject/0x-mesh/zeroex"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// fieldInfo describes a field of an order which can be used in expressions.
+type fieldInfo struct {
+	t   Type
+	get func(order *zeroex.SignedOrder) (value, error)
+}
+
+func addressField(get func(order *zeroex.SignedOrder) common.Address) fieldInfo {
+	return fieldInfo{
+		t: TypeString,
+		get: func(order *zeroex.SignedOrder) (value, error) {
+			return value{s: strings.ToLower(get(order).Hex())}, nil
+		},
+	}
+}
+
+func bytesField(get func(order *zeroex.SignedOrder) []byte) fieldInfo {
+	return fieldInfo{
+		t: TypeString,
+		get: func(order *zeroex.SignedOrder) (value, error) {
+			return value{s: hexutil.Encode(get(order))}, nil
+		},
+	}
+}
+
+func numberField(name string, get func(order *zeroex.SignedOrder) *big.Int) fieldInfo {
+	return fieldInfo{
+		t: TypeNumber,
+		get: func(order *zeroex.SignedOrder) (value, error) {
+			n := get(order)
+			if n == nil {
+				return value{}, fmt.Errorf("order is missing %s", name)
+			}
+			return value{n: new(big.Rat).SetInt(n)}, nil
+		},
+	}
+}
+
+// fields are all the order fields which can be used in expressions. The
+// names match the JSON encoding of orders.
+var fields = map[string]fieldInfo{
+	"chainId":               numberField("chainId", func(o *zeroex.SignedOrder) *big.Int { return o.ChainID }),
+	"exchangeAddress":       addressField(func(o *zeroex.SignedOrder) common.Address { return o.ExchangeAddress }),
+	"makerAddress":          addressField(func(o *zeroex.SignedOrder) common.Address { return o.MakerAddress }),
+	"makerAssetData":        bytesField(func(o *zeroex.SignedOrder) []byte { return o.MakerAssetData }),
+	"makerFeeAssetData":     bytesField(func(o *zeroex.SignedOrder) []byte { return o.MakerFeeAssetData }),
+	"makerAssetAmount":      numberField("makerAssetAmount", func(o *zeroex.SignedOrder) *big.Int { return o.MakerAssetAmount }),
+	"makerFee":              numberField("makerFee", func(o *zeroex.SignedOrder) *big.Int { return o.MakerFee }),
+	"takerAddress":          addressField(func(o *zeroex.SignedOrder) common.Address { return o.TakerAddress }),
+	"takerAssetData":        bytesField(func(o *zeroex.SignedOrder) []byte { return o.TakerAssetData }),
+	"takerFeeAssetData":     bytesField(func(o *zeroex.SignedOrder) []byte { return o.TakerFeeAssetData }),
+	"takerAssetAmount":      numberField("takerAssetAmount", func(o *zeroex.SignedOrder) *big.Int { return o.TakerAssetAmount }),
+	"takerFee":              numberField("takerFee", func(o *zeroex.SignedOrder) *big.Int { return o.TakerFee }),
+	"senderAddress":         addressField(func(o *zeroex.SignedOrder) common.Address { return o.SenderAddress }),
+	"feeRecipientAddress":   addressField(func(o *zeroex.SignedOrder) common.Address { return o.FeeRecipientAddress }),
+	"expirationTimeSeconds": numberField("expirationTimeSeconds", func(o *zeroex.SignedOrder) *big.Int { return o.ExpirationTimeSeconds }),
+	"salt":                  numberField("salt", func(o *zeroex.SignedOrder) *big.Int { return o.Salt }),
+}
+
+// function describes a function which can be called in expressions.
+type function struct {
+	args   []Type
+	result Type
+	call   func(env *env, args []value) (value, error)
+}
+
+// functions are all the functions which can be called in expressions.
+var functions = map[string]function{
+	// now() returns the current Unix time in seconds.
+	"now": {
+		result: TypeNumber,
+		call: func(env *env, args []value) (value, error) {
+			return value{n: new(big.Rat).SetInt64(env.now.Unix())}, nil
+		},
+	},
+	// assetType(assetData) returns the type of the given asset data: "ERC20",
+	// "ERC721", "ERC1155", "MultiAsset", "StaticCall" or "ERC20Bridge". It
+	// returns an empty string for empty asset data (e.g. fee asset data for
+	// orders without fees).
+	"assetType": {
+		args:   []Type{TypeString},
+		result: TypeString,
+		call: func(env *env, args []value) (value, error) {
+			assetData, err := decodeHex(args[0].s)
+			if err != nil {
+				return value{}, err
+			}
+			if len(assetData) == 0 {
+				return value{s: ""}, nil
+			}
+			name, err := getAssetDataDecoder().GetName(assetData)
+			if err != nil {
+				return value{}, err
+			}
+			assetType, found := assetDataNameToType[name]
+			if !found {
+				return value{}, fmt.Errorf("unsupported asset data type: %s", name)
+			}
+			return value{s: assetType}, nil
+		},
+	},
+	// assetAddress(assetData) returns the token address of ERC20, ERC721,
+	// ERC1155 and ERC20Bridge asset data.
+	"assetAddress": {
+		args:   []Type{TypeString},
+		result: TypeString,
+		call: func(env *env, args []value) (value, error) {
+			assetData, err := decodeHex(args[0].s)
+			if err != nil {
+				return value{}, err
+			}
+			address, err := decodeAssetAddress(assetData)
+			if err != nil {
+				return value{}, err
+			}
+			return value{s: strings.ToLower(address.Hex())}, nil
+		},
+	},
+	// tokenId(assetData) returns the token ID of ERC721 asset data.
+	"tokenId": {
+		args:   []Type{TypeString},
+		result: TypeNumber,
+		call: func(env *env, args []value) (value, error) {
+			assetData, err := decodeHex(args[0].s)
+			if err != nil {
+				return value{}, err
+			}
+			var decoded zeroex.ERC721AssetData
+			if err := decodeAssetData(assetData, "ERC721Token", &decoded); err != nil {
+				return value{}, err
+			}
+			return value{n: new(big.Rat).SetInt(decoded.TokenId)}, nil
+		},
+	},
+}
+
+// assetDataNameToType maps the names used by zeroex.AssetDataDecoder to the
+// types returned by assetType.
+var assetDataNameToType = map[string]string{
+	"ERC20Token":    "ERC20",
+	"ERC721Token":   "ERC721",
+	"ERC1155Assets": "ERC1155",
+	"MultiAsset":    "MultiAsset",
+	"StaticCall":    "StaticCall",
+	"ERC20Bridge":   "ERC20Bridge",
+}
+
+var (
+	assetDataDecoder     *zeroex.AssetDataDecoder
+	assetDataDecoderOnce sync.Once
+)
+
+// getAssetDataDecoder returns a shared asset data decoder. Creating a decoder
+// is expensive because all the ABIs need to be parsed.
+func getAssetDataDecoder() *zeroex.AssetDataDecoder {
+	assetDataDecoderOnce.Do(func() {
+		assetDataDecoder = zeroex.NewAssetDataDecoder()
+	})
+	return assetDataDecoder
+}
+
+func decodeHex(s string) ([]byte, error) {
+	decoded, err := hexutil.Decode(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid hex string %q: %s", s, err.Error())
+	}
+	return decoded, nil
+}
+
+// decodeAssetData decodes the asset data into decoded if it has the expected
+// type.
+func decodeAssetData(assetData []byte, expectedName string, decoded interface{}) error {
+	decoder := getAssetDataDecoder()
+	name, err := decoder.GetName(assetData)
+	if err != nil {
+		return err
+	}
+	if name != expectedName {
+		return fmt.Errorf("expected %s asset data but got %s", expectedName, name)
+	}
+	return decoder.Decode(assetData, decoded)
+}
+
+func decodeAssetAddress(assetData []byte) (common.Address, error) {
+	decoder := getAssetDataDecoder()
+	name, err := decoder.GetName(assetData)
+	if err != nil {
+		return common.Address{}, err
+	}
+	switch name {
+	case "ERC20Token":
+		var decoded zeroex.ERC20AssetData
+		if err := decoder.Decode(assetData, &decoded); err != nil {
+			return common.Address{}, err
+		}
+		return decoded.Address, nil
+	case "ERC721Token":
+		var decoded zeroex.ERC721AssetData
+		if err := decoder.Decode(assetData, &decoded); err != nil {
+			return common.Address{}, err
+		}
+		return decoded.Address, nil
+	case "ERC1155Assets":
+		var decoded zeroex.ERC1155AssetData
+		if err := decoder.Decode(assetData, &decoded); err != nil {
+			return common.Address{}, err
+		}
+		return decoded.Address, nil
+	case "ERC20Bridge":
+		var decoded zeroex.ERC20BridgeAssetData
+		if err := decoder.Decode(assetData, &decoded); err != nil {
+			return common.Address{}, err
+		}
+		return decoded.TokenAddress, nil
+	default:
+		return common.Address{}, errors.New("assetAddress is not supported for " + name + " asset data")
+	}
+}
@@ -0,0 +1,270 @@
+package expr
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// node is a node in the syntax tree of an expression. String returns the
+// canonical form of the node.
+type node interface {
+	typ() Type
+	eval(env *env) (value, error)
+	String() string
+}
+
+type literal struct {
+	t Type
+	v value
+}
+
+func (l *literal) typ() Type { return l.t }
+
+func (l *literal) eval(*env) (value, error) { return l.v, nil }
+
+func (l *literal) String() string {
+	switch l.t {
+	case TypeBool:
+		return strconv.FormatBool(l.v.b)
+	case TypeNumber:
+		return formatNumber(l.v.n)
+	default:
+		return strconv.Quote(l.v.s)
+	}
+}
+
+// formatNumber formats a non-negative number as an exact decimal. Numbers in
+// expressions always have a finite decimal expansion because they are
+// written as decimals.
+func formatNumber(n *big.Rat) string {
+	if n.IsInt() {
+		return n.Num().String()
+	}
+	// The denominator is of the form 2^a * 5^b, so the number has
+	// max(a, b) decimal places.
+	places := 0
+	denom := new(big.Int).Set(n.Denom())
+	ten := big.NewInt(10)
+	for denom.Cmp(big.NewInt(1)) != 0 {
+		gcd := new(big.Int).GCD(nil, nil, denom, ten)
+		if gcd.Cmp(big.NewInt(1)) == 0 {
+			// Not a finite decimal. This can't happen for literals.
+			return n.String()
+		}
+		places++
+		denom.Quo(denom, gcd)
+	}
+	return n.FloatString(places)
+}
+
+type field struct {
+	name string
+	f    fieldInfo
+}
+
+func newField(tok token) (node, error) {
+	info, found := fields[tok.text]
+	if !found {
+		return nil, CompileError{Pos: tok.pos, Msg: fmt.Sprintf("unknown field %q", tok.text)}
+	}
+	return &field{name: tok.text, f: info}, nil
+}
+
+func (f *field) typ() Type { return f.f.t }
+
+func (f *field) eval(env *env) (value, error) { return f.f.get(env.order) }
+
+func (f *field) String() string { return f.name }
+
+type unary struct {
+	op      string
+	operand node
+}
+
+func newUnary(tok token, operand node) (node, error) {
+	expected := TypeNumber
+	if tok.text == "!" {
+		expected = TypeBool
+	}
+	if operand.typ() != expected {
+		return nil, CompileError{Pos: tok.pos, Msg: fmt.Sprintf("operator %q expects a %s but got a %s", tok.text, expected, operand.typ())}
+	}
+	return &unary{op: tok.text, operand: operand}, nil
+}
+
+func (u *unary) typ() Type { return u.operand.typ() }
+
+func (u *unary) eval(env *env) (value, error) {
+	v, err := u.operand.eval(env)
+	if err != nil {
+		return value{}, err
+	}
+	if u.op == "!" {
+		return value{b: !v.b}, nil
+	}
+	return value{n: new(big.Rat).Neg(v.n)}, nil
+}
+
+func (u *unary) String() string {
+	return "(" + u.op + u.operand.String() + ")"
+}
+
+type binary struct {
+	op          string
+	left, right node
+	t           Type
+}
+
+func newBinary(tok token, left, right node) (node, error) {
+	mismatch := func(expected string) error {
+		return CompileError{Pos: tok.pos, Msg: fmt.Sprintf("operator %q expects %s but got a %s and a %s", tok.text, expected, left.typ(), right.typ())}
+	}
+	switch tok.text {
+	case "&&", "||":
+		if left.typ() != TypeBool || right.typ() != TypeBool {
+			return nil, mismatch("bools")
+		}
+		return &binary{op: tok.text, left: left, right: right, t: TypeBool}, nil
+	case "==", "!=":
+		if left.typ() != right.typ() {
+			return nil, mismatch("operands of the same type")
+		}
+		return &binary{op: tok.text, left: left, right: right, t: TypeBool}, nil
+	case "<", "<=", ">", ">=":
+		if left.typ() != TypeNumber || right.typ() != TypeNumber {
+			return nil, mismatch("numbers")
+		}
+		return &binary{op: tok.text, left: left, right: right, t: TypeBool}, nil
+	default:
+		if left.typ() != TypeNumber || right.typ() != TypeNumber {
+			return nil, mismatch("numbers")
+		}
+		return &binary{op: tok.text, left: left, right: right, t: TypeNumber}, nil
+	}
+}
+
+func (b *binary) typ() Type { return b.t }
+
+var errDivisionByZero = errors.New("division by zero")
+
+// maxNumberBits is the maximum size in bits of the numerator and denominator
+// of the result of an arithmetic operation. Token amounts have at most 256
+// bits, so this is only exceeded by expressions which repeatedly multiply or
+// divide large numbers, whose cost would otherwise grow without bound.
+const maxNumberBits = 1024
+
+var errNumberTooLarge = fmt.Errorf("result of arithmetic operation exceeds %d bits", maxNumberBits)
+
+// checkNumberSize returns errNumberTooLarge if the numerator or denominator of n
+// exceeds maxNumberBits.
+func checkNumberSize(n *big.Rat) (value, error) {
+	if n.Num().BitLen() > maxNumberBits || n.Denom().BitLen() > maxNumberBits {
+		return value{}, errNumberTooLarge
+	}
+	return value{n: n}, nil
+}
+
+func (b *binary) eval(env *env) (value, error) {
+	left, err := b.left.eval(env)
+	if err != nil {
+		return value{}, err
+	}
+	// && and || short-circuit.
+	switch {
+	case b.op == "&&" && !left.b:
+		return value{b: false}, nil
+	case b.op == "||" && left.b:
+		return value{b: true}, nil
+	}
+	right, err := b.right.eval(env)
+	if err != nil {
+		return value{}, err
+	}
+	switch b.op {
+	case "&&", "||":
+		return value{b: right.b}, nil
+	case "==", "!=":
+		var equal bool
+		switch b.left.typ() {
+		case TypeBool:
+			equal = left.b == right.b
+		case TypeNumber:
+			equal = left.n.Cmp(right.n) == 0
+		default:
+			equal = left.s == right.s
+		}
+		return value{b: equal == (b.op == "==")}, nil
+	case "<":
+		return value{b: left.n.Cmp(right.n) < 0}, nil
+	case "<=":
+		return value{b: left.n.Cmp(right.n) <= 0}, nil
+	case ">":
+		return value{b: left.n.Cmp(right.n) > 0}, nil
+	case ">=":
+		return value{b: left.n.Cmp(right.n) >= 0}, nil
+	case "+":
+		return checkNumberSize(new(big.Rat).Add(left.n, right.n))
+	case "-":
+		return checkNumberSize(new(big.Rat).Sub(left.n, right.n))
+	case "*":
+		return checkNumberSize(new(big.Rat).Mul(left.n, right.n))
+	case "/":
+		if right.n.Sign() == 0 {
+			return value{}, errDivisionByZero
+		}
+		return checkNumberSize(new(big.Rat).Quo(left.n, right.n))
+	default:
+		return value{}, fmt.Errorf("unknown operator %q", b.op)
+	}
+}
+
+func (b *binary) String() string {
+	return "(" + b.left.String() + " " + b.op + " " + b.right.String() + ")"
+}
+
+type call struct {
+	name string
+	fn   function
+	args []node
+}
+
+func newCall(name token, args []node) (node, error) {
+	fn, found := functions[name.text]
+	if !found {
+		return nil, CompileError{Pos: name.pos, Msg: fmt.Sprintf("unknown function %q", name.text)}
+	}
+	if len(args) != len(fn.args) {
+		return nil, CompileError{Pos: name.pos, Msg: fmt.Sprintf("%s expects %d argument(s) but got %d", name.text, len(fn.args), len(args))}
+	}
+	for i, arg := range args {
+		if arg.typ() != fn.args[i] {
+			return nil, CompileError{Pos: name.pos, Msg: fmt.Sprintf("argument %d of %s must be a %s but is a %s", i+1, name.text, fn.args[i], arg.typ())}
+		}
+	}
+	return &call{name: name.text, fn: fn, args: args}, nil
+}
+
+func (c *call) typ() Type { return c.fn.result }
+
+func (c *call) eval(env *env) (value, error) {
+	args := make([]value, len(c.args))
+	for i, arg := range c.args {
+		v, err := arg.eval(env)
+		if err != nil {
+			return value{}, err
+		}
+		args[i] = v
+	}
+	return c.fn.call(env, args)
+}
+
+func (c *call) String() string {
+	args := make([]string, len(c.args))
+	for i, arg := range c.args {
+		args[i] = arg.String()
+	}
+	return c.name + "(" + strings.Join(args, ", ") + ")"
+}
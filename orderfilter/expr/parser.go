@@ -0,0 +1,443 @@
+package expr
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenNumber
+	tokenString
+	tokenIdent
+	tokenOperator
+)
+
+type token struct {
+	kind tokenKind
+	pos  int
+	text string
+	// number is set for tokenNumber and str is set for tokenString.
+	number *big.Rat
+	str    string
+}
+
+// durationUnits maps the suffix of a duration literal to its number of
+// seconds.
+var durationUnits = map[string]int64{
+	"s": 1,
+	"m": 60,
+	"h": 60 * 60,
+	"d": 24 * 60 * 60,
+	"w": 7 * 24 * 60 * 60,
+}
+
+const (
+	// maxNumberLength is the maximum length of a number literal (excluding
+	// its duration unit).
+	maxNumberLength = 100
+	// maxNumberExponent is the maximum absolute value of the exponent of a
+	// number literal. Expressions are received from peers and big.Rat does not
+	// limit the exponent, so 1e100000000 would exhaust CPU and memory.
+	maxNumberExponent = 100
+)
+
+// operators are all supported operators. Two character operators must come
+// first.
+var operators = []string{"&&", "||", "==", "!=", "<=", ">=", "<", ">", "!", "+", "-", "*", "/", "(", ")", ","}
+
+// lex splits the source into tokens. The last token is always tokenEOF.
+func lex(source string) ([]token, error) {
+	tokens := []token{}
+	pos := 0
+	for pos < len(source) {
+		c := rune(source[pos])
+		switch {
+		case unicode.IsSpace(c):
+			pos++
+		case c >= '0' && c <= '9' || c == '.':
+			tok, err := lexNumber(source, pos)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, tok)
+			pos += len(tok.text)
+		case c == '"':
+			tok, err := lexString(source, pos)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, tok)
+			pos += len(tok.text)
+		case isIdentStart(c):
+			end := pos + 1
+			for end < len(source) && isIdentPart(rune(source[end])) {
+				end++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, pos: pos, text: source[pos:end]})
+			pos = end
+		default:
+			found := false
+			for _, op := range operators {
+				if strings.HasPrefix(source[pos:], op) {
+					tokens = append(tokens, token{kind: tokenOperator, pos: pos, text: op})
+					pos += len(op)
+					found = true
+					break
+				}
+			}
+			if !found {
+				return nil, CompileError{Pos: pos, Msg: fmt.Sprintf("unexpected character %q", c)}
+			}
+		}
+	}
+	return append(tokens, token{kind: tokenEOF, pos: len(source)}), nil
+}
+
+func isIdentStart(c rune) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isIdentPart(c rune) bool {
+	return isIdentStart(c) || c >= '0' && c <= '9'
+}
+
+func isDigit(source string, pos int) bool {
+	return pos < len(source) && source[pos] >= '0' && source[pos] <= '9'
+}
+
+// lexNumber lexes a decimal number with an optional fraction, exponent and
+// duration unit, e.g. 10, 0.5, 1e18, 2.5e-3 or 24h. The length and the
+// exponent of the number are bounded by maxNumberLength and maxNumberExponent.
+func lexNumber(source string, start int) (token, error) {
+	pos := start
+	for isDigit(source, pos) {
+		pos++
+	}
+	if pos < len(source) && source[pos] == '.' {
+		pos++
+		if !isDigit(source, pos) {
+			return token{}, CompileError{Pos: start, Msg: "expected digit after decimal point"}
+		}
+		for isDigit(source, pos) {
+			pos++
+		}
+	}
+	if pos < len(source) && (source[pos] == 'e' || source[pos] == 'E') {
+		signStart := pos + 1
+		exponentStart := signStart
+		if exponentStart < len(source) && (source[exponentStart] == '+' || source[exponentStart] == '-') {
+			exponentStart++
+		}
+		if isDigit(source, exponentStart) {
+			pos = exponentStart
+			for isDigit(source, pos) {
+				pos++
+			}
+			exponent, err := strconv.Atoi(source[signStart:pos])
+			if err != nil || exponent > maxNumberExponent || exponent < -maxNumberExponent {
+				return token{}, CompileError{Pos: start, Msg: fmt.Sprintf("exponent of number %q must be between %d and %d", source[start:pos], -maxNumberExponent, maxNumberExponent)}
+			}
+		}
+	}
+	if pos-start > maxNumberLength {
+		return token{}, CompileError{Pos: start, Msg: fmt.Sprintf("number must not be longer than %d characters", maxNumberLength)}
+	}
+	number, ok := new(big.Rat).SetString(source[start:pos])
+	if !ok {
+		return token{}, CompileError{Pos: start, Msg: fmt.Sprintf("invalid number %q", source[start:pos])}
+	}
+	unitStart := pos
+	for pos < len(source) && isIdentPart(rune(source[pos])) {
+		pos++
+	}
+	if unit := source[unitStart:pos]; unit != "" {
+		seconds, found := durationUnits[unit]
+		if !found {
+			return token{}, CompileError{Pos: unitStart, Msg: fmt.Sprintf("unknown duration unit %q", unit)}
+		}
+		number.Mul(number, new(big.Rat).SetInt64(seconds))
+	}
+	return token{kind: tokenNumber, pos: start, text: source[start:pos], number: number}, nil
+}
+
+// lexString lexes a double-quoted string literal. It supports the same escape
+// sequences as Go string literals.
+func lexString(source string, start int) (token, error) {
+	pos := start + 1
+	for pos < len(source) {
+		switch source[pos] {
+		case '\\':
+			pos += 2
+			continue
+		case '"':
+			text := source[start : pos+1]
+			str, err := strconv.Unquote(text)
+			if err != nil {
+				return token{}, CompileError{Pos: start, Msg: fmt.Sprintf("invalid string %s", text)}
+			}
+			return token{kind: tokenString, pos: start, text: text, str: str}, nil
+		}
+		pos++
+	}
+	return token{}, CompileError{Pos: start, Msg: "unterminated string"}
+}
+
+// parser is a recursive descent parser. The grammar, from lowest to highest
+// precedence, is:
+//
+//    or             = and { "||" and }
+//    and            = not { "&&" not }
+//    not            = "!" not | comparison
+//    comparison     = additive [ ( "==" | "!=" | "<" | "<=" | ">" | ">=" ) additive ]
+//    additive       = multiplicative { ( "+" | "-" ) multiplicative }
+//    multiplicative = unary { ( "*" | "/" ) unary }
+//    unary          = "-" unary | primary
+//    primary        = number | string | "true" | "false" | identifier
+//                   | identifier "(" [ or { "," or } ] ")" | "(" or ")"
+//
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func parse(source string) (node, error) {
+	tokens, err := lex(source)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, CompileError{Pos: tok.pos, Msg: fmt.Sprintf("unexpected %q", tok.text)}
+	}
+	return root, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// acceptOperator consumes the next token and returns true if it is one of the
+// given operators.
+func (p *parser) acceptOperator(ops ...string) (token, bool) {
+	tok := p.peek()
+	if tok.kind != tokenOperator {
+		return tok, false
+	}
+	for _, op := range ops {
+		if tok.text == op {
+			return p.next(), true
+		}
+	}
+	return tok, false
+}
+
+func (p *parser) expectOperator(op string) error {
+	if tok, ok := p.acceptOperator(op); !ok {
+		if tok.kind == tokenEOF {
+			return CompileError{Pos: tok.pos, Msg: fmt.Sprintf("expected %q but reached end of expression", op)}
+		}
+		return CompileError{Pos: tok.pos, Msg: fmt.Sprintf("expected %q but got %q", op, tok.text)}
+	}
+	return nil
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		tok, ok := p.acceptOperator("||")
+		if !ok {
+			return left, nil
+		}
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		if left, err = newBinary(tok, left, right); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		tok, ok := p.acceptOperator("&&")
+		if !ok {
+			return left, nil
+		}
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		if left, err = newBinary(tok, left, right); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (p *parser) parseNot() (node, error) {
+	if tok, ok := p.acceptOperator("!"); ok {
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return newUnary(tok, operand)
+	}
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (node, error) {
+	left, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+	tok, ok := p.acceptOperator("==", "!=", "<", "<=", ">", ">=")
+	if !ok {
+		return left, nil
+	}
+	right, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+	if next, ok := p.acceptOperator("==", "!=", "<", "<=", ">", ">="); ok {
+		return nil, CompileError{Pos: next.pos, Msg: "comparisons cannot be chained; use && instead"}
+	}
+	return newBinary(tok, left, right)
+}
+
+func (p *parser) parseAdditive() (node, error) {
+	left, err := p.parseMultiplicative()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		tok, ok := p.acceptOperator("+", "-")
+		if !ok {
+			return left, nil
+		}
+		right, err := p.parseMultiplicative()
+		if err != nil {
+			return nil, err
+		}
+		if left, err = newBinary(tok, left, right); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (p *parser) parseMultiplicative() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		tok, ok := p.acceptOperator("*", "/")
+		if !ok {
+			return left, nil
+		}
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		if left, err = newBinary(tok, left, right); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if tok, ok := p.acceptOperator("-"); ok {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return newUnary(tok, operand)
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokenNumber:
+		return &literal{t: TypeNumber, v: value{n: tok.number}}, nil
+	case tokenString:
+		str := tok.str
+		if strings.HasPrefix(str, "0x") || strings.HasPrefix(str, "0X") {
+			// Addresses and asset data are always lowercase.
+			str = strings.ToLower(str)
+		}
+		return &literal{t: TypeString, v: value{s: str}}, nil
+	case tokenIdent:
+		switch tok.text {
+		case "true":
+			return &literal{t: TypeBool, v: value{b: true}}, nil
+		case "false":
+			return &literal{t: TypeBool, v: value{b: false}}, nil
+		}
+		if _, ok := p.acceptOperator("("); ok {
+			return p.parseCall(tok)
+		}
+		return newField(tok)
+	case tokenOperator:
+		if tok.text == "(" {
+			inner, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expectOperator(")"); err != nil {
+				return nil, err
+			}
+			return inner, nil
+		}
+		return nil, CompileError{Pos: tok.pos, Msg: fmt.Sprintf("unexpected %q", tok.text)}
+	default:
+		return nil, CompileError{Pos: tok.pos, Msg: "unexpected end of expression"}
+	}
+}
+
+// parseCall parses the arguments of a function call. The opening parenthesis
+// has already been consumed.
+func (p *parser) parseCall(name token) (node, error) {
+	args := []node{}
+	if _, ok := p.acceptOperator(")"); !ok {
+		for {
+			arg, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+			if _, ok := p.acceptOperator(","); !ok {
+				break
+			}
+		}
+		if err := p.expectOperator(")"); err != nil {
+			return nil, err
+		}
+	}
+	return newCall(name, args)
+}
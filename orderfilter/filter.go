@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/0xProject/0x-mesh/ethereum"
+	"github.com/0xProject/0x-mesh/orderfilter/expr"
 	"github.com/ethereum/go-ethereum/common"
 	jsonschema "github.com/xeipuuv/gojsonschema"
 )
@@ -39,11 +40,16 @@ type Filter struct {
 	orderSchema          *jsonschema.Schema
 	messageSchema        *jsonschema.Schema
 	exchangeAddress      common.Address
+	expression           *expr.Expression
 }
 
 // TODO(jalextowle): We do not need `contractAddresses` since we only use `contractAddresses.Exchange`.
 // In a future refactor, we should update this interface.
 func New(chainID int, customOrderSchema string, contractAddresses ethereum.ContractAddresses) (*Filter, error) {
+	expression, err := parseExpression(customOrderSchema)
+	if err != nil {
+		return nil, err
+	}
 	orderLoader, err := newLoader(chainID, customOrderSchema, contractAddresses)
 	if err != nil {
 		return nil, err
@@ -67,6 +73,7 @@ func New(chainID int, customOrderSchema string, contractAddresses ethereum.Contr
 		orderSchema:          compiledRootOrderSchema,
 		messageSchema:        compiledRootOrderMessageSchema,
 		exchangeAddress:      contractAddresses.Exchange,
+		expression:           expression,
 	}, nil
 }
 
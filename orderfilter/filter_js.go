@@ -9,6 +9,7 @@ import (
 	"syscall/js"
 
 	"github.com/0xProject/0x-mesh/ethereum"
+	"github.com/0xProject/0x-mesh/orderfilter/expr"
 	"github.com/0xProject/0x-mesh/packages/browser/go/jsutil"
 	"github.com/ethereum/go-ethereum/common"
 )
@@ -20,9 +21,14 @@ type Filter struct {
 	chainID              int
 	rawCustomOrderSchema string
 	exchangeAddress      common.Address
+	expression           *expr.Expression
 }
 
 func New(chainID int, customOrderSchema string, contractAddresses ethereum.ContractAddresses) (*Filter, error) {
+	expression, err := parseExpression(customOrderSchema)
+	if err != nil {
+		return nil, err
+	}
 	chainIDSchema := fmt.Sprintf(`{"$id": "/chainId", "const":%d}`, chainID)
 	exchangeAddressSchema := fmt.Sprintf(`{"$id": "/exchangeAddress", "enum":[%q,%q]}`, contractAddresses.Exchange.Hex(), strings.ToLower(contractAddresses.Exchange.Hex()))
 
@@ -61,5 +67,6 @@ func New(chainID int, customOrderSchema string, contractAddresses ethereum.Contr
 		chainID:              chainID,
 		rawCustomOrderSchema: customOrderSchema,
 		exchangeAddress:      contractAddresses.Exchange,
+		expression:           expression,
 	}, nil
 }
@@ -433,3 +433,134 @@ func TestDefaultOrderSchemaTopic(t *testing.T) {
 	expectedTopic := "/injective-0x-orders/version/3/chain/1337/schema/e30="
 	assert.Equal(t, expectedTopic, defaultTopic, "the topic for the default filter should not change")
 }
+
+func TestFilterExpression(t *testing.T) {
+	t.Parallel()
+
+	testFilterExpression(t, New)
+	testFilterExpression(t, generateDecodedFilter)
+}
+
+func testFilterExpression(t *testing.T, generateFilter func(int, string, ethereum.ContractAddresses) (*Filter, error)) {
+	// standardValidOrderJSON has a makerAssetAmount of 100e18 and a
+	// takerAssetAmount of 100000e18.
+	validateTestCases := []struct {
+		customOrderSchema string
+		orderJSON         []byte
+		expectedErrors    []string
+	}{
+		{
+			customOrderSchema: `{"expression":"makerAssetAmount >= 1e18"}`,
+			orderJSON:         standardValidOrderJSON,
+		},
+		{
+			customOrderSchema: `{"expression":"makerAssetAmount >= 101e18"}`,
+			orderJSON:         standardValidOrderJSON,
+			expectedErrors:    []string{"order does not match filter expression"},
+		},
+		{
+			customOrderSchema: `{"expression":"takerAssetAmount / makerAssetAmount == 1000 && assetType(makerAssetData) == \"ERC20\""}`,
+			orderJSON:         standardValidOrderJSON,
+		},
+		{
+			customOrderSchema: `{"expression":"makerAssetAmount / makerFee > 1"}`,
+			orderJSON:         standardValidOrderJSON,
+			expectedErrors:    []string{"could not evaluate filter expression"},
+		},
+		{
+			// Both the JSON Schema and the expression must match.
+			customOrderSchema: `{"properties":{"senderAddress":{"pattern":"0x00000000000000000000000000000000ba5eba11"}},"expression":"makerAssetAmount >= 1e18"}`,
+			orderJSON:         standardValidOrderJSON,
+			expectedErrors:    []string{"senderAddress"},
+		},
+		{
+			customOrderSchema: `{"properties":{"senderAddress":{"pattern":"0x00000000000000000000000000000000ba5eba11"}},"expression":"makerAssetAmount >= 1e18"}`,
+			orderJSON:         orderWithSpecificSenderAddressJSON,
+		},
+	}
+	for i, tc := range validateTestCases {
+		tcInfo := fmt.Sprintf("test case %d\nschema: %s", i, tc.customOrderSchema)
+		filter, err := generateFilter(constants.TestChainID, tc.customOrderSchema, contractAddresses)
+		require.NoError(t, err, tcInfo)
+		result, err := filter.ValidateOrderJSON(tc.orderJSON)
+		require.NoError(t, err, tcInfo)
+		assert.Equal(t, len(tc.expectedErrors) == 0, result.Valid(), tcInfo)
+		signedOrder := &zeroex.SignedOrder{}
+		require.NoError(t, signedOrder.UnmarshalJSON(tc.orderJSON))
+		isValid, err := filter.MatchOrder(signedOrder)
+		require.NoError(t, err, tcInfo)
+		assert.Equal(t, len(tc.expectedErrors) == 0, isValid, tcInfo)
+	loop:
+		for _, expectedErr := range tc.expectedErrors {
+			for _, actualErr := range result.Errors() {
+				if strings.Contains(actualErr.String(), expectedErr) {
+					continue loop
+				}
+			}
+			assert.Fail(t, fmt.Sprintf("missing expected error: %q\ngot errors: %s", expectedErr, result.Errors()), tcInfo)
+		}
+	}
+
+	invalidSchemas := []string{
+		`{"expression":"makerAssetAmount"}`,
+		`{"expression":"unknownField == 1"}`,
+		`{"expression":true}`,
+	}
+	for _, customOrderSchema := range invalidSchemas {
+		_, err := generateFilter(constants.TestChainID, customOrderSchema, contractAddresses)
+		assert.Error(t, err, customOrderSchema)
+	}
+}
+
+func TestFilterExpressionTopic(t *testing.T) {
+	t.Parallel()
+
+	// All of these schemas contain equivalent expressions and should result
+	// in the same topic.
+	equivalentSchemas := []string{
+		`{"expression":"makerAssetAmount >= 1e18 && expirationTimeSeconds <= now() + 24h"}`,
+		`{ "expression": "(makerAssetAmount>=1000000000000000000) && (expirationTimeSeconds <= now() + 1d)" }`,
+		`{"expression":"((makerAssetAmount >= 1000000000000000000) && (expirationTimeSeconds <= (now() + 86400)))"}`,
+	}
+	filter, err := New(constants.TestChainID, equivalentSchemas[0], contractAddresses)
+	require.NoError(t, err)
+	expectedTopic := filter.Topic()
+	expectedRendezvous := filter.Rendezvous()
+	for _, customOrderSchema := range equivalentSchemas {
+		filter, err := New(constants.TestChainID, customOrderSchema, contractAddresses)
+		require.NoError(t, err, customOrderSchema)
+		assert.Equal(t, expectedTopic, filter.Topic(), customOrderSchema)
+		assert.Equal(t, expectedRendezvous, filter.Rendezvous(), customOrderSchema)
+		newFilter, err := NewFromTopic(filter.Topic(), contractAddresses)
+		require.NoError(t, err, customOrderSchema)
+		assert.Equal(t, expectedTopic, newFilter.Topic(), customOrderSchema)
+	}
+
+	differentFilter, err := New(constants.TestChainID, `{"expression":"makerAssetAmount >= 2e18 && expirationTimeSeconds <= now() + 24h"}`, contractAddresses)
+	require.NoError(t, err)
+	assert.NotEqual(t, expectedTopic, differentFilter.Topic())
+}
+
+func TestFilterExpressionMatchOrderMessageJSON(t *testing.T) {
+	t.Parallel()
+
+	// The order in the message has a makerAssetAmount of 100e18 and a
+	// takerAssetAmount of 50e18.
+	orderMessageJSON := []byte(`{"messageType":"order","order":{"makerAddress":"0x6ecbe1db9ef729cbe972c83fb886247691fb6beb","makerAssetData":"0xf47261b0000000000000000000000000871dd7c2b4b25e1aa18728e9d5f2af4c4e431f5c","makerAssetAmount":"100000000000000000000","makerFee":"0","takerAddress":"0x0000000000000000000000000000000000000000","takerAssetData":"0xf47261b00000000000000000000000000b1ba0af832d7c05fd64161e0db78e85978e8082","takerAssetAmount":"50000000000000000000","takerFee":"0","senderAddress":"0x0000000000000000000000000000000000000000","exchangeAddress":"0x48bacb9266a570d521063ef5dd96e61686dbe788","feeRecipientAddress":"0xa258b39954cef5cb142fd567a46cddb31a670124","expirationTimeSeconds":"1575499721","salt":"1548619145450","makerFeeAssetData":"0x","takerFeeAssetData":"0x","chainId":1337,"signature":"0x1b0d147219c5c92262f0902727a8d72b09ea5165ac2ede14bccbfbf6559343d8305978e22516dc1ea75e10af2c8954cd45da562ec907ce5723a62728272c566a3f02"},"topics":["/injective-0x-orders/version/3/chain/1337/schema/e30="]}`)
+	testCases := []struct {
+		expression     string
+		expectedResult bool
+	}{
+		{expression: "takerAssetAmount / makerAssetAmount == 0.5", expectedResult: true},
+		{expression: "takerAssetAmount / makerAssetAmount < 0.5", expectedResult: false},
+		{expression: `assetAddress(takerAssetData) == "0x0b1ba0af832d7c05fd64161e0db78e85978e8082"`, expectedResult: true},
+	}
+	for _, tc := range testCases {
+		customOrderSchema := fmt.Sprintf(`{"expression":%q}`, tc.expression)
+		filter, err := New(constants.TestChainID, customOrderSchema, contractAddresses)
+		require.NoError(t, err, tc.expression)
+		actualResult, err := filter.MatchOrderMessageJSON(orderMessageJSON)
+		require.NoError(t, err, tc.expression)
+		assert.Equal(t, tc.expectedResult, actualResult, tc.expression)
+	}
+}
@@ -4,9 +4,13 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"time"
 
+	"github.com/0xProject/0x-mesh/encoding"
 	"github.com/0xProject/0x-mesh/ethereum"
+	"github.com/0xProject/0x-mesh/orderfilter/expr"
 	"github.com/0xProject/0x-mesh/zeroex"
 	"github.com/ethereum/go-ethereum/common"
 	canonicaljson "github.com/gibson042/canonicaljson-go"
@@ -33,6 +37,32 @@ func (e WrongTopicVersionError) Error() string {
 	return fmt.Sprintf("wrong topic version: expected %d but got %d", e.expectedVersion, e.actualVersion)
 }
 
+// expressionKeyword is the keyword in a custom order schema which contains a
+// filter expression (see package expr). JSON Schema validators ignore unknown
+// keywords, so the expression is only evaluated by the Filter itself.
+const expressionKeyword = "expression"
+
+type SchemaValidationError struct {
+	err error
+}
+
+func (s *SchemaValidationError) String() string {
+	return s.err.Error()
+}
+
+type SchemaValidationResult struct {
+	valid  bool
+	errors []*SchemaValidationError
+}
+
+func (s *SchemaValidationResult) Valid() bool {
+	return s.valid
+}
+
+func (s *SchemaValidationResult) Errors() []*SchemaValidationError {
+	return s.errors
+}
+
 func GetDefaultFilter(chainID int, contractAddresses ethereum.ContractAddresses) (*Filter, error) {
 	return New(chainID, DefaultCustomOrderSchema, contractAddresses)
 }
@@ -81,6 +111,12 @@ func NewFromTopic(topic string, contractAddresses ethereum.ContractAddresses) (*
 	return New(chainID, string(customOrderSchema), contractAddresses)
 }
 
+// CustomOrderSchema returns the custom order schema the filter was created
+// with.
+func (f *Filter) CustomOrderSchema() string {
+	return f.rawCustomOrderSchema
+}
+
 func (f *Filter) Rendezvous() string {
 	if f.encodedSchema == "" {
 		f.encodedSchema = f.generateEncodedSchema()
@@ -101,7 +137,27 @@ var _ pubsub.Validator = (&Filter{}).ValidatePubSubMessage
 
 // ValidatePubSubMessage is an implementation of pubsub.Validator and will
 // return true if the contents of the message pass the message JSON Schema.
+// Messages in the compact binary encoding are decoded and the orders they
+// contain are validated against the order JSON Schema instead. A batched
+// message is only valid if all of its orders are.
 func (f *Filter) ValidatePubSubMessage(ctx context.Context, sender peer.ID, msg *pubsub.Message) bool {
+	if encoding.IsCompactMessage(msg.Data) {
+		orders, err := encoding.RawMessageToOrders(msg.Data)
+		if err != nil {
+			return false
+		}
+		for _, order := range orders {
+			isValid, err := f.MatchOrder(order)
+			if err != nil {
+				log.WithError(err).Error("MatchOrder returned an error")
+				return false
+			}
+			if !isValid {
+				return false
+			}
+		}
+		return true
+	}
 	isValid, err := f.MatchOrderMessageJSON(msg.Data)
 	if err != nil {
 		log.WithError(err).Error("MatchOrderMessageJSON returned an error")
@@ -110,6 +166,76 @@ func (f *Filter) ValidatePubSubMessage(ctx context.Context, sender peer.ID, msg
 	return isValid
 }
 
+// parseExpression compiles the filter expression in the given custom order
+// schema. It returns nil if the schema does not contain an expression.
+func parseExpression(customOrderSchema string) (*expr.Expression, error) {
+	var schema map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(customOrderSchema), &schema); err != nil {
+		// Schemas which are not JSON objects can't contain an expression. Any
+		// other problems are reported by the JSON Schema validator.
+		return nil, nil
+	}
+	rawExpression, found := schema[expressionKeyword]
+	if !found {
+		return nil, nil
+	}
+	var source string
+	if err := json.Unmarshal(rawExpression, &source); err != nil {
+		return nil, errors.New(`"expression" in custom order schema must be a string`)
+	}
+	return expr.Compile(source)
+}
+
+// validateExpression adds an error to the result if the filter has an
+// expression and the order does not match it.
+func (f *Filter) validateExpression(order *zeroex.SignedOrder, result *SchemaValidationResult) {
+	if f.expression == nil {
+		return
+	}
+	matches, err := f.expression.Evaluate(order, time.Now())
+	if err != nil {
+		result.valid = false
+		result.errors = append(result.errors, &SchemaValidationError{fmt.Errorf("could not evaluate filter expression: %s", err.Error())})
+		return
+	}
+	if !matches {
+		result.valid = false
+		result.errors = append(result.errors, &SchemaValidationError{fmt.Errorf("order does not match filter expression: %s", f.expression)})
+	}
+}
+
+// validateExpressionJSON is like validateExpression but takes a JSON encoded
+// order.
+func (f *Filter) validateExpressionJSON(orderJSON []byte, result *SchemaValidationResult) {
+	if f.expression == nil {
+		return
+	}
+	order := &zeroex.SignedOrder{}
+	if err := order.UnmarshalJSON(orderJSON); err != nil {
+		result.valid = false
+		result.errors = append(result.errors, &SchemaValidationError{fmt.Errorf("could not decode order for filter expression: %s", err.Error())})
+		return
+	}
+	f.validateExpression(order, result)
+}
+
+// matchMessageExpression returns true if the order contained in the given
+// order message matches the filter expression.
+func (f *Filter) matchMessageExpression(messageJSON []byte) bool {
+	if f.expression == nil {
+		return true
+	}
+	var message struct {
+		Order json.RawMessage `json:"order"`
+	}
+	if err := json.Unmarshal(messageJSON, &message); err != nil {
+		return false
+	}
+	result := &SchemaValidationResult{valid: true}
+	f.validateExpressionJSON(message.Order, result)
+	return result.Valid()
+}
+
 func (f *Filter) generateEncodedSchema() string {
 	// Note(albrow): We use canonicaljson to eliminate any differences in spacing,
 	// formatting, and the order of field names. This ensures that two filters
@@ -130,8 +256,14 @@ func (f *Filter) generateEncodedSchema() string {
 	//         "foo":"bar"
 	//     }
 	//
+	// Filter expressions are replaced by their canonical form, so that
+	// expressions which only differ in spacing, redundant parentheses or the
+	// formatting of literals also encode to the same topic string.
 	var holder interface{} = struct{}{}
 	_ = canonicaljson.Unmarshal([]byte(f.rawCustomOrderSchema), &holder)
+	if schema, ok := holder.(map[string]interface{}); ok && f.expression != nil {
+		schema[expressionKeyword] = f.expression.String()
+	}
 	canonicalOrderSchemaJSON, _ := canonicaljson.Marshal(holder)
 	return base64.URLEncoding.EncodeToString(canonicalOrderSchemaJSON)
 }
@@ -3,12 +3,22 @@
 package orderfilter
 
 import (
+	"errors"
+
 	"github.com/0xProject/0x-mesh/zeroex"
 	jsonschema "github.com/xeipuuv/gojsonschema"
 )
 
-func (f *Filter) ValidateOrderJSON(orderJSON []byte) (*jsonschema.Result, error) {
-	return f.orderSchema.Validate(jsonschema.NewBytesLoader(orderJSON))
+func (f *Filter) ValidateOrderJSON(orderJSON []byte) (*SchemaValidationResult, error) {
+	jsonResult, err := f.orderSchema.Validate(jsonschema.NewBytesLoader(orderJSON))
+	if err != nil {
+		return nil, err
+	}
+	result := convertResult(jsonResult)
+	if result.valid {
+		f.validateExpressionJSON(orderJSON, result)
+	}
+	return result, nil
 }
 
 func (f *Filter) MatchOrderMessageJSON(messageJSON []byte) (bool, error) {
@@ -16,9 +26,30 @@ func (f *Filter) MatchOrderMessageJSON(messageJSON []byte) (bool, error) {
 	if err != nil {
 		return false, err
 	}
-	return result.Valid(), nil
+	if !result.Valid() {
+		return false, nil
+	}
+	return f.matchMessageExpression(messageJSON), nil
 }
 
-func (f *Filter) ValidateOrder(order *zeroex.SignedOrder) (*jsonschema.Result, error) {
-	return f.orderSchema.Validate(jsonschema.NewGoLoader(order))
+func (f *Filter) ValidateOrder(order *zeroex.SignedOrder) (*SchemaValidationResult, error) {
+	jsonResult, err := f.orderSchema.Validate(jsonschema.NewGoLoader(order))
+	if err != nil {
+		return nil, err
+	}
+	result := convertResult(jsonResult)
+	if result.valid {
+		f.validateExpression(order, result)
+	}
+	return result, nil
+}
+
+// convertResult converts a JSON Schema result into a SchemaValidationResult so
+// that errors from the filter expression can be added to it.
+func convertResult(jsonResult *jsonschema.Result) *SchemaValidationResult {
+	result := &SchemaValidationResult{valid: jsonResult.Valid()}
+	for _, resultErr := range jsonResult.Errors() {
+		result.errors = append(result.errors, &SchemaValidationError{errors.New(resultErr.String())})
+	}
+	return result
 }
@@ -9,27 +9,6 @@ import (
 	"github.com/0xProject/0x-mesh/zeroex"
 )
 
-type SchemaValidationError struct {
-	err error
-}
-
-func (s *SchemaValidationError) String() string {
-	return s.err.Error()
-}
-
-type SchemaValidationResult struct {
-	valid  bool
-	errors []*SchemaValidationError
-}
-
-func (s *SchemaValidationResult) Valid() bool {
-	return s.valid
-}
-
-func (s *SchemaValidationResult) Errors() []*SchemaValidationError {
-	return s.errors
-}
-
 // ValidateOrderJSON Validates a JSON encoded signed order using the AJV javascript library.
 // This libarary is used to increase the performance of Mesh nodes that run in the browser.
 func (f *Filter) ValidateOrderJSON(orderJSON []byte) (*SchemaValidationResult, error) {
@@ -44,7 +23,11 @@ func (f *Filter) ValidateOrderJSON(orderJSON []byte) (*SchemaValidationResult, e
 	for i := 0; i < jsErrors.Length(); i++ {
 		convertedErrors = append(convertedErrors, &SchemaValidationError{errors.New(jsErrors.Index(i).String())})
 	}
-	return &SchemaValidationResult{valid: valid, errors: convertedErrors}, nil
+	result := &SchemaValidationResult{valid: valid, errors: convertedErrors}
+	if result.valid {
+		f.validateExpressionJSON(orderJSON, result)
+	}
+	return result, nil
 }
 
 func (f *Filter) MatchOrderMessageJSON(messageJSON []byte) (bool, error) {
@@ -53,7 +36,10 @@ func (f *Filter) MatchOrderMessageJSON(messageJSON []byte) (bool, error) {
 	if !jsutil.IsNullOrUndefined(fatal) {
 		return false, errors.New(fatal.String())
 	}
-	return jsResult.Get("success").Bool(), nil
+	if !jsResult.Get("success").Bool() {
+		return false, nil
+	}
+	return f.matchMessageExpression(messageJSON), nil
 }
 
 func (f *Filter) ValidateOrder(order *zeroex.SignedOrder) (*SchemaValidationResult, error) {
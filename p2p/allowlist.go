@@ -0,0 +1,93 @@
+package p2p
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/sec"
+)
+
+// peerAllowlist is a set of peer IDs which are allowed to connect to the
+// node. If it is disabled, all peers are allowed.
+type peerAllowlist struct {
+	mut     sync.RWMutex
+	enabled bool
+	peerIDs map[peer.ID]struct{}
+}
+
+func newPeerAllowlist(peerIDs []peer.ID) *peerAllowlist {
+	allowlist := &peerAllowlist{}
+	if peerIDs != nil {
+		allowlist.set(peerIDs)
+	}
+	return allowlist
+}
+
+// set enables the allowlist and replaces all allowed peer IDs.
+func (a *peerAllowlist) set(peerIDs []peer.ID) {
+	a.mut.Lock()
+	defer a.mut.Unlock()
+	a.enabled = true
+	a.peerIDs = make(map[peer.ID]struct{}, len(peerIDs))
+	for _, peerID := range peerIDs {
+		a.peerIDs[peerID] = struct{}{}
+	}
+}
+
+// allows returns true if the given peer is allowed to connect.
+func (a *peerAllowlist) allows(peerID peer.ID) bool {
+	a.mut.RLock()
+	defer a.mut.RUnlock()
+	if !a.enabled {
+		return true
+	}
+	_, found := a.peerIDs[peerID]
+	return found
+}
+
+// errPeerNotAllowed is returned when a connection to or from a peer who is not
+// on the allowlist is rejected.
+var errPeerNotAllowed = errors.New("peer is not on the allowlist")
+
+// allowlistSecureTransport is a security transport which rejects connections
+// to and from peers who are not on the allowlist. Outgoing connections are
+// rejected before the handshake and incoming connections as soon as the
+// handshake has authenticated the remote peer, so rejected peers are never
+// added to the host.
+type allowlistSecureTransport struct {
+	sec.SecureTransport
+	allowlist *peerAllowlist
+}
+
+func newAllowlistSecureTransport(secureTransport sec.SecureTransport, allowlist *peerAllowlist) *allowlistSecureTransport {
+	return &allowlistSecureTransport{
+		SecureTransport: secureTransport,
+		allowlist:       allowlist,
+	}
+}
+
+// SecureInbound secures an incoming connection and rejects it if the remote
+// peer is not on the allowlist.
+func (t *allowlistSecureTransport) SecureInbound(ctx context.Context, insecure net.Conn) (sec.SecureConn, error) {
+	conn, err := t.SecureTransport.SecureInbound(ctx, insecure)
+	if err != nil {
+		return nil, err
+	}
+	if !t.allowlist.allows(conn.RemotePeer()) {
+		_ = conn.Close()
+		return nil, errPeerNotAllowed
+	}
+	return conn, nil
+}
+
+// SecureOutbound secures an outgoing connection to the given peer. It fails
+// without starting the handshake if the peer is not on the allowlist.
+func (t *allowlistSecureTransport) SecureOutbound(ctx context.Context, insecure net.Conn, p peer.ID) (sec.SecureConn, error) {
+	if !t.allowlist.allows(p) {
+		return nil, errPeerNotAllowed
+	}
+	return t.SecureTransport.SecureOutbound(ctx, insecure, p)
+}
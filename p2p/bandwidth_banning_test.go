@@ -54,8 +54,9 @@ func TestBandwidthChecker(t *testing.T) {
 
 	// Wait for node1 to receive the message.
 	expectedMessage := &Message{
-		From: node0.ID(),
-		Data: message,
+		From:  node0.ID(),
+		Data:  message,
+		Topic: testTopic,
 	}
 	expectMessage(t, node1, expectedMessage, 15*time.Second)
 
@@ -11,6 +11,7 @@ import (
 	"github.com/albrow/stringset"
 	"github.com/libp2p/go-libp2p-core/host"
 	"github.com/libp2p/go-libp2p-core/metrics"
+	"github.com/libp2p/go-libp2p-core/peer"
 	filter "github.com/libp2p/go-maddr-filter"
 	ma "github.com/multiformats/go-multiaddr"
 	log "github.com/sirupsen/logrus"
@@ -45,6 +46,9 @@ type Config struct {
 	BandwidthCounter       *metrics.BandwidthCounter
 	MaxBytesPerSecond      float64
 	LogBandwidthUsageStats bool
+	// OnBandwidthViolation is an optional function which is called every time
+	// a peer exceeds the bandwidth limit.
+	OnBandwidthViolation func(peer.ID)
 }
 
 func New(ctx context.Context, config Config) *Banner {
@@ -75,6 +79,32 @@ func (banner *Banner) ProtectIP(maddr ma.Multiaddr) error {
 	return nil
 }
 
+// UnprotectIP removes the IP address of the given Multiaddr from the list of
+// protected IP addresses. It does not ban the IP address. If the IP address is
+// not protected this is a no-op.
+func (banner *Banner) UnprotectIP(maddr ma.Multiaddr) error {
+	banner.protectedIPsMut.Lock()
+	defer banner.protectedIPsMut.Unlock()
+	ipNet, err := ipNetFromMaddr(maddr)
+	if err != nil {
+		return err
+	}
+	banner.protectedIPs.Remove(ipNet.IP.String())
+	return nil
+}
+
+// IsIPProtected returns true if the IP address of the given Multiaddr is
+// protected.
+func (banner *Banner) IsIPProtected(maddr ma.Multiaddr) bool {
+	banner.protectedIPsMut.RLock()
+	defer banner.protectedIPsMut.RUnlock()
+	ipNet, err := ipNetFromMaddr(maddr)
+	if err != nil {
+		return false
+	}
+	return banner.protectedIPs.Contains(ipNet.IP.String())
+}
+
 // BanIP adds the IP address of the given Multiaddr to the blacklist. The
 // node will no longer dial or accept connections from this IP address. However,
 // if the IP address is protected, calling BanIP will not ban the IP address and
@@ -170,6 +200,9 @@ func (banner *Banner) CheckBandwidthUsage() {
 		// them.
 		if stats.RateIn > banner.config.MaxBytesPerSecond {
 			numViolations := banner.violations.add(remotePeerID)
+			if banner.config.OnBandwidthViolation != nil {
+				banner.config.OnBandwidthViolation(remotePeerID)
+			}
 
 			// Check if the number of violations exceeds violationsBeforeBan.
 			if numViolations >= violationsBeforeBan {
@@ -216,7 +249,7 @@ func (banner *Banner) CheckBandwidthUsage() {
 }
 
 func ipNetFromMaddr(maddr ma.Multiaddr) (ipNet net.IPNet, err error) {
-	ip, err := ipFromMaddr(maddr)
+	ip, err := IPFromMaddr(maddr)
 	if err != nil {
 		return net.IPNet{}, err
 	}
@@ -227,7 +260,9 @@ func ipNetFromMaddr(maddr ma.Multiaddr) (ipNet net.IPNet, err error) {
 	}, nil
 }
 
-func ipFromMaddr(maddr ma.Multiaddr) (net.IP, error) {
+// IPFromMaddr returns the IP address of the given Multiaddr. It also supports
+// relayed addresses, in which case the IP address of the relay is returned.
+func IPFromMaddr(maddr ma.Multiaddr) (net.IP, error) {
 	var (
 		ip    net.IP
 		found bool
@@ -252,6 +287,14 @@ func ipFromMaddr(maddr ma.Multiaddr) (net.IP, error) {
 	return ip, nil
 }
 
+// MaddrFromIP returns a Multiaddr which only consists of the given IP address.
+func MaddrFromIP(ip net.IP) (ma.Multiaddr, error) {
+	if ip.To4() != nil {
+		return ma.NewMultiaddr("/ip4/" + ip.String())
+	}
+	return ma.NewMultiaddr("/ip6/" + ip.String())
+}
+
 var (
 	ipv4AllMask = net.IPMask{255, 255, 255, 255}
 	ipv6AllMask = net.IPMask{255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255}
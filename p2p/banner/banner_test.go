@@ -51,6 +51,17 @@ func TestIPNetFromMaddr(t *testing.T) {
 	}
 }
 
+func TestMaddrFromIP(t *testing.T) {
+	for _, ipString := range []string{"159.65.4.82", "fe80:cd00:0:cde:1257:0:211e:729c"} {
+		ip := net.ParseIP(ipString)
+		maddr, err := MaddrFromIP(ip)
+		require.NoError(t, err)
+		actual, err := IPFromMaddr(maddr)
+		require.NoError(t, err)
+		assert.True(t, ip.Equal(actual), "expected %s but got %s", ip, actual)
+	}
+}
+
 func newMaddr(t *testing.T, s string) ma.Multiaddr {
 	maddr, err := ma.NewMultiaddr(s)
 	require.NoError(t, err)
@@ -0,0 +1,289 @@
+// +build !js
+
+package p2p
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/host"
+	"github.com/libp2p/go-libp2p-core/network"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/peerstore"
+	"github.com/libp2p/go-libp2p-core/protocol"
+	reuseport "github.com/libp2p/go-reuseport"
+	ma "github.com/multiformats/go-multiaddr"
+	manet "github.com/multiformats/go-multiaddr-net"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// holePunchDelay is how long to wait after a relayed connection was opened
+	// before trying to establish a direct connection. It gives both peers time
+	// to exchange their addresses via the identify protocol.
+	holePunchDelay = 5 * time.Second
+	// holePunchAttempts is the number of times each address is punched.
+	holePunchAttempts = 3
+	// holePunchRetryInterval is the time between two punches.
+	holePunchRetryInterval = time.Second
+	// holePunchDialTimeout is the timeout for a single punch.
+	holePunchDialTimeout = 5 * time.Second
+	// holePunchConnectTimeout is the timeout for replacing a relayed connection
+	// with a direct one.
+	holePunchConnectTimeout = 30 * time.Second
+	// holePunchIdleTimeout is how long to wait for the streams on a relayed
+	// connection to be closed before giving up on replacing it.
+	holePunchIdleTimeout = 2 * time.Minute
+	// holePunchIdlePollInterval is how often to check whether a relayed
+	// connection has become idle.
+	holePunchIdlePollInterval = time.Second
+)
+
+// errRelayedConnBusy is returned by reconnectDirectly if the relayed connection
+// kept carrying streams which would be interrupted by closing it.
+var errRelayedConnBusy = errors.New("relayed connection did not become idle")
+
+// persistentProtocols are the protocols whose streams stay open for as long as
+// a connection is open. They are reopened on the direct connection, so their
+// streams do not prevent the relayed connection from being replaced.
+var persistentProtocols = map[protocol.ID]struct{}{
+	pubsubProtocolID: {},
+	DHTProtocolID:    {},
+}
+
+// holePuncher tries to replace relayed connections with direct connections.
+//
+// When a relayed connection is opened, both peers dial each other's public
+// TCP addresses from their own TCP listen port at roughly the same time. This
+// opens a mapping in the NAT of each peer, which allows the other peer's dial
+// to go through (TCP simultaneous open). If the peer with the lower peer ID
+// succeeds, it waits for the relayed connection to become idle, closes it and
+// reconnects using only the direct addresses. The other peer only punches, so that the peers don't dial
+// each other at the same time over libp2p.
+type holePuncher struct {
+	ctx        context.Context
+	host       host.Host
+	mut        sync.Mutex
+	inProgress map[peer.ID]struct{}
+	// punched contains the peers we have punched holes for.
+	punched *peerSet
+}
+
+func newHolePuncher(ctx context.Context, h host.Host, punched *peerSet) *holePuncher {
+	return &holePuncher{
+		ctx:        ctx,
+		host:       h,
+		inProgress: map[peer.ID]struct{}{},
+		punched:    punched,
+	}
+}
+
+// notifiee returns a network.Notifiee which starts punching whenever a
+// relayed connection is opened.
+func (h *holePuncher) notifiee() network.Notifiee {
+	return &network.NotifyBundle{
+		ConnectedF: func(_ network.Network, conn network.Conn) {
+			if isRelayedAddr(conn.RemoteMultiaddr()) {
+				go h.punch(conn.RemotePeer())
+			}
+		},
+	}
+}
+
+func (h *holePuncher) punch(peerID peer.ID) {
+	h.mut.Lock()
+	if _, found := h.inProgress[peerID]; found {
+		h.mut.Unlock()
+		return
+	}
+	h.inProgress[peerID] = struct{}{}
+	h.mut.Unlock()
+	defer func() {
+		h.mut.Lock()
+		delete(h.inProgress, peerID)
+		h.mut.Unlock()
+	}()
+
+	select {
+	case <-h.ctx.Done():
+		return
+	case <-time.After(holePunchDelay):
+	}
+	if h.hasDirectConn(peerID) {
+		return
+	}
+	localAddr, err := h.tcpListenAddr()
+	if err != nil {
+		log.WithError(err).Debug("cannot punch holes without a TCP listen address")
+		return
+	}
+	remoteAddrs := h.publicTCPAddrs(peerID)
+	if len(remoteAddrs) == 0 {
+		return
+	}
+	logger := log.WithField("remotePeerID", peerID.String())
+	isDialer := h.host.ID() < peerID
+	h.punched.add(peerID)
+
+	for i := 0; i < holePunchAttempts; i++ {
+		for _, remoteAddr := range remoteAddrs {
+			if err := dialFrom(h.ctx, localAddr, remoteAddr); err != nil {
+				logger.WithError(err).WithField("remoteAddr", remoteAddr.String()).Trace("hole punch failed")
+				continue
+			}
+			if !isDialer {
+				return
+			}
+			if err := h.reconnectDirectly(peerID, remoteAddrs); err != nil {
+				logger.WithError(err).Debug("could not replace relayed connection with direct connection")
+				return
+			}
+			logger.Debug("replaced relayed connection with direct connection")
+			return
+		}
+		select {
+		case <-h.ctx.Done():
+			return
+		case <-time.After(holePunchRetryInterval):
+		}
+	}
+}
+
+// hasDirectConn returns true if there is at least one connection to the peer
+// which is not relayed.
+func (h *holePuncher) hasDirectConn(peerID peer.ID) bool {
+	for _, conn := range h.host.Network().ConnsToPeer(peerID) {
+		if !isRelayedAddr(conn.RemoteMultiaddr()) {
+			return true
+		}
+	}
+	return false
+}
+
+// tcpListenAddr returns the local TCP address we are listening on.
+func (h *holePuncher) tcpListenAddr() (net.Addr, error) {
+	for _, addr := range h.host.Network().ListenAddresses() {
+		if _, err := addr.ValueForProtocol(ma.P_WS); err == nil {
+			continue
+		}
+		if _, err := addr.ValueForProtocol(ma.P_TCP); err != nil {
+			continue
+		}
+		return manet.ToNetAddr(addr)
+	}
+	return nil, errors.New("no TCP listen address")
+}
+
+// publicTCPAddrs returns the public, non-relayed TCP addresses of the peer.
+func (h *holePuncher) publicTCPAddrs(peerID peer.ID) []ma.Multiaddr {
+	addrs := []ma.Multiaddr{}
+	for _, addr := range h.host.Peerstore().Addrs(peerID) {
+		if isRelayedAddr(addr) || !manet.IsPublicAddr(addr) {
+			continue
+		}
+		if _, err := addr.ValueForProtocol(ma.P_WS); err == nil {
+			continue
+		}
+		if _, err := addr.ValueForProtocol(ma.P_TCP); err != nil {
+			continue
+		}
+		addrs = append(addrs, addr)
+	}
+	return addrs
+}
+
+// reconnectDirectly closes all connections to the peer and reconnects using
+// only the given direct addresses. If that fails, it reconnects through a
+// relay again.
+//
+// The swarm uses an existing connection for every dial to a peer, so the
+// direct connection can only be opened once the relayed connection is closed.
+// To avoid interrupting requests (e.g. ordersync), the relayed connection is
+// only closed once it carries no streams other than those of
+// persistentProtocols.
+func (h *holePuncher) reconnectDirectly(peerID peer.ID, directAddrs []ma.Multiaddr) error {
+	if err := h.waitForIdleRelayedConns(peerID); err != nil {
+		return err
+	}
+	ps := h.host.Peerstore()
+	relayedAddrs := []ma.Multiaddr{}
+	for _, addr := range ps.Addrs(peerID) {
+		if isRelayedAddr(addr) {
+			relayedAddrs = append(relayedAddrs, addr)
+		}
+	}
+	// Temporarily remove the relayed addresses so that they are not used when
+	// reconnecting.
+	ps.SetAddrs(peerID, relayedAddrs, 0)
+	if err := h.host.Network().ClosePeer(peerID); err != nil {
+		ps.AddAddrs(peerID, relayedAddrs, peerstore.RecentlyConnectedAddrTTL)
+		return err
+	}
+	ctx, cancel := context.WithTimeout(h.ctx, holePunchConnectTimeout)
+	defer cancel()
+	err := h.host.Connect(ctx, peer.AddrInfo{ID: peerID, Addrs: directAddrs})
+	ps.AddAddrs(peerID, relayedAddrs, peerstore.RecentlyConnectedAddrTTL)
+	if err == nil {
+		return nil
+	}
+	if reconnectErr := h.host.Connect(ctx, peer.AddrInfo{ID: peerID}); reconnectErr != nil {
+		log.WithError(reconnectErr).WithField("remotePeerID", peerID.String()).Warn("could not reconnect through relay after failed hole punch")
+	}
+	return err
+}
+
+// waitForIdleRelayedConns waits until the relayed connections to the peer
+// carry no streams other than those of persistentProtocols. It returns
+// errRelayedConnBusy if that does not happen within holePunchIdleTimeout.
+func (h *holePuncher) waitForIdleRelayedConns(peerID peer.ID) error {
+	timeout := time.After(holePunchIdleTimeout)
+	ticker := time.NewTicker(holePunchIdlePollInterval)
+	defer ticker.Stop()
+	for !h.relayedConnsAreIdle(peerID) {
+		select {
+		case <-h.ctx.Done():
+			return h.ctx.Err()
+		case <-timeout:
+			return errRelayedConnBusy
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
+
+func (h *holePuncher) relayedConnsAreIdle(peerID peer.ID) bool {
+	for _, conn := range h.host.Network().ConnsToPeer(peerID) {
+		if !isRelayedAddr(conn.RemoteMultiaddr()) {
+			continue
+		}
+		for _, stream := range conn.GetStreams() {
+			if _, found := persistentProtocols[stream.Protocol()]; !found {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// dialFrom opens a TCP connection from the given local address to the given
+// remote address and closes it immediately. The local port is shared with the
+// TCP listener, which is what opens the mapping in the NAT.
+func dialFrom(ctx context.Context, localAddr net.Addr, remoteAddr ma.Multiaddr) error {
+	dialNetwork, address, err := manet.DialArgs(remoteAddr)
+	if err != nil {
+		return err
+	}
+	dialer := net.Dialer{
+		Control:   reuseport.Control,
+		LocalAddr: localAddr,
+		Timeout:   holePunchDialTimeout,
+	}
+	conn, err := dialer.DialContext(ctx, dialNetwork, address)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
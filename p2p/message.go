@@ -11,6 +11,8 @@ type Message struct {
 	From peer.ID
 	// Data is the underlying data for the message.
 	Data []byte
+	// Topic is the topic on which the message was received.
+	Topic string
 }
 
 // MessageHandler is an interface responsible for validating and storing
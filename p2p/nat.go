@@ -0,0 +1,84 @@
+package p2p
+
+import (
+	"sync"
+
+	"github.com/libp2p/go-libp2p-core/network"
+	"github.com/libp2p/go-libp2p-core/peer"
+	ma "github.com/multiformats/go-multiaddr"
+	manet "github.com/multiformats/go-multiaddr-net"
+)
+
+// Reachability describes whether a node can be dialed by other peers.
+type Reachability string
+
+const (
+	// ReachabilityUnknown means that it has not been determined whether the
+	// node is reachable yet.
+	ReachabilityUnknown Reachability = "unknown"
+	// ReachabilityPublic means that other peers were able to dial the node
+	// directly.
+	ReachabilityPublic Reachability = "public"
+	// ReachabilityPrivate means that the node is behind a NAT or firewall and
+	// can only be reached through relays.
+	ReachabilityPrivate Reachability = "private"
+)
+
+// Reachability returns whether the node can be dialed by other peers.
+//
+// The host already runs an AutoNAT client for AutoRelay, which asks peers to
+// dial us back, but does not expose its status. Instead of running a second
+// AutoNAT client, we use its effects: the host only advertises relay addresses
+// once AutoNAT has determined that the node is private. Otherwise the node is
+// considered public as soon as a peer has dialed it directly from a public
+// address. Connections from peers we punched holes for don't count, since
+// they could only get through because we opened a mapping in our NAT.
+func (n *Node) Reachability() Reachability {
+	for _, addr := range n.host.Addrs() {
+		if isRelayedAddr(addr) {
+			return ReachabilityPrivate
+		}
+	}
+	for _, conn := range n.host.Network().Conns() {
+		remoteAddr := conn.RemoteMultiaddr()
+		if conn.Stat().Direction != network.DirInbound || isRelayedAddr(remoteAddr) || !manet.IsPublicAddr(remoteAddr) {
+			continue
+		}
+		if n.holePunchedPeers.contains(conn.RemotePeer()) {
+			continue
+		}
+		return ReachabilityPublic
+	}
+	return ReachabilityUnknown
+}
+
+// isRelayedAddr returns true if the given address is a circuit relay address.
+func isRelayedAddr(addr ma.Multiaddr) bool {
+	_, err := addr.ValueForProtocol(ma.P_CIRCUIT)
+	return err == nil
+}
+
+// peerSet is a set of peer IDs which is safe for concurrent use.
+type peerSet struct {
+	mut     sync.RWMutex
+	peerIDs map[peer.ID]struct{}
+}
+
+func newPeerSet() *peerSet {
+	return &peerSet{
+		peerIDs: map[peer.ID]struct{}{},
+	}
+}
+
+func (s *peerSet) add(peerID peer.ID) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	s.peerIDs[peerID] = struct{}{}
+}
+
+func (s *peerSet) contains(peerID peer.ID) bool {
+	s.mut.RLock()
+	defer s.mut.RUnlock()
+	_, found := s.peerIDs[peerID]
+	return found
+}
@@ -15,11 +15,15 @@ import (
 
 	"github.com/0xProject/0x-mesh/constants"
 	"github.com/0xProject/0x-mesh/p2p/banner"
+	"github.com/0xProject/0x-mesh/p2p/privnet"
 	"github.com/0xProject/0x-mesh/p2p/ratevalidator"
+	"github.com/0xProject/0x-mesh/p2p/rendezvous"
+	"github.com/0xProject/0x-mesh/p2p/reputation"
 	"github.com/0xProject/0x-mesh/p2p/validatorset"
 	"github.com/albrow/stringset"
 	lru "github.com/hashicorp/golang-lru"
 	libp2p "github.com/libp2p/go-libp2p"
+	circuit "github.com/libp2p/go-libp2p-circuit"
 	connmgr "github.com/libp2p/go-libp2p-connmgr"
 	p2pcrypto "github.com/libp2p/go-libp2p-core/crypto"
 	"github.com/libp2p/go-libp2p-core/host"
@@ -31,6 +35,7 @@ import (
 	discovery "github.com/libp2p/go-libp2p-discovery"
 	dht "github.com/libp2p/go-libp2p-kad-dht"
 	pubsub "github.com/libp2p/go-libp2p-pubsub"
+	secio "github.com/libp2p/go-libp2p-secio"
 	swarm "github.com/libp2p/go-libp2p-swarm"
 	filter "github.com/libp2p/go-maddr-filter"
 	ma "github.com/multiformats/go-multiaddr"
@@ -85,6 +90,12 @@ const (
 	// defaultPerPeerPubSubMessageBurst is the default value for
 	// PerPeerPubSubMessageBurst.
 	defaultPerPeerPubSubMessageBurst = maxShareBatch * 5
+	// bandwidthViolationTag is the tag used for the reputation penalty of peers
+	// who exceed the bandwidth limit.
+	bandwidthViolationTag = "bandwidth-violation"
+	// bandwidthViolationPenalty is the amount a peer's score is lowered each
+	// time they exceed the bandwidth limit.
+	bandwidthViolationPenalty = -10
 )
 
 // Node is the main type for the p2p package. It represents a particpant in the
@@ -99,14 +110,32 @@ type Node struct {
 	dht              *dht.IpfsDHT
 	routingDiscovery discovery.Discovery
 	pubsub           *pubsub.PubSub
-	sub              *pubsub.Subscription
+	validators       *validatorset.Set
+	topicsMut        sync.RWMutex
+	publishTopics    []string
+	subscriptions    map[string]*pubsub.Subscription
+	messages         chan *Message
 	banner           *banner.Banner
+	bandwidthCounter *metrics.BandwidthCounter
+	reputation       *reputation.Tracker
+	allowlist        *peerAllowlist
+	// publishLimiter paces the messages sent by PacedSendToTopics so that they
+	// don't exceed the per-peer rate limit of our peers.
+	publishLimiter *rate.Limiter
+	// rendezvousServers are the rendezvous servers which are used for peer
+	// discovery in addition to the DHT.
+	rendezvousServers []peer.AddrInfo
+	rendezvousClient  *rendezvous.Client
+	// holePunchedPeers are the peers we have punched holes for. Their inbound
+	// connections don't show that the node is publicly reachable.
+	holePunchedPeers *peerSet
 }
 
 // Config contains configuration options for a Node.
 type Config struct {
 	// SubscribeTopic is the topic to subscribe to for new messages. Only messages
-	// that are published on this topic will be received and processed.
+	// that are published on this topic (or on a topic added with AddTopic) will
+	// be received and processed.
 	SubscribeTopic string
 	// PublishTopics are the topics to publish messages to. Messages may be
 	// published to more than one topic (e.g. a topic for all orders and a topic
@@ -136,6 +165,11 @@ type Config struct {
 	// BootstrapList is a list of multiaddress strings to use for bootstrapping
 	// the DHT. If empty, the default list will be used.
 	BootstrapList []string
+	// RendezvousServers is an optional list of multiaddress strings (including
+	// the peer ID) of rendezvous servers. If set, the node registers its
+	// rendezvous points with the servers and asks them for peers before
+	// falling back to the DHT.
+	RendezvousServers []string
 	// DataDir is the directory to use for storing data.
 	DataDir string
 	// GlobalPubSubMessageLimit is the maximum number of messages per second that
@@ -158,11 +192,44 @@ type Config struct {
 	// is allowed to send at once through the GossipSub network. Any additional
 	// messages will be dropped.
 	PerPeerPubSubMessageBurst int
+	// PubSubMessageWeight optionally returns the number of units a GossipSub
+	// message counts toward the global and per-peer message limits (e.g. the
+	// number of orders in a batched message). If nil, every message counts as
+	// one unit.
+	PubSubMessageWeight func(data []byte) int
 	// CustomMessageValidator is a custom validator for GossipSub messages. All
 	// incoming and outgoing messages will be dropped unless they are valid
 	// according to this custom validator, which will be run in addition to the
 	// default validators.
 	CustomMessageValidator pubsub.Validator
+	// PrivateNetworkKey is an optional pre-shared key. If set, the node only
+	// connects to peers which use the same key, i.e. it joins a private
+	// network which is separate from the public network.
+	PrivateNetworkKey *privnet.PSK
+	// PeerAllowlist is an optional list of peer IDs. If it is not nil, the node
+	// rejects connections, GossipSub messages and streams from any peer which
+	// is not on the list. The list can be updated with SetPeerAllowlist.
+	PeerAllowlist []peer.ID
+	// ReputationStore is used to persist the reputation of peers and IP
+	// addresses across restarts. If nil, reputation is only kept in memory.
+	ReputationStore reputation.Store
+	// EnableNATPortMap determines whether the node tries to open a port in the
+	// NAT via UPnP or NAT-PMP, so that it can be dialed directly. It has no
+	// effect in the browser.
+	EnableNATPortMap bool
+	// EnableRelayHop determines whether the node acts as a circuit relay for
+	// other peers, i.e. whether it relays connections to nodes which can't be
+	// dialed directly. The node always uses relays itself if it is not
+	// reachable. Relay nodes are advertised via the DHT.
+	EnableRelayHop bool
+	// EnableAutoNATService determines whether the node helps other peers
+	// determine their reachability by dialing them back. It has no effect in
+	// the browser.
+	EnableAutoNATService bool
+	// EnableHolePunching determines whether the node tries to replace relayed
+	// connections with direct connections by punching holes in the NAT of both
+	// peers. It has no effect in the browser.
+	EnableHolePunching bool
 }
 
 func getPeerstoreDir(datadir string) string {
@@ -193,6 +260,10 @@ func New(ctx context.Context, config Config) (*Node, error) {
 	if config.PerPeerPubSubMessageBurst == 0 {
 		config.PerPeerPubSubMessageBurst = defaultPerPeerPubSubMessageBurst
 	}
+	rendezvousServers, err := BootstrapListToAddrInfos(config.RendezvousServers)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config.RendezvousServers: %s", err.Error())
+	}
 
 	// We need to declare the newDHT function ahead of time so we can use it in
 	// the libp2p.Routing option.
@@ -219,17 +290,38 @@ func New(ctx context.Context, config Config) (*Node, error) {
 	// Set up and append environment agnostic host options.
 	bandwidthCounter := metrics.NewBandwidthCounter()
 	connManager := connmgr.NewConnManager(peerCountLow, peerCountHigh, peerGraceDuration)
+	relayOpts := []circuit.RelayOpt{}
+	if config.EnableRelayHop {
+		relayOpts = append(relayOpts, circuit.OptHop)
+	}
 	opts = append(opts, []libp2p.Option{
 		libp2p.Routing(newDHT),
 		libp2p.ConnectionManager(connManager),
 		libp2p.Identity(config.PrivateKey),
 		libp2p.EnableAutoRelay(),
-		libp2p.EnableRelay(),
+		libp2p.EnableRelay(relayOpts...),
 		libp2p.BandwidthReporter(bandwidthCounter),
 		Filters(filters),
 	}...)
+	// Peers who are not on the allowlist are rejected as soon as their identity
+	// is known, i.e. when the security handshake completes for incoming
+	// connections and before dialing them for outgoing connections.
+	allowlist := newPeerAllowlist(config.PeerAllowlist)
 	if config.Insecure {
 		opts = append(opts, libp2p.NoSecurity)
+	} else {
+		secureTransport, err := secio.New(config.PrivateKey)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, libp2p.Security(secio.ID, newAllowlistSecureTransport(secureTransport, allowlist)))
+	}
+	if config.PrivateNetworkKey != nil {
+		protector, err := privnet.NewProtector(config.PrivateNetworkKey)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, libp2p.PrivateNetwork(protector))
 	}
 
 	// Initialize the host.
@@ -244,12 +336,6 @@ func New(ctx context.Context, config Config) (*Node, error) {
 		_ = basicHost.Close()
 	}()
 
-	// Set up the notifee.
-	basicHost.Network().Notify(&notifee{
-		ctx:         ctx,
-		connManager: connManager,
-	})
-
 	// Set up DHT for peer discovery.
 	routingDiscovery := discovery.NewRoutingDiscovery(kadDHT)
 
@@ -259,40 +345,92 @@ func New(ctx context.Context, config Config) (*Node, error) {
 	if err != nil {
 		return nil, err
 	}
-	if err := registerValidators(ctx, basicHost, config, ps); err != nil {
+	validators, err := newValidatorSet(ctx, basicHost, config, allowlist)
+	if err != nil {
+		return nil, err
+	}
+	if err := registerValidators(ps, validators, config); err != nil {
 		return nil, err
 	}
 
-	// Configure banner.
+	// Configure banner and reputation tracker.
+	var reputationTracker *reputation.Tracker
 	banner := banner.New(ctx, banner.Config{
 		Host:                   basicHost,
 		Filters:                filters,
 		BandwidthCounter:       bandwidthCounter,
 		MaxBytesPerSecond:      defaultMaxBytesPerSecond,
 		LogBandwidthUsageStats: true,
+		OnBandwidthViolation: func(id peer.ID) {
+			reputationTracker.AddScore(id, bandwidthViolationTag, bandwidthViolationPenalty)
+		},
 	})
+	reputationTracker, err = reputation.New(ctx, reputation.Config{
+		Host:        basicHost,
+		ConnManager: connManager,
+		Banner:      banner,
+		Store:       config.ReputationStore,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Set up the notifee.
+	basicHost.Network().Notify(&notifee{
+		ctx:         ctx,
+		connManager: connManager,
+		reputation:  reputationTracker,
+		allowlist:   allowlist,
+	})
+
+	// Set up NAT traversal.
+	holePunchedPeers := newPeerSet()
+	if err := startNATTraversal(ctx, basicHost, config, holePunchedPeers); err != nil {
+		return nil, err
+	}
 
 	// Create the Node.
 	node := &Node{
-		ctx:              ctx,
-		config:           config,
-		messageHandler:   config.MessageHandler,
-		host:             basicHost,
-		connManager:      connManager,
-		dht:              kadDHT,
-		routingDiscovery: routingDiscovery,
-		pubsub:           ps,
-		banner:           banner,
+		ctx:               ctx,
+		config:            config,
+		messageHandler:    config.MessageHandler,
+		host:              basicHost,
+		connManager:       connManager,
+		dht:               kadDHT,
+		routingDiscovery:  routingDiscovery,
+		pubsub:            ps,
+		validators:        validators,
+		publishTopics:     append([]string{}, config.PublishTopics...),
+		subscriptions:     map[string]*pubsub.Subscription{},
+		messages:          make(chan *Message),
+		banner:            banner,
+		bandwidthCounter:  bandwidthCounter,
+		reputation:        reputationTracker,
+		allowlist:         allowlist,
+		publishLimiter:    rate.NewLimiter(config.PerPeerPubSubMessageLimit, config.PerPeerPubSubMessageBurst),
+		rendezvousServers: rendezvousServers,
+		rendezvousClient:  rendezvous.NewClient(basicHost),
+		holePunchedPeers:  holePunchedPeers,
 	}
 
 	return node, nil
 }
 
-// registerValidators registers all the validators we use for incoming and
-// outgoing GossipSub messages.
-func registerValidators(ctx context.Context, basicHost host.Host, config Config, ps *pubsub.PubSub) error {
+// newValidatorSet returns the set of validators which is used for incoming and
+// outgoing GossipSub messages on all topics.
+func newValidatorSet(ctx context.Context, basicHost host.Host, config Config, allowlist *peerAllowlist) (*validatorset.Set, error) {
 	validators := validatorset.New()
 
+	// Add the peer allowlist validator. It checks both the peer who sent the
+	// message to us and the peer who originally published it.
+	validators.Add("peer allowlist", func(ctx context.Context, sender peer.ID, msg *pubsub.Message) bool {
+		if sender != basicHost.ID() && !allowlist.allows(sender) {
+			return false
+		}
+		author := msg.GetFrom()
+		return author == basicHost.ID() || allowlist.allows(author)
+	})
+
 	// Add the rate limiting validator.
 	rateValidator, err := ratevalidator.New(ctx, ratevalidator.Config{
 		MyPeerID:       basicHost.ID(),
@@ -301,17 +439,18 @@ func registerValidators(ctx context.Context, basicHost host.Host, config Config,
 		PerPeerLimit:   config.PerPeerPubSubMessageLimit,
 		PerPeerBurst:   config.PerPeerPubSubMessageBurst,
 		MaxMessageSize: constants.MaxOrderSizeInBytes,
+		MessageWeight:  config.PubSubMessageWeight,
 	})
 	if err != nil {
-		return err
+		return nil, err
 	}
 	validators.Add("message rate limiting", rateValidator.Validate)
+	return validators, nil
+}
 
-	// Add the custom validator if there is one.
-	if config.CustomMessageValidator != nil {
-		validators.Add("custom", config.CustomMessageValidator)
-	}
-
+// registerValidators registers the given validators and the custom validator
+// (if any) for all the topics that we publish and/or subscribe to.
+func registerValidators(ps *pubsub.PubSub, validators *validatorset.Set, config Config) error {
 	// Register the set of validators for all topics that we publish and/or
 	// subscribe to.
 	//
@@ -328,13 +467,33 @@ func registerValidators(ctx context.Context, basicHost host.Host, config Config,
 	// in practice in the current implementation.
 	allTopics := stringset.NewFromSlice(append(config.PublishTopics, config.SubscribeTopic))
 	for topic := range allTopics {
-		if err := ps.RegisterTopicValidator(topic, validators.Validate, pubsub.WithValidatorInline(true)); err != nil {
+		if err := registerTopicValidator(ps, validators, topic, config.CustomMessageValidator); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
+// registerTopicValidator registers a validator for the given topic which
+// passes only if all the given validators and the custom validator (if not
+// nil) pass.
+func registerTopicValidator(ps *pubsub.PubSub, validators *validatorset.Set, topic string, customValidator pubsub.Validator) error {
+	validator := validators.Validate
+	if customValidator != nil {
+		validator = func(ctx context.Context, sender peer.ID, msg *pubsub.Message) bool {
+			if !validators.Validate(ctx, sender, msg) {
+				return false
+			}
+			if !customValidator(ctx, sender, msg) {
+				log.WithField("validatorName", "custom").Trace("pubsub message validation failed")
+				return false
+			}
+			return true
+		}
+	}
+	return ps.RegisterTopicValidator(topic, validator, pubsub.WithValidatorInline(true))
+}
+
 func getPrivateKey(path string) (p2pcrypto.PrivKey, error) {
 	if path == "" {
 		// If path is empty, generate a new key.
@@ -439,6 +598,20 @@ func (n *Node) Start() error {
 		}
 	}()
 
+	// Register with the rendezvous servers after the same delay.
+	if len(n.rendezvousServers) > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case <-innerCtx.Done():
+				return
+			case <-time.After(advertiseDelay):
+			}
+			n.continuouslyRegisterWithRendezvousServers(innerCtx)
+		}()
+	}
+
 	// Start message handler loop.
 	messageHandlerErrChan := make(chan error, 1)
 	wg.Add(1)
@@ -488,24 +661,39 @@ func (n *Node) Start() error {
 
 // AddPeerScore adds diff to the current score for a given peer. Tag is a unique
 // identifier for the score. A peer's total score is the sum of the scores
-// associated with each tag. Peers that end up with a low total score will
-// eventually be disconnected.
+// associated with each tag. Scores decay over time and are persisted if the
+// Node was configured with a ReputationStore. Peers that end up with a low
+// total score will eventually be disconnected and peers with a very low total
+// score are banned.
 func (n *Node) AddPeerScore(id peer.ID, tag string, diff int) {
-	n.connManager.UpsertTag(id, tag, func(current int) int { return current + diff })
+	n.reputation.AddScore(id, tag, diff)
 }
 
 // SetPeerScore sets the current score for a given peer (overwriting any
 // previous value with the same tag). Tag is a unique identifier for the score.
 // A peer's total score is the sum of the scores associated with each tag. Peers
-// that end up with a low total score will eventually be disconnected.
+// that end up with a low total score will eventually be disconnected and peers
+// with a very low total score are banned.
 func (n *Node) SetPeerScore(id peer.ID, tag string, val int) {
-	n.connManager.TagPeer(id, tag, val)
+	n.reputation.SetScore(id, tag, val)
 }
 
 // UnsetPeerScore removes any scores associated with the given tag for a peer
 // (i.e., they will no longer be counted toward the peers total score).
 func (n *Node) UnsetPeerScore(id peer.ID, tag string) {
-	n.connManager.UntagPeer(id, tag)
+	n.reputation.UnsetScore(id, tag)
+}
+
+// PeerReputations returns the reputation of all known peers, sorted from the
+// lowest to the highest score.
+func (n *Node) PeerReputations() []*reputation.Record {
+	return n.reputation.Records(reputation.KindPeer)
+}
+
+// IPReputations returns the reputation of all known IP addresses, sorted from
+// the lowest to the highest score.
+func (n *Node) IPReputations() []*reputation.Record {
+	return n.reputation.Records(reputation.KindIP)
 }
 
 // GetNumPeers returns the number of peers the node is connected to
@@ -513,9 +701,33 @@ func (n *Node) GetNumPeers() int {
 	return n.connManager.GetInfo().ConnCount
 }
 
-// SetStreamHandler registers a handler for a custom protocol.
+// SetStreamHandler registers a handler for a custom protocol. Streams from
+// peers which are not on the peer allowlist are reset.
 func (n *Node) SetStreamHandler(pid protocol.ID, handler network.StreamHandler) {
-	n.host.SetStreamHandler(pid, handler)
+	n.host.SetStreamHandler(pid, func(stream network.Stream) {
+		if !n.allowlist.allows(stream.Conn().RemotePeer()) {
+			log.WithFields(map[string]interface{}{
+				"remotePeerID": stream.Conn().RemotePeer(),
+				"protocol":     pid,
+			}).Debug("rejecting stream from peer who is not on the allowlist")
+			_ = stream.Reset()
+			return
+		}
+		handler(stream)
+	})
+}
+
+// SetPeerAllowlist replaces the peer allowlist and enables it if it was not
+// enabled before. Connections to peers which are no longer allowed are
+// closed.
+func (n *Node) SetPeerAllowlist(peerIDs []peer.ID) {
+	n.allowlist.set(peerIDs)
+	for _, peerID := range n.host.Network().Peers() {
+		if !n.allowlist.allows(peerID) {
+			log.WithField("remotePeerID", peerID).Info("disconnecting from peer who was removed from the allowlist")
+			_ = n.host.Network().ClosePeer(peerID)
+		}
+	}
 }
 
 func (n *Node) NewStream(ctx context.Context, p peer.ID, pids ...protocol.ID) (network.Stream, error) {
@@ -528,6 +740,34 @@ func (n *Node) Neighbors() []peer.ID {
 	return n.host.Network().Peers()
 }
 
+// TopicPeers returns a list of peer IDs that this node is connected to and
+// that are subscribed to at least one of the given topics.
+func (n *Node) TopicPeers(topics []string) []peer.ID {
+	peerIDs := []peer.ID{}
+	seen := map[peer.ID]struct{}{}
+	for _, topic := range topics {
+		for _, peerID := range n.pubsub.ListPeers(topic) {
+			if _, found := seen[peerID]; found {
+				continue
+			}
+			seen[peerID] = struct{}{}
+			peerIDs = append(peerIDs, peerID)
+		}
+	}
+	return peerIDs
+}
+
+// PeerSupportsProtocol returns true if the given peer has advertised support
+// for the given protocol. Supported protocols are exchanged when a connection
+// is established, so this returns false for peers we have not connected to.
+func (n *Node) PeerSupportsProtocol(peerID peer.ID, pid protocol.ID) bool {
+	supported, err := n.host.Peerstore().SupportsProtocols(peerID, string(pid))
+	if err != nil {
+		return false
+	}
+	return len(supported) > 0
+}
+
 // Connect ensures there is a connection between this host and the peer with
 // given peerInfo. If there is not an active connection, Connect will dial the
 // peer, and block until a connection is open, timeout is exceeded, or an error
@@ -608,6 +848,17 @@ func (n *Node) findNewPeers(ctx context.Context) error {
 			"maxNewPeers":      maxNewPeers,
 			"rendezvousPoint":  rendezvousPoint,
 		}).Trace("looking for new peers")
+		if len(n.rendezvousServers) > 0 {
+			// Rendezvous servers converge much faster than the DHT, so we ask
+			// them first and only fall back to the DHT if we still need more
+			// peers.
+			n.findPeersWithRendezvousServers(ctx, rendezvousPoint, maxNewPeers)
+			currentPeerCount = n.connManager.GetInfo().ConnCount
+			if currentPeerCount >= peerCountLow {
+				return nil
+			}
+			maxNewPeers = peerCountLow - currentPeerCount
+		}
 		findPeersCtx, cancel := context.WithTimeout(ctx, defaultNetworkTimeout)
 		defer cancel()
 		peerChan, err := n.routingDiscovery.FindPeers(findPeersCtx, rendezvousPoint, discovery.Limit(maxNewPeers))
@@ -617,18 +868,7 @@ func (n *Node) findNewPeers(ctx context.Context) error {
 		connectCtx, cancel := context.WithTimeout(ctx, defaultNetworkTimeout)
 		defer cancel()
 		for peer := range peerChan {
-			if peer.ID == n.host.ID() || len(peer.Addrs) == 0 {
-				continue
-			}
-			log.WithFields(map[string]interface{}{
-				"peerInfo":        peer,
-				"rendezvousPoint": rendezvousPoint,
-			}).Trace("found peer via rendezvous")
-			if err := n.host.Connect(connectCtx, peer); err != nil {
-				// We still want to try connecting to the other peers. Log the error and
-				// keep going.
-				logPeerConnectionError(peer, err)
-			}
+			n.connectToDiscoveredPeer(connectCtx, peer, rendezvousPoint)
 		}
 	}
 
@@ -698,11 +938,17 @@ func (n *Node) receiveBatch(ctx context.Context) ([]*Message, error) {
 
 // Send sends a message continaing the given data to all connected peers.
 func (n *Node) Send(data []byte) error {
+	return n.SendToTopics(data, n.PublishTopics())
+}
+
+// SendToTopics sends a message containing the given data to all connected
+// peers which are subscribed to at least one of the given topics.
+func (n *Node) SendToTopics(data []byte, topics []string) error {
 	// Note: If there is an error, we still try to publish to any remaining
 	// topics. We always return the first error that was encountered (if any),
 	// which is assigned to firstErr.
 	var firstErr error
-	for _, topic := range n.config.PublishTopics {
+	for _, topic := range topics {
 		err := n.pubsub.Publish(topic, data)
 		if err != nil && firstErr == nil {
 			firstErr = err
@@ -711,19 +957,124 @@ func (n *Node) Send(data []byte) error {
 	return firstErr
 }
 
+// PacedSendToTopics is like SendToTopics but first blocks until sending the
+// message would not exceed the per-peer rate limit of peers with the same
+// PerPeerPubSubMessageLimit and PerPeerPubSubMessageBurst as this node. Peers
+// drop messages which exceed the limit, so large numbers of messages should be
+// sent with PacedSendToTopics. It returns an error if ctx is canceled first.
+func (n *Node) PacedSendToTopics(ctx context.Context, data []byte, topics []string) error {
+	// Note: A peer which is subscribed to more than one of the topics receives
+	// the message once for each topic, so the weight is counted for each one.
+	weight := 1
+	if n.config.PubSubMessageWeight != nil {
+		weight = n.config.PubSubMessageWeight(data)
+	}
+	if weight > n.publishLimiter.Burst() {
+		weight = n.publishLimiter.Burst()
+	}
+	for range topics {
+		if err := n.publishLimiter.WaitN(ctx, weight); err != nil {
+			return err
+		}
+	}
+	return n.SendToTopics(data, topics)
+}
+
+// PublishTopics returns the topics that messages are published to by Send.
+func (n *Node) PublishTopics() []string {
+	n.topicsMut.RLock()
+	defer n.topicsMut.RUnlock()
+	return append([]string{}, n.publishTopics...)
+}
+
+// AddTopic subscribes to the given topic and adds it to the topics that
+// messages are published to. Messages on the topic are validated by the
+// default validators and by validator (if not nil) instead of
+// Config.CustomMessageValidator. It is a no-op if the topic was already added.
+func (n *Node) AddTopic(topic string, validator pubsub.Validator) error {
+	n.topicsMut.Lock()
+	defer n.topicsMut.Unlock()
+	if topic == n.config.SubscribeTopic || n.subscriptions[topic] != nil {
+		return nil
+	}
+	if !stringset.NewFromSlice(n.publishTopics).Contains(topic) {
+		if err := registerTopicValidator(n.pubsub, n.validators, topic, validator); err != nil {
+			return err
+		}
+		n.publishTopics = append(n.publishTopics, topic)
+	}
+	return n.subscribe(topic)
+}
+
+// RemoveTopic unsubscribes from a topic that was added with AddTopic and stops
+// publishing messages to it. The topic from Config.SubscribeTopic cannot be
+// removed.
+func (n *Node) RemoveTopic(topic string) error {
+	n.topicsMut.Lock()
+	defer n.topicsMut.Unlock()
+	if topic == n.config.SubscribeTopic {
+		return fmt.Errorf("cannot remove subscribe topic: %q", topic)
+	}
+	sub, found := n.subscriptions[topic]
+	if !found {
+		return fmt.Errorf("topic was not added: %q", topic)
+	}
+	sub.Cancel()
+	delete(n.subscriptions, topic)
+	if stringset.NewFromSlice(n.config.PublishTopics).Contains(topic) {
+		// Keep publishing to topics from the config.
+		return nil
+	}
+	for i, publishTopic := range n.publishTopics {
+		if publishTopic == topic {
+			n.publishTopics = append(n.publishTopics[:i], n.publishTopics[i+1:]...)
+			break
+		}
+	}
+	return n.pubsub.UnregisterTopicValidator(topic)
+}
+
+// subscribe subscribes to the given topic and forwards all messages to
+// n.messages until the subscription is canceled. It must be called while
+// holding a lock on n.topicsMut.
+func (n *Node) subscribe(topic string) error {
+	sub, err := n.pubsub.Subscribe(topic)
+	if err != nil {
+		return err
+	}
+	n.subscriptions[topic] = sub
+	go func() {
+		for {
+			msg, err := sub.Next(n.ctx)
+			if err != nil {
+				// The subscription was canceled or the context is done.
+				return
+			}
+			select {
+			case n.messages <- &Message{From: msg.GetFrom(), Data: msg.Data, Topic: topic}:
+			case <-n.ctx.Done():
+				return
+			}
+		}
+	}()
+	return nil
+}
+
 // receive returns the next pending message. It blocks if no messages are
 // available. If the given context is canceled, it returns nil, ctx.Err().
 func (n *Node) receive(ctx context.Context) (*Message, error) {
-	if n.sub == nil {
-		var err error
-		n.sub, err = n.pubsub.Subscribe(n.config.SubscribeTopic)
-		if err != nil {
+	n.topicsMut.Lock()
+	if n.subscriptions[n.config.SubscribeTopic] == nil {
+		if err := n.subscribe(n.config.SubscribeTopic); err != nil {
+			n.topicsMut.Unlock()
 			return nil, err
 		}
 	}
-	msg, err := n.sub.Next(ctx)
-	if err != nil {
-		return nil, err
+	n.topicsMut.Unlock()
+	select {
+	case msg := <-n.messages:
+		return msg, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
 	}
-	return &Message{From: msg.GetFrom(), Data: msg.Data}, nil
 }
@@ -12,10 +12,13 @@ import (
 	"time"
 
 	"github.com/0xProject/0x-mesh/p2p/banner"
+	"github.com/0xProject/0x-mesh/p2p/privnet"
+	"github.com/0xProject/0x-mesh/p2p/rendezvous"
 	"github.com/google/uuid"
 	p2pcrypto "github.com/libp2p/go-libp2p-core/crypto"
 	p2pnet "github.com/libp2p/go-libp2p-core/network"
 	"github.com/libp2p/go-libp2p-core/peer"
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
 	ma "github.com/multiformats/go-multiaddr"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -215,17 +218,63 @@ func TestPingPong(t *testing.T) {
 	time.Sleep(5 * time.Second)
 
 	// Send ping from node0 to node1
-	pingMessage := &Message{From: node0.host.ID(), Data: []byte("ping\n")}
+	pingMessage := &Message{From: node0.host.ID(), Data: []byte("ping\n"), Topic: testTopic}
 	require.NoError(t, node0.Send(pingMessage.Data))
 	const pingPongTimeout = 20 * time.Second
 	expectMessage(t, node1, pingMessage, pingPongTimeout)
 
 	// Send pong from node1 to node0
-	pongMessage := &Message{From: node1.host.ID(), Data: []byte("pong\n")}
+	pongMessage := &Message{From: node1.host.ID(), Data: []byte("pong\n"), Topic: testTopic}
 	require.NoError(t, node1.Send(pongMessage.Data))
 	expectMessage(t, node0, pongMessage, pingPongTimeout)
 }
 
+func TestAddAndRemoveTopic(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	notifee := &testNotifee{
+		streams: make(chan p2pnet.Stream),
+	}
+	node0 := newTestNode(t, ctx, notifee)
+	node1 := newTestNode(t, ctx, notifee)
+	connectTestNodes(t, node0, node1)
+	waitForGossipSubStreams(t, ctx, notifee, 4, testStreamTimeout)
+
+	const otherTopic = "0x-mesh-testing-other-topic"
+	rejectBar := func(ctx context.Context, sender peer.ID, msg *pubsub.Message) bool {
+		return !bytes.Equal(msg.Data, []byte("bar\n"))
+	}
+	require.NoError(t, node0.AddTopic(otherTopic, nil))
+	require.NoError(t, node1.AddTopic(otherTopic, rejectBar))
+	assert.Equal(t, []string{testTopic, otherTopic}, node1.PublishTopics())
+	time.Sleep(5 * time.Second)
+
+	// Messages on the new topic are received and validated with the custom
+	// validator for that topic.
+	const topicTimeout = 20 * time.Second
+	require.NoError(t, node0.SendToTopics([]byte("bar\n"), []string{otherTopic}))
+	fooMessage := &Message{From: node0.host.ID(), Data: []byte("foo\n"), Topic: otherTopic}
+	require.NoError(t, node0.SendToTopics(fooMessage.Data, []string{otherTopic}))
+	receiveCtx, receiveCancel := context.WithTimeout(ctx, topicTimeout)
+	defer receiveCancel()
+	actual, err := node1.receive(receiveCtx)
+	require.NoError(t, err)
+	assert.Equal(t, fooMessage, actual)
+
+	require.NoError(t, node1.RemoveTopic(otherTopic))
+	assert.Equal(t, []string{testTopic}, node1.PublishTopics())
+	assert.Error(t, node1.RemoveTopic(otherTopic))
+	assert.Error(t, node1.RemoveTopic(testTopic))
+
+	// The subscribe topic still works after removing the other topic.
+	pingMessage := &Message{From: node0.host.ID(), Data: []byte("ping\n"), Topic: testTopic}
+	require.NoError(t, node0.Send(pingMessage.Data))
+	expectMessage(t, node1, pingMessage, topicTimeout)
+}
+
 func expectMessage(t *testing.T, node *Node, expected *Message, timeout time.Duration) {
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
@@ -389,6 +438,181 @@ func TestProtectIP(t *testing.T) {
 	require.NoError(t, node1.Connect(node0AddrInfo, testConnectionTimeout))
 }
 
+func TestPrivateNetwork(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	psk, err := privnet.GenerateKey()
+	require.NoError(t, err)
+	otherPSK, err := privnet.GenerateKey()
+	require.NoError(t, err)
+	newPrivateTestNode := func(psk *privnet.PSK) *Node {
+		return newTestNodeWithConfig(t, ctx, nil, Config{
+			SubscribeTopic:    testTopic,
+			PublishTopics:     []string{testTopic},
+			MessageHandler:    &dummyMessageHandler{},
+			RendezvousPoints:  testRendezvousPoints,
+			UseBootstrapList:  false,
+			DataDir:           "/tmp/0x-mesh/p2p-testing/" + uuid.New().String(),
+			PrivateNetworkKey: psk,
+		})
+	}
+	node0 := newPrivateTestNode(psk)
+	node1 := newPrivateTestNode(psk)
+	node2 := newPrivateTestNode(otherPSK)
+	node3 := newTestNode(t, ctx, nil)
+
+	// Nodes with the same PSK can connect to each other.
+	connectTestNodes(t, node0, node1)
+
+	// Nodes with a different PSK or without a PSK cannot.
+	node0AddrInfo := peer.AddrInfo{
+		ID:    node0.ID(),
+		Addrs: node0.Multiaddrs(),
+	}
+	require.Error(t, node2.Connect(node0AddrInfo, testConnectionTimeout))
+	require.Error(t, node3.Connect(node0AddrInfo, testConnectionTimeout))
+}
+
+func TestNATTraversalOptions(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	relayNode := newTestNodeWithConfig(t, ctx, nil, Config{
+		SubscribeTopic:       testTopic,
+		PublishTopics:        []string{testTopic},
+		MessageHandler:       &dummyMessageHandler{},
+		RendezvousPoints:     testRendezvousPoints,
+		UseBootstrapList:     false,
+		DataDir:              "/tmp/0x-mesh/p2p-testing/" + uuid.New().String(),
+		EnableRelayHop:       true,
+		EnableAutoNATService: true,
+	})
+	holePunchingNode := newTestNodeWithConfig(t, ctx, nil, Config{
+		SubscribeTopic:     testTopic,
+		PublishTopics:      []string{testTopic},
+		MessageHandler:     &dummyMessageHandler{},
+		RendezvousPoints:   testRendezvousPoints,
+		UseBootstrapList:   false,
+		DataDir:            "/tmp/0x-mesh/p2p-testing/" + uuid.New().String(),
+		EnableHolePunching: true,
+	})
+
+	// Reachability is unknown until AutoNAT has determined that a node is
+	// private or a peer has dialed it directly from a public address.
+	assert.Equal(t, ReachabilityUnknown, relayNode.Reachability())
+	assert.Equal(t, ReachabilityUnknown, holePunchingNode.Reachability())
+	connectTestNodes(t, holePunchingNode, relayNode)
+}
+
+func TestIsRelayedAddr(t *testing.T) {
+	t.Parallel()
+	directAddr, err := ma.NewMultiaddr("/ip4/1.2.3.4/tcp/60558")
+	require.NoError(t, err)
+	relayedAddr, err := ma.NewMultiaddr("/ip4/1.2.3.4/tcp/60558/p2p/QmcgpsyWgH8Y8ajJz1Cu72KnS5uo2Aa2LpzU7kinSupNKC/p2p-circuit")
+	require.NoError(t, err)
+	assert.False(t, isRelayedAddr(directAddr))
+	assert.True(t, isRelayedAddr(relayedAddr))
+}
+
+func TestPeerSet(t *testing.T) {
+	t.Parallel()
+	set := newPeerSet()
+	set.add(peer.ID("peer0"))
+	assert.True(t, set.contains(peer.ID("peer0")))
+	assert.False(t, set.contains(peer.ID("peer1")))
+}
+
+func TestRendezvousServers(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	serverNode := newTestNode(t, ctx, nil)
+	serverNode.SetStreamHandler(rendezvous.ProtocolID, rendezvous.NewServer(ctx, 0).HandleStream)
+	serverAddr := fmt.Sprintf("%s/ipfs/%s", serverNode.Multiaddrs()[0], serverNode.ID().Pretty())
+	newRendezvousTestNode := func() *Node {
+		return newTestNodeWithConfig(t, ctx, nil, Config{
+			SubscribeTopic:    testTopic,
+			PublishTopics:     []string{testTopic},
+			MessageHandler:    &dummyMessageHandler{},
+			RendezvousPoints:  testRendezvousPoints,
+			UseBootstrapList:  false,
+			DataDir:           "/tmp/0x-mesh/p2p-testing/" + uuid.New().String(),
+			RendezvousServers: []string{serverAddr},
+		})
+	}
+	node0 := newRendezvousTestNode()
+	node1 := newRendezvousTestNode()
+
+	// node1 finds node0 through the rendezvous server without using the DHT.
+	node0.registerWithRendezvousServers(ctx)
+	node1.findPeersWithRendezvousServers(ctx, testRendezvousPoints[0], 10)
+	assert.NotEmpty(t, node1.host.Network().ConnsToPeer(node0.ID()))
+
+	// Invalid rendezvous server addresses are rejected.
+	_, err := New(ctx, Config{
+		MessageHandler:    &dummyMessageHandler{},
+		RendezvousPoints:  testRendezvousPoints,
+		RendezvousServers: []string{"not a multiaddress"},
+	})
+	assert.Error(t, err)
+}
+
+func TestPeerAllowlist(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	node1 := newTestNode(t, ctx, nil)
+	node2 := newTestNode(t, ctx, nil)
+	node0 := newTestNodeWithConfig(t, ctx, nil, Config{
+		SubscribeTopic:   testTopic,
+		PublishTopics:    []string{testTopic},
+		MessageHandler:   &dummyMessageHandler{},
+		RendezvousPoints: testRendezvousPoints,
+		UseBootstrapList: false,
+		DataDir:          "/tmp/0x-mesh/p2p-testing/" + uuid.New().String(),
+		PeerAllowlist:    []peer.ID{node1.ID()},
+	})
+
+	// Peers on the allowlist can connect.
+	connectTestNodes(t, node1, node0)
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, p2pnet.Connected, node0.host.Network().Connectedness(node1.ID()))
+
+	// Connections from other peers are rejected during the handshake.
+	err := node2.Connect(peer.AddrInfo{
+		ID:    node0.ID(),
+		Addrs: node0.Multiaddrs(),
+	}, testConnectionTimeout)
+	assert.Error(t, err)
+	assert.NotEqual(t, p2pnet.Connected, node0.host.Network().Connectedness(node2.ID()))
+
+	// Connections to other peers are rejected before dialing them.
+	err = node0.Connect(peer.AddrInfo{
+		ID:    node2.ID(),
+		Addrs: node2.Multiaddrs(),
+	}, testConnectionTimeout)
+	assert.Error(t, err)
+	assert.NotEqual(t, p2pnet.Connected, node2.host.Network().Connectedness(node0.ID()))
+
+	// Peers who are removed from the allowlist are disconnected.
+	node0.SetPeerAllowlist([]peer.ID{node2.ID()})
+	waitForDisconnect(t, node0, node1.ID())
+	connectTestNodes(t, node2, node0)
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, p2pnet.Connected, node0.host.Network().Connectedness(node2.ID()))
+}
+
+func waitForDisconnect(t *testing.T, node *Node, peerID peer.ID) {
+	deadline := time.Now().Add(5 * time.Second)
+	for node.host.Network().Connectedness(peerID) == p2pnet.Connected {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s to be disconnected", peerID)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
 func TestRateValidatorGlobal(t *testing.T) {
 	t.Parallel()
 
@@ -586,3 +810,61 @@ loop:
 		}
 	}
 }
+
+func TestPacedSendToTopics(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Create a test notifee which will be used to detect new streams.
+	notifee := &testNotifee{
+		streams: make(chan p2pnet.Stream),
+	}
+
+	// Every message counts as 5 units (e.g. a batch of 5 orders), so without
+	// pacing node1 would drop all but the first 4 messages.
+	newConfig := func() Config {
+		return Config{
+			SubscribeTopic: testTopic,
+			PublishTopics:  []string{testTopic},
+			MessageHandler: newInMemoryMessageHandler(func(*Message) (bool, error) {
+				return true, nil
+			}),
+			RendezvousPoints:          testRendezvousPoints,
+			UseBootstrapList:          false,
+			DataDir:                   "/tmp/0x-mesh/p2p-testing/" + uuid.New().String(),
+			PerPeerPubSubMessageLimit: 20,
+			PerPeerPubSubMessageBurst: 20,
+			PubSubMessageWeight:       func([]byte) int { return 5 },
+		}
+	}
+	node0 := newTestNodeWithConfig(t, ctx, notifee, newConfig())
+	node1 := newTestNodeWithConfig(t, ctx, notifee, newConfig())
+	connectTestNodes(t, node0, node1)
+
+	// Wait for 2 GossipSub streams to open (1 connection).
+	waitForGossipSubStreams(t, ctx, notifee, 2, testStreamTimeout)
+
+	// HACK(albrow): Wait for GossipSub to finish initializing.
+	time.Sleep(2 * time.Second)
+
+	require.NoError(t, node0.receiveAndHandleMessages(ctx))
+	require.NoError(t, node1.receiveAndHandleMessages(ctx))
+
+	// node0 sends enough messages to exceed the burst of node1 five times over.
+	numMessages := node1.config.PerPeerPubSubMessageBurst
+	for i := 0; i < numMessages; i++ {
+		msg := []byte(fmt.Sprintf("node0_message_%d", i))
+		require.NoError(t, node0.PacedSendToTopics(ctx, msg, node0.PublishTopics()))
+	}
+
+	// HACK(albrow): Wait for GossipSub messages to fully propagate.
+	time.Sleep(1 * time.Second)
+
+	require.NoError(t, node1.receiveAndHandleMessages(ctx))
+
+	// All of the messages should have been received by node1.
+	node1MessageCount := node1.messageHandler.(*inMemoryMessageHandler).count()
+	assert.Equal(t, numMessages, node1MessageCount, "node1 received and stored the wrong number of messages")
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"time"
 
+	"github.com/0xProject/0x-mesh/p2p/reputation"
 	connmgr "github.com/libp2p/go-libp2p-connmgr"
 	p2pnet "github.com/libp2p/go-libp2p-core/network"
 	ma "github.com/multiformats/go-multiaddr"
@@ -22,6 +23,8 @@ const (
 type notifee struct {
 	ctx         context.Context
 	connManager *connmgr.BasicConnMgr
+	reputation  *reputation.Tracker
+	allowlist   *peerAllowlist
 }
 
 var _ p2pnet.Notifiee = &notifee{}
@@ -38,6 +41,22 @@ func (n *notifee) Connected(network p2pnet.Network, conn p2pnet.Conn) {
 		"remotePeerID":       conn.RemotePeer(),
 		"remoteMultiaddress": conn.RemoteMultiaddr(),
 	}).Trace("connected to peer")
+	// Connections to and from peers who are not on the allowlist are normally
+	// rejected by the security transport. This check catches connections that
+	// were opened without it (i.e. if Config.Insecure is true).
+	if !n.allowlist.allows(conn.RemotePeer()) {
+		log.WithFields(map[string]interface{}{
+			"remotePeerID":       conn.RemotePeer(),
+			"remoteMultiaddress": conn.RemoteMultiaddr(),
+		}).Debug("closing connection to peer who is not on the allowlist")
+		// Closing the connection triggers another notification, so don't block
+		// the current one.
+		go func() {
+			_ = conn.Close()
+		}()
+		return
+	}
+	n.reputation.PeerConnected(conn.RemotePeer(), conn.RemoteMultiaddr())
 }
 
 // Disconnected is called when a connection closed
@@ -11,6 +11,7 @@ import (
 
 	leveldbStore "github.com/ipfs/go-ds-leveldb"
 	libp2p "github.com/libp2p/go-libp2p"
+	autonatsvc "github.com/libp2p/go-libp2p-autonat-svc"
 	"github.com/libp2p/go-libp2p-core/host"
 	dht "github.com/libp2p/go-libp2p-kad-dht"
 	dhtopts "github.com/libp2p/go-libp2p-kad-dht/opts"
@@ -80,13 +81,31 @@ func getHostOptions(ctx context.Context, config Config) ([]libp2p.Option, error)
 	}
 	newWebsocketTransport := ws.NewWithOptions(ws.TLSClientConfig(tlsConfig))
 
-	return []libp2p.Option{
+	opts := []libp2p.Option{
 		libp2p.Transport(tcp.NewTCPTransport),
 		libp2p.Transport(newWebsocketTransport),
 		libp2p.ListenAddrs(tcpBindAddr, wsBindAddr),
 		libp2p.AddrsFactory(newAddrsFactory(advertiseAddrs)),
 		libp2p.Peerstore(pstore),
-	}, nil
+	}
+	if config.EnableNATPortMap {
+		opts = append(opts, libp2p.NATPortMap())
+	}
+	return opts, nil
+}
+
+// startNATTraversal starts the optional NAT traversal services which require
+// a running host. Peers we punch holes for are added to holePunchedPeers.
+func startNATTraversal(ctx context.Context, h host.Host, config Config, holePunchedPeers *peerSet) error {
+	if config.EnableAutoNATService {
+		if _, err := autonatsvc.NewAutoNATService(ctx, h); err != nil {
+			return err
+		}
+	}
+	if config.EnableHolePunching {
+		h.Network().Notify(newHolePuncher(ctx, h, holePunchedPeers).notifiee())
+	}
+	return nil
 }
 
 func getPubSubOptions() []pubsub.Option {
@@ -35,6 +35,12 @@ func getHostOptions(ctx context.Context, config Config) ([]libp2p.Option, error)
 	}, nil
 }
 
+// startNATTraversal is a no-op in the browser. Browser nodes can't accept
+// incoming connections, so they always rely on relays.
+func startNATTraversal(ctx context.Context, h host.Host, config Config, holePunchedPeers *peerSet) error {
+	return nil
+}
+
 func getPubSubOptions() []pubsub.Option {
 	return []pubsub.Option{
 		pubsub.WithValidateThrottle(64),
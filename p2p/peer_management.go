@@ -0,0 +1,119 @@
+package p2p
+
+import (
+	"sort"
+	"time"
+
+	"github.com/0xProject/0x-mesh/p2p/banner"
+	"github.com/libp2p/go-libp2p-core/metrics"
+	"github.com/libp2p/go-libp2p-core/peer"
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+// PeerStats holds information about a connected peer.
+type PeerStats struct {
+	ID peer.ID
+	// Multiaddrs are the remote addresses of all open connections to the peer.
+	Multiaddrs []ma.Multiaddr
+	// Protocols are the protocols the peer has advertised support for.
+	Protocols []string
+	// Latency is an exponentially-weighted moving average of the observed
+	// latency to the peer.
+	Latency time.Duration
+	// Score is the current reputation score of the peer.
+	Score     float64
+	Bandwidth metrics.Stats
+	// Protected is true if either the peer or one of the IP addresses it is
+	// connected from is protected.
+	Protected bool
+}
+
+// GetPeerStats returns information about all connected peers, sorted by peer
+// ID.
+func (n *Node) GetPeerStats() []*PeerStats {
+	peerIDs := n.host.Network().Peers()
+	sort.Slice(peerIDs, func(i, j int) bool {
+		return peerIDs[i] < peerIDs[j]
+	})
+	allStats := make([]*PeerStats, 0, len(peerIDs))
+	for _, peerID := range peerIDs {
+		stats := &PeerStats{
+			ID:         peerID,
+			Multiaddrs: []ma.Multiaddr{},
+			Protocols:  []string{},
+			Latency:    n.host.Peerstore().LatencyEWMA(peerID),
+			Score:      n.reputation.Score(peerID),
+			Bandwidth:  n.bandwidthCounter.GetBandwidthForPeer(peerID),
+			Protected:  n.reputation.IsPeerProtected(peerID),
+		}
+		for _, conn := range n.host.Network().ConnsToPeer(peerID) {
+			remoteAddr := conn.RemoteMultiaddr()
+			stats.Multiaddrs = append(stats.Multiaddrs, remoteAddr)
+			if n.banner.IsIPProtected(remoteAddr) {
+				stats.Protected = true
+			}
+		}
+		if protocols, err := n.host.Peerstore().GetProtocols(peerID); err == nil {
+			sort.Strings(protocols)
+			stats.Protocols = protocols
+		}
+		allStats = append(allStats, stats)
+	}
+	return allStats
+}
+
+// DisconnectPeer closes all connections to the given peer. The peer is not
+// banned and may reconnect.
+func (n *Node) DisconnectPeer(id peer.ID) error {
+	return n.host.Network().ClosePeer(id)
+}
+
+// BanIP bans the IP address of the given Multiaddr and closes the connections
+// to all peers connected from it. Bans are lifted when the node is restarted.
+// It returns an error if the IP address is protected.
+func (n *Node) BanIP(maddr ma.Multiaddr) error {
+	if err := n.banner.BanIP(maddr); err != nil {
+		return err
+	}
+	bannedIP, err := banner.IPFromMaddr(maddr)
+	if err != nil {
+		return err
+	}
+	for _, conn := range n.host.Network().Conns() {
+		remoteIP, err := banner.IPFromMaddr(conn.RemoteMultiaddr())
+		if err != nil || !remoteIP.Equal(bannedIP) {
+			continue
+		}
+		_ = n.host.Network().ClosePeer(conn.RemotePeer())
+	}
+	return nil
+}
+
+// UnbanIP lifts the ban for the IP address of the given Multiaddr.
+func (n *Node) UnbanIP(maddr ma.Multiaddr) error {
+	return n.banner.UnbanIP(maddr)
+}
+
+// ProtectPeer persistently protects the given peer. Protected peers are never
+// banned or pruned by the connection manager.
+func (n *Node) ProtectPeer(id peer.ID) error {
+	return n.reputation.ProtectPeer(id)
+}
+
+// UnprotectPeer removes the protection for the given peer.
+func (n *Node) UnprotectPeer(id peer.ID) error {
+	return n.reputation.UnprotectPeer(id)
+}
+
+// ProtectIP persistently protects the IP address of the given Multiaddr.
+// Protected IP addresses are never banned. Protecting an IP address lifts any
+// existing ban.
+func (n *Node) ProtectIP(maddr ma.Multiaddr) error {
+	return n.reputation.ProtectIP(maddr)
+}
+
+// UnprotectIP removes the protection for the IP address of the given
+// Multiaddr.
+func (n *Node) UnprotectIP(maddr ma.Multiaddr) error {
+	return n.reputation.UnprotectIP(maddr)
+}
@@ -0,0 +1,127 @@
+// Package privnet implements libp2p private networks. Nodes in a private
+// network share a pre-shared key (PSK) which is used to encrypt all
+// connections before any other handshake takes place. Nodes which don't know
+// the PSK are unable to connect. Connections are protected by the libp2p PSK
+// v1 protector (see go-libp2p-pnet), so private networks are compatible with
+// other libp2p implementations.
+package privnet
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+
+	ipnet "github.com/libp2p/go-libp2p-core/pnet"
+	pnet "github.com/libp2p/go-libp2p-pnet"
+)
+
+const (
+	// KeySize is the size of a PSK in bytes.
+	KeySize = 32
+	// pskV1Header is the first line of a PSK v1 key file.
+	pskV1Header = "/key/swarm/psk/1.0.0/"
+)
+
+// PSK is a pre-shared key for a private network.
+type PSK [KeySize]byte
+
+// DecodeKey decodes a PSK in the libp2p PSK v1 format (also known as a
+// "swarm.key" file), e.g.:
+//
+//    /key/swarm/psk/1.0.0/
+//    /base16/
+//    e3c1b2dbd2f6b85a1f2d4ae3ebd5e2a7e7a0f6c3c1dd5b8f4b2b0e3f1c6a7d9e
+//
+// The base16, base64 and bin encodings are supported.
+func DecodeKey(r io.Reader) (*PSK, error) {
+	reader := bufio.NewReader(r)
+	header, err := readLine(reader)
+	if err != nil {
+		return nil, err
+	}
+	if header != pskV1Header {
+		return nil, fmt.Errorf("unsupported private network key format: %q", header)
+	}
+	encoding, err := readLine(reader)
+	if err != nil {
+		return nil, err
+	}
+	var keyBytes []byte
+	switch encoding {
+	case "/base16/":
+		encoded, err := readLine(reader)
+		if err != nil {
+			return nil, err
+		}
+		keyBytes, err = hex.DecodeString(encoded)
+		if err != nil {
+			return nil, err
+		}
+	case "/base64/":
+		encoded, err := readLine(reader)
+		if err != nil {
+			return nil, err
+		}
+		keyBytes, err = base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, err
+		}
+	case "/bin/":
+		keyBytes = make([]byte, KeySize)
+		if _, err := io.ReadFull(reader, keyBytes); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported private network key encoding: %q", encoding)
+	}
+	if len(keyBytes) != KeySize {
+		return nil, fmt.Errorf("expected private network key to be %d bytes but got %d", KeySize, len(keyBytes))
+	}
+	psk := &PSK{}
+	copy(psk[:], keyBytes)
+	return psk, nil
+}
+
+// EncodeKey encodes the PSK in the libp2p PSK v1 format using the base16
+// encoding.
+func EncodeKey(psk *PSK) []byte {
+	return []byte(fmt.Sprintf("%s\n/base16/\n%s\n", pskV1Header, hex.EncodeToString(psk[:])))
+}
+
+// GenerateKey generates a new random PSK.
+func GenerateKey() (*PSK, error) {
+	psk := &PSK{}
+	if _, err := rand.Read(psk[:]); err != nil {
+		return nil, err
+	}
+	return psk, nil
+}
+
+func readLine(reader *bufio.Reader) (string, error) {
+	line, err := reader.ReadBytes('\n')
+	if err != nil && (err != io.EOF || len(line) == 0) {
+		return "", err
+	}
+	return string(bytes.TrimSpace(line)), nil
+}
+
+// Fingerprint returns a fingerprint of the PSK which is safe to share. Nodes
+// with the same fingerprint are in the same private network.
+func Fingerprint(psk *PSK) ([]byte, error) {
+	protector, err := NewProtector(psk)
+	if err != nil {
+		return nil, err
+	}
+	return protector.Fingerprint(), nil
+}
+
+// NewProtector returns a pnet.Protector which encrypts all connections using
+// the given PSK. It can be used with libp2p.PrivateNetwork.
+func NewProtector(psk *PSK) (ipnet.Protector, error) {
+	key := [KeySize]byte(*psk)
+	return pnet.NewV1ProtectorFromBytes(&key)
+}
@@ -0,0 +1,90 @@
+package privnet
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeKey(t *testing.T) {
+	psk, err := GenerateKey()
+	require.NoError(t, err)
+	decoded, err := DecodeKey(bytes.NewReader(EncodeKey(psk)))
+	require.NoError(t, err)
+	assert.Equal(t, psk, decoded)
+
+	base64Key := "/key/swarm/psk/1.0.0/\n/base64/\nAAECAwQFBgcICQoLDA0ODxAREhMUFRYXGBkaGxwdHh8="
+	decoded, err = DecodeKey(bytes.NewReader([]byte(base64Key)))
+	require.NoError(t, err)
+	for i := 0; i < KeySize; i++ {
+		assert.Equal(t, byte(i), decoded[i])
+	}
+
+	invalidKeys := []string{
+		"",
+		"/key/swarm/psk/2.0.0/\n/base16/\n" + strings.Repeat("00", KeySize),
+		"/key/swarm/psk/1.0.0/\n/base32/\nAAAA",
+		"/key/swarm/psk/1.0.0/\n/base16/\nabcd",
+	}
+	for _, invalidKey := range invalidKeys {
+		_, err := DecodeKey(bytes.NewReader([]byte(invalidKey)))
+		assert.Error(t, err, invalidKey)
+	}
+}
+
+func TestProtector(t *testing.T) {
+	psk, err := GenerateKey()
+	require.NoError(t, err)
+	otherPSK, err := GenerateKey()
+	require.NoError(t, err)
+	protector, err := NewProtector(psk)
+	require.NoError(t, err)
+	fingerprint, err := Fingerprint(psk)
+	require.NoError(t, err)
+	assert.Equal(t, protector.Fingerprint(), fingerprint)
+	otherFingerprint, err := Fingerprint(otherPSK)
+	require.NoError(t, err)
+	assert.NotEqual(t, fingerprint, otherFingerprint)
+
+	message := []byte("hello private network")
+	testCases := []struct {
+		name          string
+		receiverPSK   *PSK
+		expectMessage bool
+	}{
+		{
+			name:          "same PSK",
+			receiverPSK:   psk,
+			expectMessage: true,
+		},
+		{
+			name:          "different PSK",
+			receiverPSK:   otherPSK,
+			expectMessage: false,
+		},
+	}
+	for _, tc := range testCases {
+		senderRaw, receiverRaw := net.Pipe()
+		sender, err := protector.Protect(senderRaw)
+		require.NoError(t, err)
+		receiverProtector, err := NewProtector(tc.receiverPSK)
+		require.NoError(t, err)
+		receiver, err := receiverProtector.Protect(receiverRaw)
+		require.NoError(t, err)
+
+		go func() {
+			_, _ = sender.Write(message)
+		}()
+		received := make([]byte, len(message))
+		_, err = io.ReadFull(receiver, received)
+		require.NoError(t, err, tc.name)
+		assert.Equal(t, tc.expectMessage, bytes.Equal(message, received), tc.name)
+		_ = sender.Close()
+		_ = receiver.Close()
+	}
+}
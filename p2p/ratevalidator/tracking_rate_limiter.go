@@ -2,6 +2,7 @@ package ratevalidator
 
 import (
 	"sync/atomic"
+	"time"
 
 	"golang.org/x/time/rate"
 )
@@ -23,8 +24,8 @@ func (l *trackingRateLimiter) resetViolations() {
 	atomic.StoreUint64(&l.violations, 0)
 }
 
-func (l *trackingRateLimiter) allow() bool {
-	allowed := l.limiter.Allow()
+func (l *trackingRateLimiter) allowN(n int) bool {
+	allowed := l.limiter.AllowN(time.Now(), n)
 	if !allowed {
 		atomic.AddUint64(&l.violations, 1)
 	}
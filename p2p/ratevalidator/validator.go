@@ -56,6 +56,10 @@ type Config struct {
 	// MaxMessageSize is the maximum size (in bytes) for a message. Any messages
 	// that exceed this size will be considered invalid.
 	MaxMessageSize int
+	// MessageWeight optionally returns the number of units a message counts
+	// toward the global and per-peer limits (e.g. the number of orders in a
+	// batched message). If nil, every message counts as one unit.
+	MessageWeight func(data []byte) int
 }
 
 // New creates and returns a new rate limiting validator.
@@ -91,7 +95,8 @@ func New(ctx context.Context, config Config) (*Validator, error) {
 
 // Validate validates a pubsub message based solely on the rate of messages
 // received. If either the global or per-peer limits are exceeded, the message
-// is considered "invalid" and will be dropped.
+// is considered "invalid" and will be dropped. Each message counts toward the
+// limits according to its weight (see Config.MessageWeight).
 func (v *Validator) Validate(ctx context.Context, peerID peer.ID, msg *pubsub.Message) bool {
 	if v.isClosed() {
 		return false
@@ -113,11 +118,22 @@ func (v *Validator) Validate(ctx context.Context, peerID peer.ID, msg *pubsub.Me
 		log.WithError(err).Error("unexpected error in getOrCreateLimiterForPeer")
 		return false
 	}
-	if !peerLimiter.Allow() {
+	weight := v.messageWeight(msg.GetData())
+	if !peerLimiter.AllowN(time.Now(), weight) {
 		return false
 	}
 
-	return v.globalLimiter.allow()
+	return v.globalLimiter.allowN(weight)
+}
+
+func (v *Validator) messageWeight(data []byte) int {
+	if v.config.MessageWeight == nil {
+		return 1
+	}
+	if weight := v.config.MessageWeight(data); weight > 1 {
+		return weight
+	}
+	return 1
 }
 
 func (v *Validator) getOrCreateLimiterForPeer(peerID peer.ID) (*rate.Limiter, error) {
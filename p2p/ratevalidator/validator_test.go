@@ -126,3 +126,46 @@ func TestValidatorMaxMessageSize(t *testing.T) {
 		assert.False(t, isValid, "message should be invalid")
 	}
 }
+
+func TestValidatorMessageWeight(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	validator, err := New(ctx, Config{
+		MyPeerID:       peerIDs[0],
+		GlobalLimit:    rate.Inf,
+		PerPeerLimit:   1,
+		PerPeerBurst:   5,
+		MaxMessageSize: 1024,
+		MessageWeight: func(data []byte) int {
+			return len(data)
+		},
+	})
+	require.NoError(t, err)
+
+	// A message with a weight of 3 uses up 3 of the 5 messages in the burst.
+	isValid := validator.Validate(ctx, peerIDs[1], &pubsub.Message{
+		Message: &pb.Message{
+			Data: make([]byte, 3),
+		},
+	})
+	assert.True(t, isValid, "message should be valid")
+
+	// Another message with a weight of 3 exceeds the burst.
+	isValid = validator.Validate(ctx, peerIDs[1], &pubsub.Message{
+		Message: &pb.Message{
+			Data: make([]byte, 3),
+		},
+	})
+	assert.False(t, isValid, "message should be invalid")
+
+	// Messages with a weight of 0 still count as 1.
+	for i := 0; i < 2; i++ {
+		isValid = validator.Validate(ctx, peerIDs[1], &pubsub.Message{})
+		assert.True(t, isValid, "message should be valid")
+	}
+	isValid = validator.Validate(ctx, peerIDs[1], &pubsub.Message{})
+	assert.False(t, isValid, "message should be invalid")
+}
@@ -0,0 +1,137 @@
+package rendezvous
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/host"
+	"github.com/libp2p/go-libp2p-core/peer"
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+// Client registers the host with rendezvous servers and discovers other peers
+// through them.
+type Client struct {
+	host host.Host
+}
+
+// NewClient creates a new Client for the given host.
+func NewClient(h host.Host) *Client {
+	return &Client{
+		host: h,
+	}
+}
+
+// Register registers the host under the given namespace with the server. It
+// returns the TTL of the registration, after which it has to be renewed. If ttl
+// is 0, DefaultTTL is used.
+func (c *Client) Register(ctx context.Context, server peer.AddrInfo, namespace string, ttl time.Duration) (time.Duration, error) {
+	addrs := []string{}
+	for _, addr := range c.host.Addrs() {
+		addrs = append(addrs, addr.String())
+	}
+	if len(addrs) > MaxAddrs {
+		addrs = addrs[:MaxAddrs]
+	}
+	res, err := c.roundTrip(ctx, server, &request{
+		Type:      requestTypeRegister,
+		Namespace: namespace,
+		TTL:       int64(ttl / time.Second),
+		Addrs:     addrs,
+	})
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(res.TTL) * time.Second, nil
+}
+
+// Unregister removes the registration of the host under the given namespace
+// from the server.
+func (c *Client) Unregister(ctx context.Context, server peer.AddrInfo, namespace string) error {
+	_, err := c.roundTrip(ctx, server, &request{
+		Type:      requestTypeUnregister,
+		Namespace: namespace,
+	})
+	return err
+}
+
+// Discover returns up to limit peers which are registered under the given
+// namespace with the server. If limit is 0, MaxDiscoverLimit is used.
+func (c *Client) Discover(ctx context.Context, server peer.AddrInfo, namespace string, limit int) ([]peer.AddrInfo, error) {
+	res, err := c.roundTrip(ctx, server, &request{
+		Type:      requestTypeDiscover,
+		Namespace: namespace,
+		Limit:     limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return parseRegistrations(res.Registrations), nil
+}
+
+func (c *Client) roundTrip(ctx context.Context, server peer.AddrInfo, req *request) (*response, error) {
+	ctx, cancel := context.WithTimeout(ctx, streamTimeout)
+	defer cancel()
+	if err := c.host.Connect(ctx, server); err != nil {
+		return nil, err
+	}
+	stream, err := c.host.NewStream(ctx, server.ID, ProtocolID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = stream.Close()
+	}()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = stream.SetDeadline(deadline)
+	}
+	res, err := exchange(stream, req)
+	if err != nil {
+		_ = stream.Reset()
+		return nil, err
+	}
+	return res, nil
+}
+
+// exchange writes the request and reads the response.
+func exchange(rw io.ReadWriter, req *request) (*response, error) {
+	if err := writeMessage(rw, req); err != nil {
+		return nil, err
+	}
+	var res response
+	if err := readMessage(rw, &res); err != nil {
+		return nil, err
+	}
+	if res.Error != "" {
+		return nil, errors.New(res.Error)
+	}
+	return &res, nil
+}
+
+// parseRegistrations converts the registrations returned by the server to
+// AddrInfos. Registrations with an invalid peer ID or without valid addresses
+// are skipped.
+func parseRegistrations(registrations []registrationResult) []peer.AddrInfo {
+	addrInfos := []peer.AddrInfo{}
+	for _, registration := range registrations {
+		peerID, err := peer.IDB58Decode(registration.PeerID)
+		if err != nil {
+			continue
+		}
+		addrInfo := peer.AddrInfo{ID: peerID}
+		for _, addrString := range registration.Addrs {
+			addr, err := ma.NewMultiaddr(addrString)
+			if err != nil {
+				continue
+			}
+			addrInfo.Addrs = append(addrInfo.Addrs, addr)
+		}
+		if len(addrInfo.Addrs) == 0 {
+			continue
+		}
+		addrInfos = append(addrInfos, addrInfo)
+	}
+	return addrInfos
+}
@@ -0,0 +1,111 @@
+// Package rendezvous implements a simple rendezvous protocol for peer
+// discovery. Peers register themselves under one or more namespaces (i.e.
+// rendezvous points) with a rendezvous server and ask the server for other
+// peers registered under the same namespace. Unlike the DHT, the rendezvous
+// server knows about all peers for a namespace, which makes discovery fast even
+// for namespaces with very few peers.
+package rendezvous
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/protocol"
+)
+
+// ProtocolID is the protocol ID of the rendezvous protocol.
+const ProtocolID = protocol.ID("/injective-0x-mesh-rendezvous/version/1")
+
+const (
+	// DefaultTTL is the TTL of a registration if the client does not specify
+	// one.
+	DefaultTTL = 2 * time.Hour
+	// MaxTTL is the maximum TTL of a registration.
+	MaxTTL = 72 * time.Hour
+	// MaxNamespaceLength is the maximum length of a namespace in bytes.
+	MaxNamespaceLength = 256
+	// MaxAddrs is the maximum number of addresses per registration.
+	MaxAddrs = 32
+	// MaxNamespacesPerPeer is the maximum number of namespaces a single peer
+	// can be registered under.
+	MaxNamespacesPerPeer = 100
+	// MaxDiscoverLimit is the maximum number of registrations returned by a
+	// single discover request.
+	MaxDiscoverLimit = 1000
+	// maxMessageSize is the maximum size of a request or response in bytes.
+	maxMessageSize = 1 << 20 // 1 MiB
+	// streamTimeout is the maximum amount of time a request and its response
+	// may take.
+	streamTimeout = 30 * time.Second
+)
+
+var (
+	// ErrInvalidNamespace is returned if a namespace is empty or too long.
+	ErrInvalidNamespace = errors.New("invalid namespace")
+	// ErrInvalidTTL is returned if a TTL is negative or exceeds MaxTTL.
+	ErrInvalidTTL = errors.New("invalid TTL")
+	// ErrInvalidAddrs is returned if a registration has no addresses, too many
+	// addresses, addresses which are not valid multiaddresses or none which
+	// match the address the server observes for the registering peer.
+	ErrInvalidAddrs = errors.New("invalid addresses")
+	// ErrNamespaceFull is returned if the maximum number of registrations for a
+	// namespace has been reached.
+	ErrNamespaceFull = errors.New("too many registrations for namespace")
+	// ErrTooManyNamespaces is returned if a peer is already registered under
+	// MaxNamespacesPerPeer namespaces.
+	ErrTooManyNamespaces = errors.New("too many namespaces for peer")
+	// ErrServerFull is returned if the maximum number of registrations of the
+	// server has been reached.
+	ErrServerFull = errors.New("too many registrations")
+)
+
+type requestType string
+
+const (
+	requestTypeRegister   requestType = "register"
+	requestTypeUnregister requestType = "unregister"
+	requestTypeDiscover   requestType = "discover"
+)
+
+// request is sent by the client. Each stream carries exactly one request and
+// one response.
+type request struct {
+	Type      requestType `json:"type"`
+	Namespace string      `json:"namespace"`
+	// TTL is the TTL of a registration in seconds. It is only used for
+	// register requests. If zero, DefaultTTL is used.
+	TTL int64 `json:"ttl,omitempty"`
+	// Addrs are the multiaddresses of the registering peer. They are only used
+	// for register requests.
+	Addrs []string `json:"addrs,omitempty"`
+	// Limit is the maximum number of registrations to return. It is only used
+	// for discover requests. If zero, MaxDiscoverLimit is used.
+	Limit int `json:"limit,omitempty"`
+}
+
+// response is sent by the server.
+type response struct {
+	// Error is empty if the request was successful.
+	Error string `json:"error,omitempty"`
+	// TTL is the TTL of the registration in seconds. It is only set for
+	// register requests.
+	TTL           int64                `json:"ttl,omitempty"`
+	Registrations []registrationResult `json:"registrations,omitempty"`
+}
+
+// registrationResult is a single registration returned for a discover
+// request.
+type registrationResult struct {
+	PeerID string   `json:"peerID"`
+	Addrs  []string `json:"addrs"`
+}
+
+func writeMessage(w io.Writer, message interface{}) error {
+	return json.NewEncoder(w).Encode(message)
+}
+
+func readMessage(r io.Reader, message interface{}) error {
+	return json.NewDecoder(io.LimitReader(r, maxMessageSize)).Decode(message)
+}
@@ -0,0 +1,290 @@
+package rendezvous
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+	ma "github.com/multiformats/go-multiaddr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testNamespace = "/injective-0x-mesh/network/1337/version/2"
+
+var peerIDStrings = []string{
+	"16Uiu2HAmGd949LwaV4KNvK2WDSiMVy7xEmW983VH75CMmefmMpP7",
+	"16Uiu2HAmVqV4kepwSiNRmvKiBxwpt4EQJi3pAe9auSMyGjzA1eBZ",
+	"16Uiu2HAm2VAoigVxsZy1i5anRc1DdkCpDAJRkqQZh6j3wsPcH2Pb",
+}
+
+var peerIDs []peer.ID
+
+// testRemoteAddr is the observed address of the connection to the peers in
+// the tests. It matches the address used by registerRequest.
+var testRemoteAddr = ma.StringCast("/ip4/1.2.3.4/tcp/60558")
+
+func init() {
+	for _, peerIDString := range peerIDStrings {
+		peerID, _ := peer.IDB58Decode(peerIDString)
+		peerIDs = append(peerIDs, peerID)
+	}
+}
+
+func registerRequest(namespace string, ttl time.Duration) *request {
+	return &request{
+		Type:      requestTypeRegister,
+		Namespace: namespace,
+		TTL:       int64(ttl / time.Second),
+		Addrs:     []string{"/ip4/1.2.3.4/tcp/60558"},
+	}
+}
+
+func TestRegisterAndDiscover(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	server := NewServer(ctx, 0)
+	now := time.Now()
+
+	res := server.handleRequest(peerIDs[0], testRemoteAddr, registerRequest(testNamespace, 0), now)
+	require.Empty(t, res.Error)
+	assert.Equal(t, int64(DefaultTTL/time.Second), res.TTL)
+	res = server.handleRequest(peerIDs[1], testRemoteAddr, registerRequest(testNamespace, time.Hour), now)
+	require.Empty(t, res.Error)
+	assert.Equal(t, int64(time.Hour/time.Second), res.TTL)
+
+	// A peer does not discover itself.
+	res = server.handleRequest(peerIDs[0], testRemoteAddr, &request{Type: requestTypeDiscover, Namespace: testNamespace}, now)
+	require.Empty(t, res.Error)
+	require.Len(t, res.Registrations, 1)
+	assert.Equal(t, peerIDs[1].Pretty(), res.Registrations[0].PeerID)
+
+	// The registration which expires last is returned first.
+	res = server.handleRequest(peerIDs[2], testRemoteAddr, &request{Type: requestTypeDiscover, Namespace: testNamespace, Limit: 1}, now)
+	require.Empty(t, res.Error)
+	require.Len(t, res.Registrations, 1)
+	assert.Equal(t, peerIDs[0].Pretty(), res.Registrations[0].PeerID)
+
+	// Other namespaces are separate.
+	res = server.handleRequest(peerIDs[2], testRemoteAddr, &request{Type: requestTypeDiscover, Namespace: "other"}, now)
+	require.Empty(t, res.Error)
+	assert.Empty(t, res.Registrations)
+
+	assert.Equal(t, map[string]int{testNamespace: 2}, server.Namespaces())
+}
+
+func TestUnregister(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	server := NewServer(ctx, 0)
+	now := time.Now()
+
+	res := server.handleRequest(peerIDs[0], testRemoteAddr, registerRequest(testNamespace, 0), now)
+	require.Empty(t, res.Error)
+	res = server.handleRequest(peerIDs[0], testRemoteAddr, &request{Type: requestTypeUnregister, Namespace: testNamespace}, now)
+	require.Empty(t, res.Error)
+
+	res = server.handleRequest(peerIDs[1], testRemoteAddr, &request{Type: requestTypeDiscover, Namespace: testNamespace}, now)
+	require.Empty(t, res.Error)
+	assert.Empty(t, res.Registrations)
+	assert.Empty(t, server.Namespaces())
+}
+
+func TestRegistrationExpires(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	server := NewServer(ctx, 0)
+	now := time.Now()
+
+	res := server.handleRequest(peerIDs[0], testRemoteAddr, registerRequest(testNamespace, time.Minute), now)
+	require.Empty(t, res.Error)
+
+	later := now.Add(2 * time.Minute)
+	res = server.handleRequest(peerIDs[1], testRemoteAddr, &request{Type: requestTypeDiscover, Namespace: testNamespace}, later)
+	require.Empty(t, res.Error)
+	assert.Empty(t, res.Registrations)
+
+	server.removeExpired(later)
+	server.mut.Lock()
+	assert.Empty(t, server.registrations)
+	server.mut.Unlock()
+}
+
+func TestInvalidRequests(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	server := NewServer(ctx, 1)
+	now := time.Now()
+
+	testCases := []struct {
+		name          string
+		req           *request
+		expectedError error
+	}{
+		{
+			name:          "empty namespace",
+			req:           registerRequest("", 0),
+			expectedError: ErrInvalidNamespace,
+		},
+		{
+			name:          "namespace too long",
+			req:           registerRequest(string(make([]byte, MaxNamespaceLength+1)), 0),
+			expectedError: ErrInvalidNamespace,
+		},
+		{
+			name:          "TTL too long",
+			req:           registerRequest(testNamespace, MaxTTL+time.Second),
+			expectedError: ErrInvalidTTL,
+		},
+		{
+			name: "no addresses",
+			req: &request{
+				Type:      requestTypeRegister,
+				Namespace: testNamespace,
+			},
+			expectedError: ErrInvalidAddrs,
+		},
+		{
+			name: "invalid address",
+			req: &request{
+				Type:      requestTypeRegister,
+				Namespace: testNamespace,
+				Addrs:     []string{"not a multiaddress"},
+			},
+			expectedError: ErrInvalidAddrs,
+		},
+	}
+	for _, testCase := range testCases {
+		res := server.handleRequest(peerIDs[0], testRemoteAddr, testCase.req, now)
+		assert.Equal(t, testCase.expectedError.Error(), res.Error, testCase.name)
+	}
+
+	// The namespace is full after the first registration, but the registered
+	// peer can still renew its registration.
+	res := server.handleRequest(peerIDs[0], testRemoteAddr, registerRequest(testNamespace, 0), now)
+	require.Empty(t, res.Error)
+	res = server.handleRequest(peerIDs[1], testRemoteAddr, registerRequest(testNamespace, 0), now)
+	assert.Equal(t, ErrNamespaceFull.Error(), res.Error)
+	res = server.handleRequest(peerIDs[0], testRemoteAddr, registerRequest(testNamespace, time.Hour), now)
+	assert.Empty(t, res.Error)
+}
+
+func TestRegisterFiltersAddrs(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	server := NewServer(ctx, 0)
+	now := time.Now()
+
+	// Addresses with another IP than the observed address are dropped, but
+	// relay addresses are kept.
+	relayAddr := "/ip4/5.6.7.8/tcp/60558/p2p/" + peerIDStrings[2] + "/p2p-circuit"
+	res := server.handleRequest(peerIDs[0], testRemoteAddr, &request{
+		Type:      requestTypeRegister,
+		Namespace: testNamespace,
+		Addrs:     []string{"/ip4/5.6.7.8/tcp/60558", "/ip4/1.2.3.4/tcp/60559", relayAddr},
+	}, now)
+	require.Empty(t, res.Error)
+	res = server.handleRequest(peerIDs[1], testRemoteAddr, &request{Type: requestTypeDiscover, Namespace: testNamespace}, now)
+	require.Len(t, res.Registrations, 1)
+	assert.Equal(t, []string{"/ip4/1.2.3.4/tcp/60559", relayAddr}, res.Registrations[0].Addrs)
+
+	// If none of the addresses can be verified, the observed address is used.
+	res = server.handleRequest(peerIDs[0], testRemoteAddr, &request{
+		Type:      requestTypeRegister,
+		Namespace: testNamespace,
+		Addrs:     []string{"/ip4/192.168.0.2/tcp/60558"},
+	}, now)
+	require.Empty(t, res.Error)
+	res = server.handleRequest(peerIDs[1], testRemoteAddr, &request{Type: requestTypeDiscover, Namespace: testNamespace}, now)
+	require.Len(t, res.Registrations, 1)
+	assert.Equal(t, []string{testRemoteAddr.String()}, res.Registrations[0].Addrs)
+}
+
+func TestRegistrationLimits(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	server := NewServer(ctx, 0)
+	now := time.Now()
+
+	for i := 0; i < MaxNamespacesPerPeer; i++ {
+		res := server.handleRequest(peerIDs[0], testRemoteAddr, registerRequest(fmt.Sprintf("namespace-%d", i), 0), now)
+		require.Empty(t, res.Error)
+	}
+	res := server.handleRequest(peerIDs[0], testRemoteAddr, registerRequest(testNamespace, 0), now)
+	assert.Equal(t, ErrTooManyNamespaces.Error(), res.Error)
+	// Existing registrations can still be renewed.
+	res = server.handleRequest(peerIDs[0], testRemoteAddr, registerRequest("namespace-0", 0), now)
+	assert.Empty(t, res.Error)
+
+	// Unregistering frees up a namespace for the peer.
+	res = server.handleRequest(peerIDs[0], testRemoteAddr, &request{Type: requestTypeUnregister, Namespace: "namespace-0"}, now)
+	require.Empty(t, res.Error)
+	res = server.handleRequest(peerIDs[0], testRemoteAddr, registerRequest(testNamespace, 0), now)
+	assert.Empty(t, res.Error)
+
+	// The total number of registrations is limited as well.
+	server.mut.Lock()
+	server.maxRegistrations = MaxNamespacesPerPeer + 1
+	server.mut.Unlock()
+	res = server.handleRequest(peerIDs[1], testRemoteAddr, registerRequest(testNamespace, 0), now)
+	require.Empty(t, res.Error)
+	res = server.handleRequest(peerIDs[2], testRemoteAddr, registerRequest(testNamespace, 0), now)
+	assert.Equal(t, ErrServerFull.Error(), res.Error)
+
+	// Expired registrations no longer count towards the limits.
+	server.removeExpired(now.Add(MaxTTL))
+	server.mut.Lock()
+	assert.Equal(t, 0, server.numRegistrations)
+	assert.Empty(t, server.numNamespacesByPeer)
+	server.mut.Unlock()
+}
+
+func TestExchange(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	server := NewServer(ctx, 0)
+	now := time.Now()
+	res := server.handleRequest(peerIDs[0], testRemoteAddr, registerRequest(testNamespace, 0), now)
+	require.Empty(t, res.Error)
+
+	clientConn, serverConn := net.Pipe()
+	defer clientConn.Close()
+	go func() {
+		defer serverConn.Close()
+		var req request
+		if err := readMessage(serverConn, &req); err != nil {
+			return
+		}
+		_ = writeMessage(serverConn, server.handleRequest(peerIDs[1], testRemoteAddr, &req, now))
+	}()
+
+	res, err := exchange(clientConn, &request{Type: requestTypeDiscover, Namespace: testNamespace})
+	require.NoError(t, err)
+	addrInfos := parseRegistrations(res.Registrations)
+	require.Len(t, addrInfos, 1)
+	assert.Equal(t, peerIDs[0], addrInfos[0].ID)
+	require.Len(t, addrInfos[0].Addrs, 1)
+	assert.Equal(t, "/ip4/1.2.3.4/tcp/60558", addrInfos[0].Addrs[0].String())
+}
+
+func TestExchangeError(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	server := NewServer(ctx, 0)
+	clientConn, serverConn := net.Pipe()
+	defer clientConn.Close()
+	go func() {
+		defer serverConn.Close()
+		var req request
+		if err := readMessage(serverConn, &req); err != nil {
+			return
+		}
+		_ = writeMessage(serverConn, server.handleRequest(peerIDs[1], testRemoteAddr, &req, time.Now()))
+	}()
+
+	_, err := exchange(clientConn, &request{Type: requestTypeDiscover})
+	assert.EqualError(t, err, ErrInvalidNamespace.Error())
+}
@@ -0,0 +1,296 @@
+package rendezvous
+
+import (
+	"context"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/network"
+	"github.com/libp2p/go-libp2p-core/peer"
+	ma "github.com/multiformats/go-multiaddr"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultMaxRegistrationsPerNamespace is the default maximum number of
+	// registrations per namespace.
+	DefaultMaxRegistrationsPerNamespace = 10000
+	// maxRegistrations is the maximum number of registrations across all
+	// namespaces.
+	maxRegistrations = 100000
+	// cleanupInterval is how often expired registrations are removed.
+	cleanupInterval = 1 * time.Minute
+)
+
+type registration struct {
+	addrs     []string
+	expiresAt time.Time
+}
+
+// Server keeps track of registrations and answers requests from clients.
+// Registrations are only kept in memory, since clients renew them well before
+// they expire.
+type Server struct {
+	mut                          sync.Mutex
+	registrations                map[string]map[peer.ID]*registration
+	numNamespacesByPeer          map[peer.ID]int
+	numRegistrations             int
+	maxRegistrationsPerNamespace int
+	maxRegistrations             int
+}
+
+// NewServer creates a new Server. Expired registrations are removed until the
+// context is canceled. If maxRegistrationsPerNamespace is 0,
+// DefaultMaxRegistrationsPerNamespace is used.
+func NewServer(ctx context.Context, maxRegistrationsPerNamespace int) *Server {
+	if maxRegistrationsPerNamespace == 0 {
+		maxRegistrationsPerNamespace = DefaultMaxRegistrationsPerNamespace
+	}
+	s := &Server{
+		registrations:                map[string]map[peer.ID]*registration{},
+		numNamespacesByPeer:          map[peer.ID]int{},
+		maxRegistrationsPerNamespace: maxRegistrationsPerNamespace,
+		maxRegistrations:             maxRegistrations,
+	}
+	go s.continuouslyRemoveExpired(ctx)
+	return s
+}
+
+// HandleStream handles a stream opened by a client. It should be registered
+// as the stream handler for ProtocolID.
+func (s *Server) HandleStream(stream network.Stream) {
+	defer func() {
+		_ = stream.Close()
+	}()
+	_ = stream.SetDeadline(time.Now().Add(streamTimeout))
+	remotePeer := stream.Conn().RemotePeer()
+	var req request
+	if err := readMessage(stream, &req); err != nil {
+		log.WithError(err).WithField("remotePeerID", remotePeer).Debug("could not read rendezvous request")
+		_ = stream.Reset()
+		return
+	}
+	res := s.handleRequest(remotePeer, stream.Conn().RemoteMultiaddr(), &req, time.Now())
+	if err := writeMessage(stream, res); err != nil {
+		log.WithError(err).WithField("remotePeerID", remotePeer).Debug("could not write rendezvous response")
+		_ = stream.Reset()
+	}
+}
+
+// Namespaces returns the number of active registrations for each namespace.
+func (s *Server) Namespaces() map[string]int {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	namespaces := map[string]int{}
+	now := time.Now()
+	for namespace, registrations := range s.registrations {
+		for _, registration := range registrations {
+			if registration.expiresAt.After(now) {
+				namespaces[namespace]++
+			}
+		}
+	}
+	return namespaces
+}
+
+// handleRequest handles a request of remotePeer. remoteAddr is the address of
+// the connection to remotePeer as observed by the server.
+func (s *Server) handleRequest(remotePeer peer.ID, remoteAddr ma.Multiaddr, req *request, now time.Time) *response {
+	if req.Namespace == "" || len(req.Namespace) > MaxNamespaceLength {
+		return &response{Error: ErrInvalidNamespace.Error()}
+	}
+	switch req.Type {
+	case requestTypeRegister:
+		ttl, err := s.register(remotePeer, remoteAddr, req, now)
+		if err != nil {
+			return &response{Error: err.Error()}
+		}
+		return &response{TTL: int64(ttl / time.Second)}
+	case requestTypeUnregister:
+		s.unregister(remotePeer, req.Namespace)
+		return &response{}
+	case requestTypeDiscover:
+		return &response{Registrations: s.discover(remotePeer, req, now)}
+	default:
+		return &response{Error: "unknown request type: " + string(req.Type)}
+	}
+}
+
+func (s *Server) register(remotePeer peer.ID, remoteAddr ma.Multiaddr, req *request, now time.Time) (time.Duration, error) {
+	ttl := time.Duration(req.TTL) * time.Second
+	if ttl == 0 {
+		ttl = DefaultTTL
+	}
+	if ttl < 0 || ttl > MaxTTL {
+		return 0, ErrInvalidTTL
+	}
+	if len(req.Addrs) == 0 || len(req.Addrs) > MaxAddrs {
+		return 0, ErrInvalidAddrs
+	}
+	addrs := make([]ma.Multiaddr, len(req.Addrs))
+	for i, addr := range req.Addrs {
+		maddr, err := ma.NewMultiaddr(addr)
+		if err != nil {
+			return 0, ErrInvalidAddrs
+		}
+		addrs[i] = maddr
+	}
+	verifiedAddrs := filterAddrs(addrs, remoteAddr)
+	if len(verifiedAddrs) == 0 {
+		return 0, ErrInvalidAddrs
+	}
+
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	registrations, found := s.registrations[req.Namespace]
+	if !found {
+		registrations = map[peer.ID]*registration{}
+		s.registrations[req.Namespace] = registrations
+	}
+	if _, found := registrations[remotePeer]; !found {
+		switch {
+		case len(registrations) >= s.maxRegistrationsPerNamespace:
+			return 0, ErrNamespaceFull
+		case s.numNamespacesByPeer[remotePeer] >= MaxNamespacesPerPeer:
+			return 0, ErrTooManyNamespaces
+		case s.numRegistrations >= s.maxRegistrations:
+			return 0, ErrServerFull
+		}
+		s.numNamespacesByPeer[remotePeer]++
+		s.numRegistrations++
+	}
+	registrations[remotePeer] = &registration{
+		addrs:     verifiedAddrs,
+		expiresAt: now.Add(ttl),
+	}
+	return ttl, nil
+}
+
+// filterAddrs returns the addresses which the server can verify against the
+// observed address of the connection to the registering peer. These are the
+// addresses with the same IP as the observed address and relay addresses, which
+// can only be used to reach the registering peer through the relay. Otherwise
+// anyone could make other peers dial arbitrary hosts. If none of the addresses
+// can be verified, the observed address is used instead, if it has an IP.
+func filterAddrs(addrs []ma.Multiaddr, remoteAddr ma.Multiaddr) []string {
+	remoteIP := ipOf(remoteAddr)
+	verifiedAddrs := []string{}
+	for _, addr := range addrs {
+		if _, err := addr.ValueForProtocol(ma.P_CIRCUIT); err == nil {
+			verifiedAddrs = append(verifiedAddrs, addr.String())
+			continue
+		}
+		if ip := ipOf(addr); remoteIP != nil && ip != nil && ip.Equal(remoteIP) {
+			verifiedAddrs = append(verifiedAddrs, addr.String())
+		}
+	}
+	if len(verifiedAddrs) == 0 && remoteIP != nil {
+		verifiedAddrs = append(verifiedAddrs, remoteAddr.String())
+	}
+	return verifiedAddrs
+}
+
+// ipOf returns the IP of the given multiaddress or nil if it does not have one.
+func ipOf(addr ma.Multiaddr) net.IP {
+	if addr == nil {
+		return nil
+	}
+	for _, code := range []int{ma.P_IP4, ma.P_IP6} {
+		if value, err := addr.ValueForProtocol(code); err == nil {
+			return net.ParseIP(value)
+		}
+	}
+	return nil
+}
+
+func (s *Server) unregister(remotePeer peer.ID, namespace string) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	s.deleteRegistration(namespace, remotePeer)
+}
+
+// deleteRegistration deletes the registration of peerID for the given
+// namespace, if any. The caller must hold s.mut.
+func (s *Server) deleteRegistration(namespace string, peerID peer.ID) {
+	registrations, found := s.registrations[namespace]
+	if !found {
+		return
+	}
+	if _, found := registrations[peerID]; !found {
+		return
+	}
+	delete(registrations, peerID)
+	if len(registrations) == 0 {
+		delete(s.registrations, namespace)
+	}
+	s.numRegistrations--
+	s.numNamespacesByPeer[peerID]--
+	if s.numNamespacesByPeer[peerID] == 0 {
+		delete(s.numNamespacesByPeer, peerID)
+	}
+}
+
+// discover returns the registrations for the namespace of the request, except
+// for the registration of the requesting peer. Registrations which expire last
+// are returned first.
+func (s *Server) discover(remotePeer peer.ID, req *request, now time.Time) []registrationResult {
+	limit := req.Limit
+	if limit <= 0 || limit > MaxDiscoverLimit {
+		limit = MaxDiscoverLimit
+	}
+
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	type entry struct {
+		peerID       peer.ID
+		registration *registration
+	}
+	entries := []entry{}
+	for peerID, registration := range s.registrations[req.Namespace] {
+		if peerID == remotePeer || !registration.expiresAt.After(now) {
+			continue
+		}
+		entries = append(entries, entry{peerID: peerID, registration: registration})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].registration.expiresAt.After(entries[j].registration.expiresAt)
+	})
+	results := []registrationResult{}
+	for _, entry := range entries {
+		if len(results) == limit {
+			break
+		}
+		results = append(results, registrationResult{
+			PeerID: entry.peerID.Pretty(),
+			Addrs:  entry.registration.addrs,
+		})
+	}
+	return results
+}
+
+func (s *Server) removeExpired(now time.Time) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	for namespace, registrations := range s.registrations {
+		for peerID, registration := range registrations {
+			if !registration.expiresAt.After(now) {
+				s.deleteRegistration(namespace, peerID)
+			}
+		}
+	}
+}
+
+func (s *Server) continuouslyRemoveExpired(ctx context.Context) {
+	ticker := time.NewTicker(cleanupInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			s.removeExpired(now)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package p2p
+
+import (
+	"context"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// rendezvousTTL is the TTL of our registrations with rendezvous servers.
+	rendezvousTTL = 15 * time.Minute
+	// rendezvousRegisterInterval is how often we renew our registrations with
+	// rendezvous servers. It is shorter than rendezvousTTL so that a failed
+	// renewal does not cause the registration to expire.
+	rendezvousRegisterInterval = rendezvousTTL / 3
+	// rendezvousUnregisterTimeout is the timeout for removing our registrations
+	// when the node is stopped.
+	rendezvousUnregisterTimeout = 2 * time.Second
+)
+
+// continuouslyRegisterWithRendezvousServers registers all rendezvous points
+// with all rendezvous servers and renews the registrations until the context
+// is canceled. The registrations are then removed.
+func (n *Node) continuouslyRegisterWithRendezvousServers(ctx context.Context) {
+	ticker := time.NewTicker(rendezvousRegisterInterval)
+	defer ticker.Stop()
+	for {
+		n.registerWithRendezvousServers(ctx)
+		select {
+		case <-ctx.Done():
+			n.unregisterFromRendezvousServers()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (n *Node) registerWithRendezvousServers(ctx context.Context) {
+	for _, server := range n.rendezvousServers {
+		for _, rendezvousPoint := range n.config.RendezvousPoints {
+			registerCtx, cancel := context.WithTimeout(ctx, defaultNetworkTimeout)
+			ttl, err := n.rendezvousClient.Register(registerCtx, server, rendezvousPoint, rendezvousTTL)
+			cancel()
+			logger := log.WithFields(map[string]interface{}{
+				"rendezvousServer": server.ID,
+				"rendezvousPoint":  rendezvousPoint,
+			})
+			if err != nil {
+				logger.WithError(err).Warn("could not register with rendezvous server")
+				continue
+			}
+			logger.WithField("ttl", ttl).Trace("registered with rendezvous server")
+		}
+	}
+}
+
+func (n *Node) unregisterFromRendezvousServers() {
+	// The node context is canceled at this point, so we use a separate context
+	// with a short timeout.
+	ctx, cancel := context.WithTimeout(context.Background(), rendezvousUnregisterTimeout)
+	defer cancel()
+	for _, server := range n.rendezvousServers {
+		for _, rendezvousPoint := range n.config.RendezvousPoints {
+			if err := n.rendezvousClient.Unregister(ctx, server, rendezvousPoint); err != nil {
+				log.WithError(err).WithField("rendezvousServer", server.ID).Debug("could not unregister from rendezvous server")
+			}
+		}
+	}
+}
+
+// findPeersWithRendezvousServers connects to up to maxNewPeers peers which are
+// registered under the given rendezvous point with any of the rendezvous
+// servers.
+func (n *Node) findPeersWithRendezvousServers(ctx context.Context, rendezvousPoint string, maxNewPeers int) {
+	for _, server := range n.rendezvousServers {
+		discoverCtx, cancel := context.WithTimeout(ctx, defaultNetworkTimeout)
+		peers, err := n.rendezvousClient.Discover(discoverCtx, server, rendezvousPoint, maxNewPeers)
+		cancel()
+		if err != nil {
+			log.WithError(err).WithField("rendezvousServer", server.ID).Warn("could not discover peers with rendezvous server")
+			continue
+		}
+		connectCtx, cancel := context.WithTimeout(ctx, defaultNetworkTimeout)
+		for _, peer := range peers {
+			n.connectToDiscoveredPeer(connectCtx, peer, rendezvousPoint)
+		}
+		cancel()
+		if n.connManager.GetInfo().ConnCount >= peerCountLow {
+			return
+		}
+	}
+}
+
+// connectToDiscoveredPeer connects to a peer which was found at the given
+// rendezvous point, unless it is the node itself or not on the allowlist.
+func (n *Node) connectToDiscoveredPeer(ctx context.Context, peer peer.AddrInfo, rendezvousPoint string) {
+	if peer.ID == n.host.ID() || len(peer.Addrs) == 0 || !n.allowlist.allows(peer.ID) {
+		return
+	}
+	log.WithFields(map[string]interface{}{
+		"peerInfo":        peer,
+		"rendezvousPoint": rendezvousPoint,
+	}).Trace("found peer via rendezvous")
+	if err := n.host.Connect(ctx, peer); err != nil {
+		// We still want to try connecting to the other peers. Log the error and
+		// keep going.
+		logPeerConnectionError(peer, err)
+	}
+}
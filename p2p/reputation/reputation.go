@@ -0,0 +1,650 @@
+// Package reputation keeps track of the reputation of peers and the IP
+// addresses they connect from. Scores decay over time and are persisted so that
+// they survive restarts. The reputation of a peer determines which connections
+// are pruned first and whether the peer (or its IP address) is banned.
+package reputation
+
+import (
+	"context"
+	"math"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/0xProject/0x-mesh/p2p/banner"
+	"github.com/libp2p/go-libp2p-core/host"
+	"github.com/libp2p/go-libp2p-core/peer"
+	ma "github.com/multiformats/go-multiaddr"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultHalfLife is the default amount of time it takes for a score to
+	// decay to half of its value.
+	DefaultHalfLife = 6 * time.Hour
+	// DefaultBanThreshold is the default score at or below which a peer and the
+	// IP addresses it is connected from are banned.
+	DefaultBanThreshold = -100
+	// banScoreFactor determines the score of banned peers and IP addresses.
+	// When a peer is banned, its score (and the score of its IP addresses) is
+	// lowered to banScoreFactor * BanThreshold, so that the ban lasts for at
+	// least one HalfLife.
+	banScoreFactor = 2
+	// connManagerTag is the tag used to report the reputation of a peer to the
+	// connection manager, which prunes the connections with the lowest scores
+	// first.
+	connManagerTag = "reputation"
+	// maxHistory is the maximum number of events stored for each record.
+	maxHistory = 20
+	// updateInterval is how often decayed scores are re-applied, expired bans
+	// are lifted, records without any tags are removed, and changed records are
+	// written to the store.
+	updateInterval = 1 * time.Minute
+	// minScore is the absolute value below which a decayed tag is removed. Tag
+	// changes smaller than minScore are not recorded in the history.
+	minScore = 0.5
+	// protectTag is the tag used for protecting peers in the connection
+	// manager.
+	protectTag = "reputation-protected"
+)
+
+// Kind is the kind of entity a Record belongs to.
+type Kind string
+
+const (
+	// KindPeer is the Kind of records for peer IDs.
+	KindPeer Kind = "peer"
+	// KindIP is the Kind of records for IP addresses.
+	KindIP Kind = "ip"
+)
+
+// Event is a single change in reputation.
+type Event struct {
+	Time time.Time `json:"time"`
+	Tag  string    `json:"tag"`
+	// Value is the new value of the tag.
+	Value float64 `json:"value"`
+	// Score is the total score after the change.
+	Score float64 `json:"score"`
+}
+
+// Record is the reputation of a single peer ID or IP address.
+type Record struct {
+	Kind Kind `json:"kind"`
+	// Subject is the peer ID or IP address.
+	Subject string `json:"subject"`
+	// Tags holds the value of each tag as of LastUpdated.
+	Tags        map[string]float64 `json:"tags"`
+	LastUpdated time.Time          `json:"lastUpdated"`
+	Banned      bool               `json:"banned"`
+	// Protected peers and IP addresses are never banned or pruned by the
+	// connection manager.
+	Protected bool    `json:"protected"`
+	History   []Event `json:"history"`
+}
+
+// ID returns the Record's ID. It is used when storing records in the database.
+func (r *Record) ID() []byte {
+	return []byte(recordKey(r.Kind, r.Subject))
+}
+
+// Score returns the total score of the record as of LastUpdated.
+func (r *Record) Score() float64 {
+	total := 0.0
+	for _, value := range r.Tags {
+		total += value
+	}
+	return total
+}
+
+func recordKey(kind Kind, subject string) string {
+	return string(kind) + "/" + subject
+}
+
+// Store persists reputation records.
+type Store interface {
+	// SavePeerReputation inserts or updates the given record.
+	SavePeerReputation(record *Record) error
+	// DeletePeerReputation deletes the given record. It is a no-op if the
+	// record was never saved.
+	DeletePeerReputation(record *Record) error
+	// FindPeerReputations returns all stored records.
+	FindPeerReputations() ([]*Record, error)
+}
+
+// ConnManager is the subset of the libp2p connection manager used for
+// reporting reputation.
+type ConnManager interface {
+	TagPeer(peer.ID, string, int)
+	Protect(peer.ID, string)
+	Unprotect(peer.ID, string) bool
+}
+
+// Config is a set of configuration options for the Tracker.
+type Config struct {
+	Host        host.Host
+	ConnManager ConnManager
+	Banner      *banner.Banner
+	// Store is used for persisting records. If nil, reputation is only kept in
+	// memory.
+	Store Store
+	// HalfLife is the amount of time it takes for a score to decay to half of
+	// its value. Defaults to DefaultHalfLife.
+	HalfLife time.Duration
+	// BanThreshold is the score at or below which a peer and the IP addresses
+	// it is connected from are banned. Defaults to DefaultBanThreshold.
+	BanThreshold float64
+}
+
+// Tracker keeps track of the reputation of peers and IP addresses.
+type Tracker struct {
+	config  Config
+	mut     sync.Mutex
+	records map[string]*Record
+	dirty   map[string]struct{}
+}
+
+// New creates and returns a new Tracker. It loads all persisted records and
+// re-applies any bans which have not expired yet. The Tracker periodically
+// applies decay and persists changes until the context is canceled. Changes
+// made since the last update are not persisted when the context is canceled,
+// since the Store may already be closed at that point. Bans and protections
+// are always persisted immediately.
+func New(ctx context.Context, config Config) (*Tracker, error) {
+	if config.HalfLife == 0 {
+		config.HalfLife = DefaultHalfLife
+	}
+	if config.BanThreshold == 0 {
+		config.BanThreshold = DefaultBanThreshold
+	}
+	tracker := &Tracker{
+		config:  config,
+		records: map[string]*Record{},
+		dirty:   map[string]struct{}{},
+	}
+	if config.Store != nil {
+		records, err := config.Store.FindPeerReputations()
+		if err != nil {
+			return nil, err
+		}
+		for _, record := range records {
+			tracker.records[recordKey(record.Kind, record.Subject)] = record
+		}
+	}
+	tracker.mut.Lock()
+	if err := tracker.applyProtections(); err != nil {
+		tracker.mut.Unlock()
+		return nil, err
+	}
+	tracker.updateBans(time.Now())
+	tracker.mut.Unlock()
+	go tracker.periodicallyUpdate(ctx)
+	return tracker, nil
+}
+
+// AddScore adds diff to the given tag for the given peer. Negative changes also
+// count toward the reputation of the IP addresses the peer is connected from.
+func (t *Tracker) AddScore(id peer.ID, tag string, diff int) {
+	t.update(id, tag, float64(diff) < 0, func(current float64) float64 {
+		return current + float64(diff)
+	})
+}
+
+// SetScore sets the given tag for the given peer to val. Negative values also
+// count toward the reputation of the IP addresses the peer is connected from.
+func (t *Tracker) SetScore(id peer.ID, tag string, val int) {
+	t.update(id, tag, float64(val) < 0, func(float64) float64 {
+		return float64(val)
+	})
+}
+
+// UnsetScore removes the given tag for the given peer.
+func (t *Tracker) UnsetScore(id peer.ID, tag string) {
+	t.update(id, tag, false, func(float64) float64 {
+		return 0
+	})
+}
+
+// Score returns the current score for the given peer.
+func (t *Tracker) Score(id peer.ID) float64 {
+	t.mut.Lock()
+	defer t.mut.Unlock()
+	record, found := t.records[recordKey(KindPeer, id.Pretty())]
+	if !found {
+		return 0
+	}
+	return t.score(record, time.Now())
+}
+
+// ProtectPeer persistently protects the given peer. Protected peers are never
+// banned or pruned by the connection manager.
+func (t *Tracker) ProtectPeer(id peer.ID) error {
+	t.mut.Lock()
+	defer t.mut.Unlock()
+	record := t.getOrCreateRecord(KindPeer, id.Pretty(), time.Now())
+	record.Protected = true
+	record.Banned = false
+	t.config.ConnManager.Protect(id, protectTag)
+	return t.save(record)
+}
+
+// UnprotectPeer removes the protection for the given peer.
+func (t *Tracker) UnprotectPeer(id peer.ID) error {
+	t.mut.Lock()
+	defer t.mut.Unlock()
+	record := t.getOrCreateRecord(KindPeer, id.Pretty(), time.Now())
+	record.Protected = false
+	t.config.ConnManager.Unprotect(id, protectTag)
+	return t.save(record)
+}
+
+// IsPeerProtected returns true if the given peer is protected.
+func (t *Tracker) IsPeerProtected(id peer.ID) bool {
+	t.mut.Lock()
+	defer t.mut.Unlock()
+	record, found := t.records[recordKey(KindPeer, id.Pretty())]
+	return found && record.Protected
+}
+
+// ProtectIP persistently protects the IP address of the given Multiaddr.
+// Protected IP addresses are never banned.
+func (t *Tracker) ProtectIP(maddr ma.Multiaddr) error {
+	ip, err := banner.IPFromMaddr(maddr)
+	if err != nil {
+		return err
+	}
+	t.mut.Lock()
+	defer t.mut.Unlock()
+	record := t.getOrCreateRecord(KindIP, ip.String(), time.Now())
+	record.Protected = true
+	record.Banned = false
+	if t.config.Banner != nil {
+		if err := t.config.Banner.ProtectIP(maddr); err != nil {
+			return err
+		}
+	}
+	return t.save(record)
+}
+
+// UnprotectIP removes the protection for the IP address of the given
+// Multiaddr.
+func (t *Tracker) UnprotectIP(maddr ma.Multiaddr) error {
+	ip, err := banner.IPFromMaddr(maddr)
+	if err != nil {
+		return err
+	}
+	t.mut.Lock()
+	defer t.mut.Unlock()
+	record := t.getOrCreateRecord(KindIP, ip.String(), time.Now())
+	record.Protected = false
+	if t.config.Banner != nil {
+		if err := t.config.Banner.UnprotectIP(maddr); err != nil {
+			return err
+		}
+	}
+	return t.save(record)
+}
+
+// Records returns a copy of all records of the given kind with decay applied
+// up until now, sorted from the lowest to the highest score.
+func (t *Tracker) Records(kind Kind) []*Record {
+	t.mut.Lock()
+	defer t.mut.Unlock()
+	now := time.Now()
+	records := []*Record{}
+	for _, record := range t.records {
+		if record.Kind != kind {
+			continue
+		}
+		decayed := *record
+		decayed.Tags = map[string]float64{}
+		factor := decayFactor(record.LastUpdated, now, t.config.HalfLife)
+		for tag, value := range record.Tags {
+			decayed.Tags[tag] = value * factor
+		}
+		decayed.LastUpdated = now
+		decayed.History = append([]Event{}, record.History...)
+		records = append(records, &decayed)
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].Score() < records[j].Score()
+	})
+	return records
+}
+
+// PeerConnected should be called whenever a new connection is opened. It
+// reports the persisted reputation of the peer to the connection manager and
+// closes the connection if the peer or its IP address is banned.
+func (t *Tracker) PeerConnected(id peer.ID, remoteAddr ma.Multiaddr) {
+	t.mut.Lock()
+	defer t.mut.Unlock()
+	now := time.Now()
+	peerScore := 0.0
+	if record, found := t.records[recordKey(KindPeer, id.Pretty())]; found {
+		peerScore = t.score(record, now)
+		t.config.ConnManager.TagPeer(id, connManagerTag, int(math.Round(peerScore)))
+	}
+	ipScore := 0.0
+	if ip, err := banner.IPFromMaddr(remoteAddr); err == nil {
+		if record, found := t.records[recordKey(KindIP, ip.String())]; found {
+			ipScore = t.score(record, now)
+		}
+	}
+	if peerScore <= t.config.BanThreshold || ipScore <= t.config.BanThreshold {
+		t.banPeer(id, now)
+	}
+}
+
+func (t *Tracker) update(id peer.ID, tag string, affectsIPs bool, updateTag func(current float64) float64) {
+	t.mut.Lock()
+	defer t.mut.Unlock()
+	now := time.Now()
+
+	peerRecord := t.getOrCreateRecord(KindPeer, id.Pretty(), now)
+	peerScore := t.updateRecord(peerRecord, tag, now, updateTag)
+	t.config.ConnManager.TagPeer(id, connManagerTag, int(math.Round(peerScore)))
+	shouldBan := peerScore <= t.config.BanThreshold
+
+	if affectsIPs && t.config.Host != nil {
+		for _, conn := range t.config.Host.Network().ConnsToPeer(id) {
+			ip, err := banner.IPFromMaddr(conn.RemoteMultiaddr())
+			if err != nil {
+				continue
+			}
+			ipRecord := t.getOrCreateRecord(KindIP, ip.String(), now)
+			ipTag := id.Pretty() + "/" + tag
+			if ipScore := t.updateRecord(ipRecord, ipTag, now, updateTag); ipScore <= t.config.BanThreshold {
+				shouldBan = true
+			}
+		}
+	}
+
+	if shouldBan {
+		t.banPeer(id, now)
+	}
+}
+
+// getOrCreateRecord returns the record with the given kind and subject,
+// creating a new one if needed. t.mut must be held.
+func (t *Tracker) getOrCreateRecord(kind Kind, subject string, now time.Time) *Record {
+	key := recordKey(kind, subject)
+	record, found := t.records[key]
+	if !found {
+		record = &Record{
+			Kind:        kind,
+			Subject:     subject,
+			Tags:        map[string]float64{},
+			LastUpdated: now,
+		}
+		t.records[key] = record
+	}
+	return record
+}
+
+// updateRecord applies decay to the record and updates the given tag. It
+// returns the new total score. t.mut must be held.
+func (t *Tracker) updateRecord(record *Record, tag string, now time.Time, updateTag func(current float64) float64) float64 {
+	t.applyDecay(record, now)
+	oldValue := record.Tags[tag]
+	newValue := updateTag(oldValue)
+	if newValue == 0 {
+		delete(record.Tags, tag)
+	} else {
+		record.Tags[tag] = newValue
+	}
+	score := record.Score()
+	if math.Abs(newValue-oldValue) < minScore {
+		// Tags which are set to the same value for every message (e.g. for
+		// every valid message) only change due to decay. Recording these
+		// changes would fill up the history and cause constant writes.
+		return score
+	}
+	record.History = append(record.History, Event{
+		Time:  now,
+		Tag:   tag,
+		Value: newValue,
+		Score: score,
+	})
+	if len(record.History) > maxHistory {
+		record.History = record.History[len(record.History)-maxHistory:]
+	}
+	t.dirty[recordKey(record.Kind, record.Subject)] = struct{}{}
+	return score
+}
+
+// applyDecay decays all tags of the given record up until now. t.mut must be
+// held.
+func (t *Tracker) applyDecay(record *Record, now time.Time) {
+	factor := decayFactor(record.LastUpdated, now, t.config.HalfLife)
+	for tag, value := range record.Tags {
+		decayed := value * factor
+		if math.Abs(decayed) < minScore {
+			delete(record.Tags, tag)
+		} else {
+			record.Tags[tag] = decayed
+		}
+	}
+	record.LastUpdated = now
+}
+
+// score returns the total score of the given record at the given time. t.mut
+// must be held.
+func (t *Tracker) score(record *Record, now time.Time) float64 {
+	return record.Score() * decayFactor(record.LastUpdated, now, t.config.HalfLife)
+}
+
+// banPeer marks the given peer as banned, bans the IP addresses of all
+// connections to it and then closes them. Peers connected from a protected IP
+// address and protected peers are never disconnected. t.mut must be held.
+func (t *Tracker) banPeer(id peer.ID, now time.Time) {
+	peerRecord := t.getOrCreateRecord(KindPeer, id.Pretty(), now)
+	if peerRecord.Protected {
+		return
+	}
+	t.lowerScoreForBan(peerRecord, "ban", now)
+	peerRecord.Banned = true
+	if err := t.save(peerRecord); err != nil {
+		log.WithError(err).WithField("remotePeerID", id.String()).Error("could not save peer reputation")
+	}
+	if t.config.Host == nil {
+		return
+	}
+	isProtected := false
+	for _, conn := range t.config.Host.Network().ConnsToPeer(id) {
+		remoteAddr := conn.RemoteMultiaddr()
+		if ip, err := banner.IPFromMaddr(remoteAddr); err == nil {
+			// Make sure that the ban of the IP address lasts as long as the
+			// ban of the peer.
+			ipRecord := t.getOrCreateRecord(KindIP, ip.String(), now)
+			if !ipRecord.Protected {
+				t.lowerScoreForBan(ipRecord, id.Pretty()+"/ban", now)
+				ipRecord.Banned = true
+				if err := t.save(ipRecord); err != nil {
+					log.WithError(err).WithField("ip", ipRecord.Subject).Error("could not save peer reputation")
+				}
+			}
+		}
+		if t.config.Banner != nil {
+			if err := t.config.Banner.BanIP(remoteAddr); err == banner.ErrProtectedIP {
+				isProtected = true
+			} else if err != nil {
+				log.WithFields(log.Fields{
+					"remotePeerID":    id.String(),
+					"remoteMultiaddr": remoteAddr.String(),
+					"error":           err.Error(),
+				}).Error("could not ban peer")
+			}
+		}
+	}
+	if isProtected {
+		return
+	}
+	log.WithField("remotePeerID", id.String()).Warn("banning peer due to low reputation")
+	// Closing the connections triggers network notifications, so don't wait for
+	// it while holding the lock.
+	go func() {
+		_ = t.config.Host.Network().ClosePeer(id)
+	}()
+}
+
+// lowerScoreForBan lowers the score of the given record to banScoreFactor *
+// BanThreshold by adjusting the given tag, unless it is already lower. t.mut
+// must be held.
+func (t *Tracker) lowerScoreForBan(record *Record, tag string, now time.Time) {
+	banScore := banScoreFactor * t.config.BanThreshold
+	if score := t.score(record, now); score > banScore {
+		t.updateRecord(record, tag, now, func(current float64) float64 {
+			return current + banScore - score
+		})
+	}
+}
+
+// updateBans bans all IP addresses whose score is at or below the ban
+// threshold and lifts the ban for any IP addresses whose score has decayed
+// above it. t.mut must be held.
+func (t *Tracker) updateBans(now time.Time) {
+	for key, record := range t.records {
+		if !record.Banned {
+			continue
+		}
+		stillBanned := t.score(record, now) <= t.config.BanThreshold
+		if !stillBanned {
+			record.Banned = false
+			t.dirty[key] = struct{}{}
+		}
+		if record.Kind != KindIP || t.config.Banner == nil {
+			continue
+		}
+		maddr, err := banner.MaddrFromIP(net.ParseIP(record.Subject))
+		if err != nil {
+			continue
+		}
+		if stillBanned {
+			if err := t.config.Banner.BanIP(maddr); err != nil && err != banner.ErrProtectedIP {
+				log.WithError(err).WithField("ip", record.Subject).Error("could not ban IP address")
+			}
+		} else {
+			log.WithField("ip", record.Subject).Debug("lifting ban for IP address")
+			_ = t.config.Banner.UnbanIP(maddr)
+		}
+	}
+}
+
+func (t *Tracker) periodicallyUpdate(ctx context.Context) {
+	ticker := time.NewTicker(updateInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			t.mut.Lock()
+			now := time.Now()
+			if t.config.Host != nil {
+				for _, id := range t.config.Host.Network().Peers() {
+					if record, found := t.records[recordKey(KindPeer, id.Pretty())]; found {
+						t.config.ConnManager.TagPeer(id, connManagerTag, int(math.Round(t.score(record, now))))
+					}
+				}
+			}
+			t.updateBans(now)
+			t.prune(now)
+			t.flush()
+			t.mut.Unlock()
+		}
+	}
+}
+
+// prune removes all records which are neither banned nor protected and whose
+// tags have all decayed to zero, both from memory and from the store. t.mut
+// must be held.
+func (t *Tracker) prune(now time.Time) {
+	for key, record := range t.records {
+		if record.Banned || record.Protected {
+			continue
+		}
+		t.applyDecay(record, now)
+		if len(record.Tags) > 0 {
+			continue
+		}
+		delete(t.records, key)
+		delete(t.dirty, key)
+		if t.config.Store != nil {
+			if err := t.config.Store.DeletePeerReputation(record); err != nil {
+				log.WithError(err).WithField("key", key).Error("could not delete peer reputation")
+			}
+		}
+	}
+}
+
+// applyProtections protects all peers and IP addresses whose records are
+// marked as protected. t.mut must be held.
+func (t *Tracker) applyProtections() error {
+	for _, record := range t.records {
+		if !record.Protected {
+			continue
+		}
+		switch record.Kind {
+		case KindPeer:
+			id, err := peer.IDB58Decode(record.Subject)
+			if err != nil {
+				return err
+			}
+			t.config.ConnManager.Protect(id, protectTag)
+		case KindIP:
+			if t.config.Banner == nil {
+				continue
+			}
+			maddr, err := banner.MaddrFromIP(net.ParseIP(record.Subject))
+			if err != nil {
+				return err
+			}
+			if err := t.config.Banner.ProtectIP(maddr); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// save immediately writes the given record to the store. t.mut must be held.
+func (t *Tracker) save(record *Record) error {
+	key := recordKey(record.Kind, record.Subject)
+	if t.config.Store == nil {
+		delete(t.dirty, key)
+		return nil
+	}
+	if err := t.config.Store.SavePeerReputation(record); err != nil {
+		return err
+	}
+	delete(t.dirty, key)
+	return nil
+}
+
+// flush writes all changed records to the store. t.mut must be held.
+func (t *Tracker) flush() {
+	if t.config.Store == nil {
+		t.dirty = map[string]struct{}{}
+		return
+	}
+	for key := range t.dirty {
+		record, found := t.records[key]
+		if !found {
+			continue
+		}
+		if err := t.config.Store.SavePeerReputation(record); err != nil {
+			log.WithError(err).WithField("key", key).Error("could not save peer reputation")
+			continue
+		}
+		delete(t.dirty, key)
+	}
+}
+
+func decayFactor(from time.Time, to time.Time, halfLife time.Duration) float64 {
+	elapsed := to.Sub(from)
+	if elapsed <= 0 {
+		return 1
+	}
+	return math.Pow(0.5, float64(elapsed)/float64(halfLife))
+}
@@ -0,0 +1,327 @@
+package reputation
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+	ma "github.com/multiformats/go-multiaddr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var peerIDStrings = []string{
+	"16Uiu2HAmGd949LwaV4KNvK2WDSiMVy7xEmW983VH75CMmefmMpP7",
+	"16Uiu2HAmVqV4kepwSiNRmvKiBxwpt4EQJi3pAe9auSMyGjzA1eBZ",
+}
+
+var peerIDs []peer.ID
+
+func init() {
+	for _, peerIDString := range peerIDStrings {
+		peerID, _ := peer.IDB58Decode(peerIDString)
+		peerIDs = append(peerIDs, peerID)
+	}
+}
+
+type fakeConnManager struct {
+	mut       sync.Mutex
+	tags      map[peer.ID]int
+	protected map[peer.ID]bool
+}
+
+func newFakeConnManager() *fakeConnManager {
+	return &fakeConnManager{
+		tags:      map[peer.ID]int{},
+		protected: map[peer.ID]bool{},
+	}
+}
+
+func (c *fakeConnManager) Protect(id peer.ID, tag string) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	c.protected[id] = true
+}
+
+func (c *fakeConnManager) Unprotect(id peer.ID, tag string) bool {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	wasProtected := c.protected[id]
+	delete(c.protected, id)
+	return wasProtected
+}
+
+func (c *fakeConnManager) isProtected(id peer.ID) bool {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	return c.protected[id]
+}
+
+func (c *fakeConnManager) TagPeer(id peer.ID, tag string, val int) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	c.tags[id] = val
+}
+
+func (c *fakeConnManager) getTag(id peer.ID) int {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	return c.tags[id]
+}
+
+type memoryStore struct {
+	mut     sync.Mutex
+	records map[string]Record
+}
+
+func (s *memoryStore) SavePeerReputation(record *Record) error {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	s.records[string(record.ID())] = *record
+	return nil
+}
+
+func (s *memoryStore) DeletePeerReputation(record *Record) error {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	delete(s.records, string(record.ID()))
+	return nil
+}
+
+func (s *memoryStore) FindPeerReputations() ([]*Record, error) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	records := []*Record{}
+	for _, record := range s.records {
+		record := record
+		records = append(records, &record)
+	}
+	return records, nil
+}
+
+func TestTrackerScores(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	connManager := newFakeConnManager()
+	tracker, err := New(ctx, Config{ConnManager: connManager})
+	require.NoError(t, err)
+
+	tracker.AddScore(peerIDs[0], "a", 5)
+	tracker.AddScore(peerIDs[0], "a", 5)
+	tracker.SetScore(peerIDs[0], "b", -3)
+	assert.InDelta(t, 7, tracker.Score(peerIDs[0]), 0.01)
+	assert.Equal(t, 7, connManager.getTag(peerIDs[0]))
+	assert.Equal(t, 0.0, tracker.Score(peerIDs[1]))
+
+	tracker.UnsetScore(peerIDs[0], "a")
+	assert.InDelta(t, -3, tracker.Score(peerIDs[0]), 0.01)
+	assert.Equal(t, -3, connManager.getTag(peerIDs[0]))
+
+	records := tracker.Records(KindPeer)
+	require.Len(t, records, 1)
+	assert.Equal(t, peerIDs[0].Pretty(), records[0].Subject)
+	assert.Len(t, records[0].History, 4)
+	assert.Len(t, tracker.Records(KindIP), 0)
+}
+
+func TestTrackerDecay(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	tracker, err := New(ctx, Config{ConnManager: newFakeConnManager()})
+	require.NoError(t, err)
+
+	tracker.SetScore(peerIDs[0], "a", 40)
+	tracker.mut.Lock()
+	record := tracker.records[recordKey(KindPeer, peerIDs[0].Pretty())]
+	record.LastUpdated = record.LastUpdated.Add(-DefaultHalfLife)
+	tracker.mut.Unlock()
+	assert.InDelta(t, 20, tracker.Score(peerIDs[0]), 0.01)
+
+	// Adding to the score applies the decay first.
+	tracker.AddScore(peerIDs[0], "a", 10)
+	assert.InDelta(t, 30, tracker.Score(peerIDs[0]), 0.01)
+}
+
+func TestTrackerBan(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	tracker, err := New(ctx, Config{ConnManager: newFakeConnManager()})
+	require.NoError(t, err)
+
+	for i := 0; i < 9; i++ {
+		tracker.AddScore(peerIDs[0], "invalid", -11)
+	}
+	records := tracker.Records(KindPeer)
+	require.Len(t, records, 1)
+	assert.False(t, records[0].Banned)
+
+	tracker.AddScore(peerIDs[0], "invalid", -11)
+	records = tracker.Records(KindPeer)
+	require.Len(t, records, 1)
+	assert.True(t, records[0].Banned)
+	// Banning lowers the score so that the ban lasts for at least one half-life.
+	assert.InDelta(t, banScoreFactor*DefaultBanThreshold, tracker.Score(peerIDs[0]), 0.01)
+
+	tracker.mut.Lock()
+	record := tracker.records[recordKey(KindPeer, peerIDs[0].Pretty())]
+	record.LastUpdated = record.LastUpdated.Add(-DefaultHalfLife + time.Minute)
+	tracker.updateBans(time.Now())
+	tracker.mut.Unlock()
+	records = tracker.Records(KindPeer)
+	require.Len(t, records, 1)
+	assert.True(t, records[0].Banned)
+
+	// The ban is lifted once the score decays above the threshold.
+	tracker.mut.Lock()
+	record.LastUpdated = record.LastUpdated.Add(-DefaultHalfLife)
+	tracker.updateBans(time.Now())
+	tracker.mut.Unlock()
+	records = tracker.Records(KindPeer)
+	require.Len(t, records, 1)
+	assert.False(t, records[0].Banned)
+}
+
+func TestTrackerBanIsPersistedImmediately(t *testing.T) {
+	store := &memoryStore{records: map[string]Record{}}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	tracker, err := New(ctx, Config{
+		ConnManager: newFakeConnManager(),
+		Store:       store,
+	})
+	require.NoError(t, err)
+
+	tracker.AddScore(peerIDs[0], "invalid", -100)
+	records, err := store.FindPeerReputations()
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.True(t, records[0].Banned)
+}
+
+func TestTrackerHistoryIgnoresUnchangedTags(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	tracker, err := New(ctx, Config{ConnManager: newFakeConnManager()})
+	require.NoError(t, err)
+
+	for i := 0; i < 2*maxHistory; i++ {
+		tracker.SetScore(peerIDs[0], "valid", 5)
+	}
+	records := tracker.Records(KindPeer)
+	require.Len(t, records, 1)
+	assert.Len(t, records[0].History, 1)
+
+	for i := 0; i < 2*maxHistory; i++ {
+		tracker.AddScore(peerIDs[0], "invalid", -1)
+	}
+	records = tracker.Records(KindPeer)
+	require.Len(t, records, 1)
+	assert.Len(t, records[0].History, maxHistory)
+}
+
+func TestTrackerPrune(t *testing.T) {
+	store := &memoryStore{records: map[string]Record{}}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	tracker, err := New(ctx, Config{
+		ConnManager: newFakeConnManager(),
+		Store:       store,
+	})
+	require.NoError(t, err)
+
+	tracker.SetScore(peerIDs[0], "a", 10)
+	tracker.SetScore(peerIDs[1], "a", 10)
+	require.NoError(t, tracker.ProtectPeer(peerIDs[1]))
+	tracker.mut.Lock()
+	tracker.flush()
+	tracker.mut.Unlock()
+	records, err := store.FindPeerReputations()
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+
+	// Records whose tags have decayed to zero are removed unless they are
+	// protected.
+	tracker.mut.Lock()
+	for _, record := range tracker.records {
+		record.LastUpdated = record.LastUpdated.Add(-10 * DefaultHalfLife)
+	}
+	tracker.prune(time.Now())
+	tracker.mut.Unlock()
+	records = tracker.Records(KindPeer)
+	require.Len(t, records, 1)
+	assert.Equal(t, peerIDs[1].Pretty(), records[0].Subject)
+	storedRecords, err := store.FindPeerReputations()
+	require.NoError(t, err)
+	require.Len(t, storedRecords, 1)
+	assert.Equal(t, peerIDs[1].Pretty(), storedRecords[0].Subject)
+}
+
+func TestTrackerPersistence(t *testing.T) {
+	store := &memoryStore{records: map[string]Record{}}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	tracker, err := New(ctx, Config{
+		ConnManager: newFakeConnManager(),
+		Store:       store,
+	})
+	require.NoError(t, err)
+	tracker.SetScore(peerIDs[0], "a", -20)
+	tracker.SetScore(peerIDs[1], "a", 10)
+	tracker.mut.Lock()
+	tracker.flush()
+	tracker.mut.Unlock()
+	records, err := store.FindPeerReputations()
+	require.NoError(t, err)
+	assert.Len(t, records, 2)
+
+	// A new tracker picks up where the old one left off.
+	connManager := newFakeConnManager()
+	newTracker, err := New(ctx, Config{
+		ConnManager: connManager,
+		Store:       store,
+	})
+	require.NoError(t, err)
+	assert.InDelta(t, -20, newTracker.Score(peerIDs[0]), 0.01)
+	assert.InDelta(t, 10, newTracker.Score(peerIDs[1]), 0.01)
+	newTracker.PeerConnected(peerIDs[0], ma.StringCast("/ip4/1.2.3.4/tcp/60558"))
+	assert.Equal(t, -20, connManager.getTag(peerIDs[0]))
+}
+
+func TestTrackerProtectPeer(t *testing.T) {
+	store := &memoryStore{records: map[string]Record{}}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	connManager := newFakeConnManager()
+	tracker, err := New(ctx, Config{
+		ConnManager: connManager,
+		Store:       store,
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, tracker.ProtectPeer(peerIDs[0]))
+	assert.True(t, tracker.IsPeerProtected(peerIDs[0]))
+	assert.True(t, connManager.isProtected(peerIDs[0]))
+	assert.False(t, tracker.IsPeerProtected(peerIDs[1]))
+
+	// Protected peers are never banned.
+	tracker.AddScore(peerIDs[0], "invalid", -200)
+	records := tracker.Records(KindPeer)
+	require.Len(t, records, 1)
+	assert.False(t, records[0].Banned)
+
+	// Protection is persisted immediately.
+	newConnManager := newFakeConnManager()
+	newTracker, err := New(ctx, Config{
+		ConnManager: newConnManager,
+		Store:       store,
+	})
+	require.NoError(t, err)
+	assert.True(t, newTracker.IsPeerProtected(peerIDs[0]))
+	assert.True(t, newConnManager.isProtected(peerIDs[0]))
+
+	require.NoError(t, newTracker.UnprotectPeer(peerIDs[0]))
+	assert.False(t, newTracker.IsPeerProtected(peerIDs[0]))
+	assert.False(t, newConnManager.isProtected(peerIDs[0]))
+}
@@ -10,6 +10,7 @@ export { SupportedProvider } from 'ethereum-types';
 
 import {
     AcceptedOrderInfo,
+    AssetProxy,
     Config,
     ContractAddresses,
     ContractEvent,
@@ -53,6 +54,7 @@ import {
 
 export {
     AcceptedOrderInfo,
+    AssetProxy,
     Config,
     ContractAddresses,
     ContractEvent,
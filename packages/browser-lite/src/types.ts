@@ -185,11 +185,41 @@ export interface Config {
     // all the required fields) are automatically included. For more information
     // on JSON Schemas, see https://json-schema.org/
     customOrderFilter?: JsonSchema;
+    // Determines whether or not Mesh should watch the pending transactions of
+    // the Ethereum RPC endpoint for fills and cancels of stored orders. If
+    // enabled, Mesh emits PENDING_FILL and PENDING_CANCEL order events as soon
+    // as a matching transaction is seen. The fillability of orders is not
+    // changed until the transaction is mined. Defaults to false.
+    enableMempoolWatcher?: boolean;
+    // The polling interval (in seconds) to wait before checking for new pending
+    // transactions. Has no effect unless enableMempoolWatcher is true. Defaults
+    // to 1 second.
+    mempoolPollingIntervalSeconds?: number;
+    // Determines how Mesh fetches the on-chain state needed to validate orders.
+    // "devutils" uses a single call to the DevUtils contract for many orders at
+    // once. "direct" makes individual calls to the Exchange and token contracts
+    // instead, which results in many more Ethereum RPC requests but works on
+    // chains where DevUtils is not deployed. Defaults to "devutils".
+    orderValidatorBackend?: 'devutils' | 'direct';
+    // Asset proxies which are not built into the 0x protocol but whose asset
+    // data uses the same encoding as ERC20, ERC721 or ERC1155 asset data.
+    // Orders using these asset proxies are validated as if they used the
+    // corresponding standard asset proxy, except that allowances are checked
+    // for the custom asset proxy address. Requires orderValidatorBackend to be
+    // "direct".
+    customAssetProxies?: AssetProxy[];
     // Offers the ability to use your own web3 provider for all Ethereum RPC
     // requests instead of the default.
     web3Provider?: SupportedProvider;
 }
 
+export interface AssetProxy {
+    // The hex-encoded 4-byte asset proxy ID.
+    id: string;
+    tokenStandard: 'ERC20Token' | 'ERC721Token' | 'ERC1155Assets';
+    address: string;
+}
+
 export interface ContractAddresses {
     exchange: string;
     devUtils: string;
@@ -200,6 +230,8 @@ export interface ContractAddresses {
     coordinatorRegistry?: string;
     weth9?: string;
     zrxToken?: string;
+    exchangeProxy?: string;
+    multicall?: string;
 }
 
 export enum Verbosity {
@@ -253,6 +285,10 @@ export interface WrapperConfig {
     customContractAddresses?: string; // json-encoded string instead of Object.
     maxOrdersInStorage?: number;
     customOrderFilter?: string; // json-encoded string instead of Object
+    enableMempoolWatcher?: boolean;
+    mempoolPollingIntervalSeconds?: number;
+    orderValidatorBackend?: string;
+    customAssetProxies?: string; // json-encoded string instead of an array.
     web3Provider?: ZeroExProvider; // Standardized ZeroExProvider instead the more permissive SupportedProvider interface
 }
 
@@ -543,6 +579,8 @@ export enum OrderEventEndState {
     Unexpired = 'UNEXPIRED',
     Unfunded = 'UNFUNDED',
     FillabilityIncreased = 'FILLABILITY_INCREASED',
+    PendingFill = 'PENDING_FILL',
+    PendingCancel = 'PENDING_CANCEL',
     StoppedWatching = 'STOPPED_WATCHING',
 }
 
@@ -653,6 +691,7 @@ export interface WrapperStats {
     ethereumChainID: number;
     latestBlock: LatestBlock;
     numPeers: number;
+    reachability: string;
     numOrders: number;
     numOrdersIncludingRemoved: number;
     numPinnedOrders: number;
@@ -671,6 +710,7 @@ export interface Stats {
     ethereumChainID: number;
     latestBlock: LatestBlock;
     numPeers: number;
+    reachability: string;
     numOrders: number;
     numOrdersIncludingRemoved: number;
     numPinnedOrders: number;
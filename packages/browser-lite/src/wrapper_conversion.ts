@@ -53,6 +53,8 @@ export function configToWrapperConfig(config: Config): WrapperConfig {
     const bootstrapList = config.bootstrapList == null ? undefined : config.bootstrapList.join(',');
     const customContractAddresses =
         config.customContractAddresses == null ? undefined : JSON.stringify(config.customContractAddresses);
+    const customAssetProxies =
+        config.customAssetProxies == null ? undefined : JSON.stringify(config.customAssetProxies);
     const customOrderFilter = config.customOrderFilter == null ? undefined : JSON.stringify(config.customOrderFilter);
     const standardizedProvider =
         config.web3Provider == null ? undefined : providerUtils.standardizeOrThrow(config.web3Provider);
@@ -60,6 +62,7 @@ export function configToWrapperConfig(config: Config): WrapperConfig {
         ...config,
         bootstrapList,
         customContractAddresses,
+        customAssetProxies,
         customOrderFilter,
         web3Provider: standardizedProvider,
     };
@@ -712,6 +712,7 @@ function testStats(stats: WrapperStats[]): void {
     printer('latestBlock | number', stats[0].latestBlock.number === 1500);
     printer('numOrders', stats[0].numOrders === 100000);
     printer('numPeers', stats[0].numPeers === 200);
+    printer('reachability', stats[0].reachability === 'public');
     printer('numOrdersIncludingRemoved', stats[0].numOrdersIncludingRemoved === 200000);
     printer('numPinnedOrders', stats[0].numPinnedOrders === 400);
     printer(
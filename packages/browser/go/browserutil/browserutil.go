@@ -37,6 +37,9 @@ func ConvertConfig(jsConfig js.Value) (core.Config, error) {
 		EnableEthereumRPCRateLimiting:    true,
 		MaxOrdersInStorage:               100000,
 		CustomOrderFilter:                orderfilter.DefaultCustomOrderSchema,
+		EnableMempoolWatcher:             false,
+		MempoolPollingInterval:           1 * time.Second,
+		OrderValidatorBackend:            "devutils",
 	}
 
 	// Required config options
@@ -80,6 +83,18 @@ func ConvertConfig(jsConfig js.Value) (core.Config, error) {
 	if customOrderFilter := jsConfig.Get("customOrderFilter"); !jsutil.IsNullOrUndefined(customOrderFilter) {
 		config.CustomOrderFilter = customOrderFilter.String()
 	}
+	if enableMempoolWatcher := jsConfig.Get("enableMempoolWatcher"); !jsutil.IsNullOrUndefined(enableMempoolWatcher) {
+		config.EnableMempoolWatcher = enableMempoolWatcher.Bool()
+	}
+	if mempoolPollingIntervalSeconds := jsConfig.Get("mempoolPollingIntervalSeconds"); !jsutil.IsNullOrUndefined(mempoolPollingIntervalSeconds) {
+		config.MempoolPollingInterval = time.Duration(mempoolPollingIntervalSeconds.Int()) * time.Second
+	}
+	if orderValidatorBackend := jsConfig.Get("orderValidatorBackend"); !jsutil.IsNullOrUndefined(orderValidatorBackend) {
+		config.OrderValidatorBackend = orderValidatorBackend.String()
+	}
+	if customAssetProxies := jsConfig.Get("customAssetProxies"); !jsutil.IsNullOrUndefined(customAssetProxies) {
+		config.CustomAssetProxies = customAssetProxies.String()
+	}
 	if ethereumRPCURL := jsConfig.Get("ethereumRPCURL"); !jsutil.IsNullOrUndefined(ethereumRPCURL) && ethereumRPCURL.String() != "" {
 		config.EthereumRPCURL = ethereumRPCURL.String()
 	}
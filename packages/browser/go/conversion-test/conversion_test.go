@@ -341,6 +341,7 @@ func registerStatsTest(description string) {
 	registerStatsField(description, "latestBlock | number")
 	registerStatsField(description, "numOrders")
 	registerStatsField(description, "numPeers")
+	registerStatsField(description, "reachability")
 	registerStatsField(description, "numOrdersIncludingRemoved")
 	registerStatsField(description, "numPinnedOrders")
 	registerStatsField(description, "maxExpirationTime")
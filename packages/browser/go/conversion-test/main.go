@@ -497,6 +497,7 @@ func setGlobals() {
 						Number: 1500,
 					},
 					NumPeers:                          200,
+					Reachability:                      "public",
 					NumOrders:                         100000,
 					NumOrdersIncludingRemoved:         200000,
 					NumPinnedOrders:                   400,
@@ -676,6 +677,8 @@ func setGlobals() {
 				EnableEthereumRPCRateLimiting:    true,
 				MaxOrdersInStorage:               100000,
 				CustomOrderFilter:                orderfilter.DefaultCustomOrderSchema,
+				MempoolPollingInterval:           1 * time.Second,
+				OrderValidatorBackend:            "devutils",
 				EthereumChainID:                  1337,
 			}, "", false)
 			testConvertConfig("FullConfig", args[4], core.Config{
@@ -692,6 +695,8 @@ func setGlobals() {
 				EnableEthereumRPCRateLimiting:    false,
 				MaxOrdersInStorage:               500000,
 				CustomOrderFilter:                `{"id":"/foobarbaz"}`,
+				MempoolPollingInterval:           1 * time.Second,
+				OrderValidatorBackend:            "devutils",
 				CustomContractAddresses:          "{\"exchange\":\"0x48bacb9266a570d521063ef5dd96e61686dbe788\",\"devUtils\":\"0x38ef19fdf8e8415f18c307ed71967e19aac28ba1\",\"erc20Proxy\":\"0x1dc4c1cefef38a777b15aa20260a54e584b16c48\",\"erc721Proxy\":\"0x1d7022f5b17d2f8b695918fb48fa1089c9f85401\",\"erc1155Proxy\":\"0x64517fa2b480ba3678a2a3c0cf08ef7fd4fad36f\"}",
 				EthereumChainID:                  1337,
 				EthereumRPCURL:                   "http://localhost:8545",
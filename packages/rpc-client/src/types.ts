@@ -193,6 +193,12 @@ export interface StringifiedExchangeCancelUpToEvent {
     orderEpoch: string;
 }
 
+export interface ExchangeSignatureValidatorApprovalEvent {
+    signerAddress: string;
+    validatorAddress: string;
+    isApproved: boolean;
+}
+
 export interface WethWithdrawalEvent {
     owner: string;
     value: BigNumber;
@@ -225,6 +231,7 @@ export enum ContractEventKind {
     ExchangeFillEvent = 'ExchangeFillEvent',
     ExchangeCancelEvent = 'ExchangeCancelEvent',
     ExchangeCancelUpToEvent = 'ExchangeCancelUpToEvent',
+    ExchangeSignatureValidatorApprovalEvent = 'ExchangeSignatureValidatorApprovalEvent',
     WethDepositEvent = 'WethDepositEvent',
     WethWithdrawalEvent = 'WethWithdrawalEvent',
 }
@@ -242,7 +249,8 @@ export type StringifiedContractEventParameters =
     | ERC1155ApprovalForAllEvent
     | StringifiedERC1155TransferSingleEvent
     | StringifiedERC1155TransferBatchEvent
-    | ExchangeCancelEvent;
+    | ExchangeCancelEvent
+    | ExchangeSignatureValidatorApprovalEvent;
 
 export interface StringifiedContractEvent {
     blockHash: string;
@@ -268,7 +276,8 @@ export type ContractEventParameters =
     | ExchangeCancelEvent
     | ERC1155ApprovalForAllEvent
     | ERC1155TransferSingleEvent
-    | ERC1155TransferBatchEvent;
+    | ERC1155TransferBatchEvent
+    | ExchangeSignatureValidatorApprovalEvent;
 
 export interface ContractEvent {
     blockHash: string;
@@ -292,6 +301,8 @@ export enum OrderEventEndState {
     StoppedWatching = 'STOPPED_WATCHING',
     Unfunded = 'UNFUNDED',
     FillabilityIncreased = 'FILLABILITY_INCREASED',
+    PendingFill = 'PENDING_FILL',
+    PendingCancel = 'PENDING_CANCEL',
 }
 
 export interface OrderEventPayload {
@@ -433,6 +444,7 @@ export interface GetStatsResponse {
     ethereumChainID: number;
     latestBlock: LatestBlock;
     numPeers: number;
+    reachability: string;
     numOrders: number;
     numOrdersIncludingRemoved: number;
     numPinnedOrders: number;
@@ -13,6 +13,7 @@ import {
     ERC1155ApprovalForAllEvent,
     ERC721ApprovalForAllEvent,
     ExchangeCancelEvent,
+    ExchangeSignatureValidatorApprovalEvent,
     GetOrdersResponse,
     GetStatsResponse,
     HeartbeatEventPayload,
@@ -219,6 +220,9 @@ export class WSClient {
                         orderEpoch: new BigNumber(exchangeCancelUpToEvent.orderEpoch),
                     };
                     break;
+                case ContractEventKind.ExchangeSignatureValidatorApprovalEvent:
+                    parameters = rawParameters as ExchangeSignatureValidatorApprovalEvent;
+                    break;
                 case ContractEventKind.WethDepositEvent:
                     const wethDepositEvent = rawParameters as StringifiedWethDepositEvent;
                     parameters = {
@@ -155,6 +155,7 @@ blockchainTests.resets('WSClient', env => {
                         hash: '',
                     },
                     numPeers: 0,
+                    reachability: 'unknown',
                     numOrders: 0,
                     numOrdersIncludingRemoved: 0,
                     numPinnedOrders: 0,
@@ -4,11 +4,13 @@ package rpc
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 
 	"github.com/0xProject/0x-mesh/common/types"
 	"github.com/0xProject/0x-mesh/zeroex"
 	"github.com/0xProject/0x-mesh/zeroex/ordervalidator"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/rpc"
 	peer "github.com/libp2p/go-libp2p-core/peer"
 	peerstore "github.com/libp2p/go-libp2p-peerstore"
@@ -32,6 +34,12 @@ func NewClient(addr string) (*Client, error) {
 	}, nil
 }
 
+// Close closes the connection to the server. Any pending requests and
+// subscriptions fail.
+func (c *Client) Close() {
+	c.rpcClient.Close()
+}
+
 // AddOrders adds orders to the 0x Mesh node and broadcasts them throughout the
 // 0x Mesh network.
 func (c *Client) AddOrders(orders []*zeroex.SignedOrder, opts ...types.AddOrdersOpts) (*ordervalidator.ValidationResults, error) {
@@ -39,12 +47,46 @@ func (c *Client) AddOrders(orders []*zeroex.SignedOrder, opts ...types.AddOrders
 	if len(opts) > 1 {
 		return nil, errors.New("invalid number of add orders opts")
 	}
+	args := []interface{}{orders}
 	if len(opts) == 1 {
-		if err := c.rpcClient.Call(&validationResults, "mesh_addOrders", orders, opts[0]); err != nil {
-			return nil, err
-		}
+		args = append(args, opts[0])
 	}
-	if err := c.rpcClient.Call(&validationResults, "mesh_addOrders", orders); err != nil {
+	if err := c.rpcClient.Call(&validationResults, "mesh_addOrders", args...); err != nil {
+		return nil, err
+	}
+	return &validationResults, nil
+}
+
+// AddOrdersV4 adds Exchange V4 orders to the 0x Mesh node. V4 orders are not
+// shared with other peers.
+func (c *Client) AddOrdersV4(orders []*zeroex.SignedOrderV4, opts ...types.AddOrdersOpts) (*ordervalidator.ValidationResults, error) {
+	var validationResults ordervalidator.ValidationResults
+	if len(opts) > 1 {
+		return nil, errors.New("invalid number of add orders opts")
+	}
+	args := []interface{}{orders}
+	if len(opts) == 1 {
+		args = append(args, opts[0])
+	}
+	if err := c.rpcClient.Call(&validationResults, "mesh_addOrdersV4", args...); err != nil {
+		return nil, err
+	}
+	return &validationResults, nil
+}
+
+// CreateOrder creates an order from the given parameters, signs it with the
+// order signer configured on the Mesh node and adds it to the node. Missing
+// parameters are filled with default values.
+func (c *Client) CreateOrder(params types.CreateOrderParams, opts ...types.AddOrdersOpts) (*ordervalidator.ValidationResults, error) {
+	var validationResults ordervalidator.ValidationResults
+	if len(opts) > 1 {
+		return nil, errors.New("invalid number of add orders opts")
+	}
+	args := []interface{}{params}
+	if len(opts) == 1 {
+		args = append(args, opts[0])
+	}
+	if err := c.rpcClient.Call(&validationResults, "mesh_createOrder", args...); err != nil {
 		return nil, err
 	}
 	return &validationResults, nil
@@ -82,6 +124,124 @@ func (c *Client) GetStats() (*types.Stats, error) {
 	return getStatsResponse, nil
 }
 
+// GetPeers retrieves information about all connected peers.
+func (c *Client) GetPeers() ([]*types.PeerInfo, error) {
+	var peers []*types.PeerInfo
+	if err := c.rpcClient.Call(&peers, "mesh_getPeers"); err != nil {
+		return nil, err
+	}
+	return peers, nil
+}
+
+// DisconnectPeer closes all connections to the peer with the given ID.
+func (c *Client) DisconnectPeer(peerID peer.ID) error {
+	return c.rpcClient.Call(nil, "mesh_disconnectPeer", peer.IDB58Encode(peerID))
+}
+
+// BanIP bans the given IP address (e.g. "159.65.4.82") or the IP address of
+// the given multiaddress and disconnects from all peers connected from it.
+func (c *Client) BanIP(ipOrMultiaddr string) error {
+	return c.rpcClient.Call(nil, "mesh_banIP", ipOrMultiaddr)
+}
+
+// UnbanIP lifts the ban for the given IP address or the IP address of the
+// given multiaddress.
+func (c *Client) UnbanIP(ipOrMultiaddr string) error {
+	return c.rpcClient.Call(nil, "mesh_unbanIP", ipOrMultiaddr)
+}
+
+// ProtectPeer persistently protects the peer with the given ID from being
+// banned or disconnected.
+func (c *Client) ProtectPeer(peerID peer.ID) error {
+	return c.rpcClient.Call(nil, "mesh_protectPeer", peer.IDB58Encode(peerID))
+}
+
+// UnprotectPeer removes the protection for the peer with the given ID.
+func (c *Client) UnprotectPeer(peerID peer.ID) error {
+	return c.rpcClient.Call(nil, "mesh_unprotectPeer", peer.IDB58Encode(peerID))
+}
+
+// ProtectIP persistently protects the given IP address or the IP address of
+// the given multiaddress from being banned.
+func (c *Client) ProtectIP(ipOrMultiaddr string) error {
+	return c.rpcClient.Call(nil, "mesh_protectIP", ipOrMultiaddr)
+}
+
+// UnprotectIP removes the protection for the given IP address or the IP
+// address of the given multiaddress.
+func (c *Client) UnprotectIP(ipOrMultiaddr string) error {
+	return c.rpcClient.Call(nil, "mesh_unprotectIP", ipOrMultiaddr)
+}
+
+// GetPeerReputations retrieves the reputation of all known peers and IP
+// addresses, sorted from the lowest to the highest score.
+func (c *Client) GetPeerReputations() ([]*types.PeerReputation, error) {
+	var reputations []*types.PeerReputation
+	if err := c.rpcClient.Call(&reputations, "mesh_getPeerReputations"); err != nil {
+		return nil, err
+	}
+	return reputations, nil
+}
+
+// GetPeerReputation retrieves the reputation of the peer or IP address with
+// the given ID. It returns an empty slice if the reputation is unknown.
+func (c *Client) GetPeerReputation(subject string) ([]*types.PeerReputation, error) {
+	var reputations []*types.PeerReputation
+	if err := c.rpcClient.Call(&reputations, "mesh_getPeerReputations", subject); err != nil {
+		return nil, err
+	}
+	return reputations, nil
+}
+
+// GetOrderFilters retrieves information about all custom order filters used
+// by the Mesh node, starting with the primary filter.
+func (c *Client) GetOrderFilters() ([]*types.OrderFilterInfo, error) {
+	var filters []*types.OrderFilterInfo
+	if err := c.rpcClient.Call(&filters, "mesh_getOrderFilters"); err != nil {
+		return nil, err
+	}
+	return filters, nil
+}
+
+// AddOrderFilter adds a custom order filter to the Mesh node at runtime. The
+// filter is a stringified JSON Schema in the same format as the
+// CUSTOM_ORDER_FILTER config option.
+func (c *Client) AddOrderFilter(customOrderFilter string) (*types.OrderFilterInfo, error) {
+	var filter types.OrderFilterInfo
+	if err := c.rpcClient.Call(&filter, "mesh_addOrderFilter", json.RawMessage(customOrderFilter)); err != nil {
+		return nil, err
+	}
+	return &filter, nil
+}
+
+// RemoveOrderFilter removes the custom order filter with the given topic from
+// the Mesh node. The primary filter cannot be removed.
+func (c *Client) RemoveOrderFilter(topic string) error {
+	return c.rpcClient.Call(nil, "mesh_removeOrderFilter", topic)
+}
+
+// GetTopMakers retrieves the makers with the most unpinned orders stored by
+// the Mesh node, sorted by number of orders in descending order. If limit is
+// <= 0, all makers are returned.
+func (c *Client) GetTopMakers(limit int) ([]*types.MakerOrderCount, error) {
+	var makers []*types.MakerOrderCount
+	if err := c.rpcClient.Call(&makers, "mesh_getTopMakers", limit); err != nil {
+		return nil, err
+	}
+	return makers, nil
+}
+
+// GetOrderbook retrieves the aggregated orderbook for the given asset pair.
+// Asks are sorted by ascending price and bids by descending price, where the
+// price is the amount of the taker asset per unit of the maker asset.
+func (c *Client) GetOrderbook(makerAssetData, takerAssetData []byte) (*types.Orderbook, error) {
+	var orderbook types.Orderbook
+	if err := c.rpcClient.Call(&orderbook, "mesh_getOrderbook", hexutil.Bytes(makerAssetData), hexutil.Bytes(takerAssetData)); err != nil {
+		return nil, err
+	}
+	return &orderbook, nil
+}
+
 // SubscribeToOrders subscribes a stream of order events
 // Note copied from `go-ethereum` codebase: Slow subscribers will be dropped eventually. Client
 // buffers up to 8000 notifications before considering the subscriber dead. The subscription Err
@@ -91,6 +251,18 @@ func (c *Client) SubscribeToOrders(ctx context.Context, ch chan<- []*zeroex.Orde
 	return c.rpcClient.Subscribe(ctx, "mesh", ch, "orders")
 }
 
+// SubscribeToOrderbook subscribes to a stream of updates of the aggregated
+// orderbook for the given asset pair. The first update contains all price
+// levels. Every following update contains the price levels which changed, with
+// their new totals. Levels with no orders left have been removed.
+// Note copied from `go-ethereum` codebase: Slow subscribers will be dropped eventually. Client
+// buffers up to 8000 notifications before considering the subscriber dead. The subscription Err
+// channel will receive ErrSubscriptionQueueOverflow. Use a sufficiently large buffer on the channel
+// or ensure that the channel usually has at least one reader to prevent this issue.
+func (c *Client) SubscribeToOrderbook(ctx context.Context, makerAssetData, takerAssetData []byte, ch chan<- *types.OrderbookUpdate) (*rpc.ClientSubscription, error) {
+	return c.rpcClient.Subscribe(ctx, "mesh", ch, "orderbook", hexutil.Bytes(makerAssetData), hexutil.Bytes(takerAssetData))
+}
+
 // SubscribeToHeartbeat subscribes a stream of heartbeats in order to have certainty that the WS
 // connection is still alive.
 // Note copied from `go-ethereum` codebase: Slow subscribers will be dropped eventually. Client
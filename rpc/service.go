@@ -5,13 +5,16 @@ package rpc
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net"
 	"strings"
 	"time"
 
 	"github.com/0xProject/0x-mesh/common/types"
 	"github.com/0xProject/0x-mesh/constants"
+	"github.com/0xProject/0x-mesh/p2p/banner"
 	"github.com/0xProject/0x-mesh/zeroex/ordervalidator"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/rpc"
 	ethrpc "github.com/ethereum/go-ethereum/rpc"
 	peer "github.com/libp2p/go-libp2p-core/peer"
@@ -23,6 +26,10 @@ import (
 // minHeartbeatInterval specifies the interval at which to emit heartbeat events to a subscriber
 var minHeartbeatInterval = 5 * time.Second
 
+// defaultTopMakersLimit is the number of makers returned by mesh_getTopMakers
+// if no limit is given.
+const defaultTopMakersLimit = 10
+
 // rpcService is an /ethereum/go-ethereum/rpc compatible service.
 type rpcService struct {
 	rpcHandler RPCHandler
@@ -32,14 +39,51 @@ type rpcService struct {
 type RPCHandler interface {
 	// AddOrders is called when the client sends an AddOrders request.
 	AddOrders(signedOrdersRaw []*json.RawMessage, opts types.AddOrdersOpts) (*ordervalidator.ValidationResults, error)
+	// AddOrdersV4 is called when the client sends an AddOrdersV4 request.
+	AddOrdersV4(signedOrdersRaw []*json.RawMessage, opts types.AddOrdersOpts) (*ordervalidator.ValidationResults, error)
+	// CreateOrder is called when the client sends a CreateOrder request.
+	CreateOrder(params types.CreateOrderParams, opts types.AddOrdersOpts) (*ordervalidator.ValidationResults, error)
 	// GetOrders is called when the clients sends a GetOrders request
 	GetOrders(page, perPage int, snapshotID string) (*types.GetOrdersResponse, error)
 	// AddPeer is called when the client sends an AddPeer request.
 	AddPeer(peerInfo peerstore.PeerInfo) error
 	// GetStats is called when the client sends an GetStats request.
 	GetStats() (*types.Stats, error)
+	// GetPeers is called when the client sends a GetPeers request.
+	GetPeers() ([]*types.PeerInfo, error)
+	// DisconnectPeer is called when the client sends a DisconnectPeer request.
+	DisconnectPeer(peerID peer.ID) error
+	// BanIP is called when the client sends a BanIP request.
+	BanIP(maddr ma.Multiaddr) error
+	// UnbanIP is called when the client sends an UnbanIP request.
+	UnbanIP(maddr ma.Multiaddr) error
+	// ProtectPeer is called when the client sends a ProtectPeer request.
+	ProtectPeer(peerID peer.ID) error
+	// UnprotectPeer is called when the client sends an UnprotectPeer request.
+	UnprotectPeer(peerID peer.ID) error
+	// ProtectIP is called when the client sends a ProtectIP request.
+	ProtectIP(maddr ma.Multiaddr) error
+	// UnprotectIP is called when the client sends an UnprotectIP request.
+	UnprotectIP(maddr ma.Multiaddr) error
+	// GetPeerReputations is called when the client sends a GetPeerReputations
+	// request.
+	GetPeerReputations(subject string) ([]*types.PeerReputation, error)
+	// GetOrderFilters is called when the client sends a GetOrderFilters
+	// request.
+	GetOrderFilters() ([]*types.OrderFilterInfo, error)
+	// AddOrderFilter is called when the client sends an AddOrderFilter request.
+	AddOrderFilter(customOrderFilter string) (*types.OrderFilterInfo, error)
+	// RemoveOrderFilter is called when the client sends a RemoveOrderFilter
+	// request.
+	RemoveOrderFilter(topic string) error
+	// GetTopMakers is called when the client sends a GetTopMakers request.
+	GetTopMakers(limit int) ([]*types.MakerOrderCount, error)
+	// GetOrderbook is called when the client sends a GetOrderbook request.
+	GetOrderbook(makerAssetData, takerAssetData []byte) (*types.Orderbook, error)
 	// SubscribeToOrders is called when a client sends a Subscribe to `orders` request
 	SubscribeToOrders(ctx context.Context) (*rpc.Subscription, error)
+	// SubscribeToOrderbook is called when a client sends a Subscribe to `orderbook` request
+	SubscribeToOrderbook(ctx context.Context, makerAssetData, takerAssetData []byte) (*rpc.Subscription, error)
 }
 
 // Orders calls rpcHandler.SubscribeToOrders and returns the rpc subscription.
@@ -47,6 +91,12 @@ func (s *rpcService) Orders(ctx context.Context) (*rpc.Subscription, error) {
 	return s.rpcHandler.SubscribeToOrders(ctx)
 }
 
+// Orderbook calls rpcHandler.SubscribeToOrderbook and returns the rpc
+// subscription.
+func (s *rpcService) Orderbook(ctx context.Context, makerAssetData, takerAssetData hexutil.Bytes) (*rpc.Subscription, error) {
+	return s.rpcHandler.SubscribeToOrderbook(ctx, makerAssetData, takerAssetData)
+}
+
 // Heartbeat calls rpcHandler.SubscribeToHeartbeat and returns the rpc subscription.
 func (s *rpcService) Heartbeat(ctx context.Context) (*rpc.Subscription, error) {
 	log.Debug("received heartbeat subscription request via RPC")
@@ -135,6 +185,23 @@ func (s *rpcService) AddOrders(signedOrdersRaw []*json.RawMessage, opts *types.A
 	return s.rpcHandler.AddOrders(signedOrdersRaw, *opts)
 }
 
+// AddOrdersV4 calls rpcHandler.AddOrdersV4 and returns the validation results.
+func (s *rpcService) AddOrdersV4(signedOrdersRaw []*json.RawMessage, opts *types.AddOrdersOpts) (*ordervalidator.ValidationResults, error) {
+	if opts == nil {
+		opts = &defaultAddOrdersOpts
+	}
+	return s.rpcHandler.AddOrdersV4(signedOrdersRaw, *opts)
+}
+
+// CreateOrder calls rpcHandler.CreateOrder and returns the validation results
+// for the created order.
+func (s *rpcService) CreateOrder(params types.CreateOrderParams, opts *types.AddOrdersOpts) (*ordervalidator.ValidationResults, error) {
+	if opts == nil {
+		opts = &defaultAddOrdersOpts
+	}
+	return s.rpcHandler.CreateOrder(params, *opts)
+}
+
 // GetOrders calls rpcHandler.GetOrders and returns the validation results.
 func (s *rpcService) GetOrders(page, perPage int, snapshotID string) (*types.GetOrdersResponse, error) {
 	return s.rpcHandler.GetOrders(page, perPage, snapshotID)
@@ -170,3 +237,133 @@ func (s *rpcService) AddPeer(peerID string, multiaddrs []string) error {
 func (s *rpcService) GetStats() (*types.Stats, error) {
 	return s.rpcHandler.GetStats()
 }
+
+// GetPeers calls rpcHandler.GetPeers. If there is an error, it returns it.
+func (s *rpcService) GetPeers() ([]*types.PeerInfo, error) {
+	return s.rpcHandler.GetPeers()
+}
+
+// DisconnectPeer parses the given peer ID and calls rpcHandler.DisconnectPeer.
+// If there is an error, it returns it.
+func (s *rpcService) DisconnectPeer(peerID string) error {
+	parsedPeerID, err := peer.IDB58Decode(peerID)
+	if err != nil {
+		return err
+	}
+	return s.rpcHandler.DisconnectPeer(parsedPeerID)
+}
+
+// BanIP parses the given IP address or multiaddress and calls
+// rpcHandler.BanIP. If there is an error, it returns it.
+func (s *rpcService) BanIP(ipOrMultiaddr string) error {
+	maddr, err := parseIPOrMultiaddr(ipOrMultiaddr)
+	if err != nil {
+		return err
+	}
+	return s.rpcHandler.BanIP(maddr)
+}
+
+// UnbanIP parses the given IP address or multiaddress and calls
+// rpcHandler.UnbanIP. If there is an error, it returns it.
+func (s *rpcService) UnbanIP(ipOrMultiaddr string) error {
+	maddr, err := parseIPOrMultiaddr(ipOrMultiaddr)
+	if err != nil {
+		return err
+	}
+	return s.rpcHandler.UnbanIP(maddr)
+}
+
+// ProtectPeer parses the given peer ID and calls rpcHandler.ProtectPeer. If
+// there is an error, it returns it.
+func (s *rpcService) ProtectPeer(peerID string) error {
+	parsedPeerID, err := peer.IDB58Decode(peerID)
+	if err != nil {
+		return err
+	}
+	return s.rpcHandler.ProtectPeer(parsedPeerID)
+}
+
+// UnprotectPeer parses the given peer ID and calls rpcHandler.UnprotectPeer.
+// If there is an error, it returns it.
+func (s *rpcService) UnprotectPeer(peerID string) error {
+	parsedPeerID, err := peer.IDB58Decode(peerID)
+	if err != nil {
+		return err
+	}
+	return s.rpcHandler.UnprotectPeer(parsedPeerID)
+}
+
+// ProtectIP parses the given IP address or multiaddress and calls
+// rpcHandler.ProtectIP. If there is an error, it returns it.
+func (s *rpcService) ProtectIP(ipOrMultiaddr string) error {
+	maddr, err := parseIPOrMultiaddr(ipOrMultiaddr)
+	if err != nil {
+		return err
+	}
+	return s.rpcHandler.ProtectIP(maddr)
+}
+
+// UnprotectIP parses the given IP address or multiaddress and calls
+// rpcHandler.UnprotectIP. If there is an error, it returns it.
+func (s *rpcService) UnprotectIP(ipOrMultiaddr string) error {
+	maddr, err := parseIPOrMultiaddr(ipOrMultiaddr)
+	if err != nil {
+		return err
+	}
+	return s.rpcHandler.UnprotectIP(maddr)
+}
+
+// parseIPOrMultiaddr parses either a plain IP address (e.g. "159.65.4.82") or a
+// multiaddress (e.g. "/ip4/159.65.4.82/tcp/60558").
+func parseIPOrMultiaddr(ipOrMultiaddr string) (ma.Multiaddr, error) {
+	if strings.HasPrefix(ipOrMultiaddr, "/") {
+		return ma.NewMultiaddr(ipOrMultiaddr)
+	}
+	ip := net.ParseIP(ipOrMultiaddr)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid IP address: %q", ipOrMultiaddr)
+	}
+	return banner.MaddrFromIP(ip)
+}
+
+// GetPeerReputations calls rpcHandler.GetPeerReputations. If subject is nil,
+// the reputation of all known peers and IP addresses is returned.
+func (s *rpcService) GetPeerReputations(subject *string) ([]*types.PeerReputation, error) {
+	if subject == nil {
+		return s.rpcHandler.GetPeerReputations("")
+	}
+	return s.rpcHandler.GetPeerReputations(*subject)
+}
+
+// GetOrderFilters calls rpcHandler.GetOrderFilters. If there is an error, it
+// returns it.
+func (s *rpcService) GetOrderFilters() ([]*types.OrderFilterInfo, error) {
+	return s.rpcHandler.GetOrderFilters()
+}
+
+// AddOrderFilter calls rpcHandler.AddOrderFilter with the given JSON Schema.
+// If there is an error, it returns it.
+func (s *rpcService) AddOrderFilter(customOrderFilter json.RawMessage) (*types.OrderFilterInfo, error) {
+	return s.rpcHandler.AddOrderFilter(string(customOrderFilter))
+}
+
+// RemoveOrderFilter calls rpcHandler.RemoveOrderFilter. If there is an error,
+// it returns it.
+func (s *rpcService) RemoveOrderFilter(topic string) error {
+	return s.rpcHandler.RemoveOrderFilter(topic)
+}
+
+// GetTopMakers calls rpcHandler.GetTopMakers. If limit is nil, the default
+// number of makers is returned.
+func (s *rpcService) GetTopMakers(limit *int) ([]*types.MakerOrderCount, error) {
+	if limit == nil {
+		return s.rpcHandler.GetTopMakers(defaultTopMakersLimit)
+	}
+	return s.rpcHandler.GetTopMakers(*limit)
+}
+
+// GetOrderbook calls rpcHandler.GetOrderbook and returns the aggregated
+// orderbook for the given asset pair.
+func (s *rpcService) GetOrderbook(makerAssetData, takerAssetData hexutil.Bytes) (*types.Orderbook, error) {
+	return s.rpcHandler.GetOrderbook(makerAssetData, takerAssetData)
+}
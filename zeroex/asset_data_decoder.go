@@ -94,11 +94,27 @@ type assetDataInfo struct {
 
 // AssetDataDecoder decodes 0x order asset data
 type AssetDataDecoder struct {
-	idToAssetDataInfo map[string]assetDataInfo
+	idToAssetDataInfo    map[string]assetDataInfo
+	idToCustomAssetProxy map[string]AssetProxy
 }
 
 // NewAssetDataDecoder instantiates a new asset data decoder
 func NewAssetDataDecoder() *AssetDataDecoder {
+	return newAssetDataDecoder(map[string]AssetProxy{})
+}
+
+// NewAssetDataDecoderWithAssetProxies instantiates a new asset data decoder
+// which also recognizes asset data for the given custom asset proxies. It
+// returns an error if the asset proxies are invalid (see ValidateAssetProxies).
+func NewAssetDataDecoderWithAssetProxies(assetProxies []AssetProxy) (*AssetDataDecoder, error) {
+	idToCustomAssetProxy, err := assetProxiesByID(assetProxies)
+	if err != nil {
+		return nil, err
+	}
+	return newAssetDataDecoder(idToCustomAssetProxy), nil
+}
+
+func newAssetDataDecoder(idToCustomAssetProxy map[string]AssetProxy) *AssetDataDecoder {
 	erc20AssetDataABI, err := abi.JSON(strings.NewReader(erc20AssetDataAbi))
 	if err != nil {
 		log.WithField("erc20AssetDataAbi", erc20AssetDataAbi).Panic("erc20AssetDataAbi should be ABI parsable")
@@ -165,13 +181,40 @@ func NewAssetDataDecoder() *AssetDataDecoder {
 			abi:  erc20BridgeAssetDataABI,
 		},
 	}
+	// Asset data for custom asset proxies is decoded exactly like the asset data
+	// for the token standard it is based on.
+	for _, proxy := range idToCustomAssetProxy {
+		var standardID string
+		switch proxy.TokenStandard {
+		case "ERC20Token":
+			standardID = ERC20AssetDataID
+		case "ERC721Token":
+			standardID = ERC721AssetDataID
+		case "ERC1155Assets":
+			standardID = ERC1155AssetDataID
+		}
+		idToAssetDataInfo[proxy.ID] = idToAssetDataInfo[standardID]
+	}
 	decoder := &AssetDataDecoder{
-		idToAssetDataInfo: idToAssetDataInfo,
+		idToAssetDataInfo:    idToAssetDataInfo,
+		idToCustomAssetProxy: idToCustomAssetProxy,
 	}
 	return decoder
 }
 
-// GetName returns the name of the assetData type
+// GetCustomAssetProxy returns the custom AssetProxy for the given asset
+// data and true, or false if the asset data does not belong to a custom asset
+// proxy.
+func (a *AssetDataDecoder) GetCustomAssetProxy(assetData []byte) (AssetProxy, bool) {
+	if len(assetData) < 4 {
+		return AssetProxy{}, false
+	}
+	proxy, found := a.idToCustomAssetProxy[common.Bytes2Hex(assetData[:4])]
+	return proxy, found
+}
+
+// GetName returns the name of the assetData type. For asset data belonging to a
+// custom asset proxy, this is the name of the token standard it is based on.
 func (a *AssetDataDecoder) GetName(assetData []byte) (string, error) {
 	if len(assetData) < 4 {
 		return "", errors.New("assetData must be at least 4 bytes long")
@@ -0,0 +1,75 @@
+package zeroex
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/0xProject/0x-mesh/constants"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// AssetProxy describes an asset proxy which is not built into the 0x protocol
+// but whose asset data uses the same encoding as one of the standard token
+// asset data types. Orders which use a custom AssetProxy are decoded, tracked
+// and validated as if they used the corresponding standard asset proxy, except
+// that allowances are checked for the custom proxy address. Custom asset
+// proxies are passed to NewAssetDataDecoderWithAssetProxies and to the
+// OrderValidator and Watcher which need to recognize them.
+type AssetProxy struct {
+	// ID is the hex-encoded 4-byte asset proxy ID, without the 0x prefix.
+	ID string `json:"id"`
+	// TokenStandard is the name of the standard asset data type whose encoding
+	// the asset data uses. It must be one of "ERC20Token", "ERC721Token" or
+	// "ERC1155Assets".
+	TokenStandard string `json:"tokenStandard"`
+	// Address is the address of the asset proxy contract which makers must
+	// approve in order for their orders to be fillable.
+	Address common.Address `json:"address"`
+}
+
+// ValidateAssetProxies returns an error if any of the given custom asset
+// proxies is invalid or if two different asset proxies use the same ID.
+// Including the same AssetProxy more than once is allowed.
+func ValidateAssetProxies(proxies []AssetProxy) error {
+	_, err := assetProxiesByID(proxies)
+	return err
+}
+
+// assetProxiesByID validates the given custom asset proxies and returns them
+// keyed by their normalized IDs (lowercase, without the 0x prefix).
+func assetProxiesByID(proxies []AssetProxy) (map[string]AssetProxy, error) {
+	idToProxy := map[string]AssetProxy{}
+	for _, proxy := range proxies {
+		proxy.ID = strings.ToLower(strings.TrimPrefix(proxy.ID, "0x"))
+		if id, err := hex.DecodeString(proxy.ID); err != nil || len(id) != 4 {
+			return nil, fmt.Errorf("invalid asset proxy ID %q: must be 4 hex-encoded bytes", proxy.ID)
+		}
+		switch proxy.TokenStandard {
+		case "ERC20Token", "ERC721Token", "ERC1155Assets":
+		default:
+			return nil, fmt.Errorf("invalid token standard for asset proxy %s: %q", proxy.ID, proxy.TokenStandard)
+		}
+		if proxy.Address == constants.NullAddress {
+			return nil, fmt.Errorf("address is required for asset proxy %s", proxy.ID)
+		}
+		if isBuiltInAssetDataID(proxy.ID) {
+			return nil, fmt.Errorf("invalid asset proxy %s: ID is already used by a built-in asset proxy", proxy.ID)
+		}
+		if existing, found := idToProxy[proxy.ID]; found && existing != proxy {
+			return nil, fmt.Errorf("invalid asset proxy %s: a different asset proxy with the same ID was given", proxy.ID)
+		}
+		idToProxy[proxy.ID] = proxy
+	}
+	return idToProxy, nil
+}
+
+func isBuiltInAssetDataID(id string) bool {
+	switch id {
+	case ERC20AssetDataID, ERC721AssetDataID, ERC1155AssetDataID, StaticCallAssetDataID,
+		CheckGasPriceDefaultID, CheckGasPriceID, MultiAssetDataID, ERC20BridgeAssetDataID:
+		return true
+	default:
+		return false
+	}
+}
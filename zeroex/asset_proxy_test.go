@@ -0,0 +1,76 @@
+package zeroex
+
+import (
+	"testing"
+
+	"github.com/0xProject/0x-mesh/constants"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateAssetProxies(t *testing.T) {
+	proxyAddress := common.HexToAddress("0x8a2c5c4a0d0b4c1d4b7a3e6f3b2d3e2a1c0b9f8e")
+	testCases := []struct {
+		name  string
+		proxy AssetProxy
+	}{
+		{"invalid ID", AssetProxy{ID: "0x1234", TokenStandard: "ERC20Token", Address: proxyAddress}},
+		{"non-hex ID", AssetProxy{ID: "zzzzzzzz", TokenStandard: "ERC20Token", Address: proxyAddress}},
+		{"unsupported token standard", AssetProxy{ID: "0x11111111", TokenStandard: "StaticCall", Address: proxyAddress}},
+		{"null address", AssetProxy{ID: "0x11111111", TokenStandard: "ERC20Token", Address: constants.NullAddress}},
+		{"built-in ID", AssetProxy{ID: "0x" + ERC20AssetDataID, TokenStandard: "ERC20Token", Address: proxyAddress}},
+	}
+	for _, tc := range testCases {
+		assert.Error(t, ValidateAssetProxies([]AssetProxy{tc.proxy}), tc.name)
+	}
+}
+
+func TestValidateAssetProxiesConflict(t *testing.T) {
+	proxy := AssetProxy{
+		ID:            "22222222",
+		TokenStandard: "ERC20Token",
+		Address:       common.HexToAddress("0x8a2c5c4a0d0b4c1d4b7a3e6f3b2d3e2a1c0b9f8e"),
+	}
+	// The same proxy can be given more than once (with or without the 0x prefix).
+	samePrefixedProxy := proxy
+	samePrefixedProxy.ID = "0x22222222"
+	require.NoError(t, ValidateAssetProxies([]AssetProxy{proxy, samePrefixedProxy}))
+	// A different proxy with the same ID is not allowed.
+	differentProxy := samePrefixedProxy
+	differentProxy.TokenStandard = "ERC721Token"
+	assert.Error(t, ValidateAssetProxies([]AssetProxy{proxy, differentProxy}))
+}
+
+func TestDecodeCustomAssetProxyAssetData(t *testing.T) {
+	proxy := AssetProxy{
+		ID:            "33333333",
+		TokenStandard: "ERC721Token",
+		Address:       common.HexToAddress("0x8a2c5c4a0d0b4c1d4b7a3e6f3b2d3e2a1c0b9f8e"),
+	}
+	d, err := NewAssetDataDecoderWithAssetProxies([]AssetProxy{proxy})
+	require.NoError(t, err)
+
+	// Same encoding as ERC721 asset data, but with the custom asset proxy ID.
+	assetData := common.Hex2Bytes("333333330000000000000000000000001dc4c1cefef38a777b15aa20260a54e584b16c480000000000000000000000000000000000000000000000000000000000000001")
+
+	name, err := d.GetName(assetData)
+	require.NoError(t, err)
+	assert.Equal(t, "ERC721Token", name)
+
+	var decodedAssetData ERC721AssetData
+	require.NoError(t, d.Decode(assetData, &decodedAssetData))
+	assert.Equal(t, common.HexToAddress("0x1dC4c1cEFEF38a777b15aA20260a54E584b16C48"), decodedAssetData.Address)
+
+	actualProxy, found := d.GetCustomAssetProxy(assetData)
+	require.True(t, found)
+	assert.Equal(t, proxy, actualProxy)
+
+	// Built-in asset data does not belong to a custom asset proxy.
+	_, found = d.GetCustomAssetProxy(common.Hex2Bytes("025717920000000000000000000000001dc4c1cefef38a777b15aa20260a54e584b16c480000000000000000000000000000000000000000000000000000000000000001"))
+	assert.False(t, found)
+
+	// Other decoders don't recognize the custom asset proxy.
+	_, err = NewAssetDataDecoder().GetName(assetData)
+	assert.Error(t, err)
+}
@@ -129,8 +129,12 @@ type OrderEvent struct {
 	Timestamp time.Time `json:"timestamp"`
 	// OrderHash is the EIP712 hash of the 0x order
 	OrderHash common.Hash `json:"orderHash"`
-	// SignedOrder is the signed 0x order struct
+	// SignedOrder is the signed 0x order struct. It is nil if the event is for
+	// an Exchange V4 order.
 	SignedOrder *SignedOrder `json:"signedOrder"`
+	// SignedOrderV4 is the signed Exchange V4 order struct. It is only set if
+	// the event is for an Exchange V4 order.
+	SignedOrderV4 *SignedOrderV4 `json:"signedOrderV4,omitempty"`
 	// EndState is the end state of this order at the time this event was generated
 	EndState OrderEventEndState `json:"endState"`
 	// FillableTakerAssetAmount is the amount for which this order is still fillable
@@ -145,6 +149,7 @@ type orderEventJSON struct {
 	Timestamp                time.Time            `json:"timestamp"`
 	OrderHash                string               `json:"orderHash"`
 	SignedOrder              *SignedOrder         `json:"signedOrder"`
+	SignedOrderV4            *SignedOrderV4       `json:"signedOrderV4,omitempty"`
 	EndState                 string               `json:"endState"`
 	FillableTakerAssetAmount string               `json:"fillableTakerAssetAmount"`
 	ContractEvents           []*contractEventJSON `json:"contractEvents"`
@@ -152,14 +157,18 @@ type orderEventJSON struct {
 
 // MarshalJSON implements a custom JSON marshaller for the OrderEvent type
 func (o OrderEvent) MarshalJSON() ([]byte, error) {
-	return json.Marshal(map[string]interface{}{
+	m := map[string]interface{}{
 		"timestamp":                o.Timestamp,
 		"orderHash":                o.OrderHash.Hex(),
 		"signedOrder":              o.SignedOrder,
 		"endState":                 o.EndState,
 		"fillableTakerAssetAmount": o.FillableTakerAssetAmount.String(),
 		"contractEvents":           o.ContractEvents,
-	})
+	}
+	if o.SignedOrderV4 != nil {
+		m["signedOrderV4"] = o.SignedOrderV4
+	}
+	return json.Marshal(m)
 }
 
 // UnmarshalJSON implements a custom JSON unmarshaller for the OrderEvent type
@@ -176,6 +185,7 @@ func (o *OrderEvent) fromOrderEventJSON(orderEventJSON orderEventJSON) error {
 	o.Timestamp = orderEventJSON.Timestamp
 	o.OrderHash = common.HexToHash(orderEventJSON.OrderHash)
 	o.SignedOrder = orderEventJSON.SignedOrder
+	o.SignedOrderV4 = orderEventJSON.SignedOrderV4
 	o.EndState = OrderEventEndState(orderEventJSON.EndState)
 	var ok bool
 	o.FillableTakerAssetAmount, ok = math.ParseBig256(orderEventJSON.FillableTakerAssetAmount)
@@ -296,6 +306,48 @@ func unmarshalContractEvent(eventJSON *contractEventJSON) (*ContractEvent, error
 		}
 		event.Parameters = parameters
 
+	case "ExchangeSignatureValidatorApprovalEvent":
+		var parameters decoder.ExchangeSignatureValidatorApprovalEvent
+		if err := json.Unmarshal(eventJSON.Parameters, &parameters); err != nil {
+			return nil, err
+		}
+		event.Parameters = parameters
+
+	case "ExchangeV4LimitOrderFilledEvent":
+		var parameters decoder.ExchangeV4LimitOrderFilledEvent
+		if err := json.Unmarshal(eventJSON.Parameters, &parameters); err != nil {
+			return nil, err
+		}
+		event.Parameters = parameters
+
+	case "ExchangeV4RfqOrderFilledEvent":
+		var parameters decoder.ExchangeV4RfqOrderFilledEvent
+		if err := json.Unmarshal(eventJSON.Parameters, &parameters); err != nil {
+			return nil, err
+		}
+		event.Parameters = parameters
+
+	case "ExchangeV4OrderCancelledEvent":
+		var parameters decoder.ExchangeV4OrderCancelledEvent
+		if err := json.Unmarshal(eventJSON.Parameters, &parameters); err != nil {
+			return nil, err
+		}
+		event.Parameters = parameters
+
+	case "ExchangeV4PairCancelledLimitOrdersEvent":
+		var parameters decoder.ExchangeV4PairCancelledLimitOrdersEvent
+		if err := json.Unmarshal(eventJSON.Parameters, &parameters); err != nil {
+			return nil, err
+		}
+		event.Parameters = parameters
+
+	case "ExchangeV4PairCancelledRfqOrdersEvent":
+		var parameters decoder.ExchangeV4PairCancelledRfqOrdersEvent
+		if err := json.Unmarshal(eventJSON.Parameters, &parameters); err != nil {
+			return nil, err
+		}
+		event.Parameters = parameters
+
 	default:
 		return nil, fmt.Errorf("unknown event kind: %s", eventJSON.Kind)
 	}
@@ -336,6 +388,14 @@ const (
 	// and no further events for this order will be emitted. In some cases, the order may be re-added in the
 	// future.
 	ESStoppedWatching = OrderEventEndState("STOPPED_WATCHING")
+	// ESOrderPendingFill means a transaction which would fill the order was seen in the mempool but has not
+	// been mined yet. The order's fillableTakerAssetAmount is left unchanged until the transaction is mined.
+	// This event is only emitted if mempool watching is enabled.
+	ESOrderPendingFill = OrderEventEndState("PENDING_FILL")
+	// ESOrderPendingCancel means a transaction which would cancel the order was seen in the mempool but has
+	// not been mined yet. The order's fillableTakerAssetAmount is left unchanged until the transaction is
+	// mined. This event is only emitted if mempool watching is enabled.
+	ESOrderPendingCancel = OrderEventEndState("PENDING_CANCEL")
 )
 
 var eip712OrderTypes = gethsigner.Types{
@@ -428,6 +488,17 @@ func (o *Order) ComputeOrderHash() (common.Hash, error) {
 		return *o.hash, nil
 	}
 
+	hash, err := signer.TypedDataHash(o.TypedData())
+	if err != nil {
+		return common.Hash{}, err
+	}
+	o.hash = &hash
+	return hash, nil
+}
+
+// TypedData returns the EIP-712 typed data of the order. The order hash is the
+// EIP-712 hash of the typed data.
+func (o *Order) TypedData() *gethsigner.TypedData {
 	chainID := math.NewHexOrDecimal256(o.ChainID.Int64())
 	var domain = gethsigner.TypedDataDomain{
 		Name:              "0x Protocol",
@@ -453,30 +524,19 @@ func (o *Order) ComputeOrderHash() (common.Hash, error) {
 		"expirationTimeSeconds": o.ExpirationTimeSeconds.String(),
 	}
 
-	var typedData = gethsigner.TypedData{
+	return &gethsigner.TypedData{
 		Types:       eip712OrderTypes,
 		PrimaryType: "Order",
 		Domain:      domain,
 		Message:     message,
 	}
-
-	domainSeparator, err := typedData.HashStruct("EIP712Domain", typedData.Domain.Map())
-	if err != nil {
-		return common.Hash{}, err
-	}
-	typedDataHash, err := typedData.HashStruct(typedData.PrimaryType, typedData.Message)
-	if err != nil {
-		return common.Hash{}, err
-	}
-	rawData := []byte(fmt.Sprintf("\x19\x01%s%s", string(domainSeparator), string(typedDataHash)))
-	hashBytes := keccak256(rawData)
-	hash := common.BytesToHash(hashBytes)
-	o.hash = &hash
-	return hash, nil
 }
 
-// SignOrder signs the 0x order with the supplied Signer
-func SignOrder(signer signer.Signer, order *Order) (*SignedOrder, error) {
+// SignOrder signs the 0x order with the supplied Signer. The signature type
+// must be either EthSignSignature, which signs the order hash with `eth_sign`,
+// or EIP712Signature, which signs the typed data of the order with
+// `eth_signTypedData_v4`.
+func SignOrder(orderSigner signer.Signer, order *Order, signatureType SignatureType) (*SignedOrder, error) {
 	if order == nil {
 		return nil, errors.New("cannot sign nil order")
 	}
@@ -485,17 +545,25 @@ func SignOrder(signer signer.Signer, order *Order) (*SignedOrder, error) {
 		return nil, err
 	}
 
-	ecSignature, err := signer.EthSign(orderHash.Bytes(), order.MakerAddress)
+	var ecSignature *signer.ECSignature
+	switch signatureType {
+	case EthSignSignature:
+		ecSignature, err = orderSigner.EthSign(orderHash.Bytes(), order.MakerAddress)
+	case EIP712Signature:
+		ecSignature, err = orderSigner.SignTypedData(order.TypedData(), order.MakerAddress)
+	default:
+		return nil, fmt.Errorf("unsupported signature type: %d", signatureType)
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	// Generate 0x EthSign Signature (append the signature type byte)
+	// Generate 0x Signature (append the signature type byte)
 	signature := make([]byte, 66)
 	signature[0] = ecSignature.V
 	copy(signature[1:33], ecSignature.R[:])
 	copy(signature[33:65], ecSignature.S[:])
-	signature[65] = byte(EthSignSignature)
+	signature[65] = byte(signatureType)
 	signedOrder := &SignedOrder{
 		Order:     *order,
 		Signature: signature,
@@ -506,7 +574,7 @@ func SignOrder(signer signer.Signer, order *Order) (*SignedOrder, error) {
 // SignTestOrder signs the 0x order with the local test signer
 func SignTestOrder(order *Order) (*SignedOrder, error) {
 	testSigner := signer.NewTestSigner()
-	signedOrder, err := SignOrder(testSigner, order)
+	signedOrder, err := SignOrder(testSigner, order, EthSignSignature)
 	if err != nil {
 		return nil, err
 	}
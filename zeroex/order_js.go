@@ -16,14 +16,20 @@ func (o OrderEvent) JSValue() js.Value {
 	for i, contractEvent := range o.ContractEvents {
 		contractEventsJS[i] = contractEvent.JSValue()
 	}
-	return js.ValueOf(map[string]interface{}{
+	m := map[string]interface{}{
 		"timestamp":                o.Timestamp.Format(time.RFC3339),
 		"orderHash":                o.OrderHash.Hex(),
-		"signedOrder":              o.SignedOrder.JSValue(),
 		"endState":                 string(o.EndState),
 		"fillableTakerAssetAmount": o.FillableTakerAssetAmount.String(),
 		"contractEvents":           contractEventsJS,
-	})
+	}
+	if o.SignedOrder != nil {
+		m["signedOrder"] = o.SignedOrder.JSValue()
+	}
+	if o.SignedOrderV4 != nil {
+		m["signedOrderV4"] = o.SignedOrderV4.JSValue()
+	}
+	return js.ValueOf(m)
 }
 
 func (s SignedOrder) JSValue() js.Value {
@@ -72,6 +78,33 @@ func (s SignedOrder) JSValue() js.Value {
 	})
 }
 
+func (s SignedOrderV4) JSValue() js.Value {
+	return js.ValueOf(map[string]interface{}{
+		"type":                string(s.Type),
+		"chainId":             s.ChainID.Int64(),
+		"verifyingContract":   strings.ToLower(s.VerifyingContract.Hex()),
+		"makerToken":          strings.ToLower(s.MakerToken.Hex()),
+		"takerToken":          strings.ToLower(s.TakerToken.Hex()),
+		"makerAmount":         s.MakerAmount.String(),
+		"takerAmount":         s.TakerAmount.String(),
+		"takerTokenFeeAmount": bigOrZero(s.TakerTokenFeeAmount).String(),
+		"maker":               strings.ToLower(s.Maker.Hex()),
+		"taker":               strings.ToLower(s.Taker.Hex()),
+		"sender":              strings.ToLower(s.Sender.Hex()),
+		"feeRecipient":        strings.ToLower(s.FeeRecipient.Hex()),
+		"txOrigin":            strings.ToLower(s.TxOrigin.Hex()),
+		"pool":                s.Pool.Hex(),
+		"expiry":              s.Expiry.String(),
+		"salt":                s.Salt.String(),
+		"signature": map[string]interface{}{
+			"signatureType": int(s.Signature.SignatureType),
+			"v":             int(s.Signature.V),
+			"r":             s.Signature.R.Hex(),
+			"s":             s.Signature.S.Hex(),
+		},
+	})
+}
+
 func (c ContractEvent) JSValue() js.Value {
 	m := map[string]interface{}{
 		"address":    c.Address.Hex(),
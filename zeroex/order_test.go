@@ -10,8 +10,10 @@ import (
 
 	"github.com/0xProject/0x-mesh/constants"
 	"github.com/0xProject/0x-mesh/ethereum"
+	"github.com/0xProject/0x-mesh/ethereum/signer"
 	"github.com/0xProject/0x-mesh/zeroex/orderwatch/decoder"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -73,6 +75,31 @@ func TestSignOrder(t *testing.T) {
 	assert.Equal(t, expectedSignature, actualSignature)
 }
 
+func TestSignOrderEIP712(t *testing.T) {
+	order := *testOrder
+	order.ResetHash()
+	signedOrder, err := SignOrder(signer.NewTestSigner(), &order, EIP712Signature)
+	require.NoError(t, err)
+	require.Len(t, signedOrder.Signature, 66)
+	assert.Equal(t, byte(EIP712Signature), signedOrder.Signature[65])
+
+	// The signature must be over the order hash without any prefix.
+	orderHash, err := order.ComputeOrderHash()
+	require.NoError(t, err)
+	typedDataHash, err := signer.TypedDataHash(order.TypedData())
+	require.NoError(t, err)
+	assert.Equal(t, orderHash, typedDataHash)
+	rsv := append(append([]byte{}, signedOrder.Signature[1:65]...), signedOrder.Signature[0]-27)
+	publicKey, err := crypto.SigToPub(orderHash.Bytes(), rsv)
+	require.NoError(t, err)
+	assert.Equal(t, order.MakerAddress, crypto.PubkeyToAddress(*publicKey))
+}
+
+func TestSignOrderUnsupportedSignatureType(t *testing.T) {
+	_, err := SignOrder(signer.NewTestSigner(), testOrder, WalletSignature)
+	assert.Error(t, err)
+}
+
 func TestMarshalUnmarshalOrderEvent(t *testing.T) {
 	signedOrder, err := SignTestOrder(testOrder)
 	require.NoError(t, err)
@@ -0,0 +1,363 @@
+package zeroex
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/0xProject/0x-mesh/ethereum/signer"
+	"github.com/0xProject/0x-mesh/ethereum/wrappers"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+)
+
+// OrderTypeV4 is the type of an Exchange V4 order.
+type OrderTypeV4 string
+
+// OrderTypeV4 values
+const (
+	// LimitOrderTypeV4 is a V4 limit order. Limit orders can be filled by anyone
+	// (unless Taker is set) and may include a taker token fee.
+	LimitOrderTypeV4 = OrderTypeV4("limit")
+	// RFQOrderTypeV4 is a V4 RFQ order. RFQ orders can only be filled by
+	// transactions originating from TxOrigin and have no fees.
+	RFQOrderTypeV4 = OrderTypeV4("rfq")
+)
+
+// OrderV4 represents an unsigned Exchange V4 (Exchange Proxy) limit or RFQ
+// order. The TakerTokenFeeAmount, Sender and FeeRecipient fields only apply to
+// limit orders and the TxOrigin field only applies to RFQ orders.
+type OrderV4 struct {
+	Type OrderTypeV4 `json:"type"`
+	// ChainID and VerifyingContract are part of the EIP-712 domain and are not
+	// included in the order struct passed to the Exchange Proxy.
+	ChainID             *big.Int       `json:"chainId"`
+	VerifyingContract   common.Address `json:"verifyingContract"`
+	MakerToken          common.Address `json:"makerToken"`
+	TakerToken          common.Address `json:"takerToken"`
+	MakerAmount         *big.Int       `json:"makerAmount"`
+	TakerAmount         *big.Int       `json:"takerAmount"`
+	TakerTokenFeeAmount *big.Int       `json:"takerTokenFeeAmount"`
+	Maker               common.Address `json:"maker"`
+	Taker               common.Address `json:"taker"`
+	Sender              common.Address `json:"sender"`
+	FeeRecipient        common.Address `json:"feeRecipient"`
+	TxOrigin            common.Address `json:"txOrigin"`
+	Pool                common.Hash    `json:"pool"`
+	Expiry              *big.Int       `json:"expiry"`
+	Salt                *big.Int       `json:"salt"`
+
+	// Cache hash for performance
+	hash *common.Hash
+}
+
+// SignatureTypeV4 represents the type of an Exchange V4 signature
+type SignatureTypeV4 uint8
+
+// SignatureTypeV4 values
+const (
+	IllegalSignatureV4 SignatureTypeV4 = iota
+	InvalidSignatureV4
+	EIP712SignatureV4
+	EthSignSignatureV4
+)
+
+// SignatureFieldV4 is the signature of an Exchange V4 order. Unlike V3
+// signatures, V4 signatures are structs rather than byte arrays.
+type SignatureFieldV4 struct {
+	SignatureType SignatureTypeV4 `json:"signatureType"`
+	V             uint8           `json:"v"`
+	R             common.Hash     `json:"r"`
+	S             common.Hash     `json:"s"`
+}
+
+// SignedOrderV4 represents a signed Exchange V4 order
+type SignedOrderV4 struct {
+	OrderV4
+	Signature SignatureFieldV4 `json:"signature"`
+}
+
+// OrderStatusV4 represents the status of a V4 order as returned by the
+// Exchange Proxy. Note that the values differ from the V3 OrderStatus.
+type OrderStatusV4 uint8
+
+// OrderStatusV4 values
+const (
+	OS4Invalid OrderStatusV4 = iota
+	OS4Fillable
+	OS4Filled
+	OS4Cancelled
+	OS4Expired
+)
+
+var (
+	eip712DomainTypeHashV4 = keccak256([]byte("EIP712Domain(string name,string version,uint256 chainId,address verifyingContract)"))
+	eip712DomainNameHashV4 = keccak256([]byte("ZeroEx"))
+	eip712DomainVersionV4  = keccak256([]byte("1.0.0"))
+	limitOrderTypeHashV4   = keccak256([]byte("LimitOrder(address makerToken,address takerToken,uint128 makerAmount,uint128 takerAmount,uint128 takerTokenFeeAmount,address maker,address taker,address sender,address feeRecipient,bytes32 pool,uint64 expiry,uint256 salt)"))
+	rfqOrderTypeHashV4     = keccak256([]byte("RfqOrder(address makerToken,address takerToken,uint128 makerAmount,uint128 takerAmount,address maker,address taker,address txOrigin,bytes32 pool,uint64 expiry,uint256 salt)"))
+)
+
+// ResetHash resets the cached order hash. Usually only required for testing.
+func (o *OrderV4) ResetHash() {
+	o.hash = nil
+}
+
+// ComputeOrderHash computes the EIP-712 hash of a V4 order. All of the fields of
+// V4 orders have a static size, so the struct hash is simply the keccak256 hash
+// of the type hash followed by each field encoded as a 32 byte word.
+func (o *OrderV4) ComputeOrderHash() (common.Hash, error) {
+	if o.hash != nil {
+		return *o.hash, nil
+	}
+	if o.ChainID == nil {
+		return common.Hash{}, errors.New("cannot compute order hash: chainId is required")
+	}
+
+	var structHash []byte
+	switch o.Type {
+	case LimitOrderTypeV4:
+		structHash = keccak256(
+			limitOrderTypeHashV4,
+			common.LeftPadBytes(o.MakerToken.Bytes(), 32),
+			common.LeftPadBytes(o.TakerToken.Bytes(), 32),
+			math.U256Bytes(bigOrZero(o.MakerAmount)),
+			math.U256Bytes(bigOrZero(o.TakerAmount)),
+			math.U256Bytes(bigOrZero(o.TakerTokenFeeAmount)),
+			common.LeftPadBytes(o.Maker.Bytes(), 32),
+			common.LeftPadBytes(o.Taker.Bytes(), 32),
+			common.LeftPadBytes(o.Sender.Bytes(), 32),
+			common.LeftPadBytes(o.FeeRecipient.Bytes(), 32),
+			o.Pool.Bytes(),
+			math.U256Bytes(bigOrZero(o.Expiry)),
+			math.U256Bytes(bigOrZero(o.Salt)),
+		)
+	case RFQOrderTypeV4:
+		structHash = keccak256(
+			rfqOrderTypeHashV4,
+			common.LeftPadBytes(o.MakerToken.Bytes(), 32),
+			common.LeftPadBytes(o.TakerToken.Bytes(), 32),
+			math.U256Bytes(bigOrZero(o.MakerAmount)),
+			math.U256Bytes(bigOrZero(o.TakerAmount)),
+			common.LeftPadBytes(o.Maker.Bytes(), 32),
+			common.LeftPadBytes(o.Taker.Bytes(), 32),
+			common.LeftPadBytes(o.TxOrigin.Bytes(), 32),
+			o.Pool.Bytes(),
+			math.U256Bytes(bigOrZero(o.Expiry)),
+			math.U256Bytes(bigOrZero(o.Salt)),
+		)
+	default:
+		return common.Hash{}, fmt.Errorf("cannot compute order hash: unknown order type %q", o.Type)
+	}
+
+	domainSeparator := keccak256(
+		eip712DomainTypeHashV4,
+		eip712DomainNameHashV4,
+		eip712DomainVersionV4,
+		math.U256Bytes(new(big.Int).Set(o.ChainID)),
+		common.LeftPadBytes(o.VerifyingContract.Bytes(), 32),
+	)
+	hash := common.BytesToHash(keccak256([]byte("\x19\x01"), domainSeparator, structHash))
+	o.hash = &hash
+	return hash, nil
+}
+
+// bigOrZero returns a copy of v, or zero if v is nil. math.U256Bytes modifies
+// its argument, so we always pass it a copy.
+func bigOrZero(v *big.Int) *big.Int {
+	if v == nil {
+		return big.NewInt(0)
+	}
+	return new(big.Int).Set(v)
+}
+
+// SignOrderV4 signs the V4 order with the supplied Signer using an EthSign
+// signature.
+func SignOrderV4(signer signer.Signer, order *OrderV4) (*SignedOrderV4, error) {
+	if order == nil {
+		return nil, errors.New("cannot sign nil order")
+	}
+	orderHash, err := order.ComputeOrderHash()
+	if err != nil {
+		return nil, err
+	}
+
+	ecSignature, err := signer.EthSign(orderHash.Bytes(), order.Maker)
+	if err != nil {
+		return nil, err
+	}
+
+	signedOrder := &SignedOrderV4{
+		OrderV4: *order,
+		Signature: SignatureFieldV4{
+			SignatureType: EthSignSignatureV4,
+			V:             ecSignature.V,
+			R:             ecSignature.R,
+			S:             ecSignature.S,
+		},
+	}
+	return signedOrder, nil
+}
+
+// SignTestOrderV4 signs the V4 order with the local test signer
+func SignTestOrderV4(order *OrderV4) (*SignedOrderV4, error) {
+	testSigner := signer.NewTestSigner()
+	signedOrder, err := SignOrderV4(testSigner, order)
+	if err != nil {
+		return nil, err
+	}
+	return signedOrder, nil
+}
+
+// LimitOrder converts the order to the limit order struct expected by the
+// Exchange Proxy.
+func (s *SignedOrderV4) LimitOrder() wrappers.LimitOrderV4 {
+	return wrappers.LimitOrderV4{
+		MakerToken:          s.MakerToken,
+		TakerToken:          s.TakerToken,
+		MakerAmount:         bigOrZero(s.MakerAmount),
+		TakerAmount:         bigOrZero(s.TakerAmount),
+		TakerTokenFeeAmount: bigOrZero(s.TakerTokenFeeAmount),
+		Maker:               s.Maker,
+		Taker:               s.Taker,
+		Sender:              s.Sender,
+		FeeRecipient:        s.FeeRecipient,
+		Pool:                [32]byte(s.Pool),
+		Expiry:              bigOrZero(s.Expiry).Uint64(),
+		Salt:                bigOrZero(s.Salt),
+	}
+}
+
+// RfqOrder converts the order to the RFQ order struct expected by the Exchange
+// Proxy.
+func (s *SignedOrderV4) RfqOrder() wrappers.RfqOrderV4 {
+	return wrappers.RfqOrderV4{
+		MakerToken:  s.MakerToken,
+		TakerToken:  s.TakerToken,
+		MakerAmount: bigOrZero(s.MakerAmount),
+		TakerAmount: bigOrZero(s.TakerAmount),
+		Maker:       s.Maker,
+		Taker:       s.Taker,
+		TxOrigin:    s.TxOrigin,
+		Pool:        [32]byte(s.Pool),
+		Expiry:      bigOrZero(s.Expiry).Uint64(),
+		Salt:        bigOrZero(s.Salt),
+	}
+}
+
+// EthereumSignature converts the signature to the struct expected by the
+// Exchange Proxy.
+func (s *SignedOrderV4) EthereumSignature() wrappers.SignatureV4 {
+	return wrappers.SignatureV4{
+		SignatureType: uint8(s.Signature.SignatureType),
+		V:             s.Signature.V,
+		R:             [32]byte(s.Signature.R),
+		S:             [32]byte(s.Signature.S),
+	}
+}
+
+// SignedOrderV4JSON is an unmodified JSON representation of a SignedOrderV4
+type SignedOrderV4JSON struct {
+	Type                OrderTypeV4          `json:"type"`
+	ChainID             int64                `json:"chainId"`
+	VerifyingContract   string               `json:"verifyingContract"`
+	MakerToken          string               `json:"makerToken"`
+	TakerToken          string               `json:"takerToken"`
+	MakerAmount         string               `json:"makerAmount"`
+	TakerAmount         string               `json:"takerAmount"`
+	TakerTokenFeeAmount string               `json:"takerTokenFeeAmount"`
+	Maker               string               `json:"maker"`
+	Taker               string               `json:"taker"`
+	Sender              string               `json:"sender"`
+	FeeRecipient        string               `json:"feeRecipient"`
+	TxOrigin            string               `json:"txOrigin"`
+	Pool                string               `json:"pool"`
+	Expiry              string               `json:"expiry"`
+	Salt                string               `json:"salt"`
+	Signature           SignatureFieldV4JSON `json:"signature"`
+}
+
+// SignatureFieldV4JSON is an unmodified JSON representation of a
+// SignatureFieldV4
+type SignatureFieldV4JSON struct {
+	SignatureType uint8  `json:"signatureType"`
+	V             uint8  `json:"v"`
+	R             string `json:"r"`
+	S             string `json:"s"`
+}
+
+// MarshalJSON implements a custom JSON marshaller for the SignedOrderV4 type
+func (s SignedOrderV4) MarshalJSON() ([]byte, error) {
+	return json.Marshal(SignedOrderV4JSON{
+		Type:                s.Type,
+		ChainID:             bigOrZero(s.ChainID).Int64(),
+		VerifyingContract:   strings.ToLower(s.VerifyingContract.Hex()),
+		MakerToken:          strings.ToLower(s.MakerToken.Hex()),
+		TakerToken:          strings.ToLower(s.TakerToken.Hex()),
+		MakerAmount:         bigOrZero(s.MakerAmount).String(),
+		TakerAmount:         bigOrZero(s.TakerAmount).String(),
+		TakerTokenFeeAmount: bigOrZero(s.TakerTokenFeeAmount).String(),
+		Maker:               strings.ToLower(s.Maker.Hex()),
+		Taker:               strings.ToLower(s.Taker.Hex()),
+		Sender:              strings.ToLower(s.Sender.Hex()),
+		FeeRecipient:        strings.ToLower(s.FeeRecipient.Hex()),
+		TxOrigin:            strings.ToLower(s.TxOrigin.Hex()),
+		Pool:                s.Pool.Hex(),
+		Expiry:              bigOrZero(s.Expiry).String(),
+		Salt:                bigOrZero(s.Salt).String(),
+		Signature: SignatureFieldV4JSON{
+			SignatureType: uint8(s.Signature.SignatureType),
+			V:             s.Signature.V,
+			R:             s.Signature.R.Hex(),
+			S:             s.Signature.S.Hex(),
+		},
+	})
+}
+
+// UnmarshalJSON implements a custom JSON unmarshaller for the SignedOrderV4 type
+func (s *SignedOrderV4) UnmarshalJSON(data []byte) error {
+	var signedOrderJSON SignedOrderV4JSON
+	err := json.Unmarshal(data, &signedOrderJSON)
+	if err != nil {
+		return err
+	}
+	s.Type = signedOrderJSON.Type
+	s.ChainID = big.NewInt(signedOrderJSON.ChainID)
+	s.VerifyingContract = common.HexToAddress(signedOrderJSON.VerifyingContract)
+	s.MakerToken = common.HexToAddress(signedOrderJSON.MakerToken)
+	s.TakerToken = common.HexToAddress(signedOrderJSON.TakerToken)
+	s.MakerAmount = parseOptionalBig256(signedOrderJSON.MakerAmount)
+	s.TakerAmount = parseOptionalBig256(signedOrderJSON.TakerAmount)
+	s.TakerTokenFeeAmount = parseOptionalBig256(signedOrderJSON.TakerTokenFeeAmount)
+	s.Maker = common.HexToAddress(signedOrderJSON.Maker)
+	s.Taker = common.HexToAddress(signedOrderJSON.Taker)
+	s.Sender = common.HexToAddress(signedOrderJSON.Sender)
+	s.FeeRecipient = common.HexToAddress(signedOrderJSON.FeeRecipient)
+	s.TxOrigin = common.HexToAddress(signedOrderJSON.TxOrigin)
+	s.Pool = common.HexToHash(signedOrderJSON.Pool)
+	s.Expiry = parseOptionalBig256(signedOrderJSON.Expiry)
+	s.Salt = parseOptionalBig256(signedOrderJSON.Salt)
+	s.Signature = SignatureFieldV4{
+		SignatureType: SignatureTypeV4(signedOrderJSON.Signature.SignatureType),
+		V:             signedOrderJSON.Signature.V,
+		R:             common.HexToHash(signedOrderJSON.Signature.R),
+		S:             common.HexToHash(signedOrderJSON.Signature.S),
+	}
+	s.hash = nil
+	return nil
+}
+
+// parseOptionalBig256 parses a decimal or hex encoded uint256. It returns nil
+// if the string is empty or invalid.
+func parseOptionalBig256(s string) *big.Int {
+	if s == "" {
+		return nil
+	}
+	v, ok := math.ParseBig256(s)
+	if !ok {
+		return nil
+	}
+	return v
+}
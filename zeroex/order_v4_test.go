@@ -0,0 +1,159 @@
+package zeroex
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/0xProject/0x-mesh/constants"
+	"github.com/0xProject/0x-mesh/zeroex/orderwatch/decoder"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testExchangeProxyAddress = common.HexToAddress("0xdef1c0ded9bec7f1a1670819833240f027b25eff")
+
+var testOrderV4 = &OrderV4{
+	Type:                LimitOrderTypeV4,
+	ChainID:             big.NewInt(constants.TestChainID),
+	VerifyingContract:   testExchangeProxyAddress,
+	MakerToken:          contractAddresses.ZRXToken,
+	TakerToken:          contractAddresses.WETH9,
+	MakerAmount:         big.NewInt(203),
+	TakerAmount:         big.NewInt(204),
+	TakerTokenFeeAmount: big.NewInt(1),
+	Maker:               constants.GanacheAccount0,
+	Taker:               constants.NullAddress,
+	Sender:              constants.NullAddress,
+	FeeRecipient:        constants.NullAddress,
+	TxOrigin:            constants.NullAddress,
+	Pool:                common.HexToHash("0x01"),
+	Expiry:              big.NewInt(205),
+	Salt:                big.NewInt(200),
+}
+
+func newTestHashOrderV4(orderType OrderTypeV4) *OrderV4 {
+	return &OrderV4{
+		Type:                orderType,
+		ChainID:             big.NewInt(constants.TestChainID),
+		VerifyingContract:   testExchangeProxyAddress,
+		MakerToken:          constants.NullAddress,
+		TakerToken:          constants.NullAddress,
+		MakerAmount:         big.NewInt(0),
+		TakerAmount:         big.NewInt(0),
+		TakerTokenFeeAmount: big.NewInt(0),
+		Maker:               constants.NullAddress,
+		Taker:               constants.NullAddress,
+		Sender:              constants.NullAddress,
+		FeeRecipient:        constants.NullAddress,
+		TxOrigin:            constants.NullAddress,
+		Expiry:              big.NewInt(0),
+		Salt:                big.NewInt(0),
+	}
+}
+
+func TestGenerateOrderHashV4(t *testing.T) {
+	testCases := []struct {
+		orderType         OrderTypeV4
+		expectedOrderHash common.Hash
+	}{
+		{
+			orderType:         LimitOrderTypeV4,
+			expectedOrderHash: common.HexToHash("0x5becd49577b73ab3946c6ab20341a084922d6ae6b85826aa1b2d2cd4bcaf9f37"),
+		},
+		{
+			orderType:         RFQOrderTypeV4,
+			expectedOrderHash: common.HexToHash("0x91c6fda2979f7db3c62c900b40720cc1e9047c7983dd961984fe36af7f76d77f"),
+		},
+	}
+	for _, tc := range testCases {
+		actualOrderHash, err := newTestHashOrderV4(tc.orderType).ComputeOrderHash()
+		require.NoError(t, err)
+		assert.Equal(t, tc.expectedOrderHash, actualOrderHash, string(tc.orderType))
+	}
+}
+
+func TestGenerateOrderHashV4InvalidType(t *testing.T) {
+	_, err := newTestHashOrderV4(OrderTypeV4("otc")).ComputeOrderHash()
+	assert.Error(t, err)
+}
+
+func TestSignOrderV4(t *testing.T) {
+	signedOrder, err := SignTestOrderV4(testOrderV4)
+	require.NoError(t, err)
+	assert.Equal(t, EthSignSignatureV4, signedOrder.Signature.SignatureType)
+	assert.NotEqual(t, common.Hash{}, signedOrder.Signature.R)
+	assert.NotEqual(t, common.Hash{}, signedOrder.Signature.S)
+}
+
+func TestMarshalUnmarshalSignedOrderV4(t *testing.T) {
+	signedOrder, err := SignTestOrderV4(testOrderV4)
+	require.NoError(t, err)
+	expectedOrderHash, err := signedOrder.ComputeOrderHash()
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, json.NewEncoder(buf).Encode(signedOrder))
+	var decoded SignedOrderV4
+	require.NoError(t, json.NewDecoder(buf).Decode(&decoded))
+
+	// We need to call ResetHash so that unexported hash field is equal in later
+	// assertions.
+	signedOrder.ResetHash()
+	assert.Equal(t, signedOrder, &decoded)
+	actualOrderHash, err := decoded.ComputeOrderHash()
+	require.NoError(t, err)
+	assert.Equal(t, expectedOrderHash, actualOrderHash)
+}
+
+func TestMarshalUnmarshalOrderEventV4(t *testing.T) {
+	signedOrder, err := SignTestOrderV4(testOrderV4)
+	require.NoError(t, err)
+	orderHash, err := signedOrder.ComputeOrderHash()
+	require.NoError(t, err)
+	orderEvent := OrderEvent{
+		Timestamp:                time.Now().UTC(),
+		OrderHash:                orderHash,
+		SignedOrderV4:            signedOrder,
+		EndState:                 ESOrderFilled,
+		FillableTakerAssetAmount: big.NewInt(104),
+		ContractEvents: []*ContractEvent{
+			{
+				BlockHash: common.HexToHash("0x3fcd58a6613265e2b0deba902d7ff693f330a0af6e5b04805b44bbffd8a415d4"),
+				TxHash:    common.HexToHash("0x3fcd58a6613265e2b0deba902d7ff693f330a0af6e5b04805b44bbffd8a415d5"),
+				TxIndex:   42,
+				LogIndex:  1337,
+				IsRemoved: false,
+				Address:   testExchangeProxyAddress,
+				Kind:      "ExchangeV4LimitOrderFilledEvent",
+				Parameters: decoder.ExchangeV4LimitOrderFilledEvent{
+					OrderHash:                 orderHash,
+					Maker:                     constants.GanacheAccount0,
+					Taker:                     constants.GanacheAccount1,
+					FeeRecipient:              constants.NullAddress,
+					MakerToken:                contractAddresses.ZRXToken,
+					TakerToken:                contractAddresses.WETH9,
+					TakerTokenFilledAmount:    big.NewInt(100),
+					MakerTokenFilledAmount:    big.NewInt(99),
+					TakerTokenFeeFilledAmount: big.NewInt(1),
+					ProtocolFeePaid:           big.NewInt(150000),
+					Pool:                      common.HexToHash("0x01"),
+				},
+			},
+		},
+	}
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, json.NewEncoder(buf).Encode(orderEvent))
+	var decoded OrderEvent
+
+	// We need to call ResetHash so that unexported hash field is equal in later
+	// assertions.
+	signedOrder.ResetHash()
+
+	require.NoError(t, json.NewDecoder(buf).Decode(&decoded))
+	assert.Equal(t, orderEvent, decoded)
+}
@@ -0,0 +1,275 @@
+package ordervalidator
+
+import (
+	"context"
+	"math/big"
+	"strings"
+
+	"github.com/0xProject/0x-mesh/ethereum"
+	"github.com/0xProject/0x-mesh/ethereum/wrappers"
+	"github.com/0xProject/0x-mesh/zeroex"
+	goethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// BalanceFetcher fetches balances and asset proxy allowances for any supported
+// asset data using individual eth_calls to the corresponding token contracts.
+//
+// ERC20Bridge asset data is not supported and always results in an amount of
+// zero.
+type BalanceFetcher struct {
+	contractCaller    bind.ContractCaller
+	erc20ABI          abi.ABI
+	erc721ABI         abi.ABI
+	erc1155ABI        abi.ABI
+	assetDataDecoder  *zeroex.AssetDataDecoder
+	contractAddresses ethereum.ContractAddresses
+}
+
+// NewBalanceFetcher creates a new BalanceFetcher. Allowances for asset data
+// belonging to one of the given custom asset proxies are checked for the
+// custom asset proxy's address.
+func NewBalanceFetcher(contractCaller bind.ContractCaller, contractAddresses ethereum.ContractAddresses, assetProxies []zeroex.AssetProxy) (*BalanceFetcher, error) {
+	assetDataDecoder, err := zeroex.NewAssetDataDecoderWithAssetProxies(assetProxies)
+	if err != nil {
+		return nil, err
+	}
+	// ZRXToken, DummyERC721Token and ERC1155Mintable all implement the standard
+	// interfaces for their respective token types, so we can use their ABIs to
+	// call any compliant token contract.
+	erc20ABI, err := abi.JSON(strings.NewReader(wrappers.ZRXTokenABI))
+	if err != nil {
+		return nil, err
+	}
+	erc721ABI, err := abi.JSON(strings.NewReader(wrappers.DummyERC721TokenABI))
+	if err != nil {
+		return nil, err
+	}
+	erc1155ABI, err := abi.JSON(strings.NewReader(wrappers.ERC1155MintableABI))
+	if err != nil {
+		return nil, err
+	}
+	return &BalanceFetcher{
+		contractCaller:    contractCaller,
+		erc20ABI:          erc20ABI,
+		erc721ABI:         erc721ABI,
+		erc1155ABI:        erc1155ABI,
+		assetDataDecoder:  assetDataDecoder,
+		contractAddresses: contractAddresses,
+	}, nil
+}
+
+// GetTransferableAssetAmount returns the amount of the given asset that can be
+// transferred from owner by the asset proxies, i.e. the minimum of the owner's
+// balance and the allowance they have given to the corresponding asset proxy.
+func (f *BalanceFetcher) GetTransferableAssetAmount(opts *bind.CallOpts, owner common.Address, assetData []byte) (*big.Int, error) {
+	balance, err := f.getBalance(opts, owner, assetData)
+	if err != nil {
+		return nil, err
+	}
+	allowance, err := f.getAssetProxyAllowance(opts, owner, assetData)
+	if err != nil {
+		return nil, err
+	}
+	return minBigInt(balance, allowance), nil
+}
+
+func (f *BalanceFetcher) getBalance(opts *bind.CallOpts, owner common.Address, assetData []byte) (*big.Int, error) {
+	assetDataName, err := f.assetDataDecoder.GetName(assetData)
+	if err != nil {
+		return big.NewInt(0), nil
+	}
+	switch assetDataName {
+	case "ERC20Token":
+		var decodedAssetData zeroex.ERC20AssetData
+		if err := f.assetDataDecoder.Decode(assetData, &decodedAssetData); err != nil {
+			return big.NewInt(0), nil
+		}
+		balance := new(*big.Int)
+		if err := f.call(opts, f.erc20ABI, decodedAssetData.Address, balance, "balanceOf", owner); err != nil {
+			return nil, err
+		}
+		return *balance, nil
+	case "ERC721Token":
+		var decodedAssetData zeroex.ERC721AssetData
+		if err := f.assetDataDecoder.Decode(assetData, &decodedAssetData); err != nil {
+			return big.NewInt(0), nil
+		}
+		tokenOwner := new(common.Address)
+		if err := f.call(opts, f.erc721ABI, decodedAssetData.Address, tokenOwner, "ownerOf", decodedAssetData.TokenId); err != nil {
+			// ownerOf reverts for tokens which don't exist.
+			if isRevertError(err) {
+				return big.NewInt(0), nil
+			}
+			return nil, err
+		}
+		if *tokenOwner == owner {
+			return big.NewInt(1), nil
+		}
+		return big.NewInt(0), nil
+	case "ERC1155Assets":
+		var decodedAssetData zeroex.ERC1155AssetData
+		if err := f.assetDataDecoder.Decode(assetData, &decodedAssetData); err != nil {
+			return big.NewInt(0), nil
+		}
+		var minScaledBalance *big.Int
+		for i, id := range decodedAssetData.Ids {
+			if i >= len(decodedAssetData.Values) || decodedAssetData.Values[i].Sign() == 0 {
+				return big.NewInt(0), nil
+			}
+			balance := new(*big.Int)
+			if err := f.call(opts, f.erc1155ABI, decodedAssetData.Address, balance, "balanceOf", owner, id); err != nil {
+				return nil, err
+			}
+			scaledBalance := new(big.Int).Div(*balance, decodedAssetData.Values[i])
+			if minScaledBalance == nil || scaledBalance.Cmp(minScaledBalance) < 0 {
+				minScaledBalance = scaledBalance
+			}
+		}
+		if minScaledBalance == nil {
+			return big.NewInt(0), nil
+		}
+		return minScaledBalance, nil
+	case "StaticCall":
+		var decodedAssetData zeroex.StaticCallAssetData
+		if err := f.assetDataDecoder.Decode(assetData, &decodedAssetData); err != nil {
+			return big.NewInt(0), nil
+		}
+		ctx := opts.Context
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		result, err := f.contractCaller.CallContract(ctx, goethereum.CallMsg{
+			From: opts.From,
+			To:   &decodedAssetData.StaticCallTargetAddress,
+			Data: decodedAssetData.StaticCallData,
+		}, opts.BlockNumber)
+		if err != nil {
+			if isRevertError(err) {
+				return big.NewInt(0), nil
+			}
+			return nil, err
+		}
+		if crypto.Keccak256Hash(result) != common.Hash(decodedAssetData.ExpectedReturnHashData) {
+			return big.NewInt(0), nil
+		}
+		return new(big.Int).Set(math.MaxBig256), nil
+	case "MultiAsset":
+		var decodedAssetData zeroex.MultiAssetData
+		if err := f.assetDataDecoder.Decode(assetData, &decodedAssetData); err != nil {
+			return big.NewInt(0), nil
+		}
+		return f.getMultiAssetAmount(opts, owner, decodedAssetData, f.getBalance)
+	default:
+		return big.NewInt(0), nil
+	}
+}
+
+// getAssetProxyAddress returns the address of the asset proxy which transfers
+// the given asset data. This is the custom asset proxy if the asset
+// data uses one, and builtInProxyAddress otherwise.
+func (f *BalanceFetcher) getAssetProxyAddress(assetData []byte, builtInProxyAddress common.Address) common.Address {
+	if proxy, found := f.assetDataDecoder.GetCustomAssetProxy(assetData); found {
+		return proxy.Address
+	}
+	return builtInProxyAddress
+}
+
+func (f *BalanceFetcher) getAssetProxyAllowance(opts *bind.CallOpts, owner common.Address, assetData []byte) (*big.Int, error) {
+	assetDataName, err := f.assetDataDecoder.GetName(assetData)
+	if err != nil {
+		return big.NewInt(0), nil
+	}
+	switch assetDataName {
+	case "ERC20Token":
+		var decodedAssetData zeroex.ERC20AssetData
+		if err := f.assetDataDecoder.Decode(assetData, &decodedAssetData); err != nil {
+			return big.NewInt(0), nil
+		}
+		allowance := new(*big.Int)
+		if err := f.call(opts, f.erc20ABI, decodedAssetData.Address, allowance, "allowance", owner, f.getAssetProxyAddress(assetData, f.contractAddresses.ERC20Proxy)); err != nil {
+			return nil, err
+		}
+		return *allowance, nil
+	case "ERC721Token":
+		var decodedAssetData zeroex.ERC721AssetData
+		if err := f.assetDataDecoder.Decode(assetData, &decodedAssetData); err != nil {
+			return big.NewInt(0), nil
+		}
+		isApprovedForAll := new(bool)
+		if err := f.call(opts, f.erc721ABI, decodedAssetData.Address, isApprovedForAll, "isApprovedForAll", owner, f.getAssetProxyAddress(assetData, f.contractAddresses.ERC721Proxy)); err != nil {
+			return nil, err
+		}
+		if *isApprovedForAll {
+			return new(big.Int).Set(math.MaxBig256), nil
+		}
+		approved := new(common.Address)
+		if err := f.call(opts, f.erc721ABI, decodedAssetData.Address, approved, "getApproved", decodedAssetData.TokenId); err != nil {
+			// getApproved reverts for tokens which don't exist.
+			if isRevertError(err) {
+				return big.NewInt(0), nil
+			}
+			return nil, err
+		}
+		if *approved == f.getAssetProxyAddress(assetData, f.contractAddresses.ERC721Proxy) {
+			return big.NewInt(1), nil
+		}
+		return big.NewInt(0), nil
+	case "ERC1155Assets":
+		var decodedAssetData zeroex.ERC1155AssetData
+		if err := f.assetDataDecoder.Decode(assetData, &decodedAssetData); err != nil {
+			return big.NewInt(0), nil
+		}
+		isApprovedForAll := new(bool)
+		if err := f.call(opts, f.erc1155ABI, decodedAssetData.Address, isApprovedForAll, "isApprovedForAll", owner, f.getAssetProxyAddress(assetData, f.contractAddresses.ERC1155Proxy)); err != nil {
+			return nil, err
+		}
+		if *isApprovedForAll {
+			return new(big.Int).Set(math.MaxBig256), nil
+		}
+		return big.NewInt(0), nil
+	case "StaticCall":
+		// The StaticCallProxy never transfers anything, so no allowance is needed.
+		return new(big.Int).Set(math.MaxBig256), nil
+	case "MultiAsset":
+		var decodedAssetData zeroex.MultiAssetData
+		if err := f.assetDataDecoder.Decode(assetData, &decodedAssetData); err != nil {
+			return big.NewInt(0), nil
+		}
+		return f.getMultiAssetAmount(opts, owner, decodedAssetData, f.getAssetProxyAllowance)
+	default:
+		return big.NewInt(0), nil
+	}
+}
+
+// getMultiAssetAmount returns the number of whole units of the given
+// MultiAssetData that are covered by getAmount for each of the nested assets.
+func (f *BalanceFetcher) getMultiAssetAmount(opts *bind.CallOpts, owner common.Address, multiAssetData zeroex.MultiAssetData, getAmount func(*bind.CallOpts, common.Address, []byte) (*big.Int, error)) (*big.Int, error) {
+	var minScaledAmount *big.Int
+	for i, nestedAssetData := range multiAssetData.NestedAssetData {
+		if i >= len(multiAssetData.Amounts) || multiAssetData.Amounts[i].Sign() == 0 {
+			return big.NewInt(0), nil
+		}
+		amount, err := getAmount(opts, owner, nestedAssetData)
+		if err != nil {
+			return nil, err
+		}
+		scaledAmount := new(big.Int).Div(amount, multiAssetData.Amounts[i])
+		if minScaledAmount == nil || scaledAmount.Cmp(minScaledAmount) < 0 {
+			minScaledAmount = scaledAmount
+		}
+	}
+	if minScaledAmount == nil {
+		return big.NewInt(0), nil
+	}
+	return minScaledAmount, nil
+}
+
+func (f *BalanceFetcher) call(opts *bind.CallOpts, contractABI abi.ABI, address common.Address, result interface{}, method string, params ...interface{}) error {
+	contract := bind.NewBoundContract(address, contractABI, f.contractCaller, nil, nil)
+	return contract.Call(opts, result, method, params...)
+}
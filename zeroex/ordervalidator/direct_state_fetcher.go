@@ -0,0 +1,220 @@
+package ordervalidator
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"strings"
+
+	"github.com/0xProject/0x-mesh/ethereum"
+	"github.com/0xProject/0x-mesh/ethereum/ethrpcclient"
+	"github.com/0xProject/0x-mesh/ethereum/wrappers"
+	"github.com/0xProject/0x-mesh/zeroex"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// DirectStateFetcher is a StateFetcher which computes the same results as
+// DevUtils.getOrderRelevantStates using individual eth_calls to the Exchange
+// contract (filled, cancelled and orderEpoch) and to the ERC20, ERC721 and
+// ERC1155 token contracts (balances and asset proxy allowances, via
+// BalanceFetcher). Signatures are checked with a SignatureVerifier. It
+// requires many more requests than the DevUtils backend but does not depend on
+// DevUtils being deployed.
+type DirectStateFetcher struct {
+	client            ethrpcclient.Client
+	exchange          *wrappers.ExchangeCaller
+	balanceFetcher    *BalanceFetcher
+	signatureCache    *SignatureCache
+	signatureVerifier *SignatureVerifier
+}
+
+// NewDirectStateFetcher creates a new DirectStateFetcher which also supports
+// orders using the given custom asset proxies.
+func NewDirectStateFetcher(client ethrpcclient.Client, contractAddresses ethereum.ContractAddresses, assetProxies []zeroex.AssetProxy) (*DirectStateFetcher, error) {
+	exchange, err := wrappers.NewExchangeCaller(contractAddresses.Exchange, client)
+	if err != nil {
+		return nil, err
+	}
+	balanceFetcher, err := NewBalanceFetcher(client, contractAddresses, assetProxies)
+	if err != nil {
+		return nil, err
+	}
+	signatureCache := NewSignatureCache(signatureCacheMaxAge, signatureCacheMaxSize)
+	signatureVerifier, err := NewSignatureVerifier(client, contractAddresses, signatureCache)
+	if err != nil {
+		return nil, err
+	}
+	return &DirectStateFetcher{
+		client:            client,
+		exchange:          exchange,
+		balanceFetcher:    balanceFetcher,
+		signatureCache:    signatureCache,
+		signatureVerifier: signatureVerifier,
+	}, nil
+}
+
+// SignatureCache returns the cache used to store the results of contract
+// signature checks.
+func (f *DirectStateFetcher) SignatureCache() *SignatureCache {
+	return f.signatureCache
+}
+
+// GetOrderRelevantStates implements StateFetcher.
+func (f *DirectStateFetcher) GetOrderRelevantStates(opts *bind.CallOpts, signedOrders []*zeroex.SignedOrder) (*OrderRelevantStates, error) {
+	ctx := opts.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	header, err := f.client.HeaderByNumber(ctx, opts.BlockNumber)
+	if err != nil {
+		return nil, err
+	}
+	blockTimestamp := big.NewInt(header.Timestamp.Unix())
+
+	// Many orders in a batch tend to share the same maker and assets, so we only
+	// look up the transferable amount for each (owner, assetData) pair once.
+	transferableAmounts := map[string]*big.Int{}
+	getTransferableAssetAmount := func(owner common.Address, assetData []byte) (*big.Int, error) {
+		key := owner.Hex() + common.Bytes2Hex(assetData)
+		if amount, found := transferableAmounts[key]; found {
+			return amount, nil
+		}
+		amount, err := f.balanceFetcher.GetTransferableAssetAmount(opts, owner, assetData)
+		if err != nil {
+			return nil, err
+		}
+		transferableAmounts[key] = amount
+		return amount, nil
+	}
+
+	isValidSignatures, err := f.signatureVerifier.VerifySignatures(opts, signedOrders)
+	if err != nil {
+		return nil, err
+	}
+	states := &OrderRelevantStates{
+		OrdersInfo:                make([]wrappers.OrderInfo, len(signedOrders)),
+		FillableTakerAssetAmounts: make([]*big.Int, len(signedOrders)),
+		IsValidSignature:          isValidSignatures,
+	}
+	for i, signedOrder := range signedOrders {
+		orderInfo, err := f.getOrderInfo(opts, signedOrder, blockTimestamp)
+		if err != nil {
+			return nil, err
+		}
+		fillableTakerAssetAmount := big.NewInt(0)
+		if zeroex.OrderStatus(orderInfo.OrderStatus) == zeroex.OSFillable {
+			transferableMakerAssetAmount, err := getTransferableAssetAmount(signedOrder.MakerAddress, signedOrder.MakerAssetData)
+			if err != nil {
+				return nil, err
+			}
+			transferableMakerFeeAssetAmount := big.NewInt(0)
+			if signedOrder.MakerFee.Sign() > 0 && len(signedOrder.MakerFeeAssetData) > 0 && !bytes.Equal(signedOrder.MakerAssetData, signedOrder.MakerFeeAssetData) {
+				transferableMakerFeeAssetAmount, err = getTransferableAssetAmount(signedOrder.MakerAddress, signedOrder.MakerFeeAssetData)
+				if err != nil {
+					return nil, err
+				}
+			}
+			fillableTakerAssetAmount = ComputeFillableTakerAssetAmount(&signedOrder.Order, orderInfo.OrderTakerAssetFilledAmount, transferableMakerAssetAmount, transferableMakerFeeAssetAmount)
+		}
+		states.OrdersInfo[i] = orderInfo
+		states.FillableTakerAssetAmounts[i] = fillableTakerAssetAmount
+	}
+	return states, nil
+}
+
+// getOrderInfo mirrors Exchange.getOrderInfo. We cannot call getOrderInfo
+// directly because the generated binding for it has the wrong return type.
+func (f *DirectStateFetcher) getOrderInfo(opts *bind.CallOpts, signedOrder *zeroex.SignedOrder, blockTimestamp *big.Int) (wrappers.OrderInfo, error) {
+	orderHash, err := signedOrder.ComputeOrderHash()
+	if err != nil {
+		return wrappers.OrderInfo{}, err
+	}
+	filledAmount, err := f.exchange.Filled(opts, orderHash)
+	if err != nil {
+		return wrappers.OrderInfo{}, err
+	}
+	isCancelled, err := f.exchange.Cancelled(opts, orderHash)
+	if err != nil {
+		return wrappers.OrderInfo{}, err
+	}
+	orderEpoch, err := f.exchange.OrderEpoch(opts, signedOrder.MakerAddress, signedOrder.SenderAddress)
+	if err != nil {
+		return wrappers.OrderInfo{}, err
+	}
+	return wrappers.OrderInfo{
+		OrderStatus:                 uint8(computeOrderStatus(&signedOrder.Order, filledAmount, isCancelled, orderEpoch, blockTimestamp)),
+		OrderHash:                   orderHash,
+		OrderTakerAssetFilledAmount: filledAmount,
+	}, nil
+}
+
+// computeOrderStatus computes the status of an order the same way as
+// Exchange.getOrderInfo. It does not check the signature.
+func computeOrderStatus(order *zeroex.Order, filledAmount *big.Int, isCancelled bool, orderEpoch *big.Int, blockTimestamp *big.Int) zeroex.OrderStatus {
+	switch {
+	case order.MakerAssetAmount.Sign() == 0:
+		return zeroex.OSInvalidMakerAssetAmount
+	case order.TakerAssetAmount.Sign() == 0:
+		return zeroex.OSInvalidTakerAssetAmount
+	case filledAmount.Cmp(order.TakerAssetAmount) >= 0:
+		return zeroex.OSFullyFilled
+	case blockTimestamp.Cmp(order.ExpirationTimeSeconds) >= 0:
+		return zeroex.OSExpired
+	case isCancelled, orderEpoch.Cmp(order.Salt) > 0:
+		return zeroex.OSCancelled
+	default:
+		return zeroex.OSFillable
+	}
+}
+
+// ComputeFillableTakerAssetAmount computes the amount of the taker asset which
+// can currently be filled the same way as DevUtils.getOrderRelevantState. The
+// transferable maker fee asset amount is ignored if the maker fee is zero or if
+// the maker fee asset is the same as the maker asset.
+func ComputeFillableTakerAssetAmount(order *zeroex.Order, filledAmount *big.Int, transferableMakerAssetAmount *big.Int, transferableMakerFeeAssetAmount *big.Int) *big.Int {
+	var transferableTakerAssetAmount *big.Int
+	if bytes.Equal(order.MakerAssetData, order.MakerFeeAssetData) {
+		// The maker fee is paid out of the same balance as the maker asset.
+		transferableTakerAssetAmount = getPartialAmountFloor(
+			transferableMakerAssetAmount,
+			new(big.Int).Add(order.MakerAssetAmount, order.MakerFee),
+			order.TakerAssetAmount,
+		)
+	} else if order.MakerFee.Sign() == 0 {
+		transferableTakerAssetAmount = getPartialAmountFloor(transferableMakerAssetAmount, order.MakerAssetAmount, order.TakerAssetAmount)
+	} else {
+		transferableTakerAssetAmount = minBigInt(
+			getPartialAmountFloor(transferableMakerAssetAmount, order.MakerAssetAmount, order.TakerAssetAmount),
+			getPartialAmountFloor(transferableMakerFeeAssetAmount, order.MakerFee, order.TakerAssetAmount),
+		)
+	}
+	remainingTakerAssetAmount := new(big.Int).Sub(order.TakerAssetAmount, filledAmount)
+	if remainingTakerAssetAmount.Sign() < 0 {
+		remainingTakerAssetAmount = big.NewInt(0)
+	}
+	return minBigInt(remainingTakerAssetAmount, transferableTakerAssetAmount)
+}
+
+// getPartialAmountFloor returns numerator * target / denominator, rounded down.
+func getPartialAmountFloor(numerator, denominator, target *big.Int) *big.Int {
+	if denominator.Sign() == 0 {
+		return big.NewInt(0)
+	}
+	partialAmount := new(big.Int).Mul(numerator, target)
+	return partialAmount.Div(partialAmount, denominator)
+}
+
+func minBigInt(a, b *big.Int) *big.Int {
+	if a.Cmp(b) <= 0 {
+		return a
+	}
+	return b
+}
+
+// isRevertError returns true if err was caused by the called contract
+// reverting, as opposed to e.g. a network error. Ganache returns "VM execution
+// error." while Geth and Parity include "revert" in the error message.
+func isRevertError(err error) bool {
+	return err.Error() == "VM execution error." || strings.Contains(err.Error(), "revert")
+}
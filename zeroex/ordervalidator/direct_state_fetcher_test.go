@@ -0,0 +1,116 @@
+// +build !js
+
+package ordervalidator
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/0xProject/0x-mesh/constants"
+	"github.com/0xProject/0x-mesh/scenario"
+	"github.com/0xProject/0x-mesh/scenario/orderopts"
+	"github.com/0xProject/0x-mesh/zeroex"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComputeOrderStatus(t *testing.T) {
+	newOrder := func(makerAssetAmount, takerAssetAmount int64) *zeroex.Order {
+		return &zeroex.Order{
+			MakerAssetAmount:      big.NewInt(makerAssetAmount),
+			TakerAssetAmount:      big.NewInt(takerAssetAmount),
+			ExpirationTimeSeconds: big.NewInt(1000),
+			Salt:                  big.NewInt(10),
+		}
+	}
+	testCases := []struct {
+		order          *zeroex.Order
+		filledAmount   int64
+		isCancelled    bool
+		orderEpoch     int64
+		blockTimestamp int64
+		expectedStatus zeroex.OrderStatus
+	}{
+		{newOrder(0, 100), 0, false, 0, 500, zeroex.OSInvalidMakerAssetAmount},
+		{newOrder(100, 0), 0, false, 0, 500, zeroex.OSInvalidTakerAssetAmount},
+		{newOrder(100, 100), 100, false, 0, 500, zeroex.OSFullyFilled},
+		{newOrder(100, 100), 0, false, 0, 1000, zeroex.OSExpired},
+		{newOrder(100, 100), 0, true, 0, 500, zeroex.OSCancelled},
+		{newOrder(100, 100), 0, false, 11, 500, zeroex.OSCancelled},
+		{newOrder(100, 100), 0, false, 10, 500, zeroex.OSFillable},
+		{newOrder(100, 100), 50, false, 0, 500, zeroex.OSFillable},
+	}
+	for i, tc := range testCases {
+		actualStatus := computeOrderStatus(tc.order, big.NewInt(tc.filledAmount), tc.isCancelled, big.NewInt(tc.orderEpoch), big.NewInt(tc.blockTimestamp))
+		assert.Equal(t, tc.expectedStatus, actualStatus, "test case %d", i)
+	}
+}
+
+func TestComputeFillableTakerAssetAmount(t *testing.T) {
+	makerAssetData := common.Hex2Bytes("f47261b0000000000000000000000000871dd7c2b4b25e1aa18728e9d5f2af4c4e431f5c")
+	feeAssetData := common.Hex2Bytes("f47261b00000000000000000000000000b1ba0af832d7c05fd64161e0db78e85978e8082")
+	newOrder := func(makerFee int64, makerFeeAssetData []byte) *zeroex.Order {
+		return &zeroex.Order{
+			MakerAssetData:    makerAssetData,
+			MakerAssetAmount:  big.NewInt(100),
+			MakerFee:          big.NewInt(makerFee),
+			MakerFeeAssetData: makerFeeAssetData,
+			TakerAssetAmount:  big.NewInt(50),
+		}
+	}
+	testCases := []struct {
+		name                            string
+		order                           *zeroex.Order
+		filledAmount                    int64
+		transferableMakerAssetAmount    int64
+		transferableMakerFeeAssetAmount int64
+		expectedFillableAmount          int64
+	}{
+		{"fully funded", newOrder(0, nil), 0, 100, 0, 50},
+		{"partially funded", newOrder(0, nil), 0, 40, 0, 20},
+		{"partially filled", newOrder(0, nil), 30, 100, 0, 20},
+		{"fee in maker asset", newOrder(100, makerAssetData), 0, 100, 0, 25},
+		{"fee in other asset", newOrder(20, feeAssetData), 0, 100, 10, 25},
+		{"fee in other asset fully funded", newOrder(20, feeAssetData), 0, 100, 20, 50},
+	}
+	for _, tc := range testCases {
+		actualFillableAmount := ComputeFillableTakerAssetAmount(tc.order, big.NewInt(tc.filledAmount), big.NewInt(tc.transferableMakerAssetAmount), big.NewInt(tc.transferableMakerFeeAssetAmount))
+		assert.Equal(t, big.NewInt(tc.expectedFillableAmount), actualFillableAmount, tc.name)
+	}
+}
+
+func TestDirectStateFetcherMatchesDevUtils(t *testing.T) {
+	if !serialTestsEnabled {
+		t.Skip("Serial tests (tests which cannot run in parallel) are disabled. You can enable them with the --serial flag")
+	}
+
+	teardownSubTest := setupSubTest(t)
+	defer teardownSubTest(t)
+
+	signedOrders := []*zeroex.SignedOrder{
+		scenario.NewSignedTestOrder(t, orderopts.SetupMakerState(true)),
+		scenario.NewSignedTestOrder(t),
+	}
+
+	devUtilsStateFetcher, err := NewDevUtilsStateFetcher(ethRPCClient, ganacheAddresses)
+	require.NoError(t, err)
+	directStateFetcher, err := NewDirectStateFetcher(ethRPCClient, ganacheAddresses, nil)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	latestBlock, err := ethRPCClient.HeaderByNumber(ctx, nil)
+	require.NoError(t, err)
+	opts := &bind.CallOpts{
+		From:        constants.GanacheDummyERC721TokenAddress,
+		Context:     ctx,
+		BlockNumber: latestBlock.Number,
+	}
+	expectedStates, err := devUtilsStateFetcher.GetOrderRelevantStates(opts, signedOrders)
+	require.NoError(t, err)
+	actualStates, err := directStateFetcher.GetOrderRelevantStates(opts, signedOrders)
+	require.NoError(t, err)
+	assert.Equal(t, expectedStates, actualStates)
+}
@@ -31,6 +31,11 @@ import (
 // Additional requests will block until an ongoing request has completed.
 const concurrencyLimit = 5
 
+// maxERC1155CallbackDataLength is the maximum number of bytes of callback data
+// allowed in ERC1155 asset data. The callback data is forwarded to the receiver
+// on every transfer, so large amounts of it make orders expensive to fill.
+const maxERC1155CallbackDataLength = 1024
+
 // RejectedOrderInfo encapsulates all the needed information to understand _why_ a 0x order
 // was rejected (i.e. did not pass) order validation. Since there are many potential reasons, some
 // Mesh-specific, others 0x-specific and others due to external factors (i.e., network
@@ -39,33 +44,44 @@ const concurrencyLimit = 5
 type RejectedOrderInfo struct {
 	OrderHash   common.Hash         `json:"orderHash"`
 	SignedOrder *zeroex.SignedOrder `json:"signedOrder"`
-	Kind        RejectedOrderKind   `json:"kind"`
-	Status      RejectedOrderStatus `json:"status"`
+	// SignedOrderV4 is set instead of SignedOrder if the rejected order is an
+	// Exchange V4 order.
+	SignedOrderV4 *zeroex.SignedOrderV4 `json:"signedOrderV4,omitempty"`
+	Kind          RejectedOrderKind     `json:"kind"`
+	Status        RejectedOrderStatus   `json:"status"`
 }
 
 // AcceptedOrderInfo represents an fillable order and how much it could be filled for
 type AcceptedOrderInfo struct {
-	OrderHash                common.Hash         `json:"orderHash"`
-	SignedOrder              *zeroex.SignedOrder `json:"signedOrder"`
-	FillableTakerAssetAmount *big.Int            `json:"fillableTakerAssetAmount"`
-	IsNew                    bool                `json:"isNew"`
+	OrderHash   common.Hash         `json:"orderHash"`
+	SignedOrder *zeroex.SignedOrder `json:"signedOrder"`
+	// SignedOrderV4 is set instead of SignedOrder if the accepted order is an
+	// Exchange V4 order.
+	SignedOrderV4            *zeroex.SignedOrderV4 `json:"signedOrderV4,omitempty"`
+	FillableTakerAssetAmount *big.Int              `json:"fillableTakerAssetAmount"`
+	IsNew                    bool                  `json:"isNew"`
 }
 
 type acceptedOrderInfoJSON struct {
-	OrderHash                string              `json:"orderHash"`
-	SignedOrder              *zeroex.SignedOrder `json:"signedOrder"`
-	FillableTakerAssetAmount string              `json:"fillableTakerAssetAmount"`
-	IsNew                    bool                `json:"isNew"`
+	OrderHash                string                `json:"orderHash"`
+	SignedOrder              *zeroex.SignedOrder   `json:"signedOrder"`
+	SignedOrderV4            *zeroex.SignedOrderV4 `json:"signedOrderV4,omitempty"`
+	FillableTakerAssetAmount string                `json:"fillableTakerAssetAmount"`
+	IsNew                    bool                  `json:"isNew"`
 }
 
 // MarshalJSON is a custom Marshaler for AcceptedOrderInfo
 func (a AcceptedOrderInfo) MarshalJSON() ([]byte, error) {
-	return json.Marshal(map[string]interface{}{
+	m := map[string]interface{}{
 		"orderHash":                a.OrderHash.Hex(),
 		"signedOrder":              a.SignedOrder,
 		"fillableTakerAssetAmount": a.FillableTakerAssetAmount.String(),
 		"isNew":                    a.IsNew,
-	})
+	}
+	if a.SignedOrderV4 != nil {
+		m["signedOrderV4"] = a.SignedOrderV4
+	}
+	return json.Marshal(m)
 }
 
 // UnmarshalJSON implements a custom JSON unmarshaller for the OrderEvent type
@@ -78,6 +94,7 @@ func (a *AcceptedOrderInfo) UnmarshalJSON(data []byte) error {
 
 	a.OrderHash = common.HexToHash(acceptedOrderInfoJSON.OrderHash)
 	a.SignedOrder = acceptedOrderInfoJSON.SignedOrder
+	a.SignedOrderV4 = acceptedOrderInfoJSON.SignedOrderV4
 	a.IsNew = acceptedOrderInfoJSON.IsNew
 	var ok bool
 	a.FillableTakerAssetAmount, ok = math.ParseBig256(acceptedOrderInfoJSON.FillableTakerAssetAmount)
@@ -187,6 +204,18 @@ var (
 		Code:    "DatabaseFullOfOrders",
 		Message: "database is full of pinned orders and no orders can be deleted to make space (consider increasing MAX_ORDERS_IN_STORAGE)",
 	}
+	ROMakerOrderQuotaExceeded = RejectedOrderStatus{
+		Code:    "MakerOrderQuotaExceeded",
+		Message: "the maker of this order already has the maximum number of unpinned orders in storage",
+	}
+	ROPeerOrderQuotaExceeded = RejectedOrderStatus{
+		Code:    "PeerOrderQuotaExceeded",
+		Message: "the peer which sent this order already sent the maximum number of unpinned orders in storage",
+	}
+	ROOrderWouldBeEvicted = RejectedOrderStatus{
+		Code:    "OrderWouldBeEvicted",
+		Message: "database is full and the order scores lower than the orders which were last removed by the eviction policy",
+	}
 )
 
 // ROInvalidSchemaCode is the RejectedOrderStatus emitted if an order doesn't conform to the order schema
@@ -234,21 +263,34 @@ type ValidationResults struct {
 type OrderValidator struct {
 	maxRequestContentLength      int
 	devUtilsABI                  abi.ABI
-	devUtils                     *wrappers.DevUtilsCaller
+	stateFetcher                 StateFetcher
+	exchangeV4                   *wrappers.ExchangeV4Caller
 	coordinatorRegistry          *wrappers.CoordinatorRegistryCaller
 	assetDataDecoder             *zeroex.AssetDataDecoder
 	chainID                      int
 	cachedFeeRecipientToEndpoint map[common.Address]string
 	contractAddresses            ethereum.ContractAddresses
+	signatureCache               *SignatureCache
 }
 
-// New instantiates a new order validator
+// New instantiates a new order validator which uses the DevUtils contract to
+// fetch on-chain order state.
 func New(contractCaller bind.ContractCaller, chainID int, maxRequestContentLength int, contractAddresses ethereum.ContractAddresses) (*OrderValidator, error) {
-	devUtilsABI, err := abi.JSON(strings.NewReader(wrappers.DevUtilsABI))
+	stateFetcher, err := NewDevUtilsStateFetcher(contractCaller, contractAddresses)
 	if err != nil {
 		return nil, err
 	}
-	devUtils, err := wrappers.NewDevUtilsCaller(contractAddresses.DevUtils, contractCaller)
+	return NewWithStateFetcher(contractCaller, chainID, maxRequestContentLength, contractAddresses, stateFetcher, nil)
+}
+
+// NewWithStateFetcher instantiates a new order validator which uses the given
+// StateFetcher to fetch on-chain order state. Asset data belonging to one of
+// the given custom asset proxies is supported as well, so the StateFetcher
+// must support them too.
+func NewWithStateFetcher(contractCaller bind.ContractCaller, chainID int, maxRequestContentLength int, contractAddresses ethereum.ContractAddresses, stateFetcher StateFetcher, assetProxies []zeroex.AssetProxy) (*OrderValidator, error) {
+	// The DevUtils ABI is still used to estimate request sizes when splitting
+	// orders into chunks, even if DevUtils itself is not used.
+	devUtilsABI, err := abi.JSON(strings.NewReader(wrappers.DevUtilsABI))
 	if err != nil {
 		return nil, err
 	}
@@ -256,20 +298,65 @@ func New(contractCaller bind.ContractCaller, chainID int, maxRequestContentLengt
 	if err != nil {
 		return nil, err
 	}
-	assetDataDecoder := zeroex.NewAssetDataDecoder()
+	assetDataDecoder, err := zeroex.NewAssetDataDecoderWithAssetProxies(assetProxies)
+	if err != nil {
+		return nil, err
+	}
+	// The Exchange Proxy is optional. If it is not set, all V4 orders are
+	// rejected.
+	var exchangeV4 *wrappers.ExchangeV4Caller
+	if contractAddresses.ExchangeProxy != constants.NullAddress {
+		exchangeV4, err = wrappers.NewExchangeV4Caller(contractAddresses.ExchangeProxy, contractCaller)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Share the signature cache with the state fetcher if it has one, so that
+	// invalidating a signer affects both.
+	signatureCache := NewSignatureCache(signatureCacheMaxAge, signatureCacheMaxSize)
+	if cachingStateFetcher, ok := stateFetcher.(signatureCachingStateFetcher); ok {
+		signatureCache = cachingStateFetcher.SignatureCache()
+	}
 
 	return &OrderValidator{
 		maxRequestContentLength:      maxRequestContentLength,
 		devUtilsABI:                  devUtilsABI,
-		devUtils:                     devUtils,
+		stateFetcher:                 stateFetcher,
+		exchangeV4:                   exchangeV4,
 		coordinatorRegistry:          coordinatorRegistry,
 		assetDataDecoder:             assetDataDecoder,
 		chainID:                      chainID,
 		cachedFeeRecipientToEndpoint: map[common.Address]string{},
 		contractAddresses:            contractAddresses,
+		signatureCache:               signatureCache,
 	}, nil
 }
 
+// HasCachedValidSignature returns true if the signature of the given order is
+// a contract signature (Validator, Wallet or EIP1271Wallet) which was found to
+// be valid and has not been invalidated since.
+func (o *OrderValidator) HasCachedValidSignature(signedOrder *zeroex.SignedOrder, orderHash common.Hash) bool {
+	if len(signedOrder.Signature) == 0 || !isContractSignatureType(zeroex.SignatureType(signedOrder.Signature[len(signedOrder.Signature)-1])) {
+		return false
+	}
+	isValid, found := o.signatureCache.Get(signedOrder.MakerAddress, orderHash, signedOrder.Signature)
+	return found && isValid
+}
+
+// InvalidateSignatures removes the cached results of all contract signature
+// checks for the given signer. It should be called when an event which could
+// change the validity of the signer's signatures is seen.
+func (o *OrderValidator) InvalidateSignatures(signerAddress common.Address) {
+	o.signatureCache.InvalidateSigner(signerAddress)
+}
+
+// ClearSignatureCache removes the cached results of all contract signature
+// checks.
+func (o *OrderValidator) ClearSignatureCache() {
+	o.signatureCache.Clear()
+}
+
 // BatchValidate retrieves all the information needed to validate the supplied orders.
 // It splits the orders into chunks of `chunkSize`, and makes no more then `concurrencyLimit`
 // requests concurrently. If a request fails, re-attempt it up to four times before giving up.
@@ -311,10 +398,6 @@ func (o *OrderValidator) BatchValidate(ctx context.Context, rawSignedOrders []*z
 			for _, signedOrder := range signedOrders {
 				trimmedOrders = append(trimmedOrders, signedOrder.Trim())
 			}
-			signatures := [][]byte{}
-			for _, signedOrder := range signedOrders {
-				signatures = append(signatures, signedOrder.Signature)
-			}
 
 			defer wg.Done()
 
@@ -341,7 +424,7 @@ func (o *OrderValidator) BatchValidate(ctx context.Context, rawSignedOrders []*z
 				}
 				opts.BlockNumber = blockNumber
 
-				results, err := o.devUtils.GetOrderRelevantStates(opts, trimmedOrders, signatures)
+				results, err := o.stateFetcher.GetOrderRelevantStates(opts, signedOrders)
 				if err != nil {
 					log.WithFields(log.Fields{
 						"error":     err.Error(),
@@ -394,6 +477,7 @@ func (o *OrderValidator) BatchValidate(ctx context.Context, rawSignedOrders []*z
 					orderHash := common.Hash(orderInfo.OrderHash)
 					signedOrder := signedOrders[j]
 					orderStatus := zeroex.OrderStatus(orderInfo.OrderStatus)
+					o.cacheSignatureResult(signedOrder, orderHash, isValidSignature)
 					if !isValidSignature {
 						orderStatus = zeroex.OSSignatureInvalid
 					}
@@ -717,7 +801,7 @@ func (o *OrderValidator) BatchOffchainValidation(signedOrders []*zeroex.SignedOr
 			}
 		}
 
-		isSupportedSignature := isSupportedSignature(signedOrder.Signature, orderHash)
+		isSupportedSignature := isSupportedSignature(signedOrder.Signature, orderHash, signedOrder.MakerAddress)
 		if !isSupportedSignature {
 			rejectedOrderInfos = append(rejectedOrderInfos, &RejectedOrderInfo{
 				OrderHash:   orderHash,
@@ -758,6 +842,13 @@ func (o *OrderValidator) isSupportedAssetData(assetData []byte) bool {
 		if err != nil {
 			return false
 		}
+		// Every ID in a batch must have a corresponding value.
+		if len(decodedAssetData.Ids) == 0 || len(decodedAssetData.Ids) != len(decodedAssetData.Values) {
+			return false
+		}
+		if len(decodedAssetData.CallbackData) > maxERC1155CallbackDataLength {
+			return false
+		}
 	case "StaticCall":
 		var decodedAssetData zeroex.StaticCallAssetData
 		err := o.assetDataDecoder.Decode(assetData, &decodedAssetData)
@@ -887,25 +978,25 @@ func (o *OrderValidator) computeOptimalChunkSizes(signedOrders []*zeroex.SignedO
 	return chunkSizes
 }
 
-func isSupportedSignature(signature []byte, orderHash common.Hash) bool {
+// cacheSignatureResult caches the result of checking a contract signature so
+// that it does not have to be checked again until it is invalidated.
+// Signatures of other types are ignored.
+func (o *OrderValidator) cacheSignatureResult(signedOrder *zeroex.SignedOrder, orderHash common.Hash, isValidSignature bool) {
+	if len(signedOrder.Signature) == 0 || !isContractSignatureType(zeroex.SignatureType(signedOrder.Signature[len(signedOrder.Signature)-1])) {
+		return
+	}
+	o.signatureCache.Set(signedOrder.MakerAddress, orderHash, signedOrder.Signature, isValidSignature)
+}
+
+func isSupportedSignature(signature []byte, orderHash common.Hash, makerAddress common.Address) bool {
 	signatureType := zeroex.SignatureType(signature[len(signature)-1])
 
 	switch signatureType {
 	case zeroex.InvalidSignature, zeroex.IllegalSignature:
 		return false
 
-	case zeroex.EIP712Signature:
-		if len(signature) != 66 {
-			return false
-		}
-		// TODO(fabio): Do further validation by splitting into r,s,v and do ECRecover
-
-	case zeroex.EthSignSignature:
-		if len(signature) != 66 {
-			return false
-		}
-		// TODO(fabio): Do further validation by splitting into r,s,v, add prefix to hash
-		// and do ECRecover
+	case zeroex.EIP712Signature, zeroex.EthSignSignature:
+		return isValidECSignature(signature, orderHash, makerAddress)
 
 	case zeroex.ValidatorSignature:
 		if len(signature) < 21 {
@@ -20,21 +20,33 @@ func (v ValidationResults) JSValue() js.Value {
 }
 
 func (a AcceptedOrderInfo) JSValue() js.Value {
-	return js.ValueOf(map[string]interface{}{
+	m := map[string]interface{}{
 		"orderHash":                a.OrderHash.Hex(),
-		"signedOrder":              a.SignedOrder.JSValue(),
 		"fillableTakerAssetAmount": a.FillableTakerAssetAmount.String(),
 		"isNew":                    a.IsNew,
-	})
+	}
+	if a.SignedOrder != nil {
+		m["signedOrder"] = a.SignedOrder.JSValue()
+	}
+	if a.SignedOrderV4 != nil {
+		m["signedOrderV4"] = a.SignedOrderV4.JSValue()
+	}
+	return js.ValueOf(m)
 }
 
 func (r RejectedOrderInfo) JSValue() js.Value {
-	return js.ValueOf(map[string]interface{}{
-		"orderHash":   r.OrderHash.String(),
-		"signedOrder": r.SignedOrder.JSValue(),
-		"kind":        string(r.Kind),
-		"status":      r.Status.JSValue(),
-	})
+	m := map[string]interface{}{
+		"orderHash": r.OrderHash.String(),
+		"kind":      string(r.Kind),
+		"status":    r.Status.JSValue(),
+	}
+	if r.SignedOrder != nil {
+		m["signedOrder"] = r.SignedOrder.JSValue()
+	}
+	if r.SignedOrderV4 != nil {
+		m["signedOrderV4"] = r.SignedOrderV4.JSValue()
+	}
+	return js.ValueOf(m)
 }
 
 func (s RejectedOrderStatus) JSValue() js.Value {
@@ -215,6 +215,43 @@ func TestBatchOffchainValidateUnsupportedStaticCall(t *testing.T) {
 	require.Equal(t, ROInvalidMakerFeeAssetData, rejected[0].Status)
 }
 
+func TestIsSupportedERC1155AssetData(t *testing.T) {
+	if !serialTestsEnabled {
+		t.Skip("Serial tests (tests which cannot run in parallel) are disabled. You can enable them with the --serial flag")
+	}
+
+	devUtils, err := wrappers.NewDevUtils(ganacheAddresses.DevUtils, ethRPCClient)
+	require.NoError(t, err)
+	orderValidator, err := New(ethRPCClient, constants.TestChainID, constants.TestMaxContentLength, ganacheAddresses)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name        string
+		tokenIDs    []*big.Int
+		amounts     []*big.Int
+		callbackLen int
+		expected    bool
+	}{
+		{"single ID", []*big.Int{big.NewInt(1)}, []*big.Int{big.NewInt(1)}, 0, true},
+		{"batch", []*big.Int{big.NewInt(1), big.NewInt(2)}, []*big.Int{big.NewInt(1), big.NewInt(5)}, 0, true},
+		{"max callback data", []*big.Int{big.NewInt(1)}, []*big.Int{big.NewInt(1)}, maxERC1155CallbackDataLength, true},
+		{"callback data too long", []*big.Int{big.NewInt(1)}, []*big.Int{big.NewInt(1)}, maxERC1155CallbackDataLength + 1, false},
+		{"mismatched values", []*big.Int{big.NewInt(1), big.NewInt(2)}, []*big.Int{big.NewInt(1)}, 0, false},
+		{"no IDs", []*big.Int{}, []*big.Int{}, 0, false},
+	}
+	for _, tc := range testCases {
+		assetData, err := devUtils.EncodeERC1155AssetData(
+			&bind.CallOpts{From: constants.GanacheAccount1},
+			constants.GanacheDummyERC1155MintableAddress,
+			tc.tokenIDs,
+			tc.amounts,
+			make([]byte, tc.callbackLen),
+		)
+		require.NoError(t, err, tc.name)
+		assert.Equal(t, tc.expected, orderValidator.isSupportedAssetData(assetData), tc.name)
+	}
+}
+
 var checkGasPriceDefaultStaticCallData = common.Hex2Bytes("c339d10a0000000000000000000000002c530e4ecc573f11bd72cf5fdf580d134d25f15f0000000000000000000000000000000000000000000000000000000000000060c5d2460186f7233c927e7db2dcc703c0e500b653ca82273b7bfad8045d85a4700000000000000000000000000000000000000000000000000000000000000004d728f5b700000000000000000000000000000000000000000000000000000000")
 
 var checkGasPriceStaticCallData = common.Hex2Bytes("c339d10a0000000000000000000000002c530e4ecc573f11bd72cf5fdf580d134d25f15f0000000000000000000000000000000000000000000000000000000000000060c5d2460186f7233c927e7db2dcc703c0e500b653ca82273b7bfad8045d85a4700000000000000000000000000000000000000000000000000000000000000024da5b166a000000000000000000000000000000000000000000000000000000000000000100000000000000000000000000000000000000000000000000000000")
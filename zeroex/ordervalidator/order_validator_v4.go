@@ -0,0 +1,324 @@
+package ordervalidator
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/0xProject/0x-mesh/constants"
+	"github.com/0xProject/0x-mesh/ethereum/wrappers"
+	"github.com/0xProject/0x-mesh/zeroex"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/jpillora/backoff"
+	log "github.com/sirupsen/logrus"
+)
+
+// RejectedOrderStatus values which only apply to Exchange V4 orders
+var (
+	ROExchangeV4Unsupported = RejectedOrderStatus{
+		Code:    "ExchangeV4Unsupported",
+		Message: "this Mesh node is not configured with an Exchange Proxy address and cannot validate V4 orders",
+	}
+	ROInvalidOrderTypeV4 = RejectedOrderStatus{
+		Code:    "OrderHasInvalidType",
+		Message: "order type must be either \"limit\" or \"rfq\"",
+	}
+	ROInvalidMakerTokenV4 = RejectedOrderStatus{
+		Code:    "OrderHasInvalidMakerToken",
+		Message: "order makerToken cannot be the null address",
+	}
+	ROInvalidTakerTokenV4 = RejectedOrderStatus{
+		Code:    "OrderHasInvalidTakerToken",
+		Message: "order takerToken cannot be the null address",
+	}
+	ROInvalidTxOriginV4 = RejectedOrderStatus{
+		Code:    "OrderHasInvalidTxOrigin",
+		Message: "rfq order txOrigin cannot be the null address",
+	}
+	ROInvalidExpiryV4 = RejectedOrderStatus{
+		Code:    "OrderHasInvalidExpiry",
+		Message: "order expiry is required",
+	}
+	ROAmountOutOfRangeV4 = RejectedOrderStatus{
+		Code:    "OrderHasAmountOutOfRange",
+		Message: "order makerAmount, takerAmount and takerTokenFeeAmount must fit in a uint128",
+	}
+)
+
+// maxUint128 is the largest value of the uint128 amount fields of V4 orders.
+// Encoding an order with a larger amount causes the whole eth_call to fail, so
+// such orders must be rejected before they are sent to the Exchange Proxy.
+var maxUint128 = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 128), big.NewInt(1))
+
+func isUint128(v *big.Int) bool {
+	return v.Sign() >= 0 && v.Cmp(maxUint128) <= 0
+}
+
+// The ABI encoded byte length of a single V4 order and its signature. All
+// fields of V4 orders and signatures are static, so unlike V3 orders, every
+// V4 order of a given type has the same encoded length. The lengths are
+// doubled since the call data is hex encoded in the JSON-RPC payload.
+const (
+	encodedLimitOrderV4ByteLength = 2 * 32 * (12 + 4)
+	encodedRfqOrderV4ByteLength   = 2 * 32 * (10 + 4)
+)
+
+// BatchValidateV4 retrieves all the information needed to validate the supplied
+// Exchange V4 orders. It behaves like BatchValidate, but fetches the on-chain
+// order state from the Exchange Proxy's `batchGetLimitOrderRelevantStates` and
+// `batchGetRfqOrderRelevantStates` functions.
+func (o *OrderValidator) BatchValidateV4(ctx context.Context, signedOrders []*zeroex.SignedOrderV4, areNewOrders bool, blockNumber *big.Int) *ValidationResults {
+	if len(signedOrders) == 0 {
+		return &ValidationResults{}
+	}
+	offchainValidSignedOrders, rejectedOrderInfos := o.BatchOffchainValidationV4(signedOrders)
+	validationResults := &ValidationResults{
+		Accepted: []*AcceptedOrderInfo{},
+		Rejected: rejectedOrderInfos,
+	}
+
+	limitOrders := []*zeroex.SignedOrderV4{}
+	rfqOrders := []*zeroex.SignedOrderV4{}
+	for _, signedOrder := range offchainValidSignedOrders {
+		if signedOrder.Type == zeroex.LimitOrderTypeV4 {
+			limitOrders = append(limitOrders, signedOrder)
+		} else {
+			rfqOrders = append(rfqOrders, signedOrder)
+		}
+	}
+	signedOrderChunks := [][]*zeroex.SignedOrderV4{}
+	signedOrderChunks = append(signedOrderChunks, o.chunkOrdersV4(limitOrders, encodedLimitOrderV4ByteLength)...)
+	signedOrderChunks = append(signedOrderChunks, o.chunkOrdersV4(rfqOrders, encodedRfqOrderV4ByteLength)...)
+
+	semaphoreChan := make(chan struct{}, concurrencyLimit)
+	defer close(semaphoreChan)
+
+	resultsMu := &sync.Mutex{}
+	wg := &sync.WaitGroup{}
+	for _, signedOrders := range signedOrderChunks {
+		wg.Add(1)
+		go func(signedOrders []*zeroex.SignedOrderV4) {
+			defer wg.Done()
+
+			// Add one to the semaphore chan. If it already has concurrencyLimit values,
+			// the request blocks here until one frees up.
+			semaphoreChan <- struct{}{}
+			defer func() { <-semaphoreChan }()
+
+			// Attempt to make the eth_call request 4 times with an exponential back-off.
+			maxDuration := 4 * time.Second
+			b := &backoff.Backoff{
+				Min:    250 * time.Millisecond, // First back-off length
+				Max:    maxDuration,            // Longest back-off length
+				Factor: 2,                      // Factor to multiple each successive back-off
+			}
+
+			for {
+				opts := &bind.CallOpts{
+					// HACK(albrow): From field should not be required for eth_call but
+					// including it here is a workaround for a bug in Ganache. Removing
+					// this line causes Ganache to crash.
+					From:        constants.GanacheDummyERC721TokenAddress,
+					Pending:     false,
+					Context:     ctx,
+					BlockNumber: blockNumber,
+				}
+
+				results, err := o.getOrderRelevantStatesV4(opts, signedOrders)
+				if err != nil {
+					log.WithFields(log.Fields{
+						"error":     err.Error(),
+						"attempt":   b.Attempt(),
+						"numOrders": len(signedOrders),
+						"orderType": signedOrders[0].Type,
+					}).Info("GetOrderRelevantStates request for V4 orders failed")
+					d := b.Duration()
+					if d == maxDuration {
+						log.WithFields(log.Fields{
+							"error":     err.Error(),
+							"numOrders": len(signedOrders),
+						}).Warning("Gave up on GetOrderRelevantStates request for V4 orders after backoff limit reached")
+						resultsMu.Lock()
+						for _, signedOrder := range signedOrders {
+							orderHash, _ := signedOrder.ComputeOrderHash()
+							validationResults.Rejected = append(validationResults.Rejected, &RejectedOrderInfo{
+								OrderHash:     orderHash,
+								SignedOrderV4: signedOrder,
+								Kind:          MeshError,
+								Status:        ROEthRPCRequestFailed,
+							})
+						}
+						resultsMu.Unlock()
+						return // Give up after 4 attempts
+					}
+					time.Sleep(d)
+					continue
+				}
+
+				resultsMu.Lock()
+				defer resultsMu.Unlock()
+				for j, orderInfo := range results.OrderInfos {
+					signedOrder := signedOrders[j]
+					orderHash := common.Hash(orderInfo.OrderHash)
+					fillableTakerAssetAmount := results.ActualFillableTakerTokenAmounts[j]
+					var status RejectedOrderStatus
+					switch {
+					case !results.IsSignatureValids[j]:
+						status = ROInvalidSignature
+					case zeroex.OrderStatusV4(orderInfo.Status) == zeroex.OS4Expired:
+						status = ROExpired
+					case zeroex.OrderStatusV4(orderInfo.Status) == zeroex.OS4Filled:
+						status = ROFullyFilled
+					case zeroex.OrderStatusV4(orderInfo.Status) == zeroex.OS4Cancelled:
+						status = ROCancelled
+					case zeroex.OrderStatusV4(orderInfo.Status) == zeroex.OS4Fillable:
+						remainingTakerAssetAmount := new(big.Int).Sub(signedOrder.TakerAmount, orderInfo.TakerTokenFilledAmount)
+						// As with V3 orders, we consider partially fillable orders as invalid.
+						if fillableTakerAssetAmount.Cmp(remainingTakerAssetAmount) != 0 {
+							status = ROUnfunded
+							break
+						}
+						validationResults.Accepted = append(validationResults.Accepted, &AcceptedOrderInfo{
+							OrderHash:                orderHash,
+							SignedOrderV4:            signedOrder,
+							FillableTakerAssetAmount: fillableTakerAssetAmount,
+							IsNew:                    areNewOrders,
+						})
+						continue
+					default:
+						// The Exchange Proxy only returns the INVALID status for orders
+						// with a zero maker or taker amount, which are rejected before
+						// we get here.
+						log.WithFields(log.Fields{
+							"orderHash": orderHash.Hex(),
+							"status":    orderInfo.Status,
+						}).Error("Unexpected V4 order status")
+						validationResults.Rejected = append(validationResults.Rejected, &RejectedOrderInfo{
+							OrderHash:     orderHash,
+							SignedOrderV4: signedOrder,
+							Kind:          MeshError,
+							Status:        ROInternalError,
+						})
+						continue
+					}
+					validationResults.Rejected = append(validationResults.Rejected, &RejectedOrderInfo{
+						OrderHash:     orderHash,
+						SignedOrderV4: signedOrder,
+						Kind:          ZeroExValidation,
+						Status:        status,
+					})
+				}
+				return
+			}
+		}(signedOrders)
+	}
+
+	wg.Wait()
+	return validationResults
+}
+
+// getOrderRelevantStatesV4 fetches the on-chain state of the given orders, all
+// of which must have the same type.
+func (o *OrderValidator) getOrderRelevantStatesV4(opts *bind.CallOpts, signedOrders []*zeroex.SignedOrderV4) (struct {
+	OrderInfos                      []wrappers.OrderInfoV4
+	ActualFillableTakerTokenAmounts []*big.Int
+	IsSignatureValids               []bool
+}, error) {
+	signatures := make([]wrappers.SignatureV4, len(signedOrders))
+	for i, signedOrder := range signedOrders {
+		signatures[i] = signedOrder.EthereumSignature()
+	}
+	if signedOrders[0].Type == zeroex.RFQOrderTypeV4 {
+		rfqOrders := make([]wrappers.RfqOrderV4, len(signedOrders))
+		for i, signedOrder := range signedOrders {
+			rfqOrders[i] = signedOrder.RfqOrder()
+		}
+		return o.exchangeV4.BatchGetRfqOrderRelevantStates(opts, rfqOrders, signatures)
+	}
+	limitOrders := make([]wrappers.LimitOrderV4, len(signedOrders))
+	for i, signedOrder := range signedOrders {
+		limitOrders[i] = signedOrder.LimitOrder()
+	}
+	return o.exchangeV4.BatchGetLimitOrderRelevantStates(opts, limitOrders, signatures)
+}
+
+// chunkOrdersV4 splits the signedOrders into chunks where the payload size of
+// each chunk is beneath the maxRequestContentLength.
+func (o *OrderValidator) chunkOrdersV4(signedOrders []*zeroex.SignedOrderV4, encodedOrderByteLength int) [][]*zeroex.SignedOrderV4 {
+	chunkSize := (o.maxRequestContentLength - jsonRPCPayloadByteLength) / encodedOrderByteLength
+	if chunkSize < 1 {
+		// This case should never be hit since we enforce that EthereumRPCMaxContentLength >= maxOrderSizeInBytes
+		log.WithField("maxRequestContentLength", o.maxRequestContentLength).Panic("EthereumRPCMaxContentLength is set so low, a single V4 order cannot fit beneath the payload limit")
+	}
+	chunks := [][]*zeroex.SignedOrderV4{}
+	for len(signedOrders) > chunkSize {
+		chunks = append(chunks, signedOrders[:chunkSize])
+		signedOrders = signedOrders[chunkSize:]
+	}
+	if len(signedOrders) > 0 {
+		chunks = append(chunks, signedOrders)
+	}
+	return chunks
+}
+
+// BatchOffchainValidationV4 validates Exchange V4 orders without making any
+// network requests. It returns the orders that passed validation and the
+// rejected order infos for those that did not.
+func (o *OrderValidator) BatchOffchainValidationV4(signedOrders []*zeroex.SignedOrderV4) ([]*zeroex.SignedOrderV4, []*RejectedOrderInfo) {
+	rejectedOrderInfos := []*RejectedOrderInfo{}
+	offchainValidSignedOrders := []*zeroex.SignedOrderV4{}
+	for _, signedOrder := range signedOrders {
+		// The order hash can only be computed if the order type and chain ID
+		// are valid, so we check those first.
+		var status *RejectedOrderStatus
+		kind := ZeroExValidation
+		switch {
+		case o.exchangeV4 == nil:
+			status, kind = &ROExchangeV4Unsupported, MeshValidation
+		case signedOrder.Type != zeroex.LimitOrderTypeV4 && signedOrder.Type != zeroex.RFQOrderTypeV4:
+			status = &ROInvalidOrderTypeV4
+		case signedOrder.ChainID == nil || signedOrder.ChainID.Cmp(big.NewInt(int64(o.chainID))) != 0:
+			status, kind = &ROIncorrectChain, MeshValidation
+		case signedOrder.VerifyingContract != o.contractAddresses.ExchangeProxy:
+			status, kind = &ROIncorrectExchangeAddress, MeshValidation
+		case signedOrder.Expiry == nil:
+			status = &ROInvalidExpiryV4
+		case !signedOrder.Expiry.IsUint64() || !signedOrder.Expiry.IsInt64():
+			status, kind = &ROMaxExpirationExceeded, MeshValidation
+		case signedOrder.MakerAmount == nil || signedOrder.MakerAmount.Sign() <= 0:
+			status = &ROInvalidMakerAssetAmount
+		case signedOrder.TakerAmount == nil || signedOrder.TakerAmount.Sign() <= 0:
+			status = &ROInvalidTakerAssetAmount
+		case !isUint128(signedOrder.MakerAmount) || !isUint128(signedOrder.TakerAmount) ||
+			(signedOrder.TakerTokenFeeAmount != nil && !isUint128(signedOrder.TakerTokenFeeAmount)):
+			status = &ROAmountOutOfRangeV4
+		case signedOrder.MakerToken == constants.NullAddress:
+			status = &ROInvalidMakerTokenV4
+		case signedOrder.TakerToken == constants.NullAddress:
+			status = &ROInvalidTakerTokenV4
+		case signedOrder.Type == zeroex.LimitOrderTypeV4 && signedOrder.Sender != constants.NullAddress:
+			status, kind = &ROSenderAddressNotAllowed, MeshValidation
+		case signedOrder.Type == zeroex.RFQOrderTypeV4 && signedOrder.TxOrigin == constants.NullAddress:
+			status = &ROInvalidTxOriginV4
+		case signedOrder.Signature.SignatureType != zeroex.EIP712SignatureV4 && signedOrder.Signature.SignatureType != zeroex.EthSignSignatureV4:
+			status = &ROInvalidSignature
+		}
+		if status != nil {
+			// If the order hash can't be computed, we still reject the order with
+			// an empty hash.
+			orderHash, _ := signedOrder.ComputeOrderHash()
+			rejectedOrderInfos = append(rejectedOrderInfos, &RejectedOrderInfo{
+				OrderHash:     orderHash,
+				SignedOrderV4: signedOrder,
+				Kind:          kind,
+				Status:        *status,
+			})
+			continue
+		}
+		offchainValidSignedOrders = append(offchainValidSignedOrders, signedOrder)
+	}
+
+	return offchainValidSignedOrders, rejectedOrderInfos
+}
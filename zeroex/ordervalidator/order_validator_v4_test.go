@@ -0,0 +1,107 @@
+// +build !js
+
+package ordervalidator
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/0xProject/0x-mesh/constants"
+	"github.com/0xProject/0x-mesh/zeroex"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testExchangeProxyAddress = common.HexToAddress("0xdef1c0ded9bec7f1a1670819833240f027b25eff")
+
+func newTestOrderV4(t *testing.T) *zeroex.SignedOrderV4 {
+	order := &zeroex.OrderV4{
+		Type:                zeroex.LimitOrderTypeV4,
+		ChainID:             big.NewInt(constants.TestChainID),
+		VerifyingContract:   testExchangeProxyAddress,
+		MakerToken:          ganacheAddresses.ZRXToken,
+		TakerToken:          ganacheAddresses.WETH9,
+		MakerAmount:         big.NewInt(100),
+		TakerAmount:         big.NewInt(42),
+		TakerTokenFeeAmount: big.NewInt(0),
+		Maker:               constants.GanacheAccount1,
+		Taker:               constants.NullAddress,
+		Sender:              constants.NullAddress,
+		FeeRecipient:        constants.NullAddress,
+		TxOrigin:            constants.NullAddress,
+		Expiry:              big.NewInt(1548619325),
+		Salt:                big.NewInt(1548619145450),
+	}
+	signedOrder, err := zeroex.SignTestOrderV4(order)
+	require.NoError(t, err)
+	return signedOrder
+}
+
+func TestBatchOffchainValidationV4(t *testing.T) {
+	tooLarge := new(big.Int).Add(maxUint128, big.NewInt(1))
+	testCases := []struct {
+		name           string
+		modify         func(order *zeroex.SignedOrderV4)
+		expectedStatus *RejectedOrderStatus
+	}{
+		{
+			name:   "valid order",
+			modify: func(order *zeroex.SignedOrderV4) {},
+		},
+		{
+			name:           "missing expiry",
+			modify:         func(order *zeroex.SignedOrderV4) { order.Expiry = nil },
+			expectedStatus: &ROInvalidExpiryV4,
+		},
+		{
+			name:           "zero maker amount",
+			modify:         func(order *zeroex.SignedOrderV4) { order.MakerAmount = big.NewInt(0) },
+			expectedStatus: &ROInvalidMakerAssetAmount,
+		},
+		{
+			name:   "max uint128 amounts",
+			modify: func(order *zeroex.SignedOrderV4) { order.MakerAmount = maxUint128; order.TakerAmount = maxUint128 },
+		},
+		{
+			name:           "maker amount too large",
+			modify:         func(order *zeroex.SignedOrderV4) { order.MakerAmount = tooLarge },
+			expectedStatus: &ROAmountOutOfRangeV4,
+		},
+		{
+			name:           "taker amount too large",
+			modify:         func(order *zeroex.SignedOrderV4) { order.TakerAmount = tooLarge },
+			expectedStatus: &ROAmountOutOfRangeV4,
+		},
+		{
+			name:           "taker token fee amount too large",
+			modify:         func(order *zeroex.SignedOrderV4) { order.TakerTokenFeeAmount = tooLarge },
+			expectedStatus: &ROAmountOutOfRangeV4,
+		},
+		{
+			name:           "negative taker token fee amount",
+			modify:         func(order *zeroex.SignedOrderV4) { order.TakerTokenFeeAmount = big.NewInt(-1) },
+			expectedStatus: &ROAmountOutOfRangeV4,
+		},
+	}
+
+	contractAddresses := ganacheAddresses
+	contractAddresses.ExchangeProxy = testExchangeProxyAddress
+	orderValidator, err := New(ethClient, constants.TestChainID, constants.TestMaxContentLength, contractAddresses)
+	require.NoError(t, err)
+
+	for _, testCase := range testCases {
+		signedOrder := newTestOrderV4(t)
+		testCase.modify(signedOrder)
+		signedOrder.ResetHash()
+		accepted, rejected := orderValidator.BatchOffchainValidationV4([]*zeroex.SignedOrderV4{signedOrder})
+		if testCase.expectedStatus == nil {
+			assert.Len(t, accepted, 1, testCase.name)
+			assert.Len(t, rejected, 0, testCase.name)
+			continue
+		}
+		assert.Len(t, accepted, 0, testCase.name)
+		require.Len(t, rejected, 1, testCase.name)
+		assert.Equal(t, *testCase.expectedStatus, rejected[0].Status, testCase.name)
+	}
+}
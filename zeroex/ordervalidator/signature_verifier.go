@@ -0,0 +1,360 @@
+package ordervalidator
+
+import (
+	"container/list"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/0xProject/0x-mesh/constants"
+	"github.com/0xProject/0x-mesh/ethereum"
+	"github.com/0xProject/0x-mesh/ethereum/wrappers"
+	"github.com/0xProject/0x-mesh/zeroex"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// signatureCacheMaxAge is how long the result of a contract signature check is
+// cached. Validator signatures are invalidated as soon as a
+// SignatureValidatorApproval event is seen, but wallet contracts can start or
+// stop accepting a signature without emitting any event we know about, so
+// their results must eventually be re-checked.
+const signatureCacheMaxAge = 10 * time.Minute
+
+// signatureCacheMaxSize is the maximum number of contract signature check
+// results which are cached. Results are cached for orders received from any
+// peer (including invalid ones), so the size must be bounded.
+const signatureCacheMaxSize = 50000
+
+// ethSignPrefix is prepended to the order hash before it is signed with
+// `eth_sign`.
+var ethSignPrefix = []byte("\x19Ethereum Signed Message:\n32")
+
+// isContractSignatureType returns true if the validity of signatures of the
+// given type depends on contract state and the result of checking them can be
+// cached until an event which affects them is seen. PreSigned signatures also
+// depend on contract state, but the Exchange does not emit an event when a
+// hash is pre-signed, so they are never cached.
+func isContractSignatureType(signatureType zeroex.SignatureType) bool {
+	switch signatureType {
+	case zeroex.ValidatorSignature, zeroex.WalletSignature, zeroex.EIP1271WalletSignature:
+		return true
+	default:
+		return false
+	}
+}
+
+// SignatureCache caches the results of contract signature checks per signer
+// and order hash. Entries expire after a maximum age and must be invalidated
+// when an event which could change the result (e.g. a
+// SignatureValidatorApproval event) is seen. Expired entries are pruned
+// whenever a new result is cached, and if the cache is full the oldest entry is
+// evicted.
+type SignatureCache struct {
+	mu      sync.Mutex
+	maxAge  time.Duration
+	maxSize int
+	// entries maps a signer address to an order hash to the element of order
+	// which holds the result of the last signature check.
+	entries map[common.Address]map[common.Hash]*list.Element
+	// order holds the entries from least to most recently checked. Since all
+	// entries have the same max age, expired entries are always at the front.
+	order *list.List
+}
+
+type signatureCacheEntry struct {
+	signerAddress common.Address
+	orderHash     common.Hash
+	// signatureHash is the hash of the signature that was checked. The same
+	// order can be received with different signatures and only the result for
+	// the exact same signature may be reused.
+	signatureHash common.Hash
+	isValid       bool
+	checkedAt     time.Time
+}
+
+// NewSignatureCache creates a new SignatureCache whose entries expire after
+// maxAge and which holds at most maxSize entries.
+func NewSignatureCache(maxAge time.Duration, maxSize int) *SignatureCache {
+	return &SignatureCache{
+		maxAge:  maxAge,
+		maxSize: maxSize,
+		entries: map[common.Address]map[common.Hash]*list.Element{},
+		order:   list.New(),
+	}
+}
+
+// Get returns the cached result for the given signer, order hash and signature
+// and whether or not it was found.
+func (c *SignatureCache) Get(signerAddress common.Address, orderHash common.Hash, signature []byte) (bool, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	element, found := c.entries[signerAddress][orderHash]
+	if !found {
+		return false, false
+	}
+	entry := element.Value.(*signatureCacheEntry)
+	if time.Since(entry.checkedAt) > c.maxAge {
+		c.remove(element)
+		return false, false
+	}
+	if entry.signatureHash != crypto.Keccak256Hash(signature) {
+		return false, false
+	}
+	return entry.isValid, true
+}
+
+// Set caches the result of checking the given signature.
+func (c *SignatureCache) Set(signerAddress common.Address, orderHash common.Hash, signature []byte, isValid bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if element, found := c.entries[signerAddress][orderHash]; found {
+		c.remove(element)
+	}
+	c.pruneExpired()
+	for c.order.Len() >= c.maxSize && c.order.Len() > 0 {
+		c.remove(c.order.Front())
+	}
+	hashToElement, found := c.entries[signerAddress]
+	if !found {
+		hashToElement = map[common.Hash]*list.Element{}
+		c.entries[signerAddress] = hashToElement
+	}
+	hashToElement[orderHash] = c.order.PushBack(&signatureCacheEntry{
+		signerAddress: signerAddress,
+		orderHash:     orderHash,
+		signatureHash: crypto.Keccak256Hash(signature),
+		isValid:       isValid,
+		checkedAt:     time.Now(),
+	})
+}
+
+// Len returns the number of cached results, including expired results which
+// have not been pruned yet.
+func (c *SignatureCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// InvalidateSigner removes all cached results for the given signer.
+func (c *SignatureCache) InvalidateSigner(signerAddress common.Address) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, element := range c.entries[signerAddress] {
+		c.order.Remove(element)
+	}
+	delete(c.entries, signerAddress)
+}
+
+// Clear removes all cached results. It should be called whenever blocks are
+// removed from the chain, since we cannot tell which results were affected.
+func (c *SignatureCache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = map[common.Address]map[common.Hash]*list.Element{}
+	c.order.Init()
+}
+
+// pruneExpired removes all expired entries. c.mu must be held.
+func (c *SignatureCache) pruneExpired() {
+	for element := c.order.Front(); element != nil; element = c.order.Front() {
+		if time.Since(element.Value.(*signatureCacheEntry).checkedAt) <= c.maxAge {
+			return
+		}
+		c.remove(element)
+	}
+}
+
+// remove removes the given entry. c.mu must be held.
+func (c *SignatureCache) remove(element *list.Element) {
+	entry := c.order.Remove(element).(*signatureCacheEntry)
+	hashToElement := c.entries[entry.signerAddress]
+	delete(hashToElement, entry.orderHash)
+	if len(hashToElement) == 0 {
+		delete(c.entries, entry.signerAddress)
+	}
+}
+
+// SignatureVerifier checks order signatures with as few eth_calls as possible.
+// EIP712 and EthSign signatures are verified off-chain with ecrecover. The
+// results of checking Validator, Wallet and EIP1271Wallet signatures are
+// cached, and signatures which are not cached are checked with a single call
+// to Multicall if it is configured, or with one call to the Exchange per order
+// otherwise.
+type SignatureVerifier struct {
+	exchangeABI     abi.ABI
+	exchangeAddress common.Address
+	exchange        *wrappers.ExchangeCaller
+	multicall       *wrappers.MulticallCaller
+	cache           *SignatureCache
+}
+
+// NewSignatureVerifier creates a new SignatureVerifier which stores the results
+// of contract signature checks in the given cache.
+func NewSignatureVerifier(contractCaller bind.ContractCaller, contractAddresses ethereum.ContractAddresses, cache *SignatureCache) (*SignatureVerifier, error) {
+	exchangeABI, err := abi.JSON(strings.NewReader(wrappers.ExchangeABI))
+	if err != nil {
+		return nil, err
+	}
+	exchange, err := wrappers.NewExchangeCaller(contractAddresses.Exchange, contractCaller)
+	if err != nil {
+		return nil, err
+	}
+	// Multicall is optional. If it is not set, each signature is checked with
+	// a separate call to the Exchange.
+	var multicall *wrappers.MulticallCaller
+	if contractAddresses.Multicall != constants.NullAddress {
+		multicall, err = wrappers.NewMulticallCaller(contractAddresses.Multicall, contractCaller)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &SignatureVerifier{
+		exchangeABI:     exchangeABI,
+		exchangeAddress: contractAddresses.Exchange,
+		exchange:        exchange,
+		multicall:       multicall,
+		cache:           cache,
+	}, nil
+}
+
+// VerifySignatures returns whether or not each of the given orders has a valid
+// signature, in the same order as the given orders.
+func (v *SignatureVerifier) VerifySignatures(opts *bind.CallOpts, signedOrders []*zeroex.SignedOrder) ([]bool, error) {
+	results := make([]bool, len(signedOrders))
+	uncheckedIndexes := []int{}
+	uncheckedOrders := []*zeroex.SignedOrder{}
+	for i, signedOrder := range signedOrders {
+		if len(signedOrder.Signature) == 0 {
+			continue
+		}
+		orderHash, err := signedOrder.ComputeOrderHash()
+		if err != nil {
+			return nil, err
+		}
+		signatureType := zeroex.SignatureType(signedOrder.Signature[len(signedOrder.Signature)-1])
+		switch {
+		case signatureType == zeroex.EIP712Signature || signatureType == zeroex.EthSignSignature:
+			results[i] = isValidECSignature(signedOrder.Signature, orderHash, signedOrder.MakerAddress)
+			continue
+		case isContractSignatureType(signatureType):
+			if isValid, found := v.cache.Get(signedOrder.MakerAddress, orderHash, signedOrder.Signature); found {
+				results[i] = isValid
+				continue
+			}
+		}
+		uncheckedIndexes = append(uncheckedIndexes, i)
+		uncheckedOrders = append(uncheckedOrders, signedOrder)
+	}
+	if len(uncheckedOrders) == 0 {
+		return results, nil
+	}
+
+	var checkResults []bool
+	var err error
+	if v.multicall != nil {
+		checkResults, err = v.checkSignaturesWithMulticall(opts, uncheckedOrders)
+	} else {
+		checkResults, err = v.checkSignatures(opts, uncheckedOrders)
+	}
+	if err != nil {
+		return nil, err
+	}
+	for j, signedOrder := range uncheckedOrders {
+		results[uncheckedIndexes[j]] = checkResults[j]
+		signatureType := zeroex.SignatureType(signedOrder.Signature[len(signedOrder.Signature)-1])
+		if !isContractSignatureType(signatureType) {
+			continue
+		}
+		orderHash, err := signedOrder.ComputeOrderHash()
+		if err != nil {
+			return nil, err
+		}
+		v.cache.Set(signedOrder.MakerAddress, orderHash, signedOrder.Signature, checkResults[j])
+	}
+	return results, nil
+}
+
+// checkSignatures calls Exchange.isValidOrderSignature once for each order.
+func (v *SignatureVerifier) checkSignatures(opts *bind.CallOpts, signedOrders []*zeroex.SignedOrder) ([]bool, error) {
+	results := make([]bool, len(signedOrders))
+	for i, signedOrder := range signedOrders {
+		isValid, err := v.exchange.IsValidOrderSignature(opts, signedOrder.Trim(), signedOrder.Signature)
+		if err != nil {
+			// The Exchange reverts for unsupported signature types and for wallet or
+			// validator contracts which revert. DevUtils treats these signatures as
+			// invalid, so we do the same.
+			if isRevertError(err) {
+				continue
+			}
+			return nil, err
+		}
+		results[i] = isValid
+	}
+	return results, nil
+}
+
+// checkSignaturesWithMulticall calls Exchange.isValidOrderSignature for all
+// orders in a single call to Multicall.tryAggregate. Calls which revert are
+// treated as invalid signatures, the same as in checkSignatures.
+func (v *SignatureVerifier) checkSignaturesWithMulticall(opts *bind.CallOpts, signedOrders []*zeroex.SignedOrder) ([]bool, error) {
+	calls := make([]wrappers.MulticallCall, len(signedOrders))
+	for i, signedOrder := range signedOrders {
+		callData, err := v.exchangeABI.Pack("isValidOrderSignature", signedOrder.Trim(), signedOrder.Signature)
+		if err != nil {
+			return nil, err
+		}
+		calls[i] = wrappers.MulticallCall{
+			Target:   v.exchangeAddress,
+			CallData: callData,
+		}
+	}
+	callResults, err := v.multicall.TryAggregate(opts, false, calls)
+	if err != nil {
+		return nil, err
+	}
+	if len(callResults) != len(calls) {
+		return nil, errors.New("unexpected number of results returned by Multicall")
+	}
+	results := make([]bool, len(signedOrders))
+	for i, callResult := range callResults {
+		if !callResult.Success {
+			continue
+		}
+		var isValid bool
+		if err := v.exchangeABI.Unpack(&isValid, "isValidOrderSignature", callResult.ReturnData); err != nil {
+			continue
+		}
+		results[i] = isValid
+	}
+	return results, nil
+}
+
+// isValidECSignature verifies an EIP712 or EthSign signature the same way as
+// the Exchange contract. The signature must be in the [V || R || S || type]
+// format.
+func isValidECSignature(signature []byte, orderHash common.Hash, signerAddress common.Address) bool {
+	if len(signature) != 66 || signerAddress == constants.NullAddress {
+		return false
+	}
+	v := signature[0]
+	if v != 27 && v != 28 {
+		return false
+	}
+	hash := orderHash.Bytes()
+	if zeroex.SignatureType(signature[65]) == zeroex.EthSignSignature {
+		hash = crypto.Keccak256(ethSignPrefix, orderHash.Bytes())
+	}
+	rsv := make([]byte, 65)
+	copy(rsv, signature[1:65])
+	rsv[64] = v - 27
+	publicKey, err := crypto.SigToPub(hash, rsv)
+	if err != nil {
+		return false
+	}
+	return crypto.PubkeyToAddress(*publicKey) == signerAddress
+}
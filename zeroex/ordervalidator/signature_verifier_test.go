@@ -0,0 +1,283 @@
+// +build !js
+
+package ordervalidator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/0xProject/0x-mesh/constants"
+	"github.com/0xProject/0x-mesh/ethereum/signer"
+	"github.com/0xProject/0x-mesh/ethereum/wrappers"
+	"github.com/0xProject/0x-mesh/scenario"
+	"github.com/0xProject/0x-mesh/zeroex"
+	goethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignatureCache(t *testing.T) {
+	signerAddress := constants.GanacheAccount1
+	orderHash := common.HexToHash("0x0bd69c50d82412baa611657851a5cd4cbec05205fb204c2548289d6bd11d4ffd")
+	signature := common.Hex2Bytes("1234567804")
+
+	cache := NewSignatureCache(time.Minute, 10)
+	_, found := cache.Get(signerAddress, orderHash, signature)
+	assert.False(t, found)
+
+	cache.Set(signerAddress, orderHash, signature, true)
+	isValid, found := cache.Get(signerAddress, orderHash, signature)
+	assert.True(t, found)
+	assert.True(t, isValid)
+
+	// Results must only be reused for the exact same signature.
+	_, found = cache.Get(signerAddress, orderHash, common.Hex2Bytes("8765432104"))
+	assert.False(t, found)
+
+	cache.InvalidateSigner(constants.GanacheAccount2)
+	_, found = cache.Get(signerAddress, orderHash, signature)
+	assert.True(t, found, "invalidating another signer should not remove the result")
+	cache.InvalidateSigner(signerAddress)
+	_, found = cache.Get(signerAddress, orderHash, signature)
+	assert.False(t, found)
+
+	cache.Set(signerAddress, orderHash, signature, false)
+	isValid, found = cache.Get(signerAddress, orderHash, signature)
+	assert.True(t, found)
+	assert.False(t, isValid)
+	cache.Clear()
+	_, found = cache.Get(signerAddress, orderHash, signature)
+	assert.False(t, found)
+}
+
+func TestSignatureCacheExpiration(t *testing.T) {
+	signerAddress := constants.GanacheAccount1
+	orderHash := common.HexToHash("0x0bd69c50d82412baa611657851a5cd4cbec05205fb204c2548289d6bd11d4ffd")
+	signature := common.Hex2Bytes("1234567804")
+
+	cache := NewSignatureCache(time.Minute, 10)
+	cache.Set(signerAddress, orderHash, signature, true)
+	cache.entries[signerAddress][orderHash].Value.(*signatureCacheEntry).checkedAt = time.Now().Add(-2 * time.Minute)
+	_, found := cache.Get(signerAddress, orderHash, signature)
+	assert.False(t, found)
+	assert.Equal(t, 0, cache.Len(), "expired result should have been removed")
+
+	// Expired results are pruned when a new result is cached.
+	cache.Set(signerAddress, orderHash, signature, true)
+	cache.entries[signerAddress][orderHash].Value.(*signatureCacheEntry).checkedAt = time.Now().Add(-2 * time.Minute)
+	otherOrderHash := common.HexToHash("0x1")
+	cache.Set(signerAddress, otherOrderHash, signature, true)
+	assert.Equal(t, 1, cache.Len())
+	_, found = cache.Get(signerAddress, otherOrderHash, signature)
+	assert.True(t, found)
+}
+
+func TestSignatureCacheMaxSize(t *testing.T) {
+	signature := common.Hex2Bytes("1234567804")
+	cache := NewSignatureCache(time.Minute, 3)
+	for i := int64(0); i < 5; i++ {
+		cache.Set(constants.GanacheAccount1, common.BigToHash(big.NewInt(i)), signature, true)
+	}
+	assert.Equal(t, 3, cache.Len())
+	// The oldest results are evicted first.
+	for i := int64(0); i < 5; i++ {
+		_, found := cache.Get(constants.GanacheAccount1, common.BigToHash(big.NewInt(i)), signature)
+		assert.Equal(t, i >= 2, found, "order hash %d", i)
+	}
+
+	// Replacing a result moves it to the back, so it is evicted last.
+	cache.Set(constants.GanacheAccount1, common.BigToHash(big.NewInt(2)), signature, false)
+	cache.Set(constants.GanacheAccount2, common.BigToHash(big.NewInt(5)), signature, true)
+	assert.Equal(t, 3, cache.Len())
+	_, found := cache.Get(constants.GanacheAccount1, common.BigToHash(big.NewInt(3)), signature)
+	assert.False(t, found)
+	isValid, found := cache.Get(constants.GanacheAccount1, common.BigToHash(big.NewInt(2)), signature)
+	assert.True(t, found)
+	assert.False(t, isValid)
+
+	cache.InvalidateSigner(constants.GanacheAccount1)
+	assert.Equal(t, 1, cache.Len())
+	cache.Clear()
+	assert.Equal(t, 0, cache.Len())
+}
+
+func TestIsValidECSignature(t *testing.T) {
+	ethSignOrder := scenario.NewSignedTestOrder(t)
+	ethSignOrderHash, err := ethSignOrder.ComputeOrderHash()
+	require.NoError(t, err)
+	require.Equal(t, zeroex.EthSignSignature, zeroex.SignatureType(ethSignOrder.Signature[65]))
+
+	eip712Order, err := zeroex.SignOrder(signer.NewTestSigner(), &ethSignOrder.Order, zeroex.EIP712Signature)
+	require.NoError(t, err)
+
+	tamperedSignature := append([]byte{}, ethSignOrder.Signature...)
+	tamperedSignature[10] ^= 0xff
+	invalidVSignature := append([]byte{}, ethSignOrder.Signature...)
+	invalidVSignature[0] = 1
+	// An EthSign signature must not be accepted as an EIP712 signature, since
+	// the signed hash is different.
+	wrongTypeSignature := append([]byte{}, ethSignOrder.Signature...)
+	wrongTypeSignature[65] = byte(zeroex.EIP712Signature)
+
+	testCases := []struct {
+		description   string
+		signature     []byte
+		signerAddress common.Address
+		expected      bool
+	}{
+		{"valid EthSign signature", ethSignOrder.Signature, ethSignOrder.MakerAddress, true},
+		{"valid EIP712 signature", eip712Order.Signature, ethSignOrder.MakerAddress, true},
+		{"wrong signer", ethSignOrder.Signature, constants.GanacheAccount2, false},
+		{"null signer", ethSignOrder.Signature, constants.NullAddress, false},
+		{"tampered signature", tamperedSignature, ethSignOrder.MakerAddress, false},
+		{"invalid v", invalidVSignature, ethSignOrder.MakerAddress, false},
+		{"wrong signature type", wrongTypeSignature, ethSignOrder.MakerAddress, false},
+		{"wrong length", ethSignOrder.Signature[1:], ethSignOrder.MakerAddress, false},
+	}
+	for _, testCase := range testCases {
+		actual := isValidECSignature(testCase.signature, ethSignOrderHash, testCase.signerAddress)
+		assert.Equal(t, testCase.expected, actual, testCase.description)
+	}
+}
+
+var testMulticallAddress = common.HexToAddress("0x5ba1e12693dc8f9c48aad8770482f4739beed696")
+
+// fakeSignatureContractCaller is a bind.ContractCaller which returns canned
+// results for calls to Multicall.tryAggregate and
+// Exchange.isValidOrderSignature.
+type fakeSignatureContractCaller struct {
+	// multicallResults are returned by every call to Multicall.tryAggregate.
+	multicallResults []wrappers.MulticallResult
+	// exchangeResults are returned by consecutive calls to
+	// Exchange.isValidOrderSignature. A nil result means the call reverts.
+	exchangeResults []*bool
+	multicallCalls  int
+	exchangeCalls   int
+}
+
+func (c *fakeSignatureContractCaller) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	return []byte{0x01}, nil
+}
+
+func (c *fakeSignatureContractCaller) CallContract(ctx context.Context, call goethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	switch *call.To {
+	case testMulticallAddress:
+		c.multicallCalls++
+		multicallABI, err := abi.JSON(strings.NewReader(wrappers.MulticallABI))
+		if err != nil {
+			return nil, err
+		}
+		return multicallABI.Methods["tryAggregate"].Outputs.Pack(c.multicallResults)
+	case ganacheAddresses.Exchange:
+		result := c.exchangeResults[c.exchangeCalls]
+		c.exchangeCalls++
+		if result == nil {
+			return nil, errors.New("execution reverted")
+		}
+		return packIsValidOrderSignatureResult(*result)
+	default:
+		return nil, fmt.Errorf("unexpected call to %s", call.To.Hex())
+	}
+}
+
+func packIsValidOrderSignatureResult(isValid bool) ([]byte, error) {
+	exchangeABI, err := abi.JSON(strings.NewReader(wrappers.ExchangeABI))
+	if err != nil {
+		return nil, err
+	}
+	return exchangeABI.Methods["isValidOrderSignature"].Outputs.Pack(isValid)
+}
+
+// newContractSignatureTestOrders returns orders with the given number of
+// distinct Wallet signatures, whose validity can only be checked by calling
+// the Exchange.
+func newContractSignatureTestOrders(t *testing.T, count int) []*zeroex.SignedOrder {
+	signedOrders := make([]*zeroex.SignedOrder, count)
+	for i := range signedOrders {
+		signedOrder := scenario.NewSignedTestOrder(t)
+		signedOrder.Salt = big.NewInt(int64(i))
+		signedOrder.ResetHash()
+		signedOrder.Signature = []byte{byte(i), byte(zeroex.WalletSignature)}
+		signedOrders[i] = signedOrder
+	}
+	return signedOrders
+}
+
+func TestVerifySignaturesWithMulticall(t *testing.T) {
+	validResult, err := packIsValidOrderSignatureResult(true)
+	require.NoError(t, err)
+	invalidResult, err := packIsValidOrderSignatureResult(false)
+	require.NoError(t, err)
+	caller := &fakeSignatureContractCaller{
+		multicallResults: []wrappers.MulticallResult{
+			{Success: true, ReturnData: validResult},
+			{Success: true, ReturnData: invalidResult},
+			// The call reverted.
+			{Success: false, ReturnData: []byte("revert reason")},
+			// The call returned data which can't be decoded.
+			{Success: true, ReturnData: []byte{0x01}},
+		},
+	}
+	contractAddresses := ganacheAddresses
+	contractAddresses.Multicall = testMulticallAddress
+	verifier, err := NewSignatureVerifier(caller, contractAddresses, NewSignatureCache(time.Minute, 10))
+	require.NoError(t, err)
+
+	ecSignatureOrder := scenario.NewSignedTestOrder(t)
+	contractSignatureOrders := newContractSignatureTestOrders(t, 4)
+	signedOrders := append([]*zeroex.SignedOrder{ecSignatureOrder}, contractSignatureOrders...)
+	expectedResults := []bool{true, true, false, false, false}
+
+	results, err := verifier.VerifySignatures(&bind.CallOpts{}, signedOrders)
+	require.NoError(t, err)
+	assert.Equal(t, expectedResults, results)
+	assert.Equal(t, 1, caller.multicallCalls, "all contract signatures should be checked with a single call")
+	assert.Equal(t, 0, caller.exchangeCalls)
+
+	// The results of the contract signature checks are cached.
+	results, err = verifier.VerifySignatures(&bind.CallOpts{}, signedOrders)
+	require.NoError(t, err)
+	assert.Equal(t, expectedResults, results)
+	assert.Equal(t, 1, caller.multicallCalls)
+}
+
+func TestCheckSignaturesWithMulticallUnexpectedNumberOfResults(t *testing.T) {
+	validResult, err := packIsValidOrderSignatureResult(true)
+	require.NoError(t, err)
+	caller := &fakeSignatureContractCaller{
+		multicallResults: []wrappers.MulticallResult{
+			{Success: true, ReturnData: validResult},
+		},
+	}
+	contractAddresses := ganacheAddresses
+	contractAddresses.Multicall = testMulticallAddress
+	verifier, err := NewSignatureVerifier(caller, contractAddresses, NewSignatureCache(time.Minute, 10))
+	require.NoError(t, err)
+
+	_, err = verifier.checkSignaturesWithMulticall(&bind.CallOpts{}, newContractSignatureTestOrders(t, 2))
+	assert.Error(t, err)
+}
+
+func TestVerifySignaturesWithoutMulticall(t *testing.T) {
+	valid := true
+	invalid := false
+	caller := &fakeSignatureContractCaller{
+		exchangeResults: []*bool{&valid, &invalid, nil},
+	}
+	verifier, err := NewSignatureVerifier(caller, ganacheAddresses, NewSignatureCache(time.Minute, 10))
+	require.NoError(t, err)
+
+	results, err := verifier.VerifySignatures(&bind.CallOpts{}, newContractSignatureTestOrders(t, 3))
+	require.NoError(t, err)
+	assert.Equal(t, []bool{true, false, false}, results)
+	assert.Equal(t, 3, caller.exchangeCalls, "each signature should be checked with a separate call")
+	assert.Equal(t, 0, caller.multicallCalls)
+}
@@ -0,0 +1,97 @@
+package ordervalidator
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/0xProject/0x-mesh/ethereum"
+	"github.com/0xProject/0x-mesh/ethereum/ethrpcclient"
+	"github.com/0xProject/0x-mesh/ethereum/wrappers"
+	"github.com/0xProject/0x-mesh/zeroex"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+)
+
+// The names of the supported StateFetcher backends.
+const (
+	// DevUtilsBackend fetches order-relevant state with a single call to the
+	// DevUtils contract per chunk of orders. It is the default.
+	DevUtilsBackend = "devutils"
+	// DirectBackend fetches order-relevant state with individual calls to the
+	// Exchange and token contracts. It is slower than DevUtilsBackend but works
+	// on chains where DevUtils is not deployed.
+	DirectBackend = "direct"
+)
+
+// OrderRelevantStates contains the on-chain state needed to validate a batch of
+// orders. Each slice contains exactly one entry per order, in the same order as
+// the orders that were passed to GetOrderRelevantStates.
+type OrderRelevantStates struct {
+	OrdersInfo                []wrappers.OrderInfo
+	FillableTakerAssetAmounts []*big.Int
+	IsValidSignature          []bool
+}
+
+// StateFetcher fetches the on-chain state needed to validate orders.
+type StateFetcher interface {
+	// GetOrderRelevantStates returns the order info, fillable taker asset amount
+	// and signature validity for each of the given orders at the block specified
+	// in opts.
+	GetOrderRelevantStates(opts *bind.CallOpts, signedOrders []*zeroex.SignedOrder) (*OrderRelevantStates, error)
+}
+
+// signatureCachingStateFetcher is implemented by StateFetchers which cache the
+// results of contract signature checks.
+type signatureCachingStateFetcher interface {
+	SignatureCache() *SignatureCache
+}
+
+// NewStateFetcher returns a StateFetcher for the backend with the given name.
+// Custom asset proxies are only supported by the DirectBackend.
+func NewStateFetcher(backend string, client ethrpcclient.Client, contractAddresses ethereum.ContractAddresses, assetProxies []zeroex.AssetProxy) (StateFetcher, error) {
+	switch backend {
+	case DevUtilsBackend:
+		if len(assetProxies) > 0 {
+			return nil, fmt.Errorf("custom asset proxies are not supported by the %q order validator backend", DevUtilsBackend)
+		}
+		return NewDevUtilsStateFetcher(client, contractAddresses)
+	case DirectBackend:
+		return NewDirectStateFetcher(client, contractAddresses, assetProxies)
+	default:
+		return nil, fmt.Errorf("unknown order validator backend: %q", backend)
+	}
+}
+
+// DevUtilsStateFetcher is a StateFetcher which uses the DevUtils contract.
+type DevUtilsStateFetcher struct {
+	devUtils *wrappers.DevUtilsCaller
+}
+
+// NewDevUtilsStateFetcher creates a new DevUtilsStateFetcher.
+func NewDevUtilsStateFetcher(contractCaller bind.ContractCaller, contractAddresses ethereum.ContractAddresses) (*DevUtilsStateFetcher, error) {
+	devUtils, err := wrappers.NewDevUtilsCaller(contractAddresses.DevUtils, contractCaller)
+	if err != nil {
+		return nil, err
+	}
+	return &DevUtilsStateFetcher{
+		devUtils: devUtils,
+	}, nil
+}
+
+// GetOrderRelevantStates implements StateFetcher.
+func (f *DevUtilsStateFetcher) GetOrderRelevantStates(opts *bind.CallOpts, signedOrders []*zeroex.SignedOrder) (*OrderRelevantStates, error) {
+	trimmedOrders := make([]wrappers.TrimmedOrder, len(signedOrders))
+	signatures := make([][]byte, len(signedOrders))
+	for i, signedOrder := range signedOrders {
+		trimmedOrders[i] = signedOrder.Trim()
+		signatures[i] = signedOrder.Signature
+	}
+	results, err := f.devUtils.GetOrderRelevantStates(opts, trimmedOrders, signatures)
+	if err != nil {
+		return nil, err
+	}
+	return &OrderRelevantStates{
+		OrdersInfo:                results.OrdersInfo,
+		FillableTakerAssetAmounts: results.FillableTakerAssetAmounts,
+		IsValidSignature:          results.IsValidSignature,
+	}, nil
+}
@@ -0,0 +1,88 @@
+package orderwatch
+
+import (
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// balanceCache caches the transferable amount (i.e. the minimum of the balance
+// and the asset proxy allowance) of assets held by makers. Entries are fetched
+// at the latest block and remain valid until a Transfer, Approval, Deposit or
+// Withdrawal event involving the owner and token is seen, at which point they
+// must be invalidated.
+type balanceCache struct {
+	mu sync.Mutex
+	// amounts maps an owner address to a token address to the asset data for
+	// that token to the transferable amount.
+	amounts map[common.Address]map[common.Address]map[string]*big.Int
+}
+
+func newBalanceCache() *balanceCache {
+	return &balanceCache{
+		amounts: map[common.Address]map[common.Address]map[string]*big.Int{},
+	}
+}
+
+// get returns the cached transferable amount for the given owner and asset data
+// and whether or not it was found.
+func (c *balanceCache) get(owner common.Address, tokenAddress common.Address, assetData []byte) (*big.Int, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	amount, found := c.amounts[owner][tokenAddress][common.Bytes2Hex(assetData)]
+	return amount, found
+}
+
+// set caches the transferable amount for the given owner and asset data.
+// tokenAddress should be the address of the token contract for the asset data.
+func (c *balanceCache) set(owner common.Address, tokenAddress common.Address, assetData []byte, amount *big.Int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	tokenToAmounts, found := c.amounts[owner]
+	if !found {
+		tokenToAmounts = map[common.Address]map[string]*big.Int{}
+		c.amounts[owner] = tokenToAmounts
+	}
+	assetDataToAmount, found := tokenToAmounts[tokenAddress]
+	if !found {
+		assetDataToAmount = map[string]*big.Int{}
+		tokenToAmounts[tokenAddress] = assetDataToAmount
+	}
+	assetDataToAmount[common.Bytes2Hex(assetData)] = amount
+}
+
+// invalidate removes all cached amounts for the given owner and token.
+func (c *balanceCache) invalidate(owner common.Address, tokenAddress common.Address) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	tokenToAmounts, found := c.amounts[owner]
+	if !found {
+		return
+	}
+	delete(tokenToAmounts, tokenAddress)
+	if len(tokenToAmounts) == 0 {
+		delete(c.amounts, owner)
+	}
+}
+
+// invalidateToken removes all cached amounts for the given token, regardless of
+// owner. It should be called whenever we stop decoding events for the token,
+// since we would no longer know when to invalidate its entries.
+func (c *balanceCache) invalidateToken(tokenAddress common.Address) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for owner, tokenToAmounts := range c.amounts {
+		delete(tokenToAmounts, tokenAddress)
+		if len(tokenToAmounts) == 0 {
+			delete(c.amounts, owner)
+		}
+	}
+}
+
+// clear removes all cached amounts.
+func (c *balanceCache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.amounts = map[common.Address]map[common.Address]map[string]*big.Int{}
+}
@@ -0,0 +1,58 @@
+package orderwatch
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/0xProject/0x-mesh/constants"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBalanceCache(t *testing.T) {
+	cache := newBalanceCache()
+	owner := constants.GanacheAccount1
+	otherOwner := constants.GanacheAccount2
+	tokenAddress := common.HexToAddress("0x871dd7c2b4b25e1aa18728e9d5f2af4c4e431f5c")
+	otherTokenAddress := common.HexToAddress("0x0b1ba0af832d7c05fd64161e0db78e85978e8082")
+	assetData := common.Hex2Bytes("f47261b0000000000000000000000000871dd7c2b4b25e1aa18728e9d5f2af4c4e431f5c")
+	otherAssetData := common.Hex2Bytes("f47261b00000000000000000000000000b1ba0af832d7c05fd64161e0db78e85978e8082")
+
+	_, found := cache.get(owner, tokenAddress, assetData)
+	assert.False(t, found)
+
+	cache.set(owner, tokenAddress, assetData, big.NewInt(42))
+	cache.set(owner, otherTokenAddress, otherAssetData, big.NewInt(7))
+	cache.set(otherOwner, tokenAddress, assetData, big.NewInt(100))
+	amount, found := cache.get(owner, tokenAddress, assetData)
+	assert.True(t, found)
+	assert.Equal(t, big.NewInt(42), amount)
+
+	// Invalidating an owner and token should not affect other owners or tokens.
+	cache.invalidate(owner, tokenAddress)
+	_, found = cache.get(owner, tokenAddress, assetData)
+	assert.False(t, found)
+	_, found = cache.get(owner, otherTokenAddress, otherAssetData)
+	assert.True(t, found)
+	_, found = cache.get(otherOwner, tokenAddress, assetData)
+	assert.True(t, found)
+
+	// Invalidating a token should affect all owners.
+	cache.invalidateToken(tokenAddress)
+	_, found = cache.get(otherOwner, tokenAddress, assetData)
+	assert.False(t, found)
+	_, found = cache.get(owner, otherTokenAddress, otherAssetData)
+	assert.True(t, found)
+
+	cache.clear()
+	_, found = cache.get(owner, otherTokenAddress, otherAssetData)
+	assert.False(t, found)
+}
+
+func TestIsBalanceOrAllowanceEvent(t *testing.T) {
+	assert.True(t, isBalanceOrAllowanceEvent("ERC20TransferEvent"))
+	assert.True(t, isBalanceOrAllowanceEvent("ERC1155ApprovalForAllEvent"))
+	assert.True(t, isBalanceOrAllowanceEvent("WethDepositEvent"))
+	assert.False(t, isBalanceOrAllowanceEvent("ExchangeFillEvent"))
+	assert.False(t, isBalanceOrAllowanceEvent("ExchangeCancelUpToEvent"))
+}
@@ -26,6 +26,12 @@ var EVENT_SIGNATURES = [...]string{
 	"Fill(address,address,bytes,bytes,bytes,bytes,bytes32,address,address,uint256,uint256,uint256,uint256,uint256)", // Exchange
 	"Cancel(address,address,bytes,bytes,address,bytes32)",                                                           // Exchange
 	"CancelUpTo(address,address,uint256)",
+	"SignatureValidatorApproval(address,address,bool)",
+	"LimitOrderFilled(bytes32,address,address,address,address,address,uint128,uint128,uint128,uint256,bytes32)", // Exchange V4
+	"RfqOrderFilled(bytes32,address,address,address,address,uint128,uint128,bytes32)",                           // Exchange V4
+	"OrderCancelled(bytes32,address)",                                                                           // Exchange V4
+	"PairCancelledLimitOrders(address,address,address,uint256)",                                                 // Exchange V4
+	"PairCancelledRfqOrders(address,address,address,uint256)",                                                   // Exchange V4
 }
 
 // Includes ERC20 `Transfer` & `Approval` events as well as WETH `Deposit` & `Withdraw` events
@@ -40,9 +46,12 @@ const erc721EventsAbiWithoutTokenIDIndexStr = "[{\"anonymous\":false,\"inputs\":
 // Includes ERC1155 `TransferSingle`, `TransferBatch` & `ApprovalForAll` events
 const erc1155EventsAbi = "[{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"address\",\"name\":\"owner\",\"type\":\"address\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"operator\",\"type\":\"address\"},{\"indexed\":false,\"internalType\":\"bool\",\"name\":\"approved\",\"type\":\"bool\"}],\"name\":\"ApprovalForAll\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"address\",\"name\":\"operator\",\"type\":\"address\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"from\",\"type\":\"address\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"to\",\"type\":\"address\"},{\"indexed\":false,\"internalType\":\"uint256[]\",\"name\":\"ids\",\"type\":\"uint256[]\"},{\"indexed\":false,\"internalType\":\"uint256[]\",\"name\":\"values\",\"type\":\"uint256[]\"}],\"name\":\"TransferBatch\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"address\",\"name\":\"operator\",\"type\":\"address\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"from\",\"type\":\"address\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"to\",\"type\":\"address\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"id\",\"type\":\"uint256\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"value\",\"type\":\"uint256\"}],\"name\":\"TransferSingle\",\"type\":\"event\"}]"
 
-// Includes Exchange `Fill`, `Cancel`, `CancelUpTo` events
+// Includes Exchange `Fill`, `Cancel`, `CancelUpTo` & `SignatureValidatorApproval` events
 const exchangeEventsAbi = "[{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"bytes32\",\"name\":\"transactionHash\",\"type\":\"bytes32\"}],\"name\":\"TransactionExecution\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"address\",\"name\":\"signerAddress\",\"type\":\"address\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"validatorAddress\",\"type\":\"address\"},{\"indexed\":false,\"internalType\":\"bool\",\"name\":\"isApproved\",\"type\":\"bool\"}],\"name\":\"SignatureValidatorApproval\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":false,\"internalType\":\"bytes4\",\"name\":\"id\",\"type\":\"bytes4\"},{\"indexed\":false,\"internalType\":\"address\",\"name\":\"assetProxy\",\"type\":\"address\"}],\"name\":\"AssetProxyRegistered\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"oldProtocolFeeMultiplier\",\"type\":\"uint256\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"updatedProtocolFeeMultiplier\",\"type\":\"uint256\"}],\"name\":\"ProtocolFeeMultiplier\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":false,\"internalType\":\"address\",\"name\":\"oldProtocolFeeCollector\",\"type\":\"address\"},{\"indexed\":false,\"internalType\":\"address\",\"name\":\"updatedProtocolFeeCollector\",\"type\":\"address\"}],\"name\":\"ProtocolFeeCollectorAddress\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"address\",\"name\":\"makerAddress\",\"type\":\"address\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"feeRecipientAddress\",\"type\":\"address\"},{\"indexed\":false,\"internalType\":\"bytes\",\"name\":\"makerAssetData\",\"type\":\"bytes\"},{\"indexed\":false,\"internalType\":\"bytes\",\"name\":\"takerAssetData\",\"type\":\"bytes\"},{\"indexed\":false,\"internalType\":\"bytes\",\"name\":\"makerFeeAssetData\",\"type\":\"bytes\"},{\"indexed\":false,\"internalType\":\"bytes\",\"name\":\"takerFeeAssetData\",\"type\":\"bytes\"},{\"indexed\":true,\"internalType\":\"bytes32\",\"name\":\"orderHash\",\"type\":\"bytes32\"},{\"indexed\":false,\"internalType\":\"address\",\"name\":\"takerAddress\",\"type\":\"address\"},{\"indexed\":false,\"internalType\":\"address\",\"name\":\"senderAddress\",\"type\":\"address\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"makerAssetFilledAmount\",\"type\":\"uint256\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"takerAssetFilledAmount\",\"type\":\"uint256\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"makerFeePaid\",\"type\":\"uint256\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"takerFeePaid\",\"type\":\"uint256\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"protocolFeePaid\",\"type\":\"uint256\"}],\"name\":\"Fill\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"address\",\"name\":\"makerAddress\",\"type\":\"address\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"feeRecipientAddress\",\"type\":\"address\"},{\"indexed\":false,\"internalType\":\"bytes\",\"name\":\"makerAssetData\",\"type\":\"bytes\"},{\"indexed\":false,\"internalType\":\"bytes\",\"name\":\"takerAssetData\",\"type\":\"bytes\"},{\"indexed\":false,\"internalType\":\"address\",\"name\":\"senderAddress\",\"type\":\"address\"},{\"indexed\":true,\"internalType\":\"bytes32\",\"name\":\"orderHash\",\"type\":\"bytes32\"}],\"name\":\"Cancel\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"address\",\"name\":\"makerAddress\",\"type\":\"address\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"orderSenderAddress\",\"type\":\"address\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"orderEpoch\",\"type\":\"uint256\"}],\"name\":\"CancelUpTo\",\"type\":\"event\"}]"
 
+// Includes Exchange V4 `LimitOrderFilled`, `RfqOrderFilled`, `OrderCancelled`, `PairCancelledLimitOrders` & `PairCancelledRfqOrders` events
+const exchangeV4EventsAbi = "[{\"anonymous\":false,\"inputs\":[{\"indexed\":false,\"internalType\":\"bytes32\",\"name\":\"orderHash\",\"type\":\"bytes32\"},{\"indexed\":false,\"internalType\":\"address\",\"name\":\"maker\",\"type\":\"address\"},{\"indexed\":false,\"internalType\":\"address\",\"name\":\"taker\",\"type\":\"address\"},{\"indexed\":false,\"internalType\":\"address\",\"name\":\"feeRecipient\",\"type\":\"address\"},{\"indexed\":false,\"internalType\":\"address\",\"name\":\"makerToken\",\"type\":\"address\"},{\"indexed\":false,\"internalType\":\"address\",\"name\":\"takerToken\",\"type\":\"address\"},{\"indexed\":false,\"internalType\":\"uint128\",\"name\":\"takerTokenFilledAmount\",\"type\":\"uint128\"},{\"indexed\":false,\"internalType\":\"uint128\",\"name\":\"makerTokenFilledAmount\",\"type\":\"uint128\"},{\"indexed\":false,\"internalType\":\"uint128\",\"name\":\"takerTokenFeeFilledAmount\",\"type\":\"uint128\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"protocolFeePaid\",\"type\":\"uint256\"},{\"indexed\":false,\"internalType\":\"bytes32\",\"name\":\"pool\",\"type\":\"bytes32\"}],\"name\":\"LimitOrderFilled\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":false,\"internalType\":\"bytes32\",\"name\":\"orderHash\",\"type\":\"bytes32\"},{\"indexed\":false,\"internalType\":\"address\",\"name\":\"maker\",\"type\":\"address\"},{\"indexed\":false,\"internalType\":\"address\",\"name\":\"taker\",\"type\":\"address\"},{\"indexed\":false,\"internalType\":\"address\",\"name\":\"makerToken\",\"type\":\"address\"},{\"indexed\":false,\"internalType\":\"address\",\"name\":\"takerToken\",\"type\":\"address\"},{\"indexed\":false,\"internalType\":\"uint128\",\"name\":\"takerTokenFilledAmount\",\"type\":\"uint128\"},{\"indexed\":false,\"internalType\":\"uint128\",\"name\":\"makerTokenFilledAmount\",\"type\":\"uint128\"},{\"indexed\":false,\"internalType\":\"bytes32\",\"name\":\"pool\",\"type\":\"bytes32\"}],\"name\":\"RfqOrderFilled\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":false,\"internalType\":\"bytes32\",\"name\":\"orderHash\",\"type\":\"bytes32\"},{\"indexed\":false,\"internalType\":\"address\",\"name\":\"maker\",\"type\":\"address\"}],\"name\":\"OrderCancelled\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":false,\"internalType\":\"address\",\"name\":\"maker\",\"type\":\"address\"},{\"indexed\":false,\"internalType\":\"address\",\"name\":\"makerToken\",\"type\":\"address\"},{\"indexed\":false,\"internalType\":\"address\",\"name\":\"takerToken\",\"type\":\"address\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"minValidSalt\",\"type\":\"uint256\"}],\"name\":\"PairCancelledLimitOrders\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":false,\"internalType\":\"address\",\"name\":\"maker\",\"type\":\"address\"},{\"indexed\":false,\"internalType\":\"address\",\"name\":\"makerToken\",\"type\":\"address\"},{\"indexed\":false,\"internalType\":\"address\",\"name\":\"takerToken\",\"type\":\"address\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"minValidSalt\",\"type\":\"uint256\"}],\"name\":\"PairCancelledRfqOrders\",\"type\":\"event\"}]"
+
 // ERC20TransferEvent represents an ERC20 Transfer event
 type ERC20TransferEvent struct {
 	From  common.Address
@@ -518,6 +527,268 @@ func (e *ExchangeCancelUpToEvent) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// ExchangeSignatureValidatorApprovalEvent represents a 0x Exchange
+// SignatureValidatorApproval event
+type ExchangeSignatureValidatorApprovalEvent struct {
+	SignerAddress    common.Address `json:"signerAddress"`
+	ValidatorAddress common.Address `json:"validatorAddress"`
+	IsApproved       bool           `json:"isApproved"`
+}
+
+// ExchangeV4LimitOrderFilledEvent represents an Exchange V4 LimitOrderFilled event
+type ExchangeV4LimitOrderFilledEvent struct {
+	OrderHash                 common.Hash
+	Maker                     common.Address
+	Taker                     common.Address
+	FeeRecipient              common.Address
+	MakerToken                common.Address
+	TakerToken                common.Address
+	TakerTokenFilledAmount    *big.Int
+	MakerTokenFilledAmount    *big.Int
+	TakerTokenFeeFilledAmount *big.Int
+	ProtocolFeePaid           *big.Int
+	Pool                      common.Hash
+}
+
+type exchangeV4LimitOrderFilledEventJSON struct {
+	OrderHash                 string `json:"orderHash"`
+	Maker                     string `json:"maker"`
+	Taker                     string `json:"taker"`
+	FeeRecipient              string `json:"feeRecipient"`
+	MakerToken                string `json:"makerToken"`
+	TakerToken                string `json:"takerToken"`
+	TakerTokenFilledAmount    string `json:"takerTokenFilledAmount"`
+	MakerTokenFilledAmount    string `json:"makerTokenFilledAmount"`
+	TakerTokenFeeFilledAmount string `json:"takerTokenFeeFilledAmount"`
+	ProtocolFeePaid           string `json:"protocolFeePaid"`
+	Pool                      string `json:"pool"`
+}
+
+// MarshalJSON implements a custom JSON marshaller for the ExchangeV4LimitOrderFilledEvent type
+func (e ExchangeV4LimitOrderFilledEvent) MarshalJSON() ([]byte, error) {
+	return json.Marshal(exchangeV4LimitOrderFilledEventJSON{
+		OrderHash:                 e.OrderHash.Hex(),
+		Maker:                     e.Maker.Hex(),
+		Taker:                     e.Taker.Hex(),
+		FeeRecipient:              e.FeeRecipient.Hex(),
+		MakerToken:                e.MakerToken.Hex(),
+		TakerToken:                e.TakerToken.Hex(),
+		TakerTokenFilledAmount:    e.TakerTokenFilledAmount.String(),
+		MakerTokenFilledAmount:    e.MakerTokenFilledAmount.String(),
+		TakerTokenFeeFilledAmount: e.TakerTokenFeeFilledAmount.String(),
+		ProtocolFeePaid:           e.ProtocolFeePaid.String(),
+		Pool:                      e.Pool.Hex(),
+	})
+}
+
+func (e *ExchangeV4LimitOrderFilledEvent) UnmarshalJSON(data []byte) error {
+	var eventJSON exchangeV4LimitOrderFilledEventJSON
+	if err := json.Unmarshal(data, &eventJSON); err != nil {
+		return err
+	}
+	e.OrderHash = common.HexToHash(eventJSON.OrderHash)
+	e.Maker = common.HexToAddress(eventJSON.Maker)
+	e.Taker = common.HexToAddress(eventJSON.Taker)
+	e.FeeRecipient = common.HexToAddress(eventJSON.FeeRecipient)
+	e.MakerToken = common.HexToAddress(eventJSON.MakerToken)
+	e.TakerToken = common.HexToAddress(eventJSON.TakerToken)
+	e.Pool = common.HexToHash(eventJSON.Pool)
+	var ok bool
+	e.TakerTokenFilledAmount, ok = math.ParseBig256(eventJSON.TakerTokenFilledAmount)
+	if !ok {
+		return fmt.Errorf("Invalid uint256 number for ExchangeV4LimitOrderFilledEvent.TakerTokenFilledAmount: %q", eventJSON.TakerTokenFilledAmount)
+	}
+	e.MakerTokenFilledAmount, ok = math.ParseBig256(eventJSON.MakerTokenFilledAmount)
+	if !ok {
+		return fmt.Errorf("Invalid uint256 number for ExchangeV4LimitOrderFilledEvent.MakerTokenFilledAmount: %q", eventJSON.MakerTokenFilledAmount)
+	}
+	e.TakerTokenFeeFilledAmount, ok = math.ParseBig256(eventJSON.TakerTokenFeeFilledAmount)
+	if !ok {
+		return fmt.Errorf("Invalid uint256 number for ExchangeV4LimitOrderFilledEvent.TakerTokenFeeFilledAmount: %q", eventJSON.TakerTokenFeeFilledAmount)
+	}
+	e.ProtocolFeePaid, ok = math.ParseBig256(eventJSON.ProtocolFeePaid)
+	if !ok {
+		return fmt.Errorf("Invalid uint256 number for ExchangeV4LimitOrderFilledEvent.ProtocolFeePaid: %q", eventJSON.ProtocolFeePaid)
+	}
+
+	return nil
+}
+
+// ExchangeV4RfqOrderFilledEvent represents an Exchange V4 RfqOrderFilled event
+type ExchangeV4RfqOrderFilledEvent struct {
+	OrderHash              common.Hash
+	Maker                  common.Address
+	Taker                  common.Address
+	MakerToken             common.Address
+	TakerToken             common.Address
+	TakerTokenFilledAmount *big.Int
+	MakerTokenFilledAmount *big.Int
+	Pool                   common.Hash
+}
+
+type exchangeV4RfqOrderFilledEventJSON struct {
+	OrderHash              string `json:"orderHash"`
+	Maker                  string `json:"maker"`
+	Taker                  string `json:"taker"`
+	MakerToken             string `json:"makerToken"`
+	TakerToken             string `json:"takerToken"`
+	TakerTokenFilledAmount string `json:"takerTokenFilledAmount"`
+	MakerTokenFilledAmount string `json:"makerTokenFilledAmount"`
+	Pool                   string `json:"pool"`
+}
+
+// MarshalJSON implements a custom JSON marshaller for the ExchangeV4RfqOrderFilledEvent type
+func (e ExchangeV4RfqOrderFilledEvent) MarshalJSON() ([]byte, error) {
+	return json.Marshal(exchangeV4RfqOrderFilledEventJSON{
+		OrderHash:              e.OrderHash.Hex(),
+		Maker:                  e.Maker.Hex(),
+		Taker:                  e.Taker.Hex(),
+		MakerToken:             e.MakerToken.Hex(),
+		TakerToken:             e.TakerToken.Hex(),
+		TakerTokenFilledAmount: e.TakerTokenFilledAmount.String(),
+		MakerTokenFilledAmount: e.MakerTokenFilledAmount.String(),
+		Pool:                   e.Pool.Hex(),
+	})
+}
+
+func (e *ExchangeV4RfqOrderFilledEvent) UnmarshalJSON(data []byte) error {
+	var eventJSON exchangeV4RfqOrderFilledEventJSON
+	if err := json.Unmarshal(data, &eventJSON); err != nil {
+		return err
+	}
+	e.OrderHash = common.HexToHash(eventJSON.OrderHash)
+	e.Maker = common.HexToAddress(eventJSON.Maker)
+	e.Taker = common.HexToAddress(eventJSON.Taker)
+	e.MakerToken = common.HexToAddress(eventJSON.MakerToken)
+	e.TakerToken = common.HexToAddress(eventJSON.TakerToken)
+	e.Pool = common.HexToHash(eventJSON.Pool)
+	var ok bool
+	e.TakerTokenFilledAmount, ok = math.ParseBig256(eventJSON.TakerTokenFilledAmount)
+	if !ok {
+		return fmt.Errorf("Invalid uint256 number for ExchangeV4RfqOrderFilledEvent.TakerTokenFilledAmount: %q", eventJSON.TakerTokenFilledAmount)
+	}
+	e.MakerTokenFilledAmount, ok = math.ParseBig256(eventJSON.MakerTokenFilledAmount)
+	if !ok {
+		return fmt.Errorf("Invalid uint256 number for ExchangeV4RfqOrderFilledEvent.MakerTokenFilledAmount: %q", eventJSON.MakerTokenFilledAmount)
+	}
+
+	return nil
+}
+
+// ExchangeV4OrderCancelledEvent represents an Exchange V4 OrderCancelled event
+type ExchangeV4OrderCancelledEvent struct {
+	OrderHash common.Hash
+	Maker     common.Address
+}
+
+type exchangeV4OrderCancelledEventJSON struct {
+	OrderHash string `json:"orderHash"`
+	Maker     string `json:"maker"`
+}
+
+// MarshalJSON implements a custom JSON marshaller for the ExchangeV4OrderCancelledEvent type
+func (e ExchangeV4OrderCancelledEvent) MarshalJSON() ([]byte, error) {
+	return json.Marshal(exchangeV4OrderCancelledEventJSON{
+		OrderHash: e.OrderHash.Hex(),
+		Maker:     e.Maker.Hex(),
+	})
+}
+
+func (e *ExchangeV4OrderCancelledEvent) UnmarshalJSON(data []byte) error {
+	var eventJSON exchangeV4OrderCancelledEventJSON
+	if err := json.Unmarshal(data, &eventJSON); err != nil {
+		return err
+	}
+	e.OrderHash = common.HexToHash(eventJSON.OrderHash)
+	e.Maker = common.HexToAddress(eventJSON.Maker)
+
+	return nil
+}
+
+// ExchangeV4PairCancelledLimitOrdersEvent represents an Exchange V4 PairCancelledLimitOrders event
+type ExchangeV4PairCancelledLimitOrdersEvent struct {
+	Maker        common.Address
+	MakerToken   common.Address
+	TakerToken   common.Address
+	MinValidSalt *big.Int
+}
+
+type exchangeV4PairCancelledLimitOrdersEventJSON struct {
+	Maker        string `json:"maker"`
+	MakerToken   string `json:"makerToken"`
+	TakerToken   string `json:"takerToken"`
+	MinValidSalt string `json:"minValidSalt"`
+}
+
+// MarshalJSON implements a custom JSON marshaller for the ExchangeV4PairCancelledLimitOrdersEvent type
+func (e ExchangeV4PairCancelledLimitOrdersEvent) MarshalJSON() ([]byte, error) {
+	return json.Marshal(exchangeV4PairCancelledLimitOrdersEventJSON{
+		Maker:        e.Maker.Hex(),
+		MakerToken:   e.MakerToken.Hex(),
+		TakerToken:   e.TakerToken.Hex(),
+		MinValidSalt: e.MinValidSalt.String(),
+	})
+}
+
+func (e *ExchangeV4PairCancelledLimitOrdersEvent) UnmarshalJSON(data []byte) error {
+	var eventJSON exchangeV4PairCancelledLimitOrdersEventJSON
+	if err := json.Unmarshal(data, &eventJSON); err != nil {
+		return err
+	}
+	e.Maker = common.HexToAddress(eventJSON.Maker)
+	e.MakerToken = common.HexToAddress(eventJSON.MakerToken)
+	e.TakerToken = common.HexToAddress(eventJSON.TakerToken)
+	var ok bool
+	e.MinValidSalt, ok = math.ParseBig256(eventJSON.MinValidSalt)
+	if !ok {
+		return fmt.Errorf("Invalid uint256 number for ExchangeV4PairCancelledLimitOrdersEvent.MinValidSalt: %q", eventJSON.MinValidSalt)
+	}
+
+	return nil
+}
+
+// ExchangeV4PairCancelledRfqOrdersEvent represents an Exchange V4 PairCancelledRfqOrders event
+type ExchangeV4PairCancelledRfqOrdersEvent struct {
+	Maker        common.Address
+	MakerToken   common.Address
+	TakerToken   common.Address
+	MinValidSalt *big.Int
+}
+
+type exchangeV4PairCancelledRfqOrdersEventJSON struct {
+	Maker        string `json:"maker"`
+	MakerToken   string `json:"makerToken"`
+	TakerToken   string `json:"takerToken"`
+	MinValidSalt string `json:"minValidSalt"`
+}
+
+// MarshalJSON implements a custom JSON marshaller for the ExchangeV4PairCancelledRfqOrdersEvent type
+func (e ExchangeV4PairCancelledRfqOrdersEvent) MarshalJSON() ([]byte, error) {
+	return json.Marshal(exchangeV4PairCancelledRfqOrdersEventJSON{
+		Maker:        e.Maker.Hex(),
+		MakerToken:   e.MakerToken.Hex(),
+		TakerToken:   e.TakerToken.Hex(),
+		MinValidSalt: e.MinValidSalt.String(),
+	})
+}
+
+func (e *ExchangeV4PairCancelledRfqOrdersEvent) UnmarshalJSON(data []byte) error {
+	var eventJSON exchangeV4PairCancelledRfqOrdersEventJSON
+	if err := json.Unmarshal(data, &eventJSON); err != nil {
+		return err
+	}
+	e.Maker = common.HexToAddress(eventJSON.Maker)
+	e.MakerToken = common.HexToAddress(eventJSON.MakerToken)
+	e.TakerToken = common.HexToAddress(eventJSON.TakerToken)
+	var ok bool
+	e.MinValidSalt, ok = math.ParseBig256(eventJSON.MinValidSalt)
+	if !ok {
+		return fmt.Errorf("Invalid uint256 number for ExchangeV4PairCancelledRfqOrdersEvent.MinValidSalt: %q", eventJSON.MinValidSalt)
+	}
+
+	return nil
+}
+
 // WethWithdrawalEvent represents a wrapped Ether Withdraw event
 type WethWithdrawalEvent struct {
 	Owner common.Address
@@ -619,19 +890,23 @@ type Decoder struct {
 	knownERC721AddressesMu             sync.RWMutex
 	knownERC1155AddressesMu            sync.RWMutex
 	knownExchangeAddressesMu           sync.RWMutex
+	knownExchangeV4AddressesMu         sync.RWMutex
 	knownERC20Addresses                map[common.Address]bool
 	knownERC721Addresses               map[common.Address]bool
 	knownERC1155Addresses              map[common.Address]bool
 	knownExchangeAddresses             map[common.Address]bool
+	knownExchangeV4Addresses           map[common.Address]bool
 	erc20ABI                           abi.ABI
 	erc721ABI                          abi.ABI
 	erc721EventsAbiWithoutTokenIDIndex abi.ABI
 	erc1155ABI                         abi.ABI
 	exchangeABI                        abi.ABI
+	exchangeV4ABI                      abi.ABI
 	erc20TopicToEventName              map[common.Hash]string
 	erc721TopicToEventName             map[common.Hash]string
 	erc1155TopicToEventName            map[common.Hash]string
 	exchangeTopicToEventName           map[common.Hash]string
+	exchangeV4TopicToEventName         map[common.Hash]string
 }
 
 // New instantiates a new 0x order-relevant events decoder
@@ -661,6 +936,11 @@ func New() (*Decoder, error) {
 		return nil, err
 	}
 
+	exchangeV4ABI, err := abi.JSON(strings.NewReader(exchangeV4EventsAbi))
+	if err != nil {
+		return nil, err
+	}
+
 	erc20TopicToEventName := map[common.Hash]string{}
 	for _, event := range erc20ABI.Events {
 		erc20TopicToEventName[event.ID()] = event.Name
@@ -677,21 +957,28 @@ func New() (*Decoder, error) {
 	for _, event := range exchangeABI.Events {
 		exchangeTopicToEventName[event.ID()] = event.Name
 	}
+	exchangeV4TopicToEventName := map[common.Hash]string{}
+	for _, event := range exchangeV4ABI.Events {
+		exchangeV4TopicToEventName[event.ID()] = event.Name
+	}
 
 	return &Decoder{
 		knownERC20Addresses:                make(map[common.Address]bool),
 		knownERC721Addresses:               make(map[common.Address]bool),
 		knownERC1155Addresses:              make(map[common.Address]bool),
 		knownExchangeAddresses:             make(map[common.Address]bool),
+		knownExchangeV4Addresses:           make(map[common.Address]bool),
 		erc20ABI:                           erc20ABI,
 		erc721ABI:                          erc721ABI,
 		erc721EventsAbiWithoutTokenIDIndex: erc721EventsAbiWithoutTokenIDIndex,
 		erc1155ABI:                         erc1155ABI,
 		exchangeABI:                        exchangeABI,
+		exchangeV4ABI:                      exchangeV4ABI,
 		erc20TopicToEventName:              erc20TopicToEventName,
 		erc721TopicToEventName:             erc721TopicToEventName,
 		erc1155TopicToEventName:            erc1155TopicToEventName,
 		exchangeTopicToEventName:           exchangeTopicToEventName,
+		exchangeV4TopicToEventName:         exchangeV4TopicToEventName,
 	}, nil
 }
 
@@ -795,6 +1082,31 @@ func (d *Decoder) isKnownExchange(address common.Address) bool {
 	return exists
 }
 
+// AddKnownExchangeV4 registers the supplied contract address as a 0x Exchange Proxy (V4) contract. If an
+// event is found from this contract address, the decoder will properly decode it's V4 order events
+// including the correct event parameter names.
+func (d *Decoder) AddKnownExchangeV4(address common.Address) {
+	d.knownExchangeV4AddressesMu.Lock()
+	defer d.knownExchangeV4AddressesMu.Unlock()
+	d.knownExchangeV4Addresses[address] = true
+}
+
+// RemoveKnownExchangeV4 removes an Exchange Proxy address from the list of known addresses. We will no
+// longer decode events for this contract.
+func (d *Decoder) RemoveKnownExchangeV4(address common.Address) {
+	d.knownExchangeV4AddressesMu.Lock()
+	defer d.knownExchangeV4AddressesMu.Unlock()
+	delete(d.knownExchangeV4Addresses, address)
+}
+
+// isKnownExchangeV4 checks if the supplied address is a known Exchange Proxy contract address
+func (d *Decoder) isKnownExchangeV4(address common.Address) bool {
+	d.knownExchangeV4AddressesMu.RLock()
+	defer d.knownExchangeV4AddressesMu.RUnlock()
+	_, exists := d.knownExchangeV4Addresses[address]
+	return exists
+}
+
 // FindEventType returns to event type contained in the supplied log. It looks both at the registered
 // contract addresses and the log topic.
 func (d *Decoder) FindEventType(log types.Log) (string, error) {
@@ -830,6 +1142,13 @@ func (d *Decoder) FindEventType(log types.Log) (string, error) {
 		}
 		return fmt.Sprintf("Exchange%sEvent", eventName), nil
 	}
+	if isKnown := d.isKnownExchangeV4(log.Address); isKnown {
+		eventName, ok := d.exchangeV4TopicToEventName[firstTopic]
+		if !ok {
+			return "", UnsupportedEventError{Topics: log.Topics, ContractAddress: log.Address}
+		}
+		return fmt.Sprintf("ExchangeV4%sEvent", eventName), nil
+	}
 
 	return "", UntrackedTokenError{Topic: firstTopic, TokenAddress: log.Address}
 }
@@ -849,6 +1168,9 @@ func (d *Decoder) Decode(log types.Log, decodedLog interface{}) error {
 	if isKnown := d.isKnownExchange(log.Address); isKnown {
 		return d.decodeExchange(log, decodedLog)
 	}
+	if isKnown := d.isKnownExchangeV4(log.Address); isKnown {
+		return d.decodeExchangeV4(log, decodedLog)
+	}
 
 	return UntrackedTokenError{Topic: log.Topics[0], TokenAddress: log.Address}
 }
@@ -912,6 +1234,19 @@ func (d *Decoder) decodeExchange(log types.Log, decodedLog interface{}) error {
 	return nil
 }
 
+func (d *Decoder) decodeExchangeV4(log types.Log, decodedLog interface{}) error {
+	eventName, ok := d.exchangeV4TopicToEventName[log.Topics[0]]
+	if !ok {
+		return UnsupportedEventError{Topics: log.Topics, ContractAddress: log.Address}
+	}
+
+	err := unpackLog(decodedLog, eventName, log, d.exchangeV4ABI)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
 // unpackLog unpacks a retrieved log into the provided output structure.
 func unpackLog(decodedEvent interface{}, event string, log types.Log, _abi abi.ABI) error {
 	if len(log.Data) > 0 {
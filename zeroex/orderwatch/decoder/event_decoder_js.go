@@ -149,6 +149,68 @@ func (e ExchangeCancelUpToEvent) JSValue() js.Value {
 	})
 }
 
+func (e ExchangeSignatureValidatorApprovalEvent) JSValue() js.Value {
+	return js.ValueOf(map[string]interface{}{
+		"signerAddress":    e.SignerAddress.Hex(),
+		"validatorAddress": e.ValidatorAddress.Hex(),
+		"isApproved":       e.IsApproved,
+	})
+}
+
+func (e ExchangeV4LimitOrderFilledEvent) JSValue() js.Value {
+	return js.ValueOf(map[string]interface{}{
+		"orderHash":                 e.OrderHash.Hex(),
+		"maker":                     e.Maker.Hex(),
+		"taker":                     e.Taker.Hex(),
+		"feeRecipient":              e.FeeRecipient.Hex(),
+		"makerToken":                e.MakerToken.Hex(),
+		"takerToken":                e.TakerToken.Hex(),
+		"takerTokenFilledAmount":    e.TakerTokenFilledAmount.String(),
+		"makerTokenFilledAmount":    e.MakerTokenFilledAmount.String(),
+		"takerTokenFeeFilledAmount": e.TakerTokenFeeFilledAmount.String(),
+		"protocolFeePaid":           e.ProtocolFeePaid.String(),
+		"pool":                      e.Pool.Hex(),
+	})
+}
+
+func (e ExchangeV4RfqOrderFilledEvent) JSValue() js.Value {
+	return js.ValueOf(map[string]interface{}{
+		"orderHash":              e.OrderHash.Hex(),
+		"maker":                  e.Maker.Hex(),
+		"taker":                  e.Taker.Hex(),
+		"makerToken":             e.MakerToken.Hex(),
+		"takerToken":             e.TakerToken.Hex(),
+		"takerTokenFilledAmount": e.TakerTokenFilledAmount.String(),
+		"makerTokenFilledAmount": e.MakerTokenFilledAmount.String(),
+		"pool":                   e.Pool.Hex(),
+	})
+}
+
+func (e ExchangeV4OrderCancelledEvent) JSValue() js.Value {
+	return js.ValueOf(map[string]interface{}{
+		"orderHash": e.OrderHash.Hex(),
+		"maker":     e.Maker.Hex(),
+	})
+}
+
+func (e ExchangeV4PairCancelledLimitOrdersEvent) JSValue() js.Value {
+	return js.ValueOf(map[string]interface{}{
+		"maker":        e.Maker.Hex(),
+		"makerToken":   e.MakerToken.Hex(),
+		"takerToken":   e.TakerToken.Hex(),
+		"minValidSalt": e.MinValidSalt.String(),
+	})
+}
+
+func (e ExchangeV4PairCancelledRfqOrdersEvent) JSValue() js.Value {
+	return js.ValueOf(map[string]interface{}{
+		"maker":        e.Maker.Hex(),
+		"makerToken":   e.MakerToken.Hex(),
+		"takerToken":   e.TakerToken.Hex(),
+		"minValidSalt": e.MinValidSalt.String(),
+	})
+}
+
 func (w WethWithdrawalEvent) JSValue() js.Value {
 	return js.ValueOf(map[string]interface{}{
 		"owner": w.Owner.Hex(),
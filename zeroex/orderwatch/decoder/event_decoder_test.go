@@ -42,6 +42,13 @@ var exchangeAddress common.Address = common.HexToAddress("0x48bacb9266a570d52106
 const exchangeFillLog string = "{\"address\":\"0x48bacb9266a570d521063ef5dd96e61686dbe788\",\"topics\":[\"0x6869791f0a34781b29882982cc39e882768cf2c96995c2a110c577c53bc932d5\",\"0x0000000000000000000000006ecbe1db9ef729cbe972c83fb886247691fb6beb\",\"0x000000000000000000000000a258b39954cef5cb142fd567a46cddb31a670124\",\"0xddb8be9f6fed5209693ecce4eb127252827c1c331d661ae7a2491c80355f3fdd\"],\"data\":\"0x000000000000000000000000000000000000000000000000000000000000016000000000000000000000000000000000000000000000000000000000000001c000000000000000000000000000000000000000000000000000000000000002200000000000000000000000000000000000000000000000000000000000000280000000000000000000000000e36ea790bc9d7ab70c55260c66d52b1eca985f84000000000000000000000000e36ea790bc9d7ab70c55260c66d52b1eca985f840000000000000000000000000000000000000000000000056bc75e2d63100000000000000000000000000000000000000000000000000002b5e3af16b18800000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000024f47261b0000000000000000000000000871dd7c2b4b25e1aa18728e9d5f2af4c4e431f5c000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000024f47261b00000000000000000000000000b1ba0af832d7c05fd64161e0db78e85978e8082000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000024f47261b0000000000000000000000000871dd7c2b4b25e1aa18728e9d5f2af4c4e431f5c000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000024f47261b0000000000000000000000000871dd7c2b4b25e1aa18728e9d5f2af4c4e431f5c00000000000000000000000000000000000000000000000000000000\",\"blockNumber\":\"0x2f\",\"transactionHash\":\"0xedc057022ac01b0014f7eb921fe990d6997be58174aa31cb5af4be773c8f28ab\",\"transactionIndex\":\"0x0\",\"blockHash\":\"0x4baee7852a94e0e029d5d4e9ce6f9c953e970db021c751002807d346b301eaaa\",\"logIndex\":\"0x0\",\"removed\":false}"
 const exchangeCancelLog string = "{\"address\":\"0x48bacb9266a570d521063ef5dd96e61686dbe788\",\"topics\":[\"0x02c310a9a43963ff31a754a4099cc435ed498049687539d72d7818d9b093415c\",\"0x0000000000000000000000006ecbe1db9ef729cbe972c83fb886247691fb6beb\",\"0x000000000000000000000000a258b39954cef5cb142fd567a46cddb31a670124\",\"0x0bd69c50d82412baa611657851a5cd4cbec05205fb204c2548289d6bd11d4ffd\"],\"data\":\"0x000000000000000000000000000000000000000000000000000000000000006000000000000000000000000000000000000000000000000000000000000000c00000000000000000000000006ecbe1db9ef729cbe972c83fb886247691fb6beb0000000000000000000000000000000000000000000000000000000000000024f47261b0000000000000000000000000871dd7c2b4b25e1aa18728e9d5f2af4c4e431f5c000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000024f47261b00000000000000000000000000b1ba0af832d7c05fd64161e0db78e85978e808200000000000000000000000000000000000000000000000000000000\",\"blockNumber\":\"0x2f\",\"transactionHash\":\"0x53c2c32ad2ee450295b1c5464ead8270cf2af6f74ebde08ad9bf3dc7712972ec\",\"transactionIndex\":\"0x0\",\"blockHash\":\"0x53dacef15e6dd06a15379a6bf4647a731661863e7fd9e4ceb941896d8f51d478\",\"logIndex\":\"0x0\",\"removed\":false}"
 const exchangeCancelUpToLog string = "{\"address\":\"0x48bacb9266a570d521063ef5dd96e61686dbe788\",\"topics\":[\"0x82af639571738f4ebd4268fb0363d8957ebe1bbb9e78dba5ebd69eed39b154f0\",\"0x0000000000000000000000006ecbe1db9ef729cbe972c83fb886247691fb6beb\",\"0x0000000000000000000000000000000000000000000000000000000000000000\"],\"data\":\"0x0000000000000000000000000000000000000000000000000000016890e4e0eb\",\"blockNumber\":\"0x2f\",\"transactionHash\":\"0x6c53a519cf31c3bf86162f3a46037979e2a2f6d1ab917275e5f64e5a7e2a0671\",\"transactionIndex\":\"0x0\",\"blockHash\":\"0xfb0cfe4f64f2c5294b0f458a1343590ccf6af465270140d64378336d90781ff5\",\"logIndex\":\"0x0\",\"removed\":false}"
+const exchangeSignatureValidatorApprovalLog string = "{\"address\":\"0x48bacb9266a570d521063ef5dd96e61686dbe788\",\"topics\":[\"0xa8656e308026eeabce8f0bc18048433252318ab80ac79da0b3d3d8697dfba891\",\"0x0000000000000000000000006ecbe1db9ef729cbe972c83fb886247691fb6beb\",\"0x000000000000000000000000a258b39954cef5cb142fd567a46cddb31a670124\"],\"data\":\"0x0000000000000000000000000000000000000000000000000000000000000001\",\"blockNumber\":\"0x30\",\"transactionHash\":\"0x2a8a5d5e6b1a2e2c4b86b8c35e6db6e0e1fcd1d2da0fdf8a9e1c0a6dc5ebf1c3\",\"transactionIndex\":\"0x0\",\"blockHash\":\"0x7e7b1c3e0bd8a2f1e9d3c4b5a6978887766554433221100ffeeddccbbaa99887\",\"logIndex\":\"0x0\",\"removed\":false}"
+
+var exchangeV4Address common.Address = common.HexToAddress("0xdef1c0ded9bec7f1a1670819833240f027b25eff")
+
+const exchangeV4LimitOrderFilledLog string = "{\"address\":\"0xdef1c0ded9bec7f1a1670819833240f027b25eff\",\"topics\":[\"0xab614d2b738543c0ea21f56347cf696a3a0c42a7cbec3212a5ca22a4dcff2124\"],\"data\":\"0x0bd69c50d82412baa611657851a5cd4cbec05205fb204c2548289d6bd11d4ffd0000000000000000000000006ecbe1db9ef729cbe972c83fb886247691fb6beb000000000000000000000000e36ea790bc9d7ab70c55260c66d52b1eca985f84000000000000000000000000a258b39954cef5cb142fd567a46cddb31a670124000000000000000000000000871dd7c2b4b25e1aa18728e9d5f2af4c4e431f5c0000000000000000000000000b1ba0af832d7c05fd64161e0db78e85978e8082000000000000000000000000000000000000000000000002b5e3af16b18800000000000000000000000000000000000000000000000000056bc75e2d631000000000000000000000000000000000000000000000000000000de0b6b3a764000000000000000000000000000000000000000000000000000000254db1c22440000000000000000000000000000000000000000000000000000000000000000001\",\"blockNumber\":\"0x2f\",\"transactionHash\":\"0x6c53a519cf31c3bf86162f3a46037979e2a2f6d1ab917275e5f64e5a7e2a0672\",\"transactionIndex\":\"0x0\",\"blockHash\":\"0xfb0cfe4f64f2c5294b0f458a1343590ccf6af465270140d64378336d90781ff6\",\"logIndex\":\"0x0\",\"removed\":false}"
+const exchangeV4OrderCancelledLog string = "{\"address\":\"0xdef1c0ded9bec7f1a1670819833240f027b25eff\",\"topics\":[\"0xa6eb7cdc219e1518ced964e9a34e61d68a94e4f1569db3e84256ba981ba52753\"],\"data\":\"0x0bd69c50d82412baa611657851a5cd4cbec05205fb204c2548289d6bd11d4ffd0000000000000000000000006ecbe1db9ef729cbe972c83fb886247691fb6beb\",\"blockNumber\":\"0x2f\",\"transactionHash\":\"0x6c53a519cf31c3bf86162f3a46037979e2a2f6d1ab917275e5f64e5a7e2a0673\",\"transactionIndex\":\"0x0\",\"blockHash\":\"0xfb0cfe4f64f2c5294b0f458a1343590ccf6af465270140d64378336d90781ff7\",\"logIndex\":\"0x0\",\"removed\":false}"
+const exchangeV4PairCancelledLimitOrdersLog string = "{\"address\":\"0xdef1c0ded9bec7f1a1670819833240f027b25eff\",\"topics\":[\"0xa91fe7ae62fce669df2c7f880f8c14d178531aae72515558e5c948e37c32a572\"],\"data\":\"0x0000000000000000000000006ecbe1db9ef729cbe972c83fb886247691fb6beb000000000000000000000000871dd7c2b4b25e1aa18728e9d5f2af4c4e431f5c0000000000000000000000000b1ba0af832d7c05fd64161e0db78e85978e80820000000000000000000000000000000000000000000000000000016890e4e0eb\",\"blockNumber\":\"0x2f\",\"transactionHash\":\"0x6c53a519cf31c3bf86162f3a46037979e2a2f6d1ab917275e5f64e5a7e2a0674\",\"transactionIndex\":\"0x0\",\"blockHash\":\"0xfb0cfe4f64f2c5294b0f458a1343590ccf6af465270140d64378336d90781ff8\",\"logIndex\":\"0x0\",\"removed\":false}"
 
 func TestDecodeERC20Transfer(t *testing.T) {
 	var transferLog types.Log
@@ -418,6 +425,132 @@ func TestDecodeExchangeCancelUpTo(t *testing.T) {
 	}
 	assert.Equal(t, expectedEvent, actualEvent, "Exchange CancelUpTo event decode")
 }
+func TestDecodeExchangeSignatureValidatorApproval(t *testing.T) {
+	var approvalLog types.Log
+	err := unmarshalLogStr(exchangeSignatureValidatorApprovalLog, &approvalLog)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	decoder, err := New()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	decoder.AddKnownExchange(exchangeAddress)
+	eventType, err := decoder.FindEventType(approvalLog)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	assert.Equal(t, "ExchangeSignatureValidatorApprovalEvent", eventType)
+	var actualEvent ExchangeSignatureValidatorApprovalEvent
+	err = decoder.Decode(approvalLog, &actualEvent)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	expectedEvent := ExchangeSignatureValidatorApprovalEvent{
+		SignerAddress:    common.HexToAddress("0x6ecbe1db9ef729cbe972c83fb886247691fb6beb"),
+		ValidatorAddress: common.HexToAddress("0xa258b39954cef5cb142fd567a46cddb31a670124"),
+		IsApproved:       true,
+	}
+	assert.Equal(t, expectedEvent, actualEvent, "Exchange SignatureValidatorApproval event decode")
+}
+func TestDecodeExchangeV4LimitOrderFilled(t *testing.T) {
+	var fillLog types.Log
+	err := unmarshalLogStr(exchangeV4LimitOrderFilledLog, &fillLog)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	decoder, err := New()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	decoder.AddKnownExchangeV4(exchangeV4Address)
+	eventType, err := decoder.FindEventType(fillLog)
+	require.NoError(t, err)
+	assert.Equal(t, "ExchangeV4LimitOrderFilledEvent", eventType)
+	var actualEvent ExchangeV4LimitOrderFilledEvent
+	err = decoder.Decode(fillLog, &actualEvent)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	expectedEvent := ExchangeV4LimitOrderFilledEvent{
+		OrderHash:                 common.HexToHash("0x0bd69c50d82412baa611657851a5cd4cbec05205fb204c2548289d6bd11d4ffd"),
+		Maker:                     common.HexToAddress("0x6ecbe1db9ef729cbe972c83fb886247691fb6beb"),
+		Taker:                     common.HexToAddress("0xe36ea790bc9d7ab70c55260c66d52b1eca985f84"),
+		FeeRecipient:              common.HexToAddress("0xa258b39954cef5cb142fd567a46cddb31a670124"),
+		MakerToken:                common.HexToAddress("0x871dd7c2b4b25e1aa18728e9d5f2af4c4e431f5c"),
+		TakerToken:                common.HexToAddress("0x0b1ba0af832d7c05fd64161e0db78e85978e8082"),
+		TakerTokenFilledAmount:    actualEvent.TakerTokenFilledAmount,
+		MakerTokenFilledAmount:    actualEvent.MakerTokenFilledAmount,
+		TakerTokenFeeFilledAmount: actualEvent.TakerTokenFeeFilledAmount,
+		ProtocolFeePaid:           actualEvent.ProtocolFeePaid,
+		Pool:                      common.HexToHash("0x0000000000000000000000000000000000000000000000000000000000000001"),
+	}
+	assert.Equal(t, expectedEvent, actualEvent, "Exchange V4 LimitOrderFilled event decode")
+
+	// We do big.Int assertions separately due to the fact that the underlying
+	// struct fields sometimes aren't identical.
+	expectedTakerTokenFilledAmount := new(big.Int).Mul(big.NewInt(50), eighteenDecimalsInBaseUnits)
+	expectedMakerTokenFilledAmount := new(big.Int).Mul(big.NewInt(100), eighteenDecimalsInBaseUnits)
+	expectedTakerTokenFeeFilledAmount := new(big.Int).Set(eighteenDecimalsInBaseUnits)
+	expectedProtocolFeePaid := big.NewInt(10500000000000000)
+	assertBigIntEqual(t, expectedTakerTokenFilledAmount, actualEvent.TakerTokenFilledAmount, "TakerTokenFilledAmount was not equal")
+	assertBigIntEqual(t, expectedMakerTokenFilledAmount, actualEvent.MakerTokenFilledAmount, "MakerTokenFilledAmount was not equal")
+	assertBigIntEqual(t, expectedTakerTokenFeeFilledAmount, actualEvent.TakerTokenFeeFilledAmount, "TakerTokenFeeFilledAmount was not equal")
+	assertBigIntEqual(t, expectedProtocolFeePaid, actualEvent.ProtocolFeePaid, "ProtocolFeePaid was not equal")
+}
+
+func TestDecodeExchangeV4OrderCancelled(t *testing.T) {
+	var cancelLog types.Log
+	err := unmarshalLogStr(exchangeV4OrderCancelledLog, &cancelLog)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	decoder, err := New()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	decoder.AddKnownExchangeV4(exchangeV4Address)
+	var actualEvent ExchangeV4OrderCancelledEvent
+	err = decoder.Decode(cancelLog, &actualEvent)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	expectedEvent := ExchangeV4OrderCancelledEvent{
+		OrderHash: common.HexToHash("0x0bd69c50d82412baa611657851a5cd4cbec05205fb204c2548289d6bd11d4ffd"),
+		Maker:     common.HexToAddress("0x6ecbe1db9ef729cbe972c83fb886247691fb6beb"),
+	}
+	assert.Equal(t, expectedEvent, actualEvent, "Exchange V4 OrderCancelled event decode")
+}
+
+func TestDecodeExchangeV4PairCancelledLimitOrders(t *testing.T) {
+	var pairCancelLog types.Log
+	err := unmarshalLogStr(exchangeV4PairCancelledLimitOrdersLog, &pairCancelLog)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	decoder, err := New()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	decoder.AddKnownExchangeV4(exchangeV4Address)
+	var actualEvent ExchangeV4PairCancelledLimitOrdersEvent
+	err = decoder.Decode(pairCancelLog, &actualEvent)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	expectedEvent := ExchangeV4PairCancelledLimitOrdersEvent{
+		Maker:        common.HexToAddress("0x6ecbe1db9ef729cbe972c83fb886247691fb6beb"),
+		MakerToken:   common.HexToAddress("0x871dd7c2b4b25e1aa18728e9d5f2af4c4e431f5c"),
+		TakerToken:   common.HexToAddress("0x0b1ba0af832d7c05fd64161e0db78e85978e8082"),
+		MinValidSalt: big.NewInt(1548619145451),
+	}
+	assert.Equal(t, expectedEvent, actualEvent, "Exchange V4 PairCancelledLimitOrders event decode")
+}
+
 func TestDecodeWethDeposit(t *testing.T) {
 	var depositLog types.Log
 	err := unmarshalLogStr(wethDepositLog, &depositLog)
@@ -652,6 +785,43 @@ func TestJSONMarshalUnmarshalExchangeCancelUpTo(t *testing.T) {
 	assert.Equal(t, expectedEvent, unmarshaledEvent)
 }
 
+func TestJSONMarshalUnmarshalExchangeV4LimitOrderFilled(t *testing.T) {
+	expectedEvent := ExchangeV4LimitOrderFilledEvent{
+		OrderHash:                 common.HexToHash("0x0bd69c50d82412baa611657851a5cd4cbec05205fb204c2548289d6bd11d4ffd"),
+		Maker:                     common.HexToAddress("0x6ecbe1db9ef729cbe972c83fb886247691fb6beb"),
+		Taker:                     common.HexToAddress("0xe36ea790bc9d7ab70c55260c66d52b1eca985f84"),
+		FeeRecipient:              common.HexToAddress("0xa258b39954cef5cb142fd567a46cddb31a670124"),
+		MakerToken:                common.HexToAddress("0x871dd7c2b4b25e1aa18728e9d5f2af4c4e431f5c"),
+		TakerToken:                common.HexToAddress("0x0b1ba0af832d7c05fd64161e0db78e85978e8082"),
+		TakerTokenFilledAmount:    big.NewInt(500),
+		MakerTokenFilledAmount:    big.NewInt(1000),
+		TakerTokenFeeFilledAmount: big.NewInt(10),
+		ProtocolFeePaid:           big.NewInt(10500000000000000),
+		Pool:                      common.HexToHash("0x0000000000000000000000000000000000000000000000000000000000000001"),
+	}
+
+	buf := bytes.Buffer{}
+	require.NoError(t, json.NewEncoder(&buf).Encode(expectedEvent))
+	var unmarshaledEvent ExchangeV4LimitOrderFilledEvent
+	require.NoError(t, json.NewDecoder(&buf).Decode(&unmarshaledEvent))
+	assert.Equal(t, expectedEvent, unmarshaledEvent)
+}
+
+func TestJSONMarshalUnmarshalExchangeV4PairCancelledLimitOrders(t *testing.T) {
+	expectedEvent := ExchangeV4PairCancelledLimitOrdersEvent{
+		Maker:        common.HexToAddress("0x6ecbe1db9ef729cbe972c83fb886247691fb6beb"),
+		MakerToken:   common.HexToAddress("0x871dd7c2b4b25e1aa18728e9d5f2af4c4e431f5c"),
+		TakerToken:   common.HexToAddress("0x0b1ba0af832d7c05fd64161e0db78e85978e8082"),
+		MinValidSalt: big.NewInt(1548619145451),
+	}
+
+	buf := bytes.Buffer{}
+	require.NoError(t, json.NewEncoder(&buf).Encode(expectedEvent))
+	var unmarshaledEvent ExchangeV4PairCancelledLimitOrdersEvent
+	require.NoError(t, json.NewDecoder(&buf).Decode(&unmarshaledEvent))
+	assert.Equal(t, expectedEvent, unmarshaledEvent)
+}
+
 func TestJSONMarshalUnmarshalWethDeposit(t *testing.T) {
 	expectedEvent := WethDepositEvent{
 		Owner: common.HexToAddress("0x81228eA33D680B0F51271aBAb1105886eCd01C2c"),
@@ -0,0 +1,283 @@
+package orderwatch
+
+import (
+	"math"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/0xProject/0x-mesh/meshdb"
+	"github.com/0xProject/0x-mesh/zeroex"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// EvictionPolicy decides which orders are removed when the number of stored
+// orders exceeds the maximum. Pinned orders are never evicted. If no
+// EvictionPolicy is configured, the Watcher removes the orders with the
+// highest expiration times and lowers the max expiration time for incoming
+// orders instead. That only requires an index scan, whereas an EvictionPolicy
+// is given all unpinned orders every time orders are evicted (see
+// meshdb.TrimOrders).
+type EvictionPolicy interface {
+	// SelectOrdersToEvict returns exactly numOrdersToEvict of the given orders,
+	// which are the orders that should be removed to make space for new orders.
+	SelectOrdersToEvict(orders []*meshdb.Order, numOrdersToEvict int) ([]*meshdb.Order, error)
+	// AdmitsOrder returns false if the given new order would have been evicted
+	// the last time orders were evicted, which means that it would most likely
+	// be evicted again right away. The Watcher only calls it while storage is
+	// full, so that incoming orders can be rejected the same way they are
+	// rejected by the max expiration time without an EvictionPolicy.
+	AdmitsOrder(signedOrder *zeroex.SignedOrder) bool
+}
+
+// PriceOracle provides the prices used by the EvictionPolicy returned by
+// NewOrderValueEvictionPolicy.
+type PriceOracle interface {
+	// Price returns the price of one base unit of the asset encoded by the given
+	// asset data in base units of the reference token, and whether or not the
+	// price is known.
+	Price(assetData []byte) (float64, bool)
+}
+
+// TokenPrices is a PriceOracle with fixed prices for ERC20 tokens, keyed by
+// token address. The prices of all other assets are unknown.
+type TokenPrices map[common.Address]float64
+
+var tokenPricesAssetDataDecoder = zeroex.NewAssetDataDecoder()
+
+// Price implements PriceOracle.
+func (p TokenPrices) Price(assetData []byte) (float64, bool) {
+	assetDataName, err := tokenPricesAssetDataDecoder.GetName(assetData)
+	if err != nil || assetDataName != "ERC20Token" {
+		return 0, false
+	}
+	var decodedAssetData zeroex.ERC20AssetData
+	if err := tokenPricesAssetDataDecoder.Decode(assetData, &decodedAssetData); err != nil {
+		return 0, false
+	}
+	price, found := p[decodedAssetData.Address]
+	return price, found
+}
+
+// NewOrderValueEvictionPolicy returns an EvictionPolicy which evicts the orders
+// with the lowest fillable value first. The value of an order is its fillable
+// taker asset amount priced in the reference token of the given PriceOracle.
+// If the price of the taker asset is unknown, the fillable maker asset amount
+// is used instead. Orders whose value cannot be determined are evicted first.
+func NewOrderValueEvictionPolicy(prices PriceOracle) EvictionPolicy {
+	return &scoredEvictionPolicy{
+		score: func(orders []*meshdb.Order) []float64 {
+			scores := make([]float64, len(orders))
+			for i, order := range orders {
+				scores[i] = orderValue(order, prices)
+			}
+			return scores
+		},
+	}
+}
+
+func orderValue(order *meshdb.Order, prices PriceOracle) float64 {
+	signedOrder := order.SignedOrder
+	if price, found := prices.Price(signedOrder.TakerAssetData); found {
+		return bigIntToFloat(order.FillableTakerAssetAmount) * price
+	}
+	if price, found := prices.Price(signedOrder.MakerAssetData); found && signedOrder.TakerAssetAmount.Sign() != 0 {
+		fillableMakerAssetAmount := new(big.Int).Mul(order.FillableTakerAssetAmount, signedOrder.MakerAssetAmount)
+		fillableMakerAssetAmount.Div(fillableMakerAssetAmount, signedOrder.TakerAssetAmount)
+		return bigIntToFloat(fillableMakerAssetAmount) * price
+	}
+	return 0
+}
+
+// NewSpreadEvictionPolicy returns an EvictionPolicy which evicts the orders
+// whose price is furthest from the best price for the same asset pair first.
+// The price of an order is the amount of the taker asset paid per unit of the
+// maker asset, so the best price is the lowest price. Only the orders which
+// are candidates for eviction (i.e. unpinned orders) are used to determine the
+// best price.
+//
+// New orders are scored against the best prices from the last time orders
+// were evicted.
+func NewSpreadEvictionPolicy() EvictionPolicy {
+	var lastBestPricesMu sync.Mutex
+	var lastBestPrices map[string]float64
+	return &scoredEvictionPolicy{
+		score: func(orders []*meshdb.Order) []float64 {
+			prices := make([]float64, len(orders))
+			bestPrices := map[string]float64{}
+			for i, order := range orders {
+				prices[i] = orderPrice(order.SignedOrder)
+				pair := assetPairKey(order.SignedOrder)
+				if bestPrice, found := bestPrices[pair]; !found || prices[i] < bestPrice {
+					bestPrices[pair] = prices[i]
+				}
+			}
+			lastBestPricesMu.Lock()
+			lastBestPrices = bestPrices
+			lastBestPricesMu.Unlock()
+			scores := make([]float64, len(orders))
+			for i, order := range orders {
+				scores[i] = spreadScore(prices[i], bestPrices[assetPairKey(order.SignedOrder)])
+			}
+			return scores
+		},
+		scoreNewOrder: func(order *meshdb.Order) float64 {
+			price := orderPrice(order.SignedOrder)
+			lastBestPricesMu.Lock()
+			bestPrice, found := lastBestPrices[assetPairKey(order.SignedOrder)]
+			lastBestPricesMu.Unlock()
+			if !found || price < bestPrice {
+				bestPrice = price
+			}
+			return spreadScore(price, bestPrice)
+		},
+	}
+}
+
+// spreadScore returns the score of an order with the given price for the
+// spread eviction policy.
+func spreadScore(price float64, bestPrice float64) float64 {
+	if math.IsInf(price, 1) || bestPrice == 0 {
+		return math.Inf(-1)
+	}
+	// The spread is always >= 0 and orders with the largest spread should be
+	// evicted first.
+	return -(price/bestPrice - 1)
+}
+
+func orderPrice(signedOrder *zeroex.SignedOrder) float64 {
+	if signedOrder.MakerAssetAmount.Sign() == 0 {
+		return math.Inf(1)
+	}
+	return bigIntToFloat(signedOrder.TakerAssetAmount) / bigIntToFloat(signedOrder.MakerAssetAmount)
+}
+
+func assetPairKey(signedOrder *zeroex.SignedOrder) string {
+	return common.Bytes2Hex(signedOrder.MakerAssetData) + "|" + common.Bytes2Hex(signedOrder.TakerAssetData)
+}
+
+// MakerReputation provides the reputation scores used by the EvictionPolicy
+// returned by NewMakerReputationEvictionPolicy.
+type MakerReputation interface {
+	// Reputation returns the reputation score of the given maker. Higher scores
+	// are better.
+	Reputation(makerAddress common.Address) float64
+}
+
+// MakerReputations is a MakerReputation with fixed scores, keyed by maker
+// address. Makers without a score have a reputation of 0.
+type MakerReputations map[common.Address]float64
+
+// Reputation implements MakerReputation.
+func (r MakerReputations) Reputation(makerAddress common.Address) float64 {
+	return r[makerAddress]
+}
+
+// NewMakerReputationEvictionPolicy returns an EvictionPolicy which evicts the
+// orders of the makers with the lowest reputation first.
+func NewMakerReputationEvictionPolicy(reputation MakerReputation) EvictionPolicy {
+	return &scoredEvictionPolicy{
+		score: func(orders []*meshdb.Order) []float64 {
+			scores := make([]float64, len(orders))
+			for i, order := range orders {
+				scores[i] = reputation.Reputation(order.SignedOrder.MakerAddress)
+			}
+			return scores
+		},
+	}
+}
+
+// NewAgeEvictionPolicy returns an EvictionPolicy which evicts the orders which
+// were added the longest time ago first. Orders without an AddedAt are
+// considered the oldest, although the database backfills it for orders which
+// were stored before it was recorded.
+func NewAgeEvictionPolicy() EvictionPolicy {
+	return &scoredEvictionPolicy{
+		score: func(orders []*meshdb.Order) []float64 {
+			scores := make([]float64, len(orders))
+			for i, order := range orders {
+				if order.AddedAt.IsZero() {
+					scores[i] = math.Inf(-1)
+					continue
+				}
+				scores[i] = float64(order.AddedAt.UnixNano())
+			}
+			return scores
+		},
+	}
+}
+
+// scoredEvictionPolicy evicts the orders with the lowest scores first. Ties are
+// broken by evicting the orders with the highest expiration times first. It
+// remembers the highest score of the orders it evicted most recently and only
+// admits new orders which score at least as high.
+type scoredEvictionPolicy struct {
+	score func(orders []*meshdb.Order) []float64
+	// scoreNewOrder is optional. It scores an order which is not stored yet. By
+	// default, the order is scored on its own with score.
+	scoreNewOrder func(order *meshdb.Order) float64
+
+	mu               sync.Mutex
+	hasEvicted       bool
+	lastEvictedScore float64
+}
+
+// SelectOrdersToEvict implements EvictionPolicy.
+func (p *scoredEvictionPolicy) SelectOrdersToEvict(orders []*meshdb.Order, numOrdersToEvict int) ([]*meshdb.Order, error) {
+	scores := p.score(orders)
+	indexes := make([]int, len(orders))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	sort.SliceStable(indexes, func(i, j int) bool {
+		a, b := indexes[i], indexes[j]
+		if scores[a] != scores[b] {
+			return scores[a] < scores[b]
+		}
+		return hasLaterExpirationTime(orders[a], orders[b])
+	})
+	selectedOrders := make([]*meshdb.Order, numOrdersToEvict)
+	for i := range selectedOrders {
+		selectedOrders[i] = orders[indexes[i]]
+	}
+	if numOrdersToEvict > 0 {
+		p.mu.Lock()
+		p.hasEvicted = true
+		p.lastEvictedScore = scores[indexes[numOrdersToEvict-1]]
+		p.mu.Unlock()
+	}
+	return selectedOrders, nil
+}
+
+// AdmitsOrder implements EvictionPolicy. The fillable amount of a new order is
+// not known yet, so it is assumed to be fully fillable.
+func (p *scoredEvictionPolicy) AdmitsOrder(signedOrder *zeroex.SignedOrder) bool {
+	p.mu.Lock()
+	hasEvicted, lastEvictedScore := p.hasEvicted, p.lastEvictedScore
+	p.mu.Unlock()
+	if !hasEvicted {
+		return true
+	}
+	order := &meshdb.Order{
+		SignedOrder:              signedOrder,
+		FillableTakerAssetAmount: signedOrder.TakerAssetAmount,
+		AddedAt:                  time.Now().UTC(),
+	}
+	var score float64
+	if p.scoreNewOrder != nil {
+		score = p.scoreNewOrder(order)
+	} else {
+		score = p.score([]*meshdb.Order{order})[0]
+	}
+	return score >= lastEvictedScore
+}
+
+func hasLaterExpirationTime(a, b *meshdb.Order) bool {
+	return a.SignedOrder.ExpirationTimeSeconds.Cmp(b.SignedOrder.ExpirationTimeSeconds) == 1
+}
+
+func bigIntToFloat(i *big.Int) float64 {
+	f, _ := new(big.Float).SetInt(i).Float64()
+	return f
+}
@@ -0,0 +1,160 @@
+package orderwatch
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/0xProject/0x-mesh/constants"
+	"github.com/0xProject/0x-mesh/meshdb"
+	"github.com/0xProject/0x-mesh/zeroex"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	evictionTestTokenAddress      = common.HexToAddress("0x871dd7c2b4b25e1aa18728e9d5f2af4c4e431f5c")
+	evictionTestOtherTokenAddress = common.HexToAddress("0x0b1ba0af832d7c05fd64161e0db78e85978e8082")
+	evictionTestAssetData         = common.Hex2Bytes("f47261b0000000000000000000000000871dd7c2b4b25e1aa18728e9d5f2af4c4e431f5c")
+	evictionTestOtherAssetData    = common.Hex2Bytes("f47261b00000000000000000000000000b1ba0af832d7c05fd64161e0db78e85978e8082")
+)
+
+func newEvictionTestOrder(salt int64, makerAssetAmount int64, takerAssetAmount int64, expirationTimeSeconds int64) *meshdb.Order {
+	return &meshdb.Order{
+		Hash: common.BigToHash(big.NewInt(salt)),
+		SignedOrder: &zeroex.SignedOrder{
+			Order: zeroex.Order{
+				MakerAddress:          constants.GanacheAccount1,
+				MakerAssetData:        evictionTestAssetData,
+				TakerAssetData:        evictionTestOtherAssetData,
+				Salt:                  big.NewInt(salt),
+				MakerAssetAmount:      big.NewInt(makerAssetAmount),
+				TakerAssetAmount:      big.NewInt(takerAssetAmount),
+				ExpirationTimeSeconds: big.NewInt(expirationTimeSeconds),
+			},
+		},
+		FillableTakerAssetAmount: big.NewInt(takerAssetAmount),
+	}
+}
+
+func TestOrderValueEvictionPolicy(t *testing.T) {
+	lowValueOrder := newEvictionTestOrder(0, 1000, 10, 500)
+	highValueOrder := newEvictionTestOrder(1, 1000, 1000, 100)
+	partiallyFilledOrder := newEvictionTestOrder(2, 1000, 1000, 100)
+	partiallyFilledOrder.FillableTakerAssetAmount = big.NewInt(100)
+	// The taker asset of this order has no price, so the value is determined by
+	// the maker asset.
+	makerPricedOrder := newEvictionTestOrder(3, 1000, 1000, 100)
+	makerPricedOrder.SignedOrder.TakerAssetData = common.Hex2Bytes("f47261b000000000000000000000000034d402f14d58e001d8efbe6585051bf9706aa064")
+	// Neither asset has a price, so this order should be evicted first.
+	unpricedOrder := newEvictionTestOrder(4, 1000, 1000, 100)
+	unpricedOrder.SignedOrder.MakerAssetData = common.Hex2Bytes("f47261b000000000000000000000000034d402f14d58e001d8efbe6585051bf9706aa064")
+	unpricedOrder.SignedOrder.TakerAssetData = common.Hex2Bytes("f47261b000000000000000000000000034d402f14d58e001d8efbe6585051bf9706aa064")
+
+	policy := NewOrderValueEvictionPolicy(TokenPrices{
+		evictionTestTokenAddress:      2,
+		evictionTestOtherTokenAddress: 1,
+	})
+	orders := []*meshdb.Order{highValueOrder, lowValueOrder, makerPricedOrder, partiallyFilledOrder, unpricedOrder}
+	evictedOrders, err := policy.SelectOrdersToEvict(orders, 4)
+	require.NoError(t, err)
+	assert.Equal(t, []*meshdb.Order{unpricedOrder, lowValueOrder, partiallyFilledOrder, highValueOrder}, evictedOrders)
+}
+
+func TestSpreadEvictionPolicy(t *testing.T) {
+	bestOrder := newEvictionTestOrder(0, 1000, 1000, 100)
+	worseOrder := newEvictionTestOrder(1, 1000, 1100, 100)
+	worstOrder := newEvictionTestOrder(2, 1000, 2000, 100)
+	// This order is the best order for another asset pair, so it should be
+	// kept even though its price is worse than the price of worstOrder.
+	otherPairOrder := newEvictionTestOrder(3, 1000, 3000, 100)
+	otherPairOrder.SignedOrder.MakerAssetData = evictionTestOtherAssetData
+	otherPairOrder.SignedOrder.TakerAssetData = evictionTestAssetData
+
+	policy := NewSpreadEvictionPolicy()
+	orders := []*meshdb.Order{bestOrder, otherPairOrder, worseOrder, worstOrder}
+	evictedOrders, err := policy.SelectOrdersToEvict(orders, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []*meshdb.Order{worstOrder, worseOrder}, evictedOrders)
+}
+
+func TestMakerReputationEvictionPolicy(t *testing.T) {
+	trustedOrder := newEvictionTestOrder(0, 1000, 1000, 100)
+	unknownOrder := newEvictionTestOrder(1, 1000, 1000, 100)
+	unknownOrder.SignedOrder.MakerAddress = constants.GanacheAccount2
+	// Ties are broken by evicting the order with the higher expiration time.
+	unknownLongDatedOrder := newEvictionTestOrder(2, 1000, 1000, 500)
+	unknownLongDatedOrder.SignedOrder.MakerAddress = constants.GanacheAccount2
+	untrustedOrder := newEvictionTestOrder(3, 1000, 1000, 100)
+	untrustedOrder.SignedOrder.MakerAddress = constants.GanacheAccount3
+
+	policy := NewMakerReputationEvictionPolicy(MakerReputations{
+		constants.GanacheAccount1: 10,
+		constants.GanacheAccount3: -1,
+	})
+	orders := []*meshdb.Order{trustedOrder, unknownOrder, unknownLongDatedOrder, untrustedOrder}
+	evictedOrders, err := policy.SelectOrdersToEvict(orders, 3)
+	require.NoError(t, err)
+	assert.Equal(t, []*meshdb.Order{untrustedOrder, unknownLongDatedOrder, unknownOrder}, evictedOrders)
+}
+
+func TestAgeEvictionPolicy(t *testing.T) {
+	now := time.Now()
+	newOrder := newEvictionTestOrder(0, 1000, 1000, 100)
+	newOrder.AddedAt = now
+	oldOrder := newEvictionTestOrder(1, 1000, 1000, 100)
+	oldOrder.AddedAt = now.Add(-time.Hour)
+	// Orders without AddedAt are considered the oldest.
+	legacyOrder := newEvictionTestOrder(2, 1000, 1000, 100)
+
+	policy := NewAgeEvictionPolicy()
+	orders := []*meshdb.Order{newOrder, oldOrder, legacyOrder}
+	evictedOrders, err := policy.SelectOrdersToEvict(orders, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []*meshdb.Order{legacyOrder, oldOrder}, evictedOrders)
+}
+
+func TestMakerReputationEvictionPolicyAdmitsOrder(t *testing.T) {
+	trustedOrder := newEvictionTestOrder(0, 1000, 1000, 100)
+	unknownOrder := newEvictionTestOrder(1, 1000, 1000, 100)
+	unknownOrder.SignedOrder.MakerAddress = constants.GanacheAccount2
+	untrustedOrder := newEvictionTestOrder(2, 1000, 1000, 100)
+	untrustedOrder.SignedOrder.MakerAddress = constants.GanacheAccount3
+
+	policy := NewMakerReputationEvictionPolicy(MakerReputations{
+		constants.GanacheAccount1: 10,
+		constants.GanacheAccount3: -1,
+	})
+	// All orders are admitted until orders have been evicted.
+	assert.True(t, policy.AdmitsOrder(untrustedOrder.SignedOrder))
+
+	orders := []*meshdb.Order{trustedOrder, unknownOrder, untrustedOrder}
+	_, err := policy.SelectOrdersToEvict(orders, 2)
+	require.NoError(t, err)
+	assert.True(t, policy.AdmitsOrder(trustedOrder.SignedOrder))
+	assert.True(t, policy.AdmitsOrder(unknownOrder.SignedOrder))
+	assert.False(t, policy.AdmitsOrder(untrustedOrder.SignedOrder))
+}
+
+func TestSpreadEvictionPolicyAdmitsOrder(t *testing.T) {
+	bestOrder := newEvictionTestOrder(0, 1000, 1000, 100)
+	worseOrder := newEvictionTestOrder(1, 1000, 1100, 100)
+	worstOrder := newEvictionTestOrder(2, 1000, 2000, 100)
+
+	policy := NewSpreadEvictionPolicy()
+	orders := []*meshdb.Order{bestOrder, worseOrder, worstOrder}
+	_, err := policy.SelectOrdersToEvict(orders, 2)
+	require.NoError(t, err)
+
+	// New orders are scored against the best price from the last eviction.
+	betterThanEvictedOrder := newEvictionTestOrder(3, 1000, 1050, 100)
+	assert.True(t, policy.AdmitsOrder(betterThanEvictedOrder.SignedOrder))
+	worseThanEvictedOrder := newEvictionTestOrder(4, 1000, 1500, 100)
+	assert.False(t, policy.AdmitsOrder(worseThanEvictedOrder.SignedOrder))
+	// Orders for an asset pair without a best price are admitted.
+	otherPairOrder := newEvictionTestOrder(5, 1000, 3000, 100)
+	otherPairOrder.SignedOrder.MakerAssetData = evictionTestOtherAssetData
+	otherPairOrder.SignedOrder.TakerAssetData = evictionTestAssetData
+	assert.True(t, policy.AdmitsOrder(otherPairOrder.SignedOrder))
+}
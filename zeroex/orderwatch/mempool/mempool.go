@@ -0,0 +1,317 @@
+// Package mempool watches the pending transactions of an Ethereum node and
+// decodes any calls to the 0x Exchange contract which would fill or cancel
+// orders once mined.
+package mempool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/0xProject/0x-mesh/ethereum/ethrpcclient"
+	"github.com/0xProject/0x-mesh/ethereum/wrappers"
+	"github.com/0xProject/0x-mesh/zeroex"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/event"
+	lru "github.com/hashicorp/golang-lru"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// seenTransactionsCacheSize is the number of pending transaction hashes to
+	// remember. Transactions which were already seen will not be fetched or
+	// decoded again.
+	seenTransactionsCacheSize = 10000
+	// filterNotFoundError is the error message returned by Geth and Parity when
+	// a filter has expired or was never installed.
+	filterNotFoundError = "filter not found"
+)
+
+// ActionKind is the kind of action a pending transaction will take on an order
+// once it is mined.
+type ActionKind uint8
+
+// ActionKind values
+const (
+	ActionFill ActionKind = iota
+	ActionCancel
+)
+
+// EndState returns the zeroex.OrderEventEndState which should be used when
+// emitting an order event for the given kind of action.
+func (k ActionKind) EndState() zeroex.OrderEventEndState {
+	switch k {
+	case ActionFill:
+		return zeroex.ESOrderPendingFill
+	case ActionCancel:
+		return zeroex.ESOrderPendingCancel
+	default:
+		return zeroex.ESInvalid
+	}
+}
+
+// PendingOrderAction is a fill or cancel of a single order that is contained in
+// a pending (i.e. not yet mined) transaction.
+type PendingOrderAction struct {
+	TxHash    common.Hash
+	OrderHash common.Hash
+	Kind      ActionKind
+}
+
+// Config holds some configuration options for an instance of Watcher.
+type Config struct {
+	Client          ethrpcclient.Client
+	ChainID         int
+	ExchangeAddress common.Address
+	PollingInterval time.Duration
+}
+
+// Watcher polls an Ethereum node for new pending transactions and emits a
+// PendingOrderAction for every order which is filled or cancelled by a pending
+// call to the Exchange contract.
+type Watcher struct {
+	client           ethrpcclient.Client
+	chainID          *big.Int
+	exchangeAddress  common.Address
+	pollingInterval  time.Duration
+	exchangeABI      abi.ABI
+	seenTransactions *lru.Cache
+	actionsFeed      event.Feed
+	actionsScope     event.SubscriptionScope // Subscription scope tracking current live listeners
+	wasStartedOnce   bool
+	mu               sync.Mutex
+}
+
+// New creates a new Watcher instance.
+func New(config Config) (*Watcher, error) {
+	if config.Client == nil {
+		return nil, errors.New("config.Client is required")
+	} else if config.PollingInterval == 0 {
+		return nil, errors.New("config.PollingInterval is required and cannot be zero")
+	}
+	exchangeABI, err := abi.JSON(strings.NewReader(wrappers.ExchangeABI))
+	if err != nil {
+		return nil, err
+	}
+	// lru.New only returns an error if size is <= 0.
+	seenTransactions, _ := lru.New(seenTransactionsCacheSize)
+	return &Watcher{
+		client:           config.Client,
+		chainID:          big.NewInt(int64(config.ChainID)),
+		exchangeAddress:  config.ExchangeAddress,
+		pollingInterval:  config.PollingInterval,
+		exchangeABI:      exchangeABI,
+		seenTransactions: seenTransactions,
+	}, nil
+}
+
+// Subscribe allows one to subscribe to the pending order actions emitted by the
+// Watcher. To unsubscribe, simply call `Unsubscribe` on the returned
+// subscription. The sink channel should have ample buffer space to avoid
+// blocking other subscribers. Slow subscribers are not dropped.
+func (w *Watcher) Subscribe(sink chan<- []*PendingOrderAction) event.Subscription {
+	return w.actionsScope.Track(w.actionsFeed.Subscribe(sink))
+}
+
+// Watch starts polling for pending transactions. It blocks until there is a
+// critical error or the given context is canceled.
+func (w *Watcher) Watch(ctx context.Context) error {
+	w.mu.Lock()
+	if w.wasStartedOnce {
+		w.mu.Unlock()
+		return errors.New("Can only start mempool Watcher once per instance")
+	}
+	w.wasStartedOnce = true
+	w.mu.Unlock()
+
+	filterID, err := w.installFilter(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		// Use a fresh context since ctx is most likely already canceled.
+		uninstallCtx, cancel := context.WithTimeout(context.Background(), w.pollingInterval)
+		defer cancel()
+		_ = w.client.CallContext(uninstallCtx, nil, "eth_uninstallFilter", filterID)
+	}()
+
+	ticker := time.NewTicker(w.pollingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			w.actionsScope.Close()
+			return nil
+		case <-ticker.C:
+			var txHashes []common.Hash
+			if err := w.client.CallContext(ctx, &txHashes, "eth_getFilterChanges", filterID); err != nil {
+				if !strings.Contains(err.Error(), filterNotFoundError) {
+					log.WithError(err).Warn("could not get pending transactions")
+					continue
+				}
+				// The filter expired (e.g. because the Ethereum node was restarted).
+				// Install a new one and try again on the next tick.
+				filterID, err = w.installFilter(ctx)
+				if err != nil {
+					log.WithError(err).Warn("could not re-install pending transaction filter")
+				}
+				continue
+			}
+			actions := w.handlePendingTransactions(ctx, txHashes)
+			if len(actions) > 0 {
+				w.actionsFeed.Send(actions)
+			}
+		}
+	}
+}
+
+func (w *Watcher) installFilter(ctx context.Context) (string, error) {
+	var filterID string
+	if err := w.client.CallContext(ctx, &filterID, "eth_newPendingTransactionFilter"); err != nil {
+		return "", fmt.Errorf("could not install pending transaction filter: %s", err.Error())
+	}
+	return filterID, nil
+}
+
+// rpcTransaction contains the subset of fields returned by
+// eth_getTransactionByHash that we need.
+type rpcTransaction struct {
+	Hash        common.Hash     `json:"hash"`
+	To          *common.Address `json:"to"`
+	Input       hexutil.Bytes   `json:"input"`
+	BlockNumber *hexutil.Big    `json:"blockNumber"`
+}
+
+func (w *Watcher) handlePendingTransactions(ctx context.Context, txHashes []common.Hash) []*PendingOrderAction {
+	actions := []*PendingOrderAction{}
+	for _, txHash := range txHashes {
+		if ok, _ := w.seenTransactions.ContainsOrAdd(txHash, nil); ok {
+			continue
+		}
+		var tx *rpcTransaction
+		if err := w.client.CallContext(ctx, &tx, "eth_getTransactionByHash", txHash); err != nil {
+			log.WithFields(log.Fields{
+				"error":  err.Error(),
+				"txHash": txHash.Hex(),
+			}).Trace("could not get pending transaction")
+			continue
+		}
+		// Transactions can be dropped from the mempool or mined before we get to
+		// them. Either way, there is nothing left for us to do.
+		if tx == nil || tx.BlockNumber != nil || tx.To == nil || *tx.To != w.exchangeAddress {
+			continue
+		}
+		txActions, err := w.DecodeExchangeCall(tx.Hash, tx.Input)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"error":  err.Error(),
+				"txHash": txHash.Hex(),
+			}).Trace("could not decode pending Exchange transaction")
+			continue
+		}
+		actions = append(actions, txActions...)
+	}
+	return actions
+}
+
+// exchangeCallArgs holds the decoded arguments for all of the Exchange methods
+// we are interested in. Arguments are matched to fields by name, so any fields
+// which are not used by a particular method are left empty.
+type exchangeCallArgs struct {
+	Order       wrappers.TrimmedOrder
+	Orders      []wrappers.TrimmedOrder
+	LeftOrder   wrappers.TrimmedOrder
+	RightOrder  wrappers.TrimmedOrder
+	LeftOrders  []wrappers.TrimmedOrder
+	RightOrders []wrappers.TrimmedOrder
+}
+
+// DecodeExchangeCall decodes the input data of a transaction sent to the
+// Exchange contract and returns the orders it would fill or cancel. Calls to
+// methods which do not fill or cancel specific orders (e.g. cancelOrdersUpTo)
+// result in an empty slice.
+func (w *Watcher) DecodeExchangeCall(txHash common.Hash, input []byte) ([]*PendingOrderAction, error) {
+	if len(input) < 4 {
+		return nil, errors.New("transaction input must be at least 4 bytes long")
+	}
+	method, err := w.exchangeABI.MethodById(input[:4])
+	if err != nil {
+		return nil, err
+	}
+
+	var kind ActionKind
+	switch method.Name {
+	case "fillOrder", "fillOrKillOrder",
+		"batchFillOrders", "batchFillOrKillOrders", "batchFillOrdersNoThrow",
+		"marketSellOrdersNoThrow", "marketSellOrdersFillOrKill",
+		"marketBuyOrdersNoThrow", "marketBuyOrdersFillOrKill",
+		"matchOrders", "matchOrdersWithMaximalFill",
+		"batchMatchOrders", "batchMatchOrdersWithMaximalFill":
+		kind = ActionFill
+	case "cancelOrder", "batchCancelOrders":
+		kind = ActionCancel
+	default:
+		return []*PendingOrderAction{}, nil
+	}
+
+	var args exchangeCallArgs
+	if err := method.Inputs.Unpack(&args, input[4:]); err != nil {
+		return nil, err
+	}
+
+	trimmedOrders := []wrappers.TrimmedOrder{}
+	switch method.Name {
+	case "fillOrder", "fillOrKillOrder", "cancelOrder":
+		trimmedOrders = append(trimmedOrders, args.Order)
+	case "matchOrders", "matchOrdersWithMaximalFill":
+		trimmedOrders = append(trimmedOrders, args.LeftOrder, args.RightOrder)
+	case "batchMatchOrders", "batchMatchOrdersWithMaximalFill":
+		trimmedOrders = append(trimmedOrders, args.LeftOrders...)
+		trimmedOrders = append(trimmedOrders, args.RightOrders...)
+	default:
+		trimmedOrders = append(trimmedOrders, args.Orders...)
+	}
+
+	actions := make([]*PendingOrderAction, len(trimmedOrders))
+	for i, trimmedOrder := range trimmedOrders {
+		orderHash, err := w.untrimOrder(trimmedOrder).ComputeOrderHash()
+		if err != nil {
+			return nil, err
+		}
+		actions[i] = &PendingOrderAction{
+			TxHash:    txHash,
+			OrderHash: orderHash,
+			Kind:      kind,
+		}
+	}
+	return actions, nil
+}
+
+// untrimOrder converts a wrappers.TrimmedOrder back into a zeroex.Order so that
+// its hash can be computed.
+func (w *Watcher) untrimOrder(trimmedOrder wrappers.TrimmedOrder) *zeroex.Order {
+	return &zeroex.Order{
+		ChainID:               w.chainID,
+		ExchangeAddress:       w.exchangeAddress,
+		MakerAddress:          trimmedOrder.MakerAddress,
+		MakerAssetData:        trimmedOrder.MakerAssetData,
+		MakerFeeAssetData:     trimmedOrder.MakerFeeAssetData,
+		MakerAssetAmount:      trimmedOrder.MakerAssetAmount,
+		MakerFee:              trimmedOrder.MakerFee,
+		TakerAddress:          trimmedOrder.TakerAddress,
+		TakerAssetData:        trimmedOrder.TakerAssetData,
+		TakerFeeAssetData:     trimmedOrder.TakerFeeAssetData,
+		TakerAssetAmount:      trimmedOrder.TakerAssetAmount,
+		TakerFee:              trimmedOrder.TakerFee,
+		SenderAddress:         trimmedOrder.SenderAddress,
+		FeeRecipientAddress:   trimmedOrder.FeeRecipientAddress,
+		ExpirationTimeSeconds: trimmedOrder.ExpirationTimeSeconds,
+		Salt:                  trimmedOrder.Salt,
+	}
+}
@@ -0,0 +1,105 @@
+package mempool
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/0xProject/0x-mesh/constants"
+	"github.com/0xProject/0x-mesh/ethereum"
+	"github.com/0xProject/0x-mesh/ethereum/wrappers"
+	"github.com/0xProject/0x-mesh/zeroex"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	contractAddresses = ethereum.GanacheAddresses
+	testTxHash        = common.HexToHash("0x7f0d5e1a2b7a3c7c3d1a5f4d93d6a1b1f59c8a0e4b2b1b0a9d8c7b6a5f4e3d2c")
+)
+
+func newTestWatcher(t *testing.T) *Watcher {
+	exchangeABI, err := abi.JSON(strings.NewReader(wrappers.ExchangeABI))
+	require.NoError(t, err)
+	return &Watcher{
+		chainID:         big.NewInt(constants.TestChainID),
+		exchangeAddress: contractAddresses.Exchange,
+		exchangeABI:     exchangeABI,
+	}
+}
+
+func newTestOrder(salt int64) *zeroex.SignedOrder {
+	return &zeroex.SignedOrder{
+		Order: zeroex.Order{
+			ChainID:               big.NewInt(constants.TestChainID),
+			ExchangeAddress:       contractAddresses.Exchange,
+			MakerAddress:          constants.GanacheAccount1,
+			MakerAssetData:        common.Hex2Bytes("f47261b0000000000000000000000000871dd7c2b4b25e1aa18728e9d5f2af4c4e431f5c"),
+			MakerFeeAssetData:     constants.NullBytes,
+			MakerAssetAmount:      big.NewInt(100),
+			MakerFee:              big.NewInt(0),
+			TakerAddress:          constants.NullAddress,
+			TakerAssetData:        common.Hex2Bytes("f47261b00000000000000000000000000b1ba0af832d7c05fd64161e0db78e85978e8082"),
+			TakerFeeAssetData:     constants.NullBytes,
+			TakerAssetAmount:      big.NewInt(42),
+			TakerFee:              big.NewInt(0),
+			SenderAddress:         constants.NullAddress,
+			FeeRecipientAddress:   constants.NullAddress,
+			ExpirationTimeSeconds: big.NewInt(1594000000),
+			Salt:                  big.NewInt(salt),
+		},
+		Signature: common.Hex2Bytes("1c"),
+	}
+}
+
+func TestDecodeExchangeCallFillOrder(t *testing.T) {
+	w := newTestWatcher(t)
+	order := newTestOrder(1)
+	input, err := w.exchangeABI.Pack("fillOrder", order.Trim(), big.NewInt(10), order.Signature)
+	require.NoError(t, err)
+
+	actions, err := w.DecodeExchangeCall(testTxHash, input)
+	require.NoError(t, err)
+	expectedOrderHash, err := order.ComputeOrderHash()
+	require.NoError(t, err)
+	expectedActions := []*PendingOrderAction{
+		{
+			TxHash:    testTxHash,
+			OrderHash: expectedOrderHash,
+			Kind:      ActionFill,
+		},
+	}
+	assert.Equal(t, expectedActions, actions)
+	assert.Equal(t, zeroex.ESOrderPendingFill, actions[0].Kind.EndState())
+}
+
+func TestDecodeExchangeCallBatchCancelOrders(t *testing.T) {
+	w := newTestWatcher(t)
+	orders := []*zeroex.SignedOrder{newTestOrder(1), newTestOrder(2)}
+	trimmedOrders := []wrappers.TrimmedOrder{orders[0].Trim(), orders[1].Trim()}
+	input, err := w.exchangeABI.Pack("batchCancelOrders", trimmedOrders)
+	require.NoError(t, err)
+
+	actions, err := w.DecodeExchangeCall(testTxHash, input)
+	require.NoError(t, err)
+	require.Len(t, actions, len(orders))
+	for i, order := range orders {
+		expectedOrderHash, err := order.ComputeOrderHash()
+		require.NoError(t, err)
+		assert.Equal(t, expectedOrderHash, actions[i].OrderHash, "wrong order hash for action %d", i)
+		assert.Equal(t, ActionCancel, actions[i].Kind, "wrong kind for action %d", i)
+		assert.Equal(t, zeroex.ESOrderPendingCancel, actions[i].Kind.EndState())
+	}
+}
+
+func TestDecodeExchangeCallIgnoresOtherMethods(t *testing.T) {
+	w := newTestWatcher(t)
+	input, err := w.exchangeABI.Pack("cancelOrdersUpTo", big.NewInt(5))
+	require.NoError(t, err)
+
+	actions, err := w.DecodeExchangeCall(testTxHash, input)
+	require.NoError(t, err)
+	assert.Empty(t, actions)
+}
@@ -0,0 +1,115 @@
+package orderwatch
+
+import (
+	"github.com/0xProject/0x-mesh/meshdb"
+	"github.com/0xProject/0x-mesh/zeroex"
+	"github.com/0xProject/0x-mesh/zeroex/ordervalidator"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// OrderQuotas limits the number of unpinned orders that a single maker or a
+// single peer can have in storage. Pinned orders and orders which are flagged
+// for removal never count towards the quotas. A quota of 0 means there is no
+// limit.
+type OrderQuotas struct {
+	// MaxOrdersPerMaker is the default maximum number of unpinned orders per
+	// maker address.
+	MaxOrdersPerMaker int
+	// MaxOrdersPerPeer is the default maximum number of unpinned orders which
+	// were received from the same peer. Orders which were added locally (e.g.
+	// via the JSON-RPC API) do not count towards any peer quota.
+	MaxOrdersPerPeer int
+	// MakerOverrides overrides MaxOrdersPerMaker for specific maker addresses.
+	MakerOverrides map[common.Address]int
+	// PeerOverrides overrides MaxOrdersPerPeer for specific peer IDs.
+	PeerOverrides map[string]int
+}
+
+// MaxOrdersForMaker returns the quota for the given maker address.
+func (q OrderQuotas) MaxOrdersForMaker(makerAddress common.Address) int {
+	if maxOrders, found := q.MakerOverrides[makerAddress]; found {
+		return maxOrders
+	}
+	return q.MaxOrdersPerMaker
+}
+
+// MaxOrdersForPeer returns the quota for the given peer ID.
+func (q OrderQuotas) MaxOrdersForPeer(peerID string) int {
+	if maxOrders, found := q.PeerOverrides[peerID]; found {
+		return maxOrders
+	}
+	return q.MaxOrdersPerPeer
+}
+
+// isEnabled returns true if any quota is set.
+func (q OrderQuotas) isEnabled() bool {
+	return q.MaxOrdersPerMaker != 0 || q.MaxOrdersPerPeer != 0 || len(q.MakerOverrides) != 0 || len(q.PeerOverrides) != 0
+}
+
+// orderQuotaChecker checks the quotas for a batch of orders. Orders which pass
+// the check are counted, so that a single batch cannot exceed the quotas
+// either.
+type orderQuotaChecker struct {
+	meshDB           *meshdb.MeshDB
+	quotas           OrderQuotas
+	makerOrderCounts map[common.Address]int
+	peerOrderCounts  map[string]int
+}
+
+func newOrderQuotaChecker(meshDB *meshdb.MeshDB, quotas OrderQuotas) *orderQuotaChecker {
+	return &orderQuotaChecker{
+		meshDB:           meshDB,
+		quotas:           quotas,
+		makerOrderCounts: map[common.Address]int{},
+		peerOrderCounts:  map[string]int{},
+	}
+}
+
+// check returns the RejectedOrderStatus for the given order if accepting it
+// would exceed the quota of its maker or of the peer which sent it. If the
+// order is within the quotas, it is counted and check returns nil.
+func (c *orderQuotaChecker) check(order *zeroex.SignedOrder, senderPeerID string) (*ordervalidator.RejectedOrderStatus, error) {
+	makerOrderCount, err := c.makerOrderCount(order.MakerAddress)
+	if err != nil {
+		return nil, err
+	}
+	if maxOrders := c.quotas.MaxOrdersForMaker(order.MakerAddress); maxOrders != 0 && makerOrderCount >= maxOrders {
+		return &ordervalidator.ROMakerOrderQuotaExceeded, nil
+	}
+	if senderPeerID != "" {
+		peerOrderCount, err := c.peerOrderCount(senderPeerID)
+		if err != nil {
+			return nil, err
+		}
+		if maxOrders := c.quotas.MaxOrdersForPeer(senderPeerID); maxOrders != 0 && peerOrderCount >= maxOrders {
+			return &ordervalidator.ROPeerOrderQuotaExceeded, nil
+		}
+		c.peerOrderCounts[senderPeerID]++
+	}
+	c.makerOrderCounts[order.MakerAddress]++
+	return nil, nil
+}
+
+func (c *orderQuotaChecker) makerOrderCount(makerAddress common.Address) (int, error) {
+	if count, found := c.makerOrderCounts[makerAddress]; found {
+		return count, nil
+	}
+	count, err := c.meshDB.CountUnpinnedOrdersByMakerAddress(makerAddress)
+	if err != nil {
+		return 0, err
+	}
+	c.makerOrderCounts[makerAddress] = count
+	return count, nil
+}
+
+func (c *orderQuotaChecker) peerOrderCount(peerID string) (int, error) {
+	if count, found := c.peerOrderCounts[peerID]; found {
+		return count, nil
+	}
+	count, err := c.meshDB.CountUnpinnedOrdersReceivedFrom(peerID)
+	if err != nil {
+		return 0, err
+	}
+	c.peerOrderCounts[peerID] = count
+	return count, nil
+}
@@ -0,0 +1,94 @@
+// +build !js
+
+package orderwatch
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/0xProject/0x-mesh/constants"
+	"github.com/0xProject/0x-mesh/meshdb"
+	"github.com/0xProject/0x-mesh/zeroex"
+	"github.com/0xProject/0x-mesh/zeroex/ordervalidator"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderQuotas(t *testing.T) {
+	quotas := OrderQuotas{
+		MaxOrdersPerMaker: 10,
+		MaxOrdersPerPeer:  20,
+		MakerOverrides: map[common.Address]int{
+			constants.GanacheAccount1: 0,
+		},
+		PeerOverrides: map[string]int{
+			"peerA": 5,
+		},
+	}
+	assert.True(t, quotas.isEnabled())
+	assert.False(t, OrderQuotas{}.isEnabled())
+	assert.Equal(t, 10, quotas.MaxOrdersForMaker(constants.GanacheAccount0))
+	assert.Equal(t, 0, quotas.MaxOrdersForMaker(constants.GanacheAccount1))
+	assert.Equal(t, 5, quotas.MaxOrdersForPeer("peerA"))
+	assert.Equal(t, 20, quotas.MaxOrdersForPeer("peerB"))
+}
+
+func TestOrderQuotaChecker(t *testing.T) {
+	meshDB, err := meshdb.New("/tmp/leveldb_testing/"+uuid.New().String(), ganacheAddresses)
+	require.NoError(t, err)
+	defer meshDB.Close()
+
+	newOrder := func(salt int64, makerAddress common.Address) *zeroex.SignedOrder {
+		return &zeroex.SignedOrder{
+			Order: zeroex.Order{
+				ChainID:               big.NewInt(constants.TestChainID),
+				ExchangeAddress:       ganacheAddresses.Exchange,
+				MakerAddress:          makerAddress,
+				MakerAssetData:        common.Hex2Bytes("f47261b0000000000000000000000000871dd7c2b4b25e1aa18728e9d5f2af4c4e431f5c"),
+				MakerFeeAssetData:     constants.NullBytes,
+				TakerAssetData:        common.Hex2Bytes("f47261b000000000000000000000000034d402f14d58e001d8efbe6585051bf9706aa064"),
+				TakerFeeAssetData:     constants.NullBytes,
+				MakerAssetAmount:      big.NewInt(1000),
+				TakerAssetAmount:      big.NewInt(1000),
+				MakerFee:              big.NewInt(0),
+				TakerFee:              big.NewInt(0),
+				Salt:                  big.NewInt(salt),
+				ExpirationTimeSeconds: big.NewInt(100),
+			},
+		}
+	}
+	// One order of GanacheAccount0 which was received from peerA is already
+	// stored.
+	require.NoError(t, meshDB.Orders.Insert(&meshdb.Order{
+		Hash:                     common.BigToHash(big.NewInt(100)),
+		SignedOrder:              newOrder(100, constants.GanacheAccount0),
+		FillableTakerAssetAmount: big.NewInt(1),
+		ReceivedFrom:             "peerA",
+	}))
+
+	quotas := OrderQuotas{
+		MaxOrdersPerMaker: 2,
+		MaxOrdersPerPeer:  3,
+	}
+	checker := newOrderQuotaChecker(meshDB, quotas)
+
+	testCases := []struct {
+		order          *zeroex.SignedOrder
+		sender         string
+		expectedStatus *ordervalidator.RejectedOrderStatus
+	}{
+		{newOrder(0, constants.GanacheAccount0), "peerA", nil},
+		{newOrder(1, constants.GanacheAccount0), "peerB", &ordervalidator.ROMakerOrderQuotaExceeded},
+		{newOrder(2, constants.GanacheAccount1), "peerA", nil},
+		{newOrder(3, constants.GanacheAccount2), "peerA", &ordervalidator.ROPeerOrderQuotaExceeded},
+		// Orders which were added locally do not count towards any peer quota.
+		{newOrder(4, constants.GanacheAccount2), "", nil},
+	}
+	for i, testCase := range testCases {
+		status, err := checker.check(testCase.order, testCase.sender)
+		require.NoError(t, err)
+		assert.Equal(t, testCase.expectedStatus, status, "test case %d", i)
+	}
+}
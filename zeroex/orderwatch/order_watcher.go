@@ -1,6 +1,7 @@
 package orderwatch
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
@@ -19,10 +20,13 @@ import (
 	"github.com/0xProject/0x-mesh/zeroex"
 	"github.com/0xProject/0x-mesh/zeroex/ordervalidator"
 	"github.com/0xProject/0x-mesh/zeroex/orderwatch/decoder"
+	"github.com/0xProject/0x-mesh/zeroex/orderwatch/mempool"
 	"github.com/0xProject/0x-mesh/zeroex/orderwatch/slowcounter"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/event"
+	peer "github.com/libp2p/go-libp2p-core/peer"
 	logger "github.com/sirupsen/logrus"
 )
 
@@ -84,23 +88,35 @@ type Watcher struct {
 	assetDataDecoder           *zeroex.AssetDataDecoder
 	blockSubscription          event.Subscription
 	blockEventsChan            chan []*blockwatch.Event
+	mempoolWatcher             *mempool.Watcher
 	contractAddresses          ethereum.ContractAddresses
 	expirationWatcher          *expirationwatch.Watcher
 	orderFeed                  event.Feed
 	orderScope                 event.SubscriptionScope // Subscription scope tracking current live listeners
 	contractAddressToSeenCount map[common.Address]uint
 	orderValidator             *ordervalidator.OrderValidator
+	balanceFetcher             *ordervalidator.BalanceFetcher
+	balanceCache               *balanceCache
 	wasStartedOnce             bool
 	mu                         sync.Mutex
 	maxExpirationTime          *big.Int
 	maxExpirationCounter       *slowcounter.SlowCounter
+	maxExpirationTimeV4        *big.Int
+	maxExpirationCounterV4     *slowcounter.SlowCounter
 	maxOrders                  int
+	evictionPolicy             EvictionPolicy
+	orderQuotas                OrderQuotas
+	assetProxies               []zeroex.AssetProxy
 	handleBlockEventsMu        sync.RWMutex
 	// atLeastOneBlockProcessed is closed to signal that the BlockWatcher has processed at least one
 	// block. Validation of orders should block until this has completed
 	atLeastOneBlockProcessed   chan struct{}
 	atLeastOneBlockProcessedMu sync.Mutex
 	didProcessABlock           bool
+	// isEvicting is true while storage is full and orders have been removed
+	// by the eviction policy.
+	isEvictingMu sync.Mutex
+	isEvicting   bool
 }
 
 type Config struct {
@@ -111,6 +127,31 @@ type Config struct {
 	ContractAddresses ethereum.ContractAddresses
 	MaxOrders         int
 	MaxExpirationTime *big.Int
+	// MempoolWatcher is optional. If provided, the Watcher will emit
+	// PENDING_FILL and PENDING_CANCEL events for stored orders that are affected
+	// by pending Exchange transactions.
+	MempoolWatcher *mempool.Watcher
+	// BalanceFetcher is optional. If provided, the Watcher will cache maker
+	// balances and allowances and use them to re-validate orders locally when
+	// they are only affected by token events (e.g. Transfer or Approval). Orders
+	// which cannot be re-validated locally are still re-validated by the
+	// OrderValidator.
+	BalanceFetcher *ordervalidator.BalanceFetcher
+	// EvictionPolicy is optional. If provided, it is used to select the orders
+	// which are removed when the number of stored orders exceeds MaxOrders.
+	// While storage is full, unpinned orders which the policy does not admit
+	// are rejected. By default, the orders with the highest expiration times
+	// are removed and MaxExpirationTime is lowered so that orders which would
+	// be removed right away are not added.
+	EvictionPolicy EvictionPolicy
+	// OrderQuotas is optional. If provided, unpinned orders are rejected if
+	// their maker or the peer which sent them already has too many unpinned
+	// orders in storage.
+	OrderQuotas OrderQuotas
+	// AssetProxies is optional. It contains the custom asset proxies whose
+	// asset data should be recognized. They should be the same as the ones
+	// given to the OrderValidator and BalanceFetcher.
+	AssetProxies []zeroex.AssetProxy
 }
 
 // New instantiates a new order watcher
@@ -119,7 +160,10 @@ func New(config Config) (*Watcher, error) {
 	if err != nil {
 		return nil, err
 	}
-	assetDataDecoder := zeroex.NewAssetDataDecoder()
+	assetDataDecoder, err := zeroex.NewAssetDataDecoderWithAssetProxies(config.AssetProxies)
+	if err != nil {
+		return nil, err
+	}
 
 	// Validate config.
 	if config.MaxOrders == 0 {
@@ -143,6 +187,12 @@ func New(config Config) (*Watcher, error) {
 	if err != nil {
 		return nil, err
 	}
+	// V4 orders are trimmed separately from V3 orders, so they have their own
+	// max expiration time.
+	maxExpirationCounterV4, err := slowcounter.New(slowCounterConfig, config.MaxExpirationTime)
+	if err != nil {
+		return nil, err
+	}
 
 	w := &Watcher{
 		meshDB:                     config.MeshDB,
@@ -150,13 +200,21 @@ func New(config Config) (*Watcher, error) {
 		expirationWatcher:          expirationwatch.New(),
 		contractAddressToSeenCount: map[common.Address]uint{},
 		orderValidator:             config.OrderValidator,
+		balanceFetcher:             config.BalanceFetcher,
+		balanceCache:               newBalanceCache(),
 		eventDecoder:               decoder,
 		assetDataDecoder:           assetDataDecoder,
 		contractAddresses:          config.ContractAddresses,
 		maxExpirationTime:          big.NewInt(0).Set(config.MaxExpirationTime),
 		maxExpirationCounter:       maxExpirationCounter,
+		maxExpirationTimeV4:        big.NewInt(0).Set(config.MaxExpirationTime),
+		maxExpirationCounterV4:     maxExpirationCounterV4,
 		maxOrders:                  config.MaxOrders,
+		evictionPolicy:             config.EvictionPolicy,
+		orderQuotas:                config.OrderQuotas,
+		assetProxies:               config.AssetProxies,
 		blockEventsChan:            make(chan []*blockwatch.Event, 100),
+		mempoolWatcher:             config.MempoolWatcher,
 		atLeastOneBlockProcessed:   make(chan struct{}),
 		didProcessABlock:           false,
 	}
@@ -182,6 +240,19 @@ func New(config Config) (*Watcher, error) {
 		}
 	}
 
+	// Exchange V4 orders are only watched if an Exchange Proxy address is
+	// configured.
+	if config.ContractAddresses.ExchangeProxy != constants.NullAddress {
+		w.eventDecoder.AddKnownExchangeV4(config.ContractAddresses.ExchangeProxy)
+		ordersV4 := []*meshdb.OrderV4{}
+		if err := w.meshDB.OrdersV4.FindAll(&ordersV4); err != nil {
+			return nil, err
+		}
+		for _, order := range ordersV4 {
+			w.setupInMemoryOrderStateV4(order.SignedOrder)
+		}
+	}
+
 	return w, nil
 }
 
@@ -231,6 +302,16 @@ func (w *Watcher) Watch(ctx context.Context) error {
 		defer wg.Done()
 		removedCheckerLoopErrChan <- w.removedCheckerLoop(innerCtx)
 	}()
+	// The pending transactions loop is optional and only runs if a mempool
+	// watcher was provided.
+	pendingTransactionsLoopErrChan := make(chan error, 1)
+	if w.mempoolWatcher != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			pendingTransactionsLoopErrChan <- w.pendingTransactionsLoop(innerCtx)
+		}()
+	}
 
 	// If any error channel returns a non-nil error, we cancel the inner context
 	// and return the error. Note that this means we only return the first error
@@ -256,6 +337,11 @@ func (w *Watcher) Watch(ctx context.Context) error {
 			cancel()
 			return err
 		}
+	case err := <-pendingTransactionsLoopErrChan:
+		if err != nil {
+			cancel()
+			return err
+		}
 	}
 
 	// Wait for all goroutines to exit. If we reached here it means we are done
@@ -364,6 +450,61 @@ func (w *Watcher) removedCheckerLoop(ctx context.Context) error {
 	}
 }
 
+// pendingTransactionsLoop emits PENDING_FILL and PENDING_CANCEL order events
+// for any stored orders that are affected by pending Exchange transactions.
+// Stored fillability is never changed here. Orders are only updated once the
+// corresponding transaction is mined and handled in handleBlockEvents.
+func (w *Watcher) pendingTransactionsLoop(ctx context.Context) error {
+	actionsChan := make(chan []*mempool.PendingOrderAction, 100)
+	actionsSubscription := w.mempoolWatcher.Subscribe(actionsChan)
+	defer actionsSubscription.Unsubscribe()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-actionsSubscription.Err():
+			if err != nil {
+				logger.WithFields(logger.Fields{
+					"error": err.Error(),
+				}).Error("pending transactions subscription error encountered")
+			}
+			return nil
+		case actions := <-actionsChan:
+			orderEvents := w.generatePendingOrderEvents(actions)
+			if len(orderEvents) > 0 {
+				w.orderFeed.Send(orderEvents)
+			}
+		}
+	}
+}
+
+func (w *Watcher) generatePendingOrderEvents(actions []*mempool.PendingOrderAction) []*zeroex.OrderEvent {
+	orderEvents := []*zeroex.OrderEvent{}
+	now := time.Now().UTC()
+	for _, action := range actions {
+		order := w.findOrder(action.OrderHash)
+		if order == nil || order.IsRemoved {
+			// We only emit events for orders we are actively watching.
+			continue
+		}
+		logger.WithFields(logger.Fields{
+			"orderHash": action.OrderHash.Hex(),
+			"txHash":    action.TxHash.Hex(),
+			"endState":  action.Kind.EndState(),
+		}).Trace("found pending transaction for order")
+		orderEvents = append(orderEvents, &zeroex.OrderEvent{
+			Timestamp:                now,
+			OrderHash:                order.Hash,
+			SignedOrder:              order.SignedOrder,
+			FillableTakerAssetAmount: order.FillableTakerAssetAmount,
+			EndState:                 action.Kind.EndState(),
+			ContractEvents:           []*zeroex.ContractEvent{},
+		})
+	}
+	return orderEvents
+}
+
 // handleOrderExpirations takes care of generating expired and unexpired order events for orders that do not require re-validation.
 // Since expiry is now done according to block timestamp, we can figure out which orders have expired/unexpired statically. We do not
 // process blocks that require re-validation, since the validation process will already emit the necessary events and we cannot make
@@ -460,6 +601,10 @@ func (w *Watcher) handleBlockEvents(
 	defer func() {
 		_ = ordersColTxn.Discard()
 	}()
+	ordersV4ColTxn := w.meshDB.OrdersV4.OpenTransaction()
+	defer func() {
+		_ = ordersV4ColTxn.Discard()
+	}()
 
 	var previousLatestBlockTimestamp time.Time
 	previousLatestBlock, err := w.meshDB.FindLatestMiniHeader()
@@ -474,6 +619,17 @@ func (w *Watcher) handleBlockEvents(
 	}
 	latestBlockNumber, latestBlockTimestamp := w.getBlockchainState(events)
 
+	// Cached balances, allowances and signatures are only invalidated by the
+	// events we decode. In the case of a block re-org it is simpler and safer to
+	// start over with empty caches.
+	for _, event := range events {
+		if event.Type == blockwatch.Removed {
+			w.balanceCache.clear()
+			w.orderValidator.ClearSignatureCache()
+			break
+		}
+	}
+
 	err = updateBlockHeadersStoredInDB(miniHeadersColTxn, events)
 	if err != nil {
 		return err
@@ -481,6 +637,8 @@ func (w *Watcher) handleBlockEvents(
 
 	orderHashToDBOrder := map[common.Hash]*meshdb.Order{}
 	orderHashToEvents := map[common.Hash][]*zeroex.ContractEvent{}
+	orderHashToDBOrderV4 := map[common.Hash]*meshdb.OrderV4{}
+	orderHashToEventsV4 := map[common.Hash][]*zeroex.ContractEvent{}
 	for _, event := range events {
 		for _, log := range event.BlockHeader.Logs {
 			eventType, err := w.eventDecoder.FindEventType(log)
@@ -511,6 +669,7 @@ func (w *Watcher) handleBlockEvents(
 				Kind:      eventType,
 			}
 			orders := []*meshdb.Order{}
+			ordersV4 := []*meshdb.OrderV4{}
 			switch eventType {
 			case "ERC20TransferEvent":
 				var transferEvent decoder.ERC20TransferEvent
@@ -522,6 +681,8 @@ func (w *Watcher) handleBlockEvents(
 					return err
 				}
 				contractEvent.Parameters = transferEvent
+				w.balanceCache.invalidate(transferEvent.From, log.Address)
+				w.balanceCache.invalidate(transferEvent.To, log.Address)
 				fromOrders, err := w.findOrdersByTokenAddressAndTokenID(transferEvent.From, log.Address, nil)
 				if err != nil {
 					return err
@@ -532,6 +693,16 @@ func (w *Watcher) handleBlockEvents(
 					return err
 				}
 				orders = append(orders, toOrders...)
+				fromOrdersV4, err := w.findOrdersV4ByMakerToken(transferEvent.From, log.Address)
+				if err != nil {
+					return err
+				}
+				ordersV4 = append(ordersV4, fromOrdersV4...)
+				toOrdersV4, err := w.findOrdersV4ByMakerToken(transferEvent.To, log.Address)
+				if err != nil {
+					return err
+				}
+				ordersV4 = append(ordersV4, toOrdersV4...)
 
 			case "ERC20ApprovalEvent":
 				var approvalEvent decoder.ERC20ApprovalEvent
@@ -542,11 +713,21 @@ func (w *Watcher) handleBlockEvents(
 					}
 					return err
 				}
+				// Approvals set to the Exchange Proxy only affect V4 orders
+				if w.isExchangeProxyAddress(approvalEvent.Spender) {
+					contractEvent.Parameters = approvalEvent
+					ordersV4, err = w.findOrdersV4ByMakerToken(approvalEvent.Owner, log.Address)
+					if err != nil {
+						return err
+					}
+					break
+				}
 				// Ignores approvals set to anyone except the AssetProxy
-				if approvalEvent.Spender != w.contractAddresses.ERC20Proxy {
+				if !w.isAssetProxyAddress("ERC20Token", approvalEvent.Spender) {
 					continue
 				}
 				contractEvent.Parameters = approvalEvent
+				w.balanceCache.invalidate(approvalEvent.Owner, log.Address)
 				orders, err = w.findOrdersByTokenAddressAndTokenID(approvalEvent.Owner, log.Address, nil)
 				if err != nil {
 					return err
@@ -562,6 +743,8 @@ func (w *Watcher) handleBlockEvents(
 					return err
 				}
 				contractEvent.Parameters = transferEvent
+				w.balanceCache.invalidate(transferEvent.From, log.Address)
+				w.balanceCache.invalidate(transferEvent.To, log.Address)
 				fromOrders, err := w.findOrdersByTokenAddressAndTokenID(transferEvent.From, log.Address, transferEvent.TokenId)
 				if err != nil {
 					return err
@@ -583,6 +766,7 @@ func (w *Watcher) handleBlockEvents(
 					return err
 				}
 				contractEvent.Parameters = approvalEvent
+				w.balanceCache.invalidate(approvalEvent.Owner, log.Address)
 				orders, err = w.findOrdersByTokenAddressAndTokenID(approvalEvent.Owner, log.Address, approvalEvent.TokenId)
 				if err != nil {
 					return err
@@ -598,10 +782,11 @@ func (w *Watcher) handleBlockEvents(
 					return err
 				}
 				// Ignores approvals set to anyone except the AssetProxy
-				if approvalForAllEvent.Operator != w.contractAddresses.ERC721Proxy {
+				if !w.isAssetProxyAddress("ERC721Token", approvalForAllEvent.Operator) {
 					continue
 				}
 				contractEvent.Parameters = approvalForAllEvent
+				w.balanceCache.invalidate(approvalForAllEvent.Owner, log.Address)
 				orders, err = w.findOrdersByTokenAddressAndTokenID(approvalForAllEvent.Owner, log.Address, nil)
 				if err != nil {
 					return err
@@ -624,6 +809,8 @@ func (w *Watcher) handleBlockEvents(
 				// further. In the future, we might want to special-case this broader approach for the Augur
 				// contract address specifically.
 				contractEvent.Parameters = transferEvent
+				w.balanceCache.invalidate(transferEvent.From, log.Address)
+				w.balanceCache.invalidate(transferEvent.To, log.Address)
 				fromOrders, err := w.findOrdersByTokenAddressAndTokenID(transferEvent.From, log.Address, nil)
 				if err != nil {
 					return err
@@ -645,6 +832,8 @@ func (w *Watcher) handleBlockEvents(
 					return err
 				}
 				contractEvent.Parameters = transferEvent
+				w.balanceCache.invalidate(transferEvent.From, log.Address)
+				w.balanceCache.invalidate(transferEvent.To, log.Address)
 				fromOrders, err := w.findOrdersByTokenAddressAndTokenID(transferEvent.From, log.Address, nil)
 				if err != nil {
 					return err
@@ -666,10 +855,11 @@ func (w *Watcher) handleBlockEvents(
 					return err
 				}
 				// Ignores approvals set to anyone except the AssetProxy
-				if approvalForAllEvent.Operator != w.contractAddresses.ERC1155Proxy {
+				if !w.isAssetProxyAddress("ERC1155Assets", approvalForAllEvent.Operator) {
 					continue
 				}
 				contractEvent.Parameters = approvalForAllEvent
+				w.balanceCache.invalidate(approvalForAllEvent.Owner, log.Address)
 				orders, err = w.findOrdersByTokenAddressAndTokenID(approvalForAllEvent.Owner, log.Address, nil)
 				if err != nil {
 					return err
@@ -685,10 +875,15 @@ func (w *Watcher) handleBlockEvents(
 					return err
 				}
 				contractEvent.Parameters = withdrawalEvent
+				w.balanceCache.invalidate(withdrawalEvent.Owner, log.Address)
 				orders, err = w.findOrdersByTokenAddressAndTokenID(withdrawalEvent.Owner, log.Address, nil)
 				if err != nil {
 					return err
 				}
+				ordersV4, err = w.findOrdersV4ByMakerToken(withdrawalEvent.Owner, log.Address)
+				if err != nil {
+					return err
+				}
 
 			case "WethDepositEvent":
 				var depositEvent decoder.WethDepositEvent
@@ -700,10 +895,15 @@ func (w *Watcher) handleBlockEvents(
 					return err
 				}
 				contractEvent.Parameters = depositEvent
+				w.balanceCache.invalidate(depositEvent.Owner, log.Address)
 				orders, err = w.findOrdersByTokenAddressAndTokenID(depositEvent.Owner, log.Address, nil)
 				if err != nil {
 					return err
 				}
+				ordersV4, err = w.findOrdersV4ByMakerToken(depositEvent.Owner, log.Address)
+				if err != nil {
+					return err
+				}
 
 			case "ExchangeFillEvent":
 				var exchangeFillEvent decoder.ExchangeFillEvent
@@ -755,6 +955,113 @@ func (w *Watcher) handleBlockEvents(
 				}
 				orders = append(orders, cancelledOrders...)
 
+			case "ExchangeSignatureValidatorApprovalEvent":
+				var signatureValidatorApprovalEvent decoder.ExchangeSignatureValidatorApprovalEvent
+				err = w.eventDecoder.Decode(log, &signatureValidatorApprovalEvent)
+				if err != nil {
+					if isNonCritical := w.checkDecodeErr(err, eventType); isNonCritical {
+						continue
+					}
+					return err
+				}
+				contractEvent.Parameters = signatureValidatorApprovalEvent
+				w.orderValidator.InvalidateSignatures(signatureValidatorApprovalEvent.SignerAddress)
+				makerOrders, err := w.meshDB.FindOrdersByMakerAddress(signatureValidatorApprovalEvent.SignerAddress)
+				if err != nil {
+					logger.WithFields(logger.Fields{
+						"error": err.Error(),
+					}).Error("unexpected query error encountered")
+					return err
+				}
+				for _, order := range makerOrders {
+					if usesSignatureValidator(order.SignedOrder, signatureValidatorApprovalEvent.ValidatorAddress) {
+						orders = append(orders, order)
+					}
+				}
+
+			case "ExchangeV4LimitOrderFilledEvent":
+				var limitOrderFilledEvent decoder.ExchangeV4LimitOrderFilledEvent
+				err = w.eventDecoder.Decode(log, &limitOrderFilledEvent)
+				if err != nil {
+					if isNonCritical := w.checkDecodeErr(err, eventType); isNonCritical {
+						continue
+					}
+					return err
+				}
+				contractEvent.Parameters = limitOrderFilledEvent
+				order := w.findOrderV4(limitOrderFilledEvent.OrderHash)
+				if order != nil {
+					ordersV4 = append(ordersV4, order)
+				}
+
+			case "ExchangeV4RfqOrderFilledEvent":
+				var rfqOrderFilledEvent decoder.ExchangeV4RfqOrderFilledEvent
+				err = w.eventDecoder.Decode(log, &rfqOrderFilledEvent)
+				if err != nil {
+					if isNonCritical := w.checkDecodeErr(err, eventType); isNonCritical {
+						continue
+					}
+					return err
+				}
+				contractEvent.Parameters = rfqOrderFilledEvent
+				order := w.findOrderV4(rfqOrderFilledEvent.OrderHash)
+				if order != nil {
+					ordersV4 = append(ordersV4, order)
+				}
+
+			case "ExchangeV4OrderCancelledEvent":
+				var orderCancelledEvent decoder.ExchangeV4OrderCancelledEvent
+				err = w.eventDecoder.Decode(log, &orderCancelledEvent)
+				if err != nil {
+					if isNonCritical := w.checkDecodeErr(err, eventType); isNonCritical {
+						continue
+					}
+					return err
+				}
+				contractEvent.Parameters = orderCancelledEvent
+				order := w.findOrderV4(orderCancelledEvent.OrderHash)
+				if order != nil {
+					ordersV4 = append(ordersV4, order)
+				}
+
+			case "ExchangeV4PairCancelledLimitOrdersEvent":
+				var pairCancelledEvent decoder.ExchangeV4PairCancelledLimitOrdersEvent
+				err = w.eventDecoder.Decode(log, &pairCancelledEvent)
+				if err != nil {
+					if isNonCritical := w.checkDecodeErr(err, eventType); isNonCritical {
+						continue
+					}
+					return err
+				}
+				contractEvent.Parameters = pairCancelledEvent
+				// Pair cancellations only affect orders of one type, but we re-validate
+				// all orders for the pair since they are cheap to look up.
+				ordersV4, err = w.meshDB.FindOrdersV4ByMakerAddressAndPair(pairCancelledEvent.Maker, pairCancelledEvent.MakerToken, pairCancelledEvent.TakerToken)
+				if err != nil {
+					logger.WithFields(logger.Fields{
+						"error": err.Error(),
+					}).Error("unexpected query error encountered")
+					return err
+				}
+
+			case "ExchangeV4PairCancelledRfqOrdersEvent":
+				var pairCancelledEvent decoder.ExchangeV4PairCancelledRfqOrdersEvent
+				err = w.eventDecoder.Decode(log, &pairCancelledEvent)
+				if err != nil {
+					if isNonCritical := w.checkDecodeErr(err, eventType); isNonCritical {
+						continue
+					}
+					return err
+				}
+				contractEvent.Parameters = pairCancelledEvent
+				ordersV4, err = w.meshDB.FindOrdersV4ByMakerAddressAndPair(pairCancelledEvent.Maker, pairCancelledEvent.MakerToken, pairCancelledEvent.TakerToken)
+				if err != nil {
+					logger.WithFields(logger.Fields{
+						"error": err.Error(),
+					}).Error("unexpected query error encountered")
+					return err
+				}
+
 			default:
 				logger.WithFields(logger.Fields{
 					"eventType": eventType,
@@ -770,6 +1077,10 @@ func (w *Watcher) handleBlockEvents(
 					orderHashToEvents[order.Hash] = append(orderHashToEvents[order.Hash], contractEvent)
 				}
 			}
+			for _, order := range ordersV4 {
+				orderHashToDBOrderV4[order.Hash] = order
+				orderHashToEventsV4[order.Hash] = append(orderHashToEventsV4[order.Hash], contractEvent)
+			}
 		}
 	}
 
@@ -785,6 +1096,14 @@ func (w *Watcher) handleBlockEvents(
 	if err != nil {
 		return err
 	}
+	expirationOrderEventsV4, err := w.handleOrderExpirationsV4(ordersV4ColTxn, latestBlockTimestamp, previousLatestBlockTimestamp, orderHashToDBOrderV4)
+	if err != nil {
+		return err
+	}
+	postValidationOrderEventsV4, err := w.generateOrderEventsV4IfChanged(ctx, ordersV4ColTxn, orderHashToDBOrderV4, orderHashToEventsV4, latestBlockNumber, latestBlockTimestamp)
+	if err != nil {
+		return err
+	}
 
 	if err := ordersColTxn.Commit(); err != nil {
 		logger.WithFields(logger.Fields{
@@ -792,6 +1111,12 @@ func (w *Watcher) handleBlockEvents(
 		}).Error("Failed to commit orders collection transaction")
 		return err
 	}
+	if err := ordersV4ColTxn.Commit(); err != nil {
+		logger.WithFields(logger.Fields{
+			"error": err.Error(),
+		}).Error("Failed to commit V4 orders collection transaction")
+		return err
+	}
 	if err := miniHeadersColTxn.Commit(); err != nil {
 		logger.WithFields(logger.Fields{
 			"error": err.Error(),
@@ -800,6 +1125,8 @@ func (w *Watcher) handleBlockEvents(
 	}
 
 	orderEvents := append(expirationOrderEvents, postValidationOrderEvents...)
+	orderEvents = append(orderEvents, expirationOrderEventsV4...)
+	orderEvents = append(orderEvents, postValidationOrderEventsV4...)
 	if len(orderEvents) > 0 {
 		w.orderFeed.Send(orderEvents)
 	}
@@ -828,6 +1155,11 @@ func (w *Watcher) Cleanup(ctx context.Context, lastUpdatedBuffer time.Duration)
 	w.handleBlockEventsMu.RLock()
 	defer w.handleBlockEventsMu.RUnlock()
 
+	// Start with empty balance and signature caches so that we don't miss any
+	// balance or wallet changes which were not accompanied by an event.
+	w.balanceCache.clear()
+	w.orderValidator.ClearSignatureCache()
+
 	ordersColTxn := w.meshDB.Orders.OpenTransaction()
 	defer func() {
 		_ = ordersColTxn.Discard()
@@ -871,6 +1203,12 @@ func (w *Watcher) Cleanup(ctx context.Context, lastUpdatedBuffer time.Duration)
 		}).Error("Failed to commit orders collection transaction")
 	}
 
+	orderEventsV4, err := w.cleanupV4(ctx, lastUpdatedCutOff, latestBlock.Number, latestBlock.Timestamp)
+	if err != nil {
+		return err
+	}
+	orderEvents = append(orderEvents, orderEventsV4...)
+
 	if len(orderEvents) > 0 {
 		w.orderFeed.Send(orderEvents)
 	}
@@ -893,7 +1231,7 @@ func (w *Watcher) permanentlyDeleteStaleRemovedOrders(ctx context.Context) error
 		}
 	}
 
-	return nil
+	return w.permanentlyDeleteStaleRemovedOrdersV4()
 }
 
 // add adds a 0x order to the DB and watches it for changes in fillability. It
@@ -901,7 +1239,7 @@ func (w *Watcher) permanentlyDeleteStaleRemovedOrders(ctx context.Context) error
 // true, the orders will be marked as pinned. Pinned orders will not be affected
 // by any DDoS prevention or incentive mechanisms and will always stay in
 // storage until they are no longer fillable.
-func (w *Watcher) add(orderInfos []*ordervalidator.AcceptedOrderInfo, validationBlockNumber *big.Int, pinned bool) ([]*zeroex.OrderEvent, error) {
+func (w *Watcher) add(orderInfos []*ordervalidator.AcceptedOrderInfo, validationBlockNumber *big.Int, pinned bool, orderHashToSender map[common.Hash]string) ([]*zeroex.OrderEvent, error) {
 	orderEvents, err := w.decreaseMaxExpirationTimeIfNeeded()
 	if err != nil {
 		return orderEvents, err
@@ -923,9 +1261,11 @@ func (w *Watcher) add(orderInfos []*ordervalidator.AcceptedOrderInfo, validation
 			Hash:                     orderInfo.OrderHash,
 			SignedOrder:              orderInfo.SignedOrder,
 			LastUpdated:              now,
+			AddedAt:                  now,
 			FillableTakerAssetAmount: orderInfo.FillableTakerAssetAmount,
 			IsRemoved:                false,
 			IsPinned:                 pinned,
+			ReceivedFrom:             orderHashToSender[orderInfo.OrderHash],
 		}
 		// Final expiration time check before inserting the order. We might have just
 		// changed max expiration time above.
@@ -1006,15 +1346,54 @@ func (w *Watcher) trimOrdersAndGenerateEvents() ([]*zeroex.OrderEvent, error) {
 	orderEvents := []*zeroex.OrderEvent{}
 
 	targetMaxOrders := int(maxOrdersTrimRatio * float64(w.maxOrders))
-	newMaxExpirationTime, removedOrders, err := w.meshDB.TrimOrdersByExpirationTime(targetMaxOrders)
+	// V3 and V4 orders share the storage limit. Each collection is trimmed in
+	// proportion to the number of orders it currently holds.
+	numV3Orders, err := w.meshDB.Orders.Count()
+	if err != nil {
+		return orderEvents, err
+	}
+	numV4Orders, err := w.meshDB.OrdersV4.Count()
+	if err != nil {
+		return orderEvents, err
+	}
+	targetMaxV4Orders := 0
+	if numV4Orders > 0 {
+		targetMaxV4Orders = int(int64(targetMaxOrders) * int64(numV4Orders) / int64(numV3Orders+numV4Orders))
+		// Pinned V4 orders cannot be removed, so any part of the V4 share which
+		// they take up is trimmed from the V3 orders instead.
+		numPinnedV4Orders, err := w.meshDB.CountPinnedOrdersV4()
+		if err != nil {
+			return orderEvents, err
+		}
+		if numPinnedV4Orders > targetMaxV4Orders {
+			targetMaxV4Orders = numPinnedV4Orders
+		}
+	}
+	targetMaxV3Orders := targetMaxOrders - targetMaxV4Orders
+	if targetMaxV3Orders < 0 {
+		targetMaxV3Orders = 0
+	}
+
+	var newMaxExpirationTime *big.Int
+	var removedOrders []*meshdb.Order
+	if w.evictionPolicy != nil {
+		// Orders are removed according to the eviction policy, so there is no
+		// reason to reject incoming orders based on their expiration time.
+		removedOrders, err = w.meshDB.TrimOrders(targetMaxV3Orders, w.evictionPolicy.SelectOrdersToEvict)
+	} else {
+		newMaxExpirationTime, removedOrders, err = w.meshDB.TrimOrdersByExpirationTime(targetMaxV3Orders)
+	}
 	if err != nil {
 		return orderEvents, err
 	}
 	if len(removedOrders) > 0 {
 		logger.WithFields(logger.Fields{
 			"numOrdersRemoved": len(removedOrders),
-			"targetMaxOrders":  targetMaxOrders,
+			"targetMaxOrders":  targetMaxV3Orders,
 		}).Debug("removing orders to make space")
+		if w.evictionPolicy != nil {
+			w.setIsEvicting(true)
+		}
 	}
 	now := time.Now().UTC()
 	for _, removedOrder := range removedOrders {
@@ -1042,7 +1421,7 @@ func (w *Watcher) trimOrdersAndGenerateEvents() ([]*zeroex.OrderEvent, error) {
 			return orderEvents, err
 		}
 	}
-	if newMaxExpirationTime.Cmp(w.maxExpirationTime) == -1 {
+	if newMaxExpirationTime != nil && newMaxExpirationTime.Cmp(w.maxExpirationTime) == -1 {
 		// Decrease the max expiration time to account for the fact that orders were
 		// removed.
 		logger.WithFields(logger.Fields{
@@ -1054,6 +1433,42 @@ func (w *Watcher) trimOrdersAndGenerateEvents() ([]*zeroex.OrderEvent, error) {
 		w.saveMaxExpirationTime(newMaxExpirationTime)
 	}
 
+	// Eviction policies only apply to V3 orders, so V4 orders are always
+	// removed by expiration time. The V3 orders removed above have already been
+	// deleted, so an error here is logged instead of returned to make sure that
+	// their events are still emitted.
+	newMaxExpirationTimeV4, removedOrdersV4, err := w.meshDB.TrimOrdersV4ByExpirationTime(targetMaxV4Orders)
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"error":           err.Error(),
+			"targetMaxOrders": targetMaxV4Orders,
+		}).Error("could not trim V4 orders")
+	}
+	if len(removedOrdersV4) > 0 {
+		logger.WithFields(logger.Fields{
+			"numOrdersV4Removed": len(removedOrdersV4),
+			"targetMaxOrders":    targetMaxV4Orders,
+		}).Debug("removing V4 orders to make space")
+	}
+	for _, removedOrder := range removedOrdersV4 {
+		orderEvents = append(orderEvents, &zeroex.OrderEvent{
+			Timestamp:                now,
+			OrderHash:                removedOrder.Hash,
+			SignedOrderV4:            removedOrder.SignedOrder,
+			FillableTakerAssetAmount: removedOrder.FillableTakerAssetAmount,
+			EndState:                 zeroex.ESStoppedWatching,
+		})
+		w.removeInMemoryOrderStateV4(removedOrder.SignedOrder)
+	}
+	if newMaxExpirationTimeV4 != nil && newMaxExpirationTimeV4.Cmp(w.maxExpirationTimeV4) == -1 {
+		logger.WithFields(logger.Fields{
+			"oldMaxExpirationTime": w.maxExpirationTimeV4.String(),
+			"newMaxExpirationTime": newMaxExpirationTimeV4.String(),
+		}).Debug("decreasing max expiration time for V4 orders")
+		w.maxExpirationTimeV4 = newMaxExpirationTimeV4
+		w.maxExpirationCounterV4.Reset(newMaxExpirationTimeV4)
+	}
+
 	return orderEvents, nil
 }
 
@@ -1119,6 +1534,12 @@ func (w *Watcher) MaxExpirationTime() *big.Int {
 	return w.maxExpirationTime
 }
 
+// MaxExpirationTimeV4 returns the current maximum expiry for incoming V4
+// orders.
+func (w *Watcher) MaxExpirationTimeV4() *big.Int {
+	return w.maxExpirationTimeV4
+}
+
 func (w *Watcher) setupInMemoryOrderState(signedOrder *zeroex.SignedOrder) error {
 	orderHash, err := signedOrder.ComputeOrderHash()
 	if err != nil {
@@ -1356,6 +1777,10 @@ func (w *Watcher) generateOrderEventsIfChanged(
 	validationBlockTimestamp time.Time,
 ) ([]*zeroex.OrderEvent, error) {
 	signedOrders := []*zeroex.SignedOrder{}
+	validationResults := &ordervalidator.ValidationResults{
+		Accepted: []*ordervalidator.AcceptedOrderInfo{},
+		Rejected: []*ordervalidator.RejectedOrderInfo{},
+	}
 	for _, order := range orderHashToDBOrder {
 		if order.IsRemoved && time.Since(order.LastUpdated) > permanentlyDeleteAfter {
 			if err := w.permanentlyDeleteOrder(ordersColTxn, order); err != nil {
@@ -1363,23 +1788,252 @@ func (w *Watcher) generateOrderEventsIfChanged(
 			}
 			continue
 		}
+		if acceptedOrderInfo, rejectedOrderInfo, ok := w.validateOrderLocally(ctx, order, orderHashToEvents[order.Hash], validationBlockNumber); ok {
+			if acceptedOrderInfo != nil {
+				validationResults.Accepted = append(validationResults.Accepted, acceptedOrderInfo)
+			} else {
+				validationResults.Rejected = append(validationResults.Rejected, rejectedOrderInfo)
+			}
+			continue
+		}
 		signedOrders = append(signedOrders, order.SignedOrder)
 	}
-	if len(signedOrders) == 0 {
+	if len(signedOrders) == 0 && len(validationResults.Accepted) == 0 && len(validationResults.Rejected) == 0 {
 		return nil, nil
 	}
-	areNewOrders := false
-	validationResults := w.orderValidator.BatchValidate(ctx, signedOrders, areNewOrders, validationBlockNumber)
+	if len(signedOrders) > 0 {
+		areNewOrders := false
+		onchainValidationResults := w.orderValidator.BatchValidate(ctx, signedOrders, areNewOrders, validationBlockNumber)
+		validationResults.Accepted = append(validationResults.Accepted, onchainValidationResults.Accepted...)
+		validationResults.Rejected = append(validationResults.Rejected, onchainValidationResults.Rejected...)
+	}
 
 	return w.convertValidationResultsIntoOrderEvents(
 		ordersColTxn, validationResults, orderHashToDBOrder, orderHashToEvents, validationBlockTimestamp,
 	)
 }
 
+// validateOrderLocally attempts to re-validate an order which is already being
+// watched without going through the OrderValidator, using cached balances and
+// allowances instead. This is only possible if the order was affected
+// exclusively by token events (e.g. Transfer or Approval) since these cannot
+// change the order's status or filled amount, and if the filled amount of the
+// order is known. If the order could be validated locally, either an
+// AcceptedOrderInfo with the new fillable amount or a RejectedOrderInfo is
+// returned along with true. Otherwise, it returns false and the order must be
+// validated by the OrderValidator.
+func (w *Watcher) validateOrderLocally(ctx context.Context, order *meshdb.Order, contractEvents []*zeroex.ContractEvent, validationBlockNumber *big.Int) (*ordervalidator.AcceptedOrderInfo, *ordervalidator.RejectedOrderInfo, bool) {
+	if w.balanceFetcher == nil || order.IsRemoved || order.FillableTakerAssetAmount.Sign() == 0 {
+		return nil, nil, false
+	}
+	// Orders without any events are being re-validated by the cleanup job and
+	// should always go through the OrderValidator.
+	if len(contractEvents) == 0 {
+		return nil, nil, false
+	}
+	for _, contractEvent := range contractEvents {
+		if !isBalanceOrAllowanceEvent(contractEvent.Kind) {
+			return nil, nil, false
+		}
+	}
+	// The validity of wallet, validator and EIP1271 signatures depends on
+	// contract state, so we can only skip checking signatures which are purely
+	// cryptographic or which are cached as valid.
+	signedOrder := order.SignedOrder
+	if len(signedOrder.Signature) == 0 {
+		return nil, nil, false
+	}
+	// The filled amount is not stored, but if the order is fillable for its
+	// entire taker asset amount, it cannot have been filled at all. Otherwise
+	// the fillable amount may be limited by the maker's funds rather than by
+	// previous fills, and the filled amount cannot be derived from it.
+	if order.FillableTakerAssetAmount.Cmp(signedOrder.TakerAssetAmount) != 0 {
+		return nil, nil, false
+	}
+	switch zeroex.SignatureType(signedOrder.Signature[len(signedOrder.Signature)-1]) {
+	case zeroex.EIP712Signature, zeroex.EthSignSignature:
+	default:
+		if !w.orderValidator.HasCachedValidSignature(signedOrder, order.Hash) {
+			return nil, nil, false
+		}
+	}
+
+	transferableMakerAssetAmount, ok := w.getTransferableAssetAmount(ctx, signedOrder.MakerAddress, signedOrder.MakerAssetData, validationBlockNumber)
+	if !ok {
+		return nil, nil, false
+	}
+	transferableMakerFeeAssetAmount := big.NewInt(0)
+	if signedOrder.MakerFee.Sign() > 0 && len(signedOrder.MakerFeeAssetData) > 0 && !bytes.Equal(signedOrder.MakerAssetData, signedOrder.MakerFeeAssetData) {
+		transferableMakerFeeAssetAmount, ok = w.getTransferableAssetAmount(ctx, signedOrder.MakerAddress, signedOrder.MakerFeeAssetData, validationBlockNumber)
+		if !ok {
+			return nil, nil, false
+		}
+	}
+
+	filledAmount := big.NewInt(0)
+	fillableTakerAssetAmount := ordervalidator.ComputeFillableTakerAssetAmount(&signedOrder.Order, filledAmount, transferableMakerAssetAmount, transferableMakerFeeAssetAmount)
+	if fillableTakerAssetAmount.Sign() == 0 {
+		return nil, &ordervalidator.RejectedOrderInfo{
+			OrderHash:   order.Hash,
+			SignedOrder: signedOrder,
+			Kind:        ordervalidator.ZeroExValidation,
+			Status:      ordervalidator.ROUnfunded,
+		}, true
+	}
+	return &ordervalidator.AcceptedOrderInfo{
+		OrderHash:                order.Hash,
+		SignedOrder:              signedOrder,
+		FillableTakerAssetAmount: fillableTakerAssetAmount,
+		IsNew:                    false,
+	}, nil, true
+}
+
+// getTransferableAssetAmount returns the transferable amount of the given asset
+// for owner, either from the balance cache or by fetching it at the given block
+// number. It returns false if the asset data is not supported by the cache or
+// the amount could not be fetched.
+func (w *Watcher) getTransferableAssetAmount(ctx context.Context, owner common.Address, assetData []byte, blockNumber *big.Int) (*big.Int, bool) {
+	tokenAddress, ok := w.getCacheableTokenAddress(assetData)
+	if !ok {
+		return nil, false
+	}
+	if amount, found := w.balanceCache.get(owner, tokenAddress, assetData); found {
+		return amount, true
+	}
+	opts := &bind.CallOpts{
+		// HACK(albrow): From field should not be required for eth_call but
+		// including it here is a workaround for a bug in Ganache. Removing
+		// this line causes Ganache to crash.
+		From:        constants.GanacheDummyERC721TokenAddress,
+		Pending:     false,
+		Context:     ctx,
+		BlockNumber: blockNumber,
+	}
+	amount, err := w.balanceFetcher.GetTransferableAssetAmount(opts, owner, assetData)
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"error":     err.Error(),
+			"owner":     owner.Hex(),
+			"assetData": common.Bytes2Hex(assetData),
+		}).Debug("could not fetch transferable asset amount")
+		return nil, false
+	}
+	w.balanceCache.set(owner, tokenAddress, assetData, amount)
+	return amount, true
+}
+
+// getCacheableTokenAddress returns the address of the token contract for the
+// given asset data if its balance and allowance can be cached. Only asset data
+// for a single ERC20, ERC721 or ERC1155 token contract can be cached, since
+// those are the only assets for which we decode the events needed for cache
+// invalidation.
+func (w *Watcher) getCacheableTokenAddress(assetData []byte) (common.Address, bool) {
+	assetDataName, err := w.assetDataDecoder.GetName(assetData)
+	if err != nil {
+		return common.Address{}, false
+	}
+	switch assetDataName {
+	case "ERC20Token":
+		var decodedAssetData zeroex.ERC20AssetData
+		if err := w.assetDataDecoder.Decode(assetData, &decodedAssetData); err != nil {
+			return common.Address{}, false
+		}
+		return decodedAssetData.Address, true
+	case "ERC721Token":
+		var decodedAssetData zeroex.ERC721AssetData
+		if err := w.assetDataDecoder.Decode(assetData, &decodedAssetData); err != nil {
+			return common.Address{}, false
+		}
+		return decodedAssetData.Address, true
+	case "ERC1155Assets":
+		var decodedAssetData zeroex.ERC1155AssetData
+		if err := w.assetDataDecoder.Decode(assetData, &decodedAssetData); err != nil {
+			return common.Address{}, false
+		}
+		return decodedAssetData.Address, true
+	default:
+		return common.Address{}, false
+	}
+}
+
+// isBalanceOrAllowanceEvent returns true if the given kind of contract event
+// can only affect balances or asset proxy allowances.
+func isBalanceOrAllowanceEvent(kind string) bool {
+	switch kind {
+	case "ERC20TransferEvent", "ERC20ApprovalEvent",
+		"ERC721TransferEvent", "ERC721ApprovalEvent", "ERC721ApprovalForAllEvent",
+		"ERC1155TransferSingleEvent", "ERC1155TransferBatchEvent", "ERC1155ApprovalForAllEvent",
+		"WethDepositEvent", "WethWithdrawalEvent":
+		return true
+	default:
+		return false
+	}
+}
+
+// isAssetProxyAddress returns true if address is the built-in asset proxy for
+// the given token standard or a custom asset proxy for it.
+func (w *Watcher) isAssetProxyAddress(tokenStandard string, address common.Address) bool {
+	switch tokenStandard {
+	case "ERC20Token":
+		if address == w.contractAddresses.ERC20Proxy {
+			return true
+		}
+	case "ERC721Token":
+		if address == w.contractAddresses.ERC721Proxy {
+			return true
+		}
+	case "ERC1155Assets":
+		if address == w.contractAddresses.ERC1155Proxy {
+			return true
+		}
+	}
+	for _, proxy := range w.assetProxies {
+		if proxy.TokenStandard == tokenStandard && proxy.Address == address {
+			return true
+		}
+	}
+	return false
+}
+
+// usesSignatureValidator returns true if the order has a Validator signature
+// which is checked by the given validator contract. Validator signatures are in
+// the [signature || validatorAddress || type] format.
+func usesSignatureValidator(signedOrder *zeroex.SignedOrder, validatorAddress common.Address) bool {
+	signature := signedOrder.Signature
+	if len(signature) < 21 || zeroex.SignatureType(signature[len(signature)-1]) != zeroex.ValidatorSignature {
+		return false
+	}
+	return common.BytesToAddress(signature[len(signature)-21:len(signature)-1]) == validatorAddress
+}
+
 // ValidateAndStoreValidOrders applies general 0x validation and Mesh-specific validation to
 // the given orders and if they are valid, adds them to the OrderWatcher
 func (w *Watcher) ValidateAndStoreValidOrders(ctx context.Context, orders []*zeroex.SignedOrder, pinned bool, chainID int) (*ordervalidator.ValidationResults, error) {
-	results, validMeshOrders, err := w.meshSpecificOrderValidation(orders, chainID)
+	return w.validateAndStoreValidOrders(ctx, orders, nil, pinned, chainID)
+}
+
+// ValidateAndStoreValidOrdersFromPeers is like ValidateAndStoreValidOrders
+// but is used for unpinned orders which were received from other peers.
+// senderPeerIDs must contain the ID of the peer which sent each order, in the
+// same order as orders. The orders count towards the quotas of the senders.
+func (w *Watcher) ValidateAndStoreValidOrdersFromPeers(ctx context.Context, orders []*zeroex.SignedOrder, senderPeerIDs []peer.ID, chainID int) (*ordervalidator.ValidationResults, error) {
+	if len(senderPeerIDs) != len(orders) {
+		return nil, fmt.Errorf("expected %d sender peer IDs but got %d", len(orders), len(senderPeerIDs))
+	}
+	senders := make([]string, len(senderPeerIDs))
+	for i, senderPeerID := range senderPeerIDs {
+		senders[i] = senderPeerID.Pretty()
+	}
+	return w.validateAndStoreValidOrders(ctx, orders, senders, false, chainID)
+}
+
+// OrderQuotas returns the quotas for unpinned orders used by the Watcher.
+func (w *Watcher) OrderQuotas() OrderQuotas {
+	return w.orderQuotas
+}
+
+func (w *Watcher) validateAndStoreValidOrders(ctx context.Context, orders []*zeroex.SignedOrder, senders []string, pinned bool, chainID int) (*ordervalidator.ValidationResults, error) {
+	results, validMeshOrders, orderHashToSender, err := w.meshSpecificOrderValidation(orders, senders, pinned, chainID)
 	if err != nil {
 		return nil, err
 	}
@@ -1407,7 +2061,7 @@ func (w *Watcher) ValidateAndStoreValidOrders(ctx context.Context, orders []*zer
 	// Add the order to the OrderWatcher. This also saves the order in the
 	// database.
 	allOrderEvents := []*zeroex.OrderEvent{}
-	orderEvents, err := w.add(newOrderInfos, validationBlock.Number, pinned)
+	orderEvents, err := w.add(newOrderInfos, validationBlock.Number, pinned, orderHashToSender)
 	if err != nil {
 		return nil, err
 	}
@@ -1452,10 +2106,21 @@ func (w *Watcher) onchainOrderValidation(ctx context.Context, orders []*zeroex.S
 	return validationBlock, zeroexResults, nil
 }
 
-func (w *Watcher) meshSpecificOrderValidation(orders []*zeroex.SignedOrder, chainID int) (*ordervalidator.ValidationResults, []*zeroex.SignedOrder, error) {
+// meshSpecificOrderValidation applies the Mesh-specific validation rules to
+// the given orders. senders is either nil or contains the ID of the peer which
+// sent each order. It returns the validation results for the orders which were
+// rejected or are already stored, the orders which still need to be validated
+// on-chain, and the sender of each of those orders.
+func (w *Watcher) meshSpecificOrderValidation(orders []*zeroex.SignedOrder, senders []string, pinned bool, chainID int) (*ordervalidator.ValidationResults, []*zeroex.SignedOrder, map[common.Hash]string, error) {
 	results := &ordervalidator.ValidationResults{}
 	validMeshOrders := []*zeroex.SignedOrder{}
-	for _, order := range orders {
+	orderHashToSender := map[common.Hash]string{}
+	var quotaChecker *orderQuotaChecker
+	if !pinned && w.orderQuotas.isEnabled() {
+		quotaChecker = newOrderQuotaChecker(w.meshDB, w.orderQuotas)
+	}
+	isEvicting := w.evictionPolicy != nil && w.getIsEvicting()
+	for i, order := range orders {
 		orderHash, err := order.ComputeOrderHash()
 		if err != nil {
 			logger.WithField("error", err).Error("could not compute order hash")
@@ -1476,6 +2141,15 @@ func (w *Watcher) meshSpecificOrderValidation(orders []*zeroex.SignedOrder, chai
 			})
 			continue
 		}
+		if !pinned && isEvicting && !w.evictionPolicy.AdmitsOrder(order) {
+			results.Rejected = append(results.Rejected, &ordervalidator.RejectedOrderInfo{
+				OrderHash:   orderHash,
+				SignedOrder: order,
+				Kind:        ordervalidator.MeshValidation,
+				Status:      ordervalidator.ROOrderWouldBeEvicted,
+			})
+			continue
+		}
 		// Note(albrow): Orders with a sender address can be canceled or invalidated
 		// off-chain which is difficult to support since we need to prune
 		// canceled/invalidated orders from the database. We can special-case some
@@ -1543,7 +2217,7 @@ func (w *Watcher) meshSpecificOrderValidation(orders []*zeroex.SignedOrder, chai
 		if err != nil {
 			if _, ok := err.(db.NotFoundError); !ok {
 				logger.WithField("error", err).Error("could not check if order was already stored")
-				return nil, nil, err
+				return nil, nil, nil, err
 			}
 			// If the error is a db.NotFoundError, it just means the order is not currently stored in
 			// the database. There's nothing else in the database to check, so we can continue.
@@ -1569,10 +2243,37 @@ func (w *Watcher) meshSpecificOrderValidation(orders []*zeroex.SignedOrder, chai
 			}
 		}
 
+		var sender string
+		if senders != nil {
+			sender = senders[i]
+		}
+		if quotaChecker != nil {
+			// NOTE: Orders are counted before they are validated on-chain, so orders
+			// which turn out to be invalid still count towards the quotas for the
+			// rest of the batch.
+			rejectedStatus, err := quotaChecker.check(order, sender)
+			if err != nil {
+				logger.WithField("error", err).Error("could not check order quotas")
+				return nil, nil, nil, err
+			}
+			if rejectedStatus != nil {
+				results.Rejected = append(results.Rejected, &ordervalidator.RejectedOrderInfo{
+					OrderHash:   orderHash,
+					SignedOrder: order,
+					Kind:        ordervalidator.MeshValidation,
+					Status:      *rejectedStatus,
+				})
+				continue
+			}
+		}
+
+		if sender != "" {
+			orderHashToSender[orderHash] = sender
+		}
 		validMeshOrders = append(validMeshOrders, order)
 	}
 
-	return results, validMeshOrders, nil
+	return results, validMeshOrders, orderHashToSender, nil
 }
 
 func validateOrderSize(order *zeroex.SignedOrder) error {
@@ -1768,6 +2469,7 @@ func (w *Watcher) removeAssetDataAddressFromEventDecoder(assetData []byte) error
 		w.contractAddressToSeenCount[decodedAssetData.Address] = w.contractAddressToSeenCount[decodedAssetData.Address] - 1
 		if w.contractAddressToSeenCount[decodedAssetData.Address] == 0 {
 			w.eventDecoder.RemoveKnownERC20(decodedAssetData.Address)
+			w.balanceCache.invalidateToken(decodedAssetData.Address)
 		}
 	case "ERC721Token":
 		var decodedAssetData zeroex.ERC721AssetData
@@ -1778,6 +2480,7 @@ func (w *Watcher) removeAssetDataAddressFromEventDecoder(assetData []byte) error
 		w.contractAddressToSeenCount[decodedAssetData.Address] = w.contractAddressToSeenCount[decodedAssetData.Address] - 1
 		if w.contractAddressToSeenCount[decodedAssetData.Address] == 0 {
 			w.eventDecoder.RemoveKnownERC721(decodedAssetData.Address)
+			w.balanceCache.invalidateToken(decodedAssetData.Address)
 		}
 	case "ERC1155Assets":
 		var decodedAssetData zeroex.ERC1155AssetData
@@ -1788,6 +2491,7 @@ func (w *Watcher) removeAssetDataAddressFromEventDecoder(assetData []byte) error
 		w.contractAddressToSeenCount[decodedAssetData.Address] = w.contractAddressToSeenCount[decodedAssetData.Address] - 1
 		if w.contractAddressToSeenCount[decodedAssetData.Address] == 0 {
 			w.eventDecoder.RemoveKnownERC1155(decodedAssetData.Address)
+			w.balanceCache.invalidateToken(decodedAssetData.Address)
 		}
 	case "StaticCall":
 		var decodedAssetData zeroex.StaticCallAssetData
@@ -1815,9 +2519,23 @@ func (w *Watcher) removeAssetDataAddressFromEventDecoder(assetData []byte) error
 	return nil
 }
 
+// countStoredOrders returns the number of V3 and V4 orders in the database,
+// all of which count towards maxOrders.
+func (w *Watcher) countStoredOrders() (int, error) {
+	numV3Orders, err := w.meshDB.Orders.Count()
+	if err != nil {
+		return 0, err
+	}
+	numV4Orders, err := w.meshDB.OrdersV4.Count()
+	if err != nil {
+		return 0, err
+	}
+	return numV3Orders + numV4Orders, nil
+}
+
 func (w *Watcher) decreaseMaxExpirationTimeIfNeeded() ([]*zeroex.OrderEvent, error) {
 	orderEvents := []*zeroex.OrderEvent{}
-	if orderCount, err := w.meshDB.Orders.Count(); err != nil {
+	if orderCount, err := w.countStoredOrders(); err != nil {
 		return orderEvents, err
 	} else if orderCount+1 > w.maxOrders {
 		return w.trimOrdersAndGenerateEvents()
@@ -1826,11 +2544,13 @@ func (w *Watcher) decreaseMaxExpirationTimeIfNeeded() ([]*zeroex.OrderEvent, err
 }
 
 func (w *Watcher) increaseMaxExpirationTimeIfPossible() error {
-	if orderCount, err := w.meshDB.Orders.Count(); err != nil {
+	if orderCount, err := w.countStoredOrders(); err != nil {
 		return err
 	} else if orderCount < w.maxOrders {
-		// We have enough space for new orders. Set the new max expiration time to the
-		// value of slow counter.
+		// We have enough space for new orders, so the eviction policy no longer
+		// needs to reject any.
+		w.setIsEvicting(false)
+		// Set the new max expiration time to the value of slow counter.
 		newMaxExpiration := w.maxExpirationCounter.Count()
 		if w.maxExpirationTime.Cmp(newMaxExpiration) != 0 {
 			logger.WithFields(logger.Fields{
@@ -1840,11 +2560,31 @@ func (w *Watcher) increaseMaxExpirationTimeIfPossible() error {
 			w.maxExpirationTime.Set(newMaxExpiration)
 			w.saveMaxExpirationTime(newMaxExpiration)
 		}
+		newMaxExpirationV4 := w.maxExpirationCounterV4.Count()
+		if w.maxExpirationTimeV4.Cmp(newMaxExpirationV4) != 0 {
+			logger.WithFields(logger.Fields{
+				"oldMaxExpirationTime": w.maxExpirationTimeV4.String(),
+				"newMaxExpirationTime": fmt.Sprint(newMaxExpirationV4),
+			}).Debug("increasing max expiration time for V4 orders")
+			w.maxExpirationTimeV4.Set(newMaxExpirationV4)
+		}
 	}
 
 	return nil
 }
 
+func (w *Watcher) getIsEvicting() bool {
+	w.isEvictingMu.Lock()
+	defer w.isEvictingMu.Unlock()
+	return w.isEvicting
+}
+
+func (w *Watcher) setIsEvicting(isEvicting bool) {
+	w.isEvictingMu.Lock()
+	defer w.isEvictingMu.Unlock()
+	w.isEvicting = isEvicting
+}
+
 // saveMaxExpirationTime saves the new max expiration time in the database.
 func (w *Watcher) saveMaxExpirationTime(maxExpirationTime *big.Int) {
 	if err := w.meshDB.UpdateMetadata(func(metadata meshdb.Metadata) meshdb.Metadata {
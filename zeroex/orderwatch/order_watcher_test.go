@@ -818,6 +818,205 @@ func TestOrderWatcherERC20PartiallyFilled(t *testing.T) {
 	assert.Equal(t, false, orders[0].IsRemoved)
 	assert.Equal(t, halfAmount, orders[0].FillableTakerAssetAmount)
 }
+
+func TestOrderWatcherERC20BalanceDecreased(t *testing.T) {
+	if !serialTestsEnabled {
+		t.Skip("Serial tests (tests which cannot run in parallel) are disabled. You can enable them with the --serial flag")
+	}
+
+	teardownSubTest := setupSubTest(t)
+	defer teardownSubTest(t)
+
+	meshDB, err := meshdb.New("/tmp/leveldb_testing/"+uuid.New().String(), ganacheAddresses)
+	require.NoError(t, err)
+
+	signedOrder := scenario.NewSignedTestOrder(t,
+		orderopts.SetupMakerState(true),
+		orderopts.MakerAssetAmount(big.NewInt(100)),
+		orderopts.TakerAssetAmount(big.NewInt(100)),
+	)
+	ctx, cancelFn := context.WithCancel(context.Background())
+	defer cancelFn()
+	blockWatcher, orderEventsChan := setupOrderWatcherScenario(ctx, t, ethClient, meshDB, signedOrder)
+
+	// Transfer part of the makerAsset out of maker address. The order is now
+	// only partially funded.
+	opts := &bind.TransactOpts{
+		From:   signedOrder.MakerAddress,
+		Signer: scenario.GetTestSignerFn(signedOrder.MakerAddress),
+	}
+	txn, err := zrx.Transfer(opts, constants.GanacheAccount4, big.NewInt(40))
+	require.NoError(t, err)
+	waitTxnSuccessfullyMined(t, ethClient, txn)
+
+	err = blockWatcher.SyncToLatestBlock()
+	require.NoError(t, err)
+
+	orderEvents := waitForOrderEvents(t, orderEventsChan, 1, 4*time.Second)
+	require.Len(t, orderEvents, 1)
+	orderEvent := orderEvents[0]
+	assert.Equal(t, zeroex.ESOrderFilled, orderEvent.EndState)
+	assert.Equal(t, big.NewInt(60), orderEvent.FillableTakerAssetAmount)
+
+	// Transfer more of the makerAsset out of maker address. The order has not
+	// been filled, so it must not be considered unfunded.
+	txn, err = zrx.Transfer(opts, constants.GanacheAccount4, big.NewInt(10))
+	require.NoError(t, err)
+	waitTxnSuccessfullyMined(t, ethClient, txn)
+
+	err = blockWatcher.SyncToLatestBlock()
+	require.NoError(t, err)
+
+	orderEvents = waitForOrderEvents(t, orderEventsChan, 1, 4*time.Second)
+	require.Len(t, orderEvents, 1)
+	orderEvent = orderEvents[0]
+	assert.Equal(t, zeroex.ESOrderFilled, orderEvent.EndState)
+	assert.Equal(t, big.NewInt(50), orderEvent.FillableTakerAssetAmount)
+
+	var orders []*meshdb.Order
+	err = meshDB.Orders.FindAll(&orders)
+	require.NoError(t, err)
+	require.Len(t, orders, 1)
+	assert.Equal(t, orderEvent.OrderHash, orders[0].Hash)
+	assert.Equal(t, false, orders[0].IsRemoved)
+	assert.Equal(t, big.NewInt(50), orders[0].FillableTakerAssetAmount)
+}
+
+func TestOrderWatcherERC20BalanceIncreased(t *testing.T) {
+	if !serialTestsEnabled {
+		t.Skip("Serial tests (tests which cannot run in parallel) are disabled. You can enable them with the --serial flag")
+	}
+
+	teardownSubTest := setupSubTest(t)
+	defer teardownSubTest(t)
+
+	meshDB, err := meshdb.New("/tmp/leveldb_testing/"+uuid.New().String(), ganacheAddresses)
+	require.NoError(t, err)
+
+	signedOrder := scenario.NewSignedTestOrder(t,
+		orderopts.SetupMakerState(true),
+		orderopts.MakerAssetAmount(big.NewInt(100)),
+		orderopts.TakerAssetAmount(big.NewInt(100)),
+	)
+	ctx, cancelFn := context.WithCancel(context.Background())
+	defer cancelFn()
+	blockWatcher, orderEventsChan := setupOrderWatcherScenario(ctx, t, ethClient, meshDB, signedOrder)
+
+	// Transfer part of the makerAsset out of maker address
+	opts := &bind.TransactOpts{
+		From:   signedOrder.MakerAddress,
+		Signer: scenario.GetTestSignerFn(signedOrder.MakerAddress),
+	}
+	txn, err := zrx.Transfer(opts, constants.GanacheAccount4, big.NewInt(40))
+	require.NoError(t, err)
+	waitTxnSuccessfullyMined(t, ethClient, txn)
+
+	err = blockWatcher.SyncToLatestBlock()
+	require.NoError(t, err)
+
+	orderEvents := waitForOrderEvents(t, orderEventsChan, 1, 4*time.Second)
+	require.Len(t, orderEvents, 1)
+	orderEvent := orderEvents[0]
+	assert.Equal(t, zeroex.ESOrderFilled, orderEvent.EndState)
+	assert.Equal(t, big.NewInt(60), orderEvent.FillableTakerAssetAmount)
+
+	// Transfer makerAsset back to maker address
+	zrxCoinbase := constants.GanacheAccount0
+	opts = &bind.TransactOpts{
+		From:   zrxCoinbase,
+		Signer: scenario.GetTestSignerFn(zrxCoinbase),
+	}
+	txn, err = zrx.Transfer(opts, signedOrder.MakerAddress, big.NewInt(40))
+	require.NoError(t, err)
+	waitTxnSuccessfullyMined(t, ethClient, txn)
+
+	err = blockWatcher.SyncToLatestBlock()
+	require.NoError(t, err)
+
+	orderEvents = waitForOrderEvents(t, orderEventsChan, 1, 4*time.Second)
+	require.Len(t, orderEvents, 1)
+	orderEvent = orderEvents[0]
+	assert.Equal(t, zeroex.ESOrderFillabilityIncreased, orderEvent.EndState)
+	assert.Equal(t, signedOrder.TakerAssetAmount, orderEvent.FillableTakerAssetAmount)
+
+	var orders []*meshdb.Order
+	err = meshDB.Orders.FindAll(&orders)
+	require.NoError(t, err)
+	require.Len(t, orders, 1)
+	assert.Equal(t, orderEvent.OrderHash, orders[0].Hash)
+	assert.Equal(t, false, orders[0].IsRemoved)
+	assert.Equal(t, signedOrder.TakerAssetAmount, orders[0].FillableTakerAssetAmount)
+}
+
+func TestOrderWatcherERC20PartiallyFilledThenBalanceDecreased(t *testing.T) {
+	if !serialTestsEnabled {
+		t.Skip("Serial tests (tests which cannot run in parallel) are disabled. You can enable them with the --serial flag")
+	}
+
+	teardownSubTest := setupSubTest(t)
+	defer teardownSubTest(t)
+
+	meshDB, err := meshdb.New("/tmp/leveldb_testing/"+uuid.New().String(), ganacheAddresses)
+	require.NoError(t, err)
+
+	takerAddress := constants.GanacheAccount3
+	signedOrder := scenario.NewSignedTestOrder(t,
+		orderopts.SetupMakerState(true),
+		orderopts.SetupTakerAddress(takerAddress),
+		orderopts.MakerAssetAmount(big.NewInt(100)),
+		orderopts.TakerAssetAmount(big.NewInt(100)),
+	)
+	ctx, cancelFn := context.WithCancel(context.Background())
+	defer cancelFn()
+	blockWatcher, orderEventsChan := setupOrderWatcherScenario(ctx, t, ethClient, meshDB, signedOrder)
+
+	// Partially fill order
+	opts := &bind.TransactOpts{
+		From:   takerAddress,
+		Signer: scenario.GetTestSignerFn(takerAddress),
+		Value:  big.NewInt(100000000000000000),
+	}
+	trimmedOrder := signedOrder.Trim()
+	txn, err := exchange.FillOrder(opts, trimmedOrder, big.NewInt(50), signedOrder.Signature)
+	require.NoError(t, err)
+	waitTxnSuccessfullyMined(t, ethClient, txn)
+
+	err = blockWatcher.SyncToLatestBlock()
+	require.NoError(t, err)
+
+	orderEvents := waitForOrderEvents(t, orderEventsChan, 1, 4*time.Second)
+	require.Len(t, orderEvents, 1)
+	orderEvent := orderEvents[0]
+	assert.Equal(t, zeroex.ESOrderFilled, orderEvent.EndState)
+	assert.Equal(t, big.NewInt(50), orderEvent.FillableTakerAssetAmount)
+
+	// Transfer part of the remaining makerAsset out of maker address
+	opts = &bind.TransactOpts{
+		From:   signedOrder.MakerAddress,
+		Signer: scenario.GetTestSignerFn(signedOrder.MakerAddress),
+	}
+	txn, err = zrx.Transfer(opts, constants.GanacheAccount4, big.NewInt(20))
+	require.NoError(t, err)
+	waitTxnSuccessfullyMined(t, ethClient, txn)
+
+	err = blockWatcher.SyncToLatestBlock()
+	require.NoError(t, err)
+
+	orderEvents = waitForOrderEvents(t, orderEventsChan, 1, 4*time.Second)
+	require.Len(t, orderEvents, 1)
+	orderEvent = orderEvents[0]
+	assert.Equal(t, zeroex.ESOrderFilled, orderEvent.EndState)
+	assert.Equal(t, big.NewInt(30), orderEvent.FillableTakerAssetAmount)
+
+	var orders []*meshdb.Order
+	err = meshDB.Orders.FindAll(&orders)
+	require.NoError(t, err)
+	require.Len(t, orders, 1)
+	assert.Equal(t, orderEvent.OrderHash, orders[0].Hash)
+	assert.Equal(t, false, orders[0].IsRemoved)
+	assert.Equal(t, big.NewInt(30), orders[0].FillableTakerAssetAmount)
+}
+
 func TestOrderWatcherOrderExpiredThenUnexpired(t *testing.T) {
 	if !serialTestsEnabled {
 		t.Skip("Serial tests (tests which cannot run in parallel) are disabled. You can enable them with the --serial flag")
@@ -1648,6 +1847,70 @@ func TestDrainAllBlockEventsChan(t *testing.T) {
 	require.Equal(t, allEvents[0], blockEventsOne[0])
 }
 
+func TestMeshSpecificOrderValidationWithEvictionPolicy(t *testing.T) {
+	meshDB, err := meshdb.New("/tmp/leveldb_testing/"+uuid.New().String(), ganacheAddresses)
+	require.NoError(t, err)
+	defer meshDB.Close()
+
+	newOrder := func(salt int64, makerAddress common.Address) *zeroex.SignedOrder {
+		return &zeroex.SignedOrder{
+			Order: zeroex.Order{
+				ChainID:               big.NewInt(constants.TestChainID),
+				ExchangeAddress:       ganacheAddresses.Exchange,
+				MakerAddress:          makerAddress,
+				MakerAssetData:        common.Hex2Bytes("f47261b0000000000000000000000000871dd7c2b4b25e1aa18728e9d5f2af4c4e431f5c"),
+				MakerFeeAssetData:     constants.NullBytes,
+				TakerAssetData:        common.Hex2Bytes("f47261b000000000000000000000000034d402f14d58e001d8efbe6585051bf9706aa064"),
+				TakerFeeAssetData:     constants.NullBytes,
+				MakerAssetAmount:      big.NewInt(1000),
+				TakerAssetAmount:      big.NewInt(1000),
+				MakerFee:              big.NewInt(0),
+				TakerFee:              big.NewInt(0),
+				Salt:                  big.NewInt(salt),
+				ExpirationTimeSeconds: big.NewInt(100),
+			},
+		}
+	}
+	trustedOrder := newOrder(0, constants.GanacheAccount1)
+	untrustedOrder := newOrder(1, constants.GanacheAccount3)
+
+	policy := NewMakerReputationEvictionPolicy(MakerReputations{
+		constants.GanacheAccount1: 10,
+		constants.GanacheAccount3: -1,
+	})
+	_, err = policy.SelectOrdersToEvict([]*meshdb.Order{
+		{SignedOrder: newOrder(2, constants.GanacheAccount2), FillableTakerAssetAmount: big.NewInt(1000)},
+	}, 1)
+	require.NoError(t, err)
+	w := &Watcher{
+		meshDB:            meshDB,
+		contractAddresses: ganacheAddresses,
+		maxExpirationTime: constants.UnlimitedExpirationTime,
+		evictionPolicy:    policy,
+	}
+	orders := []*zeroex.SignedOrder{trustedOrder, untrustedOrder}
+
+	// Orders are only rejected by the eviction policy while storage is full.
+	results, validOrders, _, err := w.meshSpecificOrderValidation(orders, nil, false, constants.TestChainID)
+	require.NoError(t, err)
+	assert.Empty(t, results.Rejected)
+	assert.Len(t, validOrders, 2)
+
+	w.setIsEvicting(true)
+	results, validOrders, _, err = w.meshSpecificOrderValidation(orders, nil, false, constants.TestChainID)
+	require.NoError(t, err)
+	require.Len(t, results.Rejected, 1)
+	assert.Equal(t, untrustedOrder, results.Rejected[0].SignedOrder)
+	assert.Equal(t, ordervalidator.ROOrderWouldBeEvicted, results.Rejected[0].Status)
+	assert.Equal(t, []*zeroex.SignedOrder{trustedOrder}, validOrders)
+
+	// Pinned orders are never rejected by the eviction policy.
+	results, validOrders, _, err = w.meshSpecificOrderValidation(orders, nil, true, constants.TestChainID)
+	require.NoError(t, err)
+	assert.Empty(t, results.Rejected)
+	assert.Len(t, validOrders, 2)
+}
+
 func setupOrderWatcherScenario(ctx context.Context, t *testing.T, ethClient *ethclient.Client, meshDB *meshdb.MeshDB, signedOrder *zeroex.SignedOrder) (*blockwatch.Watcher, chan []*zeroex.OrderEvent) {
 	blockWatcher, orderWatcher := setupOrderWatcher(ctx, t, ethRPCClient, meshDB)
 
@@ -1688,10 +1951,13 @@ func setupOrderWatcher(ctx context.Context, t *testing.T, ethRPCClient ethrpccli
 	blockWatcher := blockwatch.New(blockWatcherConfig)
 	orderValidator, err := ordervalidator.New(ethRPCClient, constants.TestChainID, ethereumRPCMaxContentLength, ganacheAddresses)
 	require.NoError(t, err)
+	balanceFetcher, err := ordervalidator.NewBalanceFetcher(ethRPCClient, ganacheAddresses, nil)
+	require.NoError(t, err)
 	orderWatcher, err := New(Config{
 		MeshDB:            meshDB,
 		BlockWatcher:      blockWatcher,
 		OrderValidator:    orderValidator,
+		BalanceFetcher:    balanceFetcher,
 		ChainID:           constants.TestChainID,
 		ContractAddresses: ganacheAddresses,
 		MaxExpirationTime: constants.UnlimitedExpirationTime,
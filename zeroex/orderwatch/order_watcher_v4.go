@@ -0,0 +1,584 @@
+package orderwatch
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/0xProject/0x-mesh/constants"
+	"github.com/0xProject/0x-mesh/db"
+	"github.com/0xProject/0x-mesh/meshdb"
+	"github.com/0xProject/0x-mesh/zeroex"
+	"github.com/0xProject/0x-mesh/zeroex/ordervalidator"
+	"github.com/ethereum/go-ethereum/common"
+	logger "github.com/sirupsen/logrus"
+)
+
+// isExchangeProxyAddress returns true if V4 support is enabled and the given
+// address is the Exchange Proxy. Makers of V4 orders set their allowances on
+// the Exchange Proxy directly instead of on an AssetProxy.
+func (w *Watcher) isExchangeProxyAddress(address common.Address) bool {
+	return w.contractAddresses.ExchangeProxy != constants.NullAddress && address == w.contractAddresses.ExchangeProxy
+}
+
+// ValidateAndStoreValidOrdersV4 applies general 0x validation and Mesh-specific
+// validation to the given Exchange V4 orders and if they are valid, adds them
+// to the OrderWatcher. V4 orders are stored alongside V3 orders and count
+// towards the same max orders limit.
+func (w *Watcher) ValidateAndStoreValidOrdersV4(ctx context.Context, orders []*zeroex.SignedOrderV4, pinned bool, chainID int) (*ordervalidator.ValidationResults, error) {
+	results, validMeshOrders, err := w.meshSpecificOrderValidationV4(orders, chainID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Lock down the processing of additional block events until we've validated and added these new orders
+	w.handleBlockEventsMu.RLock()
+	defer w.handleBlockEventsMu.RUnlock()
+
+	// See the comment in onchainOrderValidation for why we validate at the
+	// latest known block number.
+	validationBlock, err := w.meshDB.FindLatestMiniHeader()
+	if err != nil {
+		return nil, err
+	}
+	// This timeout of 1min is for limiting how long this call should block at the ETH RPC rate limiter
+	validationCtx, cancel := context.WithTimeout(ctx, 1*time.Minute)
+	defer cancel()
+	areNewOrders := true
+	zeroexResults := w.orderValidator.BatchValidateV4(validationCtx, validMeshOrders, areNewOrders, validationBlock.Number)
+	results.Accepted = append(results.Accepted, zeroexResults.Accepted...)
+	results.Rejected = append(results.Rejected, zeroexResults.Rejected...)
+
+	newOrderInfos := []*ordervalidator.AcceptedOrderInfo{}
+	for _, acceptedOrderInfo := range results.Accepted {
+		if acceptedOrderInfo.IsNew {
+			newOrderInfos = append(newOrderInfos, acceptedOrderInfo)
+		}
+	}
+	orderEvents, err := w.addV4(newOrderInfos, pinned)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(orderEvents) > 0 {
+		// NOTE(albrow): See ValidateAndStoreValidOrders for why Send is called in a
+		// goroutine.
+		done := make(chan interface{})
+		go func() {
+			w.orderFeed.Send(orderEvents)
+			done <- struct{}{}
+		}()
+		select {
+		case <-done:
+		case <-ctx.Done():
+		}
+	}
+
+	return results, nil
+}
+
+func (w *Watcher) meshSpecificOrderValidationV4(orders []*zeroex.SignedOrderV4, chainID int) (*ordervalidator.ValidationResults, []*zeroex.SignedOrderV4, error) {
+	results := &ordervalidator.ValidationResults{}
+	validMeshOrders := []*zeroex.SignedOrderV4{}
+	for _, order := range orders {
+		orderHash, err := order.ComputeOrderHash()
+		if err != nil {
+			logger.WithField("error", err).Error("could not compute order hash")
+			results.Rejected = append(results.Rejected, &ordervalidator.RejectedOrderInfo{
+				OrderHash:     orderHash,
+				SignedOrderV4: order,
+				Kind:          ordervalidator.MeshError,
+				Status:        ordervalidator.ROInternalError,
+			})
+			continue
+		}
+		if order.Expiry == nil {
+			results.Rejected = append(results.Rejected, &ordervalidator.RejectedOrderInfo{
+				OrderHash:     orderHash,
+				SignedOrderV4: order,
+				Kind:          ordervalidator.ZeroExValidation,
+				Status:        ordervalidator.ROInvalidExpiryV4,
+			})
+			continue
+		}
+		if order.Expiry.Cmp(w.MaxExpirationTimeV4()) == 1 {
+			results.Rejected = append(results.Rejected, &ordervalidator.RejectedOrderInfo{
+				OrderHash:     orderHash,
+				SignedOrderV4: order,
+				Kind:          ordervalidator.MeshValidation,
+				Status:        ordervalidator.ROMaxExpirationExceeded,
+			})
+			continue
+		}
+		if order.ChainID.Cmp(big.NewInt(int64(chainID))) != 0 {
+			results.Rejected = append(results.Rejected, &ordervalidator.RejectedOrderInfo{
+				OrderHash:     orderHash,
+				SignedOrderV4: order,
+				Kind:          ordervalidator.MeshValidation,
+				Status:        ordervalidator.ROIncorrectChain,
+			})
+			continue
+		}
+
+		// Check if order is already stored in DB
+		var dbOrder meshdb.OrderV4
+		err = w.meshDB.OrdersV4.FindByID(orderHash.Bytes(), &dbOrder)
+		if err != nil {
+			if _, ok := err.(db.NotFoundError); !ok {
+				logger.WithField("error", err).Error("could not check if order was already stored")
+				return nil, nil, err
+			}
+		} else {
+			if dbOrder.IsRemoved {
+				results.Rejected = append(results.Rejected, &ordervalidator.RejectedOrderInfo{
+					OrderHash:     orderHash,
+					SignedOrderV4: order,
+					Kind:          ordervalidator.MeshValidation,
+					Status:        ordervalidator.ROOrderAlreadyStoredAndUnfillable,
+				})
+			} else {
+				results.Accepted = append(results.Accepted, &ordervalidator.AcceptedOrderInfo{
+					OrderHash:                orderHash,
+					SignedOrderV4:            order,
+					FillableTakerAssetAmount: dbOrder.FillableTakerAssetAmount,
+					IsNew:                    false,
+				})
+			}
+			continue
+		}
+
+		validMeshOrders = append(validMeshOrders, order)
+	}
+
+	return results, validMeshOrders, nil
+}
+
+// addV4 adds V4 orders to the DB and watches them for changes in fillability.
+// It behaves like add. V4 orders count towards maxOrders and existing V3 or V4
+// orders are removed to make space for them if needed.
+func (w *Watcher) addV4(orderInfos []*ordervalidator.AcceptedOrderInfo, pinned bool) ([]*zeroex.OrderEvent, error) {
+	orderEvents, err := w.decreaseMaxExpirationTimeIfNeeded()
+	if err != nil {
+		return orderEvents, err
+	}
+
+	txn := w.meshDB.OrdersV4.OpenTransaction()
+	defer func() {
+		_ = txn.Discard()
+	}()
+
+	now := time.Now().UTC()
+	addedOrderInfos := []*ordervalidator.AcceptedOrderInfo{}
+	for _, orderInfo := range orderInfos {
+		order := &meshdb.OrderV4{
+			Hash:                     orderInfo.OrderHash,
+			SignedOrder:              orderInfo.SignedOrderV4,
+			LastUpdated:              now,
+			FillableTakerAssetAmount: orderInfo.FillableTakerAssetAmount,
+			IsRemoved:                false,
+			IsPinned:                 pinned,
+		}
+		// Final expiration time check before inserting the order, since we might
+		// have just changed max expiration time above. See add for why we emit
+		// ADDED and STOPPED_WATCHING events in this case.
+		if !pinned && orderInfo.SignedOrderV4.Expiry.Cmp(w.maxExpirationTimeV4) == 1 {
+			orderEvents = append(orderEvents, &zeroex.OrderEvent{
+				Timestamp:                now,
+				OrderHash:                orderInfo.OrderHash,
+				SignedOrderV4:            orderInfo.SignedOrderV4,
+				FillableTakerAssetAmount: orderInfo.FillableTakerAssetAmount,
+				EndState:                 zeroex.ESOrderAdded,
+			}, &zeroex.OrderEvent{
+				Timestamp:                now,
+				OrderHash:                orderInfo.OrderHash,
+				SignedOrderV4:            orderInfo.SignedOrderV4,
+				FillableTakerAssetAmount: orderInfo.FillableTakerAssetAmount,
+				EndState:                 zeroex.ESStoppedWatching,
+			})
+			continue
+		}
+		if err := txn.Insert(order); err != nil {
+			if _, ok := err.(db.AlreadyExistsError); ok {
+				// If we're already watching the order, that's fine in this case.
+				continue
+			}
+			if _, ok := err.(db.ConflictingOperationsError); ok {
+				logger.WithFields(logger.Fields{
+					"error": err.Error(),
+					"order": order,
+				}).Error("Failed to insert V4 order into DB")
+				continue
+			}
+			return orderEvents, err
+		}
+		addedOrderInfos = append(addedOrderInfos, orderInfo)
+	}
+
+	if err := txn.Commit(); err != nil {
+		return orderEvents, err
+	}
+
+	for _, orderInfo := range addedOrderInfos {
+		w.setupInMemoryOrderStateV4(orderInfo.SignedOrderV4)
+		orderEvents = append(orderEvents, &zeroex.OrderEvent{
+			Timestamp:                now,
+			OrderHash:                orderInfo.OrderHash,
+			SignedOrderV4:            orderInfo.SignedOrderV4,
+			FillableTakerAssetAmount: orderInfo.FillableTakerAssetAmount,
+			EndState:                 zeroex.ESOrderAdded,
+		})
+	}
+
+	return orderEvents, nil
+}
+
+// setupInMemoryOrderStateV4 registers the maker token of the given order with
+// the event decoder. V4 orders are not tracked by the expiration watcher.
+// Instead, expired V4 orders are found with a DB query whenever a new block is
+// processed.
+func (w *Watcher) setupInMemoryOrderStateV4(signedOrder *zeroex.SignedOrderV4) {
+	w.eventDecoder.AddKnownERC20(signedOrder.MakerToken)
+	w.contractAddressToSeenCount[signedOrder.MakerToken] = w.contractAddressToSeenCount[signedOrder.MakerToken] + 1
+}
+
+func (w *Watcher) removeInMemoryOrderStateV4(signedOrder *zeroex.SignedOrderV4) {
+	w.contractAddressToSeenCount[signedOrder.MakerToken] = w.contractAddressToSeenCount[signedOrder.MakerToken] - 1
+	if w.contractAddressToSeenCount[signedOrder.MakerToken] == 0 {
+		w.eventDecoder.RemoveKnownERC20(signedOrder.MakerToken)
+		w.balanceCache.invalidateToken(signedOrder.MakerToken)
+	}
+}
+
+func (w *Watcher) findOrderV4(orderHash common.Hash) *meshdb.OrderV4 {
+	order := meshdb.OrderV4{}
+	err := w.meshDB.OrdersV4.FindByID(orderHash.Bytes(), &order)
+	if err != nil {
+		if _, ok := err.(db.NotFoundError); ok {
+			// short-circuit. We expect to receive events from orders we aren't actively tracking
+			return nil
+		}
+		logger.WithFields(logger.Fields{
+			"error":     err.Error(),
+			"orderHash": orderHash,
+		}).Warning("Unexpected error using FindByID for V4 order")
+		return nil
+	}
+	return &order
+}
+
+// findOrdersV4ByMakerToken finds and returns all V4 orders with the given
+// maker and maker token. It returns nothing if V4 support is disabled.
+func (w *Watcher) findOrdersV4ByMakerToken(makerAddress, tokenAddress common.Address) ([]*meshdb.OrderV4, error) {
+	if w.contractAddresses.ExchangeProxy == constants.NullAddress {
+		return nil, nil
+	}
+	orders, err := w.meshDB.FindOrdersV4ByMakerAddressAndMakerToken(makerAddress, tokenAddress)
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"error": err.Error(),
+		}).Error("unexpected query error encountered")
+		return nil, err
+	}
+	return orders, nil
+}
+
+// handleOrderExpirationsV4 is the V4 counterpart of handleOrderExpirations.
+func (w *Watcher) handleOrderExpirationsV4(ordersColTxn *db.Transaction, latestBlockTimestamp, previousLatestBlockTimestamp time.Time, ordersToRevalidate map[common.Hash]*meshdb.OrderV4) ([]*zeroex.OrderEvent, error) {
+	orderEvents := []*zeroex.OrderEvent{}
+	if w.contractAddresses.ExchangeProxy == constants.NullAddress {
+		return orderEvents, nil
+	}
+	var defaultTime time.Time
+
+	if previousLatestBlockTimestamp == defaultTime || previousLatestBlockTimestamp.Before(latestBlockTimestamp) {
+		expiredOrders, err := w.meshDB.FindOrdersV4ExpiredAt(big.NewInt(latestBlockTimestamp.Unix()))
+		if err != nil {
+			return orderEvents, err
+		}
+		for _, order := range expiredOrders {
+			if order.IsRemoved {
+				continue
+			}
+			// If we will re-validate this order, the revalidation process will discover that
+			// it's expired, and an appropriate event will already be emitted
+			if _, ok := ordersToRevalidate[order.Hash]; ok {
+				continue
+			}
+			w.unwatchOrderV4(ordersColTxn, order, order.FillableTakerAssetAmount)
+			orderEvents = append(orderEvents, &zeroex.OrderEvent{
+				Timestamp:                latestBlockTimestamp,
+				OrderHash:                order.Hash,
+				SignedOrderV4:            order.SignedOrder,
+				FillableTakerAssetAmount: big.NewInt(0),
+				EndState:                 zeroex.ESOrderExpired,
+			})
+		}
+	} else if previousLatestBlockTimestamp.After(latestBlockTimestamp) {
+		// A block re-org happened resulting in the latest block timestamp being
+		// lower than on the previous latest block. We need to "unexpire" any orders
+		// that have now become valid again as a result.
+		removedOrders, err := w.meshDB.FindRemovedOrdersV4()
+		if err != nil {
+			return orderEvents, err
+		}
+		for _, order := range removedOrders {
+			// Orders removed due to expiration have non-zero FillableTakerAssetAmounts
+			if order.FillableTakerAssetAmount.Cmp(big.NewInt(0)) == 0 {
+				continue
+			}
+			if _, ok := ordersToRevalidate[order.Hash]; ok {
+				continue
+			}
+			expiration := time.Unix(order.SignedOrder.Expiry.Int64(), 0)
+			if latestBlockTimestamp.Before(expiration) {
+				w.rewatchOrderV4(ordersColTxn, order, order.FillableTakerAssetAmount)
+				orderEvents = append(orderEvents, &zeroex.OrderEvent{
+					Timestamp:                latestBlockTimestamp,
+					OrderHash:                order.Hash,
+					SignedOrderV4:            order.SignedOrder,
+					FillableTakerAssetAmount: order.FillableTakerAssetAmount,
+					EndState:                 zeroex.ESOrderUnexpired,
+				})
+			}
+		}
+	}
+
+	return orderEvents, nil
+}
+
+// generateOrderEventsV4IfChanged is the V4 counterpart of
+// generateOrderEventsIfChanged. V4 orders are always re-validated on-chain.
+func (w *Watcher) generateOrderEventsV4IfChanged(
+	ctx context.Context,
+	ordersColTxn *db.Transaction,
+	orderHashToDBOrder map[common.Hash]*meshdb.OrderV4,
+	orderHashToEvents map[common.Hash][]*zeroex.ContractEvent,
+	validationBlockNumber *big.Int,
+	validationBlockTimestamp time.Time,
+) ([]*zeroex.OrderEvent, error) {
+	signedOrders := []*zeroex.SignedOrderV4{}
+	for _, order := range orderHashToDBOrder {
+		if order.IsRemoved && time.Since(order.LastUpdated) > permanentlyDeleteAfter {
+			if err := w.permanentlyDeleteOrderV4(ordersColTxn, order); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		signedOrders = append(signedOrders, order.SignedOrder)
+	}
+	if len(signedOrders) == 0 {
+		return nil, nil
+	}
+	areNewOrders := false
+	validationResults := w.orderValidator.BatchValidateV4(ctx, signedOrders, areNewOrders, validationBlockNumber)
+
+	return w.convertValidationResultsIntoOrderEventsV4(
+		ordersColTxn, validationResults, orderHashToDBOrder, orderHashToEvents, validationBlockTimestamp,
+	)
+}
+
+// convertValidationResultsIntoOrderEventsV4 is the V4 counterpart of
+// convertValidationResultsIntoOrderEvents.
+func (w *Watcher) convertValidationResultsIntoOrderEventsV4(
+	ordersColTxn *db.Transaction,
+	validationResults *ordervalidator.ValidationResults,
+	orderHashToDBOrder map[common.Hash]*meshdb.OrderV4,
+	orderHashToEvents map[common.Hash][]*zeroex.ContractEvent,
+	validationBlockTimestamp time.Time,
+) ([]*zeroex.OrderEvent, error) {
+	orderEvents := []*zeroex.OrderEvent{}
+	for _, acceptedOrderInfo := range validationResults.Accepted {
+		order, found := orderHashToDBOrder[acceptedOrderInfo.OrderHash]
+		if !found {
+			logger.WithFields(logger.Fields{
+				"unknownOrderHash": acceptedOrderInfo.OrderHash,
+			}).Error("validationResults.Accepted contained unknown V4 order hash")
+			continue
+		}
+		oldFillableAmount := order.FillableTakerAssetAmount
+		newFillableAmount := acceptedOrderInfo.FillableTakerAssetAmount
+
+		if oldFillableAmount.Cmp(big.NewInt(0)) == 0 {
+			// The order was revived, e.g. because a block re-org caused the fill
+			// transaction to get reverted.
+			w.rewatchOrderV4(ordersColTxn, order, newFillableAmount)
+			orderEvents = append(orderEvents, &zeroex.OrderEvent{
+				Timestamp:                validationBlockTimestamp,
+				OrderHash:                order.Hash,
+				SignedOrderV4:            order.SignedOrder,
+				FillableTakerAssetAmount: newFillableAmount,
+				EndState:                 zeroex.ESOrderAdded,
+				ContractEvents:           orderHashToEvents[order.Hash],
+			})
+			continue
+		}
+
+		expiration := time.Unix(order.SignedOrder.Expiry.Int64(), 0)
+		// If order was previously expired, check if it has become unexpired
+		if order.IsRemoved && validationBlockTimestamp.Before(expiration) {
+			w.rewatchOrderV4(ordersColTxn, order, newFillableAmount)
+			orderEvents = append(orderEvents, &zeroex.OrderEvent{
+				Timestamp:                validationBlockTimestamp,
+				OrderHash:                order.Hash,
+				SignedOrderV4:            order.SignedOrder,
+				FillableTakerAssetAmount: newFillableAmount,
+				EndState:                 zeroex.ESOrderUnexpired,
+			})
+		} else if oldFillableAmount.Cmp(newFillableAmount) != 0 {
+			order.FillableTakerAssetAmount = newFillableAmount
+			w.updateOrderV4DBEntry(ordersColTxn, order)
+		}
+
+		var endState zeroex.OrderEventEndState
+		switch oldFillableAmount.Cmp(newFillableAmount) {
+		case 0:
+			// No important state-change happened
+			continue
+		case 1:
+			endState = zeroex.ESOrderFilled
+		default:
+			// The order is now fillable for more then it was before. E.g.: A fill txn reverted (block-reorg)
+			endState = zeroex.ESOrderFillabilityIncreased
+		}
+		orderEvents = append(orderEvents, &zeroex.OrderEvent{
+			Timestamp:                validationBlockTimestamp,
+			OrderHash:                order.Hash,
+			SignedOrderV4:            order.SignedOrder,
+			EndState:                 endState,
+			FillableTakerAssetAmount: newFillableAmount,
+			ContractEvents:           orderHashToEvents[order.Hash],
+		})
+	}
+	for _, rejectedOrderInfo := range validationResults.Rejected {
+		switch rejectedOrderInfo.Kind {
+		case ordervalidator.MeshError, ordervalidator.MeshValidation:
+			// These are not caused by a change in on-chain state, so we leave the
+			// order as is and try again the next time it is re-validated.
+		case ordervalidator.ZeroExValidation:
+			order, found := orderHashToDBOrder[rejectedOrderInfo.OrderHash]
+			if !found {
+				logger.WithFields(logger.Fields{
+					"unknownOrderHash": rejectedOrderInfo.OrderHash,
+				}).Error("validationResults.Rejected contained unknown V4 order hash")
+				continue
+			}
+			if order.FillableTakerAssetAmount.Cmp(big.NewInt(0)) == 0 {
+				// If the oldFillableAmount was already 0, this order is already flagged for removal.
+				continue
+			}
+			w.unwatchOrderV4(ordersColTxn, order, big.NewInt(0))
+			endState, ok := ordervalidator.ConvertRejectOrderCodeToOrderEventEndState(rejectedOrderInfo.Status)
+			if !ok {
+				err := fmt.Errorf("no OrderEventEndState corresponding to RejectedOrderStatus: %q", rejectedOrderInfo.Status)
+				logger.WithError(err).WithField("rejectedOrderStatus", rejectedOrderInfo.Status).Error("no OrderEventEndState corresponding to RejectedOrderStatus")
+				return nil, err
+			}
+			orderEvents = append(orderEvents, &zeroex.OrderEvent{
+				Timestamp:                validationBlockTimestamp,
+				OrderHash:                order.Hash,
+				SignedOrderV4:            order.SignedOrder,
+				FillableTakerAssetAmount: big.NewInt(0),
+				EndState:                 endState,
+				ContractEvents:           orderHashToEvents[order.Hash],
+			})
+		default:
+			err := fmt.Errorf("unknown rejectedOrderInfo.Kind: %q", rejectedOrderInfo.Kind)
+			logger.WithError(err).Error("encountered unhandled rejectedOrderInfo.Kind value")
+			return nil, err
+		}
+	}
+
+	return orderEvents, nil
+}
+
+// cleanupV4 re-validates all V4 orders which haven't been re-validated since
+// lastUpdatedCutOff.
+func (w *Watcher) cleanupV4(ctx context.Context, lastUpdatedCutOff time.Time, validationBlockNumber *big.Int, validationBlockTimestamp time.Time) ([]*zeroex.OrderEvent, error) {
+	if w.contractAddresses.ExchangeProxy == constants.NullAddress {
+		return nil, nil
+	}
+	ordersColTxn := w.meshDB.OrdersV4.OpenTransaction()
+	defer func() {
+		_ = ordersColTxn.Discard()
+	}()
+	orders, err := w.meshDB.FindOrdersV4LastUpdatedBefore(lastUpdatedCutOff)
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"error":             err.Error(),
+			"lastUpdatedCutOff": lastUpdatedCutOff,
+		}).Error("Failed to find V4 orders by LastUpdatedBefore")
+		return nil, err
+	}
+	orderHashToDBOrder := map[common.Hash]*meshdb.OrderV4{}
+	orderHashToEvents := map[common.Hash][]*zeroex.ContractEvent{} // No events when running cleanup job
+	for _, order := range orders {
+		orderHashToDBOrder[order.Hash] = order
+		orderHashToEvents[order.Hash] = []*zeroex.ContractEvent{}
+	}
+	orderEvents, err := w.generateOrderEventsV4IfChanged(ctx, ordersColTxn, orderHashToDBOrder, orderHashToEvents, validationBlockNumber, validationBlockTimestamp)
+	if err != nil {
+		return nil, err
+	}
+	if err := ordersColTxn.Commit(); err != nil {
+		logger.WithFields(logger.Fields{
+			"error": err.Error(),
+		}).Error("Failed to commit V4 orders collection transaction")
+	}
+	return orderEvents, nil
+}
+
+func (w *Watcher) permanentlyDeleteStaleRemovedOrdersV4() error {
+	removedOrders, err := w.meshDB.FindRemovedOrdersV4()
+	if err != nil {
+		return err
+	}
+	for _, order := range removedOrders {
+		if time.Since(order.LastUpdated) > permanentlyDeleteAfter {
+			if err := w.permanentlyDeleteOrderV4(w.meshDB.OrdersV4, order); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (w *Watcher) updateOrderV4DBEntry(u orderUpdater, order *meshdb.OrderV4) {
+	order.LastUpdated = time.Now().UTC()
+	if err := u.Update(order); err != nil {
+		logger.WithFields(logger.Fields{
+			"error": err.Error(),
+			"order": order,
+		}).Error("Failed to update V4 order")
+	}
+}
+
+func (w *Watcher) rewatchOrderV4(u orderUpdater, order *meshdb.OrderV4, fillableTakerAssetAmount *big.Int) {
+	order.IsRemoved = false
+	order.FillableTakerAssetAmount = fillableTakerAssetAmount
+	w.updateOrderV4DBEntry(u, order)
+}
+
+func (w *Watcher) unwatchOrderV4(u orderUpdater, order *meshdb.OrderV4, newFillableAmount *big.Int) {
+	order.IsRemoved = true
+	order.FillableTakerAssetAmount = newFillableAmount
+	w.updateOrderV4DBEntry(u, order)
+}
+
+func (w *Watcher) permanentlyDeleteOrderV4(deleter orderDeleter, order *meshdb.OrderV4) error {
+	if err := deleter.Delete(order.Hash.Bytes()); err != nil {
+		if _, ok := err.(db.ConflictingOperationsError); ok {
+			logger.WithFields(logger.Fields{
+				"error": err.Error(),
+				"order": order,
+			}).Error("Failed to permanently delete V4 order")
+			return nil
+		}
+		if _, ok := err.(db.NotFoundError); ok {
+			return nil // Already deleted. Noop.
+		}
+		return err
+	}
+	w.removeInMemoryOrderStateV4(order.SignedOrder)
+	return nil
+}
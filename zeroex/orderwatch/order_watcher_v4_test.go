@@ -0,0 +1,223 @@
+// +build !js
+
+package orderwatch
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/0xProject/0x-mesh/constants"
+	"github.com/0xProject/0x-mesh/meshdb"
+	"github.com/0xProject/0x-mesh/scenario"
+	"github.com/0xProject/0x-mesh/scenario/orderopts"
+	"github.com/0xProject/0x-mesh/zeroex"
+	"github.com/0xProject/0x-mesh/zeroex/ordervalidator"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newSignedTestOrderV4(t *testing.T, salt int64, expiry *big.Int) *zeroex.SignedOrderV4 {
+	order := &zeroex.OrderV4{
+		Type:                zeroex.LimitOrderTypeV4,
+		ChainID:             big.NewInt(constants.TestChainID),
+		VerifyingContract:   common.HexToAddress("0xdef1c0ded9bec7f1a1670819833240f027b25eff"),
+		MakerToken:          ganacheAddresses.ZRXToken,
+		TakerToken:          ganacheAddresses.WETH9,
+		MakerAmount:         big.NewInt(100),
+		TakerAmount:         big.NewInt(42),
+		TakerTokenFeeAmount: big.NewInt(0),
+		Maker:               constants.GanacheAccount1,
+		Taker:               constants.NullAddress,
+		Sender:              constants.NullAddress,
+		FeeRecipient:        constants.NullAddress,
+		TxOrigin:            constants.NullAddress,
+		Expiry:              expiry,
+		Salt:                big.NewInt(salt),
+	}
+	signedOrder, err := zeroex.SignTestOrderV4(order)
+	require.NoError(t, err)
+	return signedOrder
+}
+
+// acceptedOrderInfosV4 returns the AcceptedOrderInfos which the order validator
+// would return for the given fillable V4 orders.
+func acceptedOrderInfosV4(t *testing.T, signedOrders []*zeroex.SignedOrderV4) []*ordervalidator.AcceptedOrderInfo {
+	orderInfos := make([]*ordervalidator.AcceptedOrderInfo, len(signedOrders))
+	for i, signedOrder := range signedOrders {
+		orderHash, err := signedOrder.ComputeOrderHash()
+		require.NoError(t, err)
+		orderInfos[i] = &ordervalidator.AcceptedOrderInfo{
+			OrderHash:                orderHash,
+			SignedOrderV4:            signedOrder,
+			FillableTakerAssetAmount: signedOrder.TakerAmount,
+			IsNew:                    true,
+		}
+	}
+	return orderInfos
+}
+
+func TestOrderWatcherV4RejectsMissingExpiry(t *testing.T) {
+	if !serialTestsEnabled {
+		t.Skip("Serial tests (tests which cannot run in parallel) are disabled. You can enable them with the --serial flag")
+	}
+
+	teardownSubTest := setupSubTest(t)
+	defer teardownSubTest(t)
+	meshDB, err := meshdb.New("/tmp/leveldb_testing/"+uuid.New().String(), ganacheAddresses)
+	require.NoError(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	_, orderWatcher := setupOrderWatcher(ctx, t, ethRPCClient, meshDB)
+
+	signedOrder := newSignedTestOrderV4(t, 0, nil)
+	results, validOrders, err := orderWatcher.meshSpecificOrderValidationV4([]*zeroex.SignedOrderV4{signedOrder}, constants.TestChainID)
+	require.NoError(t, err)
+	assert.Len(t, validOrders, 0)
+	require.Len(t, results.Rejected, 1)
+	assert.Equal(t, ordervalidator.ROInvalidExpiryV4, results.Rejected[0].Status)
+}
+
+func TestOrderWatcherV4OrdersAreTrimmed(t *testing.T) {
+	if !serialTestsEnabled {
+		t.Skip("Serial tests (tests which cannot run in parallel) are disabled. You can enable them with the --serial flag")
+	}
+
+	teardownSubTest := setupSubTest(t)
+	defer teardownSubTest(t)
+	meshDB, err := meshdb.New("/tmp/leveldb_testing/"+uuid.New().String(), ganacheAddresses)
+	require.NoError(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	_, orderWatcher := setupOrderWatcher(ctx, t, ethRPCClient, meshDB)
+	orderWatcher.maxOrders = 10
+
+	// Fill the database with V4 orders, each with a different expiry.
+	baseExpiry := time.Now().Add(10 * time.Minute).Unix()
+	signedOrders := make([]*zeroex.SignedOrderV4, orderWatcher.maxOrders)
+	for i := range signedOrders {
+		signedOrders[i] = newSignedTestOrderV4(t, int64(i), big.NewInt(baseExpiry+int64(i)*60))
+	}
+	orderEvents, err := orderWatcher.addV4(acceptedOrderInfosV4(t, signedOrders), false)
+	require.NoError(t, err)
+	require.Len(t, orderEvents, orderWatcher.maxOrders)
+
+	// The next order should cause the V4 order with the highest expiry to be
+	// removed.
+	newOrder := newSignedTestOrderV4(t, int64(orderWatcher.maxOrders), big.NewInt(baseExpiry+1))
+	orderEvents, err = orderWatcher.addV4(acceptedOrderInfosV4(t, []*zeroex.SignedOrderV4{newOrder}), false)
+	require.NoError(t, err)
+	expectedNumRemoved := orderWatcher.maxOrders - int(maxOrdersTrimRatio*float64(orderWatcher.maxOrders))
+	require.Len(t, orderEvents, expectedNumRemoved+1)
+	for _, orderEvent := range orderEvents[:expectedNumRemoved] {
+		assert.Equal(t, zeroex.ESStoppedWatching, orderEvent.EndState)
+		assert.True(t, orderEvent.SignedOrderV4.Expiry.Cmp(orderWatcher.MaxExpirationTimeV4()) == 1)
+	}
+	assert.Equal(t, zeroex.ESOrderAdded, orderEvents[expectedNumRemoved].EndState)
+
+	var remainingOrders []*meshdb.OrderV4
+	require.NoError(t, meshDB.OrdersV4.FindAll(&remainingOrders))
+	assert.Len(t, remainingOrders, orderWatcher.maxOrders-expectedNumRemoved+1)
+	for _, order := range remainingOrders {
+		assert.True(t, order.SignedOrder.Expiry.Cmp(orderWatcher.MaxExpirationTimeV4()) != 1)
+	}
+}
+
+func TestOrderWatcherV4OrdersCountTowardsMaxOrders(t *testing.T) {
+	if !serialTestsEnabled {
+		t.Skip("Serial tests (tests which cannot run in parallel) are disabled. You can enable them with the --serial flag")
+	}
+
+	teardownSubTest := setupSubTest(t)
+	defer teardownSubTest(t)
+	meshDB, err := meshdb.New("/tmp/leveldb_testing/"+uuid.New().String(), ganacheAddresses)
+	require.NoError(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	blockWatcher, orderWatcher := setupOrderWatcher(ctx, t, ethRPCClient, meshDB)
+	orderWatcher.maxOrders = 10
+
+	// Fill the database with V3 orders.
+	optionsForIndex := func(index int) []orderopts.Option {
+		expirationTime := time.Now().Add(10*time.Minute + time.Duration(index)*time.Minute)
+		return []orderopts.Option{
+			orderopts.SetupMakerState(true),
+			orderopts.ExpirationTimeSeconds(big.NewInt(expirationTime.Unix())),
+		}
+	}
+	for _, signedOrder := range scenario.NewSignedTestOrdersBatch(t, orderWatcher.maxOrders, optionsForIndex) {
+		watchOrder(ctx, t, orderWatcher, blockWatcher, ethClient, signedOrder)
+	}
+
+	// Adding a V4 order exceeds the limit, so V3 orders are removed.
+	newOrder := newSignedTestOrderV4(t, 0, big.NewInt(time.Now().Add(10*time.Minute).Unix()))
+	orderEvents, err := orderWatcher.addV4(acceptedOrderInfosV4(t, []*zeroex.SignedOrderV4{newOrder}), false)
+	require.NoError(t, err)
+	expectedNumRemoved := orderWatcher.maxOrders - int(maxOrdersTrimRatio*float64(orderWatcher.maxOrders))
+	require.Len(t, orderEvents, expectedNumRemoved+1)
+	for _, orderEvent := range orderEvents[:expectedNumRemoved] {
+		assert.Equal(t, zeroex.ESStoppedWatching, orderEvent.EndState)
+		assert.NotNil(t, orderEvent.SignedOrder)
+	}
+	assert.Equal(t, zeroex.ESOrderAdded, orderEvents[expectedNumRemoved].EndState)
+
+	count, err := orderWatcher.countStoredOrders()
+	require.NoError(t, err)
+	assert.Equal(t, orderWatcher.maxOrders-expectedNumRemoved+1, count)
+}
+
+func TestOrderWatcherPinnedV4OrdersAreTrimmedFromV3Orders(t *testing.T) {
+	if !serialTestsEnabled {
+		t.Skip("Serial tests (tests which cannot run in parallel) are disabled. You can enable them with the --serial flag")
+	}
+
+	teardownSubTest := setupSubTest(t)
+	defer teardownSubTest(t)
+	meshDB, err := meshdb.New("/tmp/leveldb_testing/"+uuid.New().String(), ganacheAddresses)
+	require.NoError(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	blockWatcher, orderWatcher := setupOrderWatcher(ctx, t, ethRPCClient, meshDB)
+	orderWatcher.maxOrders = 10
+
+	// Fill half of the database with unpinned V3 orders and the other half with
+	// pinned V4 orders.
+	numV3Orders := orderWatcher.maxOrders / 2
+	optionsForIndex := func(index int) []orderopts.Option {
+		expirationTime := time.Now().Add(10*time.Minute + time.Duration(index)*time.Minute)
+		return []orderopts.Option{
+			orderopts.SetupMakerState(true),
+			orderopts.ExpirationTimeSeconds(big.NewInt(expirationTime.Unix())),
+		}
+	}
+	for _, signedOrder := range scenario.NewSignedTestOrdersBatch(t, numV3Orders, optionsForIndex) {
+		watchOrder(ctx, t, orderWatcher, blockWatcher, ethClient, signedOrder)
+	}
+	baseExpiry := time.Now().Add(10 * time.Minute).Unix()
+	pinnedOrders := make([]*zeroex.SignedOrderV4, orderWatcher.maxOrders-numV3Orders)
+	for i := range pinnedOrders {
+		pinnedOrders[i] = newSignedTestOrderV4(t, int64(i), big.NewInt(baseExpiry+int64(i)*60))
+	}
+	_, err = orderWatcher.addV4(acceptedOrderInfosV4(t, pinnedOrders), true)
+	require.NoError(t, err)
+
+	// The V4 share of the storage is taken up by pinned orders, so V3 orders
+	// are removed instead.
+	newOrder := newSignedTestOrderV4(t, int64(len(pinnedOrders)), big.NewInt(baseExpiry))
+	orderEvents, err := orderWatcher.addV4(acceptedOrderInfosV4(t, []*zeroex.SignedOrderV4{newOrder}), false)
+	require.NoError(t, err)
+	expectedNumRemoved := orderWatcher.maxOrders - int(maxOrdersTrimRatio*float64(orderWatcher.maxOrders))
+	require.Len(t, orderEvents, expectedNumRemoved+1)
+	for _, orderEvent := range orderEvents[:expectedNumRemoved] {
+		assert.Equal(t, zeroex.ESStoppedWatching, orderEvent.EndState)
+		assert.NotNil(t, orderEvent.SignedOrder)
+	}
+	assert.Equal(t, zeroex.ESOrderAdded, orderEvents[expectedNumRemoved].EndState)
+
+	numV4Orders, err := meshDB.OrdersV4.Count()
+	require.NoError(t, err)
+	assert.Equal(t, len(pinnedOrders)+1, numV4Orders)
+}